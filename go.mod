@@ -17,6 +17,7 @@ require (
 	github.com/spf13/afero v1.6.0
 	github.com/terminalstatic/go-xsd-validate v0.1.5
 	github.com/wtolson/go-taglib v0.0.0-20180718000046-586eb63c2628
+	golang.org/x/crypto v0.45.0
 	golang.org/x/image v0.19.0
 	golang.org/x/sync v0.18.0
 	gopkg.in/mineo/gocaa.v1 v1.0.0-20180225115936-2500f801cd83
@@ -29,8 +30,8 @@ require (
 	github.com/lib/pq v1.10.4 // indirect
 	github.com/magefile/mage v1.12.1 // indirect
 	github.com/ziutek/mymysql v1.5.4 // indirect
-	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/mod v0.29.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 	golang.org/x/tools v0.38.0 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
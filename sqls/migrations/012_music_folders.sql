@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `music_folders` (
+    `id` integer not null primary key,
+    `name` text not null,
+    `fs_path` text not null unique
+);
+
+alter table `tracks` add column `music_folder_id` integer null;
+create index if not exists tracks_music_folders on `tracks` (`music_folder_id`);
+
+-- +migrate Down
+drop index if exists tracks_music_folders;
+alter table `tracks` drop column `music_folder_id`;
+drop table if exists `music_folders`;
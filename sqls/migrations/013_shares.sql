@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `shares` (
+    `id` text not null primary key,
+    `description` text null,
+    `created_at` integer not null,
+    `expires_at` integer null,
+    `last_visited` integer null,
+    `visit_count` integer not null default 0
+);
+
+CREATE TABLE IF NOT EXISTS `shares_items` (
+    `share_id` text not null,
+    `item_type` integer not null,
+    `item_id` integer not null,
+    `index` integer not null default 0,
+    FOREIGN KEY(share_id) REFERENCES shares(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create index if not exists shares_items_share on `shares_items` (`share_id`);
+
+-- +migrate Down
+drop index if exists shares_items_share;
+drop table if exists `shares_items`;
+drop table if exists `shares`;
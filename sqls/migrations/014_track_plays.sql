@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `track_plays` (
+    `track_id` integer not null,
+    `played_at` integer not null, -- Unix timestamp in seconds.
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create index if not exists track_plays_played_at on `track_plays` (`played_at`);
+create index if not exists track_plays_track on `track_plays` (`track_id`);
+
+-- +migrate Down
+drop index if exists track_plays_track;
+drop index if exists track_plays_played_at;
+drop table if exists `track_plays`;
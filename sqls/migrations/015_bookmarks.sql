@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `bookmarks` (
+    `track_id` integer not null primary key,
+    `position` integer not null,
+    `comment` text null,
+    `created_at` integer not null,
+    `updated_at` integer not null,
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- +migrate Down
+drop table if exists `bookmarks`;
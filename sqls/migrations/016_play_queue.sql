@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `play_queue` (
+    `id` integer not null primary key,
+    `current_index` integer null,
+    `position` integer not null default 0,
+    `changed_by` text not null,
+    `updated_at` integer not null,
+    `version` integer not null
+);
+
+CREATE TABLE IF NOT EXISTS `play_queue_tracks` (
+    `queue_id` integer not null,
+    `track_id` integer not null,
+    `index` integer not null,
+    FOREIGN KEY(queue_id) REFERENCES play_queue(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create index if not exists play_queue_tracks_queue on `play_queue_tracks` (`queue_id`);
+
+-- +migrate Down
+drop index if exists play_queue_tracks_queue;
+drop table if exists `play_queue_tracks`;
+drop table if exists `play_queue`;
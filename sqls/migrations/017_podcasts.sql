@@ -0,0 +1,40 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `podcast_channels` (
+    `id` integer not null primary key,
+    `url` text not null unique,
+    `title` text not null,
+    `description` text null,
+    `image_url` text null,
+    `image_path` text null,
+    `status` text not null,
+    `error_message` text null,
+    `created_at` integer not null,
+    `refreshed_at` integer null
+);
+
+CREATE TABLE IF NOT EXISTS `podcast_episodes` (
+    `id` integer not null primary key,
+    `channel_id` integer not null,
+    `guid` text not null,
+    `title` text not null,
+    `description` text null,
+    `url` text not null,
+    `content_type` text null,
+    `size` integer not null default 0,
+    `duration` integer not null default 0,
+    `publish_date` integer null,
+    `status` text not null,
+    `error_message` text null,
+    `file_path` text null,
+    `downloaded_at` integer null,
+    FOREIGN KEY(channel_id) REFERENCES podcast_channels(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create unique index if not exists podcast_episodes_guid on `podcast_episodes` (`channel_id`, `guid`);
+create index if not exists podcast_episodes_publish_date on `podcast_episodes` (`publish_date`);
+
+-- +migrate Down
+drop index if exists podcast_episodes_publish_date;
+drop index if exists podcast_episodes_guid;
+drop table if exists `podcast_episodes`;
+drop table if exists `podcast_channels`;
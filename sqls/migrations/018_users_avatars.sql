@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `users_avatars` (
+    `id` integer not null primary key,
+    `username` text not null unique,
+    `image` blob not null,
+    `image_small` blob default null,
+    `updated_at` integer not null
+);
+
+-- +migrate Down
+drop table if exists `users_avatars`;
@@ -0,0 +1,8 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS `library_modified` (
+    `id` integer not null primary key,
+    `modified_at` integer not null
+);
+
+-- +migrate Down
+drop table if exists `library_modified`;
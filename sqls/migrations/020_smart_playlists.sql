@@ -0,0 +1,6 @@
+-- +migrate Up
+-- JSON encoded rules for smart playlists. NULL for regular playlists.
+alter table playlists add column rules text null;
+
+-- +migrate Down
+alter table playlists drop column rules;
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- The playlist file in the library which this playlist mirrors. NULL for
+-- playlists which are not backed by files.
+alter table playlists add column fs_path text null;
+
+create unique index if not exists playlists_fs_paths on `playlists` (`fs_path`);
+
+-- +migrate Down
+drop index if exists playlists_fs_paths;
+alter table playlists drop column fs_path;
@@ -0,0 +1,5 @@
+-- +migrate Up
+alter table `playlists_images` add column `image_small` blob default null;
+
+-- +migrate Down
+alter table `playlists_images` drop column `image_small`;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- The name of the user who owns the playlist. NULL for playlists which were
+-- created before playlists had owners and for the ones backed by files. Such
+-- playlists are visible and editable for all users.
+alter table playlists add column owner text null;
+
+-- Users other than the owner who may edit a playlist.
+create table if not exists `playlists_collaborators` (
+    `playlist_id` integer not null,
+    `username` text not null,
+    FOREIGN KEY(playlist_id) REFERENCES playlists(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create unique index if not exists playlists_collaborators_pairs on `playlists_collaborators` (`playlist_id`, `username`);
+
+-- +migrate Down
+drop index if exists playlists_collaborators_pairs;
+drop table if exists `playlists_collaborators`;
+alter table playlists drop column owner;
@@ -0,0 +1,53 @@
+-- +migrate Up
+-- Playlist entries remember the file path and metadata of their tracks so that
+-- they could be linked again when a removed track is added back to the library.
+-- The track ID is NULL while the track is missing from the library. SQLite does
+-- not support changing foreign keys so the table is created anew.
+CREATE TABLE IF NOT EXISTS `playlists_entries` (
+    `playlist_id` integer not null,
+    `track_id` integer null,
+    `index` integer not null default 0,
+    `fs_path` text null,
+    `artist` text null,
+    `album` text null,
+    `title` text null,
+    `duration` integer null,
+    FOREIGN KEY(playlist_id) REFERENCES playlists(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+INSERT INTO playlists_entries
+    (playlist_id, track_id, "index", fs_path, artist, album, title, duration)
+SELECT
+    pt.playlist_id, pt.track_id, pt."index", t.fs_path, ar.name, al.name, t.name,
+    t.duration
+FROM
+    playlists_tracks pt
+    JOIN tracks t ON t.id = pt.track_id
+    LEFT JOIN artists ar ON ar.id = t.artist_id
+    LEFT JOIN albums al ON al.id = t.album_id;
+
+drop table if exists `playlists_tracks`;
+alter table `playlists_entries` rename to `playlists_tracks`;
+
+create unique index if not exists playlist_pairs_with_index on `playlists_tracks` ('playlist_id', `index`);
+create index if not exists playlists_tracks_ids on `playlists_tracks` (`track_id`);
+
+-- +migrate Down
+CREATE TABLE IF NOT EXISTS `playlists_entries` (
+    `playlist_id` integer not null,
+    `track_id` integer not null,
+    `index` integer not null default 0,
+    FOREIGN KEY(playlist_id) REFERENCES playlists(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+INSERT INTO playlists_entries (playlist_id, track_id, "index")
+SELECT playlist_id, track_id, "index"
+FROM playlists_tracks
+WHERE track_id IS NOT NULL;
+
+drop table if exists `playlists_tracks`;
+alter table `playlists_entries` rename to `playlists_tracks`;
+
+create unique index if not exists playlist_pairs_with_index on `playlists_tracks` ('playlist_id', `index`);
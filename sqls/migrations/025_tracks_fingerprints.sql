@@ -0,0 +1,7 @@
+-- +migrate Up
+alter table tracks add column fingerprint text null; -- checksum of the audio data
+create index if not exists tracks_fingerprints on tracks (fingerprint);
+
+-- +migrate Down
+drop index if exists tracks_fingerprints;
+alter table tracks drop column fingerprint;
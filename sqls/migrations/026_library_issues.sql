@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Problems found while scanning the library and resolving album artwork. Issues of
+-- files which could not be added have neither track_id nor album_id.
+CREATE TABLE IF NOT EXISTS `library_issues` (
+    `id` integer not null primary key,
+    `kind` text not null,
+    `fs_path` text not null,
+    `track_id` integer null,
+    `album_id` integer null,
+    `message` text not null default '',
+    `found_at` integer not null, -- Unix timestamp in seconds
+    FOREIGN KEY(track_id) REFERENCES tracks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(album_id) REFERENCES albums(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create unique index if not exists library_issues_kinds on `library_issues` (`kind`, `fs_path`);
+create index if not exists library_issues_tracks on `library_issues` (`track_id`);
+create index if not exists library_issues_albums on `library_issues` (`album_id`);
+
+-- +migrate Down
+drop index if exists library_issues_albums;
+drop index if exists library_issues_tracks;
+drop index if exists library_issues_kinds;
+drop table if exists `library_issues`;
@@ -0,0 +1,7 @@
+-- +migrate Up
+alter table tracks add column genre text null;
+alter table tracks add column comment text null;
+
+-- +migrate Down
+alter table tracks drop column comment;
+alter table tracks drop column genre;
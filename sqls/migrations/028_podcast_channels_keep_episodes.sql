@@ -0,0 +1,5 @@
+-- +migrate Up
+alter table podcast_channels add column keep_episodes integer null;
+
+-- +migrate Down
+alter table podcast_channels drop column keep_episodes;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- The name of the user who created the share. NULL for shares which were created
+-- before shares had owners. Private playlists are never expanded for them.
+alter table shares add column owner text null;
+
+-- +migrate Down
+alter table shares drop column owner;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Fingerprints are computed only from the audio data and from samples of it for
+-- large files now. The old ones are removed so that they are computed again.
+update tracks set fingerprint = null;
+
+-- +migrate Down
+update tracks set fingerprint = null;
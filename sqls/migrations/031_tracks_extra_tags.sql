@@ -0,0 +1,27 @@
+-- +migrate Up
+-- Tags which are read by their TagLib property names. The album ones are stored
+-- for every track and are aggregated for albums the same way as the year.
+alter table tracks add column sort_name text null;
+alter table tracks add column musicbrainz_id text null;
+alter table tracks add column isrc text null; -- semicolon separated list
+alter table tracks add column bpm integer null;
+alter table tracks add column disc_number integer null;
+alter table tracks add column disc_subtitle text null;
+alter table tracks add column album_sort_name text null;
+alter table tracks add column album_musicbrainz_id text null;
+alter table tracks add column release_date text null;
+alter table tracks add column original_release_date text null;
+alter table tracks add column compilation integer null;
+
+-- +migrate Down
+alter table tracks drop column compilation;
+alter table tracks drop column original_release_date;
+alter table tracks drop column release_date;
+alter table tracks drop column album_musicbrainz_id;
+alter table tracks drop column album_sort_name;
+alter table tracks drop column disc_subtitle;
+alter table tracks drop column disc_number;
+alter table tracks drop column bpm;
+alter table tracks drop column isrc;
+alter table tracks drop column musicbrainz_id;
+alter table tracks drop column sort_name;
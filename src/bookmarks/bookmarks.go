@@ -0,0 +1,58 @@
+// Package bookmarks deals with saved positions in tracks. They are used for
+// resuming long tracks such as audiobooks and DJ mixes, possibly on another
+// device. There is at most one bookmark for every track.
+package bookmarks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+//counterfeiter:generate . Bookmarker
+
+// Bookmarker is the interface for handling bookmarks in Euterpe.
+type Bookmarker interface {
+	// Get returns the bookmark for the track with ID `trackID`.
+	Get(ctx context.Context, trackID int64) (Bookmark, error)
+
+	// List returns all bookmarks. The most recently changed are first.
+	List(ctx context.Context) ([]Bookmark, error)
+
+	// Set creates or replaces the bookmark for the track with ID `trackID`.
+	// Returns ErrTrackNotFound when there is no such track.
+	Set(ctx context.Context, trackID int64, args SetArgs) error
+
+	// Delete removes the bookmark for the track with ID `trackID`.
+	Delete(ctx context.Context, trackID int64) error
+}
+
+// Bookmark represents a saved position in a track.
+type Bookmark struct {
+	// Track is the bookmarked track.
+	Track library.TrackInfo
+
+	Position  int64     // Position is the saved position in milliseconds.
+	Comment   string    // Comment is an optional text for the bookmark.
+	CreatedAt time.Time // CreatedAt is the time when this bookmark was created.
+	UpdatedAt time.Time // UpdatedAt is the last time this bookmark was changed.
+}
+
+// SetArgs are the arguments for creating or replacing a bookmark.
+type SetArgs struct {
+	// Position is the position in the track in milliseconds.
+	Position int64
+
+	// Comment is an optional text for the bookmark.
+	Comment string
+}
+
+var (
+	// ErrNotFound is returned when a bookmark was not found for a given operation.
+	ErrNotFound = errors.New("bookmark not found")
+
+	// ErrTrackNotFound is returned when bookmarking a track which does not exist.
+	ErrTrackNotFound = errors.New("track not found")
+)
@@ -0,0 +1,139 @@
+package bookmarks_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// TestBookmarksManager checks that the bookmarks manager creates, replaces and
+// removes bookmarks.
+func TestBookmarksManager(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	allTracks := lib.Search(ctx, library.SearchArgs{Query: "", Count: 100})
+	if len(allTracks) < 2 {
+		t.Fatalf("not enough tracks found in the library for working with bookmarks")
+	}
+
+	manager := bookmarks.NewManager(lib.ExecuteDBJobAndWait)
+
+	list, err := manager.List(ctx)
+	assert.NilErr(t, err, "listing bookmarks")
+	assert.Equal(t, 0, len(list), "did not expect any bookmarks")
+
+	_, err = manager.Get(ctx, allTracks[0].ID)
+	if !errors.Is(err, bookmarks.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing bookmark but got %v", err)
+	}
+
+	err = manager.Set(ctx, 987654, bookmarks.SetArgs{Position: 100})
+	if !errors.Is(err, bookmarks.ErrTrackNotFound) {
+		t.Errorf("expected ErrTrackNotFound for missing track but got %v", err)
+	}
+
+	err = manager.Set(ctx, allTracks[0].ID, bookmarks.SetArgs{Position: -1})
+	if err == nil {
+		t.Errorf("expected an error for negative position")
+	}
+
+	now := time.Now()
+	err = manager.Set(ctx, allTracks[0].ID, bookmarks.SetArgs{
+		Position: 60000,
+		Comment:  "chapter two",
+	})
+	assert.NilErr(t, err, "creating bookmark")
+
+	bookmark, err := manager.Get(ctx, allTracks[0].ID)
+	assert.NilErr(t, err, "getting bookmark")
+	assert.Equal(t, allTracks[0].ID, bookmark.Track.ID, "bookmarked track ID")
+	assert.Equal(t, allTracks[0].Title, bookmark.Track.Title, "bookmarked track title")
+	assert.Equal(t, 60000, bookmark.Position, "bookmark position")
+	assert.Equal(t, "chapter two", bookmark.Comment, "bookmark comment")
+	if bookmark.CreatedAt.Before(now.Add(-time.Second)) {
+		t.Errorf("bookmark creation time %s is too far in the past", bookmark.CreatedAt)
+	}
+
+	err = manager.Set(ctx, allTracks[1].ID, bookmarks.SetArgs{Position: 10})
+	assert.NilErr(t, err, "creating second bookmark")
+
+	err = manager.Set(ctx, allTracks[0].ID, bookmarks.SetArgs{Position: 90000})
+	assert.NilErr(t, err, "replacing bookmark")
+
+	replaced, err := manager.Get(ctx, allTracks[0].ID)
+	assert.NilErr(t, err, "getting replaced bookmark")
+	assert.Equal(t, 90000, replaced.Position, "replaced bookmark position")
+	assert.Equal(t, "", replaced.Comment, "replaced bookmark comment")
+	assert.Equal(t, bookmark.CreatedAt, replaced.CreatedAt, "creation time after replace")
+
+	list, err = manager.List(ctx)
+	assert.NilErr(t, err, "listing bookmarks")
+	assert.Equal(t, 2, len(list), "number of bookmarks")
+
+	err = manager.Delete(ctx, allTracks[0].ID)
+	assert.NilErr(t, err, "deleting bookmark")
+
+	err = manager.Delete(ctx, allTracks[0].ID)
+	if !errors.Is(err, bookmarks.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for deleted bookmark but got %v", err)
+	}
+
+	list, err = manager.List(ctx)
+	assert.NilErr(t, err, "listing bookmarks after delete")
+	assert.Equal(t, 1, len(list), "number of bookmarks after delete")
+	assert.Equal(t, allTracks[1].ID, list[0].Track.ID, "remaining bookmark")
+}
+
+// getTestMigrationFiles returns the SQLs directory used by the application itself
+// normally. This way tests will be done with the exact same files which will be
+// bundled into the binary on build.
+func getTestMigrationFiles() fs.FS {
+	return os.DirFS("../../sqls")
+}
+
+// getLibrary returns a library with all test files scanned into it.
+func getLibrary(ctx context.Context, t *testing.T) *library.LocalLibrary {
+	migrationsFS := getTestMigrationFiles()
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, migrationsFS)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	return lib
+}
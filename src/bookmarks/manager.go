@@ -0,0 +1,263 @@
+package bookmarks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// manager implements the Bookmarker interface by just requiring a function for
+// sending database work.
+type manager struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewManager returns a Bookmarker which will send SQL queries to `sendDBWork`.
+func NewManager(sendDBWork func(library.DatabaseExecutable) error) Bookmarker {
+	return &manager{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+const selectBookmarkQuery = `
+	SELECT
+		track_id,
+		position,
+		comment,
+		created_at,
+		updated_at
+	FROM
+		bookmarks
+`
+
+// Get implements Bookmarker.
+func (m *manager) Get(ctx context.Context, trackID int64) (Bookmark, error) {
+	const getBookmarkQuery = selectBookmarkQuery + `
+		WHERE track_id = @track_id
+	`
+
+	var bookmark Bookmark
+
+	work := func(db *sql.DB) error {
+		row := db.QueryRowContext(ctx, getBookmarkQuery, sql.Named("track_id", trackID))
+		scanned, err := scanBookmark(row)
+		if err != nil && errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+
+		bookmarks := []Bookmark{scanned}
+		if err := populateTracks(ctx, db, bookmarks); err != nil {
+			return err
+		}
+
+		bookmark = bookmarks[0]
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return Bookmark{}, err
+	}
+
+	return bookmark, nil
+}
+
+// List implements Bookmarker.
+func (m *manager) List(ctx context.Context) ([]Bookmark, error) {
+	const listBookmarksQuery = selectBookmarkQuery + `
+		ORDER BY updated_at DESC, track_id
+	`
+
+	var bookmarks []Bookmark
+
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, listBookmarksQuery)
+		if err != nil {
+			return fmt.Errorf("could not query the database: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			bookmark, err := scanBookmark(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning bookmarks: %w", err)
+			}
+
+			bookmarks = append(bookmarks, bookmark)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over bookmarks: %w", err)
+		}
+
+		return populateTracks(ctx, db, bookmarks)
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// Set implements Bookmarker.
+func (m *manager) Set(ctx context.Context, trackID int64, args SetArgs) error {
+	if args.Position < 0 {
+		return fmt.Errorf("bookmark position cannot be negative")
+	}
+
+	const setBookmarkQuery = `
+		INSERT INTO
+			bookmarks (track_id, position, comment, created_at, updated_at)
+		SELECT
+			id, @position, @comment, @current_time, @current_time
+		FROM
+			tracks
+		WHERE
+			id = @track_id
+		ON CONFLICT (track_id) DO
+		UPDATE SET
+			position = excluded.position,
+			comment = excluded.comment,
+			updated_at = excluded.updated_at
+	`
+
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx, setBookmarkQuery,
+			sql.Named("track_id", trackID),
+			sql.Named("position", args.Position),
+			sql.Named("comment", nullString(args.Comment)),
+			sql.Named("current_time", time.Now().Unix()),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set bookmark: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+
+		if affected < 1 {
+			return ErrTrackNotFound
+		}
+
+		return nil
+	}
+
+	return m.executeDBJobAndWait(work)
+}
+
+// Delete implements Bookmarker.
+func (m *manager) Delete(ctx context.Context, trackID int64) error {
+	const deleteBookmarkQuery = `
+		DELETE FROM bookmarks
+		WHERE track_id = @track_id
+	`
+
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx, deleteBookmarkQuery,
+			sql.Named("track_id", trackID),
+		)
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+
+		if affected < 1 {
+			return ErrNotFound
+		}
+
+		return nil
+	}
+
+	return m.executeDBJobAndWait(work)
+}
+
+// populateTracks sets the track information for all bookmarks.
+func populateTracks(ctx context.Context, db *sql.DB, bookmarks []Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	tracksQueryArg := make([]any, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		tracksQueryArg = append(tracksQueryArg, bookmark.Track.ID)
+	}
+
+	queryTracksWhere := []string{
+		"t.id IN (" + strings.TrimSuffix(
+			strings.Repeat("?,", len(tracksQueryArg)),
+			",",
+		) + ")",
+	}
+
+	rows, err := library.QueryTracks(ctx, db, queryTracksWhere, "", tracksQueryArg)
+	if err != nil {
+		return fmt.Errorf("error selecting bookmarked tracks: %w", err)
+	}
+	defer rows.Close()
+
+	// tracks is a map from track ID => track info.
+	tracks := make(map[int64]library.TrackInfo, len(bookmarks))
+	for rows.Next() {
+		track, err := library.ScanTrack(rows)
+		if err != nil {
+			return fmt.Errorf("error while scanning a track: %w", err)
+		}
+
+		tracks[track.ID] = track
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over tracks: %w", err)
+	}
+
+	for ind := range bookmarks {
+		if track, found := tracks[bookmarks[ind].Track.ID]; found {
+			bookmarks[ind].Track = track
+		}
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBookmark(row rowScanner) (Bookmark, error) {
+	var (
+		bookmark  Bookmark
+		comment   sql.NullString
+		createdAt int64
+		updatedAt int64
+	)
+
+	err := row.Scan(
+		&bookmark.Track.ID,
+		&bookmark.Position,
+		&comment,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return bookmark, err
+	}
+
+	bookmark.Comment = comment.String
+	bookmark.CreatedAt = time.Unix(createdAt, 0)
+	bookmark.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return bookmark, nil
+}
+
+func nullString(val string) sql.NullString {
+	return sql.NullString{String: val, Valid: val != ""}
+}
@@ -14,8 +14,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os/user"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ironsmile/euterpe/src/helpers"
@@ -50,6 +52,22 @@ var defaultConfig = Config{
 	ReadTimeout:    15,
 	WriteTimeout:   1200,
 	MaxHeadersSize: 1048576,
+	LoginAttempts: LoginAttempts{
+		MaxFailures:     10,
+		InitialBackoff:  time.Second,
+		MaxBackoff:      time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		ForgetAfter:     time.Hour,
+	},
+	Podcasts: PodcastsSection{
+		Directory:       "podcasts",
+		RefreshInterval: time.Hour,
+		KeepEpisodes:    10,
+		DownloadNewest:  1,
+		DownloadTimeout: time.Hour,
+		MaxEpisodeSize:  2 * 1024 * 1024 * 1024,
+		MaxImageSize:    20 * 1024 * 1024,
+	},
 }
 
 // Config contains representation for everything in config.json
@@ -70,6 +88,18 @@ type Config struct {
 	DownloadArtwork  bool        `json:"download_artwork,omitempty"`
 	DiscogsAuthToken string      `json:"discogs_auth_token,omitempty"`
 	AccessLog        bool        `json:"access_log,omitempty"`
+
+	// LoginAttempts configures the protection against brute-forcing the
+	// login credentials.
+	LoginAttempts LoginAttempts `json:"login_attempts,omitempty"`
+
+	// TrustedProxies is a list with IP networks of reverse proxies. Only for
+	// requests coming from them headers such as X-Forwarded-For are used for
+	// determining the client IP address.
+	TrustedProxies CIDRList `json:"trusted_proxies,omitempty"`
+
+	// Podcasts configures the podcast subscriptions.
+	Podcasts PodcastsSection `json:"podcasts,omitempty"`
 }
 
 // ScanSection is used for merging the two configs. Its purpose is to essentially
@@ -120,6 +150,234 @@ func (ss *ScanSection) UnmarshalJSON(input []byte) error {
 	return nil
 }
 
+// LoginAttempts is the configuration for limiting failed login attempts. After every
+// failed attempt further attempts from the same client are rejected for a duration
+// which starts at InitialBackoff and doubles with every subsequent failure up to
+// MaxBackoff. After MaxFailures failed attempts the client is locked out for
+// LockoutDuration.
+type LoginAttempts struct {
+	Disable         bool          `json:"disable,omitempty"`
+	MaxFailures     int           `json:"max_failures,omitempty"`
+	InitialBackoff  time.Duration `json:"initial_backoff,omitempty"`
+	MaxBackoff      time.Duration `json:"max_backoff,omitempty"`
+	LockoutDuration time.Duration `json:"lockout_duration,omitempty"`
+
+	// ForgetAfter is the duration after which failed attempts are forgotten
+	// when there are no new failures.
+	ForgetAfter time.Duration `json:"forget_after,omitempty"`
+}
+
+// UnmarshalJSON parses a JSON and populates its LoginAttempts. Satisfies the
+// Unmarshaller interface. Properties missing in the JSON keep their values.
+func (la *LoginAttempts) UnmarshalJSON(input []byte) error {
+	laProxy := &struct {
+		Disable         bool   `json:"disable"`
+		MaxFailures     int    `json:"max_failures"`
+		InitialBackoff  string `json:"initial_backoff"`
+		MaxBackoff      string `json:"max_backoff"`
+		LockoutDuration string `json:"lockout_duration"`
+		ForgetAfter     string `json:"forget_after"`
+	}{}
+	if err := json.Unmarshal(input, laProxy); err != nil {
+		return fmt.Errorf("wrong JSON value: %w", err)
+	}
+
+	la.Disable = laProxy.Disable
+
+	if laProxy.MaxFailures < 0 {
+		return errors.New("max_failures must be a positive integer")
+	} else if laProxy.MaxFailures > 0 {
+		la.MaxFailures = laProxy.MaxFailures
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"initial_backoff", laProxy.InitialBackoff, &la.InitialBackoff},
+		{"max_backoff", laProxy.MaxBackoff, &la.MaxBackoff},
+		{"lockout_duration", laProxy.LockoutDuration, &la.LockoutDuration},
+		{"forget_after", laProxy.ForgetAfter, &la.ForgetAfter},
+	}
+	for _, dur := range durations {
+		if dur.value == "" {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(dur.value)
+		if err != nil {
+			return fmt.Errorf("wrong value for %s: %w", dur.name, err)
+		}
+		*dur.dest = parsed
+	}
+
+	return nil
+}
+
+// PodcastsSection is the configuration for podcast subscriptions.
+type PodcastsSection struct {
+	// Directory is where podcast episodes and channel images are downloaded. A
+	// relative path is relative to the Euterpe user directory.
+	Directory string `json:"directory,omitempty"`
+
+	// RefreshInterval is how often all channels are checked for new episodes.
+	// Zero disables the periodic refresh.
+	RefreshInterval time.Duration `json:"refresh_interval,omitempty"`
+
+	// KeepEpisodes is the number of downloaded episodes kept for every channel
+	// unless the channel has its own limit. Older episodes are deleted. Zero
+	// means keeping all of them.
+	KeepEpisodes int `json:"keep_episodes,omitempty"`
+
+	// DownloadNewest is the number of newest episodes which are downloaded
+	// automatically when they are found in a channel's feed.
+	DownloadNewest int `json:"download_newest,omitempty"`
+
+	// DownloadTimeout is the maximum time for downloading a single feed, episode
+	// or channel image. Zero means no timeout.
+	DownloadTimeout time.Duration `json:"download_timeout,omitempty"`
+
+	// MaxEpisodeSize is the maximum size of an episode file in bytes. Larger
+	// episodes are not downloaded. Zero means no limit.
+	MaxEpisodeSize int64 `json:"max_episode_size,omitempty"`
+
+	// MaxImageSize is the maximum size of a channel image in bytes. Zero means
+	// no limit.
+	MaxImageSize int64 `json:"max_image_size,omitempty"`
+}
+
+// UnmarshalJSON parses a JSON and populates its PodcastsSection. Satisfies the
+// Unmarshaller interface. Properties missing in the JSON keep their values.
+func (ps *PodcastsSection) UnmarshalJSON(input []byte) error {
+	psProxy := &struct {
+		Directory       string `json:"directory"`
+		RefreshInterval string `json:"refresh_interval"`
+		KeepEpisodes    *int   `json:"keep_episodes"`
+		DownloadNewest  *int   `json:"download_newest"`
+		DownloadTimeout string `json:"download_timeout"`
+		MaxEpisodeSize  *int64 `json:"max_episode_size"`
+		MaxImageSize    *int64 `json:"max_image_size"`
+	}{}
+	if err := json.Unmarshal(input, psProxy); err != nil {
+		return fmt.Errorf("wrong JSON value: %w", err)
+	}
+
+	if psProxy.Directory != "" {
+		ps.Directory = psProxy.Directory
+	}
+
+	if psProxy.RefreshInterval != "" {
+		interval, err := time.ParseDuration(psProxy.RefreshInterval)
+		if err != nil {
+			return fmt.Errorf("wrong value for refresh_interval: %w", err)
+		}
+		ps.RefreshInterval = interval
+	}
+
+	if psProxy.KeepEpisodes != nil {
+		if *psProxy.KeepEpisodes < 0 {
+			return errors.New("keep_episodes must be a positive integer")
+		}
+		ps.KeepEpisodes = *psProxy.KeepEpisodes
+	}
+
+	if psProxy.DownloadNewest != nil {
+		if *psProxy.DownloadNewest < 0 {
+			return errors.New("download_newest must be a positive integer")
+		}
+		ps.DownloadNewest = *psProxy.DownloadNewest
+	}
+
+	if psProxy.DownloadTimeout != "" {
+		timeout, err := time.ParseDuration(psProxy.DownloadTimeout)
+		if err != nil {
+			return fmt.Errorf("wrong value for download_timeout: %w", err)
+		}
+		if timeout < 0 {
+			return errors.New("download_timeout must not be negative")
+		}
+		ps.DownloadTimeout = timeout
+	}
+
+	if psProxy.MaxEpisodeSize != nil {
+		if *psProxy.MaxEpisodeSize < 0 {
+			return errors.New("max_episode_size must be a positive integer")
+		}
+		ps.MaxEpisodeSize = *psProxy.MaxEpisodeSize
+	}
+
+	if psProxy.MaxImageSize != nil {
+		if *psProxy.MaxImageSize < 0 {
+			return errors.New("max_image_size must be a positive integer")
+		}
+		ps.MaxImageSize = *psProxy.MaxImageSize
+	}
+
+	return nil
+}
+
+// CIDRList is a list of IP networks. In JSON it is represented as a list of
+// strings where each one is either a network in CIDR notation or a single IP
+// address.
+type CIDRList []*net.IPNet
+
+// UnmarshalJSON parses a JSON list of networks. Satisfies the Unmarshaller interface.
+func (cl *CIDRList) UnmarshalJSON(input []byte) error {
+	var networks []string
+	if err := json.Unmarshal(input, &networks); err != nil {
+		return fmt.Errorf("wrong JSON value: %w", err)
+	}
+
+	list := make(CIDRList, 0, len(networks))
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			ip := net.ParseIP(network)
+			if ip == nil {
+				return fmt.Errorf("wrong IP address `%s`", network)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+
+			list = append(list, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return fmt.Errorf("wrong network `%s`: %w", network, err)
+		}
+		list = append(list, ipNet)
+	}
+
+	*cl = list
+	return nil
+}
+
+// MarshalJSON encodes the list as JSON list of strings in CIDR notation. Satisfies
+// the Marshaller interface.
+func (cl CIDRList) MarshalJSON() ([]byte, error) {
+	networks := make([]string, 0, len(cl))
+	for _, ipNet := range cl {
+		networks = append(networks, ipNet.String())
+	}
+	return json.Marshal(networks)
+}
+
+// Contains returns true if ip is in any of the networks in the list.
+func (cl CIDRList) Contains(ip net.IP) bool {
+	for _, ipNet := range cl {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // Cert represents a configuration for TLS certificate
 type Cert struct {
 	Crt string `json:"crt,omitempty"`
@@ -128,9 +386,55 @@ type Cert struct {
 
 // Auth represents a configuration HTTP Basic authentication
 type Auth struct {
-	User     string `json:"user,omitempty"`
+	User string `json:"user,omitempty"`
+
+	// Password is the password in plain text. It is deprecated in favour of
+	// PasswordHash and used only when there is no hash configured.
 	Password string `json:"password,omitempty"`
-	Secret   string `json:"secret"`
+
+	// PasswordHash is an argon2id or bcrypt hash of the password.
+	PasswordHash string `json:"password_hash,omitempty"`
+
+	// Secret is used for signing authentication tokens.
+	Secret string `json:"secret"`
+
+	// SubsonicTokenAuth enables the legacy Subsonic authentication with token
+	// and salt. It requires the clear text password to be known by the server
+	// so it is stored encrypted in SubsonicSecret.
+	SubsonicTokenAuth bool `json:"subsonic_token_auth,omitempty"`
+
+	// SubsonicSecret is the password encrypted with a key derived from Secret.
+	SubsonicSecret string `json:"subsonic_secret,omitempty"`
+
+	// ProxyHeader is the name of a HTTP header such as "Remote-User" which an
+	// authenticating reverse proxy sets to the name of the logged in user.
+	ProxyHeader string `json:"proxy_header,omitempty"`
+
+	// ProxyNetworks lists the networks from which ProxyHeader is trusted. The
+	// header is ignored for requests coming from anywhere else.
+	ProxyNetworks CIDRList `json:"proxy_networks,omitempty"`
+
+	// MusicFolders limits the user to these library directories. Every item is
+	// either a path from the "libraries" list or the name of the music folder.
+	// Names are unique, see the library package for how they are chosen. An
+	// empty list gives access to all libraries.
+	MusicFolders []string `json:"music_folders,omitempty"`
+}
+
+// CanAccessMusicFolder returns true if the user is allowed to access the library
+// directory with this name and file system path.
+func (a Auth) CanAccessMusicFolder(name, path string) bool {
+	if len(a.MusicFolders) == 0 {
+		return true
+	}
+
+	for _, allowed := range a.MusicFolders {
+		if allowed == name || allowed == path {
+			return true
+		}
+	}
+
+	return false
 }
 
 // FindAndParse actually finds the configuration file, parsing it and merging it on
@@ -213,17 +517,106 @@ func copyDefaultOverUser(appfs afero.Fs) error {
 		},
 	}
 
+	return writeUserConfig(appfs, UserConfigPath(appfs), &userCfg)
+}
+
+// SetPassword stores a hash of password in the user's configuration file and removes
+// the plain text password from it. When the Subsonic token authentication is
+// enabled the password is also stored encrypted in the "subsonic_secret" property.
+//
+// All other properties of the configuration file are preserved.
+func SetPassword(appfs afero.Fs, password string) error {
+	if !userConfigExists(appfs) {
+		if err := copyDefaultOverUser(appfs); err != nil {
+			return err
+		}
+	}
+
 	userCfgPath := UserConfigPath(appfs)
-	fh, err := appfs.Create(userCfgPath)
+	cfgBytes, err := afero.ReadFile(appfs, userCfgPath)
 	if err != nil {
-		return fmt.Errorf("create config `%s`: %w", userCfgPath, err)
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	userCfg := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(cfgBytes, &userCfg); err != nil {
+		return fmt.Errorf("decoding config: %w", err)
+	}
+
+	var auth Auth
+	authSection := make(map[string]any)
+	if rawAuth, ok := userCfg["authentication"]; ok {
+		if err := json.Unmarshal(rawAuth, &authSection); err != nil {
+			return fmt.Errorf("decoding authentication section: %w", err)
+		}
+		if err := json.Unmarshal(rawAuth, &auth); err != nil {
+			return fmt.Errorf("decoding authentication section: %w", err)
+		}
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hashing password: %w", err)
+	}
+
+	authSection["password_hash"] = hash
+	delete(authSection, "password")
+	delete(authSection, "subsonic_secret")
+
+	if auth.SubsonicTokenAuth {
+		subsonicSecret, err := EncryptSubsonicSecret(auth.Secret, password)
+		if err != nil {
+			return fmt.Errorf("encrypting subsonic secret: %w", err)
+		}
+		authSection["subsonic_secret"] = subsonicSecret
+	}
+
+	rawAuth, err := json.Marshal(authSection)
+	if err != nil {
+		return fmt.Errorf("encoding authentication section: %w", err)
+	}
+	userCfg["authentication"] = rawAuth
+
+	return writeUserConfig(appfs, userCfgPath, userCfg)
+}
+
+// writeUserConfig encodes cfg as JSON into the configuration file at path. The
+// file contains secrets so it is readable only by its owner. It is first written
+// into a temporary file which is then renamed. This way the configuration is
+// never left half written.
+func writeUserConfig(appfs afero.Fs, path string, cfg any) error {
+	fh, err := afero.TempFile(appfs, filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create config `%s`: %w", path, err)
+	}
+	tmpPath := fh.Name()
+	defer func() {
+		_ = appfs.Remove(tmpPath)
+	}()
+
+	if err := appfs.Chmod(tmpPath, 0600); err != nil {
+		fh.Close()
+		return fmt.Errorf("setting config permissions: %w", err)
 	}
-	defer fh.Close()
 
 	enc := json.NewEncoder(fh)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(&userCfg); err != nil {
-		return fmt.Errorf("encoding default config `%s`: %w", userCfgPath, err)
+	if err := enc.Encode(cfg); err != nil {
+		fh.Close()
+		return fmt.Errorf("encoding config `%s`: %w", path, err)
+	}
+
+	if err := fh.Sync(); err != nil {
+		fh.Close()
+		return fmt.Errorf("syncing config `%s`: %w", path, err)
+	}
+
+	if err := fh.Close(); err != nil {
+		return fmt.Errorf("closing config `%s`: %w", path, err)
+	}
+
+	if err := appfs.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing config `%s`: %w", path, err)
 	}
 
 	return nil
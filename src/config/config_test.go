@@ -113,6 +113,57 @@ func TestScanSectionErrors(t *testing.T) {
 	}
 }
 
+// TestPodcastsSectionUnmarshalJSON makes sure that decoding the JSON for the
+// "podcasts" configuration key keeps the values which are missing in it.
+func TestPodcastsSectionUnmarshalJSON(t *testing.T) {
+	ps := config.PodcastsSection{
+		Directory:       "podcasts",
+		RefreshInterval: time.Hour,
+		KeepEpisodes:    10,
+		DownloadNewest:  1,
+		DownloadTimeout: time.Hour,
+		MaxEpisodeSize:  1024,
+		MaxImageSize:    512,
+	}
+
+	err := json.Unmarshal([]byte(`{
+		"refresh_interval": "30m",
+		"keep_episodes": 0,
+		"download_timeout": "10m",
+		"max_image_size": 0
+	}`), &ps)
+	if err != nil {
+		t.Fatalf("decoding PodcastsSection JSON failed: %s", err)
+	}
+
+	expected := config.PodcastsSection{
+		Directory:       "podcasts",
+		RefreshInterval: 30 * time.Minute,
+		KeepEpisodes:    0,
+		DownloadNewest:  1,
+		DownloadTimeout: 10 * time.Minute,
+		MaxEpisodeSize:  1024,
+		MaxImageSize:    0,
+	}
+	if ps != expected {
+		t.Errorf("expected `%+v` but got `%+v`", expected, ps)
+	}
+
+	for _, cfgString := range []string{
+		`{"refresh_interval": "baba"}`,
+		`{"keep_episodes": -1}`,
+		`{"download_newest": -1}`,
+		`{"download_timeout": "baba"}`,
+		`{"download_timeout": "-1s"}`,
+		`{"max_episode_size": -1}`,
+		`{"max_image_size": -1}`,
+	} {
+		if err := json.Unmarshal([]byte(cfgString), &ps); err == nil {
+			t.Errorf("expected an error for `%s` but got none", cfgString)
+		}
+	}
+}
+
 // TestFindAndParseCreatesConfig makes sure that a new configuration file is created
 // when there was not when run.
 func TestFindAndParseCreatesConfig(t *testing.T) {
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Parameters used for newly created argon2id password hashes. They follow the
+// recommendations in RFC 9106 for memory constrained environments.
+const (
+	argon2Time    uint32 = 1
+	argon2Memory  uint32 = 64 * 1024
+	argon2Threads uint8  = 4
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen        = 16
+)
+
+// Limits for the parameters of argon2id hashes which are verified. The parameters
+// come from the hash itself and are used on every login attempt so hashes which
+// would use too much memory or time are not supported.
+const (
+	argon2MaxMemory  uint32 = 256 * 1024
+	argon2MaxTime    uint32 = 16
+	argon2MinSaltLen        = 8
+	argon2MinKeyLen         = 4
+	argon2MaxKeyLen         = 64
+)
+
+const (
+	// verifiedCacheSize is the maximum number of remembered successful password
+	// hash verifications.
+	verifiedCacheSize = 16
+
+	// verifiedCacheTTL is for how long a successful verification is remembered.
+	verifiedCacheTTL = 10 * time.Minute
+)
+
+// verified remembers recent successful password hash verifications. Clients such
+// as the Subsonic ones send the password with every request and computing the
+// hash every time is prohibitively expensive. Only SHA-256 sums of the hash and
+// password pairs are kept.
+var verified = struct {
+	sync.Mutex
+	entries map[[sha256.Size]byte]time.Time
+}{
+	entries: make(map[[sha256.Size]byte]time.Time),
+}
+
+// ErrUnsupportedHash is returned when a password hash is in a format which is
+// not supported.
+var ErrUnsupportedHash = errors.New("unsupported password hash format")
+
+// HashPassword returns an argon2id hash of password encoded in the PHC string
+// format. It is suitable for the "password_hash" property of the configuration.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generating salt: %w", err)
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		salt,
+		argon2Time,
+		argon2Memory,
+		argon2Threads,
+		argon2KeyLen,
+	)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argon2Memory,
+		argon2Time,
+		argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// isPasswordHash returns true when s looks like one of the supported password
+// hashes.
+func isPasswordHash(s string) bool {
+	for _, prefix := range []string{"$argon2id$", "$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VerifyPasswordHash checks whether password matches the hash. Supported are
+// argon2id hashes in the PHC string format and bcrypt hashes.
+func VerifyPasswordHash(hash, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(hash, password)
+	case strings.HasPrefix(hash, "$2a$"),
+		strings.HasPrefix(hash, "$2b$"),
+		strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		} else if err != nil {
+			return false, fmt.Errorf("bcrypt: %w", err)
+		}
+		return true, nil
+	default:
+		return false, ErrUnsupportedHash
+	}
+}
+
+func verifyArgon2id(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, fmt.Errorf("parsing argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	var (
+		memory, time uint32
+		threads      uint8
+	)
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	if err != nil {
+		return false, fmt.Errorf("parsing argon2id parameters: %w", err)
+	}
+	if threads == 0 || time == 0 || time > argon2MaxTime ||
+		memory < 8*uint32(threads) || memory > argon2MaxMemory {
+		return false, fmt.Errorf(
+			"%w: argon2id parameters m=%d,t=%d,p=%d are out of range",
+			ErrUnsupportedHash, memory, time, threads,
+		)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("decoding argon2id salt: %w", err)
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("decoding argon2id key: %w", err)
+	}
+
+	if len(salt) < argon2MinSaltLen ||
+		len(expected) < argon2MinKeyLen || len(expected) > argon2MaxKeyLen {
+		return false, fmt.Errorf(
+			"%w: argon2id salt or key length is out of range",
+			ErrUnsupportedHash,
+		)
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		salt,
+		time,
+		memory,
+		threads,
+		uint32(len(expected)),
+	)
+
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
+
+// CheckCredentials returns true when user and pass match the configured ones. The
+// password hash is used when configured. Otherwise the plain text password is
+// used.
+//
+// The user check is carefully orchestrated so that it will take constant time for
+// wrong and correct usernames. This mitigates simple timing attacks.
+func (a Auth) CheckCredentials(user, pass string) bool {
+	userCheck := subtle.ConstantTimeCompare([]byte(user), []byte(a.User))
+
+	var passCheck int
+	if a.PasswordHash != "" {
+		if verifyPasswordHashCached(a.PasswordHash, pass) {
+			passCheck = 1
+		}
+	} else {
+		passCheck = subtle.ConstantTimeCompare([]byte(pass), []byte(a.Password))
+	}
+
+	return userCheck&passCheck == 1
+}
+
+// verifyPasswordHashCached is like VerifyPasswordHash but remembers successful
+// verifications for a while.
+func verifyPasswordHashCached(hash, password string) bool {
+	key := sha256.Sum256([]byte(hash + "\x00" + password))
+	now := time.Now()
+
+	verified.Lock()
+	expires, ok := verified.entries[key]
+	verified.Unlock()
+
+	if ok && now.Before(expires) {
+		return true
+	}
+
+	match, err := VerifyPasswordHash(hash, password)
+	if err != nil || !match {
+		return false
+	}
+
+	verified.Lock()
+	defer verified.Unlock()
+
+	for k, exp := range verified.entries {
+		if !now.Before(exp) {
+			delete(verified.entries, k)
+		}
+	}
+	for k := range verified.entries {
+		if len(verified.entries) < verifiedCacheSize {
+			break
+		}
+		delete(verified.entries, k)
+	}
+	verified.entries[key] = now.Add(verifiedCacheTTL)
+
+	return true
+}
+
+// HasPlainTextPassword returns true when the configuration still uses a password
+// stored in plain text.
+func (a Auth) HasPlainTextPassword() bool {
+	return a.Password != ""
+}
+
+// Validate returns an error when the authentication configuration could not be
+// used safely. This is when the Subsonic token authentication is enabled without
+// a "subsonic_secret" or when the plain text "password" is actually a password
+// hash.
+func (a Auth) Validate() error {
+	if isPasswordHash(a.Password) {
+		return errors.New("password contains a password hash, " +
+			"move it to password_hash or run with -set-password")
+	}
+
+	if a.SubsonicTokenAuth && a.SubsonicSecret == "" {
+		return errors.New("subsonic_token_auth requires subsonic_secret, " +
+			"run with -set-password in order to create it")
+	}
+
+	return nil
+}
+
+// SubsonicPassword returns the clear text password which is used for the Subsonic
+// token authentication. It is decrypted from the "subsonic_secret" property using
+// the "secret" property. When there is no such secret the plain text password
+// is returned.
+//
+// An error is returned when there is no password at all. Tokens created with an
+// empty password could be made by anyone. The same goes for a plain text password
+// which is actually a hash since the hash is not secret enough.
+func (a Auth) SubsonicPassword() (string, error) {
+	if a.SubsonicSecret == "" {
+		if a.Password == "" {
+			return "", errors.New("no password for the Subsonic token authentication")
+		}
+		if isPasswordHash(a.Password) {
+			return "", errors.New("password is a password hash and could not be " +
+				"used for the Subsonic token authentication")
+		}
+		return a.Password, nil
+	}
+
+	gcm, err := subsonicSecretCipher(a.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := hex.DecodeString(a.SubsonicSecret)
+	if err != nil {
+		return "", fmt.Errorf("decoding subsonic_secret: %w", err)
+	}
+
+	if len(encrypted) < gcm.NonceSize() {
+		return "", errors.New("subsonic_secret is too short")
+	}
+
+	nonce, encrypted := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, encrypted, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting subsonic_secret: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// EncryptSubsonicSecret encrypts password with a key derived from secret. The
+// result is suitable for the "subsonic_secret" property of the configuration.
+func EncryptSubsonicSecret(secret, password string) (string, error) {
+	gcm, err := subsonicSecretCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	encrypted := gcm.Seal(nonce, nonce, []byte(password), nil)
+	return hex.EncodeToString(encrypted), nil
+}
+
+func subsonicSecretCipher(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, errors.New("authentication secret is not set")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCM: %w", err)
+	}
+
+	return gcm, nil
+}
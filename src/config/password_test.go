@@ -0,0 +1,312 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestCheckCredentials makes sure that both password hashes and plain text
+// passwords are accepted by Auth.CheckCredentials.
+func TestCheckCredentials(t *testing.T) {
+	const (
+		user = "test-user"
+		pass = "test-pass"
+	)
+
+	argonHash, err := config.HashPassword(pass)
+	if err != nil {
+		t.Fatalf("hashing password: %s", err)
+	}
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt hashing password: %s", err)
+	}
+
+	tests := []struct {
+		desc     string
+		auth     config.Auth
+		user     string
+		pass     string
+		expected bool
+	}{
+		{
+			desc:     "plain text password",
+			auth:     config.Auth{User: user, Password: pass},
+			user:     user,
+			pass:     pass,
+			expected: true,
+		},
+		{
+			desc:     "argon2id hash",
+			auth:     config.Auth{User: user, PasswordHash: argonHash},
+			user:     user,
+			pass:     pass,
+			expected: true,
+		},
+		{
+			desc:     "bcrypt hash",
+			auth:     config.Auth{User: user, PasswordHash: string(bcryptHash)},
+			user:     user,
+			pass:     pass,
+			expected: true,
+		},
+		{
+			desc:     "hash takes precedence over plain text",
+			auth:     config.Auth{User: user, Password: pass, PasswordHash: argonHash},
+			user:     user,
+			pass:     "wrong-pass",
+			expected: false,
+		},
+		{
+			desc:     "wrong password for argon2id hash",
+			auth:     config.Auth{User: user, PasswordHash: argonHash},
+			user:     user,
+			pass:     "wrong-pass",
+			expected: false,
+		},
+		{
+			desc:     "wrong password for bcrypt hash",
+			auth:     config.Auth{User: user, PasswordHash: string(bcryptHash)},
+			user:     user,
+			pass:     "wrong-pass",
+			expected: false,
+		},
+		{
+			desc:     "wrong user",
+			auth:     config.Auth{User: user, PasswordHash: argonHash},
+			user:     "wrong-user",
+			pass:     pass,
+			expected: false,
+		},
+		{
+			desc:     "unsupported hash",
+			auth:     config.Auth{User: user, PasswordHash: "$md5$something"},
+			user:     user,
+			pass:     pass,
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual := test.auth.CheckCredentials(test.user, test.pass)
+			if actual != test.expected {
+				t.Errorf("expected %t but got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+// TestVerifyPasswordHashParameters makes sure that argon2id hashes with parameters
+// which would break or exhaust the server are rejected before hashing.
+func TestVerifyPasswordHashParameters(t *testing.T) {
+	const (
+		salt = "c29tZXNhbHRzb21lc2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+
+	tests := []struct {
+		desc   string
+		params string
+	}{
+		{desc: "zero time", params: "m=65536,t=0,p=4"},
+		{desc: "zero threads", params: "m=65536,t=1,p=0"},
+		{desc: "oversized memory", params: "m=4294967295,t=1,p=4"},
+		{desc: "too little memory", params: "m=8,t=1,p=4"},
+		{desc: "too much time", params: "m=65536,t=1000000,p=4"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			hash := fmt.Sprintf("$argon2id$v=19$%s$%s$%s", test.params, salt, key)
+			_, err := config.VerifyPasswordHash(hash, "test-pass")
+			if !errors.Is(err, config.ErrUnsupportedHash) {
+				t.Errorf("expected ErrUnsupportedHash but got %v", err)
+			}
+		})
+	}
+}
+
+// TestSubsonicSecret checks that the encrypted Subsonic secret could be decrypted
+// back to the original password.
+func TestSubsonicSecret(t *testing.T) {
+	const (
+		secret = "some-secret"
+		pass   = "test-pass"
+	)
+
+	encrypted, err := config.EncryptSubsonicSecret(secret, pass)
+	if err != nil {
+		t.Fatalf("encrypting secret: %s", err)
+	}
+
+	auth := config.Auth{
+		Secret:         secret,
+		SubsonicSecret: encrypted,
+	}
+
+	decrypted, err := auth.SubsonicPassword()
+	if err != nil {
+		t.Fatalf("decrypting secret: %s", err)
+	}
+
+	if decrypted != pass {
+		t.Errorf("expected decrypted password `%s` but got `%s`", pass, decrypted)
+	}
+
+	auth.Secret = "other-secret"
+	if _, err := auth.SubsonicPassword(); err == nil {
+		t.Errorf("expected an error when decrypting with the wrong secret")
+	}
+}
+
+// TestSetPassword makes sure that SetPassword replaces the plain text password
+// in the configuration file and keeps the rest of the configuration intact.
+func TestSetPassword(t *testing.T) {
+	testfs := afero.NewMemMapFs()
+	configPath := config.UserConfigPath(testfs)
+
+	const (
+		listenAddress = "1.2.3.4:1234"
+		user          = "test-user"
+		newPass       = "new-pass"
+	)
+
+	err := afero.WriteFile(testfs, configPath, []byte(`{
+		"listen": "`+listenAddress+`",
+		"basic_authenticate": true,
+		"library_scan": {
+			"initial_wait_duration": "1s"
+		},
+		"authentication": {
+			"user": "`+user+`",
+			"password": "old-pass",
+			"secret": "some-secret",
+			"subsonic_token_auth": true
+		}
+	}`), 0600)
+	if err != nil {
+		t.Fatalf("error setting up test, writing config: %s", err)
+	}
+
+	if err := config.SetPassword(testfs, newPass); err != nil {
+		t.Fatalf("error setting password: %s", err)
+	}
+
+	cfg, err := config.FindAndParse(testfs)
+	if err != nil {
+		t.Fatalf("error finding and parsing configuration file: %s", err)
+	}
+
+	if cfg.Listen != listenAddress {
+		t.Errorf("expected listen address `%s` but got `%s`", listenAddress, cfg.Listen)
+	}
+
+	if cfg.LibraryScan.InitialWait.String() != "1s" {
+		t.Errorf("expected initial wait 1s but got %s", cfg.LibraryScan.InitialWait)
+	}
+
+	if cfg.Authenticate.HasPlainTextPassword() {
+		t.Errorf("expected plain text password to be removed")
+	}
+
+	if !cfg.Authenticate.CheckCredentials(user, newPass) {
+		t.Errorf("expected the new password to be accepted")
+	}
+
+	if cfg.Authenticate.CheckCredentials(user, "old-pass") {
+		t.Errorf("expected the old password to be rejected")
+	}
+
+	subsonicPass, err := cfg.Authenticate.SubsonicPassword()
+	if err != nil {
+		t.Fatalf("error getting subsonic password: %s", err)
+	}
+
+	if subsonicPass != newPass {
+		t.Errorf("expected subsonic password `%s` but got `%s`", newPass, subsonicPass)
+	}
+
+	st, err := testfs.Stat(configPath)
+	if err != nil {
+		t.Fatalf("error getting config file info: %s", err)
+	}
+
+	if perm := st.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected config file permissions 0600 but got %o", perm)
+	}
+
+	files, err := afero.ReadDir(testfs, filepath.Dir(configPath))
+	if err != nil {
+		t.Fatalf("error listing config directory: %s", err)
+	}
+
+	if len(files) != 1 {
+		t.Errorf("expected only the config file in its directory but got %d files",
+			len(files))
+	}
+}
+
+// TestSubsonicTokenAuthWithoutSecret makes sure that Subsonic token authentication
+// could not be used without a password. Tokens for an empty password could be
+// created by anyone.
+func TestSubsonicTokenAuthWithoutSecret(t *testing.T) {
+	passwordHash, err := config.HashPassword("test-pass")
+	if err != nil {
+		t.Fatalf("hashing password: %s", err)
+	}
+
+	auth := config.Auth{
+		User:              "test-user",
+		PasswordHash:      passwordHash,
+		Secret:            "some-secret",
+		SubsonicTokenAuth: true,
+	}
+
+	if err := auth.Validate(); err == nil {
+		t.Errorf("expected validation error for token auth without subsonic_secret")
+	}
+
+	if _, err := auth.SubsonicPassword(); err == nil {
+		t.Errorf("expected an error for getting an empty Subsonic password")
+	}
+
+	auth.SubsonicSecret, err = config.EncryptSubsonicSecret(auth.Secret, "test-pass")
+	if err != nil {
+		t.Fatalf("encrypting secret: %s", err)
+	}
+
+	if err := auth.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+}
+
+// TestHashInPlainTextPassword makes sure that a password hash in the plain text
+// password property is rejected instead of being used for Subsonic tokens.
+func TestHashInPlainTextPassword(t *testing.T) {
+	passwordHash, err := config.HashPassword("test-pass")
+	if err != nil {
+		t.Fatalf("hashing password: %s", err)
+	}
+
+	auth := config.Auth{
+		User:     "test-user",
+		Password: passwordHash,
+		Secret:   "some-secret",
+	}
+
+	if err := auth.Validate(); err == nil {
+		t.Errorf("expected validation error for a hash in the password property")
+	}
+
+	if _, err := auth.SubsonicPassword(); err == nil {
+		t.Errorf("expected an error for using a hash as the Subsonic password")
+	}
+}
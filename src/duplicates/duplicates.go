@@ -0,0 +1,115 @@
+// Package duplicates finds tracks in the library which are the same song. Such
+// are for example several rips of the same album or the same album in different
+// formats. The stats and playlist references of the duplicates could be merged
+// into a single track of the group which is to be kept.
+package duplicates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// DefaultTolerance is the maximum difference between the durations of tracks
+// with the same artist and title for them to be considered duplicates when no
+// other tolerance is given.
+const DefaultTolerance = 2 * time.Second
+
+//counterfeiter:generate . Finder
+
+// Finder is the interface for finding and merging duplicate tracks.
+type Finder interface {
+	// Find returns all groups of duplicate tracks in the library.
+	Find(ctx context.Context, args FindArgs) ([]Group, error)
+
+	// Merge moves the plays, ratings, favourites, bookmarks and playlist
+	// entries of the tracks with IDs `duplicateIDs` to the track with ID
+	// `keeperID`. The duplicates themselves stay in the library.
+	//
+	// Returns ErrTrackNotFound when any of the tracks does not exist.
+	Merge(ctx context.Context, keeperID int64, duplicateIDs []int64) error
+}
+
+// FindArgs are the arguments for finding duplicate tracks.
+type FindArgs struct {
+	// Tolerance is the maximum difference between the durations of tracks
+	// with the same artist and title. When zero DefaultTolerance is used.
+	Tolerance time.Duration
+}
+
+// Match is the way in which the tracks of a group were found to be the same.
+type Match string
+
+const (
+	// MatchAudio is for tracks with exactly the same audio data. They are
+	// usually the same file in different places with possibly different tags.
+	MatchAudio Match = "audio"
+
+	// MatchMetadata is for tracks with the same artist and title and similar
+	// durations. They are usually different rips or formats of the same song.
+	MatchMetadata Match = "metadata"
+)
+
+// Group is a set of tracks which are the same song.
+type Group struct {
+	// Match is the way in which the tracks were found to be the same.
+	Match Match `json:"match"`
+
+	// Tracks are the duplicates, ordered by their IDs.
+	Tracks []Track `json:"tracks"`
+}
+
+// Track is a single track of a duplicates group.
+type Track struct {
+	library.TrackInfo
+
+	// Path is the file system path of the track's file.
+	Path string `json:"path"`
+}
+
+var (
+	// ErrTrackNotFound is returned when merging tracks which do not exist.
+	ErrTrackNotFound = errors.New("track not found")
+
+	// ErrNoDuplicates is returned when merging without any duplicates or when
+	// the keeper is among them.
+	ErrNoDuplicates = errors.New("no duplicates to merge other than the kept track")
+)
+
+// GroupedWith returns the IDs of all tracks which are in the same groups as the
+// track with ID `trackID`, without the track itself.
+func GroupedWith(groups []Group, trackID int64) []int64 {
+	var (
+		ids  []int64
+		seen = map[int64]struct{}{trackID: {}}
+	)
+
+	for _, group := range groups {
+		if !group.contains(trackID) {
+			continue
+		}
+
+		for _, track := range group.Tracks {
+			if _, ok := seen[track.ID]; ok {
+				continue
+			}
+
+			seen[track.ID] = struct{}{}
+			ids = append(ids, track.ID)
+		}
+	}
+
+	return ids
+}
+
+func (g Group) contains(trackID int64) bool {
+	for _, track := range g.Tracks {
+		if track.ID == trackID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,252 @@
+package duplicates_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/duplicates"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestFindingDuplicates checks that tracks with the same audio and tracks with
+// the same artist, title and similar durations are grouped together.
+func TestFindingDuplicates(t *testing.T) {
+	ctx := t.Context()
+	lib, tracks := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	original := tracks["Tittled Track"]
+	two := tracks["Another One"]
+
+	rerip := insertTrack(t, lib, two, "ANOTHER one!", 61000)
+	_ = insertTrack(t, lib, two, "Another One", 75000)
+
+	finder := duplicates.NewFinder(lib.ExecuteDBJobAndWait)
+	groups, err := finder.Find(ctx, duplicates.FindArgs{})
+	assert.NilErr(t, err, "finding duplicates")
+	assert.Equal(t, 2, len(groups), "number of duplicate groups")
+	if len(groups) != 2 {
+		t.FailNow()
+	}
+
+	sameAudio, sameName := groups[0], groups[1]
+	if sameAudio.Match != duplicates.MatchAudio {
+		sameAudio, sameName = sameName, sameAudio
+	}
+
+	assert.Equal(t, duplicates.MatchAudio, sameAudio.Match, "audio group match")
+	assert.Equal(t, 2, len(sameAudio.Tracks), "audio group size")
+	for _, track := range sameAudio.Tracks {
+		assert.Equal(t, original.Title, track.Title, "audio group track title")
+		if !strings.HasSuffix(track.Path, ".mp3") {
+			t.Errorf("unexpected path in audio group: %s", track.Path)
+		}
+	}
+
+	assert.Equal(t, duplicates.MatchMetadata, sameName.Match, "metadata group match")
+	assert.Equal(t, 2, len(sameName.Tracks), "metadata group size")
+	if len(sameName.Tracks) == 2 {
+		assert.Equal(t, two.ID, sameName.Tracks[0].ID, "first metadata track")
+		assert.Equal(t, rerip, sameName.Tracks[1].ID, "second metadata track")
+		assert.Equal(t, "flac", sameName.Tracks[1].Format, "re-ripped track format")
+		assert.Equal(t, "rerip", filepath.Base(filepath.Dir(sameName.Tracks[1].Path)), "path")
+	}
+
+	groups, err = finder.Find(ctx, duplicates.FindArgs{Tolerance: 500 * time.Millisecond})
+	assert.NilErr(t, err, "finding duplicates with small tolerance")
+	assert.Equal(t, 1, len(groups), "number of groups with small tolerance")
+
+	ids := duplicates.GroupedWith(groups, groups[0].Tracks[0].ID)
+	assert.Equal(t, 1, len(ids), "tracks grouped with the first one")
+
+	var report bytes.Buffer
+	assert.NilErr(t, duplicates.WriteReport(&report, groups), "writing report")
+	if !strings.Contains(report.String(), sameAudio.Tracks[1].Path) {
+		t.Errorf("expected report to contain duplicate path but got:\n%s", report.String())
+	}
+}
+
+// TestMergingDuplicates checks that stats, bookmarks and playlist entries of
+// duplicates are moved to the kept track.
+func TestMergingDuplicates(t *testing.T) {
+	ctx := t.Context()
+	lib, tracks := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	keeper := tracks["Another One"]
+	rerip := insertTrack(t, lib, keeper, keeper.Title, 60000)
+
+	assert.NilErr(t, lib.SetTrackRating(ctx, rerip, 4), "rating duplicate")
+	err := lib.RecordTrackPlay(ctx, rerip, time.Now())
+	assert.NilErr(t, err, "playing duplicate")
+	err = lib.RecordTrackPlay(ctx, keeper.ID, time.Now())
+	assert.NilErr(t, err, "playing kept track")
+
+	bookmarker := bookmarks.NewManager(lib.ExecuteDBJobAndWait)
+	err = bookmarker.Set(ctx, rerip, bookmarks.SetArgs{Position: 1000})
+	assert.NilErr(t, err, "bookmarking duplicate")
+
+	playlister := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	playlistID, err := playlister.Create(ctx, playlists.CreateArgs{
+		Name:   "With Duplicate",
+		Tracks: []int64{rerip, tracks["Payback"].ID},
+	})
+	assert.NilErr(t, err, "creating playlist")
+
+	finder := duplicates.NewFinder(lib.ExecuteDBJobAndWait)
+
+	err = finder.Merge(ctx, keeper.ID, []int64{keeper.ID})
+	if !errors.Is(err, duplicates.ErrNoDuplicates) {
+		t.Errorf("expected ErrNoDuplicates but got %v", err)
+	}
+
+	err = finder.Merge(ctx, keeper.ID, []int64{rerip, 987654})
+	if !errors.Is(err, duplicates.ErrTrackNotFound) {
+		t.Errorf("expected ErrTrackNotFound but got %v", err)
+	}
+
+	err = finder.Merge(ctx, keeper.ID, []int64{rerip})
+	assert.NilErr(t, err, "merging duplicates")
+
+	merged, err := lib.GetTrack(ctx, keeper.ID)
+	assert.NilErr(t, err, "getting kept track")
+	assert.Equal(t, int64(2), merged.Plays, "plays of kept track")
+	assert.Equal(t, uint8(4), merged.Rating, "rating of kept track")
+
+	duplicate, err := lib.GetTrack(ctx, rerip)
+	assert.NilErr(t, err, "getting duplicate")
+	assert.Equal(t, int64(0), duplicate.Plays, "plays of duplicate")
+	assert.Equal(t, uint8(0), duplicate.Rating, "rating of duplicate")
+
+	bookmark, err := bookmarker.Get(ctx, keeper.ID)
+	assert.NilErr(t, err, "getting moved bookmark")
+	assert.Equal(t, int64(1000), bookmark.Position, "moved bookmark position")
+
+	playlist, err := playlister.Get(ctx, playlistID, "")
+	assert.NilErr(t, err, "getting playlist")
+	assert.Equal(t, 2, len(playlist.Tracks), "playlist tracks")
+	if len(playlist.Tracks) == 2 {
+		assert.Equal(t, keeper.ID, playlist.Tracks[0].ID, "merged playlist entry")
+	}
+}
+
+// insertTrack adds a track to the library which is a different rip of `track`
+// with title `title` and duration `duration` in milliseconds. The original track
+// is set the duration of a minute. Returns the ID of the new track.
+func insertTrack(
+	t *testing.T,
+	lib *library.LocalLibrary,
+	track library.TrackInfo,
+	title string,
+	duration int64,
+) int64 {
+	t.Helper()
+
+	dir := filepath.Dir(lib.GetFilePath(context.Background(), track.ID))
+
+	var trackID int64
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`UPDATE tracks SET duration = 60000 WHERE id = ?`, track.ID)
+		if err != nil {
+			return err
+		}
+
+		res, err := db.Exec(`
+			INSERT INTO tracks
+				(name, album_id, artist_id, fs_path, number, duration, fingerprint)
+			VALUES
+				(@name, @album_id, @artist_id, @fs_path, 1, @duration, @fingerprint)
+		`,
+			sql.Named("name", title),
+			sql.Named("album_id", track.AlbumID),
+			sql.Named("artist_id", track.ArtistID),
+			sql.Named("fs_path", filepath.Join(dir, "rerip", title+".flac")),
+			sql.Named("duration", duration),
+			sql.Named("fingerprint", title+time.Now().String()),
+		)
+		if err != nil {
+			return err
+		}
+
+		trackID, err = res.LastInsertId()
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		t.Fatalf("inserting track: %s", err)
+	}
+
+	return trackID
+}
+
+// getTestMigrationFiles returns the SQLs directory used by the application itself
+// normally. This way tests will be done with the exact same files which will be
+// bundled into the binary on build.
+func getTestMigrationFiles() fs.FS {
+	return os.DirFS("../../sqls")
+}
+
+// getLibrary returns a library with all test files scanned into it together
+// with a copy of one of them. The tracks are returned by their title.
+func getLibrary(
+	ctx context.Context,
+	t *testing.T,
+) (*library.LocalLibrary, map[string]library.TrackInfo) {
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	libraryDir := filepath.Join(projRoot, "test_files", "library")
+	copiesDir := t.TempDir()
+	original, err := os.ReadFile(filepath.Join(libraryDir, "test_file_one.mp3"))
+	assert.NilErr(t, err, "reading test file")
+	err = os.WriteFile(filepath.Join(copiesDir, "copy.mp3"), original, 0600)
+	assert.NilErr(t, err, "writing copy of test file")
+
+	lib.DisableWatching()
+	lib.AddLibraryPath(libraryDir)
+	lib.AddLibraryPath(copiesDir)
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	tracks := make(map[string]library.TrackInfo)
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: "", Count: 100}) {
+		tracks[track.Title] = track
+	}
+
+	return lib, tracks
+}
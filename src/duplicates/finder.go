@@ -0,0 +1,421 @@
+package duplicates
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// queryBatchSize is the maximum number of track IDs used in a single query.
+const queryBatchSize = 500
+
+// finder implements the Finder interface by just requiring a function for
+// sending database work.
+type finder struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewFinder returns a Finder which will send SQL queries to `sendDBWork`.
+func NewFinder(sendDBWork func(library.DatabaseExecutable) error) Finder {
+	return &finder{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// candidate is a track as needed for finding its duplicates.
+type candidate struct {
+	id          int64
+	path        string
+	artist      string // normalised artist name
+	title       string // normalised title
+	duration    sql.NullInt64
+	fingerprint sql.NullString
+}
+
+// Find implements Finder.
+func (f *finder) Find(ctx context.Context, args FindArgs) ([]Group, error) {
+	const candidatesQuery = `
+		SELECT
+			t.id,
+			t.fs_path,
+			COALESCE(at.name, ''),
+			t.name,
+			t.duration,
+			t.fingerprint
+		FROM
+			tracks as t
+				LEFT JOIN artists as at ON at.id = t.artist_id
+		ORDER BY
+			t.id
+	`
+
+	tolerance := args.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	var groups []Group
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, candidatesQuery)
+		if err != nil {
+			return fmt.Errorf("querying tracks: %w", err)
+		}
+		defer rows.Close()
+
+		var candidates []candidate
+		for rows.Next() {
+			var (
+				c             candidate
+				artist, title string
+			)
+			err := rows.Scan(&c.id, &c.path, &artist, &title, &c.duration, &c.fingerprint)
+			if err != nil {
+				return fmt.Errorf("scanning track: %w", err)
+			}
+
+			c.artist = normalise(artist)
+			c.title = normalise(title)
+			candidates = append(candidates, c)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterating over tracks: %w", err)
+		}
+
+		groups, err = populateGroups(ctx, db, groupCandidates(candidates, tolerance))
+		return err
+	}
+	if err := f.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// Merge implements Finder.
+func (f *finder) Merge(ctx context.Context, keeperID int64, duplicateIDs []int64) error {
+	duplicateIDs = slices.Clone(duplicateIDs)
+	slices.Sort(duplicateIDs)
+	duplicateIDs = slices.Compact(duplicateIDs)
+	if len(duplicateIDs) == 0 || slices.Contains(duplicateIDs, keeperID) {
+		return ErrNoDuplicates
+	}
+
+	allIDs := append([]any{keeperID}, toArgs(duplicateIDs)...)
+	allIn := placeholders(len(allIDs))
+	duplicatesIn := placeholders(len(duplicateIDs))
+	keeperArgs := func(args ...any) []any {
+		return append(args, toArgs(duplicateIDs)...)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM tracks WHERE id IN (` + allIn + `)`
+
+	// The rating of the kept track is used when it has one. Otherwise the
+	// highest rating of the duplicates is used.
+	mergeStatsQuery := `
+		INSERT INTO user_stats
+			(track_id, favourite, user_rating, last_played, play_count)
+		SELECT
+			?,
+			MIN(NULLIF(favourite, 0)),
+			COALESCE(
+				MAX(CASE WHEN track_id = ? THEN NULLIF(user_rating, 0) END),
+				MAX(NULLIF(user_rating, 0))
+			),
+			MAX(last_played),
+			COALESCE(SUM(play_count), 0)
+		FROM
+			user_stats
+		WHERE
+			track_id IN (` + allIn + `)
+		HAVING
+			COUNT(*) > 0
+		ON CONFLICT (track_id) DO UPDATE SET
+			favourite = excluded.favourite,
+			user_rating = excluded.user_rating,
+			last_played = excluded.last_played,
+			play_count = excluded.play_count
+	`
+
+	queries := []struct {
+		query string
+		args  []any
+	}{
+		{
+			query: mergeStatsQuery,
+			args:  append([]any{keeperID, keeperID}, allIDs...),
+		},
+		{
+			query: `DELETE FROM user_stats WHERE track_id IN (` + duplicatesIn + `)`,
+			args:  toArgs(duplicateIDs),
+		},
+		{
+			query: `
+				UPDATE track_plays SET track_id = ?
+				WHERE track_id IN (` + duplicatesIn + `)
+			`,
+			args: keeperArgs(keeperID),
+		},
+		{
+			// A bookmark is moved only when the kept track has none.
+			query: `
+				UPDATE OR IGNORE bookmarks SET track_id = ?
+				WHERE track_id IN (` + duplicatesIn + `)
+			`,
+			args: keeperArgs(keeperID),
+		},
+		{
+			query: `
+				UPDATE playlists SET updated_at = ?
+				WHERE id IN (
+					SELECT playlist_id FROM playlists_tracks
+					WHERE track_id IN (` + duplicatesIn + `)
+				)
+			`,
+			args: keeperArgs(time.Now().Unix()),
+		},
+		{
+			query: `
+				UPDATE playlists_tracks SET track_id = ?
+				WHERE track_id IN (` + duplicatesIn + `)
+			`,
+			args: keeperArgs(keeperID),
+		},
+		{
+			// Playlist entries remember the tracks they point to.
+			query: `
+				UPDATE playlists_tracks
+				SET
+					fs_path = t.fs_path,
+					artist = at.name,
+					album = al.name,
+					title = t.name,
+					duration = t.duration
+				FROM
+					tracks as t
+						LEFT JOIN artists as at ON at.id = t.artist_id
+						LEFT JOIN albums as al ON al.id = t.album_id
+				WHERE
+					t.id = playlists_tracks.track_id AND
+					playlists_tracks.track_id = ?
+			`,
+			args: []any{keeperID},
+		},
+	}
+
+	work := func(db *sql.DB) (retErr error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin DB transaction: %w", err)
+		}
+		defer func() {
+			if retErr == nil {
+				if commitErr := tx.Commit(); commitErr != nil {
+					retErr = commitErr
+				}
+			} else {
+				_ = tx.Rollback()
+			}
+		}()
+
+		var found int
+		if err := tx.QueryRowContext(ctx, countQuery, allIDs...).Scan(&found); err != nil {
+			return fmt.Errorf("checking tracks: %w", err)
+		}
+		if found != len(allIDs) {
+			return ErrTrackNotFound
+		}
+
+		for _, q := range queries {
+			if _, err := tx.ExecContext(ctx, q.query, q.args...); err != nil {
+				return fmt.Errorf("merging duplicates into track %d: %w", keeperID, err)
+			}
+		}
+
+		return nil
+	}
+
+	return f.executeDBJobAndWait(work)
+}
+
+// groupCandidates returns the groups of duplicates found among `candidates`.
+// Every group is a list of candidates ordered by ID.
+func groupCandidates(candidates []candidate, tolerance time.Duration) []duplicates {
+	var (
+		byFingerprint = make(map[string][]candidate)
+		byName        = make(map[string][]candidate)
+	)
+
+	for _, c := range candidates {
+		if c.fingerprint.Valid && c.fingerprint.String != "" {
+			byFingerprint[c.fingerprint.String] = append(
+				byFingerprint[c.fingerprint.String], c,
+			)
+		}
+
+		if c.title != "" && c.duration.Valid {
+			key := c.artist + "\x00" + c.title
+			byName[key] = append(byName[key], c)
+		}
+	}
+
+	var groups []duplicates
+	for _, group := range byFingerprint {
+		if len(group) > 1 {
+			groups = append(groups, duplicates{match: MatchAudio, tracks: group})
+		}
+	}
+
+	for _, named := range byName {
+		slices.SortFunc(named, func(a, b candidate) int {
+			return cmp.Compare(a.duration.Int64, b.duration.Int64)
+		})
+
+		var cluster []candidate
+		for _, c := range named {
+			if len(cluster) > 0 &&
+				c.duration.Int64-cluster[len(cluster)-1].duration.Int64 >
+					tolerance.Milliseconds() {
+				groups = appendCluster(groups, cluster)
+				cluster = nil
+			}
+			cluster = append(cluster, c)
+		}
+		groups = appendCluster(groups, cluster)
+	}
+
+	for _, group := range groups {
+		slices.SortFunc(group.tracks, func(a, b candidate) int {
+			return cmp.Compare(a.id, b.id)
+		})
+	}
+
+	slices.SortFunc(groups, func(a, b duplicates) int {
+		first, second := a.tracks[0], b.tracks[0]
+		if c := strings.Compare(first.artist, second.artist); c != 0 {
+			return c
+		}
+		if c := strings.Compare(first.title, second.title); c != 0 {
+			return c
+		}
+		if c := strings.Compare(string(a.match), string(b.match)); c != 0 {
+			return c
+		}
+		return cmp.Compare(first.id, second.id)
+	})
+
+	return groups
+}
+
+// appendCluster adds the tracks with the same name and similar durations in
+// `cluster` to `groups` unless they are the same audio. Such are in a group of
+// their own already.
+func appendCluster(groups []duplicates, cluster []candidate) []duplicates {
+	if len(cluster) < 2 {
+		return groups
+	}
+
+	sameAudio := cluster[0].fingerprint.Valid && cluster[0].fingerprint.String != ""
+	for _, c := range cluster[1:] {
+		if c.fingerprint != cluster[0].fingerprint {
+			sameAudio = false
+			break
+		}
+	}
+	if sameAudio {
+		return groups
+	}
+
+	return append(groups, duplicates{match: MatchMetadata, tracks: cluster})
+}
+
+// populateGroups returns the groups with the full information for every track
+// in the `found` groups.
+func populateGroups(
+	ctx context.Context,
+	db *sql.DB,
+	found []duplicates,
+) ([]Group, error) {
+	var ids []int64
+	for _, group := range found {
+		for _, c := range group.tracks {
+			ids = append(ids, c.id)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	tracks := make(map[int64]library.TrackInfo, len(ids))
+	for batch := range slices.Chunk(ids, queryBatchSize) {
+		where := []string{"t.id IN (" + placeholders(len(batch)) + ")"}
+		rows, err := library.QueryTracks(ctx, db, where, "", toArgs(batch))
+		if err != nil {
+			return nil, fmt.Errorf("error selecting duplicate tracks: %w", err)
+		}
+
+		for rows.Next() {
+			track, err := library.ScanTrack(rows)
+			if err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("error while scanning a track: %w", err)
+			}
+
+			tracks[track.ID] = track
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating over tracks: %w", err)
+		}
+	}
+
+	groups := make([]Group, 0, len(found))
+	for _, group := range found {
+		populated := Group{Match: group.match}
+		for _, c := range group.tracks {
+			populated.Tracks = append(populated.Tracks, Track{
+				TrackInfo: tracks[c.id],
+				Path:      c.path,
+			})
+		}
+		groups = append(groups, populated)
+	}
+
+	return groups, nil
+}
+
+// duplicates is a group of candidates before their full information is read
+// from the database.
+type duplicates struct {
+	match  Match
+	tracks []candidate
+}
+
+// normalise returns `s` in lower case, with punctuation removed and with
+// all consecutive white space replaced by a single space.
+func normalise(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", count), ",")
+}
+
+func toArgs(ids []int64) []any {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return args
+}
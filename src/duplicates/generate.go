@@ -0,0 +1,6 @@
+package duplicates
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+// This file is here just to hold the generate directives so that they are not duplicated
+// in many places.
@@ -0,0 +1,51 @@
+package duplicates
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteReport writes a human readable report of the duplicates `groups` to `w`.
+func WriteReport(w io.Writer, groups []Group) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Found %d groups of duplicate tracks.\n", len(groups))
+	for _, group := range groups {
+		switch group.Match {
+		case MatchAudio:
+			sb.WriteString("\nSame audio:\n")
+		default:
+			sb.WriteString("\nSame artist, title and duration:\n")
+		}
+
+		for _, track := range group.Tracks {
+			fmt.Fprintf(&sb, "  %d\t%s - %s", track.ID, track.Artist, track.Title)
+			if track.Album != "" {
+				fmt.Fprintf(&sb, " [%s]", track.Album)
+			}
+			sb.WriteString("\n")
+
+			details := []string{
+				track.Format,
+				(time.Duration(track.Duration) * time.Millisecond).String(),
+			}
+			if track.Bitrate > 0 {
+				details = append(details, fmt.Sprintf("%d kbps", track.Bitrate/1024))
+			}
+			details = append(details, fmt.Sprintf("%d plays", track.Plays))
+			if track.Rating > 0 {
+				details = append(details, fmt.Sprintf("rating %d", track.Rating))
+			}
+			if track.Favourite > 0 {
+				details = append(details, "favourite")
+			}
+			fmt.Fprintf(&sb, "\t%s\n", strings.Join(details, ", "))
+			fmt.Fprintf(&sb, "\t%s\n", track.Path)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -119,6 +119,7 @@ func (lib *LocalLibrary) findAndSaveAlbumArtworkOrOriginal(
 	if err := lib.saveAlbumArtworkNotFound(albumID); err != nil {
 		return nil, size, err
 	}
+	lib.recordMissingArtwork(albumID)
 
 	return nil, size, ErrArtworkNotFound
 }
@@ -188,6 +189,10 @@ func (lib *LocalLibrary) storeAlbumArtwork(
 		return nil, size, err
 	}
 
+	if size == OriginalImage {
+		lib.resolveMissingArtwork(albumID)
+	}
+
 	return newBytesReadCloser(buff), size, nil
 }
 
@@ -541,6 +546,7 @@ func (lib *LocalLibrary) SaveAlbumArtwork(
 		return err
 	}
 
+	lib.resolveMissingArtwork(albumID)
 	return nil
 }
 
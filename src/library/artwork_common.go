@@ -44,6 +44,52 @@ type ArtistImageManager interface {
 	RemoveArtistImage(ctx context.Context, artistID int64) error
 }
 
+//counterfeiter:generate . AvatarManager
+
+// AvatarManager is an interface for all methods for managing the avatars of users.
+type AvatarManager interface {
+	// FindAvatar returns the avatar of a user. ErrArtworkNotFound is returned when
+	// the user has no avatar.
+	FindAvatar(ctx context.Context, username string, size ImageSize) (io.ReadCloser, error)
+
+	// SaveAvatar stores the avatar of a user, replacing the previous one.
+	SaveAvatar(ctx context.Context, username string, r io.Reader) error
+
+	// RemoveAvatar removes the stored avatar of a user.
+	RemoveAvatar(ctx context.Context, username string) error
+}
+
+//counterfeiter:generate . PlaylistImageManager
+
+// PlaylistImageManager is an interface for all methods for managing the images of
+// playlists.
+type PlaylistImageManager interface {
+	// FindPlaylistImage returns the uploaded image of a playlist.
+	// ErrArtworkNotFound is returned when the playlist has no image.
+	FindPlaylistImage(
+		ctx context.Context,
+		playlistID int64,
+		size ImageSize,
+	) (io.ReadCloser, error)
+
+	// SavePlaylistImage stores the image for a playlist, replacing the previous
+	// one. ErrPlaylistNotFound is returned when there is no such playlist.
+	SavePlaylistImage(ctx context.Context, playlistID int64, r io.Reader) error
+
+	// RemovePlaylistImage removes the stored image of a playlist.
+	RemovePlaylistImage(ctx context.Context, playlistID int64) error
+
+	// AlbumsMosaic returns a 2x2 mosaic of the artwork of the first albums from
+	// `albumIDs` which have artwork. It is meant for playlists without images of
+	// their own. ErrArtworkNotFound is returned when none of the albums has
+	// artwork.
+	AlbumsMosaic(
+		ctx context.Context,
+		albumIDs []int64,
+		size ImageSize,
+	) (io.ReadCloser, error)
+}
+
 // ImageSize is an enum type which defines the different sizes form images from the
 // ArtistImageManager and ArtworkManager.
 type ImageSize int64
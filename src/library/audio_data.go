@@ -0,0 +1,413 @@
+package library
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const (
+	// oggPageHeaderSize is the size of an Ogg page header without its segment
+	// table.
+	oggPageHeaderSize = 27
+
+	// oggPageChangingEnd is the offset in an Ogg page header after its page
+	// sequence number and checksum. These are the header fields which change
+	// when pages before it are rewritten.
+	oggPageChangingEnd = 26
+
+	ebmlSegmentID = 0x18538067
+	ebmlClusterID = 0x1F43B675
+)
+
+// fileSection is a continuous part of a file.
+type fileSection struct {
+	offset int64
+	length int64
+}
+
+// audioData is the audio of a media file without its meta data. It is made of
+// the sections of the file which hold the audio and reads them as if they were
+// one continuous stream.
+type audioData struct {
+	r        io.ReaderAt
+	sections []fileSection
+	length   int64
+
+	// oggPages is true when the audio consists of Ogg pages. Their sequence
+	// numbers and checksums change when the pages with the tags are rewritten.
+	oggPages bool
+}
+
+// newAudioData returns the audio data made of `sections` of `r`.
+func newAudioData(
+	r io.ReaderAt,
+	oggPages bool,
+	sections ...fileSection,
+) (*audioData, error) {
+	audio := &audioData{
+		r:        r,
+		sections: sections,
+		oggPages: oggPages,
+	}
+	for _, section := range sections {
+		if section.offset < 0 || section.length < 0 {
+			return nil, errors.New("malformed audio data section")
+		}
+		audio.length += section.length
+	}
+
+	if audio.length == 0 {
+		return nil, errors.New("no audio data found")
+	}
+
+	return audio, nil
+}
+
+// ReadAt implements io.ReaderAt. The offset is in the audio data and not in
+// the file.
+func (a *audioData) ReadAt(p []byte, off int64) (int, error) {
+	var n int
+	for _, section := range a.sections {
+		if n == len(p) {
+			break
+		}
+		if off >= section.length {
+			off -= section.length
+			continue
+		}
+
+		toRead := int(min(int64(len(p)-n), section.length-off))
+		read, err := a.r.ReadAt(p[n:n+toRead], section.offset+off)
+		n += read
+		if err != nil && (!errors.Is(err, io.EOF) || read < toRead) {
+			return n, err
+		}
+		off = 0
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// findAudioData returns the audio data of the media file in `r` which is `size`
+// bytes long. Its container format is recognized by its content.
+func findAudioData(r io.ReaderAt, size int64) (*audioData, error) {
+	offset, err := skipID3v2(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 12)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, fmt.Errorf("reading file header: %w", err)
+	}
+
+	switch {
+	case string(header[:4]) == "fLaC":
+		return flacAudio(r, offset, size)
+	case string(header[:4]) == "OggS":
+		return oggAudio(r, offset, size)
+	case string(header[4:8]) == "ftyp":
+		return mp4Audio(r, offset, size)
+	case string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return wavAudio(r, offset, size)
+	case string(header[:4]) == "\x1a\x45\xdf\xa3":
+		return matroskaAudio(r, offset, size)
+	}
+
+	return mpegAudio(r, offset, size)
+}
+
+// skipID3v2 returns the offset after the ID3v2 tags at the start of `r`.
+func skipID3v2(r io.ReaderAt) (int64, error) {
+	var offset int64
+	header := make([]byte, 10)
+	for {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return 0, fmt.Errorf("reading file header: %w", err)
+		}
+
+		if string(header[:3]) != "ID3" {
+			return offset, nil
+		}
+
+		// The tag size is a synchsafe integer which excludes the header
+		// and the footer.
+		tagSize := int64(header[6])<<21 | int64(header[7])<<14 |
+			int64(header[8])<<7 | int64(header[9])
+		if header[5]&0x10 != 0 {
+			tagSize += 10
+		}
+
+		offset += int64(len(header)) + tagSize
+	}
+}
+
+// mpegAudio returns the audio of an MPEG file which starts at `offset`. The
+// ID3v1 and APEv2 tags at the end of the file are not part of it.
+func mpegAudio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	end := size
+	buf := make([]byte, 32)
+	if end-offset >= 128 {
+		if _, err := r.ReadAt(buf[:3], end-128); err != nil {
+			return nil, fmt.Errorf("reading ID3v1 tag: %w", err)
+		}
+		if string(buf[:3]) == "TAG" {
+			end -= 128
+		}
+	}
+
+	if end-offset >= int64(len(buf)) {
+		if _, err := r.ReadAt(buf, end-int64(len(buf))); err != nil {
+			return nil, fmt.Errorf("reading APEv2 tag footer: %w", err)
+		}
+		if string(buf[:8]) == "APETAGEX" {
+			// The tag size includes the footer but not the optional header.
+			tagSize := int64(binary.LittleEndian.Uint32(buf[12:16]))
+			if binary.LittleEndian.Uint32(buf[20:24])&(1<<31) != 0 {
+				tagSize += int64(len(buf))
+			}
+			end = max(offset, end-tagSize)
+		}
+	}
+
+	return newAudioData(r, false, fileSection{offset: offset, length: end - offset})
+}
+
+// flacAudio returns the audio of a FLAC stream which starts at `offset`. It
+// follows the meta data blocks of the stream.
+func flacAudio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	offset += 4
+	header := make([]byte, 4)
+	for {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return nil, fmt.Errorf("reading FLAC meta data block header: %w", err)
+		}
+
+		blockSize := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		offset += int64(len(header)) + blockSize
+		if header[0]&0x80 != 0 {
+			break
+		}
+	}
+
+	return newAudioData(r, false, fileSection{offset: offset, length: size - offset})
+}
+
+// oggAudio returns the audio of an Ogg stream which starts at `offset`. It
+// starts with the first page after the header packets of the codec. One of
+// them is the comment header with the tags.
+func oggAudio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	var (
+		headerPackets = -1
+		packets       int
+		header        = make([]byte, oggPageHeaderSize)
+		segments      = make([]byte, 255)
+	)
+	for {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return nil, fmt.Errorf("reading Ogg page header: %w", err)
+		}
+		if string(header[:4]) != "OggS" {
+			return nil, fmt.Errorf("malformed Ogg page at offset %d", offset)
+		}
+
+		segments = segments[:header[26]]
+		if _, err := r.ReadAt(segments, offset+oggPageHeaderSize); err != nil {
+			return nil, fmt.Errorf("reading Ogg segment table: %w", err)
+		}
+		payload := offset + oggPageHeaderSize + int64(len(segments))
+
+		if headerPackets < 0 {
+			var err error
+			headerPackets, err = oggHeaderPackets(r, payload)
+			if err != nil {
+				return nil, err
+			}
+			if headerPackets == 0 {
+				break
+			}
+		}
+
+		var payloadSize int64
+		for _, lacing := range segments {
+			payloadSize += int64(lacing)
+			if lacing < 255 {
+				packets++
+			}
+		}
+
+		offset = payload + payloadSize
+		if packets >= headerPackets {
+			break
+		}
+	}
+
+	return newAudioData(r, true, fileSection{offset: offset, length: size - offset})
+}
+
+// oggHeaderPackets returns the number of header packets of the Ogg stream which
+// first packet starts at `offset`. It is zero for codecs which are not known.
+// Their streams are taken whole.
+func oggHeaderPackets(r io.ReaderAt, offset int64) (int, error) {
+	id := make([]byte, 9)
+	if _, err := r.ReadAt(id, offset); err != nil {
+		return 0, fmt.Errorf("reading Ogg identification header: %w", err)
+	}
+
+	switch {
+	case string(id[:7]) == "\x01vorbis":
+		return 3, nil
+	case string(id[:8]) == "OpusHead":
+		return 2, nil
+	case string(id[:5]) == "\x7fFLAC":
+		// The mapping header is followed by this number of other header
+		// packets. Zero means that it is unknown.
+		if others := int(binary.BigEndian.Uint16(id[7:9])); others > 0 {
+			return 1 + others, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// mp4Audio returns the audio of an MP4 file. It is the content of its "mdat"
+// boxes.
+func mp4Audio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	var sections []fileSection
+	header := make([]byte, 16)
+	for offset+8 <= size {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return nil, fmt.Errorf("reading MP4 box header: %w", err)
+		}
+
+		headerSize := int64(8)
+		boxSize := int64(binary.BigEndian.Uint32(header[:4]))
+		switch boxSize {
+		case 0:
+			// The box extends to the end of the file.
+			boxSize = size - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return nil, fmt.Errorf("reading MP4 box size: %w", err)
+			}
+			headerSize = 16
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if boxSize < headerSize {
+			return nil, fmt.Errorf("malformed MP4 box at offset %d", offset)
+		}
+
+		if string(header[4:8]) == "mdat" {
+			sections = append(sections, fileSection{
+				offset: offset + headerSize,
+				length: min(boxSize, size-offset) - headerSize,
+			})
+		}
+		offset += boxSize
+	}
+
+	return newAudioData(r, false, sections...)
+}
+
+// wavAudio returns the audio of a WAVE file. It is the content of its "data"
+// chunk.
+func wavAudio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	offset += 12
+	header := make([]byte, 8)
+	for offset+8 <= size {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return nil, fmt.Errorf("reading WAVE chunk header: %w", err)
+		}
+
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+		if string(header[:4]) == "data" {
+			return newAudioData(r, false, fileSection{
+				offset: offset + 8,
+				length: min(chunkSize, size-offset-8),
+			})
+		}
+
+		// Chunks are padded to an even number of bytes.
+		offset += 8 + chunkSize + chunkSize%2
+	}
+
+	return nil, errors.New("no WAVE data chunk found")
+}
+
+// matroskaAudio returns the audio of a Matroska or WebM file. It is made of the
+// clusters of its segment.
+func matroskaAudio(r io.ReaderAt, offset, size int64) (*audioData, error) {
+	var sections []fileSection
+	end := size
+	for offset < end {
+		id, idLen, err := readEBMLNumber(r, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		dataSize, sizeLen, err := readEBMLNumber(r, offset+idLen, false)
+		if err != nil {
+			return nil, err
+		}
+
+		dataStart := offset + idLen + sizeLen
+		if dataSize < 0 {
+			// Elements with unknown size extend to the end of their parent.
+			dataSize = end - dataStart
+		}
+
+		switch id {
+		case ebmlSegmentID:
+			// The clusters are children of the segment.
+			end = min(end, dataStart+dataSize)
+			offset = dataStart
+			continue
+		case ebmlClusterID:
+			sections = append(sections, fileSection{
+				offset: offset,
+				length: min(dataStart+dataSize, end) - offset,
+			})
+		}
+		offset = dataStart + dataSize
+	}
+
+	return newAudioData(r, false, sections...)
+}
+
+// readEBMLNumber reads the variable length number at `offset` of `r` and returns
+// it along with its length in bytes. Element IDs keep their length marker while
+// element sizes do not. Unknown element sizes are returned as -1.
+func readEBMLNumber(r io.ReaderAt, offset int64, isID bool) (int64, int64, error) {
+	buf := make([]byte, 8)
+	if _, err := r.ReadAt(buf[:1], offset); err != nil {
+		return 0, 0, fmt.Errorf("reading EBML element: %w", err)
+	}
+
+	length := bits.LeadingZeros8(buf[0]) + 1
+	if length > len(buf) {
+		return 0, 0, fmt.Errorf("malformed EBML element at offset %d", offset)
+	}
+	if _, err := r.ReadAt(buf[1:length], offset+1); err != nil {
+		return 0, 0, fmt.Errorf("reading EBML element: %w", err)
+	}
+
+	var value uint64
+	for _, b := range buf[:length] {
+		value = value<<8 | uint64(b)
+	}
+	if isID {
+		return int64(value), int64(length), nil
+	}
+
+	value &^= 1 << (7 * length)
+	if value == 1<<(7*length)-1 {
+		return -1, int64(length), nil
+	}
+
+	return int64(value), int64(length), nil
+}
@@ -0,0 +1,170 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FindAvatar implements the AvatarManager interface for the local library. Small
+// avatars are created from the original one on first use and stored in the
+// database for later retrieval.
+func (lib *LocalLibrary) FindAvatar(
+	ctx context.Context,
+	username string,
+	size ImageSize,
+) (io.ReadCloser, error) {
+	original, small, err := lib.avatarFromDB(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == OriginalImage {
+		return newBytesReadCloser(original), nil
+	}
+
+	if len(small) > 0 {
+		return newBytesReadCloser(small), nil
+	}
+
+	scaled, err := lib.scaleImage(ctx, newBytesReadCloser(original), size)
+	if err != nil {
+		return nil, fmt.Errorf("error scaling avatar: %w", err)
+	}
+	defer scaled.Close()
+
+	small, err = io.ReadAll(scaled)
+	if err != nil {
+		return nil, fmt.Errorf("reading scaled avatar: %w", err)
+	}
+
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `
+			UPDATE users_avatars
+			SET image_small = @image_small
+			WHERE username = @username
+		`,
+			sql.Named("image_small", small),
+			sql.Named("username", username),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return nil, fmt.Errorf("storing small avatar: %w", err)
+	}
+
+	return newBytesReadCloser(small), nil
+}
+
+// avatarFromDB returns the original and the small avatar of a user. The small one
+// is empty when it has not been created yet.
+func (lib *LocalLibrary) avatarFromDB(
+	ctx context.Context,
+	username string,
+) ([]byte, []byte, error) {
+	var original, small []byte
+
+	work := func(db *sql.DB) error {
+		row := db.QueryRowContext(ctx, `
+			SELECT
+				image,
+				image_small
+			FROM
+				users_avatars
+			WHERE
+				username = @username
+		`, sql.Named("username", username))
+
+		err := row.Scan(&original, &small)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrArtworkNotFound
+		} else if err != nil {
+			return fmt.Errorf("error getting avatar from db: %w", err)
+		}
+
+		return nil
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return nil, nil, err
+	}
+
+	if len(original) == 0 {
+		return nil, nil, ErrArtworkNotFound
+	}
+
+	return original, small, nil
+}
+
+// SaveAvatar implements the AvatarManager interface for the local library.
+//
+// It saves the image in `r` in the database. It will read up to 5MB of data from
+// `r` and if this limit is reached, the image is considered too big and will not
+// be saved in the db.
+func (lib *LocalLibrary) SaveAvatar(
+	ctx context.Context,
+	username string,
+	r io.Reader,
+) error {
+	var readLimit int64 = 5 * 1024 * 1024
+
+	lr := &io.LimitedReader{
+		R: r,
+		N: readLimit,
+	}
+
+	buff, err := io.ReadAll(lr)
+	if err != nil {
+		return fmt.Errorf("reading avatar for user %s: %w", username, err)
+	}
+
+	if int64(len(buff)) >= readLimit {
+		return ErrArtworkTooBig
+	}
+
+	if len(buff) == 0 {
+		return NewArtworkError(errors.New("uploaded avatar is empty"))
+	}
+
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO
+				users_avatars (username, image, image_small, updated_at)
+			VALUES
+				(@username, @image, NULL, @updated_at)
+			ON CONFLICT (username) DO
+			UPDATE SET
+				image = excluded.image,
+				image_small = NULL,
+				updated_at = excluded.updated_at
+		`,
+			sql.Named("username", username),
+			sql.Named("image", buff),
+			sql.Named("updated_at", time.Now().Unix()),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return fmt.Errorf("storing avatar: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveAvatar implements the AvatarManager interface for the local library.
+func (lib *LocalLibrary) RemoveAvatar(ctx context.Context, username string) error {
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx,
+			"DELETE FROM users_avatars WHERE username = @username",
+			sql.Named("username", username),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return fmt.Errorf("removing avatar: %w", err)
+	}
+
+	return nil
+}
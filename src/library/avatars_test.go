@@ -0,0 +1,118 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/scaler/scalerfakes"
+)
+
+// TestLocalLibraryAvatars checks that avatars are saved, scaled, replaced and
+// removed by the local library.
+func TestLocalLibraryAvatars(t *testing.T) {
+	var (
+		bigImage    = []byte("big-avatar-is-really-bigger-than-the-small")
+		secondImage = []byte("second-avatar-image")
+		smallImage  = []byte("small-avatar")
+		ctx         = context.Background()
+		username    = "test-user"
+	)
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	defer func() { _ = lib.Truncate() }()
+
+	fakeScaler := &scalerfakes.FakeScaler{
+		ScaleStub: func(_ context.Context, r io.Reader, _ int) ([]byte, error) {
+			if _, err := io.ReadAll(r); err != nil {
+				return nil, err
+			}
+
+			imgb := make([]byte, len(smallImage))
+			copy(imgb, smallImage)
+			return imgb, nil
+		},
+	}
+	lib.SetScaler(fakeScaler)
+
+	_, err = lib.FindAvatar(ctx, username, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Fatalf("expected artwork not found error but got `%+v`", err)
+	}
+
+	if err := lib.SaveAvatar(ctx, username, bytes.NewReader(bigImage)); err != nil {
+		t.Fatalf("error saving avatar: %s", err)
+	}
+	assertAvatar(t, lib, username, OriginalImage, bigImage)
+	assertAvatar(t, lib, username, SmallImage, smallImage)
+	assertAvatar(t, lib, username, SmallImage, smallImage)
+
+	if calls := fakeScaler.ScaleCallCount(); calls != 1 {
+		t.Errorf("expected the avatar to be scaled once but it was %d times", calls)
+	}
+
+	// Replacing the avatar must drop the stored small version.
+	if err := lib.SaveAvatar(ctx, username, bytes.NewReader(secondImage)); err != nil {
+		t.Fatalf("error replacing avatar: %s", err)
+	}
+	assertAvatar(t, lib, username, OriginalImage, secondImage)
+	assertAvatar(t, lib, username, SmallImage, smallImage)
+
+	if calls := fakeScaler.ScaleCallCount(); calls != 2 {
+		t.Errorf("expected the new avatar to be scaled but it was not")
+	}
+
+	err = lib.SaveAvatar(ctx, username, strings.NewReader(""))
+	var artErr *ArtworkError
+	if !errors.As(err, &artErr) {
+		t.Errorf("expected artwork error for empty avatar but got `%+v`", err)
+	}
+
+	tooBig := bytes.NewReader(make([]byte, 6*1024*1024))
+	if err := lib.SaveAvatar(ctx, username, tooBig); !errors.Is(err, ErrArtworkTooBig) {
+		t.Errorf("expected artwork too big error but got `%+v`", err)
+	}
+
+	if err := lib.RemoveAvatar(ctx, username); err != nil {
+		t.Fatalf("error removing avatar: %s", err)
+	}
+
+	_, err = lib.FindAvatar(ctx, username, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Fatalf("expected artwork not found error but got `%+v`", err)
+	}
+}
+
+func assertAvatar(
+	t *testing.T,
+	lib *LocalLibrary,
+	username string,
+	size ImageSize,
+	expectedImage []byte,
+) {
+	foundImg, err := lib.FindAvatar(context.Background(), username, size)
+	if err != nil {
+		t.Fatalf("error finding avatar: %s", err)
+	}
+	defer foundImg.Close()
+
+	foundImgBytes, err := io.ReadAll(foundImg)
+	if err != nil {
+		t.Fatalf("error reading avatar reader: %s", err)
+	}
+
+	if !bytes.Equal(expectedImage, foundImgBytes) {
+		t.Errorf("expected avatar `%s` but got `%s`", expectedImage, foundImgBytes)
+	}
+}
@@ -1,5 +1,7 @@
 package library
 
+import "time"
+
 // BrowseOrder represents different strategies which can be made with respect to the
 // comparison function.
 type BrowseOrder int
@@ -90,6 +92,11 @@ type BrowseArgs struct {
 	// To year is the inclusive upper limit for the year of recording for the returned
 	// results.
 	ToYear *int64
+
+	// MusicFolderIDs may be used for filtering the results so that only results
+	// which are in any of these music folders are returned. An empty list means
+	// "all music folders".
+	MusicFolderIDs []int64
 }
 
 //counterfeiter:generate . Browser
@@ -110,4 +117,8 @@ type Browser interface {
 	// of songs (optionally sorted) and the number of songs which match the browsing
 	// criteria.
 	BrowseTracks(BrowseArgs) ([]TrackInfo, int)
+
+	// LastModified returns the last time the content of the library was changed.
+	// That is tracks, albums or artists were added, updated or removed.
+	LastModified() time.Time
 }
@@ -0,0 +1,399 @@
+package library
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"strings"
+	"time"
+)
+
+// fingerprintSampleSize is the number of bytes hashed from each of the start,
+// the middle and the end of the audio data of a file for its fingerprint.
+const fingerprintSampleSize = 64 * 1024
+
+// defaultRemovedFilesWait is the time for which tracks stay in the library after
+// their files are removed or renamed. Files with the same audio which appear
+// in the meantime take the place of the removed tracks instead of being added
+// as new ones. This way moved files keep their plays, ratings, favourites and
+// bookmarks.
+//
+// It is the initial value of LocalLibrary.removedFilesWait.
+var defaultRemovedFilesWait = 10 * time.Second
+
+// fileFingerprint returns a checksum of the audio data in the file at `path`.
+// It does not change when the tags of the file are edited. An empty string is
+// returned for files which could not be read.
+func (lib *LocalLibrary) fileFingerprint(path string) string {
+	fh, err := lib.fs.Open(path)
+	if err != nil {
+		log.Printf("Error opening %s for fingerprinting: %s\n", path, err)
+		return ""
+	}
+	defer fh.Close()
+
+	st, err := fh.Stat()
+	if err != nil {
+		log.Printf("Error getting %s size for fingerprinting: %s\n", path, err)
+		return ""
+	}
+
+	ra, ok := fh.(io.ReaderAt)
+	if !ok {
+		log.Printf("Fingerprinting %s: file does not support random access\n", path)
+		return ""
+	}
+
+	fingerprint, err := audioFingerprint(ra, st.Size())
+	if err != nil {
+		log.Printf("Error fingerprinting %s: %s\n", path, err)
+		return ""
+	}
+
+	return fingerprint
+}
+
+// moveRemovedTrack moves a track with the same fingerprint which file no longer
+// exists to `newPath`. It returns whether such a track was found.
+func (lib *LocalLibrary) moveRemovedTrack(fingerprint, newPath string) (bool, error) {
+	if fingerprint == "" {
+		return false, nil
+	}
+
+	var candidates []track
+	work := func(db *sql.DB) error {
+		rows, err := db.Query(`
+			SELECT
+				id,
+				fs_path
+			FROM
+				tracks
+			WHERE
+				fingerprint = @fingerprint AND
+				fs_path != @fs_path
+			ORDER BY
+				id
+		`,
+			sql.Named("fingerprint", fingerprint),
+			sql.Named("fs_path", newPath),
+		)
+		if err != nil {
+			return fmt.Errorf("querying tracks by fingerprint: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var tr track
+			if err := rows.Scan(&tr.id, &tr.fsPath); err != nil {
+				return fmt.Errorf("scanning track: %w", err)
+			}
+			candidates = append(candidates, tr)
+		}
+
+		return rows.Err()
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return false, err
+	}
+
+	for _, candidate := range candidates {
+		_, err := fs.Stat(lib.fs, candidate.fsPath)
+		if !errors.Is(err, fs.ErrNotExist) {
+			// This is a copy of a file which is still in the library.
+			continue
+		}
+
+		var moved bool
+		work := func(db *sql.DB) error {
+			res, err := db.Exec(`
+				UPDATE tracks
+				SET
+					fs_path = @new_path
+				WHERE
+					id = @id AND
+					fs_path = @old_path AND
+					NOT EXISTS (SELECT 1 FROM tracks WHERE fs_path = @new_path)
+			`,
+				sql.Named("new_path", newPath),
+				sql.Named("id", candidate.id),
+				sql.Named("old_path", candidate.fsPath),
+			)
+			if err != nil {
+				return fmt.Errorf("moving track %d: %w", candidate.id, err)
+			}
+
+			affected, _ := res.RowsAffected()
+			moved = affected > 0
+			if !moved {
+				return nil
+			}
+
+			return lib.markModified(db)
+		}
+		if err := lib.ExecuteDBJobAndWait(work); err != nil {
+			return false, err
+		}
+
+		if moved {
+			log.Printf("Moved track %d from %s to %s\n",
+				candidate.id, candidate.fsPath, newPath)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// removeLater calls `remove` after lib.removedFilesWait unless the library is
+// closed in the meantime. Pending removals are stopped by stopPendingRemovals.
+func (lib *LocalLibrary) removeLater(remove func()) {
+	lib.pendingRemovalsLock.Lock()
+	defer lib.pendingRemovalsLock.Unlock()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(lib.removedFilesWait, func() {
+		lib.pendingRemovalsLock.Lock()
+		delete(lib.pendingRemovals, timer)
+		lib.pendingRemovalsLock.Unlock()
+
+		if lib.ctx.Err() != nil {
+			return
+		}
+
+		remove()
+	})
+
+	if lib.pendingRemovals == nil {
+		lib.pendingRemovals = make(map[*time.Timer]func())
+	}
+	lib.pendingRemovals[timer] = remove
+}
+
+// stopPendingRemovals stops the timers of all removals which have not started
+// yet. When `runNow` is true the stopped removals are done right away instead.
+func (lib *LocalLibrary) stopPendingRemovals(runNow bool) {
+	lib.pendingRemovalsLock.Lock()
+	var stopped []func()
+	for timer, remove := range lib.pendingRemovals {
+		if timer.Stop() {
+			stopped = append(stopped, remove)
+		}
+	}
+	lib.pendingRemovals = nil
+	lib.pendingRemovalsLock.Unlock()
+
+	if !runNow {
+		return
+	}
+
+	for _, remove := range stopped {
+		remove()
+	}
+}
+
+// removeFileLater removes the file at `filePath` from the library after
+// lib.removedFilesWait unless it was moved in the meantime.
+func (lib *LocalLibrary) removeFileLater(filePath string) {
+	lib.removeLater(func() {
+		if _, err := fs.Stat(lib.fs, filePath); !errors.Is(err, fs.ErrNotExist) {
+			// A file was created at the same place.
+			return
+		}
+
+		lib.removeFile(filePath)
+
+		// The file may have been moved to a place which was already
+		// added to the library.
+		lib.relinkPlaylistEntries()
+	})
+}
+
+// removeDirectoryLater removes the files in the `dirPath` directory from the
+// library after lib.removedFilesWait. Files which were moved in the meantime or
+// which exist at the same place again are kept.
+func (lib *LocalLibrary) removeDirectoryLater(dirPath string) {
+	lib.removeLater(func() {
+		// Adding slash at the end to make sure we are always removing directories
+		dirMatch := fmt.Sprintf("%s/%%", strings.TrimRight(dirPath, "/"))
+
+		var tracks []track
+		work := func(db *sql.DB) error {
+			rows, err := db.Query(`
+				SELECT
+					id,
+					fs_path
+				FROM
+					tracks
+				WHERE
+					fs_path LIKE ?
+			`, dirMatch)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var tr track
+				if err := rows.Scan(&tr.id, &tr.fsPath); err != nil {
+					return err
+				}
+				tracks = append(tracks, tr)
+			}
+
+			return rows.Err()
+		}
+		if err := lib.ExecuteDBJobAndWait(work); err != nil {
+			log.Printf("Error getting tracks in removed directory %s: %s\n", dirPath, err)
+			return
+		}
+
+		if err := lib.checkAndRemoveTracks(tracks); err != nil {
+			log.Printf("Error removing directory %s: %s\n", dirPath, err)
+		}
+
+		lib.cleanupPlaylistFiles()
+		lib.relinkPlaylistEntries()
+	})
+}
+
+// fingerprintTracks stores the fingerprints of the tracks which do not have
+// one. Such are the tracks added before the fingerprints were introduced.
+func (lib *LocalLibrary) fingerprintTracks() {
+	var cursor int64
+
+	for {
+		var tracks []track
+		work := func(db *sql.DB) error {
+			rows, err := db.Query(`
+				SELECT
+					id,
+					fs_path
+				FROM
+					tracks
+				WHERE
+					fingerprint IS NULL AND
+					id > ?
+				ORDER BY
+					id
+				LIMIT ?
+			`, cursor, batchLimit)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var tr track
+				if err := rows.Scan(&tr.id, &tr.fsPath); err != nil {
+					return err
+				}
+				tracks = append(tracks, tr)
+			}
+
+			return rows.Err()
+		}
+		if err := lib.ExecuteDBJobAndWait(work); err != nil {
+			log.Printf("Error getting tracks without fingerprints: %s\n", err)
+			return
+		}
+
+		for _, tr := range tracks {
+			cursor = tr.id
+
+			fingerprint := lib.fileFingerprint(tr.fsPath)
+			if fingerprint == "" {
+				continue
+			}
+
+			work := func(db *sql.DB) error {
+				_, err := db.Exec(`
+					UPDATE tracks
+					SET
+						fingerprint = ?
+					WHERE
+						id = ?
+				`, fingerprint, tr.id)
+				return err
+			}
+			if err := lib.ExecuteDBJobAndWait(work); err != nil {
+				log.Printf("Error storing fingerprint of track %d: %s\n", tr.id, err)
+				return
+			}
+		}
+
+		if len(tracks) < batchLimit {
+			return
+		}
+	}
+}
+
+// audioFingerprint returns a checksum of the audio data in `r` which is `size`
+// bytes long. The meta data of the file is not part of it. Only the length of
+// the audio data and samples from its start, middle and end are hashed so that
+// large files are not read whole.
+func audioFingerprint(r io.ReaderAt, size int64) (string, error) {
+	audio, err := findAudioData(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha1.New()
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(audio.length)))
+
+	for _, sample := range fingerprintSamples(audio.length) {
+		// Ogg page headers which start right before the sample could have
+		// their changing fields in it.
+		var lead int64
+		if audio.oggPages {
+			lead = min(sample.offset, oggPageChangingEnd-1)
+		}
+
+		buf := make([]byte, lead+sample.length)
+		if _, err := audio.ReadAt(buf, sample.offset-lead); err != nil {
+			return "", fmt.Errorf("reading audio data: %w", err)
+		}
+		if audio.oggPages {
+			clearOggPageChanges(buf)
+		}
+		h.Write(buf[lead:])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fingerprintSamples returns the parts of audio data with `length` which are
+// hashed for its fingerprint. Short audio is hashed whole.
+func fingerprintSamples(length int64) []fileSection {
+	if length <= 3*fingerprintSampleSize {
+		return []fileSection{{offset: 0, length: length}}
+	}
+
+	return []fileSection{
+		{offset: 0, length: fingerprintSampleSize},
+		{offset: (length - fingerprintSampleSize) / 2, length: fingerprintSampleSize},
+		{offset: length - fingerprintSampleSize, length: fingerprintSampleSize},
+	}
+}
+
+// clearOggPageChanges zeroes the page sequence numbers and checksums of the Ogg
+// page headers in `buf`. They change for all pages after the comment header
+// when it is rewritten with a different number of pages.
+func clearOggPageChanges(buf []byte) {
+	for start := 0; ; {
+		found := bytes.Index(buf[start:], []byte("OggS"))
+		if found < 0 {
+			return
+		}
+		found += start
+
+		clear(buf[min(found+18, len(buf)):min(found+oggPageChangingEnd, len(buf))])
+		start = found + 4
+	}
+}
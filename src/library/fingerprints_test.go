@@ -0,0 +1,497 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+)
+
+// TestFileFingerprintIgnoresTags checks that the fingerprint of a file does not
+// change when its tags are changed.
+func TestFileFingerprintIgnoresTags(t *testing.T) {
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	original := filepath.Join(projRoot, "test_files", "more_mp3s", "test_file_added.mp3")
+	data, err := os.ReadFile(original)
+	assert.NilErr(t, err, "reading test file")
+
+	// Replace the ID3v2 tag of the file with one which has only a title.
+	tagSize := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+	frame := append([]byte("TIT2\x00\x00\x00\x09\x00\x00\x03"), "Retagged"...)
+	retagged := append([]byte("ID3\x04\x00\x00\x00\x00\x00"), byte(len(frame)))
+	retagged = append(retagged, frame...)
+	retagged = append(retagged, data[10+tagSize:]...)
+
+	retaggedFile := filepath.Join(t.TempDir(), "retagged.mp3")
+	err = os.WriteFile(retaggedFile, retagged, 0600)
+	assert.NilErr(t, err, "writing retagged file")
+
+	lib := &LocalLibrary{fs: &osFS{}}
+	fingerprint := lib.fileFingerprint(original)
+	if fingerprint == "" {
+		t.Fatalf("expected fingerprint for %s", original)
+	}
+	assert.Equal(t, fingerprint, lib.fileFingerprint(retaggedFile), "retagged fingerprint")
+
+	changedAudio := bytes.Clone(retagged)
+	changedAudio[len(changedAudio)/2]++
+	changedFile := filepath.Join(t.TempDir(), "changed.mp3")
+	err = os.WriteFile(changedFile, changedAudio, 0600)
+	assert.NilErr(t, err, "writing changed file")
+
+	if fingerprint == lib.fileFingerprint(changedFile) {
+		t.Errorf("expected different fingerprint for file with different audio")
+	}
+}
+
+// TestOggFingerprintIgnoresTags checks that the fingerprint of an Ogg file does
+// not change when its comment header is rewritten with a different number of
+// pages. Then the sequence numbers and checksums of all pages after it change.
+func TestOggFingerprintIgnoresTags(t *testing.T) {
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	oggFile := filepath.Join(projRoot, "test_files", "ogg_files", "vorbis-tags.ogg")
+	data, err := os.ReadFile(oggFile)
+	assert.NilErr(t, err, "reading test file")
+
+	// Make the audio long enough so that only samples of it are hashed.
+	headers, audioStart := splitOggHeaders(t, data, 3)
+	long := bytes.Clone(data)
+	seq := uint32(bytes.Count(data, []byte("OggS")))
+	for page := range 100 {
+		payload := make([]byte, 255*16)
+		for ind := range payload {
+			payload[ind] = byte(page*31 + ind*7)
+		}
+		long = append(long, oggTestPage(0, uint64(20000+page*1000), seq, payload)...)
+		seq++
+	}
+
+	comment := []byte("\x03vorbis\x04\x00\x00\x00test\x01\x00\x00\x00")
+	tag := append([]byte("METADATA_BLOCK_PICTURE="), bytes.Repeat([]byte("A"), 100000)...)
+	comment = binary.LittleEndian.AppendUint32(comment, uint32(len(tag)))
+	comment = append(comment, tag...)
+	comment = append(comment, 1)
+
+	for _, original := range [][]byte{data, long} {
+		retagged := retagOgg(original, audioStart, headers[0], comment, headers[2])
+
+		originalFile := filepath.Join(t.TempDir(), "original.ogg")
+		err = os.WriteFile(originalFile, original, 0600)
+		assert.NilErr(t, err, "writing original file")
+
+		retaggedFile := filepath.Join(t.TempDir(), "retagged.ogg")
+		err = os.WriteFile(retaggedFile, retagged, 0600)
+		assert.NilErr(t, err, "writing retagged file")
+
+		lib := &LocalLibrary{fs: &osFS{}}
+		fingerprint := lib.fileFingerprint(originalFile)
+		if fingerprint == "" {
+			t.Fatalf("expected fingerprint for %s", originalFile)
+		}
+		assert.Equal(t, fingerprint, lib.fileFingerprint(retaggedFile), "retagged fingerprint")
+
+		changedAudio := bytes.Clone(retagged)
+		changedAudio[len(changedAudio)-1]++
+		changedFile := filepath.Join(t.TempDir(), "changed.ogg")
+		err = os.WriteFile(changedFile, changedAudio, 0600)
+		assert.NilErr(t, err, "writing changed file")
+
+		if fingerprint == lib.fileFingerprint(changedFile) {
+			t.Errorf("expected different fingerprint for file with different audio")
+		}
+	}
+}
+
+// TestFindAudioData checks that only the audio data is found in files with the
+// supported container formats and that their meta data is skipped.
+func TestFindAudioData(t *testing.T) {
+	audio := []byte("the audio data of the file")
+
+	apeFooter := append([]byte("APETAGEX"), make([]byte, 24)...)
+	binary.LittleEndian.PutUint32(apeFooter[12:16], 40)
+	apeTag := append(make([]byte, 8), apeFooter...)
+
+	mp4Box := func(name string, content []byte) []byte {
+		box := binary.BigEndian.AppendUint32(nil, uint32(8+len(content)))
+		return append(append(box, name...), content...)
+	}
+	wavChunk := func(name string, content []byte) []byte {
+		chunk := binary.LittleEndian.AppendUint32([]byte(name), uint32(len(content)))
+		return append(chunk, content...)
+	}
+	ebmlElement := func(id string, content []byte) []byte {
+		return append(append([]byte(id), 0x80|byte(len(content))), content...)
+	}
+
+	tests := []struct {
+		desc     string
+		file     []byte
+		expected []byte
+	}{
+		{
+			desc:     "MPEG with ID3v1 and APEv2 tags",
+			file:     concat(audio, apeTag, []byte("TAG"), make([]byte, 125)),
+			expected: audio,
+		},
+		{
+			desc: "FLAC",
+			file: concat(
+				[]byte("fLaC"),
+				[]byte{0x00, 0x00, 0x00, 0x02}, []byte("si"),
+				[]byte{0x84, 0x00, 0x00, 0x03}, []byte("tag"),
+				audio,
+			),
+			expected: audio,
+		},
+		{
+			desc: "MP4",
+			file: concat(
+				mp4Box("ftyp", []byte("M4A 0000")),
+				mp4Box("moov", []byte("the tags")),
+				mp4Box("mdat", audio),
+				mp4Box("free", []byte("padding")),
+			),
+			expected: audio,
+		},
+		{
+			desc: "WAVE",
+			file: concat(
+				[]byte("RIFF\x00\x00\x00\x00WAVE"),
+				wavChunk("fmt ", []byte("format")),
+				wavChunk("LIST", []byte("tags")),
+				wavChunk("data", audio),
+			),
+			expected: audio,
+		},
+		{
+			desc: "WebM",
+			file: concat(
+				ebmlElement("\x1a\x45\xdf\xa3", []byte("webm")),
+				ebmlElement("\x18\x53\x80\x67", concat(
+					ebmlElement("\x12\x54\xc3\x67", []byte("tags")),
+					ebmlElement("\x1f\x43\xb6\x75", audio),
+				)),
+			),
+			expected: ebmlElement("\x1f\x43\xb6\x75", audio),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			found, err := findAudioData(bytes.NewReader(test.file), int64(len(test.file)))
+			assert.NilErr(t, err, "finding audio data")
+
+			data := make([]byte, found.length)
+			_, err = found.ReadAt(data, 0)
+			assert.NilErr(t, err, "reading audio data")
+			assert.Equal(t, string(test.expected), string(data), "audio data")
+		})
+	}
+}
+
+// concat returns all `parts` joined together.
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// splitOggHeaders returns the first `count` packets of the Ogg stream in `data`
+// and the offset of the page after them.
+func splitOggHeaders(t *testing.T, data []byte, count int) ([][]byte, int) {
+	var (
+		packets [][]byte
+		packet  []byte
+		offset  int
+	)
+	for len(packets) < count {
+		if string(data[offset:offset+4]) != "OggS" {
+			t.Fatalf("expected Ogg page at offset %d", offset)
+		}
+		segments := data[offset+27 : offset+27+int(data[offset+26])]
+		payload := offset + 27 + len(segments)
+		for _, lacing := range segments {
+			packet = append(packet, data[payload:payload+int(lacing)]...)
+			payload += int(lacing)
+			if lacing < 255 {
+				packets = append(packets, packet)
+				packet = nil
+			}
+		}
+		offset = payload
+	}
+
+	return packets, offset
+}
+
+// retagOgg returns the Ogg stream in `data` with new header packets the way
+// tag editors write it. The audio pages which start at `audioStart` are kept
+// but renumbered.
+func retagOgg(data []byte, audioStart int, headers ...[]byte) []byte {
+	out := oggTestPage(2, 0, 0, headers[0])
+	seq := uint32(1)
+
+	var lacing, body []byte
+	for _, packet := range headers[1:] {
+		lacing = append(lacing, bytes.Repeat([]byte{255}, len(packet)/255)...)
+		lacing = append(lacing, byte(len(packet)%255))
+		body = append(body, packet...)
+	}
+	var continued bool
+	for len(lacing) > 0 {
+		segments := lacing[:min(len(lacing), 255)]
+		var size int
+		for _, lacingValue := range segments {
+			size += int(lacingValue)
+		}
+
+		var headerType byte
+		if continued {
+			headerType = 1
+		}
+		out = append(out, oggPageWithLacing(headerType, 0, seq, segments, body[:size])...)
+		seq++
+
+		continued = segments[len(segments)-1] == 255
+		lacing, body = lacing[len(segments):], body[size:]
+	}
+
+	for offset := audioStart; offset < len(data); {
+		segments := data[offset+27 : offset+27+int(data[offset+26])]
+		size := 27 + len(segments)
+		for _, lacingValue := range segments {
+			size += int(lacingValue)
+		}
+
+		page := bytes.Clone(data[offset : offset+size])
+		binary.LittleEndian.PutUint32(page[18:22], seq)
+		setOggChecksum(page)
+		out = append(out, page...)
+
+		seq++
+		offset += size
+	}
+
+	return out
+}
+
+// oggTestPage returns an Ogg page with a single packet.
+func oggTestPage(headerType byte, granule uint64, seq uint32, packet []byte) []byte {
+	lacing := bytes.Repeat([]byte{255}, len(packet)/255)
+	lacing = append(lacing, byte(len(packet)%255))
+	return oggPageWithLacing(headerType, granule, seq, lacing, packet)
+}
+
+// oggPageWithLacing returns an Ogg page with the `lacing` segment table and
+// `payload`.
+func oggPageWithLacing(
+	headerType byte,
+	granule uint64,
+	seq uint32,
+	lacing []byte,
+	payload []byte,
+) []byte {
+	page := append([]byte("OggS\x00"), headerType)
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = binary.LittleEndian.AppendUint32(page, 0x1234)
+	page = binary.LittleEndian.AppendUint32(page, seq)
+	page = binary.LittleEndian.AppendUint32(page, 0)
+	page = append(page, byte(len(lacing)))
+	page = append(page, lacing...)
+	page = append(page, payload...)
+	setOggChecksum(page)
+
+	return page
+}
+
+// setOggChecksum stores the checksum of the Ogg `page` in its header.
+func setOggChecksum(page []byte) {
+	binary.LittleEndian.PutUint32(page[22:26], 0)
+
+	var crc uint32
+	for _, b := range page {
+		crc ^= uint32(b) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	binary.LittleEndian.PutUint32(page[22:26], crc)
+}
+
+// TestMovedFilesKeepTracks checks that files which are moved or renamed keep
+// their tracks along with their ratings and plays.
+func TestMovedFilesKeepTracks(t *testing.T) {
+	projRoot, _ := helpers.ProjectRoot()
+	testFiles := filepath.Join(projRoot, "test_files")
+
+	srcTestMp3 := filepath.Join(testFiles, "more_mp3s", "test_file_added.mp3")
+	addedFile := filepath.Join(testFiles, "library", "test_file_added.mp3")
+	renamedFile := filepath.Join(testFiles, "library", "test_file_renamed.mp3")
+
+	if err := copyFile(srcTestMp3, addedFile); err != nil {
+		t.Fatalf("Copying file to library failed: %s", err)
+	}
+	defer os.Remove(addedFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lib := getLibraryDisabledWatching(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	found := lib.Search(ctx, SearchArgs{Query: "Added Song"})
+	if len(found) != 1 {
+		t.Fatalf("expected one 'Added Song' but found %d", len(found))
+	}
+	trackID := found[0].ID
+
+	assert.NilErr(t, lib.SetTrackRating(ctx, trackID, 4), "rating track")
+	err := lib.RecordTrackPlay(ctx, trackID, time.Now())
+	assert.NilErr(t, err, "recording track play")
+
+	if err := os.Rename(addedFile, renamedFile); err != nil {
+		t.Fatalf("renaming file: %s", err)
+	}
+	defer os.Remove(renamedFile)
+
+	modifiedBefore := lib.LastModified()
+	moved, err := lib.moveRemovedTrack(lib.fileFingerprint(renamedFile), renamedFile)
+	assert.NilErr(t, err, "moving removed track")
+	if !moved {
+		t.Fatalf("expected the renamed file to take the place of the removed one")
+	}
+	if !lib.LastModified().After(modifiedBefore) {
+		t.Errorf("expected moving a track to change the library modification time")
+	}
+
+	waitForLibraryScan(t, lib)
+
+	found = lib.Search(ctx, SearchArgs{Query: "Added Song"})
+	if len(found) != 1 {
+		t.Fatalf("expected one 'Added Song' after rename but found %d", len(found))
+	}
+	assert.Equal(t, trackID, found[0].ID, "track ID after rename")
+	assert.Equal(t, renamedFile, lib.GetFilePath(ctx, trackID), "track path")
+
+	track, err := lib.GetTrack(ctx, trackID)
+	assert.NilErr(t, err, "getting moved track")
+	assert.Equal(t, uint8(4), track.Rating, "rating of moved track")
+	assert.Equal(t, int64(1), track.Plays, "plays of moved track")
+}
+
+// TestWatchedMovedDirectoryKeepsTracks checks that tracks in directories which
+// are moved within the library keep their IDs.
+func TestWatchedMovedDirectoryKeepsTracks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+
+	projRoot, _ := helpers.ProjectRoot()
+	testFiles := filepath.Join(projRoot, "test_files")
+
+	firstPlace := filepath.Join(testFiles, "library", "to_be_renamed_directory")
+	secondPlace := filepath.Join(testFiles, "library", "renamed_directory")
+	srcTestMp3 := filepath.Join(testFiles, "more_mp3s", "test_file_added.mp3")
+
+	_ = os.RemoveAll(firstPlace)
+	_ = os.RemoveAll(secondPlace)
+
+	if err := os.Mkdir(firstPlace, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(firstPlace)
+
+	err := copyFile(srcTestMp3, filepath.Join(firstPlace, "test_file_added.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lib := getPathedLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+	lib.removedFilesWait = 5 * time.Second
+	waitForLibraryScan(t, lib)
+
+	found := lib.Search(ctx, SearchArgs{Query: "Added Song"})
+	if len(found) != 1 {
+		t.Fatalf("expected one 'Added Song' but found %d", len(found))
+	}
+	trackID := found[0].ID
+
+	if err := os.Rename(firstPlace, secondPlace); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(secondPlace)
+
+	time.Sleep(100 * time.Millisecond)
+
+	found = lib.Search(ctx, SearchArgs{Query: "Added Song"})
+	if len(found) != 1 {
+		t.Fatalf("expected one 'Added Song' after move but found %d", len(found))
+	}
+	assert.Equal(t, trackID, found[0].ID, "track ID after move")
+
+	expectedPath := filepath.Join(secondPlace, "test_file_added.mp3")
+	assert.Equal(t, expectedPath, lib.GetFilePath(ctx, trackID), "track path")
+}
+
+// TestPendingRemovals checks that files removed from the library directories are
+// removed from the library on rescan without waiting for them to be moved. And
+// that waiting removals are stopped when the library is closed.
+func TestPendingRemovals(t *testing.T) {
+	ctx := context.Background()
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	lib.removedFilesWait = time.Hour
+
+	removedPath := filepath.Join(t.TempDir(), "removed.mp3")
+	err = lib.insertMediaIntoDatabase(&MockMedia{
+		artist: "Artist",
+		album:  "Album",
+		title:  "Removed",
+		length: time.Minute,
+	}, fileInfo{FilePath: removedPath, Modified: time.Now()})
+	assert.NilErr(t, err, "inserting track")
+
+	lib.removeFileLater(removedPath)
+	assert.Equal(t, 1, lib.pendingRemovalsCount(), "pending removals")
+
+	assert.NilErr(t, lib.Rescan(ctx), "rescanning library")
+	assert.Equal(t, 0, lib.pendingRemovalsCount(), "pending removals after rescan")
+
+	found := lib.Search(ctx, SearchArgs{Query: "Removed"})
+	assert.Equal(t, 0, len(found), "tracks of removed files after rescan")
+
+	lib.removeFileLater(removedPath)
+	lib.Close()
+	assert.Equal(t, 0, lib.pendingRemovalsCount(), "pending removals after close")
+}
+
+func (lib *LocalLibrary) pendingRemovalsCount() int {
+	lib.pendingRemovalsLock.Lock()
+	defer lib.pendingRemovalsLock.Unlock()
+
+	return len(lib.pendingRemovals)
+}
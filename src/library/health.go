@@ -0,0 +1,411 @@
+package library
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// HealthIssueKind is the type of problem found in the library.
+type HealthIssueKind string
+
+// The following are all kinds of issues recorded in the library.
+const (
+	// IssueUnreadablePath is for files and directories which could not be
+	// read from the file system.
+	IssueUnreadablePath HealthIssueKind = "unreadable_path"
+
+	// IssueBadTags is for media files which meta data could not be parsed.
+	// Such files are not added to the library.
+	IssueBadTags HealthIssueKind = "bad_tags"
+
+	// IssueUnknownArtist is for tracks without an artist tag. They are filed
+	// under an artist named UnknownLabel.
+	IssueUnknownArtist HealthIssueKind = "unknown_artist"
+
+	// IssueUnknownAlbum is for tracks without an album tag. They are filed
+	// under an album named UnknownLabel.
+	IssueUnknownAlbum HealthIssueKind = "unknown_album"
+
+	// IssueZeroDuration is for tracks which duration could not be read.
+	IssueZeroDuration HealthIssueKind = "zero_duration"
+
+	// IssueMissingArtwork is for albums for which no artwork was found in their
+	// directory or on the internet.
+	IssueMissingArtwork HealthIssueKind = "missing_artwork"
+)
+
+// HealthIssueKinds lists all kinds of issues in the order they are reported.
+var HealthIssueKinds = []HealthIssueKind{
+	IssueUnreadablePath,
+	IssueBadTags,
+	IssueUnknownArtist,
+	IssueUnknownAlbum,
+	IssueZeroDuration,
+	IssueMissingArtwork,
+}
+
+//counterfeiter:generate . HealthReporter
+
+// HealthReporter is the interface for getting the problems found in the library
+// together with some statistics for it.
+type HealthReporter interface {
+	// LibraryHealth returns the totals for the library and all of the issues
+	// found in it while scanning and resolving album artwork.
+	LibraryHealth(ctx context.Context) (HealthReport, error)
+}
+
+// HealthReport describes the contents of the library and the problems in it.
+type HealthReport struct {
+	// Tracks is the number of tracks in the library.
+	Tracks int64
+
+	// Size is the sum of the sizes of all track files in bytes.
+	Size int64
+
+	// Duration is the sum of the durations of all tracks.
+	Duration time.Duration
+
+	// Formats are the totals for every file format in the library, ordered
+	// by the number of tracks.
+	Formats []FormatStats
+
+	// Issues are all problems found in the library, ordered by kind as in
+	// HealthIssueKinds and then by path.
+	Issues []HealthIssue
+}
+
+// FormatStats are the totals for the tracks with a single file format.
+type FormatStats struct {
+	// Format is the file format. Examples: "mp3", "flac", "ogg" etc.
+	Format string
+
+	// Tracks is the number of tracks with this format.
+	Tracks int64
+
+	// Size is the sum of the sizes of the files in bytes.
+	Size int64
+
+	// Duration is the sum of the durations of the tracks.
+	Duration time.Duration
+}
+
+// HealthIssue is a single problem found in the library.
+type HealthIssue struct {
+	Kind HealthIssueKind
+
+	// Path is the file system path of the file or the directory with the
+	// problem. For albums it is the album directory.
+	Path string
+
+	// TrackID is the ID of the track with the problem. It is zero for
+	// files which are not in the library and for albums.
+	TrackID int64
+
+	// AlbumID is the ID of the album with the problem. Zero when the
+	// problem is not with an album.
+	AlbumID int64
+
+	// Message describes the problem in more details. It may be empty.
+	Message string
+
+	// FoundAt is the last time the problem was found.
+	FoundAt time.Time
+}
+
+// LibraryHealth implements the HealthReporter interface.
+func (lib *LocalLibrary) LibraryHealth(ctx context.Context) (HealthReport, error) {
+	var report HealthReport
+
+	work := func(db *sql.DB) error {
+		formats, err := lib.formatStats(ctx, db)
+		if err != nil {
+			return err
+		}
+		report.Formats = formats
+
+		for _, format := range formats {
+			report.Tracks += format.Tracks
+			report.Size += format.Size
+			report.Duration += format.Duration
+		}
+
+		report.Issues, err = lib.healthIssues(ctx, db)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (lib *LocalLibrary) formatStats(ctx context.Context, db *sql.DB) ([]FormatStats, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			fs_path,
+			COALESCE(size, 0),
+			COALESCE(duration, 0)
+		FROM
+			tracks
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying tracks: %w", err)
+	}
+	defer rows.Close()
+
+	byFormat := make(map[string]*FormatStats)
+	for rows.Next() {
+		var (
+			path           string
+			size, duration int64
+		)
+		if err := rows.Scan(&path, &size, &duration); err != nil {
+			return nil, fmt.Errorf("scanning track: %w", err)
+		}
+
+		format := mediaFormatFromFileName(path)
+		stats, ok := byFormat[format]
+		if !ok {
+			stats = &FormatStats{Format: format}
+			byFormat[format] = stats
+		}
+
+		stats.Tracks++
+		stats.Size += size
+		stats.Duration += time.Duration(duration) * time.Millisecond
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating over tracks: %w", err)
+	}
+
+	formats := make([]FormatStats, 0, len(byFormat))
+	for _, stats := range byFormat {
+		formats = append(formats, *stats)
+	}
+	slices.SortFunc(formats, func(a, b FormatStats) int {
+		if c := cmp.Compare(b.Tracks, a.Tracks); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Format, b.Format)
+	})
+
+	return formats, nil
+}
+
+func (lib *LocalLibrary) healthIssues(ctx context.Context, db *sql.DB) ([]HealthIssue, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			kind,
+			fs_path,
+			COALESCE(track_id, 0),
+			COALESCE(album_id, 0),
+			message,
+			found_at
+		FROM
+			library_issues
+		ORDER BY
+			fs_path
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying library issues: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []HealthIssue
+	for rows.Next() {
+		var (
+			issue   HealthIssue
+			foundAt int64
+		)
+		err := rows.Scan(
+			&issue.Kind,
+			&issue.Path,
+			&issue.TrackID,
+			&issue.AlbumID,
+			&issue.Message,
+			&foundAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning library issue: %w", err)
+		}
+
+		issue.FoundAt = time.Unix(foundAt, 0)
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating over library issues: %w", err)
+	}
+
+	slices.SortStableFunc(issues, func(a, b HealthIssue) int {
+		return cmp.Compare(
+			slices.Index(HealthIssueKinds, a.Kind),
+			slices.Index(HealthIssueKinds, b.Kind),
+		)
+	})
+
+	return issues, nil
+}
+
+// recordFileIssue stores a problem of kind `kind` with the file or directory at
+// `path` which prevented it from being added to the library.
+func (lib *LocalLibrary) recordFileIssue(kind HealthIssueKind, path string, cause error) {
+	work := func(db *sql.DB) error {
+		return insertIssue(db, HealthIssue{
+			Kind:    kind,
+			Path:    path,
+			Message: cause.Error(),
+		})
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		log.Printf("Error recording %s issue for %s: %s\n", kind, path, err)
+	}
+}
+
+// recordTrackIssues replaces the issues stored for the file at `path` with the
+// problems found in its meta data. It is called every time the file is added to
+// the library or its track is updated.
+func (lib *LocalLibrary) recordTrackIssues(
+	trackID int64,
+	path, artist, album string,
+	duration time.Duration,
+) {
+	var issues []HealthIssue
+	addIssue := func(kind HealthIssueKind, message string) {
+		issues = append(issues, HealthIssue{
+			Kind:    kind,
+			Path:    path,
+			TrackID: trackID,
+			Message: message,
+		})
+	}
+
+	if artist == "" {
+		addIssue(IssueUnknownArtist, "missing artist tag")
+	}
+	if album == "" {
+		addIssue(IssueUnknownAlbum, "missing album tag")
+	}
+	if duration <= 0 {
+		addIssue(IssueZeroDuration, "could not read the track duration")
+	}
+
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`
+			DELETE FROM library_issues
+			WHERE
+				fs_path = ? AND
+				album_id IS NULL
+		`, path)
+		if err != nil {
+			return fmt.Errorf("removing old issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			if err := insertIssue(db, issue); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		log.Printf("Error recording issues for %s: %s\n", path, err)
+	}
+}
+
+// recordMissingArtwork stores that no artwork was found for the album with ID
+// `albumID`.
+func (lib *LocalLibrary) recordMissingArtwork(albumID int64) {
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`
+			INSERT INTO library_issues
+				(kind, fs_path, album_id, message, found_at)
+			SELECT
+				@kind, fs_path, id, @message, @found_at
+			FROM
+				albums
+			WHERE
+				id = @album_id
+			ON CONFLICT (kind, fs_path) DO UPDATE SET
+				album_id = excluded.album_id,
+				message = excluded.message,
+				found_at = excluded.found_at
+		`,
+			sql.Named("kind", IssueMissingArtwork),
+			sql.Named("message", "no artwork in the album directory or on the internet"),
+			sql.Named("found_at", time.Now().Unix()),
+			sql.Named("album_id", albumID),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		log.Printf("Error recording missing artwork for album %d: %s\n", albumID, err)
+	}
+}
+
+// resolveMissingArtwork removes the missing artwork issue for the album with
+// ID `albumID`.
+func (lib *LocalLibrary) resolveMissingArtwork(albumID int64) {
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`
+			DELETE FROM library_issues
+			WHERE
+				kind = ? AND
+				album_id = ?
+		`, IssueMissingArtwork, albumID)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		log.Printf("Error removing missing artwork issue for album %d: %s\n",
+			albumID, err)
+	}
+}
+
+// removeFileIssuesBefore removes the issues found while walking the library
+// directories which were not found again since `since`. Their files were
+// either fixed or removed.
+func (lib *LocalLibrary) removeFileIssuesBefore(since time.Time) {
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`
+			DELETE FROM library_issues
+			WHERE
+				kind IN (?, ?) AND
+				found_at < ?
+		`, IssueUnreadablePath, IssueBadTags, since.Unix())
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		log.Printf("Error removing old library issues: %s\n", err)
+	}
+}
+
+func insertIssue(db *sql.DB, issue HealthIssue) error {
+	trackID := sql.NullInt64{Int64: issue.TrackID, Valid: issue.TrackID != 0}
+
+	_, err := db.Exec(`
+		INSERT INTO library_issues
+			(kind, fs_path, track_id, message, found_at)
+		VALUES
+			(@kind, @fs_path, @track_id, @message, @found_at)
+		ON CONFLICT (kind, fs_path) DO UPDATE SET
+			track_id = excluded.track_id,
+			message = excluded.message,
+			found_at = excluded.found_at
+	`,
+		sql.Named("kind", issue.Kind),
+		sql.Named("fs_path", issue.Path),
+		sql.Named("track_id", trackID),
+		sql.Named("message", issue.Message),
+		sql.Named("found_at", time.Now().Unix()),
+	)
+	if err != nil {
+		return fmt.Errorf("storing %s issue: %w", issue.Kind, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/art"
+	"github.com/ironsmile/euterpe/src/art/artfakes"
+	"github.com/ironsmile/euterpe/src/assert"
+)
+
+// TestLibraryHealth checks that problems found while adding files and resolving
+// album artwork are reported together with the library totals. And that they are
+// removed once fixed.
+func TestLibraryHealth(t *testing.T) {
+	ctx := context.Background()
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	lib.SetArtFinder(&artfakes.FakeFinder{
+		GetFrontImageStub: func(context.Context, string, string) ([]byte, error) {
+			return nil, art.ErrImageNotFound
+		},
+	})
+
+	const (
+		goodPath      = "music/album/good.flac"
+		noArtistPath  = "music/album/no-artist.mp3"
+		noAlbumPath   = "music/other/no-album.mp3"
+		brokenPath    = "music/other/broken.mp3"
+		missingPath   = "music/other/missing.mp3"
+		goodSize      = 3000
+		noArtistSize  = 1000
+		noAlbumSize   = 2000
+		goodLength    = 3 * time.Minute
+		noAlbumLength = 2 * time.Minute
+	)
+
+	lib.fs = fstest.MapFS{
+		goodPath: &fstest.MapFile{
+			Data:    []byte("good-file"),
+			ModTime: time.Now(),
+		},
+		brokenPath: &fstest.MapFile{
+			Data:    []byte("not really an mp3"),
+			ModTime: time.Now(),
+		},
+	}
+
+	tracks := []struct {
+		media MockMedia
+		info  fileInfo
+	}{
+		{
+			media: MockMedia{
+				artist: "Testy Testov",
+				album:  "The Test Strikes Back",
+				title:  "Good Tags",
+				length: goodLength,
+			},
+			info: fileInfo{FilePath: goodPath, Size: goodSize},
+		},
+		{
+			media: MockMedia{
+				album: "The Test Strikes Back",
+				title: "No Artist",
+			},
+			info: fileInfo{FilePath: noArtistPath, Size: noArtistSize},
+		},
+		{
+			media: MockMedia{
+				artist: "Testy Testov",
+				title:  "No Album",
+				length: noAlbumLength,
+			},
+			info: fileInfo{FilePath: noAlbumPath, Size: noAlbumSize},
+		},
+	}
+	for _, track := range tracks {
+		track.info.Modified = time.Now()
+		if err := lib.insertMediaIntoDatabase(&track.media, track.info); err != nil {
+			t.Fatalf("inserting %s failed: %s", track.info.FilePath, err)
+		}
+	}
+
+	if _, err := lib.addMedia(brokenPath); err == nil {
+		t.Errorf("expected error for adding file with broken tags")
+	}
+	if _, err := lib.addMedia(missingPath); err == nil {
+		t.Errorf("expected error for adding missing file")
+	}
+
+	albumID, err := lib.GetAlbumID("The Test Strikes Back", path.Dir(goodPath))
+	assert.NilErr(t, err, "getting album ID")
+
+	_, err = lib.FindAndSaveAlbumArtwork(ctx, albumID, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Fatalf("expected artwork not found but got %v", err)
+	}
+
+	report, err := lib.LibraryHealth(ctx)
+	assert.NilErr(t, err, "getting library health")
+
+	assert.Equal(t, int64(3), report.Tracks, "number of tracks")
+	assert.Equal(t, int64(goodSize+noArtistSize+noAlbumSize), report.Size, "size")
+	assert.Equal(t, goodLength+noAlbumLength, report.Duration, "duration")
+	assert.Equal(t, 2, len(report.Formats), "number of formats")
+	if len(report.Formats) == 2 {
+		assert.Equal(t, "mp3", report.Formats[0].Format, "most used format")
+		assert.Equal(t, int64(2), report.Formats[0].Tracks, "mp3 tracks")
+		assert.Equal(t, int64(noArtistSize+noAlbumSize), report.Formats[0].Size, "mp3 size")
+		assert.Equal(t, "flac", report.Formats[1].Format, "second format")
+		assert.Equal(t, goodLength, report.Formats[1].Duration, "flac duration")
+	}
+
+	expected := []struct {
+		kind HealthIssueKind
+		path string
+	}{
+		{kind: IssueUnreadablePath, path: missingPath},
+		{kind: IssueBadTags, path: brokenPath},
+		{kind: IssueUnknownArtist, path: noArtistPath},
+		{kind: IssueUnknownAlbum, path: noAlbumPath},
+		{kind: IssueZeroDuration, path: noArtistPath},
+		{kind: IssueMissingArtwork, path: path.Dir(goodPath)},
+	}
+	assert.Equal(t, len(expected), len(report.Issues), "number of issues")
+	for i, issue := range report.Issues {
+		if i >= len(expected) {
+			break
+		}
+
+		assert.Equal(t, expected[i].kind, issue.Kind, "issue kind")
+		assert.Equal(t, expected[i].path, issue.Path, "issue path")
+		if issue.Kind == IssueMissingArtwork {
+			assert.Equal(t, albumID, issue.AlbumID, "album of missing artwork")
+		}
+		if issue.Kind == IssueUnknownArtist && issue.TrackID == 0 {
+			t.Errorf("expected track ID for issue with %s", issue.Path)
+		}
+	}
+
+	// Fix the problems and make sure they are no longer reported.
+	fixed := tracks[1]
+	fixed.media.artist = "Testy Testov"
+	fixed.media.length = time.Minute
+	if err := lib.insertMediaIntoDatabase(&fixed.media, fixed.info); err != nil {
+		t.Fatalf("updating %s failed: %s", fixed.info.FilePath, err)
+	}
+
+	err = lib.SaveAlbumArtwork(ctx, albumID, bytes.NewReader([]byte("cover")))
+	assert.NilErr(t, err, "saving album artwork")
+
+	lib.removeFileIssuesBefore(time.Now().Add(time.Second))
+
+	report, err = lib.LibraryHealth(ctx)
+	assert.NilErr(t, err, "getting library health after fixes")
+	assert.Equal(t, 1, len(report.Issues), "number of issues after fixes")
+	if len(report.Issues) == 1 {
+		assert.Equal(t, IssueUnknownAlbum, report.Issues[0].Kind, "remaining issue")
+	}
+}
@@ -71,6 +71,58 @@ type SearchResult struct {
 	//
 	// Not encoded in the JSON response the API for the moment.
 	CreatedAt int64 `json:"-"`
+
+	// Genre is the genre of this media file as found in its tags.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	Genre string `json:"-"`
+
+	// Comment is the comment tag of this media file.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	Comment string `json:"-"`
+
+	// MusicFolderID is the ID of the music folder in which the media file is.
+	// It is zero for files which are not in any of the music folders.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	MusicFolderID int64 `json:"-"`
+
+	// SortName is the title by which this track is sorted as found in its tags.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	SortName string `json:"-"`
+
+	// MusicBrainzID is the MusicBrainz recording ID of this track.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	MusicBrainzID string `json:"-"`
+
+	// ISRCs are the International Standard Recording Codes of this track.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	ISRCs []string `json:"-"`
+
+	// BPM is the number of beats per minute of this track. Zero when unknown.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	BPM int `json:"-"`
+
+	// Disc is the number of the disc in the album on which this track is. Zero
+	// when unknown.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	Disc int `json:"-"`
+
+	// DiscSubtitle is the title of the disc on which this track is.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	DiscSubtitle string `json:"-"`
+
+	// Missing is true for playlist entries which tracks are no longer in the
+	// library. Only the title, artist, album and duration which the playlist
+	// remembers are set for them.
+	Missing bool `json:"missing,omitempty"`
 }
 
 // SearchArgs is the input parameters for searching in the library.
@@ -86,6 +138,27 @@ type SearchArgs struct {
 	// Count limits the number of items returned by a search. A Count of zero
 	// means "no limit".
 	Count uint32
+
+	// MusicFolderIDs limits the search results to items which are in any of these
+	// music folders. An empty list means "all music folders".
+	MusicFolderIDs []int64
+
+	// NewerThan limits the search results to items with tracks which were added
+	// to the library after this time. The zero value means "no limit".
+	NewerThan time.Time
+}
+
+// MusicFolder is a single library root directory.
+type MusicFolder struct {
+	// ID is stable between restarts for the same directory.
+	ID int64 `json:"id"`
+
+	// Name is a unique human readable name for the folder. It is the name of
+	// the directory itself unless another folder already has this name.
+	Name string `json:"name"`
+
+	// Path is the file system path of the directory.
+	Path string `json:"-"`
 }
 
 // TrackInfo contains information for a single media file.
@@ -137,6 +210,31 @@ type Album struct {
 	// AvgBitrate is the average bitrate of the songs in the album. Measured in
 	// bits per second.
 	AvgBitrate uint64 `json:"avg_bitrate,omitempty"`
+
+	// SortName is the name by which the album is sorted as found in the tags
+	// of its tracks.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	SortName string `json:"-"`
+
+	// MusicBrainzID is the MusicBrainz release ID of the album.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	MusicBrainzID string `json:"-"`
+
+	// ReleaseDate and OriginalReleaseDate are the dates on which this release
+	// of the album and its first release have been released. They are in one of
+	// the "YYYY", "YYYY-MM" and "YYYY-MM-DD" forms as found in the tags.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	ReleaseDate         string `json:"-"`
+	OriginalReleaseDate string `json:"-"`
+
+	// Compilation is true for albums which tracks are tagged as being part of
+	// a compilation.
+	//
+	// Not encoded in the JSON response the API for the moment.
+	Compilation bool `json:"-"`
 }
 
 // Favourites describes a set of favourite tracks, artists and albums.
@@ -158,6 +256,25 @@ type Library interface {
 	// will be started.
 	AddLibraryPath(directory string)
 
+	// MusicFolders returns all library directories which were added using
+	// AddLibraryPath.
+	MusicFolders(ctx context.Context) ([]MusicFolder, error)
+
+	// TrackInMusicFolders returns true when the track with ID `trackID` is in
+	// any of the music folders with IDs `folderIDs`. An empty list of folders
+	// means that any music folder will do.
+	TrackInMusicFolders(ctx context.Context, trackID int64, folderIDs []int64) (bool, error)
+
+	// AlbumInMusicFolders returns true when the album with ID `albumID` has a
+	// track in any of the music folders with IDs `folderIDs`. An empty list of
+	// folders means that any music folder will do.
+	AlbumInMusicFolders(ctx context.Context, albumID int64, folderIDs []int64) (bool, error)
+
+	// ArtistInMusicFolders returns true when the artist with ID `artistID` has
+	// a track in any of the music folders with IDs `folderIDs`. An empty list
+	// of folders means that any music folder will do.
+	ArtistInMusicFolders(ctx context.Context, artistID int64, folderIDs []int64) (bool, error)
+
 	// Search the library using a search string. It will match against Artist, Album
 	// and Title. Will OR the results. So it is "return anything which Artist matches or
 	// Album matches or Title matches".
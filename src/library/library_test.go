@@ -39,6 +39,10 @@ func init() {
 		devnull, _ := os.Create(os.DevNull)
 		log.SetOutput(devnull)
 	}
+
+	// Tests which remove files from the library do not wait for them to be
+	// possibly moved.
+	defaultRemovedFilesWait = 20 * time.Millisecond
 }
 
 func getTestLibraryPath() (string, error) {
@@ -1441,6 +1445,132 @@ func TestLocalLibraryGetArtistAlbums(t *testing.T) {
 	}
 }
 
+// TestTrackGenreAndComment makes sure that the genre and the comment tags of
+// media files are stored and returned with their tracks.
+func TestTrackGenreAndComment(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lib := getPathedLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	const trackPath = "/media/genres/track-1.mp3"
+	media := MockMedia{
+		artist:  "Genre Artist",
+		album:   "Genre Album",
+		title:   "Genre Song",
+		track:   1,
+		length:  200 * time.Second,
+		genre:   "Trip Hop",
+		comment: "Recorded live",
+	}
+	err := lib.insertMediaIntoDatabase(&media, fileInfo{
+		FilePath: trackPath,
+		Modified: time.Now(),
+	})
+	assert.NilErr(t, err, "inserting media file")
+
+	found := lib.Search(ctx, SearchArgs{Query: media.title, Count: 1})
+	if len(found) != 1 {
+		t.Fatalf("expected one track but found %d", len(found))
+	}
+	assert.Equal(t, media.genre, found[0].Genre, "track genre")
+	assert.Equal(t, media.comment, found[0].Comment, "track comment")
+
+	// Rescanning a file without these tags removes them.
+	media.genre, media.comment = "", ""
+	err = lib.insertMediaIntoDatabase(&media, fileInfo{
+		FilePath: trackPath,
+		Modified: time.Now(),
+	})
+	assert.NilErr(t, err, "updating media file")
+
+	track, err := lib.GetTrack(ctx, found[0].ID)
+	assert.NilErr(t, err, "getting track")
+	assert.Equal(t, "", track.Genre, "track genre after update")
+	assert.Equal(t, "", track.Comment, "track comment after update")
+}
+
+// TestTrackProperties makes sure that the additional tag properties of media
+// files are stored and returned for tracks and their albums.
+func TestTrackProperties(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lib := getPathedLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	props := MediaProperties{
+		SortName:            "Song, The",
+		MusicBrainzID:       "e5a3f8a0-0b8d-4d46-9a27-6b2a4bb1f0c3",
+		ISRCs:               []string{"USRC17607839", "GBAYE0601498"},
+		BPM:                 120,
+		Disc:                2,
+		DiscSubtitle:        "Live",
+		AlbumSortName:       "Album, The",
+		AlbumMusicBrainzID:  "0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1",
+		ReleaseDate:         "2020-05-01",
+		OriginalReleaseDate: "1999",
+		Compilation:         true,
+	}
+	media := MockMedia{
+		artist:     "Properties Artist",
+		album:      "Properties Album",
+		title:      "Properties Song",
+		track:      1,
+		length:     200 * time.Second,
+		properties: props,
+	}
+	err := lib.insertMediaIntoDatabase(&media, fileInfo{
+		FilePath: "/media/properties/track-1.mp3",
+		Modified: time.Now(),
+	})
+	assert.NilErr(t, err, "inserting media file")
+
+	found := lib.Search(ctx, SearchArgs{Query: media.title, Count: 1})
+	if len(found) != 1 {
+		t.Fatalf("expected one track but found %d", len(found))
+	}
+
+	track := found[0]
+	assert.Equal(t, props.SortName, track.SortName, "track sort name")
+	assert.Equal(t, props.MusicBrainzID, track.MusicBrainzID, "track MBID")
+	assert.Equal(t, props.BPM, track.BPM, "track BPM")
+	assert.Equal(t, props.Disc, track.Disc, "track disc")
+	assert.Equal(t, props.DiscSubtitle, track.DiscSubtitle, "track disc subtitle")
+	if !slices.Equal(props.ISRCs, track.ISRCs) {
+		t.Errorf("expected ISRCs %v but got %v", props.ISRCs, track.ISRCs)
+	}
+
+	album, err := lib.GetAlbum(ctx, track.AlbumID)
+	assert.NilErr(t, err, "getting album")
+	assert.Equal(t, props.AlbumSortName, album.SortName, "album sort name")
+	assert.Equal(t, props.AlbumMusicBrainzID, album.MusicBrainzID, "album MBID")
+	assert.Equal(t, props.ReleaseDate, album.ReleaseDate, "album release date")
+	assert.Equal(
+		t, props.OriginalReleaseDate, album.OriginalReleaseDate,
+		"album original release date",
+	)
+	assert.Equal(t, props.Compilation, album.Compilation, "album compilation")
+
+	// Rescanning a file without these tags removes them.
+	media.properties = MediaProperties{}
+	err = lib.insertMediaIntoDatabase(&media, fileInfo{
+		FilePath: "/media/properties/track-1.mp3",
+		Modified: time.Now(),
+	})
+	assert.NilErr(t, err, "updating media file")
+
+	track, err = lib.GetTrack(ctx, track.ID)
+	assert.NilErr(t, err, "getting track")
+	assert.Equal(t, "", track.SortName, "track sort name after update")
+	assert.Equal(t, 0, len(track.ISRCs), "track ISRCs after update")
+	assert.Equal(t, 0, track.Disc, "track disc after update")
+
+	album, err = lib.GetAlbum(ctx, track.AlbumID)
+	assert.NilErr(t, err, "getting album after update")
+	assert.Equal(t, "", album.ReleaseDate, "album release date after update")
+	assert.Equal(t, false, album.Compilation, "album compilation after update")
+}
+
 // getTestMigrationFiles returns the SQLs directory used by the application itself
 // normally. This way tests will be done with the exact same files which will be
 // bundled into the binary on build.
@@ -2,7 +2,6 @@ package library
 
 import (
 	"context"
-	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,26 +10,6 @@ import (
 	"github.com/ironsmile/euterpe/src/helpers"
 )
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer in.Close()
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-	_, err = io.Copy(out, in)
-	cerr := out.Close()
-	if err != nil {
-		return err
-	}
-	return cerr
-}
-
 func TestMovingFileIntoLibrary(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
@@ -3,6 +3,7 @@ package libraryfakes
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ironsmile/euterpe/src/library"
 )
@@ -47,6 +48,16 @@ type FakeBrowser struct {
 		result1 []library.TrackInfo
 		result2 int
 	}
+	LastModifiedStub        func() time.Time
+	lastModifiedMutex       sync.RWMutex
+	lastModifiedArgsForCall []struct {
+	}
+	lastModifiedReturns struct {
+		result1 time.Time
+	}
+	lastModifiedReturnsOnCall map[int]struct {
+		result1 time.Time
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -243,6 +254,59 @@ func (fake *FakeBrowser) BrowseTracksReturnsOnCall(i int, result1 []library.Trac
 	}{result1, result2}
 }
 
+func (fake *FakeBrowser) LastModified() time.Time {
+	fake.lastModifiedMutex.Lock()
+	ret, specificReturn := fake.lastModifiedReturnsOnCall[len(fake.lastModifiedArgsForCall)]
+	fake.lastModifiedArgsForCall = append(fake.lastModifiedArgsForCall, struct {
+	}{})
+	stub := fake.LastModifiedStub
+	fakeReturns := fake.lastModifiedReturns
+	fake.recordInvocation("LastModified", []interface{}{})
+	fake.lastModifiedMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBrowser) LastModifiedCallCount() int {
+	fake.lastModifiedMutex.RLock()
+	defer fake.lastModifiedMutex.RUnlock()
+	return len(fake.lastModifiedArgsForCall)
+}
+
+func (fake *FakeBrowser) LastModifiedCalls(stub func() time.Time) {
+	fake.lastModifiedMutex.Lock()
+	defer fake.lastModifiedMutex.Unlock()
+	fake.LastModifiedStub = stub
+}
+
+func (fake *FakeBrowser) LastModifiedReturns(result1 time.Time) {
+	fake.lastModifiedMutex.Lock()
+	defer fake.lastModifiedMutex.Unlock()
+	fake.LastModifiedStub = nil
+	fake.lastModifiedReturns = struct {
+		result1 time.Time
+	}{result1}
+}
+
+func (fake *FakeBrowser) LastModifiedReturnsOnCall(i int, result1 time.Time) {
+	fake.lastModifiedMutex.Lock()
+	defer fake.lastModifiedMutex.Unlock()
+	fake.LastModifiedStub = nil
+	if fake.lastModifiedReturnsOnCall == nil {
+		fake.lastModifiedReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+		})
+	}
+	fake.lastModifiedReturnsOnCall[i] = struct {
+		result1 time.Time
+	}{result1}
+}
+
 func (fake *FakeBrowser) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -252,6 +316,8 @@ func (fake *FakeBrowser) Invocations() map[string][][]interface{} {
 	defer fake.browseArtistsMutex.RUnlock()
 	fake.browseTracksMutex.RLock()
 	defer fake.browseTracksMutex.RUnlock()
+	fake.lastModifiedMutex.RLock()
+	defer fake.lastModifiedMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -26,6 +26,36 @@ type FakeLibrary struct {
 	addMediaReturnsOnCall map[int]struct {
 		result1 error
 	}
+	AlbumInMusicFoldersStub        func(context.Context, int64, []int64) (bool, error)
+	albumInMusicFoldersMutex       sync.RWMutex
+	albumInMusicFoldersArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}
+	albumInMusicFoldersReturns struct {
+		result1 bool
+		result2 error
+	}
+	albumInMusicFoldersReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ArtistInMusicFoldersStub        func(context.Context, int64, []int64) (bool, error)
+	artistInMusicFoldersMutex       sync.RWMutex
+	artistInMusicFoldersArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}
+	artistInMusicFoldersReturns struct {
+		result1 bool
+		result2 error
+	}
+	artistInMusicFoldersReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	CloseStub        func()
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct {
@@ -118,6 +148,19 @@ type FakeLibrary struct {
 	initializeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MusicFoldersStub        func(context.Context) ([]library.MusicFolder, error)
+	musicFoldersMutex       sync.RWMutex
+	musicFoldersArgsForCall []struct {
+		arg1 context.Context
+	}
+	musicFoldersReturns struct {
+		result1 []library.MusicFolder
+		result2 error
+	}
+	musicFoldersReturnsOnCall map[int]struct {
+		result1 []library.MusicFolder
+		result2 error
+	}
 	RecordFavouriteStub        func(context.Context, library.Favourites) error
 	recordFavouriteMutex       sync.RWMutex
 	recordFavouriteArgsForCall []struct {
@@ -234,6 +277,21 @@ type FakeLibrary struct {
 	setTrackRatingReturnsOnCall map[int]struct {
 		result1 error
 	}
+	TrackInMusicFoldersStub        func(context.Context, int64, []int64) (bool, error)
+	trackInMusicFoldersMutex       sync.RWMutex
+	trackInMusicFoldersArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}
+	trackInMusicFoldersReturns struct {
+		result1 bool
+		result2 error
+	}
+	trackInMusicFoldersReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	TruncateStub        func() error
 	truncateMutex       sync.RWMutex
 	truncateArgsForCall []struct {
@@ -341,6 +399,148 @@ func (fake *FakeLibrary) AddMediaReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeLibrary) AlbumInMusicFolders(arg1 context.Context, arg2 int64, arg3 []int64) (bool, error) {
+	var arg3Copy []int64
+	if arg3 != nil {
+		arg3Copy = make([]int64, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.albumInMusicFoldersMutex.Lock()
+	ret, specificReturn := fake.albumInMusicFoldersReturnsOnCall[len(fake.albumInMusicFoldersArgsForCall)]
+	fake.albumInMusicFoldersArgsForCall = append(fake.albumInMusicFoldersArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}{arg1, arg2, arg3Copy})
+	stub := fake.AlbumInMusicFoldersStub
+	fakeReturns := fake.albumInMusicFoldersReturns
+	fake.recordInvocation("AlbumInMusicFolders", []interface{}{arg1, arg2, arg3Copy})
+	fake.albumInMusicFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLibrary) AlbumInMusicFoldersCallCount() int {
+	fake.albumInMusicFoldersMutex.RLock()
+	defer fake.albumInMusicFoldersMutex.RUnlock()
+	return len(fake.albumInMusicFoldersArgsForCall)
+}
+
+func (fake *FakeLibrary) AlbumInMusicFoldersCalls(stub func(context.Context, int64, []int64) (bool, error)) {
+	fake.albumInMusicFoldersMutex.Lock()
+	defer fake.albumInMusicFoldersMutex.Unlock()
+	fake.AlbumInMusicFoldersStub = stub
+}
+
+func (fake *FakeLibrary) AlbumInMusicFoldersArgsForCall(i int) (context.Context, int64, []int64) {
+	fake.albumInMusicFoldersMutex.RLock()
+	defer fake.albumInMusicFoldersMutex.RUnlock()
+	argsForCall := fake.albumInMusicFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeLibrary) AlbumInMusicFoldersReturns(result1 bool, result2 error) {
+	fake.albumInMusicFoldersMutex.Lock()
+	defer fake.albumInMusicFoldersMutex.Unlock()
+	fake.AlbumInMusicFoldersStub = nil
+	fake.albumInMusicFoldersReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLibrary) AlbumInMusicFoldersReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.albumInMusicFoldersMutex.Lock()
+	defer fake.albumInMusicFoldersMutex.Unlock()
+	fake.AlbumInMusicFoldersStub = nil
+	if fake.albumInMusicFoldersReturnsOnCall == nil {
+		fake.albumInMusicFoldersReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.albumInMusicFoldersReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLibrary) ArtistInMusicFolders(arg1 context.Context, arg2 int64, arg3 []int64) (bool, error) {
+	var arg3Copy []int64
+	if arg3 != nil {
+		arg3Copy = make([]int64, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.artistInMusicFoldersMutex.Lock()
+	ret, specificReturn := fake.artistInMusicFoldersReturnsOnCall[len(fake.artistInMusicFoldersArgsForCall)]
+	fake.artistInMusicFoldersArgsForCall = append(fake.artistInMusicFoldersArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}{arg1, arg2, arg3Copy})
+	stub := fake.ArtistInMusicFoldersStub
+	fakeReturns := fake.artistInMusicFoldersReturns
+	fake.recordInvocation("ArtistInMusicFolders", []interface{}{arg1, arg2, arg3Copy})
+	fake.artistInMusicFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLibrary) ArtistInMusicFoldersCallCount() int {
+	fake.artistInMusicFoldersMutex.RLock()
+	defer fake.artistInMusicFoldersMutex.RUnlock()
+	return len(fake.artistInMusicFoldersArgsForCall)
+}
+
+func (fake *FakeLibrary) ArtistInMusicFoldersCalls(stub func(context.Context, int64, []int64) (bool, error)) {
+	fake.artistInMusicFoldersMutex.Lock()
+	defer fake.artistInMusicFoldersMutex.Unlock()
+	fake.ArtistInMusicFoldersStub = stub
+}
+
+func (fake *FakeLibrary) ArtistInMusicFoldersArgsForCall(i int) (context.Context, int64, []int64) {
+	fake.artistInMusicFoldersMutex.RLock()
+	defer fake.artistInMusicFoldersMutex.RUnlock()
+	argsForCall := fake.artistInMusicFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeLibrary) ArtistInMusicFoldersReturns(result1 bool, result2 error) {
+	fake.artistInMusicFoldersMutex.Lock()
+	defer fake.artistInMusicFoldersMutex.Unlock()
+	fake.ArtistInMusicFoldersStub = nil
+	fake.artistInMusicFoldersReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLibrary) ArtistInMusicFoldersReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.artistInMusicFoldersMutex.Lock()
+	defer fake.artistInMusicFoldersMutex.Unlock()
+	fake.ArtistInMusicFoldersStub = nil
+	if fake.artistInMusicFoldersReturnsOnCall == nil {
+		fake.artistInMusicFoldersReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.artistInMusicFoldersReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLibrary) Close() {
 	fake.closeMutex.Lock()
 	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
@@ -799,6 +999,70 @@ func (fake *FakeLibrary) InitializeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeLibrary) MusicFolders(arg1 context.Context) ([]library.MusicFolder, error) {
+	fake.musicFoldersMutex.Lock()
+	ret, specificReturn := fake.musicFoldersReturnsOnCall[len(fake.musicFoldersArgsForCall)]
+	fake.musicFoldersArgsForCall = append(fake.musicFoldersArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.MusicFoldersStub
+	fakeReturns := fake.musicFoldersReturns
+	fake.recordInvocation("MusicFolders", []interface{}{arg1})
+	fake.musicFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLibrary) MusicFoldersCallCount() int {
+	fake.musicFoldersMutex.RLock()
+	defer fake.musicFoldersMutex.RUnlock()
+	return len(fake.musicFoldersArgsForCall)
+}
+
+func (fake *FakeLibrary) MusicFoldersCalls(stub func(context.Context) ([]library.MusicFolder, error)) {
+	fake.musicFoldersMutex.Lock()
+	defer fake.musicFoldersMutex.Unlock()
+	fake.MusicFoldersStub = stub
+}
+
+func (fake *FakeLibrary) MusicFoldersArgsForCall(i int) context.Context {
+	fake.musicFoldersMutex.RLock()
+	defer fake.musicFoldersMutex.RUnlock()
+	argsForCall := fake.musicFoldersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLibrary) MusicFoldersReturns(result1 []library.MusicFolder, result2 error) {
+	fake.musicFoldersMutex.Lock()
+	defer fake.musicFoldersMutex.Unlock()
+	fake.MusicFoldersStub = nil
+	fake.musicFoldersReturns = struct {
+		result1 []library.MusicFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLibrary) MusicFoldersReturnsOnCall(i int, result1 []library.MusicFolder, result2 error) {
+	fake.musicFoldersMutex.Lock()
+	defer fake.musicFoldersMutex.Unlock()
+	fake.MusicFoldersStub = nil
+	if fake.musicFoldersReturnsOnCall == nil {
+		fake.musicFoldersReturnsOnCall = make(map[int]struct {
+			result1 []library.MusicFolder
+			result2 error
+		})
+	}
+	fake.musicFoldersReturnsOnCall[i] = struct {
+		result1 []library.MusicFolder
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLibrary) RecordFavourite(arg1 context.Context, arg2 library.Favourites) error {
 	fake.recordFavouriteMutex.Lock()
 	ret, specificReturn := fake.recordFavouriteReturnsOnCall[len(fake.recordFavouriteArgsForCall)]
@@ -1385,6 +1649,77 @@ func (fake *FakeLibrary) SetTrackRatingReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeLibrary) TrackInMusicFolders(arg1 context.Context, arg2 int64, arg3 []int64) (bool, error) {
+	var arg3Copy []int64
+	if arg3 != nil {
+		arg3Copy = make([]int64, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.trackInMusicFoldersMutex.Lock()
+	ret, specificReturn := fake.trackInMusicFoldersReturnsOnCall[len(fake.trackInMusicFoldersArgsForCall)]
+	fake.trackInMusicFoldersArgsForCall = append(fake.trackInMusicFoldersArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 []int64
+	}{arg1, arg2, arg3Copy})
+	stub := fake.TrackInMusicFoldersStub
+	fakeReturns := fake.trackInMusicFoldersReturns
+	fake.recordInvocation("TrackInMusicFolders", []interface{}{arg1, arg2, arg3Copy})
+	fake.trackInMusicFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLibrary) TrackInMusicFoldersCallCount() int {
+	fake.trackInMusicFoldersMutex.RLock()
+	defer fake.trackInMusicFoldersMutex.RUnlock()
+	return len(fake.trackInMusicFoldersArgsForCall)
+}
+
+func (fake *FakeLibrary) TrackInMusicFoldersCalls(stub func(context.Context, int64, []int64) (bool, error)) {
+	fake.trackInMusicFoldersMutex.Lock()
+	defer fake.trackInMusicFoldersMutex.Unlock()
+	fake.TrackInMusicFoldersStub = stub
+}
+
+func (fake *FakeLibrary) TrackInMusicFoldersArgsForCall(i int) (context.Context, int64, []int64) {
+	fake.trackInMusicFoldersMutex.RLock()
+	defer fake.trackInMusicFoldersMutex.RUnlock()
+	argsForCall := fake.trackInMusicFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeLibrary) TrackInMusicFoldersReturns(result1 bool, result2 error) {
+	fake.trackInMusicFoldersMutex.Lock()
+	defer fake.trackInMusicFoldersMutex.Unlock()
+	fake.TrackInMusicFoldersStub = nil
+	fake.trackInMusicFoldersReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLibrary) TrackInMusicFoldersReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.trackInMusicFoldersMutex.Lock()
+	defer fake.trackInMusicFoldersMutex.Unlock()
+	fake.TrackInMusicFoldersStub = nil
+	if fake.trackInMusicFoldersReturnsOnCall == nil {
+		fake.trackInMusicFoldersReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.trackInMusicFoldersReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLibrary) Truncate() error {
 	fake.truncateMutex.Lock()
 	ret, specificReturn := fake.truncateReturnsOnCall[len(fake.truncateArgsForCall)]
@@ -1445,6 +1780,10 @@ func (fake *FakeLibrary) Invocations() map[string][][]interface{} {
 	defer fake.addLibraryPathMutex.RUnlock()
 	fake.addMediaMutex.RLock()
 	defer fake.addMediaMutex.RUnlock()
+	fake.albumInMusicFoldersMutex.RLock()
+	defer fake.albumInMusicFoldersMutex.RUnlock()
+	fake.artistInMusicFoldersMutex.RLock()
+	defer fake.artistInMusicFoldersMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
 	fake.getAlbumMutex.RLock()
@@ -1461,6 +1800,8 @@ func (fake *FakeLibrary) Invocations() map[string][][]interface{} {
 	defer fake.getTrackMutex.RUnlock()
 	fake.initializeMutex.RLock()
 	defer fake.initializeMutex.RUnlock()
+	fake.musicFoldersMutex.RLock()
+	defer fake.musicFoldersMutex.RUnlock()
 	fake.recordFavouriteMutex.RLock()
 	defer fake.recordFavouriteMutex.RUnlock()
 	fake.recordTrackPlayMutex.RLock()
@@ -1481,6 +1822,8 @@ func (fake *FakeLibrary) Invocations() map[string][][]interface{} {
 	defer fake.setArtistRatingMutex.RUnlock()
 	fake.setTrackRatingMutex.RLock()
 	defer fake.setTrackRatingMutex.RUnlock()
+	fake.trackInMusicFoldersMutex.RLock()
+	defer fake.trackInMusicFoldersMutex.RUnlock()
 	fake.truncateMutex.RLock()
 	defer fake.truncateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -30,6 +30,16 @@ func (lib *LocalLibrary) BrowseArtists(args BrowseArgs) ([]Artist, int) {
 		queryArgs = append(queryArgs, sql.Named("artistID", args.ArtistID))
 	}
 
+	if cond, condArgs := musicFoldersWhere(
+		"tr.music_folder_id", args.MusicFolderIDs,
+	); cond != "" {
+		where = append(where, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM tracks tr WHERE tr.artist_id = ar.id AND %s)",
+			cond,
+		))
+		queryArgs = append(queryArgs, condArgs...)
+	}
+
 	order := "ASC"
 	orderBy := "ar.name"
 
@@ -169,6 +179,13 @@ func (lib *LocalLibrary) BrowseAlbums(args BrowseArgs) ([]Album, int) {
 		queryArgs = append(queryArgs, sql.Named("toYear", *args.ToYear))
 	}
 
+	if cond, condArgs := musicFoldersWhere(
+		"tr.music_folder_id", args.MusicFolderIDs,
+	); cond != "" {
+		where = append(where, cond)
+		queryArgs = append(queryArgs, condArgs...)
+	}
+
 	order := "ASC"
 	if args.Order == OrderDesc {
 		order = "DESC"
@@ -244,7 +261,8 @@ func (lib *LocalLibrary) BrowseAlbums(args BrowseArgs) ([]Album, int) {
 				MIN(tr.year) as year,
 				als.favourite,
 				als.user_rating,
-				SUM(tr.bitrate) / COUNT(tr.id) as avg_bitrate
+				SUM(tr.bitrate) / COUNT(tr.id) as avg_bitrate,
+				%s
 			FROM
 				tracks tr
 				LEFT JOIN
@@ -262,7 +280,7 @@ func (lib *LocalLibrary) BrowseAlbums(args BrowseArgs) ([]Album, int) {
 				%s
 			LIMIT
 				@offset, @perPage
-		`, whereStr, orderBy), queryArgs...)
+		`, albumTagsColumns("tr"), whereStr, orderBy), queryArgs...)
 
 		if err != nil {
 			return err
@@ -278,13 +296,15 @@ func (lib *LocalLibrary) BrowseAlbums(args BrowseArgs) ([]Album, int) {
 				plays  sql.NullInt64
 				year   sql.NullInt32
 				avgBr  sql.NullInt64
+				tags   albumTags
 			)
-			if err := rows.Scan(
+			if err := rows.Scan(append([]any{
 				&res.ID, &res.Name, &res.Artist, &res.SongCount,
 				&dur, &plays, &year, &fav, &rating, &avgBr,
-			); err != nil {
+			}, tags.dest()...)...); err != nil {
 				return fmt.Errorf("scanning db failed: %w", err)
 			}
+			tags.setTo(&res)
 			if dur.Valid {
 				res.Duration = dur.Int64
 			}
@@ -354,6 +374,13 @@ func (lib *LocalLibrary) BrowseTracks(args BrowseArgs) ([]TrackInfo, int) {
 		queryArgs = append(queryArgs, sql.Named("toYear", *args.ToYear))
 	}
 
+	if cond, condArgs := musicFoldersWhere(
+		"t.music_folder_id", args.MusicFolderIDs,
+	); cond != "" {
+		where = append(where, cond)
+		queryArgs = append(queryArgs, condArgs...)
+	}
+
 	order := "ASC"
 
 	if args.Order == OrderDesc {
@@ -83,11 +83,22 @@ func ScanTrack(rows scanner) (TrackInfo, error) {
 		bitrate    sql.NullInt64
 		size       sql.NullInt64
 		createdAt  sql.NullInt64
+		genre      sql.NullString
+		comment    sql.NullString
+		folderID   sql.NullInt64
+		sortName   sql.NullString
+		mbID       sql.NullString
+		isrc       sql.NullString
+		bpm        sql.NullInt64
+		disc       sql.NullInt64
+		discTitle  sql.NullString
 	)
 
 	err := rows.Scan(&res.ID, &res.Title, &res.Album, &res.Artist,
 		&res.ArtistID, &res.TrackNumber, &res.AlbumID, &res.Format,
-		&dur, &year, &bitrate, &size, &createdAt, &fav, &rating, &lastPlayed, &playCount,
+		&dur, &year, &bitrate, &size, &createdAt, &genre, &comment, &folderID,
+		&sortName, &mbID, &isrc, &bpm, &disc, &discTitle,
+		&fav, &rating, &lastPlayed, &playCount,
 	)
 	if err != nil {
 		return res, err
@@ -121,10 +132,79 @@ func ScanTrack(rows scanner) (TrackInfo, error) {
 	if createdAt.Valid {
 		res.CreatedAt = createdAt.Int64
 	}
+	if genre.Valid {
+		res.Genre = genre.String
+	}
+	if comment.Valid {
+		res.Comment = comment.String
+	}
+	if folderID.Valid {
+		res.MusicFolderID = folderID.Int64
+	}
+	if sortName.Valid {
+		res.SortName = sortName.String
+	}
+	if mbID.Valid {
+		res.MusicBrainzID = mbID.String
+	}
+	if isrc.Valid && isrc.String != "" {
+		res.ISRCs = strings.Split(isrc.String, isrcSeparator)
+	}
+	if bpm.Valid {
+		res.BPM = int(bpm.Int64)
+	}
+	if disc.Valid {
+		res.Disc = int(disc.Int64)
+	}
+	if discTitle.Valid {
+		res.DiscSubtitle = discTitle.String
+	}
 
 	return res, nil
 }
 
+// albumTagsColumns returns the columns with the album tags which are stored for
+// each of the tracks with table alias `alias`. They are meant for queries which
+// group tracks by album and are scanned with albumTags.
+func albumTagsColumns(alias string) string {
+	return fmt.Sprintf(`
+		MAX(%[1]s.album_sort_name) as album_sort_name,
+		MAX(%[1]s.album_musicbrainz_id) as album_musicbrainz_id,
+		MIN(%[1]s.release_date) as release_date,
+		MIN(%[1]s.original_release_date) as original_release_date,
+		MAX(%[1]s.compilation) as compilation
+	`, alias)
+}
+
+// albumTags is the scan destination for the columns from albumTagsColumns.
+type albumTags struct {
+	sortName            sql.NullString
+	musicBrainzID       sql.NullString
+	releaseDate         sql.NullString
+	originalReleaseDate sql.NullString
+	compilation         sql.NullInt64
+}
+
+// dest returns the arguments for scanning the album tags columns.
+func (t *albumTags) dest() []any {
+	return []any{
+		&t.sortName,
+		&t.musicBrainzID,
+		&t.releaseDate,
+		&t.originalReleaseDate,
+		&t.compilation,
+	}
+}
+
+// setTo sets the scanned tags to `album`.
+func (t *albumTags) setTo(album *Album) {
+	album.SortName = t.sortName.String
+	album.MusicBrainzID = t.musicBrainzID.String
+	album.ReleaseDate = t.releaseDate.String
+	album.OriginalReleaseDate = t.originalReleaseDate.String
+	album.Compilation = t.compilation.Valid && t.compilation.Int64 > 0
+}
+
 type scanner interface {
 	Scan(dest ...any) error
 }
@@ -147,6 +227,15 @@ var (
 		t.bitrate as bitrate,
 		t.size as file_size,
 		t.created_at as file_created_at,
+		t.genre as genre,
+		t.comment as comment,
+		t.music_folder_id as music_folder_id,
+		t.sort_name as sort_name,
+		t.musicbrainz_id as musicbrainz_id,
+		t.isrc as isrc,
+		t.bpm as bpm,
+		t.disc_number as disc_number,
+		t.disc_subtitle as disc_subtitle,
 		us.favourite as fav,
 		us.user_rating as rating,
 		us.last_played as last_played,
@@ -67,6 +67,13 @@ var (
 	// ErrArtistNotFound is returned when no artist could be found for particular operation.
 	ErrArtistNotFound = fmt.Errorf("Artist: %w", ErrNotFound)
 
+	// ErrTrackNotFound is returned when no track could be found for particular operation.
+	ErrTrackNotFound = fmt.Errorf("Track: %w", ErrNotFound)
+
+	// ErrPlaylistNotFound is returned when no playlist could be found for particular
+	// operation.
+	ErrPlaylistNotFound = fmt.Errorf("Playlist: %w", ErrNotFound)
+
 	// ErrArtworkNotFound is returned when no artwork can be found for particular album.
 	ErrArtworkNotFound = NewArtworkError(fmt.Errorf("Artwork: %w", ErrNotFound))
 
@@ -152,14 +159,59 @@ type LocalLibrary struct {
 	// runningRescan shows that at the moment a complete rescan is running.
 	runningRescan bool
 
+	// scanLock makes sure that only one scan or rescan is running at a time.
+	scanLock sync.Mutex
+
+	// scanStatus is the progress of the running or the last finished scan.
+	scanStatus     ScanStatus
+	scanStatusLock sync.Mutex
+
 	// When noWatch is set then no file system watchers will be created
 	// for the scanned directories.
 	noWatch bool
+
+	// musicFolders are the music folders for every path in paths.
+	musicFolders     []MusicFolder
+	musicFoldersLock sync.RWMutex
+
+	// lastModified is the last time the content of the library was changed.
+	lastModified     time.Time
+	lastModifiedLock sync.RWMutex
+
+	// playlistFiles syncs the playlist files found in the library directories.
+	// When nil playlist files are ignored.
+	playlistFiles PlaylistFilesSyncer
+
+	// playlistEntries links the playlist entries to the tracks added back to
+	// the library. When nil playlist entries stay missing once their tracks
+	// are removed.
+	playlistEntries PlaylistEntriesLinker
+
+	// tagBackupsDir is the directory into which media files are copied before
+	// their tags are edited. Tags could not be edited while it is empty.
+	tagBackupsDir string
+
+	// writeTags writes the tag changes into media files.
+	writeTags TagWriter
+
+	// tagPropertiesWritable shows whether writeTags could write the album
+	// artist and the disc number.
+	tagPropertiesWritable bool
+
+	// removedFilesWait is the time for which tracks stay in the library after
+	// their files are removed. See defaultRemovedFilesWait.
+	removedFilesWait time.Duration
+
+	// pendingRemovals are the timers for removing files from the library which
+	// have not fired yet together with the removal they will do.
+	pendingRemovals     map[*time.Timer]func()
+	pendingRemovalsLock sync.Mutex
 }
 
 // Close closes the database connection. It is safe to call it as many times as you want.
 func (lib *LocalLibrary) Close() {
 	lib.ctxCancelFunc()
+	lib.stopPendingRemovals(false)
 	lib.db.Close()
 }
 
@@ -172,6 +224,16 @@ func (lib *LocalLibrary) AddLibraryPath(path string) {
 	}
 
 	lib.paths = append(lib.paths, path)
+
+	folder, err := lib.registerMusicFolder(path)
+	if err != nil {
+		log.Printf("error registering music folder %s: %s", path, err)
+		return
+	}
+
+	lib.musicFoldersLock.Lock()
+	lib.musicFolders = append(lib.musicFolders, folder)
+	lib.musicFoldersLock.Unlock()
 }
 
 // Search searches in the library. Will match against the track's name, artist and album.
@@ -195,12 +257,28 @@ func (lib *LocalLibrary) Search(ctx context.Context, args SearchArgs) []SearchRe
 			" OR ",
 		)}
 
+		where[0] = "(" + where[0] + ")"
+
 		queryArgs := []any{
 			sql.Named("searchTerm", searchTerm),
 			sql.Named("offset", args.Offset),
 			sql.Named("count", limitCount),
 		}
 
+		if cond, condArgs := musicFoldersWhere(
+			"t.music_folder_id", args.MusicFolderIDs,
+		); cond != "" {
+			where = append(where, cond)
+			queryArgs = append(queryArgs, condArgs...)
+		}
+
+		if cond, condArgs := newerThanWhere(
+			"t.created_at", args.NewerThan,
+		); cond != "" {
+			where = append(where, cond)
+			queryArgs = append(queryArgs, condArgs...)
+		}
+
 		rows, err := QueryTracks(ctx, db, where, orderBy, queryArgs)
 		if err != nil {
 			log.Printf("Search query not successful: %s\n", err.Error())
@@ -239,7 +317,27 @@ func (lib *LocalLibrary) SearchAlbums(ctx context.Context, args SearchArgs) []Al
 			limitCount = int64(args.Count)
 		}
 
-		rows, err := db.QueryContext(ctx, `
+		queryArgs := []any{
+			sql.Named("searchTerm", searchTerm),
+			sql.Named("offset", args.Offset),
+			sql.Named("count", limitCount),
+		}
+
+		var tracksCond string
+		if cond, condArgs := musicFoldersWhere(
+			"t.music_folder_id", args.MusicFolderIDs,
+		); cond != "" {
+			tracksCond += " AND " + cond
+			queryArgs = append(queryArgs, condArgs...)
+		}
+		if cond, condArgs := newerThanWhere(
+			"t.created_at", args.NewerThan,
+		); cond != "" {
+			tracksCond += " AND " + cond
+			queryArgs = append(queryArgs, condArgs...)
+		}
+
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(`
 			SELECT
 				t.album_id as album_id,
 				al.name as album,
@@ -253,7 +351,8 @@ func (lib *LocalLibrary) SearchAlbums(ctx context.Context, args SearchArgs) []Al
 				SUM(us.play_count) as play_count,
 				asr.favourite,
 				asr.user_rating,
-				MIN(t.year) as album_year
+				MIN(t.year) as album_year,
+				%s
 			FROM
 				tracks as t
 					LEFT JOIN albums as al ON al.id = t.album_id
@@ -261,16 +360,19 @@ func (lib *LocalLibrary) SearchAlbums(ctx context.Context, args SearchArgs) []Al
 					LEFT JOIN user_stats as us ON us.track_id = t.id
 					LEFT JOIN albums_stats as asr ON asr.album_id = t.album_id
 			WHERE
-				t.name LIKE ? OR
-				al.name LIKE ? OR
-				at.name LIKE ?
+				(
+					t.name LIKE @searchTerm OR
+					al.name LIKE @searchTerm OR
+					at.name LIKE @searchTerm
+				)
+				%s
 			GROUP BY
 				t.album_id
 			ORDER BY
 				al.name, t.album_id
 			LIMIT
-				?, ?
-		`, searchTerm, searchTerm, searchTerm, args.Offset, limitCount)
+				@offset, @count
+		`, albumTagsColumns("t"), tracksCond), queryArgs...)
 		if err != nil {
 			log.Printf("Search album query not successful: %s\n", err.Error())
 			return nil
@@ -285,17 +387,19 @@ func (lib *LocalLibrary) SearchAlbums(ctx context.Context, args SearchArgs) []Al
 				fav        sql.NullInt64
 				rating     sql.NullInt16
 				year       sql.NullInt32
+				tags       albumTags
 			)
 
-			err := rows.Scan(
+			err := rows.Scan(append([]any{
 				&res.ID, &res.Name, &res.Artist,
 				&res.SongCount, &res.Duration, &lastPlayed,
 				&playCount, &fav, &rating, &year,
-			)
+			}, tags.dest()...)...)
 			if err != nil {
 				log.Printf("Error scanning search album result: %s\n", err)
 				continue
 			}
+			tags.setTo(&res)
 			if lastPlayed.Valid {
 				res.LastPlayed = lastPlayed.Int64
 			}
@@ -335,25 +439,54 @@ func (lib *LocalLibrary) SearchArtists(ctx context.Context, args SearchArgs) []A
 			limitCount = int64(args.Count)
 		}
 
-		rows, err := db.QueryContext(ctx, `
+		queryArgs := []any{
+			sql.Named("searchTerm", searchTerm),
+			sql.Named("offset", args.Offset),
+			sql.Named("count", limitCount),
+		}
+
+		var tracksCond string
+		if cond, condArgs := musicFoldersWhere(
+			"tr.music_folder_id", args.MusicFolderIDs,
+		); cond != "" {
+			tracksCond += " AND " + cond
+			queryArgs = append(queryArgs, condArgs...)
+		}
+		if cond, condArgs := newerThanWhere(
+			"tr.created_at", args.NewerThan,
+		); cond != "" {
+			tracksCond += " AND " + cond
+			queryArgs = append(queryArgs, condArgs...)
+		}
+
+		// Artists are limited to the ones with matching tracks only when
+		// there is something to match the tracks against.
+		var artistsCond string
+		if tracksCond != "" {
+			artistsCond = fmt.Sprintf(`AND EXISTS (SELECT 1
+					FROM tracks tr
+					WHERE tr.artist_id = ar.id %s)`, tracksCond)
+		}
+
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(`
 			SELECT
 				ar.id,
 				ar.name,
 				(SELECT COUNT(DISTINCT(tr.album_id))
 					FROM tracks tr
-					WHERE tr.artist_id = ar.id) as albumsCount,
+					WHERE tr.artist_id = ar.id %[1]s) as albumsCount,
 				ars.favourite,
 				ars.user_rating
 			FROM
 				artists ar
 				LEFT JOIN artists_stats as ars ON ars.artist_id = ar.id
 			WHERE
-				ar.name LIKE ?
+				ar.name LIKE @searchTerm %[2]s
 			ORDER BY
 				ar.name, ar.id
 			LIMIT
-				?, ?
-		`, searchTerm, args.Offset, limitCount)
+				@offset, @count
+		`, tracksCond, artistsCond), queryArgs...)
 		if err != nil {
 			log.Printf("Search artist query not successful: %s\n", err.Error())
 			return nil
@@ -565,7 +698,8 @@ func (lib *LocalLibrary) GetAlbum(
 			MAX(us.last_played) as last_played,
 			als.favourite,
 			als.user_rating,
-			SUM(tr.bitrate) / COUNT(tr.id) as avg_bitrate
+			SUM(tr.bitrate) / COUNT(tr.id) as avg_bitrate,
+		` + albumTagsColumns("tr") + `
 		FROM tracks tr
 			LEFT JOIN artists as ar ON ar.id = tr.artist_id
 			LEFT JOIN albums_stats as als ON als.album_id = tr.album_id
@@ -589,8 +723,9 @@ func (lib *LocalLibrary) GetAlbum(
 			lastPlayed sql.NullInt64
 			year       sql.NullInt32
 			avgBr      sql.NullInt64
+			tags       albumTags
 		)
-		err := row.Scan(
+		err := row.Scan(append([]any{
 			&res.Name,
 			&res.Artist,
 			&res.SongCount,
@@ -601,13 +736,14 @@ func (lib *LocalLibrary) GetAlbum(
 			&fav,
 			&rating,
 			&avgBr,
-		)
+		}, tags.dest()...)...)
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrAlbumNotFound
 		} else if err != nil {
 			return fmt.Errorf("sql query for artist info failed: %w", err)
 		}
 		res.ID = albumID
+		tags.setTo(&res)
 		if dur.Valid {
 			res.Duration = dur.Int64
 		}
@@ -644,7 +780,8 @@ func (lib *LocalLibrary) GetAlbum(
 //
 // play_count and last_played are updated only if a sufficient time has
 // passed since the previous value of last_played. This sufficient time is
-// calculated based on the length of the media file.
+// calculated based on the length of the media file. Every counted play is
+// also stored in the play history in the `track_plays` table.
 func (lib *LocalLibrary) RecordTrackPlay(
 	ctx context.Context,
 	mediaID int64,
@@ -669,13 +806,31 @@ func (lib *LocalLibrary) RecordTrackPlay(
 					) / 3 < @unixTime
 				);
 		`
+		const historyQuery = `
+			INSERT INTO track_plays (track_id, played_at)
+			VALUES (@mediaID, @unixTime)
+		`
 		unixTime := atTime.Unix()
 
-		_, err := db.ExecContext(
+		res, err := db.ExecContext(
 			ctx, query,
 			sql.Named("mediaID", mediaID),
 			sql.Named("unixTime", unixTime),
 		)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil || affected < 1 {
+			// The play was not counted so it is not part of the history.
+			return err
+		}
+
+		_, err = db.ExecContext(
+			ctx, historyQuery,
+			sql.Named("mediaID", mediaID),
+			sql.Named("unixTime", unixTime),
+		)
 		return err
 	}
 
@@ -720,7 +875,8 @@ func (lib *LocalLibrary) GetArtistAlbums(
 				als.favourite,
 				als.user_rating,
 				MIN(t.year) as album_year,
-				SUM(t.bitrate) / COUNT(t.id) as avg_bitrate
+				SUM(t.bitrate) / COUNT(t.id) as avg_bitrate,
+			`+albumTagsColumns("t")+`
 			FROM
 				tracks t
 					LEFT JOIN albums a ON a.id = t.album_id
@@ -749,9 +905,10 @@ func (lib *LocalLibrary) GetArtistAlbums(
 				rating     sql.NullInt16
 				year       sql.NullInt32
 				avgBr      sql.NullInt64
+				tags       albumTags
 			)
 
-			err := rows.Scan(
+			err := rows.Scan(append([]any{
 				&res.ID,
 				&res.Name,
 				&res.SongCount,
@@ -762,10 +919,11 @@ func (lib *LocalLibrary) GetArtistAlbums(
 				&rating,
 				&year,
 				&avgBr,
-			)
+			}, tags.dest()...)...)
 			if err != nil {
 				return fmt.Errorf("scanning for GetArtistAlbums error: %w", err)
 			}
+			tags.setTo(&res)
 			if lastPlayed.Valid {
 				res.LastPlayed = lastPlayed.Int64
 			}
@@ -813,42 +971,24 @@ func (lib *LocalLibrary) removeFile(filePath string) {
 
 func (lib *LocalLibrary) removeFileExact(filePath string) {
 	work := func(db *sql.DB) error {
-		_, err := db.Exec(`
+		res, err := db.Exec(`
 			DELETE FROM tracks
 			WHERE fs_path = ?
 		`, filePath)
 		if err != nil {
 			log.Printf("Error removing %s: %s\n", filePath, err.Error())
+			return nil
 		}
 
-		return nil
-	}
-
-	if err := lib.ExecuteDBJobAndWait(work); err != nil {
-		log.Printf("Error executing remove file db work: %s", err)
-	}
-}
-
-// Removes files which belong in this directory from the library.
-func (lib *LocalLibrary) removeDirectory(dirPath string) {
-
-	// Adding slash at the end to make sure we are always removing directories
-	deleteMatch := fmt.Sprintf("%s/%%", strings.TrimRight(dirPath, "/"))
-
-	work := func(db *sql.DB) error {
-		_, err := db.Exec(`
-			DELETE FROM tracks
-			WHERE fs_path LIKE ?
-		`, deleteMatch)
-		if err != nil {
-			log.Printf("Error removing %s: %s\n", dirPath, err.Error())
+		if affected, _ := res.RowsAffected(); affected > 0 {
+			return lib.markModified(db)
 		}
 
 		return nil
 	}
 
 	if err := lib.ExecuteDBJobAndWait(work); err != nil {
-		log.Printf("Error executing remove dir db work: %s", err)
+		log.Printf("Error executing remove file db work: %s", err)
 	}
 }
 
@@ -888,34 +1028,56 @@ func (lib *LocalLibrary) isSupportedFormat(path string) bool {
 // AddMedia adds a file specified by its file system name to the library. Will create the
 // needed Artist, Album if necessary.
 func (lib *LocalLibrary) AddMedia(filename string) error {
+	_, err := lib.addMedia(filename)
+	return err
+}
+
+// addMedia is the same as AddMedia but also returns whether the file was actually
+// added. It is false when the file is in the library already.
+func (lib *LocalLibrary) addMedia(filename string) (bool, error) {
 	filename = filepath.Clean(filename)
 
 	if lib.MediaExistsInLibrary(filename) {
-		return nil
+		return false, nil
 	}
 
 	st, err := fs.Stat(lib.fs, filename)
 	if err != nil {
-		return err
+		lib.recordFileIssue(IssueUnreadablePath, filename, err)
+		return false, err
 	}
 
 	file, err := parseFileTags(taglib.Read, filename)
 	if err != nil {
-		return fmt.Errorf("parsing tags error for %s: %s", filename, err.Error())
+		lib.recordFileIssue(IssueBadTags, filename, err)
+		return false, fmt.Errorf("parsing tags error for %s: %s", filename, err.Error())
 	}
 
 	fi := fileInfo{
-		FilePath: filename,
-		Size:     st.Size(),
-		Modified: st.ModTime(),
+		FilePath:    filename,
+		Size:        st.Size(),
+		Modified:    st.ModTime(),
+		Fingerprint: lib.fileFingerprint(filename),
+	}
+
+	// The file may be one which was just moved or renamed. Then the track
+	// is kept so that it does not lose its plays, ratings and so on.
+	if _, err := lib.moveRemovedTrack(fi.Fingerprint, filename); err != nil {
+		log.Printf("Error looking for moved track for %s: %s\n", filename, err)
+	}
+
+	if err := lib.insertMediaIntoDatabase(file, fi); err != nil {
+		return false, err
 	}
-	return lib.insertMediaIntoDatabase(file, fi)
+
+	return true, nil
 }
 
 type fileInfo struct {
-	Size     int64
-	FilePath string
-	Modified time.Time
+	Size        int64
+	FilePath    string
+	Modified    time.Time
+	Fingerprint string
 }
 
 // insertMediaIntoDatabase accepts an already parsed media info object, its path.
@@ -945,9 +1107,11 @@ func (lib *LocalLibrary) insertMediaIntoDatabase(file MediaFile, info fileInfo)
 		title = filepath.Base(info.FilePath)
 	}
 
-	_, err = lib.setTrackID(
+	trackID, err := lib.setTrackID(
 		title,
 		info.FilePath,
+		strings.TrimSpace(file.Genre()),
+		strings.TrimSpace(file.Comment()),
 		trackNumber,
 		artistID,
 		albumID,
@@ -956,8 +1120,15 @@ func (lib *LocalLibrary) insertMediaIntoDatabase(file MediaFile, info fileInfo)
 		file.Bitrate()*1024,
 		info.Size,
 		info.Modified,
+		info.Fingerprint,
+		file.Properties(),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	lib.recordTrackIssues(trackID, info.FilePath, artist, album, file.Length())
+	return nil
 }
 
 // MediaExistsInLibrary checks if the media file with file system path "filename" has
@@ -1069,7 +1240,7 @@ func (lib *LocalLibrary) setArtistID(artist string) (int64, error) {
 		}
 
 		lastInsertID, _ = res.LastInsertId()
-		return nil
+		return lib.markModified(db)
 	}
 
 	if err := lib.ExecuteDBJobAndWait(work); err != nil {
@@ -1170,7 +1341,7 @@ func (lib *LocalLibrary) setAlbumID(album string, fsPath string) (int64, error)
 		}
 
 		lastInsertID, _ = res.LastInsertId()
-		return nil
+		return lib.markModified(db)
 	}
 	if err := lib.ExecuteDBJobAndWait(work); err != nil {
 		return 0, err
@@ -1323,6 +1494,42 @@ func (lib *LocalLibrary) GetTrackID(
 	return newID, nil
 }
 
+// mediaPropertiesArgs returns the named arguments for storing `props` in the
+// tracks table. Empty properties are stored as NULL.
+func mediaPropertiesArgs(props MediaProperties) []any {
+	text := func(name, value string) sql.NamedArg {
+		if value == "" {
+			return sql.Named(name, nil)
+		}
+		return sql.Named(name, value)
+	}
+	number := func(name string, value int) sql.NamedArg {
+		if value == 0 {
+			return sql.Named(name, nil)
+		}
+		return sql.Named(name, value)
+	}
+
+	compilationArg := sql.Named("compilation", nil)
+	if props.Compilation {
+		compilationArg = sql.Named("compilation", 1)
+	}
+
+	return []any{
+		text("sortName", props.SortName),
+		text("musicBrainzID", props.MusicBrainzID),
+		text("isrc", strings.Join(props.ISRCs, isrcSeparator)),
+		number("bpm", props.BPM),
+		number("discNumber", props.Disc),
+		text("discSubtitle", props.DiscSubtitle),
+		text("albumSortName", props.AlbumSortName),
+		text("albumMusicBrainzID", props.AlbumMusicBrainzID),
+		text("releaseDate", props.ReleaseDate),
+		text("originalReleaseDate", props.OriginalReleaseDate),
+		compilationArg,
+	}
+}
+
 // Sets a new ID for this track if it is new to the library. If not, returns
 // its current id. Tracks with the same name but by different artists and/or album
 // need to have separate IDs hence the artistID and albumID parameters.
@@ -1332,11 +1539,13 @@ func (lib *LocalLibrary) GetTrackID(
 // In case the track with this file system path already exists in the library it
 // is updated with new values for the test of the properties.
 func (lib *LocalLibrary) setTrackID(
-	title, fsPath string,
+	title, fsPath, genre, comment string,
 	trackNumber, artistID, albumID, duration int64,
 	year, bitrate int,
 	size int64,
 	lastModified time.Time,
+	fingerprint string,
+	props MediaProperties,
 ) (int64, error) {
 	var lastInsertID int64
 	work := func(db *sql.DB) error {
@@ -1344,12 +1553,20 @@ func (lib *LocalLibrary) setTrackID(
 			INSERT INTO
 				tracks (
 					name, album_id, artist_id, fs_path, number, duration,
-					year, bitrate, size, created_at
+					year, bitrate, size, created_at, music_folder_id, fingerprint,
+					genre, comment, sort_name, musicbrainz_id, isrc, bpm,
+					disc_number, disc_subtitle, album_sort_name,
+					album_musicbrainz_id, release_date, original_release_date,
+					compilation
 				)
 			VALUES
 				(
 					@title, @albumID, @artistID, @fsPath, @trackNumber, @duration,
-					@year, @bitrate, @size, strftime('%s')
+					@year, @bitrate, @size, strftime('%s'), @musicFolderID,
+					@fingerprint, @genre, @comment, @sortName, @musicBrainzID,
+					@isrc, @bpm, @discNumber, @discSubtitle, @albumSortName,
+					@albumMusicBrainzID, @releaseDate, @originalReleaseDate,
+					@compilation
 				)
 			ON CONFLICT (fs_path) DO
 			UPDATE SET
@@ -1361,7 +1578,47 @@ func (lib *LocalLibrary) setTrackID(
 				year = @year,
 				size = @size,
 				bitrate = @bitrate,
-				created_at = COALESCE(created_at, @lastModified)
+				genre = @genre,
+				comment = @comment,
+				sort_name = @sortName,
+				musicbrainz_id = @musicBrainzID,
+				isrc = @isrc,
+				bpm = @bpm,
+				disc_number = @discNumber,
+				disc_subtitle = @discSubtitle,
+				album_sort_name = @albumSortName,
+				album_musicbrainz_id = @albumMusicBrainzID,
+				release_date = @releaseDate,
+				original_release_date = @originalReleaseDate,
+				compilation = @compilation,
+				created_at = COALESCE(created_at, @lastModified),
+				music_folder_id = COALESCE(@musicFolderID, music_folder_id),
+				fingerprint = COALESCE(@fingerprint, fingerprint)
+			WHERE
+				name IS NOT @title OR
+				album_id IS NOT @albumID OR
+				artist_id IS NOT @artistID OR
+				number IS NOT @trackNumber OR
+				duration IS NOT @duration OR
+				year IS NOT @year OR
+				size IS NOT @size OR
+				bitrate IS NOT @bitrate OR
+				genre IS NOT @genre OR
+				comment IS NOT @comment OR
+				sort_name IS NOT @sortName OR
+				musicbrainz_id IS NOT @musicBrainzID OR
+				isrc IS NOT @isrc OR
+				bpm IS NOT @bpm OR
+				disc_number IS NOT @discNumber OR
+				disc_subtitle IS NOT @discSubtitle OR
+				album_sort_name IS NOT @albumSortName OR
+				album_musicbrainz_id IS NOT @albumMusicBrainzID OR
+				release_date IS NOT @releaseDate OR
+				original_release_date IS NOT @originalReleaseDate OR
+				compilation IS NOT @compilation OR
+				created_at IS NULL OR
+				music_folder_id IS NOT COALESCE(@musicFolderID, music_folder_id) OR
+				fingerprint IS NOT COALESCE(@fingerprint, fingerprint)
 		`)
 		if err != nil {
 			return err
@@ -1384,7 +1641,27 @@ func (lib *LocalLibrary) setTrackID(
 			durationArg = sql.Named("duration", nil)
 		}
 
-		res, err := stmt.Exec(
+		musicFolderArg := sql.Named("musicFolderID", nil)
+		if folderID, ok := lib.musicFolderID(fsPath); ok {
+			musicFolderArg = sql.Named("musicFolderID", folderID)
+		}
+
+		fingerprintArg := sql.Named("fingerprint", fingerprint)
+		if fingerprint == "" {
+			fingerprintArg = sql.Named("fingerprint", nil)
+		}
+
+		genreArg := sql.Named("genre", genre)
+		if genre == "" {
+			genreArg = sql.Named("genre", nil)
+		}
+
+		commentArg := sql.Named("comment", comment)
+		if comment == "" {
+			commentArg = sql.Named("comment", nil)
+		}
+
+		args := []any{
 			sql.Named("title", title),
 			sql.Named("albumID", albumID),
 			sql.Named("artistID", artistID),
@@ -1395,13 +1672,29 @@ func (lib *LocalLibrary) setTrackID(
 			sql.Named("size", size),
 			bitrateArg,
 			sql.Named("lastModified", lastModified.Unix()),
-		)
+			musicFolderArg,
+			fingerprintArg,
+			genreArg,
+			commentArg,
+		}
+		res, err := stmt.Exec(append(args, mediaPropertiesArgs(props)...)...)
 		if err != nil {
 			return err
 		}
 
 		lastInsertID, _ = res.LastInsertId()
-		return nil
+
+		// Nothing is changed when the track is already stored with exactly
+		// the same values. Then the library is not modified either.
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+		if affected < 1 {
+			return nil
+		}
+
+		return lib.markModified(db)
 	}
 
 	if err := lib.ExecuteDBJobAndWait(work); err != nil {
@@ -1471,7 +1764,10 @@ func (lib *LocalLibrary) Initialize() error {
 	// This database is already created and populated. We could just apply the
 	// migrations without executing the initial schema.
 	if st, err := fs.Stat(lib.fs, lib.database); err == nil && st.Size() > 0 {
-		return lib.applyMigrations()
+		if err := lib.applyMigrations(); err != nil {
+			return err
+		}
+		return lib.loadLastModified()
 	}
 
 	sqlSchema, err := lib.readSchema()
@@ -1496,7 +1792,11 @@ func (lib *LocalLibrary) Initialize() error {
 		}
 	}
 
-	return lib.applyMigrations()
+	if err := lib.applyMigrations(); err != nil {
+		return err
+	}
+
+	return lib.loadLastModified()
 }
 
 // Returns the SQL schema for the library. It is stored in the project root directory
@@ -1609,6 +1909,9 @@ func NewLocalLibrary(
 	lib.artworkSem = make(chan struct{}, 10)
 
 	lib.cleanupLock = &sync.RWMutex{}
+	lib.writeTags = writeTagsWithTaglib
+	lib.tagPropertiesWritable = taglibHasProperties()
+	lib.removedFilesWait = defaultRemovedFilesWait
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -42,6 +42,8 @@ func (lib *LocalLibrary) cleanUpDatabase() {
 	lib.cleanupTracks()
 	lib.cleanupAlbums()
 	lib.cleanupArtists()
+	lib.cleanupPlaylistFiles()
+	lib.relinkPlaylistEntries()
 }
 
 // cleanupTracks walks through all tracks in the database and cleanups from it any
@@ -265,7 +267,7 @@ func (lib *LocalLibrary) checkAndRemoveAlbums(albumIDs []int64) error {
 				return err
 			}
 
-			return nil
+			return lib.markModified(db)
 		}); err != nil {
 			log.Printf("Error deleting album %d: %s", albumID, err)
 		}
@@ -316,7 +318,7 @@ func (lib *LocalLibrary) checkAndRemoveArtists(artistIDs []int64) error {
 				return err
 			}
 
-			return nil
+			return lib.markModified(db)
 		}); err != nil {
 			log.Printf("Error deleting artist %d: %s", artistID, err)
 		}
@@ -0,0 +1,75 @@
+package library
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LastModified implements the Browser interface. It returns the last time
+// tracks, albums or artists were added, changed or removed from the library.
+func (lib *LocalLibrary) LastModified() time.Time {
+	lib.lastModifiedLock.RLock()
+	defer lib.lastModifiedLock.RUnlock()
+
+	return lib.lastModified
+}
+
+// markModified records that the content of the library has been changed just now.
+// It must be called from within a database work unit since the time is stored
+// in the database too. This way it survives restarts.
+func (lib *LocalLibrary) markModified(db *sql.DB) error {
+	lib.lastModifiedLock.Lock()
+	defer lib.lastModifiedLock.Unlock()
+
+	// The modification time is used with millisecond precision by clients. Make
+	// sure every change moves it forward even when changes are very close to
+	// each other.
+	modified := time.Now().Truncate(time.Millisecond)
+	if !modified.After(lib.lastModified) {
+		modified = lib.lastModified.Add(time.Millisecond)
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO
+			library_modified (id, modified_at)
+		VALUES
+			(1, @modifiedAt)
+		ON CONFLICT (id) DO
+		UPDATE SET
+			modified_at = excluded.modified_at
+	`, sql.Named("modifiedAt", modified.UnixMilli()))
+	if err != nil {
+		return fmt.Errorf("storing library modification time: %w", err)
+	}
+
+	lib.lastModified = modified
+	return nil
+}
+
+// loadLastModified reads the last modification time of the library from the
+// database. Libraries which have never recorded one are considered modified
+// right now.
+func (lib *LocalLibrary) loadLastModified() error {
+	var modifiedAt int64
+	err := lib.db.QueryRow(`
+		SELECT
+			modified_at
+		FROM
+			library_modified
+		WHERE
+			id = 1
+	`).Scan(&modifiedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return lib.markModified(lib.db)
+	} else if err != nil {
+		return fmt.Errorf("reading library modification time: %w", err)
+	}
+
+	lib.lastModifiedLock.Lock()
+	lib.lastModified = time.UnixMilli(modifiedAt)
+	lib.lastModifiedLock.Unlock()
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package library
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLocalLibraryLastModified checks that adding and removing tracks moves the
+// library modification time forward and that it is kept between restarts.
+func TestLocalLibraryLastModified(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "library.db")
+
+	lib, err := NewLocalLibrary(ctx, dbPath, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	initial := lib.LastModified()
+	if initial.IsZero() {
+		t.Fatal("expected new library to have its modification time set")
+	}
+
+	mediaFile := MockMedia{
+		artist: "Modified Artist",
+		album:  "Modified Album",
+		title:  "Modified Track",
+		track:  1,
+		length: 123 * time.Second,
+	}
+	mediaInfo := fileInfo{
+		Size:     2134,
+		FilePath: filepath.FromSlash("/path/to/modified.mp3"),
+		Modified: time.Now(),
+	}
+	if err := lib.insertMediaIntoDatabase(&mediaFile, mediaInfo); err != nil {
+		t.Fatalf("inserting media file failed: %s", err)
+	}
+
+	afterInsert := lib.LastModified()
+	if !afterInsert.After(initial) {
+		t.Errorf("expected modification time after insert `%s` to be after `%s`",
+			afterInsert, initial)
+	}
+
+	// Storing the same file again with the same tags does not change anything.
+	if err := lib.insertMediaIntoDatabase(&mediaFile, mediaInfo); err != nil {
+		t.Fatalf("inserting media file again failed: %s", err)
+	}
+	if lm := lib.LastModified(); !lm.Equal(afterInsert) {
+		t.Errorf("expected modification time after the same insert to stay `%s` "+
+			"but it was `%s`", afterInsert, lm)
+	}
+
+	// Changing only the genre of the file is a change.
+	mediaFile.genre = "Ambient"
+	if err := lib.insertMediaIntoDatabase(&mediaFile, mediaInfo); err != nil {
+		t.Fatalf("inserting media file with genre failed: %s", err)
+	}
+	afterGenre := lib.LastModified()
+	if !afterGenre.After(afterInsert) {
+		t.Errorf("expected modification time after genre change `%s` to be after `%s`",
+			afterGenre, afterInsert)
+	}
+	afterInsert = afterGenre
+
+	// Removing files which are not in the library does not change anything.
+	lib.removeFileExact(filepath.FromSlash("/path/to/not-in-the-library.mp3"))
+	if lm := lib.LastModified(); !lm.Equal(afterInsert) {
+		t.Errorf("expected modification time to stay `%s` but it was `%s`",
+			afterInsert, lm)
+	}
+
+	lib.removeFileExact(mediaInfo.FilePath)
+	afterRemove := lib.LastModified()
+	if !afterRemove.After(afterInsert) {
+		t.Errorf("expected modification time after remove `%s` to be after `%s`",
+			afterRemove, afterInsert)
+	}
+
+	lib.Close()
+
+	lib, err = NewLocalLibrary(ctx, dbPath, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library after restart: %s", err)
+	}
+
+	if lm := lib.LastModified(); !lm.Equal(afterRemove) {
+		t.Errorf("expected modification time after restart to be `%s` but it was `%s`",
+			afterRemove, lm)
+	}
+}
+
+// TestLocalLibrarySearchNewerThan checks that searching with NewerThan returns only
+// items with tracks added after it.
+func TestLocalLibrarySearchNewerThan(t *testing.T) {
+	ctx := context.Background()
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	mediaFile := MockMedia{
+		artist: "Newer Artist",
+		album:  "Newer Album",
+		title:  "Newer Track",
+		track:  1,
+		length: 123 * time.Second,
+	}
+	mediaInfo := fileInfo{
+		Size:     2134,
+		FilePath: filepath.FromSlash("/path/to/newer.mp3"),
+		Modified: time.Now(),
+	}
+	if err := lib.insertMediaIntoDatabase(&mediaFile, mediaInfo); err != nil {
+		t.Fatalf("inserting media file failed: %s", err)
+	}
+
+	tests := []struct {
+		desc      string
+		newerThan time.Time
+		expected  int
+	}{
+		{
+			desc:     "no limit",
+			expected: 1,
+		},
+		{
+			desc:      "added after the time",
+			newerThan: time.Now().Add(-time.Hour),
+			expected:  1,
+		},
+		{
+			desc:      "added before the time",
+			newerThan: time.Now().Add(time.Hour),
+			expected:  0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := SearchArgs{
+				Query:     "Newer",
+				NewerThan: test.newerThan,
+			}
+
+			if found := lib.Search(ctx, args); len(found) != test.expected {
+				t.Errorf("expected %d tracks but got %d", test.expected, len(found))
+			}
+
+			if found := lib.SearchAlbums(ctx, args); len(found) != test.expected {
+				t.Errorf("expected %d albums but got %d", test.expected, len(found))
+			}
+
+			if found := lib.SearchArtists(ctx, args); len(found) != test.expected {
+				t.Errorf("expected %d artists but got %d", test.expected, len(found))
+			}
+		})
+	}
+}
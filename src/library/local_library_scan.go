@@ -15,20 +15,35 @@ import (
 // Scan scans all of the folders in paths for media files. New files will be added to the
 // database.
 func (lib *LocalLibrary) Scan() {
+	lib.scan(lib.ScanConfig.InitialWait)
+}
+
+// scan is the same as Scan but waits for `initialWait` instead of the configured
+// initial wait before starting walking the file system.
+func (lib *LocalLibrary) scan(initialWait time.Duration) {
+	lib.scanLock.Lock()
+	defer lib.scanLock.Unlock()
+
 	// Make sure there are no other scans working at the moment
 	lib.waitScanLock.RLock()
 	lib.walkWG.Wait()
 	lib.waitScanLock.RUnlock()
 
+	lib.beginScanStatus(false)
+	defer lib.finishScanStatus()
+
 	start := time.Now()
 
 	lib.initializeWatcher()
-	initialWait := lib.ScanConfig.InitialWait
 	if !LibraryFastScan && initialWait > 0 {
 		log.Printf("Pausing initial library scan for %s as configured", initialWait)
 		time.Sleep(initialWait)
 	}
 
+	// Tracks need fingerprints before walking so that moved files could be
+	// matched to them.
+	lib.fingerprintTracks()
+
 	lib.waitScanLock.Lock()
 	for _, path := range lib.paths {
 		lib.walkWG.Add(1)
@@ -41,11 +56,82 @@ func (lib *LocalLibrary) Scan() {
 	lib.waitScanLock.RUnlock()
 	log.Printf("Scaning took %s", time.Since(start))
 
+	// Files with issues are found again while walking. Issues which were
+	// not are for files which have been fixed or removed since.
+	lib.removeFileIssuesBefore(start)
+
 	start = time.Now()
 	lib.cleanUpDatabase()
 	log.Printf("Cleaning up took %s", time.Since(start))
 }
 
+// ScanStatus implements the Scanner interface.
+func (lib *LocalLibrary) ScanStatus() ScanStatus {
+	lib.scanStatusLock.Lock()
+	defer lib.scanStatusLock.Unlock()
+
+	return lib.scanStatus
+}
+
+// StartScan implements the Scanner interface. Scans started with it do not honour
+// the configured initial wait since they are requested explicitly.
+func (lib *LocalLibrary) StartScan(fullRescan bool) error {
+	lib.scanStatusLock.Lock()
+	if lib.scanStatus.Running {
+		lib.scanStatusLock.Unlock()
+		return ErrScanInProgress
+	}
+	lib.scanStatus = ScanStatus{
+		Running:    true,
+		FullRescan: fullRescan,
+		StartedAt:  time.Now(),
+	}
+	lib.scanStatusLock.Unlock()
+
+	go func() {
+		if !fullRescan {
+			lib.scan(0)
+			return
+		}
+
+		if err := lib.Rescan(lib.ctx); err != nil {
+			log.Printf("Library rescan failed: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+// beginScanStatus resets the scan status at the beginning of a scan.
+func (lib *LocalLibrary) beginScanStatus(fullRescan bool) {
+	lib.scanStatusLock.Lock()
+	defer lib.scanStatusLock.Unlock()
+
+	lib.scanStatus = ScanStatus{
+		Running:    true,
+		FullRescan: fullRescan,
+		StartedAt:  time.Now(),
+	}
+}
+
+// finishScanStatus marks the scan status as finished.
+func (lib *LocalLibrary) finishScanStatus() {
+	lib.scanStatusLock.Lock()
+	defer lib.scanStatusLock.Unlock()
+
+	lib.scanStatus.Running = false
+	lib.scanStatus.FinishedAt = time.Now()
+	lib.scanStatus.CurrentPath = ""
+}
+
+// updateScanStatus calls `update` with the scan status while holding its lock.
+func (lib *LocalLibrary) updateScanStatus(update func(*ScanStatus)) {
+	lib.scanStatusLock.Lock()
+	defer lib.scanStatusLock.Unlock()
+
+	update(&lib.scanStatus)
+}
+
 // This is the goroutine which actually scans a library path.
 // For now it ignores everything but the list of supported files. It is so
 // because jplayer cannot play anything else. Sends every suitable
@@ -61,20 +147,47 @@ func (lib *LocalLibrary) scanPath(scannedPath string) {
 	filesPerOperation := lib.ScanConfig.FilesPerOperation
 	sleepPerOperation := lib.ScanConfig.SleepPerOperation
 
-	var scannedFiles int64
+	var (
+		scannedFiles  int64
+		playlistFiles []string
+	)
 
 	walkFunc := func(path string, info os.FileInfo, err error) error {
 
 		if err != nil {
 			log.Printf("error while scanning %s: %s", path, err)
+			lib.recordFileIssue(IssueUnreadablePath, path, err)
+			lib.updateScanStatus(func(status *ScanStatus) {
+				status.Errors++
+			})
 			return nil
 		}
 
+		if !info.IsDir() {
+			lib.updateScanStatus(func(status *ScanStatus) {
+				status.FilesWalked++
+				status.CurrentPath = path
+			})
+		}
+
+		if !info.IsDir() && lib.isPlaylistFile(path) {
+			// Playlist files are synced after the walk so that the files
+			// they reference are already in the library.
+			playlistFiles = append(playlistFiles, path)
+		}
+
 		if !info.IsDir() && lib.isSupportedFormat(path) {
-			err := lib.AddMedia(path)
+			added, err := lib.addMedia(path)
 			if err != nil {
 				log.Printf("Error adding `%s`: %s\n", path, err)
 			}
+			lib.updateScanStatus(func(status *ScanStatus) {
+				if err != nil {
+					status.Errors++
+				} else if added {
+					status.FilesAdded++
+				}
+			})
 		}
 
 		lib.watchLock.RLock()
@@ -105,16 +218,32 @@ func (lib *LocalLibrary) scanPath(scannedPath string) {
 	if err != nil {
 		log.Printf("error while walking %s: %s", scannedPath, err)
 	}
+
+	lib.relinkPlaylistEntries()
+
+	for _, playlistFile := range playlistFiles {
+		lib.syncPlaylistFile(playlistFile)
+	}
 }
 
 // Rescan goes through the database and for every file reads the meta data again from
 // the disk and updates it.
 func (lib *LocalLibrary) Rescan(ctx context.Context) error {
+	lib.scanLock.Lock()
+	defer lib.scanLock.Unlock()
+
+	lib.beginScanStatus(true)
+	defer lib.finishScanStatus()
+
 	lib.runningRescan = true
 	defer func() {
 		lib.runningRescan = false
 	}()
 
+	// Files removed recently are not waited for since they would be rescanned
+	// otherwise.
+	lib.stopPendingRemovals(true)
+
 	const batchSize = 500
 	var cursor int64
 
@@ -129,32 +258,56 @@ func (lib *LocalLibrary) Rescan(ctx context.Context) error {
 		cursor += int64(len(mediaFiles))
 
 		for _, fileName := range mediaFiles {
-			st, err := os.Stat(fileName)
-			if err != nil {
-				log.Printf("Filesystem error (stat) for %s: %s\n", fileName, err)
-				continue
-			}
+			lib.updateScanStatus(func(status *ScanStatus) {
+				status.FilesWalked++
+				status.CurrentPath = fileName
+			})
 
-			file, err := parseFileTags(taglib.Read, fileName)
+			err := lib.rescanFile(fileName)
 			if err != nil {
-				log.Printf("Parsing tags error for %s: %s\n", fileName, err)
-				continue
-			}
-
-			fi := fileInfo{
-				Size:     st.Size(),
-				FilePath: fileName,
-				Modified: st.ModTime(),
-			}
-			if err := lib.insertMediaIntoDatabase(file, fi); err != nil {
-				log.Printf("failed updating file %s: %s\n", fileName, err)
+				log.Printf("%s\n", err)
 			}
+			lib.updateScanStatus(func(status *ScanStatus) {
+				if err != nil {
+					status.Errors++
+				} else {
+					status.FilesUpdated++
+				}
+			})
 		}
 	}
 
 	return nil
 }
 
+// rescanFile reads the meta data of a file which is already in the library and
+// updates it in the database.
+func (lib *LocalLibrary) rescanFile(fileName string) error {
+	st, err := os.Stat(fileName)
+	if err != nil {
+		lib.recordFileIssue(IssueUnreadablePath, fileName, err)
+		return fmt.Errorf("filesystem error (stat) for %s: %w", fileName, err)
+	}
+
+	file, err := parseFileTags(taglib.Read, fileName)
+	if err != nil {
+		lib.recordFileIssue(IssueBadTags, fileName, err)
+		return fmt.Errorf("parsing tags error for %s: %w", fileName, err)
+	}
+
+	fi := fileInfo{
+		Size:        st.Size(),
+		FilePath:    fileName,
+		Modified:    st.ModTime(),
+		Fingerprint: lib.fileFingerprint(fileName),
+	}
+	if err := lib.insertMediaIntoDatabase(file, fi); err != nil {
+		return fmt.Errorf("failed updating file %s: %w", fileName, err)
+	}
+
+	return nil
+}
+
 // getMediaFilenames returns batchSize media files after moving the db offset at
 // cursor size.
 func (lib *LocalLibrary) getMediaFilenames(
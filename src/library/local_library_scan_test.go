@@ -0,0 +1,100 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestStartScan checks that scans could be started on demand, that only one scan
+// is running at a time and that the scan status follows the scan's progress.
+func TestStartScan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lib := getPathedLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	status := lib.ScanStatus()
+	if status.Running || !status.StartedAt.IsZero() {
+		t.Errorf("expected no scan status before the first scan but got %+v", status)
+	}
+
+	// Holding the scan lock makes sure the started scan is still running when
+	// starting a second one.
+	lib.scanLock.Lock()
+	if err := lib.StartScan(false); err != nil {
+		lib.scanLock.Unlock()
+		t.Fatalf("starting scan: %s", err)
+	}
+	if err := lib.StartScan(true); !errors.Is(err, ErrScanInProgress) {
+		t.Errorf("expected ErrScanInProgress for a second scan but got %v", err)
+	}
+	if status := lib.ScanStatus(); !status.Running {
+		t.Errorf("expected the scan to be running")
+	}
+	lib.scanLock.Unlock()
+
+	status = waitForScanStatus(t, lib)
+	if status.FullRescan {
+		t.Errorf("expected a normal scan but got a full rescan")
+	}
+	if status.FilesWalked != 4 {
+		t.Errorf("expected 4 walked files but got %d", status.FilesWalked)
+	}
+	if status.FilesAdded != 3 {
+		t.Errorf("expected 3 added files but got %d", status.FilesAdded)
+	}
+	if status.Errors != 0 {
+		t.Errorf("expected no errors but got %d", status.Errors)
+	}
+	if status.CurrentPath != "" {
+		t.Errorf("expected no current path after the scan but got %s", status.CurrentPath)
+	}
+	if status.FinishedAt.Before(status.StartedAt) {
+		t.Errorf("scan finished at %s before it started at %s",
+			status.FinishedAt, status.StartedAt)
+	}
+
+	found := lib.Search(ctx, SearchArgs{Query: "Another One"})
+	if len(found) != 1 {
+		t.Errorf("expected a track to be found after the scan")
+	}
+
+	if err := lib.StartScan(true); err != nil {
+		t.Fatalf("starting full rescan: %s", err)
+	}
+	status = waitForScanStatus(t, lib)
+	if !status.FullRescan {
+		t.Errorf("expected a full rescan")
+	}
+	if status.FilesUpdated != 3 {
+		t.Errorf("expected 3 updated files but got %d", status.FilesUpdated)
+	}
+	if status.FilesAdded != 0 {
+		t.Errorf("expected no added files during rescan but got %d", status.FilesAdded)
+	}
+
+	if err := lib.StartScan(false); err != nil {
+		t.Fatalf("starting second scan: %s", err)
+	}
+	status = waitForScanStatus(t, lib)
+	if status.FilesAdded != 0 {
+		t.Errorf("expected no added files on the second scan but got %d",
+			status.FilesAdded)
+	}
+}
+
+// waitForScanStatus waits for the running scan to finish and returns its status.
+func waitForScanStatus(t *testing.T, lib *LocalLibrary) ScanStatus {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := lib.ScanStatus(); !status.Running {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("scan did not finish in time")
+	return ScanStatus{}
+}
@@ -92,9 +92,12 @@ func (lib *LocalLibrary) handleWatchEvent(event *fsnotify.FileEvent) {
 	}
 
 	if event.IsDelete() || event.IsRename() {
-		if lib.isSupportedFormat(event.Name) {
-			// This is a file
-			lib.removeFile(event.Name)
+		if lib.isPlaylistFile(event.Name) {
+			lib.removePlaylistFile(event.Name)
+		} else if lib.isSupportedFormat(event.Name) {
+			// This is a file. It is removed a bit later since it may have
+			// been moved to another place in the library.
+			lib.removeFileLater(event.Name)
 		} else {
 			// It was a directory... probably
 			lib.watchLock.Lock()
@@ -104,7 +107,7 @@ func (lib *LocalLibrary) handleWatchEvent(event *fsnotify.FileEvent) {
 				fmt.Printf("error removing watcher for %s: %s\n", event.Name, err)
 			}
 
-			lib.removeDirectory(event.Name)
+			lib.removeDirectoryLater(event.Name)
 		}
 		return
 	}
@@ -122,21 +125,37 @@ func (lib *LocalLibrary) handleWatchEvent(event *fsnotify.FileEvent) {
 		return
 	}
 
+	if (event.IsCreate() || event.IsModify()) && !st.IsDir() &&
+		lib.isPlaylistFile(event.Name) {
+		lib.syncPlaylistFile(event.Name)
+		return
+	}
+
 	if event.IsCreate() && !st.IsDir() {
 		if lib.isSupportedFormat(event.Name) {
-			if err := lib.AddMedia(event.Name); err != nil {
+			var err error
+			if lib.MediaExistsInLibrary(event.Name) {
+				// The file replaced one which is still in the library
+				// because it was removed just now.
+				err = lib.rescanFile(event.Name)
+			} else {
+				err = lib.AddMedia(event.Name)
+			}
+			if err != nil {
 				fmt.Printf("error adding newly created file: %s\n", err)
 			}
+			lib.relinkPlaylistEntries()
 		}
 		return
 	}
 
 	if event.IsModify() && !st.IsDir() {
 		if lib.isSupportedFormat(event.Name) {
-			lib.removeFile(event.Name)
-			if err := lib.AddMedia(event.Name); err != nil {
+			// The track is updated in place so that it keeps its ID.
+			if err := lib.rescanFile(event.Name); err != nil {
 				fmt.Printf("error adding modified file: %s\n", err)
 			}
+			lib.relinkPlaylistEntries()
 		}
 		return
 	}
@@ -0,0 +1,259 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MusicFolders returns the music folders for all library paths. See
+// Library.MusicFolders for more.
+func (lib *LocalLibrary) MusicFolders(_ context.Context) ([]MusicFolder, error) {
+	lib.musicFoldersLock.RLock()
+	defer lib.musicFoldersLock.RUnlock()
+
+	folders := make([]MusicFolder, len(lib.musicFolders))
+	copy(folders, lib.musicFolders)
+
+	return folders, nil
+}
+
+// TrackInMusicFolders implements Library.TrackInMusicFolders.
+func (lib *LocalLibrary) TrackInMusicFolders(
+	ctx context.Context,
+	trackID int64,
+	folderIDs []int64,
+) (bool, error) {
+	return lib.tracksInMusicFolders(ctx, "id", trackID, folderIDs)
+}
+
+// AlbumInMusicFolders implements Library.AlbumInMusicFolders.
+func (lib *LocalLibrary) AlbumInMusicFolders(
+	ctx context.Context,
+	albumID int64,
+	folderIDs []int64,
+) (bool, error) {
+	return lib.tracksInMusicFolders(ctx, "album_id", albumID, folderIDs)
+}
+
+// ArtistInMusicFolders implements Library.ArtistInMusicFolders.
+func (lib *LocalLibrary) ArtistInMusicFolders(
+	ctx context.Context,
+	artistID int64,
+	folderIDs []int64,
+) (bool, error) {
+	return lib.tracksInMusicFolders(ctx, "artist_id", artistID, folderIDs)
+}
+
+// tracksInMusicFolders returns true when there is a track with `id` in its
+// `column` which is in any of the music folders with IDs `folderIDs`.
+func (lib *LocalLibrary) tracksInMusicFolders(
+	ctx context.Context,
+	column string,
+	id int64,
+	folderIDs []int64,
+) (bool, error) {
+	if len(folderIDs) == 0 {
+		return true, nil
+	}
+
+	cond, args := musicFoldersWhere("music_folder_id", folderIDs)
+	args = append(args, sql.Named("id", id))
+
+	var found bool
+	work := func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT EXISTS (
+				SELECT 1
+				FROM tracks
+				WHERE %s = @id AND %s
+			)
+		`, column, cond), args...).Scan(&found)
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// registerMusicFolder stores path as a music folder in the database unless it is
+// already there. Tracks under path which do not have a music folder yet are
+// assigned to it. The name of the music folder is unique, see musicFolderName.
+func (lib *LocalLibrary) registerMusicFolder(path string) (MusicFolder, error) {
+	folder := MusicFolder{
+		Name: filepath.Base(path),
+		Path: path,
+	}
+
+	work := func(db *sql.DB) error {
+		_, err := db.Exec(`
+			INSERT OR IGNORE INTO
+				music_folders (name, fs_path)
+			VALUES
+				(@name, @fsPath)
+		`, sql.Named("name", folder.Name), sql.Named("fsPath", path))
+		if err != nil {
+			return fmt.Errorf("inserting: %w", err)
+		}
+
+		err = db.QueryRow(`
+			SELECT
+				id, name
+			FROM
+				music_folders
+			WHERE
+				fs_path = @fsPath
+		`, sql.Named("fsPath", path)).Scan(&folder.ID, &folder.Name)
+		if err != nil {
+			return fmt.Errorf("getting ID: %w", err)
+		}
+
+		name, err := musicFolderName(db, path, folder.ID)
+		if err != nil {
+			return fmt.Errorf("choosing name: %w", err)
+		}
+		if name != folder.Name {
+			_, err = db.Exec(`
+				UPDATE
+					music_folders
+				SET
+					name = @name
+				WHERE
+					id = @id
+			`, sql.Named("name", name), sql.Named("id", folder.ID))
+			if err != nil {
+				return fmt.Errorf("renaming: %w", err)
+			}
+			folder.Name = name
+		}
+
+		prefix := musicFolderPrefix(path)
+		_, err = db.Exec(`
+			UPDATE
+				tracks
+			SET
+				music_folder_id = @id
+			WHERE
+				music_folder_id IS NULL AND
+				substr(fs_path, 1, length(@prefix)) = @prefix
+		`, sql.Named("id", folder.ID), sql.Named("prefix", prefix))
+		if err != nil {
+			return fmt.Errorf("assigning tracks: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return folder, err
+	}
+
+	return folder, nil
+}
+
+// musicFolderName returns the name for the music folder with `id` at `path`.
+// Names are used for giving users access to music folders so they must be
+// unique. The name is the base name of the directory. When a music folder
+// registered earlier already has this name then parent directories are added
+// to it until it becomes unique. So "/mnt/a/Music" and "/mnt/b/Music" would be
+// named "Music" and "b/Music".
+func musicFolderName(db *sql.DB, path string, id int64) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(filepath.Clean(path), string(filepath.Separator)) {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	for i := len(parts) - 1; i >= 0; i-- {
+		name := filepath.Join(parts[i:]...)
+
+		var taken bool
+		err := db.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1
+				FROM music_folders
+				WHERE name = @name AND id < @id
+			)
+		`, sql.Named("name", name), sql.Named("id", id)).Scan(&taken)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return name, nil
+		}
+	}
+
+	return path, nil
+}
+
+// musicFolderID returns the ID of the music folder which contains the file at
+// fsPath. When there are nested music folders the deepest one is returned.
+func (lib *LocalLibrary) musicFolderID(fsPath string) (int64, bool) {
+	lib.musicFoldersLock.RLock()
+	defer lib.musicFoldersLock.RUnlock()
+
+	var (
+		found     bool
+		id        int64
+		prefixLen int
+	)
+	for _, folder := range lib.musicFolders {
+		prefix := musicFolderPrefix(folder.Path)
+		if !strings.HasPrefix(fsPath, prefix) || len(prefix) <= prefixLen {
+			continue
+		}
+
+		found = true
+		id = folder.ID
+		prefixLen = len(prefix)
+	}
+
+	return id, found
+}
+
+// musicFolderPrefix returns the prefix which all files in the directory at path
+// have.
+func musicFolderPrefix(path string) string {
+	if strings.HasSuffix(path, string(filepath.Separator)) {
+		return path
+	}
+	return path + string(filepath.Separator)
+}
+
+// musicFoldersWhere returns a SQL condition which is true when column is any of
+// the music folder IDs. It is accompanied with the named arguments used in it.
+// When there are no IDs an empty condition is returned.
+func musicFoldersWhere(column string, ids []int64) (string, []any) {
+	if len(ids) == 0 {
+		return "", nil
+	}
+
+	var (
+		names []string
+		args  []any
+	)
+	for i, id := range ids {
+		name := fmt.Sprintf("musicFolder%d", i)
+		names = append(names, "@"+name)
+		args = append(args, sql.Named(name, id))
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(names, ", ")), args
+}
+
+// newerThanWhere returns an SQL condition which matches rows with a Unix
+// timestamp in `column` after `newerThan`. When `newerThan` is the zero time
+// then an empty condition is returned.
+func newerThanWhere(column string, newerThan time.Time) (string, []any) {
+	if newerThan.IsZero() {
+		return "", nil
+	}
+
+	return column + " > @newerThan", []any{sql.Named("newerThan", newerThan.Unix())}
+}
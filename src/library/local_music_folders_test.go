@@ -0,0 +1,298 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+)
+
+// TestMusicFolders checks that every library path becomes a music folder with
+// a stable ID and that searching and browsing could be limited to some of them.
+func TestMusicFolders(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	lib := getLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "Main")
+	kidsPath := filepath.Join(root, "Kids")
+	for _, dir := range []string{mainPath, kidsPath} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatalf("creating directory %s: %s", dir, err)
+		}
+	}
+
+	// A track which is inserted before its library path is added must be
+	// assigned to the music folder later.
+	early := MockMedia{
+		artist: "Main Artist",
+		album:  "Main Album",
+		title:  "Early Song",
+		track:  1,
+		length: 120 * time.Second,
+	}
+	earlyPath := filepath.Join(mainPath, "album", "early.mp3")
+	insertMusicFolderTrack(t, lib, &early, earlyPath)
+
+	lib.AddLibraryPath(mainPath)
+	lib.AddLibraryPath(kidsPath)
+
+	folders, err := lib.MusicFolders(ctx)
+	if err != nil {
+		t.Fatalf("error getting music folders: %s", err)
+	}
+	if len(folders) != 2 {
+		t.Fatalf("expected 2 music folders but got %d: %+v", len(folders), folders)
+	}
+
+	mainFolder, kidsFolder := folders[0], folders[1]
+	if mainFolder.Name != "Main" || mainFolder.Path != mainPath {
+		t.Errorf("unexpected main music folder: %+v", mainFolder)
+	}
+	if kidsFolder.Name != "Kids" || kidsFolder.Path != kidsPath {
+		t.Errorf("unexpected kids music folder: %+v", kidsFolder)
+	}
+	if mainFolder.ID == kidsFolder.ID {
+		t.Errorf("expected different IDs for music folders but got %d", mainFolder.ID)
+	}
+
+	again, err := lib.registerMusicFolder(kidsPath)
+	if err != nil {
+		t.Fatalf("error registering music folder again: %s", err)
+	}
+	if again.ID != kidsFolder.ID {
+		t.Errorf("expected stable music folder ID %d but got %d", kidsFolder.ID, again.ID)
+	}
+
+	insertMusicFolderTrack(t, lib, &MockMedia{
+		artist: "Main Artist",
+		album:  "Main Album",
+		title:  "Main Song",
+		track:  2,
+		length: 180 * time.Second,
+	}, filepath.Join(mainPath, "album", "main.mp3"))
+	insertMusicFolderTrack(t, lib, &MockMedia{
+		artist: "Kids Artist",
+		album:  "Kids Album",
+		title:  "Kids Song",
+		track:  1,
+		length: 90 * time.Second,
+	}, filepath.Join(kidsPath, "album", "kids.mp3"))
+
+	tests := []struct {
+		desc    string
+		folders []int64
+		tracks  []string
+		albums  []string
+		artists []string
+	}{
+		{
+			desc:    "main folder",
+			folders: []int64{mainFolder.ID},
+			tracks:  []string{"Early Song", "Main Song"},
+			albums:  []string{"Main Album"},
+			artists: []string{"Main Artist"},
+		},
+		{
+			desc:    "kids folder",
+			folders: []int64{kidsFolder.ID},
+			tracks:  []string{"Kids Song"},
+			albums:  []string{"Kids Album"},
+			artists: []string{"Kids Artist"},
+		},
+		{
+			desc:    "both folders",
+			folders: []int64{mainFolder.ID, kidsFolder.ID},
+			tracks:  []string{"Early Song", "Kids Song", "Main Song"},
+			albums:  []string{"Kids Album", "Main Album"},
+			artists: []string{"Kids Artist", "Main Artist"},
+		},
+		{
+			desc:    "missing folder",
+			folders: []int64{-1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			searchArgs := SearchArgs{MusicFolderIDs: test.folders}
+
+			var tracks []string
+			for _, track := range lib.Search(ctx, searchArgs) {
+				tracks = append(tracks, track.Title)
+				if !slices.Contains(test.folders, track.MusicFolderID) {
+					t.Errorf("track %s has unexpected music folder %d",
+						track.Title, track.MusicFolderID)
+				}
+			}
+			assertNames(t, "searched tracks", test.tracks, tracks)
+
+			var albums []string
+			for _, album := range lib.SearchAlbums(ctx, searchArgs) {
+				albums = append(albums, album.Name)
+			}
+			assertNames(t, "searched albums", test.albums, albums)
+
+			var artists []string
+			for _, artist := range lib.SearchArtists(ctx, searchArgs) {
+				artists = append(artists, artist.Name)
+			}
+			assertNames(t, "searched artists", test.artists, artists)
+
+			browseArgs := BrowseArgs{PerPage: 100, MusicFolderIDs: test.folders}
+
+			tracks = nil
+			browsedTracks, count := lib.BrowseTracks(browseArgs)
+			for _, track := range browsedTracks {
+				tracks = append(tracks, track.Title)
+			}
+			assertNames(t, "browsed tracks", test.tracks, tracks)
+			if count != len(test.tracks) {
+				t.Errorf(
+					"expected %d browsed tracks in total but got %d",
+					len(test.tracks), count,
+				)
+			}
+
+			albums = nil
+			browsedAlbums, count := lib.BrowseAlbums(browseArgs)
+			for _, album := range browsedAlbums {
+				albums = append(albums, album.Name)
+			}
+			assertNames(t, "browsed albums", test.albums, albums)
+			if count != len(test.albums) {
+				t.Errorf(
+					"expected %d browsed albums in total but got %d",
+					len(test.albums), count,
+				)
+			}
+
+			artists = nil
+			browsedArtists, count := lib.BrowseArtists(browseArgs)
+			for _, artist := range browsedArtists {
+				artists = append(artists, artist.Name)
+			}
+			assertNames(t, "browsed artists", test.artists, artists)
+			if count != len(test.artists) {
+				t.Errorf(
+					"expected %d browsed artists in total but got %d",
+					len(test.artists), count,
+				)
+			}
+		})
+	}
+
+	found := lib.Search(ctx, SearchArgs{Query: "Kids Song"})
+	if len(found) != 1 {
+		t.Fatalf("expected one kids song but got %d", len(found))
+	}
+	kidsSong := found[0]
+
+	checks := []struct {
+		desc     string
+		folders  []int64
+		expected bool
+	}{
+		{desc: "own folder", folders: []int64{kidsFolder.ID}, expected: true},
+		{desc: "other folder", folders: []int64{mainFolder.ID}},
+		{desc: "any folder", expected: true},
+	}
+	for _, check := range checks {
+		t.Run("items in "+check.desc, func(t *testing.T) {
+			inTrack, err := lib.TrackInMusicFolders(ctx, kidsSong.ID, check.folders)
+			assert.NilErr(t, err, "checking track")
+			assert.Equal(t, check.expected, inTrack, "track in music folders")
+
+			inAlbum, err := lib.AlbumInMusicFolders(ctx, kidsSong.AlbumID, check.folders)
+			assert.NilErr(t, err, "checking album")
+			assert.Equal(t, check.expected, inAlbum, "album in music folders")
+
+			inArtist, err := lib.ArtistInMusicFolders(ctx, kidsSong.ArtistID, check.folders)
+			assert.NilErr(t, err, "checking artist")
+			assert.Equal(t, check.expected, inArtist, "artist in music folders")
+		})
+	}
+}
+
+// TestMusicFolderNames makes sure that music folders get unique names even when
+// their directories have the same base name.
+func TestMusicFolderNames(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	lib := getLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	root := t.TempDir()
+	paths := []string{
+		filepath.Join(root, "a", "Music"),
+		filepath.Join(root, "b", "Music"),
+		filepath.Join(root, "c", "b", "Music"),
+	}
+	for _, dir := range paths {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatalf("creating directory %s: %s", dir, err)
+		}
+		lib.AddLibraryPath(dir)
+	}
+
+	folders, err := lib.MusicFolders(ctx)
+	assert.NilErr(t, err, "getting music folders")
+
+	var names []string
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+	}
+	assertNames(t, "music folder", []string{
+		"Music",
+		filepath.Join("b", "Music"),
+		filepath.Join("c", "b", "Music"),
+	}, names)
+
+	// Registering again keeps the names.
+	for ind, dir := range paths {
+		folder, err := lib.registerMusicFolder(dir)
+		assert.NilErr(t, err, "registering music folder %s again", dir)
+		assert.Equal(t, folders[ind].Name, folder.Name, "name of music folder %s", dir)
+	}
+}
+
+func insertMusicFolderTrack(
+	t *testing.T,
+	lib *LocalLibrary,
+	track *MockMedia,
+	path string,
+) {
+	t.Helper()
+
+	info := fileInfo{
+		Size:     int64(track.Length().Seconds()) * 128000,
+		FilePath: path,
+		Modified: time.Now(),
+	}
+	if err := lib.insertMediaIntoDatabase(track, info); err != nil {
+		t.Fatalf("adding media file %s failed: %s", track.Title(), err)
+	}
+}
+
+func assertNames(t *testing.T, what string, expected, found []string) {
+	t.Helper()
+
+	sort.Strings(found)
+	if len(expected) != len(found) {
+		t.Fatalf("expected %s %v but got %v", what, expected, found)
+	}
+	for i := range expected {
+		if expected[i] != found[i] {
+			t.Fatalf("expected %s %v but got %v", what, expected, found)
+		}
+	}
+}
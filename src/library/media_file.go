@@ -2,7 +2,11 @@ package library
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dhowden/tag"
@@ -33,6 +37,124 @@ type MediaFile interface {
 
 	// Returns the bitrate of the file in kb/s.
 	Bitrate() int
+
+	// Genre returns the genre of this media as written in its tags.
+	Genre() string
+
+	// Comment returns the comment tag of this media.
+	Comment() string
+
+	// Properties returns the tags of this media which are read by their names.
+	Properties() MediaProperties
+}
+
+// isrcSeparator separates the ISRCs of a track when they are stored in the
+// database.
+const isrcSeparator = ";"
+
+// MediaProperties are the tags of a media file which are read by their TagLib
+// property names instead of with the basic tag fields. Reading them needs
+// TagLib 2.0 or newer. They are all empty otherwise.
+type MediaProperties struct {
+	// SortName is the title by which the media is sorted.
+	SortName string
+
+	// MusicBrainzID is the MusicBrainz recording ID of the media.
+	MusicBrainzID string
+
+	// ISRCs are the International Standard Recording Codes of the media.
+	ISRCs []string
+
+	// BPM is the number of beats per minute. Zero when unknown.
+	BPM int
+
+	// Disc is the number of the disc of the album on which the media is. Zero
+	// when unknown.
+	Disc int
+
+	// DiscSubtitle is the title of the disc on which the media is.
+	DiscSubtitle string
+
+	// AlbumSortName is the name by which the album of the media is sorted.
+	AlbumSortName string
+
+	// AlbumMusicBrainzID is the MusicBrainz release ID of the album.
+	AlbumMusicBrainzID string
+
+	// ReleaseDate and OriginalReleaseDate are the dates of the release of the
+	// album and of its first release. They are as written in the tags which
+	// is usually one of "YYYY", "YYYY-MM" and "YYYY-MM-DD".
+	ReleaseDate         string
+	OriginalReleaseDate string
+
+	// Compilation is true when the album is a compilation.
+	Compilation bool
+}
+
+// mediaPropertyNames are the names of the TagLib properties which are read for
+// MediaProperties.
+var mediaPropertyNames = []string{
+	"TITLESORT",
+	"MUSICBRAINZ_TRACKID",
+	"ISRC",
+	"BPM",
+	"DISCNUMBER",
+	"DISCSUBTITLE",
+	"ALBUMSORT",
+	"MUSICBRAINZ_ALBUMID",
+	"RELEASEDATE",
+	"DATE",
+	"ORIGINALDATE",
+	"COMPILATION",
+}
+
+// mediaPropertiesFromMap returns the MediaProperties for the TagLib `properties`
+// which map property names to their values.
+func mediaPropertiesFromMap(properties map[string][]string) MediaProperties {
+	first := func(name string) string {
+		for _, value := range properties[name] {
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	props := MediaProperties{
+		SortName:            first("TITLESORT"),
+		MusicBrainzID:       first("MUSICBRAINZ_TRACKID"),
+		DiscSubtitle:        first("DISCSUBTITLE"),
+		AlbumSortName:       first("ALBUMSORT"),
+		AlbumMusicBrainzID:  first("MUSICBRAINZ_ALBUMID"),
+		ReleaseDate:         first("RELEASEDATE"),
+		OriginalReleaseDate: first("ORIGINALDATE"),
+		Compilation:         first("COMPILATION") == "1",
+	}
+	if props.ReleaseDate == "" {
+		props.ReleaseDate = first("DATE")
+	}
+
+	// Some taggers write many ISRCs in a single value.
+	for _, value := range properties["ISRC"] {
+		for _, isrc := range strings.Split(value, isrcSeparator) {
+			if isrc = strings.TrimSpace(isrc); isrc != "" {
+				props.ISRCs = append(props.ISRCs, isrc)
+			}
+		}
+	}
+
+	// The BPM is sometimes written with a fractional part.
+	if bpm, err := strconv.ParseFloat(first("BPM"), 64); err == nil && bpm > 0 {
+		props.BPM = int(math.Round(bpm))
+	}
+
+	// The disc number could be followed by the number of discs as in "1/2".
+	disc, _, _ := strings.Cut(first("DISCNUMBER"), "/")
+	if number, err := strconv.Atoi(strings.TrimSpace(disc)); err == nil && number > 0 {
+		props.Disc = number
+	}
+
+	return props
 }
 
 // TaglibRead is a function which uses taglib to read a file.
@@ -43,7 +165,15 @@ func parseFileTags(readFunc TaglibRead, fileName string) (MediaFile, error) {
 	file, tglErr := readFunc(fileName)
 	if tglErr == nil {
 		defer file.Close()
-		return medaFileFromTaglib(file), nil
+		mf := medaFileFromTaglib(file)
+
+		props, err := readTaglibProperties(fileName)
+		if err != nil {
+			log.Printf("Error reading tag properties of %s: %s\n", fileName, err)
+		}
+		mf.properties = props
+
+		return mf, nil
 	}
 
 	mf, tagErr := mediaFileFromTag(fileName)
@@ -65,6 +195,10 @@ type mediaFile struct {
 	length  time.Duration
 	year    int
 	bitrate int
+	genre   string
+	comment string
+
+	properties MediaProperties
 }
 
 func (f *mediaFile) Artist() string        { return f.artist }
@@ -74,9 +208,13 @@ func (f *mediaFile) Track() int            { return f.track }
 func (f *mediaFile) Length() time.Duration { return f.length }
 func (f *mediaFile) Year() int             { return f.year }
 func (f *mediaFile) Bitrate() int          { return f.bitrate }
+func (f *mediaFile) Genre() string         { return f.genre }
+func (f *mediaFile) Comment() string       { return f.comment }
+
+func (f *mediaFile) Properties() MediaProperties { return f.properties }
 
 // medaFileFromTaglib returns a MediaFile from a taglib parsed file.
-func medaFileFromTaglib(file *taglib.File) MediaFile {
+func medaFileFromTaglib(file *taglib.File) *mediaFile {
 	return &mediaFile{
 		artist:  file.Artist(),
 		album:   file.Album(),
@@ -85,6 +223,8 @@ func medaFileFromTaglib(file *taglib.File) MediaFile {
 		length:  file.Length(),
 		year:    file.Year(),
 		bitrate: file.Bitrate(),
+		genre:   file.Genre(),
+		comment: file.Comment(),
 	}
 }
 
@@ -101,12 +241,17 @@ func mediaFileFromTag(fileName string) (MediaFile, error) {
 	}
 
 	track, _ := md.Track()
+	disc, _ := md.Disc()
 	file := &mediaFile{
-		artist: md.Artist(),
-		album:  md.Album(),
-		title:  md.Title(),
-		track:  track,
-		year:   md.Year(),
+		artist:  md.Artist(),
+		album:   md.Album(),
+		title:   md.Title(),
+		track:   track,
+		year:    md.Year(),
+		genre:   md.Genre(),
+		comment: md.Comment(),
+
+		properties: MediaProperties{Disc: disc},
 	}
 
 	return file, nil
@@ -3,6 +3,7 @@ package library
 import (
 	"errors"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/ironsmile/euterpe/src/assert"
@@ -42,4 +43,89 @@ func TestTagParsingFallback(t *testing.T) {
 	}
 }
 
+// TestMediaPropertiesFromMap checks how the additional tag properties read with
+// TagLib are converted to MediaProperties.
+func TestMediaPropertiesFromMap(t *testing.T) {
+	tests := []struct {
+		desc       string
+		properties map[string][]string
+		expected   MediaProperties
+	}{
+		{
+			desc:       "no properties",
+			properties: nil,
+			expected:   MediaProperties{},
+		},
+		{
+			desc: "all properties",
+			properties: map[string][]string{
+				"TITLESORT":           {"Song, The"},
+				"MUSICBRAINZ_TRACKID": {"e5a3f8a0-0b8d-4d46-9a27-6b2a4bb1f0c3"},
+				"ISRC":                {"USRC17607839"},
+				"BPM":                 {"128"},
+				"DISCNUMBER":          {"2"},
+				"DISCSUBTITLE":        {"Live"},
+				"ALBUMSORT":           {"Album, The"},
+				"MUSICBRAINZ_ALBUMID": {"0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1"},
+				"RELEASEDATE":         {"2020-05-01"},
+				"DATE":                {"2021"},
+				"ORIGINALDATE":        {"1999-12"},
+				"COMPILATION":         {"1"},
+			},
+			expected: MediaProperties{
+				SortName:            "Song, The",
+				MusicBrainzID:       "e5a3f8a0-0b8d-4d46-9a27-6b2a4bb1f0c3",
+				ISRCs:               []string{"USRC17607839"},
+				BPM:                 128,
+				Disc:                2,
+				DiscSubtitle:        "Live",
+				AlbumSortName:       "Album, The",
+				AlbumMusicBrainzID:  "0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1",
+				ReleaseDate:         "2020-05-01",
+				OriginalReleaseDate: "1999-12",
+				Compilation:         true,
+			},
+		},
+		{
+			desc: "unusual values",
+			properties: map[string][]string{
+				"TITLESORT":   {"  ", " Sort "},
+				"ISRC":        {"USRC17607839; GBAYE0601498", "USRC17607840"},
+				"BPM":         {"97.6"},
+				"DISCNUMBER":  {"3/4"},
+				"DATE":        {"2021"},
+				"COMPILATION": {"0"},
+			},
+			expected: MediaProperties{
+				SortName: "Sort",
+				ISRCs: []string{
+					"USRC17607839",
+					"GBAYE0601498",
+					"USRC17607840",
+				},
+				BPM:         98,
+				Disc:        3,
+				ReleaseDate: "2021",
+			},
+		},
+		{
+			desc: "invalid numbers",
+			properties: map[string][]string{
+				"BPM":        {"fast"},
+				"DISCNUMBER": {"A"},
+			},
+			expected: MediaProperties{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			props := mediaPropertiesFromMap(test.properties)
+			if !reflect.DeepEqual(test.expected, props) {
+				t.Errorf("expected properties %+v but got %+v", test.expected, props)
+			}
+		})
+	}
+}
+
 var errTaglibTesting = errors.New("error reading from taglib mock")
@@ -11,6 +11,10 @@ type MockMedia struct {
 	length  time.Duration
 	year    int
 	bitrate int
+	genre   string
+	comment string
+
+	properties MediaProperties
 }
 
 // Artist satisfies the MediaFile interface and just returns the object attribute.
@@ -52,3 +56,18 @@ func (m *MockMedia) Bitrate() int {
 	}
 	return m.bitrate
 }
+
+// Genre satisfies the MediaFile interface and just returns the object attribute.
+func (m *MockMedia) Genre() string {
+	return m.genre
+}
+
+// Comment satisfies the MediaFile interface and just returns the object attribute.
+func (m *MockMedia) Comment() string {
+	return m.comment
+}
+
+// Properties satisfies the MediaFile interface and just returns the object attribute.
+func (m *MockMedia) Properties() MediaProperties {
+	return m.properties
+}
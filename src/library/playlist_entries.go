@@ -0,0 +1,36 @@
+package library
+
+import (
+	"context"
+	"log"
+)
+
+//counterfeiter:generate . PlaylistEntriesLinker
+
+// PlaylistEntriesLinker keeps the playlist entries linked to their tracks when
+// the tracks are removed from the library and added back, possibly under a
+// different path.
+type PlaylistEntriesLinker interface {
+	// RelinkPlaylistEntries links the playlist entries which tracks are missing
+	// from the library to matching tracks in it, if there are such.
+	RelinkPlaylistEntries(ctx context.Context) error
+}
+
+// SetPlaylistEntriesLinker binds a PlaylistEntriesLinker to this library.
+// Without one playlist entries stay missing after their tracks are removed
+// from the library.
+func (lib *LocalLibrary) SetPlaylistEntriesLinker(linker PlaylistEntriesLinker) {
+	lib.playlistEntries = linker
+}
+
+// relinkPlaylistEntries links the missing playlist entries to tracks which
+// were added to the library.
+func (lib *LocalLibrary) relinkPlaylistEntries() {
+	if lib.playlistEntries == nil {
+		return
+	}
+
+	if err := lib.playlistEntries.RelinkPlaylistEntries(lib.ctx); err != nil {
+		log.Printf("Error relinking playlist entries: %s\n", err)
+	}
+}
@@ -0,0 +1,114 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+//counterfeiter:generate . PlaylistFilesSyncer
+
+// PlaylistFilesSyncer keeps playlists in sync with the playlist files (such as
+// .m3u8 files) found in the library directories.
+type PlaylistFilesSyncer interface {
+	// SyncPlaylistFile creates or updates the playlist for the file at `path`.
+	// The content of the file is read from `file`.
+	SyncPlaylistFile(ctx context.Context, path string, file io.Reader) error
+
+	// RemovePlaylistFile removes the playlist for the file at `path`.
+	RemovePlaylistFile(ctx context.Context, path string) error
+
+	// PlaylistFiles returns the paths of all playlist files which have
+	// playlists.
+	PlaylistFiles(ctx context.Context) ([]string, error)
+}
+
+// SetPlaylistFilesSyncer binds a PlaylistFilesSyncer to this library. Without
+// one playlist files in the library directories are ignored.
+func (lib *LocalLibrary) SetPlaylistFilesSyncer(syncer PlaylistFilesSyncer) {
+	lib.playlistFiles = syncer
+}
+
+// isPlaylistFile returns true when `path` is a playlist file which should be
+// synced with the playlists.
+func (lib *LocalLibrary) isPlaylistFile(path string) bool {
+	if lib.playlistFiles == nil {
+		return false
+	}
+
+	// Hidden files are skipped since editors and file managers tend to leave
+	// temporary copies of the files they change.
+	base := filepath.Base(path)
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+
+	ext := filepath.Ext(base)
+
+	for _, format := range []string{".m3u", ".m3u8", ".pls", ".xspf"} {
+		if strings.EqualFold(ext, format) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncPlaylistFile creates or updates the playlist for the playlist file at
+// `path`.
+func (lib *LocalLibrary) syncPlaylistFile(path string) {
+	fullPath, err := filepath.Abs(path)
+	if err != nil {
+		log.Printf("Error syncing playlist file %s: %s\n", path, err)
+		return
+	}
+
+	file, err := lib.fs.Open(fullPath)
+	if err != nil {
+		log.Printf("Error opening playlist file %s: %s\n", fullPath, err)
+		return
+	}
+	defer file.Close()
+
+	if err := lib.playlistFiles.SyncPlaylistFile(lib.ctx, fullPath, file); err != nil {
+		log.Printf("Error syncing playlist file %s: %s\n", fullPath, err)
+	}
+}
+
+// removePlaylistFile removes the playlist for the playlist file at `path`.
+func (lib *LocalLibrary) removePlaylistFile(path string) {
+	fullPath, err := filepath.Abs(path)
+	if err != nil {
+		log.Printf("Error removing playlist file %s: %s\n", path, err)
+		return
+	}
+
+	if err := lib.playlistFiles.RemovePlaylistFile(lib.ctx, fullPath); err != nil {
+		log.Printf("Error removing playlist file %s: %s\n", fullPath, err)
+	}
+}
+
+// cleanupPlaylistFiles removes the playlists for playlist files which no longer
+// exist.
+func (lib *LocalLibrary) cleanupPlaylistFiles() {
+	if lib.playlistFiles == nil {
+		return
+	}
+
+	paths, err := lib.playlistFiles.PlaylistFiles(lib.ctx)
+	if err != nil {
+		log.Printf("Error getting playlist files for cleanup: %s\n", err)
+		return
+	}
+
+	for _, path := range paths {
+		if _, err := fs.Stat(lib.fs, path); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		lib.removePlaylistFile(path)
+	}
+}
@@ -0,0 +1,186 @@
+package library
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/helpers"
+)
+
+// TestPlaylistFilesScan checks that playlist files found while scanning are
+// synced after the tracks of the scanned directory are in the library and that
+// the playlists for removed files are cleaned up.
+func TestPlaylistFilesScan(t *testing.T) {
+	ctx := t.Context()
+
+	projRoot, err := helpers.ProjectRoot()
+	if err != nil {
+		t.Fatalf("Was not able to find test_files directory: %s", err)
+	}
+
+	libDir := t.TempDir()
+	err = copyFile(
+		filepath.Join(projRoot, "test_files", "library", "test_file_one.mp3"),
+		filepath.Join(libDir, "test_file_one.mp3"),
+	)
+	if err != nil {
+		t.Fatalf("copying test file: %s", err)
+	}
+
+	const playlistContent = "#EXTM3U\ntest_file_one.mp3\n"
+	playlistPath := filepath.Join(libDir, "favourites.m3u8")
+	err = os.WriteFile(playlistPath, []byte(playlistContent), 0o644)
+	if err != nil {
+		t.Fatalf("writing playlist file: %s", err)
+	}
+
+	hiddenPath := filepath.Join(libDir, ".hidden.m3u")
+	if err := os.WriteFile(hiddenPath, []byte(playlistContent), 0o644); err != nil {
+		t.Fatalf("writing hidden playlist file: %s", err)
+	}
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	syncer := &recordingPlaylistSyncer{lib: lib}
+	lib.SetPlaylistFilesSyncer(syncer)
+	lib.DisableWatching()
+	lib.AddLibraryPath(libDir)
+	waitForLibraryScan(t, lib)
+
+	synced := syncer.syncedFiles()
+	if len(synced) != 1 {
+		t.Fatalf("expected one synced playlist file but got %v", synced)
+	}
+	if synced[0].path != playlistPath {
+		t.Errorf("expected playlist file %s but got %s", playlistPath, synced[0].path)
+	}
+	if synced[0].content != playlistContent {
+		t.Errorf("wrong playlist file content: %q", synced[0].content)
+	}
+	if synced[0].tracks != 1 {
+		t.Errorf("expected the track to be in the library during playlist sync, "+
+			"found %d tracks", synced[0].tracks)
+	}
+
+	if err := os.Remove(playlistPath); err != nil {
+		t.Fatalf("removing playlist file: %s", err)
+	}
+	lib.cleanupPlaylistFiles()
+
+	removed := syncer.removedFiles()
+	if !slices.Equal([]string{playlistPath}, removed) {
+		t.Errorf("expected removed playlist file %s but got %v", playlistPath, removed)
+	}
+}
+
+// TestPlaylistFilesWithoutSyncer checks that playlist files are not recognised
+// when the library has no syncer for them.
+func TestPlaylistFilesWithoutSyncer(t *testing.T) {
+	lib := getLibrary(t.Context(), t)
+	defer func() { _ = lib.Truncate() }()
+
+	if lib.isPlaylistFile("/music/favourites.m3u8") {
+		t.Errorf("playlist file recognised without a syncer")
+	}
+
+	lib.SetPlaylistFilesSyncer(&recordingPlaylistSyncer{lib: lib})
+	for path, expected := range map[string]bool{
+		"/music/favourites.m3u8": true,
+		"/music/favourites.M3U":  true,
+		"/music/favourites.pls":  true,
+		"/music/favourites.xspf": true,
+		"/music/.m3u":            false,
+		"/music/.favourites.m3u": false,
+		"/music/track.mp3":       false,
+	} {
+		if actual := lib.isPlaylistFile(path); actual != expected {
+			t.Errorf("isPlaylistFile(%s): expected %t but got %t",
+				path, expected, actual)
+		}
+	}
+}
+
+// recordingPlaylistSyncer is a PlaylistFilesSyncer which records its calls
+// together with the number of tracks in the library at the time of syncing.
+type recordingPlaylistSyncer struct {
+	lib *LocalLibrary
+
+	sync.Mutex
+	synced  []syncedPlaylistFile
+	removed []string
+}
+
+type syncedPlaylistFile struct {
+	path    string
+	content string
+	tracks  int
+}
+
+func (s *recordingPlaylistSyncer) SyncPlaylistFile(
+	ctx context.Context,
+	path string,
+	file io.Reader,
+) error {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	tracks := len(s.lib.Search(ctx, SearchArgs{Query: "", Count: 100}))
+
+	s.Lock()
+	defer s.Unlock()
+	s.synced = append(s.synced, syncedPlaylistFile{
+		path:    path,
+		content: string(content),
+		tracks:  tracks,
+	})
+	return nil
+}
+
+func (s *recordingPlaylistSyncer) RemovePlaylistFile(
+	_ context.Context,
+	path string,
+) error {
+	s.Lock()
+	defer s.Unlock()
+	s.removed = append(s.removed, path)
+	return nil
+}
+
+func (s *recordingPlaylistSyncer) PlaylistFiles(_ context.Context) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	var paths []string
+	for _, synced := range s.synced {
+		if !slices.Contains(s.removed, synced.path) {
+			paths = append(paths, synced.path)
+		}
+	}
+	return paths, nil
+}
+
+func (s *recordingPlaylistSyncer) syncedFiles() []syncedPlaylistFile {
+	s.Lock()
+	defer s.Unlock()
+	return slices.Clone(s.synced)
+}
+
+func (s *recordingPlaylistSyncer) removedFiles() []string {
+	s.Lock()
+	defer s.Unlock()
+	return slices.Clone(s.removed)
+}
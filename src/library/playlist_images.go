@@ -0,0 +1,315 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"log"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// mosaicWidth is the width and height of the original size playlist mosaics.
+	mosaicWidth = 600
+
+	// mosaicMaxAlbums is the maximum number of albums for which artwork will be
+	// looked up when creating a mosaic. It keeps playlists with many albums
+	// without artwork from causing too many lookups.
+	mosaicMaxAlbums = 16
+)
+
+// mosaicLayouts defines which of the found artworks goes in each of the four
+// tiles of a mosaic depending on the number of found artworks. The tiles are
+// ordered left to right and top to bottom.
+var mosaicLayouts = map[int][4]int{
+	2: {0, 1, 1, 0},
+	3: {0, 1, 2, 0},
+	4: {0, 1, 2, 3},
+}
+
+// FindPlaylistImage implements the PlaylistImageManager interface for the local
+// library. Small images are created from the original one on first use and stored
+// in the database for later retrieval.
+func (lib *LocalLibrary) FindPlaylistImage(
+	ctx context.Context,
+	playlistID int64,
+	size ImageSize,
+) (io.ReadCloser, error) {
+	original, small, err := lib.playlistImageFromDB(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == OriginalImage {
+		return newBytesReadCloser(original), nil
+	}
+
+	if len(small) > 0 {
+		return newBytesReadCloser(small), nil
+	}
+
+	scaled, err := lib.scaleImage(ctx, newBytesReadCloser(original), size)
+	if err != nil {
+		return nil, fmt.Errorf("error scaling playlist image: %w", err)
+	}
+	defer scaled.Close()
+
+	small, err = io.ReadAll(scaled)
+	if err != nil {
+		return nil, fmt.Errorf("reading scaled playlist image: %w", err)
+	}
+
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `
+			UPDATE playlists_images
+			SET image_small = @image_small
+			WHERE playlist_id = @playlist_id
+		`,
+			sql.Named("image_small", small),
+			sql.Named("playlist_id", playlistID),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return nil, fmt.Errorf("storing small playlist image: %w", err)
+	}
+
+	return newBytesReadCloser(small), nil
+}
+
+// playlistImageFromDB returns the original and the small image of a playlist. The
+// small one is empty when it has not been created yet.
+func (lib *LocalLibrary) playlistImageFromDB(
+	ctx context.Context,
+	playlistID int64,
+) ([]byte, []byte, error) {
+	var original, small []byte
+
+	work := func(db *sql.DB) error {
+		row := db.QueryRowContext(ctx, `
+			SELECT
+				image,
+				image_small
+			FROM
+				playlists_images
+			WHERE
+				playlist_id = @playlist_id
+		`, sql.Named("playlist_id", playlistID))
+
+		err := row.Scan(&original, &small)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrArtworkNotFound
+		} else if err != nil {
+			return fmt.Errorf("error getting playlist image from db: %w", err)
+		}
+
+		return nil
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return nil, nil, err
+	}
+
+	if len(original) == 0 {
+		return nil, nil, ErrArtworkNotFound
+	}
+
+	return original, small, nil
+}
+
+// SavePlaylistImage implements the PlaylistImageManager interface for the local
+// library.
+//
+// It saves the image in `r` in the database. It will read up to 5MB of data from
+// `r` and if this limit is reached, the image is considered too big and will not
+// be saved in the db.
+func (lib *LocalLibrary) SavePlaylistImage(
+	ctx context.Context,
+	playlistID int64,
+	r io.Reader,
+) error {
+	var readLimit int64 = 5 * 1024 * 1024
+
+	lr := &io.LimitedReader{
+		R: r,
+		N: readLimit,
+	}
+
+	buff, err := io.ReadAll(lr)
+	if err != nil {
+		return fmt.Errorf("reading image for playlist %d: %w", playlistID, err)
+	}
+
+	if int64(len(buff)) >= readLimit {
+		return ErrArtworkTooBig
+	}
+
+	if len(buff) == 0 {
+		return NewArtworkError(errors.New("uploaded playlist image is empty"))
+	}
+
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx, `
+			INSERT INTO
+				playlists_images (playlist_id, image, image_small, updated_at)
+			SELECT
+				@playlist_id, @image, NULL, @updated_at
+			WHERE
+				EXISTS (SELECT 1 FROM playlists WHERE id = @playlist_id)
+			ON CONFLICT (playlist_id) DO
+			UPDATE SET
+				image = excluded.image,
+				image_small = NULL,
+				updated_at = excluded.updated_at
+		`,
+			sql.Named("playlist_id", playlistID),
+			sql.Named("image", buff),
+			sql.Named("updated_at", time.Now().Unix()),
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+
+		if affected < 1 {
+			return ErrPlaylistNotFound
+		}
+
+		return nil
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return fmt.Errorf("storing playlist image: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePlaylistImage implements the PlaylistImageManager interface for the local
+// library.
+func (lib *LocalLibrary) RemovePlaylistImage(ctx context.Context, playlistID int64) error {
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx,
+			"DELETE FROM playlists_images WHERE playlist_id = @playlist_id",
+			sql.Named("playlist_id", playlistID),
+		)
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return fmt.Errorf("removing playlist image: %w", err)
+	}
+
+	return nil
+}
+
+// AlbumsMosaic implements the PlaylistImageManager interface for the local library.
+// With a single found artwork it is used for the whole mosaic and with two or three
+// some of them are repeated in order to fill all four tiles.
+func (lib *LocalLibrary) AlbumsMosaic(
+	ctx context.Context,
+	albumIDs []int64,
+	size ImageSize,
+) (io.ReadCloser, error) {
+	var (
+		artworks []image.Image
+		tried    int
+		seen     = make(map[int64]struct{})
+	)
+
+	for _, albumID := range albumIDs {
+		if len(artworks) == 4 || tried >= mosaicMaxAlbums {
+			break
+		}
+
+		if _, ok := seen[albumID]; ok {
+			continue
+		}
+		seen[albumID] = struct{}{}
+		tried++
+
+		artwork, err := lib.albumArtworkImage(ctx, albumID)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		} else if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			log.Printf("Getting album %d artwork for mosaic: %s\n", albumID, err)
+			continue
+		}
+
+		artworks = append(artworks, artwork)
+	}
+
+	if len(artworks) == 0 {
+		return nil, ErrArtworkNotFound
+	}
+
+	width := mosaicWidth
+	if size == SmallImage {
+		width = thumbnailWidth
+	}
+
+	mosaic := image.NewRGBA(image.Rect(0, 0, width, width))
+	if len(artworks) == 1 {
+		drawSquare(mosaic, mosaic.Bounds(), artworks[0])
+	} else {
+		half := width / 2
+		for tile, artworkIndex := range mosaicLayouts[len(artworks)] {
+			x := (tile % 2) * half
+			y := (tile / 2) * half
+			drawSquare(mosaic, image.Rect(x, y, x+half, y+half), artworks[artworkIndex])
+		}
+	}
+
+	var buff bytes.Buffer
+	if err := jpeg.Encode(&buff, mosaic, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("encoding mosaic: %w", err)
+	}
+
+	return newBytesReadCloser(buff.Bytes()), nil
+}
+
+// albumArtworkImage returns the decoded original artwork of an album.
+func (lib *LocalLibrary) albumArtworkImage(
+	ctx context.Context,
+	albumID int64,
+) (image.Image, error) {
+	artwork, err := lib.FindAndSaveAlbumArtwork(ctx, albumID, OriginalImage)
+	if err != nil {
+		return nil, err
+	}
+	defer artwork.Close()
+
+	img, _, err := image.Decode(artwork)
+	if err != nil {
+		return nil, fmt.Errorf("decoding artwork: %w", err)
+	}
+
+	return img, nil
+}
+
+// drawSquare scales `src` into the `rect` square of `dst`. Images which are not
+// square are cropped around their center first.
+func drawSquare(dst draw.Image, rect image.Rectangle, src image.Image) {
+	srcRect := src.Bounds()
+	if srcRect.Dx() > srcRect.Dy() {
+		offset := (srcRect.Dx() - srcRect.Dy()) / 2
+		srcRect.Min.X += offset
+		srcRect.Max.X = srcRect.Min.X + srcRect.Dy()
+	} else if srcRect.Dy() > srcRect.Dx() {
+		offset := (srcRect.Dy() - srcRect.Dx()) / 2
+		srcRect.Min.Y += offset
+		srcRect.Max.Y = srcRect.Min.Y + srcRect.Dx()
+	}
+
+	draw.CatmullRom.Scale(dst, rect, src, srcRect, draw.Src, nil)
+}
@@ -0,0 +1,250 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/scaler/scalerfakes"
+)
+
+// TestLocalLibraryPlaylistImages checks that playlist images are saved, scaled
+// and removed by the local library and only for existing playlists.
+func TestLocalLibraryPlaylistImages(t *testing.T) {
+	var (
+		bigImage   = []byte("big-playlist-image-is-bigger-than-the-small")
+		smallImage = []byte("small-playlist-image")
+		ctx        = context.Background()
+	)
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	defer func() { _ = lib.Truncate() }()
+
+	fakeScaler := &scalerfakes.FakeScaler{
+		ScaleStub: func(_ context.Context, r io.Reader, _ int) ([]byte, error) {
+			if _, err := io.ReadAll(r); err != nil {
+				return nil, err
+			}
+
+			imgb := make([]byte, len(smallImage))
+			copy(imgb, smallImage)
+			return imgb, nil
+		},
+	}
+	lib.SetScaler(fakeScaler)
+
+	playlistID := insertTestPlaylist(t, lib)
+
+	_, err = lib.FindPlaylistImage(ctx, playlistID, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Fatalf("expected artwork not found error but got `%+v`", err)
+	}
+
+	err = lib.SavePlaylistImage(ctx, playlistID, bytes.NewReader(bigImage))
+	if err != nil {
+		t.Fatalf("error saving playlist image: %s", err)
+	}
+	assertPlaylistImage(t, lib, playlistID, OriginalImage, bigImage)
+	assertPlaylistImage(t, lib, playlistID, SmallImage, smallImage)
+	assertPlaylistImage(t, lib, playlistID, SmallImage, smallImage)
+
+	if calls := fakeScaler.ScaleCallCount(); calls != 1 {
+		t.Errorf("expected the image to be scaled once but it was %d times", calls)
+	}
+
+	err = lib.SavePlaylistImage(ctx, playlistID+1, bytes.NewReader(bigImage))
+	if !errors.Is(err, ErrPlaylistNotFound) {
+		t.Errorf("expected playlist not found error but got `%+v`", err)
+	}
+
+	tooBig := bytes.NewReader(make([]byte, 6*1024*1024))
+	err = lib.SavePlaylistImage(ctx, playlistID, tooBig)
+	if !errors.Is(err, ErrArtworkTooBig) {
+		t.Errorf("expected artwork too big error but got `%+v`", err)
+	}
+
+	if err := lib.RemovePlaylistImage(ctx, playlistID); err != nil {
+		t.Fatalf("error removing playlist image: %s", err)
+	}
+
+	_, err = lib.FindPlaylistImage(ctx, playlistID, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Fatalf("expected artwork not found error but got `%+v`", err)
+	}
+}
+
+// TestLocalLibraryAlbumsMosaic checks that mosaics are made out of the artwork
+// of the first distinct albums which have one.
+func TestLocalLibraryAlbumsMosaic(t *testing.T) {
+	ctx := context.Background()
+	lib := getScannedLibrary(ctx, t)
+	defer func() { _ = lib.Truncate() }()
+
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	albums := make(map[string]int64)
+	for _, track := range lib.Search(ctx, SearchArgs{Query: "", Count: 100}) {
+		albums[track.Title] = track.AlbumID
+	}
+	firstAlbum := albums["Payback"]
+	secondAlbum := albums["Tittled Track"]
+	missingAlbum := firstAlbum + secondAlbum + 100
+
+	saveColourArtwork(t, lib, firstAlbum, red)
+	saveColourArtwork(t, lib, secondAlbum, blue)
+
+	_, err := lib.AlbumsMosaic(ctx, []int64{missingAlbum}, OriginalImage)
+	if !errors.Is(err, ErrArtworkNotFound) {
+		t.Errorf("expected artwork not found error but got `%+v`", err)
+	}
+
+	albumIDs := []int64{firstAlbum, firstAlbum, missingAlbum, secondAlbum}
+	mosaic := decodeMosaic(t, lib, albumIDs, OriginalImage)
+	if width := mosaic.Bounds().Dx(); width != mosaicWidth {
+		t.Errorf("expected mosaic width %d but got %d", mosaicWidth, width)
+	}
+
+	quarter := mosaicWidth / 4
+	expectedTiles := []struct {
+		x, y   int
+		colour color.RGBA
+	}{
+		{x: quarter, y: quarter, colour: red},
+		{x: 3 * quarter, y: quarter, colour: blue},
+		{x: quarter, y: 3 * quarter, colour: blue},
+		{x: 3 * quarter, y: 3 * quarter, colour: red},
+	}
+	for ind, tile := range expectedTiles {
+		assertColour(t, tile.colour, mosaic.At(tile.x, tile.y), "tile %d", ind)
+	}
+
+	small := decodeMosaic(t, lib, []int64{secondAlbum}, SmallImage)
+	if width := small.Bounds().Dx(); width != thumbnailWidth {
+		t.Errorf("expected small mosaic width %d but got %d", thumbnailWidth, width)
+	}
+	assertColour(t, blue, small.At(5, thumbnailWidth-5), "single artwork mosaic")
+}
+
+func insertTestPlaylist(t *testing.T, lib *LocalLibrary) int64 {
+	var playlistID int64
+	work := func(db *sql.DB) error {
+		res, err := db.Exec(`
+			INSERT INTO playlists (name, created_at, updated_at)
+			VALUES ('Test Playlist', @now, @now)
+		`, sql.Named("now", time.Now().Unix()))
+		if err != nil {
+			return err
+		}
+
+		playlistID, err = res.LastInsertId()
+		return err
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		t.Fatalf("error inserting playlist: %s", err)
+	}
+
+	return playlistID
+}
+
+func saveColourArtwork(t *testing.T, lib *LocalLibrary, albumID int64, c color.Color) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := range 40 {
+		for y := range 30 {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		t.Fatalf("error encoding artwork: %s", err)
+	}
+
+	err := lib.SaveAlbumArtwork(context.Background(), albumID, &buff)
+	if err != nil {
+		t.Fatalf("error saving album %d artwork: %s", albumID, err)
+	}
+}
+
+func decodeMosaic(
+	t *testing.T,
+	lib *LocalLibrary,
+	albumIDs []int64,
+	size ImageSize,
+) image.Image {
+	r, err := lib.AlbumsMosaic(context.Background(), albumIDs, size)
+	if err != nil {
+		t.Fatalf("error creating mosaic: %s", err)
+	}
+	defer r.Close()
+
+	img, format, err := image.Decode(r)
+	if err != nil {
+		t.Fatalf("error decoding mosaic: %s", err)
+	}
+	if format != "jpeg" {
+		t.Errorf("expected JPEG mosaic but got %s", format)
+	}
+
+	return img
+}
+
+// assertColour checks that `actual` is close to `expected`. Colours are never
+// exact because of the JPEG compression.
+func assertColour(
+	t *testing.T,
+	expected color.RGBA,
+	actual color.Color,
+	msg string,
+	args ...any,
+) {
+	t.Helper()
+
+	r, g, b, _ := actual.RGBA()
+	diff := func(expected uint8, actual uint32) int {
+		return max(int(expected)-int(actual>>8), int(actual>>8)-int(expected))
+	}
+
+	if diff(expected.R, r) > 30 || diff(expected.G, g) > 30 || diff(expected.B, b) > 30 {
+		t.Errorf("wrong colour for "+msg+": expected %v but got %v",
+			append(args, expected, actual)...)
+	}
+}
+
+func assertPlaylistImage(
+	t *testing.T,
+	lib *LocalLibrary,
+	playlistID int64,
+	size ImageSize,
+	expectedImage []byte,
+) {
+	foundImg, err := lib.FindPlaylistImage(context.Background(), playlistID, size)
+	if err != nil {
+		t.Fatalf("error finding playlist image: %s", err)
+	}
+	defer foundImg.Close()
+
+	foundImgBytes, err := io.ReadAll(foundImg)
+	if err != nil {
+		t.Fatalf("error reading playlist image reader: %s", err)
+	}
+
+	if !bytes.Equal(expectedImage, foundImgBytes) {
+		t.Errorf("expected image `%s` but got `%s`", expectedImage, foundImgBytes)
+	}
+}
@@ -0,0 +1,63 @@
+package library
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrScanInProgress is returned when a scan is requested while another one is
+// still running.
+var ErrScanInProgress = errors.New("library scan is already in progress")
+
+//counterfeiter:generate . Scanner
+
+// Scanner is the interface for starting library scans on demand and following
+// their progress.
+type Scanner interface {
+	// ScanStatus returns the progress of the currently running scan. When no
+	// scan is running it describes the last finished one.
+	ScanStatus() ScanStatus
+
+	// StartScan starts a scan in the background and returns immediately. A
+	// normal scan only adds new files to the library. When fullRescan is true
+	// then the meta data of all files already in the library is read again
+	// instead.
+	//
+	// ErrScanInProgress is returned when there is a scan running already.
+	StartScan(fullRescan bool) error
+}
+
+// ScanStatus describes the progress of a library scan.
+type ScanStatus struct {
+	// Running is true while the scan is in progress.
+	Running bool
+
+	// FullRescan is true when this is a rescan of all files already in
+	// the library.
+	FullRescan bool
+
+	// StartedAt is the time at which the scan started. It is zero when there
+	// has not been a scan yet.
+	StartedAt time.Time
+
+	// FinishedAt is the time at which the scan finished. It is zero while the
+	// scan is running.
+	FinishedAt time.Time
+
+	// FilesWalked is the number of files which were checked so far.
+	FilesWalked int64
+
+	// FilesAdded is the number of new media files added to the library.
+	FilesAdded int64
+
+	// FilesUpdated is the number of media files for which the meta data in the
+	// library has been updated.
+	FilesUpdated int64
+
+	// Errors is the number of files which could not be read or stored.
+	Errors int64
+
+	// CurrentPath is the file system path of the file which is being scanned at
+	// the moment. Empty when no scan is running.
+	CurrentPath string
+}
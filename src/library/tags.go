@@ -0,0 +1,559 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTagChanges is returned when the requested tag changes could not
+	// be made. For example when there are no changes at all or when changing
+	// tags which could not be written.
+	ErrInvalidTagChanges = errors.New("invalid tag changes")
+
+	// ErrTagsNotWritable is returned when editing the tags of files which format
+	// does not support writing tags safely.
+	ErrTagsNotWritable = errors.New("tags of this file format could not be written")
+)
+
+// Backups made before editing tags are removed once there are more than
+// maxTagBackups of them or when they become older than maxTagBackupAge.
+const (
+	maxTagBackups   = 1000
+	maxTagBackupAge = 30 * 24 * time.Hour
+)
+
+// tagWritableFormats are the file extensions of the formats for which taglib
+// writes tags safely.
+var tagWritableFormats = []string{
+	".mp3",
+	".flac",
+	".fla",
+	".ogg",
+	".oga",
+	".opus",
+	".m4a",
+}
+
+//counterfeiter:generate . TagEditor
+
+// TagEditor is the interface for editing the meta data tags of media files. The
+// changes are written into the files and then stored in the library.
+type TagEditor interface {
+	// EditTrackTags writes `changes` into the file of the track with ID
+	// `trackID`. The track keeps its ID.
+	//
+	// ErrTrackNotFound is returned when there is no such track.
+	EditTrackTags(ctx context.Context, trackID int64, changes TagChanges) error
+
+	// EditAlbumTags writes `changes` into the files of all tracks in the album
+	// with ID `albumID`. Only album-wide tags could be changed this way. So the
+	// title and the track number are not allowed. The album keeps its ID unless
+	// it is renamed to an album which already exists in the same directory.
+	//
+	// ErrAlbumNotFound is returned when there is no such album.
+	EditAlbumTags(ctx context.Context, albumID int64, changes TagChanges) error
+}
+
+// TagChanges are the new values for the tags of media files. Only tags with
+// non-nil values are changed.
+type TagChanges struct {
+	Title  *string
+	Artist *string
+	Album  *string
+	Genre  *string
+
+	// AlbumArtist and Disc are written with the property API of TagLib which
+	// is available since TagLib 2.0. With older versions changing them results
+	// in ErrTagsNotWritable.
+	AlbumArtist *string
+
+	// Disc is the disc number. 0 removes it.
+	Disc *int
+
+	// Track is the track number. 0 removes it.
+	Track *int
+
+	// Year is the four-digit release year. 0 removes it.
+	Year *int
+}
+
+// TagWriter writes the tag changes into the media file at `path`.
+type TagWriter func(path string, changes TagChanges) error
+
+// SetTagBackupsDir sets the directory into which media files are copied before
+// their tags are edited. Tags could not be edited until it is set.
+func (lib *LocalLibrary) SetTagBackupsDir(dir string) {
+	lib.tagBackupsDir = dir
+}
+
+// EditTrackTags implements the TagEditor interface.
+func (lib *LocalLibrary) EditTrackTags(
+	ctx context.Context,
+	trackID int64,
+	changes TagChanges,
+) error {
+	if err := changes.validate(); err != nil {
+		return err
+	}
+
+	tracks, err := lib.tracksForEditing(ctx, "id = ?", trackID)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		return ErrTrackNotFound
+	}
+
+	return lib.editTags(ctx, tracks, changes, 0)
+}
+
+// EditAlbumTags implements the TagEditor interface.
+func (lib *LocalLibrary) EditAlbumTags(
+	ctx context.Context,
+	albumID int64,
+	changes TagChanges,
+) error {
+	if changes.Title != nil || changes.Track != nil {
+		return fmt.Errorf(
+			"%w: title and track number are not album-wide tags",
+			ErrInvalidTagChanges,
+		)
+	}
+	if err := changes.validate(); err != nil {
+		return err
+	}
+
+	tracks, err := lib.tracksForEditing(ctx, "album_id = ?", albumID)
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		return ErrAlbumNotFound
+	}
+
+	return lib.editTags(ctx, tracks, changes, albumID)
+}
+
+// editTags writes `changes` into the files of `tracks` and then stores them in
+// the database. Every file is backed up before writing into it. When writing any
+// of the files fails all of them are restored from their backups. `albumID` is
+// the album which is edited as a whole. It is zero when editing a single track.
+func (lib *LocalLibrary) editTags(
+	ctx context.Context,
+	tracks []track,
+	changes TagChanges,
+	albumID int64,
+) error {
+	for _, tr := range tracks {
+		if !isTagWritable(tr.fsPath) {
+			return fmt.Errorf("%w: %s", ErrTagsNotWritable, tr.fsPath)
+		}
+	}
+
+	if (changes.AlbumArtist != nil || changes.Disc != nil) && !lib.tagPropertiesWritable {
+		return fmt.Errorf(
+			"%w: album artist and disc number need TagLib 2.0 or newer",
+			ErrTagsNotWritable,
+		)
+	}
+
+	if lib.tagBackupsDir == "" {
+		return errors.New("no directory for backups before editing tags")
+	}
+
+	backups := make([]string, 0, len(tracks))
+	for _, tr := range tracks {
+		backup, err := lib.backupFile(tr)
+		if err != nil {
+			return fmt.Errorf("backing up %s: %w", tr.fsPath, err)
+		}
+		backups = append(backups, backup)
+	}
+
+	for i, tr := range tracks {
+		if err := ctx.Err(); err != nil {
+			lib.restoreBackups(tracks[:i], backups)
+			return err
+		}
+
+		if err := lib.writeTags(tr.fsPath, changes); err != nil {
+			lib.restoreBackups(tracks[:i+1], backups)
+			return fmt.Errorf("writing tags into %s: %w", tr.fsPath, err)
+		}
+	}
+
+	if err := lib.storeTagChanges(tracks, changes, albumID); err != nil {
+		// Some of the changes may have been stored already. Reading the restored
+		// files again brings the database back in line with them.
+		lib.restoreBackups(tracks, backups)
+		for _, tr := range tracks {
+			if rescanErr := lib.rescanFile(tr.fsPath); rescanErr != nil {
+				log.Printf("Error reading restored %s: %s\n", tr.fsPath, rescanErr)
+			}
+		}
+		return fmt.Errorf("storing tag changes: %w", err)
+	}
+
+	lib.pruneTagBackups()
+	return nil
+}
+
+// tracksForEditing returns the tracks which match the SQL `where` condition.
+func (lib *LocalLibrary) tracksForEditing(
+	ctx context.Context,
+	where string,
+	args ...any,
+) ([]track, error) {
+	var tracks []track
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT
+				id,
+				fs_path
+			FROM
+				tracks
+			WHERE
+				`+where+`
+			ORDER BY
+				id
+		`, args...)
+		if err != nil {
+			return fmt.Errorf("querying tracks: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var tr track
+			if err := rows.Scan(&tr.id, &tr.fsPath); err != nil {
+				return fmt.Errorf("scanning track: %w", err)
+			}
+			tracks = append(tracks, tr)
+		}
+
+		return rows.Err()
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// backupFile copies the file of `tr` into the backups directory and returns the
+// path to the copy.
+func (lib *LocalLibrary) backupFile(tr track) (string, error) {
+	if err := os.MkdirAll(lib.tagBackupsDir, 0700); err != nil {
+		return "", fmt.Errorf("creating backups directory: %w", err)
+	}
+
+	backup := filepath.Join(lib.tagBackupsDir, fmt.Sprintf(
+		"%d-%d-%s",
+		tr.id,
+		time.Now().UnixNano(),
+		filepath.Base(tr.fsPath),
+	))
+	if err := copyFile(tr.fsPath, backup); err != nil {
+		return "", err
+	}
+
+	return backup, nil
+}
+
+// pruneTagBackups removes the oldest backups when there are more than
+// maxTagBackups of them and all backups older than maxTagBackupAge.
+func (lib *LocalLibrary) pruneTagBackups() {
+	entries, err := os.ReadDir(lib.tagBackupsDir)
+	if err != nil {
+		log.Printf("Error listing tag backups: %s\n", err)
+		return
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+
+	backups := make([]backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{
+			path:    filepath.Join(lib.tagBackupsDir, entry.Name()),
+			modTime: info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(backups, func(a, b backup) int {
+		return b.modTime.Compare(a.modTime)
+	})
+
+	oldest := time.Now().Add(-maxTagBackupAge)
+	for i, b := range backups {
+		if i < maxTagBackups && b.modTime.After(oldest) {
+			continue
+		}
+		if err := os.Remove(b.path); err != nil {
+			log.Printf("Error removing tag backup %s: %s\n", b.path, err)
+		}
+	}
+}
+
+// restoreBackups copies the `backups` back over the files of `tracks`.
+func (lib *LocalLibrary) restoreBackups(tracks []track, backups []string) {
+	for i, tr := range tracks {
+		if err := copyFile(backups[i], tr.fsPath); err != nil {
+			log.Printf("Error restoring %s from backup %s: %s\n",
+				tr.fsPath, backups[i], err)
+		}
+	}
+}
+
+// storeTagChanges updates the database rows of `tracks` in place with the tag
+// `changes` which were already written into their files.
+func (lib *LocalLibrary) storeTagChanges(
+	tracks []track,
+	changes TagChanges,
+	albumID int64,
+) error {
+	artistID := sql.NullInt64{}
+	if changes.Artist != nil {
+		id, err := lib.setArtistID(strings.TrimSpace(*changes.Artist))
+		if err != nil {
+			return fmt.Errorf("getting artist: %w", err)
+		}
+		artistID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	renamed := false
+	if changes.Album != nil && albumID != 0 {
+		var err error
+		renamed, err = lib.renameAlbum(albumID, strings.TrimSpace(*changes.Album))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tr := range tracks {
+		trackAlbumID := sql.NullInt64{}
+		if changes.Album != nil && !renamed {
+			id, err := lib.setAlbumID(
+				strings.TrimSpace(*changes.Album),
+				filepath.Dir(tr.fsPath),
+			)
+			if err != nil {
+				return fmt.Errorf("getting album: %w", err)
+			}
+			trackAlbumID = sql.NullInt64{Int64: id, Valid: true}
+		}
+
+		title := sql.NullString{}
+		if changes.Title != nil {
+			title = sql.NullString{String: strings.TrimSpace(*changes.Title), Valid: true}
+			if title.String == "" {
+				title.String = filepath.Base(tr.fsPath)
+			}
+		}
+
+		genre := sql.NullString{}
+		if changes.Genre != nil {
+			genre = sql.NullString{String: strings.TrimSpace(*changes.Genre), Valid: true}
+		}
+
+		number := sql.NullInt64{}
+		if changes.Track != nil {
+			number = sql.NullInt64{Int64: int64(*changes.Track), Valid: true}
+		}
+
+		var size sql.NullInt64
+		if st, err := os.Stat(tr.fsPath); err == nil {
+			size = sql.NullInt64{Int64: st.Size(), Valid: true}
+		}
+
+		work := func(db *sql.DB) error {
+			_, err := db.Exec(`
+				UPDATE tracks
+				SET
+					name = COALESCE(@title, name),
+					artist_id = COALESCE(@artist_id, artist_id),
+					album_id = COALESCE(@album_id, album_id),
+					number = COALESCE(@number, number),
+					genre = CASE WHEN @set_genre THEN NULLIF(@genre, '') ELSE genre END,
+					year = CASE WHEN @set_year THEN NULLIF(@year, 0) ELSE year END,
+					disc_number = CASE WHEN @set_disc THEN NULLIF(@disc, 0) ELSE disc_number END,
+					size = COALESCE(@size, size)
+				WHERE
+					id = @id
+			`,
+				sql.Named("title", title),
+				sql.Named("artist_id", artistID),
+				sql.Named("album_id", trackAlbumID),
+				sql.Named("number", number),
+				sql.Named("set_genre", genre.Valid),
+				sql.Named("genre", genre.String),
+				sql.Named("set_year", changes.Year != nil),
+				sql.Named("year", intOrZero(changes.Year)),
+				sql.Named("set_disc", changes.Disc != nil),
+				sql.Named("disc", intOrZero(changes.Disc)),
+				sql.Named("size", size),
+				sql.Named("id", tr.id),
+			)
+			if err != nil {
+				return fmt.Errorf("updating track %d: %w", tr.id, err)
+			}
+
+			// Issues with missing tags are fixed when the tags are set.
+			var fixed []any
+			if changes.Artist != nil && strings.TrimSpace(*changes.Artist) != "" {
+				fixed = append(fixed, IssueUnknownArtist)
+			}
+			if changes.Album != nil && strings.TrimSpace(*changes.Album) != "" {
+				fixed = append(fixed, IssueUnknownAlbum)
+			}
+			if len(fixed) > 0 {
+				_, err = db.Exec(`
+					DELETE FROM library_issues
+					WHERE
+						track_id = ? AND
+						kind IN (`+strings.TrimSuffix(strings.Repeat("?,", len(fixed)), ",")+`)
+				`, append([]any{tr.id}, fixed...)...)
+				if err != nil {
+					return fmt.Errorf("removing fixed issues: %w", err)
+				}
+			}
+
+			return lib.markModified(db)
+		}
+		if err := lib.ExecuteDBJobAndWait(work); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renameAlbum changes the name of the album with ID `albumID` to `name`. It
+// returns false when there is another album with this name in the same
+// directory. Then the album could not be renamed in place.
+func (lib *LocalLibrary) renameAlbum(albumID int64, name string) (bool, error) {
+	if name == "" {
+		name = UnknownLabel
+	}
+
+	var renamed bool
+	work := func(db *sql.DB) error {
+		res, err := db.Exec(`
+			UPDATE albums
+			SET
+				name = @name
+			WHERE
+				id = @id AND
+				NOT EXISTS (
+					SELECT 1
+					FROM albums as other
+					WHERE
+						other.name = @name AND
+						other.fs_path = albums.fs_path AND
+						other.id != albums.id
+				)
+		`,
+			sql.Named("name", name),
+			sql.Named("id", albumID),
+		)
+		if err != nil {
+			return fmt.Errorf("renaming album %d: %w", albumID, err)
+		}
+
+		affected, _ := res.RowsAffected()
+		renamed = affected > 0
+		return lib.markModified(db)
+	}
+	if err := lib.ExecuteDBJobAndWait(work); err != nil {
+		return false, err
+	}
+
+	return renamed, nil
+}
+
+// validate returns an error when the changes could not be written.
+func (c TagChanges) validate() error {
+	if c.Title == nil && c.Artist == nil && c.Album == nil && c.Genre == nil &&
+		c.AlbumArtist == nil && c.Disc == nil && c.Track == nil && c.Year == nil {
+		return fmt.Errorf("%w: no tags to change", ErrInvalidTagChanges)
+	}
+
+	if c.Track != nil && *c.Track < 0 {
+		return fmt.Errorf("%w: negative track number", ErrInvalidTagChanges)
+	}
+
+	if c.Disc != nil && *c.Disc < 0 {
+		return fmt.Errorf("%w: negative disc number", ErrInvalidTagChanges)
+	}
+
+	if c.Year != nil && (*c.Year < 0 || *c.Year > 9999) {
+		return fmt.Errorf("%w: year must be a four-digit number", ErrInvalidTagChanges)
+	}
+
+	return nil
+}
+
+// isTagWritable returns whether tags could be written safely into the file at
+// `path` judging by its format.
+func isTagWritable(path string) bool {
+	ext := filepath.Ext(path)
+	for _, format := range tagWritableFormats {
+		if strings.EqualFold(ext, format) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyFile copies the file at `src` over the file at `dst`, keeping the file
+// mode of `src`.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	st, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, st.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
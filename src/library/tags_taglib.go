@@ -0,0 +1,201 @@
+package library
+
+// #cgo pkg-config: taglib
+// #cgo LDFLAGS: -ltag_c
+// #include <stdlib.h>
+// #include <tag_c.h>
+//
+// // The property API of the TagLib C bindings is available since TagLib 2.0. It
+// // is declared weak so that Euterpe could be built and run with older versions
+// // too. Then the functions are NULL and the properties could not be read or
+// // written.
+// #pragma weak taglib_property_set
+// void taglib_property_set(TagLib_File *file, const char *prop, const char *value);
+// #pragma weak taglib_property_get
+// char **taglib_property_get(const TagLib_File *file, const char *prop);
+// #pragma weak taglib_property_free
+// void taglib_property_free(char **props);
+//
+// static int euterpe_taglib_has_properties() {
+//     return taglib_property_set != NULL &&
+//         taglib_property_get != NULL &&
+//         taglib_property_free != NULL;
+// }
+//
+// static void euterpe_taglib_property_set(
+//     TagLib_File *file,
+//     const char *prop,
+//     const char *value
+// ) {
+//     taglib_property_set(file, prop, value);
+// }
+//
+// static char **euterpe_taglib_property_get(TagLib_File *file, const char *prop) {
+//     return taglib_property_get(file, prop);
+// }
+//
+// static void euterpe_taglib_property_free(char **props) {
+//     taglib_property_free(props);
+// }
+import "C"
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// taglibLock serializes the calls to the TagLib C bindings made while reading
+// and writing tags by their property names.
+var taglibLock sync.Mutex
+
+// taglibHasProperties returns whether the linked TagLib supports reading and
+// writing tags by property name. Without it the album artist and the disc number
+// could not be written and MediaProperties could not be read.
+func taglibHasProperties() bool {
+	return C.euterpe_taglib_has_properties() != 0
+}
+
+// writeTagsWithTaglib is a TagWriter which uses the TagLib C bindings. All
+// changes are saved into the file at once.
+func writeTagsWithTaglib(path string, changes TagChanges) error {
+	if (changes.AlbumArtist != nil || changes.Disc != nil) && !taglibHasProperties() {
+		return errors.New("writing album artist and disc number needs TagLib 2.0 or newer")
+	}
+
+	taglibLock.Lock()
+	defer taglibLock.Unlock()
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	file := C.taglib_file_new(cPath)
+	if file == nil {
+		return errors.New("cannot open file with taglib")
+	}
+	defer C.taglib_file_free(file)
+
+	if C.taglib_file_is_valid(file) == 0 {
+		return errors.New("invalid file")
+	}
+
+	tag := C.taglib_file_tag(file)
+	if tag == nil {
+		return errors.New("file has no tags")
+	}
+
+	setString := func(value *string, set func(*C.TagLib_Tag, *C.char)) {
+		if value == nil {
+			return
+		}
+		cValue := C.CString(strings.TrimSpace(*value))
+		defer C.free(unsafe.Pointer(cValue))
+		set(tag, cValue)
+	}
+
+	setString(changes.Title, func(t *C.TagLib_Tag, v *C.char) { C.taglib_tag_set_title(t, v) })
+	setString(changes.Artist, func(t *C.TagLib_Tag, v *C.char) { C.taglib_tag_set_artist(t, v) })
+	setString(changes.Album, func(t *C.TagLib_Tag, v *C.char) { C.taglib_tag_set_album(t, v) })
+	setString(changes.Genre, func(t *C.TagLib_Tag, v *C.char) { C.taglib_tag_set_genre(t, v) })
+
+	if changes.Track != nil {
+		C.taglib_tag_set_track(tag, C.uint(*changes.Track))
+	}
+	if changes.Year != nil {
+		C.taglib_tag_set_year(tag, C.uint(*changes.Year))
+	}
+
+	if changes.AlbumArtist != nil {
+		setProperty(file, "ALBUMARTIST", strings.TrimSpace(*changes.AlbumArtist))
+	}
+	if changes.Disc != nil {
+		disc := ""
+		if *changes.Disc > 0 {
+			disc = strconv.Itoa(*changes.Disc)
+		}
+		setProperty(file, "DISCNUMBER", disc)
+	}
+
+	if C.taglib_file_save(file) == 0 {
+		return errors.New("cannot save file")
+	}
+
+	return nil
+}
+
+// setProperty sets the tag property `name` of `file` to `value`. An empty value
+// removes the property.
+func setProperty(file *C.TagLib_File, name, value string) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	if value == "" {
+		C.euterpe_taglib_property_set(file, cName, nil)
+		return
+	}
+
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+	C.euterpe_taglib_property_set(file, cName, cValue)
+}
+
+// readTaglibProperties reads the MediaProperties of the file at `path` with the
+// TagLib C bindings. They are empty when the linked TagLib does not support
+// properties.
+func readTaglibProperties(path string) (MediaProperties, error) {
+	if !taglibHasProperties() {
+		return MediaProperties{}, nil
+	}
+
+	taglibLock.Lock()
+	defer taglibLock.Unlock()
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	file := C.taglib_file_new(cPath)
+	if file == nil {
+		return MediaProperties{}, errors.New("cannot open file with taglib")
+	}
+	defer C.taglib_file_free(file)
+
+	if C.taglib_file_is_valid(file) == 0 {
+		return MediaProperties{}, errors.New("invalid file")
+	}
+
+	properties := make(map[string][]string, len(mediaPropertyNames))
+	for _, name := range mediaPropertyNames {
+		properties[name] = getProperty(file, name)
+	}
+
+	return mediaPropertiesFromMap(properties), nil
+}
+
+// getProperty returns all values of the tag property `name` of `file`.
+func getProperty(file *C.TagLib_File, name string) []string {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	cValues := C.euterpe_taglib_property_get(file, cName)
+	if cValues == nil {
+		return nil
+	}
+	defer C.euterpe_taglib_property_free(cValues)
+
+	// The values are a NULL terminated array of strings.
+	var values []string
+	for ind := 0; ; ind++ {
+		cValue := *(**C.char)(unsafe.Add(
+			unsafe.Pointer(cValues),
+			uintptr(ind)*unsafe.Sizeof(*cValues),
+		))
+		if cValue == nil {
+			break
+		}
+		values = append(values, C.GoString(cValue))
+	}
+
+	return values
+}
@@ -0,0 +1,354 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+)
+
+// TestEditingTags checks that tag changes are written into backed up files and
+// then stored in the library without changing the IDs of tracks and albums.
+func TestEditingTags(t *testing.T) {
+	ctx := context.Background()
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	testLibraryPath, err := getTestLibraryPath()
+	assert.NilErr(t, err, "getting test library path")
+
+	musicDir := t.TempDir()
+	backupsDir := filepath.Join(t.TempDir(), "backups")
+
+	var trackIDs []int64
+	for _, name := range []string{"test_file_one.mp3", "test_file_two.mp3"} {
+		filePath := filepath.Join(musicDir, name)
+		err := copyFile(filepath.Join(testLibraryPath, name), filePath)
+		assert.NilErr(t, err, "copying test file")
+		assert.NilErr(t, lib.AddMedia(filePath), "adding test file")
+
+		track := findTrackByPath(ctx, t, lib, filePath)
+		trackIDs = append(trackIDs, track.ID)
+	}
+	firstPath := filepath.Join(musicDir, "test_file_one.mp3")
+	original, err := os.ReadFile(firstPath)
+	assert.NilErr(t, err, "reading test file")
+
+	var (
+		written        []string
+		writtenChanges []TagChanges
+	)
+	lib.writeTags = func(path string, changes TagChanges) error {
+		written = append(written, path)
+		writtenChanges = append(writtenChanges, changes)
+		return nil
+	}
+	lib.tagPropertiesWritable = true
+
+	title := "Fixed Title"
+	err = lib.EditTrackTags(ctx, trackIDs[0], TagChanges{Title: &title})
+	if err == nil {
+		t.Errorf("expected error when there is no backups directory")
+	}
+	assert.Equal(t, 0, len(written), "files written without backups directory")
+
+	lib.SetTagBackupsDir(backupsDir)
+
+	var (
+		artist = "Fixed Artist"
+		genre  = "Jazz"
+		number = 5
+		year   = 2001
+	)
+	err = lib.EditTrackTags(ctx, trackIDs[0], TagChanges{
+		Title:  &title,
+		Artist: &artist,
+		Genre:  &genre,
+		Track:  &number,
+		Year:   &year,
+	})
+	assert.NilErr(t, err, "editing track tags")
+	assert.Equal(t, 1, len(written), "written files")
+	if len(written) == 1 {
+		assert.Equal(t, firstPath, written[0], "written file")
+	}
+
+	edited, err := lib.GetTrack(ctx, trackIDs[0])
+	assert.NilErr(t, err, "getting edited track")
+	assert.Equal(t, title, edited.Title, "edited title")
+	assert.Equal(t, artist, edited.Artist, "edited artist")
+	assert.Equal(t, genre, edited.Genre, "edited genre")
+	assert.Equal(t, int64(number), edited.TrackNumber, "edited track number")
+	assert.Equal(t, int32(year), edited.Year, "edited year")
+
+	backups, err := os.ReadDir(backupsDir)
+	assert.NilErr(t, err, "reading backups directory")
+	assert.Equal(t, 1, len(backups), "number of backups")
+	if len(backups) == 1 {
+		backup, err := os.ReadFile(filepath.Join(backupsDir, backups[0].Name()))
+		assert.NilErr(t, err, "reading backup")
+		if !bytes.Equal(original, backup) {
+			t.Errorf("backup differs from the original file")
+		}
+	}
+
+	// Rename the whole album.
+	albumID := edited.AlbumID
+	album := "Fixed Album"
+	written = nil
+	err = lib.EditAlbumTags(ctx, albumID, TagChanges{Album: &album})
+	assert.NilErr(t, err, "editing album tags")
+	assert.Equal(t, 2, len(written), "written files for album")
+
+	renamed, err := lib.GetAlbum(ctx, albumID)
+	assert.NilErr(t, err, "getting renamed album")
+	assert.Equal(t, album, renamed.Name, "album name")
+	for _, trackID := range trackIDs {
+		track, err := lib.GetTrack(ctx, trackID)
+		assert.NilErr(t, err, "getting album track")
+		assert.Equal(t, albumID, track.AlbumID, "album of track")
+	}
+
+	// Album artist and disc number are written into the files.
+	var (
+		albumArtist = "Various Artists"
+		disc        = 2
+	)
+	written, writtenChanges = nil, nil
+	err = lib.EditAlbumTags(ctx, albumID, TagChanges{AlbumArtist: &albumArtist, Disc: &disc})
+	assert.NilErr(t, err, "editing album artist and disc")
+	assert.Equal(t, 2, len(writtenChanges), "written files for album artist")
+	for _, changes := range writtenChanges {
+		if changes.AlbumArtist == nil || *changes.AlbumArtist != albumArtist {
+			t.Errorf("expected album artist to be written")
+		}
+		if changes.Disc == nil || *changes.Disc != disc {
+			t.Errorf("expected disc number to be written")
+		}
+	}
+
+	// Old backups are removed after editing.
+	oldBackup := filepath.Join(backupsDir, "old-backup.mp3")
+	assert.NilErr(t, os.WriteFile(oldBackup, original, 0600), "writing old backup")
+	oldTime := time.Now().Add(-maxTagBackupAge - time.Hour)
+	assert.NilErr(t, os.Chtimes(oldBackup, oldTime, oldTime), "aging old backup")
+
+	err = lib.EditTrackTags(ctx, trackIDs[1], TagChanges{Year: &year})
+	assert.NilErr(t, err, "editing tags with old backup")
+	if _, err := os.Stat(oldBackup); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected old backup to be removed but got %v", err)
+	}
+
+	// Failing to store the changes in the database restores the files.
+	err = lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.Exec(`
+			CREATE TRIGGER fail_track_updates BEFORE UPDATE ON tracks
+			BEGIN
+				SELECT RAISE(ABORT, 'updates are broken');
+			END
+		`)
+		return err
+	})
+	assert.NilErr(t, err, "creating failing trigger")
+
+	lib.writeTags = func(path string, changes TagChanges) error {
+		return os.WriteFile(path, []byte("changed"), 0600)
+	}
+	err = lib.EditTrackTags(ctx, trackIDs[0], TagChanges{Title: &title})
+	if err == nil {
+		t.Errorf("expected error when storing tag changes fails")
+	}
+	restored, err := os.ReadFile(firstPath)
+	assert.NilErr(t, err, "reading file restored after failed store")
+	if !bytes.Equal(original, restored) {
+		t.Errorf("file was not restored after failing to store the changes")
+	}
+
+	err = lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.Exec(`DROP TRIGGER fail_track_updates`)
+		return err
+	})
+	assert.NilErr(t, err, "dropping failing trigger")
+
+	// A failed write restores all files from their backups.
+	lib.writeTags = func(path string, changes TagChanges) error {
+		if err := os.WriteFile(path, []byte("broken"), 0600); err != nil {
+			return err
+		}
+		return errors.New("writing failed")
+	}
+	err = lib.EditAlbumTags(ctx, albumID, TagChanges{Year: &year})
+	if err == nil {
+		t.Errorf("expected error when writing tags fails")
+	}
+	restored, err = os.ReadFile(firstPath)
+	assert.NilErr(t, err, "reading restored file")
+	if !bytes.Equal(original, restored) {
+		t.Errorf("file was not restored after failed write")
+	}
+}
+
+// TestEditingTagsErrors checks that tag changes which could not be made are
+// rejected before writing into any files.
+func TestEditingTagsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	lib, err := NewLocalLibrary(ctx, SQLiteMemoryFile, getTestMigrationFiles())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := lib.Initialize(); err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+	defer func() { _ = lib.Truncate() }()
+
+	lib.SetTagBackupsDir(t.TempDir())
+	lib.writeTags = func(path string, changes TagChanges) error {
+		t.Errorf("unexpected writing into %s", path)
+		return nil
+	}
+
+	const wavPath = "/music/album/song.wav"
+	err = lib.insertMediaIntoDatabase(&MockMedia{
+		artist: "Artist",
+		album:  "Album",
+		title:  "Song",
+		length: time.Minute,
+	}, fileInfo{FilePath: wavPath, Modified: time.Now()})
+	assert.NilErr(t, err, "inserting wav file")
+
+	wavTrack := findTrackByPath(ctx, t, lib, wavPath)
+
+	const mp3Path = "/music/album/song.mp3"
+	err = lib.insertMediaIntoDatabase(&MockMedia{
+		artist: "Artist",
+		album:  "Album",
+		title:  "Other Song",
+		length: time.Minute,
+	}, fileInfo{FilePath: mp3Path, Modified: time.Now()})
+	assert.NilErr(t, err, "inserting mp3 file")
+
+	mp3Track := findTrackByPath(ctx, t, lib, mp3Path)
+	lib.tagPropertiesWritable = false
+
+	var (
+		title       = "Title"
+		albumArtist = "Album Artist"
+		disc        = 2
+		negative    = -1
+	)
+	tests := []struct {
+		desc     string
+		album    bool
+		id       int64
+		changes  TagChanges
+		expected error
+	}{
+		{
+			desc:     "no changes",
+			id:       wavTrack.ID,
+			expected: ErrInvalidTagChanges,
+		},
+		{
+			desc:     "negative disc number",
+			id:       wavTrack.ID,
+			changes:  TagChanges{Disc: &negative},
+			expected: ErrInvalidTagChanges,
+		},
+		{
+			desc:     "album artist without TagLib properties",
+			id:       mp3Track.ID,
+			changes:  TagChanges{AlbumArtist: &albumArtist},
+			expected: ErrTagsNotWritable,
+		},
+		{
+			desc:     "album disc number without TagLib properties",
+			album:    true,
+			id:       mp3Track.AlbumID,
+			changes:  TagChanges{Disc: &disc},
+			expected: ErrTagsNotWritable,
+		},
+		{
+			desc:     "negative year",
+			id:       wavTrack.ID,
+			changes:  TagChanges{Year: &negative},
+			expected: ErrInvalidTagChanges,
+		},
+		{
+			desc:     "album title",
+			album:    true,
+			id:       wavTrack.AlbumID,
+			changes:  TagChanges{Title: &title},
+			expected: ErrInvalidTagChanges,
+		},
+		{
+			desc:     "missing track",
+			id:       wavTrack.ID + 100,
+			changes:  TagChanges{Title: &title},
+			expected: ErrTrackNotFound,
+		},
+		{
+			desc:     "missing album",
+			album:    true,
+			id:       wavTrack.AlbumID + 100,
+			changes:  TagChanges{Year: &disc},
+			expected: ErrAlbumNotFound,
+		},
+		{
+			desc:     "not writable format",
+			id:       wavTrack.ID,
+			changes:  TagChanges{Title: &title},
+			expected: ErrTagsNotWritable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var err error
+			if test.album {
+				err = lib.EditAlbumTags(ctx, test.id, test.changes)
+			} else {
+				err = lib.EditTrackTags(ctx, test.id, test.changes)
+			}
+
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected error `%s` but got `%v`", test.expected, err)
+			}
+		})
+	}
+}
+
+func findTrackByPath(
+	ctx context.Context,
+	t *testing.T,
+	lib *LocalLibrary,
+	path string,
+) TrackInfo {
+	t.Helper()
+
+	tracks, err := lib.tracksForEditing(ctx, "fs_path = ?", path)
+	assert.NilErr(t, err, "finding track by path")
+	if len(tracks) != 1 {
+		t.Fatalf("expected one track for %s but found %d", path, len(tracks))
+	}
+
+	track, err := lib.GetTrack(ctx, tracks[0].id)
+	assert.NilErr(t, err, "getting track")
+
+	return track
+}
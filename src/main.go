@@ -6,21 +6,26 @@
 package src
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ironsmile/euterpe/src/art"
 	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/daemon"
+	"github.com/ironsmile/euterpe/src/duplicates"
 	"github.com/ironsmile/euterpe/src/helpers"
 	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
 	"github.com/ironsmile/euterpe/src/scaler"
 	"github.com/ironsmile/euterpe/src/version"
 	"github.com/ironsmile/euterpe/src/webserver"
@@ -54,6 +59,20 @@ var (
 	// doNotWatchDirs is controlled by the -dont-watch flag and will cause
 	// the program to cease watching music library directories for changes.
 	doNotWatchDirs bool
+
+	// setPassword is controlled by the -set-password flag. It will cause the
+	// program to read a password from the standard input, store its hash in the
+	// configuration and then exit.
+	setPassword bool
+
+	// listDuplicates is controlled by the -duplicates flag. It will cause the
+	// program to print all groups of duplicate tracks in the library and then
+	// exit.
+	listDuplicates bool
+
+	// mergeDuplicates is populated by the -merge-duplicates flag. It is the ID
+	// of a track into which all of its duplicates will be merged.
+	mergeDuplicates int64
 )
 
 const userAgentFormat = "Euterpe Media Server/%s (github.com/ironsmile/euterpe)"
@@ -80,6 +99,17 @@ func init() {
 			"Alternatively one could use the -rescan flag.\n\n"+
 			"This option is useful for systems with low open files limit such\n"+
 			"MacOS by default.")
+	flag.BoolVar(&setPassword, "set-password", false,
+		"Reads a password from the standard input and stores its hash in the\n"+
+			"configuration file. Any plain text password in the configuration is\n"+
+			"removed. Then exits.")
+	flag.BoolVar(&listDuplicates, "duplicates", false,
+		"Prints all groups of duplicate tracks in the library. Such are tracks\n"+
+			"with the same audio or with the same artist, title and duration.\n"+
+			"Then exits.")
+	flag.Int64Var(&mergeDuplicates, "merge-duplicates", 0,
+		"Merges the plays, ratings, favourites, bookmarks and playlist entries\n"+
+			"of all duplicates of the track with this ID into it. Then exits.")
 }
 
 // Main is the only thing run in the project's root main.go file.
@@ -101,6 +131,14 @@ func Main(httpRootFS, htmlTemplatesFS, sqlFilesFS fs.FS) {
 		os.Exit(0)
 	}
 
+	if listDuplicates || mergeDuplicates != 0 {
+		if err := runDuplicates(appfs, sqlFilesFS, os.Stdout); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if localFiles {
 		httpRootFS = os.DirFS("http_root")
 		htmlTemplatesFS = os.DirFS("templates")
@@ -115,6 +153,14 @@ func Main(httpRootFS, htmlTemplatesFS, sqlFilesFS fs.FS) {
 		os.Exit(0)
 	}
 
+	if setPassword {
+		if err := runSetPassword(appfs, os.Stdin, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not set password: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if err := runServer(
 		appfs,
 		httpRootFS,
@@ -179,6 +225,10 @@ func getLibrary(
 		lib.AddLibraryPath(path)
 	}
 
+	lib.SetPlaylistFilesSyncer(playlists.NewFilesSyncer(lib.ExecuteDBJobAndWait))
+	lib.SetPlaylistEntriesLinker(playlists.NewEntriesLinker(lib.ExecuteDBJobAndWait))
+	lib.SetTagBackupsDir(filepath.Join(userPath, "tag_backups"))
+
 	if cfg.DownloadArtwork {
 		useragent := fmt.Sprintf(userAgentFormat, version.Version)
 		caf := art.NewClient(useragent, time.Second, cfg.DiscogsAuthToken)
@@ -211,6 +261,15 @@ func runServer(appfs afero.Fs, httpRootFS, htmlTemplatesFS, sqlFilesFS fs.FS) er
 		}
 	}
 
+	if err := cfg.Authenticate.Validate(); err != nil {
+		return fmt.Errorf("invalid authentication configuration: %w", err)
+	}
+
+	if cfg.Authenticate.HasPlainTextPassword() {
+		log.Println("WARNING: the configuration contains a password in plain text. " +
+			"Run with -set-password in order to replace it with a password hash.")
+	}
+
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	defer cancelCtx()
 
@@ -236,6 +295,8 @@ func runServer(appfs afero.Fs, httpRootFS, htmlTemplatesFS, sqlFilesFS fs.FS) er
 		go lib.Scan()
 	}
 
+	cfg.Podcasts.Directory = helpers.AbsolutePath(cfg.Podcasts.Directory, userPath)
+
 	log.Printf("Release %s\n", version.Version)
 	srv := webserver.NewServer(ctx, cfg, lib, httpRootFS, htmlTemplatesFS)
 	srv.Serve()
@@ -271,3 +332,68 @@ func runLibraryRescan(appfs afero.Fs, sqlFilesFS fs.FS) error {
 
 	return lib.Rescan(ctx)
 }
+
+// runDuplicates prints the groups of duplicate tracks in the library to `out`.
+// When the -merge-duplicates flag is used the duplicates of the chosen track are
+// merged into it instead.
+func runDuplicates(appfs afero.Fs, sqlFilesFS fs.FS, out io.Writer) error {
+	ctx, cancelContext := context.WithCancel(context.Background())
+	defer cancelContext()
+
+	cfg, err := config.FindAndParse(appfs)
+	if err != nil {
+		return fmt.Errorf("parsing configuration: %s", err)
+	}
+
+	userPath := filepath.Dir(config.UserConfigPath(appfs))
+	lib, err := getLibrary(ctx, userPath, cfg, sqlFilesFS)
+	if err != nil {
+		return fmt.Errorf("creating library object: %w", err)
+	}
+
+	finder := duplicates.NewFinder(lib.ExecuteDBJobAndWait)
+	groups, err := finder.Find(ctx, duplicates.FindArgs{})
+	if err != nil {
+		return fmt.Errorf("finding duplicates: %w", err)
+	}
+
+	if mergeDuplicates == 0 {
+		return duplicates.WriteReport(out, groups)
+	}
+
+	duplicateIDs := duplicates.GroupedWith(groups, mergeDuplicates)
+	if err := finder.Merge(ctx, mergeDuplicates, duplicateIDs); err != nil {
+		return fmt.Errorf("merging duplicates of track %d: %w", mergeDuplicates, err)
+	}
+
+	fmt.Fprintf(out, "Merged %d duplicates into track %d.\n",
+		len(duplicateIDs), mergeDuplicates)
+	return nil
+}
+
+// runSetPassword reads a single line from `in` and stores it as a password hash in
+// the user's configuration file.
+func runSetPassword(appfs afero.Fs, in io.Reader, out io.Writer) error {
+	fmt.Fprint(out, "New password: ")
+
+	scanner := bufio.NewScanner(in)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		return fmt.Errorf("no password given")
+	}
+	fmt.Fprintln(out)
+
+	password := strings.TrimRight(scanner.Text(), "\r")
+	if password == "" {
+		return fmt.Errorf("the password must not be empty")
+	}
+
+	if err := config.SetPassword(appfs, password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Password hash saved in %s\n", config.UserConfigPath(appfs))
+	return nil
+}
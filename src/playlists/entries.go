@@ -0,0 +1,256 @@
+package playlists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// relinkDurationTolerance is the maximum difference between the duration which
+// a playlist entry remembers and the duration of a track with the same metadata
+// for the track to be linked to the entry. Durations differ slightly between
+// encodings of the same recording.
+const relinkDurationTolerance = 2 * time.Second
+
+// entriesLinker implements library.PlaylistEntriesLinker by using the file path
+// and metadata which every playlist entry remembers about its track.
+type entriesLinker struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewEntriesLinker returns a library.PlaylistEntriesLinker which will send SQL
+// queries to `sendDBWork`.
+func NewEntriesLinker(
+	sendDBWork func(library.DatabaseExecutable) error,
+) library.PlaylistEntriesLinker {
+	return &entriesLinker{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// RelinkPlaylistEntries implements library.PlaylistEntriesLinker. Entries are
+// linked to the track with the same file path or failing that to a track with
+// the same title, artist and album and a similar duration.
+func (l *entriesLinker) RelinkPlaylistEntries(ctx context.Context) error {
+	const missingEntriesQuery = `
+		SELECT
+			playlist_id,
+			"index",
+			fs_path,
+			artist,
+			album,
+			title,
+			duration
+		FROM
+			playlists_tracks
+		WHERE
+			track_id IS NULL
+	`
+
+	const relinkQuery = `
+		UPDATE playlists_tracks
+		SET
+			track_id = @track_id
+		WHERE
+			playlist_id = @playlist_id AND
+			"index" = @index
+	`
+
+	type missingEntry struct {
+		playlistID int64
+		index      int64
+		entry      FileEntry
+	}
+
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, missingEntriesQuery)
+		if err != nil {
+			return fmt.Errorf("querying missing playlist entries: %w", err)
+		}
+
+		var missing []missingEntry
+		for rows.Next() {
+			var me missingEntry
+			entry, err := scanEntryReference(rows, &me.playlistID, &me.index)
+			if err != nil {
+				_ = rows.Close()
+				return err
+			}
+
+			me.entry = entry
+			missing = append(missing, me)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterating over missing playlist entries: %w", err)
+		}
+
+		for _, me := range missing {
+			trackID, found, err := findMissingEntryTrack(ctx, db, me.entry)
+			if err != nil {
+				return fmt.Errorf("matching playlist %d entry %d: %w",
+					me.playlistID, me.index, err)
+			}
+
+			if !found {
+				continue
+			}
+
+			_, err = db.ExecContext(ctx, relinkQuery,
+				sql.Named("track_id", trackID),
+				sql.Named("playlist_id", me.playlistID),
+				sql.Named("index", me.index),
+			)
+			if err != nil {
+				return fmt.Errorf("relinking playlist %d entry %d: %w",
+					me.playlistID, me.index, err)
+			}
+
+			if err := refreshEntries(ctx, db, me.playlistID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return l.executeDBJobAndWait(work)
+}
+
+// findMissingEntryTrack returns the ID of the library track for an entry which
+// track was removed from the library. It is stricter than findEntryTrack since
+// linking an entry to the wrong track silently changes the playlist.
+func findMissingEntryTrack(
+	ctx context.Context,
+	db *sql.DB,
+	entry FileEntry,
+) (int64, bool, error) {
+	const pathQuery = `
+		SELECT id FROM tracks WHERE fs_path = @fs_path
+	`
+
+	const metaQuery = `
+		SELECT
+			t.id
+		FROM
+			tracks as t
+				LEFT JOIN artists as at ON at.id = t.artist_id
+				LEFT JOIN albums as al ON al.id = t.album_id
+		WHERE
+			t.name = @title COLLATE NOCASE AND
+			at.name = @artist COLLATE NOCASE AND
+			COALESCE(al.name, '') = @album COLLATE NOCASE AND
+			(
+				@duration = 0 OR
+				t.duration IS NULL OR
+				ABS(t.duration - @duration) <= @tolerance
+			)
+		ORDER BY t.id
+		LIMIT 1
+	`
+
+	var trackID int64
+	if entry.Location != "" {
+		err := db.QueryRowContext(ctx, pathQuery,
+			sql.Named("fs_path", entry.Location),
+		).Scan(&trackID)
+		if err == nil {
+			return trackID, true, nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return 0, false, fmt.Errorf("querying track by path: %w", err)
+		}
+	}
+
+	if entry.Title == "" || entry.Artist == "" {
+		return 0, false, nil
+	}
+
+	err := db.QueryRowContext(ctx, metaQuery,
+		sql.Named("title", entry.Title),
+		sql.Named("artist", entry.Artist),
+		sql.Named("album", entry.Album),
+		sql.Named("duration", entry.Duration.Milliseconds()),
+		sql.Named("tolerance", relinkDurationTolerance.Milliseconds()),
+	).Scan(&trackID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("querying track by metadata: %w", err)
+	}
+
+	return trackID, true, nil
+}
+
+// refreshEntries stores the file path and metadata of the tracks of all entries
+// of a playlist alongside them. This way the entries could be linked to their
+// tracks again if the tracks are removed and then added back to the library.
+func refreshEntries(ctx context.Context, db execer, playlistID int64) error {
+	const refreshQuery = `
+		UPDATE playlists_tracks
+		SET
+			fs_path = t.fs_path,
+			artist = at.name,
+			album = al.name,
+			title = t.name,
+			duration = t.duration
+		FROM
+			tracks as t
+				LEFT JOIN artists as at ON at.id = t.artist_id
+				LEFT JOIN albums as al ON al.id = t.album_id
+		WHERE
+			t.id = playlists_tracks.track_id AND
+			playlists_tracks.playlist_id = @playlist_id
+	`
+
+	_, err := db.ExecContext(ctx, refreshQuery, sql.Named("playlist_id", playlistID))
+	if err != nil {
+		return fmt.Errorf("failed to store playlist entries metadata: %w", err)
+	}
+
+	return nil
+}
+
+// scanEntryReference scans the file path and metadata which a playlist entry
+// remembers about its track. They are expected to be the last columns of the
+// row, after the ones for `dest`.
+func scanEntryReference(row rowScanner, dest ...any) (FileEntry, error) {
+	var (
+		fsPath   sql.NullString
+		artist   sql.NullString
+		album    sql.NullString
+		title    sql.NullString
+		duration sql.NullInt64
+	)
+
+	dest = append(dest, &fsPath, &artist, &album, &title, &duration)
+	if err := row.Scan(dest...); err != nil {
+		return FileEntry{}, fmt.Errorf("scanning playlist entry: %w", err)
+	}
+
+	return FileEntry{
+		Location: fsPath.String,
+		Artist:   artist.String,
+		Album:    album.String,
+		Title:    title.String,
+		Duration: time.Duration(duration.Int64) * time.Millisecond,
+	}, nil
+}
+
+// missingTrack returns the track info shown in place of a playlist entry which
+// track is missing from the library.
+func missingTrack(entry FileEntry) library.TrackInfo {
+	return library.TrackInfo{
+		Artist:   entry.Artist,
+		Album:    entry.Album,
+		Title:    entry.Title,
+		Duration: entry.Duration.Milliseconds(),
+		Missing:  true,
+	}
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -0,0 +1,123 @@
+package playlists_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestPlaylistEntriesRelinking checks that playlist entries are kept when their
+// tracks are removed from the library and that they are linked to the tracks
+// again once they are back.
+func TestPlaylistEntriesRelinking(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	tracks := make(map[string]library.SearchResult)
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: "", Count: 100}) {
+		tracks[track.Title] = track
+	}
+	first := tracks["Payback"]
+	moved := tracks["Tittled Track"]
+	removed := tracks["Another One"]
+
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	linker := playlists.NewEntriesLinker(lib.ExecuteDBJobAndWait)
+
+	id, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:   "Travel",
+		Tracks: []int64{first.ID, moved.ID, removed.ID},
+	})
+	assert.NilErr(t, err, "creating playlist")
+
+	movedPath := lib.GetFilePath(ctx, moved.ID)
+	for _, trackID := range []int64{moved.ID, removed.ID} {
+		err := lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+			_, err := db.ExecContext(ctx, `DELETE FROM tracks WHERE id = @id`,
+				sql.Named("id", trackID),
+			)
+			return err
+		})
+		assert.NilErr(t, err, "removing track %d", trackID)
+	}
+
+	pl, err := manager.Get(ctx, id, "")
+	assert.NilErr(t, err, "getting playlist with missing tracks")
+	assert.Equal(t, 3, len(pl.Tracks), "number of playlist entries")
+	assert.Equal(t, int64(3), pl.TracksCount, "number of counted entries")
+	if len(pl.Tracks) == 3 {
+		assert.Equal(t, false, pl.Tracks[0].Missing, "first entry missing")
+		assert.Equal(t, first.ID, pl.Tracks[0].ID, "first entry track")
+		assert.Equal(t, true, pl.Tracks[1].Missing, "second entry missing")
+		assert.Equal(t, moved.Title, pl.Tracks[1].Title, "missing entry title")
+		assert.Equal(t, moved.Artist, pl.Tracks[1].Artist, "missing entry artist")
+		assert.Equal(t, moved.Album, pl.Tracks[1].Album, "missing entry album")
+		assert.Equal(t, true, pl.Tracks[2].Missing, "third entry missing")
+	}
+
+	// The track is added back to the library at a different path so that
+	// it could only be matched by its metadata.
+	assert.NilErr(t, lib.AddMedia(movedPath), "adding back removed track")
+	err = lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `
+			UPDATE tracks SET fs_path = @new_path WHERE fs_path = @old_path
+		`,
+			sql.Named("new_path", movedPath+".moved"),
+			sql.Named("old_path", movedPath),
+		)
+		return err
+	})
+	assert.NilErr(t, err, "moving re-added track")
+
+	assert.NilErr(t, linker.RelinkPlaylistEntries(ctx), "relinking playlist entries")
+
+	var readdedID int64
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: moved.Title}) {
+		readdedID = track.ID
+	}
+
+	pl, err = manager.Get(ctx, id, "")
+	assert.NilErr(t, err, "getting relinked playlist")
+	assert.Equal(t, 3, len(pl.Tracks), "number of relinked playlist entries")
+	assert.Equal(t, int64(3), pl.TracksCount, "number of counted relinked entries")
+	if len(pl.Tracks) == 3 {
+		assert.Equal(t, false, pl.Tracks[1].Missing, "relinked entry missing")
+		assert.Equal(t, readdedID, pl.Tracks[1].ID, "relinked entry track")
+		assert.Equal(t, true, pl.Tracks[2].Missing, "removed entry missing")
+	}
+
+	// Removing the missing entry works with the indexes of all entries.
+	err = manager.Update(ctx, id, playlists.UpdateArgs{RemoveTracks: []int64{2}})
+	assert.NilErr(t, err, "removing missing entry")
+
+	pl, err = manager.Get(ctx, id, "")
+	assert.NilErr(t, err, "getting playlist without missing entries")
+	assertTracks(t, []int64{first.ID, readdedID}, pl)
+}
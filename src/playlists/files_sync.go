@@ -0,0 +1,289 @@
+package playlists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// filesSyncer implements library.PlaylistFilesSyncer by keeping a read-only
+// playlist for every playlist file found in the library.
+type filesSyncer struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewFilesSyncer returns a library.PlaylistFilesSyncer which will send SQL
+// queries to `sendDBWork`. The playlists it creates are stored along the ones
+// managed by the Playlister returned from NewManager.
+func NewFilesSyncer(
+	sendDBWork func(library.DatabaseExecutable) error,
+) library.PlaylistFilesSyncer {
+	return &filesSyncer{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// SyncPlaylistFile implements library.PlaylistFilesSyncer. Relative locations
+// in the file are resolved against its directory. Entries which could not be
+// matched to library tracks are skipped.
+func (s *filesSyncer) SyncPlaylistFile(
+	ctx context.Context,
+	path string,
+	file io.Reader,
+) error {
+	format, err := ParseFormat(path)
+	if err != nil {
+		return err
+	}
+
+	entries, err := Decode(file, format)
+	if err != nil {
+		return fmt.Errorf("decoding playlist file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	for ind, entry := range entries {
+		entries[ind].Location = resolveFileLocation(dir, entry.Location)
+	}
+
+	const findPlaylistQuery = `
+		SELECT id FROM playlists WHERE fs_path = @fs_path
+	`
+
+	const insertPlaylistQuery = `
+		INSERT INTO
+			playlists (name, public, fs_path, created_at, updated_at)
+		VALUES
+			(@name, 1, @fs_path, @current_time, @current_time)
+	`
+
+	const playlistTracksQuery = `
+		SELECT track_id FROM playlists_tracks
+		WHERE playlist_id = @playlist_id AND track_id IS NOT NULL
+		ORDER BY "index"
+	`
+
+	const removeTracksQuery = `
+		DELETE FROM playlists_tracks
+		WHERE playlist_id = @playlist_id
+	`
+
+	const insertTrackQuery = `
+		INSERT INTO
+			playlists_tracks (playlist_id, track_id, "index")
+		VALUES
+			(@playlist_id, @track_id, @index)
+	`
+
+	const touchPlaylistQuery = `
+		UPDATE playlists
+		SET updated_at = @current_time
+		WHERE id = @playlist_id
+	`
+
+	work := func(db *sql.DB) (retErr error) {
+		var trackIDs []int64
+		for _, entry := range entries {
+			trackID, found, err := findEntryTrack(ctx, db, entry)
+			if err != nil {
+				return fmt.Errorf("matching entry on line %d: %w", entry.Line, err)
+			}
+
+			if !found {
+				log.Printf("Playlist file %s: track on line %d not found: %s\n",
+					path, entry.Line, entry.Location)
+				continue
+			}
+
+			trackIDs = append(trackIDs, trackID)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin DB transaction: %w", err)
+		}
+		defer func() {
+			if retErr == nil {
+				retErr = tx.Commit()
+			} else {
+				_ = tx.Rollback()
+			}
+		}()
+
+		now := time.Now().Unix()
+
+		var playlistID int64
+		err = tx.QueryRowContext(ctx, findPlaylistQuery, sql.Named("fs_path", path)).
+			Scan(&playlistID)
+		if errors.Is(err, sql.ErrNoRows) {
+			name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			res, err := tx.ExecContext(ctx, insertPlaylistQuery,
+				sql.Named("name", name),
+				sql.Named("fs_path", path),
+				sql.Named("current_time", now),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert playlist: %w", err)
+			}
+
+			playlistID, err = res.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("cannot get last insert ID for playlist: %w", err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("querying playlist for file: %w", err)
+		} else {
+			current, err := queryTrackIDs(ctx, tx, playlistTracksQuery, playlistID)
+			if err != nil {
+				return err
+			}
+
+			if slices.Equal(current, trackIDs) {
+				return nil
+			}
+
+			_, err = tx.ExecContext(ctx, removeTracksQuery,
+				sql.Named("playlist_id", playlistID),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to remove playlist tracks: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, touchPlaylistQuery,
+				sql.Named("playlist_id", playlistID),
+				sql.Named("current_time", now),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update playlist: %w", err)
+			}
+		}
+
+		for index, trackID := range trackIDs {
+			_, err := tx.ExecContext(ctx, insertTrackQuery,
+				sql.Named("playlist_id", playlistID),
+				sql.Named("track_id", trackID),
+				sql.Named("index", index),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert playlist track: %w", err)
+			}
+		}
+
+		return refreshEntries(ctx, tx, playlistID)
+	}
+
+	return s.executeDBJobAndWait(work)
+}
+
+// RemovePlaylistFile implements library.PlaylistFilesSyncer.
+func (s *filesSyncer) RemovePlaylistFile(ctx context.Context, path string) error {
+	const deletePlaylistQuery = `
+		DELETE FROM playlists
+		WHERE fs_path = @fs_path
+	`
+
+	work := func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, deletePlaylistQuery, sql.Named("fs_path", path))
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+
+		return nil
+	}
+
+	return s.executeDBJobAndWait(work)
+}
+
+// PlaylistFiles implements library.PlaylistFilesSyncer.
+func (s *filesSyncer) PlaylistFiles(ctx context.Context) ([]string, error) {
+	const filesQuery = `
+		SELECT fs_path FROM playlists
+		WHERE fs_path IS NOT NULL
+	`
+
+	var paths []string
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, filesQuery)
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var path string
+			if err := rows.Scan(&path); err != nil {
+				return fmt.Errorf("scanning playlist file path: %w", err)
+			}
+			paths = append(paths, path)
+		}
+
+		return rows.Err()
+	}
+
+	if err := s.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// queryTrackIDs returns the IDs of the tracks currently in a playlist in their
+// order.
+func queryTrackIDs(
+	ctx context.Context,
+	tx *sql.Tx,
+	query string,
+	playlistID int64,
+) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, sql.Named("playlist_id", playlistID))
+	if err != nil {
+		return nil, fmt.Errorf("querying playlist tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var trackIDs []int64
+	for rows.Next() {
+		var trackID int64
+		if err := rows.Scan(&trackID); err != nil {
+			return nil, fmt.Errorf("scanning playlist track: %w", err)
+		}
+		trackIDs = append(trackIDs, trackID)
+	}
+
+	return trackIDs, rows.Err()
+}
+
+// resolveFileLocation returns the location of a playlist file entry with relative
+// file paths resolved against `dir`, the directory of the playlist file. URLs
+// and absolute paths are returned as they are.
+func resolveFileLocation(dir, location string) string {
+	if location == "" {
+		return location
+	}
+
+	if uri, err := url.Parse(location); err == nil && len(uri.Scheme) > 1 {
+		return location
+	}
+
+	// Playlist files made on Windows may have drive letters and back slashes.
+	if len(location) > 1 && location[1] == ':' {
+		return location
+	}
+	location = strings.ReplaceAll(location, `\`, "/")
+
+	if strings.HasPrefix(location, "/") {
+		return location
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(location))
+}
@@ -0,0 +1,119 @@
+package playlists_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestPlaylistFilesSyncer checks that playlist files are synced into read-only
+// playlists, that relative paths are resolved against the playlist file's
+// directory and that the playlists follow changes and removal of the files.
+func TestPlaylistFilesSyncer(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	libDir := filepath.Join(projRoot, "test_files", "library")
+	lib.AddLibraryPath(libDir)
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	trackIDs := make(map[string]int64)
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: "", Count: 100}) {
+		trackIDs[track.Title] = track.ID
+	}
+
+	syncer := playlists.NewFilesSyncer(lib.ExecuteDBJobAndWait)
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+
+	playlistPath := filepath.Join(libDir, "folder_one", "Road Trip.m3u8")
+	playlistFile := "#EXTM3U\n" +
+		"third_file.mp3\n" +
+		"../test_file_one.mp3\n" +
+		filepath.Join(libDir, "test_file_two.mp3") + "\n" +
+		"missing.mp3\n"
+
+	err = syncer.SyncPlaylistFile(ctx, playlistPath, strings.NewReader(playlistFile))
+	assert.NilErr(t, err, "syncing new playlist file")
+
+	list, err := manager.List(ctx, playlists.ListArgs{})
+	assert.NilErr(t, err, "listing playlists")
+	assert.Equal(t, 1, len(list), "number of playlists")
+
+	playlistID := list[0].ID
+	pl, err := manager.Get(ctx, playlistID, "")
+	assert.NilErr(t, err, "getting synced playlist")
+	assert.Equal(t, "Road Trip", pl.Name, "playlist name")
+	assert.Equal(t, playlistPath, pl.FilePath, "playlist file path")
+	assert.Equal(t, true, pl.ReadOnly(), "read-only playlist")
+	assertTracks(t, []int64{
+		trackIDs["Payback"],
+		trackIDs["Tittled Track"],
+		trackIDs["Another One"],
+	}, pl)
+
+	// Syncing the changed file updates the same playlist.
+	playlistFile = "#EXTM3U\n" +
+		"../test_file_two.mp3\n" +
+		"third_file.mp3\n"
+
+	err = syncer.SyncPlaylistFile(ctx, playlistPath, strings.NewReader(playlistFile))
+	assert.NilErr(t, err, "syncing changed playlist file")
+
+	pl, err = manager.Get(ctx, playlistID, "")
+	assert.NilErr(t, err, "getting changed playlist")
+	assertTracks(t, []int64{trackIDs["Another One"], trackIDs["Payback"]}, pl)
+
+	files, err := syncer.PlaylistFiles(ctx)
+	assert.NilErr(t, err, "listing playlist files")
+	assert.Equal(t, 1, len(files), "number of playlist files")
+	assert.Equal(t, playlistPath, files[0], "playlist file")
+
+	// File-backed playlists could not be changed or deleted directly.
+	err = manager.Update(ctx, playlistID, playlists.UpdateArgs{Name: "Renamed"})
+	if !errors.Is(err, playlists.ErrReadOnly) {
+		t.Errorf("expected read-only error for update but got: %v", err)
+	}
+
+	err = manager.Delete(ctx, playlistID, "")
+	if !errors.Is(err, playlists.ErrReadOnly) {
+		t.Errorf("expected read-only error for delete but got: %v", err)
+	}
+
+	err = syncer.RemovePlaylistFile(ctx, playlistPath)
+	assert.NilErr(t, err, "removing playlist file")
+
+	_, err = manager.Get(ctx, playlistID, "")
+	if !errors.Is(err, playlists.ErrNotFound) {
+		t.Errorf("expected not found error after removal but got: %v", err)
+	}
+
+	err = syncer.SyncPlaylistFile(ctx, "/music/list.wpl", strings.NewReader(""))
+	if !errors.Is(err, playlists.ErrUnknownFormat) {
+		t.Errorf("expected unknown format error but got: %v", err)
+	}
+}
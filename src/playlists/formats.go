@@ -0,0 +1,454 @@
+package playlists
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is a file format for playlists which could be imported and exported.
+type Format string
+
+// All of the supported playlist file formats.
+const (
+	FormatM3U  Format = "m3u"
+	FormatM3U8 Format = "m3u8"
+	FormatPLS  Format = "pls"
+	FormatXSPF Format = "xspf"
+)
+
+// ErrUnknownFormat is returned when a playlist file format is not supported.
+var ErrUnknownFormat = errors.New("unknown playlist format")
+
+// ParseFormat returns the playlist file format for `name`. It may be either the
+// name of the format such as "m3u8" or a file name such as "favourites.m3u8".
+func ParseFormat(name string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "" {
+		ext = name
+	}
+
+	format := Format(strings.ToLower(ext))
+	switch format {
+	case FormatM3U, FormatM3U8, FormatPLS, FormatXSPF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+}
+
+// ContentType returns the MIME type for files in this format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatM3U, FormatM3U8:
+		return "audio/x-mpegurl; charset=utf-8"
+	case FormatPLS:
+		return "audio/x-scpls; charset=utf-8"
+	case FormatXSPF:
+		return "application/xspf+xml; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileEntry is a single track in a playlist file.
+type FileEntry struct {
+	// Line is the line in the file on which the entry is defined. For XSPF
+	// files this is the position of the track in the track list instead.
+	Line int
+
+	// Location is the file path or URL of the track.
+	Location string
+
+	// Artist, Album and Title are the track metadata stored in the playlist
+	// file. Any of them may be empty.
+	Artist string
+	Album  string
+	Title  string
+
+	// Duration is the length of the track. Zero when not known.
+	Duration time.Duration
+}
+
+// Decode reads all track entries from a playlist file in `format`.
+func Decode(r io.Reader, format Format) ([]FileEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading playlist file: %w", err)
+	}
+
+	// Plain .m3u files are traditionally encoded in Latin-1 while .m3u8 ones
+	// are always UTF-8. Many programs write UTF-8 in .m3u files as well so
+	// Latin-1 is assumed only when the file is not valid UTF-8.
+	if format == FormatM3U && !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return decodeM3U(data)
+	case FormatPLS:
+		return decodePLS(data)
+	case FormatXSPF:
+		return decodeXSPF(data)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// Encode writes a playlist file in `format` with the given name and entries.
+// The Line property of the entries is ignored.
+func Encode(w io.Writer, format Format, name string, entries []FileEntry) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case FormatM3U, FormatM3U8:
+		err = encodeM3U(bw, name, entries)
+	case FormatPLS:
+		err = encodePLS(bw, entries)
+	case FormatXSPF:
+		err = encodeXSPF(bw, name, entries)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func decodeM3U(data []byte) ([]FileEntry, error) {
+	var (
+		entries []FileEntry
+		next    FileEntry
+	)
+
+	for lineNo, line := range splitLines(data) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if info, ok := strings.CutPrefix(line, "#EXTINF:"); ok {
+			durStr, display, _ := strings.Cut(info, ",")
+
+			// The duration may be followed by key="value" attributes.
+			durStr, _, _ = strings.Cut(strings.TrimSpace(durStr), " ")
+			if secs, err := strconv.ParseFloat(durStr, 64); err == nil && secs > 0 {
+				next.Duration = time.Duration(secs * float64(time.Second))
+			}
+			next.Artist, next.Title = splitDisplayTitle(display)
+			continue
+		}
+
+		if album, ok := strings.CutPrefix(line, "#EXTALB:"); ok {
+			next.Album = strings.TrimSpace(album)
+			continue
+		}
+
+		if artist, ok := strings.CutPrefix(line, "#EXTART:"); ok {
+			next.Artist = strings.TrimSpace(artist)
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		next.Line = lineNo + 1
+		next.Location = line
+		entries = append(entries, next)
+		next = FileEntry{}
+	}
+
+	return entries, nil
+}
+
+func encodeM3U(w io.Writer, name string, entries []FileEntry) error {
+	if _, err := fmt.Fprintf(w, "#EXTM3U\n#PLAYLIST:%s\n", oneLine(name)); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		display := oneLine(entry.Title)
+		if entry.Artist != "" {
+			display = oneLine(entry.Artist) + " - " + display
+		}
+
+		_, err := fmt.Fprintf(w, "#EXTINF:%d,%s\n", durationSeconds(entry.Duration), display)
+		if err != nil {
+			return err
+		}
+
+		if entry.Album != "" {
+			if _, err := fmt.Fprintf(w, "#EXTALB:%s\n", oneLine(entry.Album)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\n", oneLine(entry.Location)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodePLS(data []byte) ([]FileEntry, error) {
+	byIndex := make(map[int]*FileEntry)
+	entry := func(index int) *FileEntry {
+		if _, ok := byIndex[index]; !ok {
+			byIndex[index] = &FileEntry{}
+		}
+		return byIndex[index]
+	}
+
+	for lineNo, line := range splitLines(data) {
+		key, val, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		var field string
+		for _, prefix := range []string{"file", "title", "length"} {
+			if strings.HasPrefix(key, prefix) {
+				field = prefix
+				break
+			}
+		}
+
+		index, err := strconv.Atoi(strings.TrimPrefix(key, field))
+		if field == "" || err != nil {
+			continue
+		}
+
+		switch field {
+		case "file":
+			entry(index).Location = val
+			entry(index).Line = lineNo + 1
+		case "title":
+			entry(index).Artist, entry(index).Title = splitDisplayTitle(val)
+		case "length":
+			if secs, err := strconv.ParseInt(val, 10, 64); err == nil && secs > 0 {
+				entry(index).Duration = time.Duration(secs) * time.Second
+			}
+		}
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+
+	entries := make([]FileEntry, 0, len(indexes))
+	for _, index := range indexes {
+		if byIndex[index].Location == "" {
+			continue
+		}
+		entries = append(entries, *byIndex[index])
+	}
+
+	return entries, nil
+}
+
+func encodePLS(w io.Writer, entries []FileEntry) error {
+	if _, err := fmt.Fprintln(w, "[playlist]"); err != nil {
+		return err
+	}
+
+	for ind, entry := range entries {
+		title := oneLine(entry.Title)
+		if entry.Artist != "" {
+			title = oneLine(entry.Artist) + " - " + title
+		}
+
+		_, err := fmt.Fprintf(w, "File%d=%s\nTitle%d=%s\nLength%d=%d\n",
+			ind+1, oneLine(entry.Location),
+			ind+1, title,
+			ind+1, durationSeconds(entry.Duration),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", len(entries))
+	return err
+}
+
+// xspfPlaylist is the XML Shareable Playlist Format document. Only the elements
+// relevant to Euterpe are defined. See https://xspf.org/spec for details.
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location []string `xml:"location"`
+	Title    string   `xml:"title,omitempty"`
+	Creator  string   `xml:"creator,omitempty"`
+	Album    string   `xml:"album,omitempty"`
+	Duration int64    `xml:"duration,omitempty"` // in milliseconds
+}
+
+func decodeXSPF(data []byte) ([]FileEntry, error) {
+	var playlist xspfPlaylist
+	if err := xml.Unmarshal(data, &playlist); err != nil {
+		return nil, fmt.Errorf("decoding XSPF: %w", err)
+	}
+
+	entries := make([]FileEntry, 0, len(playlist.Tracks))
+	for ind, track := range playlist.Tracks {
+		entry := FileEntry{
+			Line:     ind + 1,
+			Artist:   strings.TrimSpace(track.Creator),
+			Album:    strings.TrimSpace(track.Album),
+			Title:    strings.TrimSpace(track.Title),
+			Duration: time.Duration(track.Duration) * time.Millisecond,
+		}
+		if len(track.Location) > 0 {
+			entry.Location = xspfLocationToPath(strings.TrimSpace(track.Location[0]))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func encodeXSPF(w io.Writer, name string, entries []FileEntry) error {
+	playlist := xspfPlaylist{
+		Version: "1",
+		Title:   name,
+		Tracks:  make([]xspfTrack, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		playlist.Tracks = append(playlist.Tracks, xspfTrack{
+			Location: []string{pathToXSPFLocation(entry.Location)},
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Album:    entry.Album,
+			Duration: entry.Duration.Milliseconds(),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(playlist); err != nil {
+		return fmt.Errorf("encoding XSPF: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xspfLocationToPath converts a XSPF track location to a file path. Locations
+// in XSPF are URIs. Such with the "file" scheme are converted to file paths,
+// relative URIs are unescaped and all others are returned as they are.
+func xspfLocationToPath(location string) string {
+	uri, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+
+	switch uri.Scheme {
+	case "file":
+		filePath := uri.Path
+
+		// Windows paths such as file:///C:/Music/track.mp3 have their drive
+		// letter after the leading slash.
+		if len(filePath) > 2 && filePath[0] == '/' && filePath[2] == ':' {
+			filePath = filePath[1:]
+		}
+		return filePath
+	case "":
+		return uri.Path
+	default:
+		return location
+	}
+}
+
+// pathToXSPFLocation is the reverse of xspfLocationToPath. It converts file paths
+// to URIs suitable for XSPF track locations.
+func pathToXSPFLocation(location string) string {
+	if uri, err := url.Parse(location); err == nil && len(uri.Scheme) > 1 {
+		return location
+	}
+
+	slashed := filepath.ToSlash(location)
+	if path.IsAbs(slashed) {
+		return (&url.URL{Scheme: "file", Path: slashed}).String()
+	}
+
+	return (&url.URL{Path: slashed}).String()
+}
+
+// splitDisplayTitle splits titles in the "Artist - Title" form commonly used in
+// M3U and PLS files. When there is no artist in the title then only the title
+// is returned.
+func splitDisplayTitle(display string) (artist, title string) {
+	display = strings.TrimSpace(display)
+	if artist, title, ok := strings.Cut(display, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+
+	return "", display
+}
+
+// splitLines returns the lines in data regardless of whether they are
+// terminated by "\n" or "\r\n".
+func splitLines(data []byte) []string {
+	lines := strings.Split(string(data), "\n")
+	for ind, line := range lines {
+		lines[ind] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// oneLine makes sure `text` cannot break the line based playlist file formats.
+func oneLine(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
+
+// durationSeconds returns the duration in seconds as used in M3U and PLS files.
+// Unknown durations are encoded as -1.
+func durationSeconds(dur time.Duration) int64 {
+	if dur <= 0 {
+		return -1
+	}
+	return int64(dur.Round(time.Second).Seconds())
+}
+
+// latin1ToUTF8 converts ISO 8859-1 encoded data to UTF-8.
+func latin1ToUTF8(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	for _, b := range data {
+		buf.WriteRune(rune(b))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,235 @@
+package playlists_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestDecodePlaylistFiles checks that entries are read correctly from all of the
+// supported playlist file formats.
+func TestDecodePlaylistFiles(t *testing.T) {
+	tests := []struct {
+		desc     string
+		format   playlists.Format
+		file     string
+		expected []playlists.FileEntry
+	}{
+		{
+			desc:   "extended m3u8 with CRLF and BOM",
+			format: playlists.FormatM3U8,
+			file: "\ufeff#EXTM3U\r\n" +
+				"#EXTINF:183,Artist Testoff - Tittled Track\r\n" +
+				"#EXTALB:Album Of Tests\r\n" +
+				"D:\\Music\\Artist Testoff\\test_file_one.mp3\r\n" +
+				"\r\n" +
+				"# some comment\r\n" +
+				"folder_one/third_file.mp3\r\n",
+			expected: []playlists.FileEntry{
+				{
+					Line:     4,
+					Location: `D:\Music\Artist Testoff\test_file_one.mp3`,
+					Artist:   "Artist Testoff",
+					Album:    "Album Of Tests",
+					Title:    "Tittled Track",
+					Duration: 183 * time.Second,
+				},
+				{
+					Line:     7,
+					Location: "folder_one/third_file.mp3",
+				},
+			},
+		},
+		{
+			desc:   "m3u with attributes and unknown duration",
+			format: playlists.FormatM3U,
+			file: "#EXTM3U\n" +
+				"#EXTINF:-1 tvg-id=\"some\",Only Title\n" +
+				"http://example.com/stream.mp3\n",
+			expected: []playlists.FileEntry{
+				{
+					Line:     3,
+					Location: "http://example.com/stream.mp3",
+					Title:    "Only Title",
+				},
+			},
+		},
+		{
+			desc:   "latin-1 encoded m3u",
+			format: playlists.FormatM3U,
+			file:   "#EXTINF:10,Bj\xf6rk - J\xf3ga\nj\xf3ga.mp3\n",
+			expected: []playlists.FileEntry{
+				{
+					Line:     2,
+					Location: "jóga.mp3",
+					Artist:   "Björk",
+					Title:    "Jóga",
+					Duration: 10 * time.Second,
+				},
+			},
+		},
+		{
+			desc:   "pls",
+			format: playlists.FormatPLS,
+			file: "[playlist]\n" +
+				"File2=/music/second.mp3\n" +
+				"Title2=Second Artist - Second Song\n" +
+				"Length2=-1\n" +
+				"File1=/music/first.mp3\n" +
+				"Title1=First Song\n" +
+				"Length1=95\n" +
+				"NumberOfEntries=2\n" +
+				"Version=2\n",
+			expected: []playlists.FileEntry{
+				{
+					Line:     5,
+					Location: "/music/first.mp3",
+					Title:    "First Song",
+					Duration: 95 * time.Second,
+				},
+				{
+					Line:     2,
+					Location: "/music/second.mp3",
+					Artist:   "Second Artist",
+					Title:    "Second Song",
+				},
+			},
+		},
+		{
+			desc:   "xspf",
+			format: playlists.FormatXSPF,
+			file: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Some Playlist</title>
+  <trackList>
+    <track>
+      <location>file:///music/Some%20Artist/first.mp3</location>
+      <creator>Some Artist</creator>
+      <album>Some Album</album>
+      <title>First</title>
+      <duration>95000</duration>
+    </track>
+    <track>
+      <location>file:///C:/Music/second.flac</location>
+    </track>
+    <track>
+      <location>relative/third%20song.ogg</location>
+    </track>
+  </trackList>
+</playlist>`,
+			expected: []playlists.FileEntry{
+				{
+					Line:     1,
+					Location: "/music/Some Artist/first.mp3",
+					Artist:   "Some Artist",
+					Album:    "Some Album",
+					Title:    "First",
+					Duration: 95 * time.Second,
+				},
+				{
+					Line:     2,
+					Location: "C:/Music/second.flac",
+				},
+				{
+					Line:     3,
+					Location: "relative/third song.ogg",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			entries, err := playlists.Decode(strings.NewReader(test.file), test.format)
+			assert.NilErr(t, err, "decoding playlist file")
+			assert.Equal(t, len(test.expected), len(entries), "number of entries")
+
+			for ind, expected := range test.expected {
+				if ind >= len(entries) {
+					break
+				}
+				assert.Equal(t, expected, entries[ind], "entry %d", ind)
+			}
+		})
+	}
+}
+
+// TestEncodePlaylistFiles checks that playlist files written in every format
+// could be read back without losing information.
+func TestEncodePlaylistFiles(t *testing.T) {
+	entries := []playlists.FileEntry{
+		{
+			Location: "https://example.com/v1/file/5",
+			Artist:   "Some Artist",
+			Album:    "Some Album",
+			Title:    "Song & Title",
+			Duration: 183 * time.Second,
+		},
+		{
+			Location: "Other Artist/Album/track two.mp3",
+			Title:    "Track Two",
+		},
+	}
+
+	formats := []playlists.Format{
+		playlists.FormatM3U,
+		playlists.FormatM3U8,
+		playlists.FormatPLS,
+		playlists.FormatXSPF,
+	}
+
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := playlists.Encode(&buf, format, "Test Playlist", entries)
+			assert.NilErr(t, err, "encoding playlist")
+
+			decoded, err := playlists.Decode(&buf, format)
+			assert.NilErr(t, err, "decoding encoded playlist")
+			assert.Equal(t, len(entries), len(decoded), "number of entries")
+
+			for ind, expected := range entries {
+				if ind >= len(decoded) {
+					break
+				}
+
+				actual := decoded[ind]
+				actual.Line = 0
+
+				// There is no place for the album in PLS files.
+				if format == playlists.FormatPLS {
+					expected.Album = ""
+				}
+				assert.Equal(t, expected, actual, "entry %d", ind)
+			}
+		})
+	}
+}
+
+// TestParsePlaylistFormat checks that playlist formats are recognized by name
+// and by file extension.
+func TestParsePlaylistFormat(t *testing.T) {
+	tests := map[string]playlists.Format{
+		"m3u8":                playlists.FormatM3U8,
+		"M3U":                 playlists.FormatM3U,
+		"favourites.pls":      playlists.FormatPLS,
+		"some/dir/list.XSPF":  playlists.FormatXSPF,
+		"foobar2000 mix.m3u8": playlists.FormatM3U8,
+	}
+
+	for name, expected := range tests {
+		format, err := playlists.ParseFormat(name)
+		assert.NilErr(t, err, "parsing format %s", name)
+		assert.Equal(t, expected, format, "format for %s", name)
+	}
+
+	_, err := playlists.ParseFormat("list.wpl")
+	if !errors.Is(err, playlists.ErrUnknownFormat) {
+		t.Errorf("expected unknown format error but got: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package playlists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Import implements Playlister.
+func (m *manager) Import(ctx context.Context, args ImportArgs) (ImportResult, error) {
+	if args.Name == "" {
+		return ImportResult{}, fmt.Errorf("name cannot be empty")
+	}
+
+	var (
+		result   ImportResult
+		trackIDs []int64
+	)
+
+	work := func(db *sql.DB) error {
+		for _, entry := range args.Entries {
+			trackID, found, err := findEntryTrack(ctx, db, entry)
+			if err != nil {
+				return fmt.Errorf("matching entry on line %d: %w", entry.Line, err)
+			}
+
+			if !found {
+				result.Unmatched = append(result.Unmatched, entry)
+				continue
+			}
+
+			trackIDs = append(trackIDs, trackID)
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return ImportResult{}, err
+	}
+
+	playlistID, err := m.Create(ctx, CreateArgs{
+		Name:        args.Name,
+		Description: args.Description,
+		Owner:       args.Owner,
+		Tracks:      trackIDs,
+	})
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("creating playlist: %w", err)
+	}
+
+	result.PlaylistID = playlistID
+	result.Matched = len(trackIDs)
+
+	return result, nil
+}
+
+// streamURLPathRegexp matches the path of the URLs for streaming tracks. Such
+// URLs are found in playlists exported by Euterpe.
+var streamURLPathRegexp = regexp.MustCompile(`/v1/file/(\d+)$`)
+
+// findEntryTrack returns the ID of the library track for a playlist file entry.
+// It first tries to find the track by its location and when that fails by its
+// artist, album and title.
+func findEntryTrack(
+	ctx context.Context,
+	db *sql.DB,
+	entry FileEntry,
+) (int64, bool, error) {
+	location := entry.Location
+	if uri, err := url.Parse(location); err == nil && len(uri.Scheme) > 1 {
+		matches := streamURLPathRegexp.FindStringSubmatch(uri.Path)
+		if uri.Scheme == "file" {
+			location = xspfLocationToPath(location)
+		} else if len(matches) == 2 {
+			trackID, _ := strconv.ParseInt(matches[1], 10, 64)
+			return findTrackByID(ctx, db, trackID)
+		} else {
+			location = ""
+		}
+	}
+
+	if location != "" {
+		trackID, found, err := findTrackByPath(ctx, db, location)
+		if err != nil || found {
+			return trackID, found, err
+		}
+	}
+
+	return findTrackByMeta(ctx, db, entry)
+}
+
+func findTrackByID(ctx context.Context, db *sql.DB, trackID int64) (int64, bool, error) {
+	const query = `
+		SELECT id FROM tracks WHERE id = @id
+	`
+
+	err := db.QueryRowContext(ctx, query, sql.Named("id", trackID)).Scan(&trackID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("querying track by ID: %w", err)
+	}
+
+	return trackID, true, nil
+}
+
+// findTrackByPath looks for a track with exactly the same file path first. When
+// there is no such track then the longest path suffix (in whole path elements)
+// which matches exactly one track is used. This way playlists created on other
+// machines or with relative paths still find their tracks.
+func findTrackByPath(
+	ctx context.Context,
+	db *sql.DB,
+	location string,
+) (int64, bool, error) {
+	const exactQuery = `
+		SELECT id FROM tracks WHERE fs_path = @fs_path
+	`
+
+	const suffixQuery = `
+		SELECT id FROM tracks
+		WHERE fs_path LIKE @suffix ESCAPE '\'
+		LIMIT 2
+	`
+
+	var trackID int64
+	err := db.QueryRowContext(ctx, exactQuery,
+		sql.Named("fs_path", filepath.Clean(location)),
+	).Scan(&trackID)
+	if err == nil {
+		return trackID, true, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("querying track by path: %w", err)
+	}
+
+	// Playlist files made on Windows use back slashes regardless of where
+	// they are imported.
+	var elements []string
+	for _, element := range strings.Split(strings.ReplaceAll(location, `\`, "/"), "/") {
+		if element == "" || element == "." || element == ".." {
+			continue
+		}
+		elements = append(elements, element)
+	}
+
+	for start := range elements {
+		suffix := string(filepath.Separator) + path.Join(elements[start:]...)
+		suffix = filepath.FromSlash(suffix)
+
+		rows, err := db.QueryContext(ctx, suffixQuery,
+			sql.Named("suffix", "%"+escapeLike(suffix)),
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("querying track by path suffix: %w", err)
+		}
+
+		var found []int64
+		for rows.Next() {
+			if err := rows.Scan(&trackID); err != nil {
+				_ = rows.Close()
+				return 0, false, fmt.Errorf("scanning track ID: %w", err)
+			}
+			found = append(found, trackID)
+		}
+		if err := rows.Err(); err != nil {
+			return 0, false, fmt.Errorf("iterating tracks by path suffix: %w", err)
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], true, nil
+		default:
+			// Shorter suffixes will be even more ambiguous.
+			return 0, false, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// findTrackByMeta looks for a track by its title and artist and optionally its
+// album. Titles alone are too ambiguous so entries without an artist are never
+// matched.
+func findTrackByMeta(
+	ctx context.Context,
+	db *sql.DB,
+	entry FileEntry,
+) (int64, bool, error) {
+	if entry.Title == "" || entry.Artist == "" {
+		return 0, false, nil
+	}
+
+	query := `
+		SELECT
+			t.id
+		FROM
+			tracks as t
+				LEFT JOIN artists as at ON at.id = t.artist_id
+				LEFT JOIN albums as al ON al.id = t.album_id
+		WHERE
+			t.name = @title COLLATE NOCASE AND
+			at.name = @artist COLLATE NOCASE
+	`
+	queryArgs := []any{
+		sql.Named("title", entry.Title),
+		sql.Named("artist", entry.Artist),
+	}
+
+	if entry.Album != "" {
+		query += ` AND al.name = @album COLLATE NOCASE`
+		queryArgs = append(queryArgs, sql.Named("album", entry.Album))
+	}
+	query += `
+		ORDER BY t.id
+		LIMIT 1
+	`
+
+	var trackID int64
+	err := db.QueryRowContext(ctx, query, queryArgs...).Scan(&trackID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("querying track by metadata: %w", err)
+	}
+
+	return trackID, true, nil
+}
+
+// escapeLike escapes the special characters for the SQL LIKE operator. It is
+// expected to be used with `ESCAPE '\'`.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,94 @@
+package playlists_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestPlaylistsManagerImport checks that entries from playlist files are matched
+// to library tracks by path and by metadata and that unmatched entries are
+// reported.
+func TestPlaylistsManagerImport(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	libraryPath := filepath.Join(projRoot, "test_files", "library")
+	lib.AddLibraryPath(libraryPath)
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	trackIDs := make(map[string]int64)
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: "", Count: 100}) {
+		trackIDs[track.Title] = track.ID
+	}
+	if len(trackIDs) < 3 {
+		t.Fatalf("not enough tracks found in the library for importing playlists")
+	}
+
+	file := strings.Join([]string{
+		"#EXTM3U",
+		"#EXTINF:-1,Buggy Bugoff - Payback",
+		filepath.Join(libraryPath, "folder_one", "third_file.mp3"),
+		`D:\Music\library\test_file_one.mp3`,
+		"#EXTINF:-1,artist testoff - ANOTHER ONE",
+		"missing/test_file_three.mp3",
+		"#EXTINF:-1,Unknown Artist - Unknown Song",
+		"missing/unknown.mp3",
+		"#EXTINF:-1,Missing Artist",
+		"https://example.com/v1/file/" + "2",
+		"https://example.com/stream.mp3",
+	}, "\n")
+
+	entries, err := playlists.Decode(strings.NewReader(file), playlists.FormatM3U8)
+	assert.NilErr(t, err, "decoding playlist file")
+
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	result, err := manager.Import(ctx, playlists.ImportArgs{
+		Name:    "Imported",
+		Entries: entries,
+	})
+	assert.NilErr(t, err, "importing playlist")
+	assert.Equal(t, 4, result.Matched, "number of matched entries")
+	assert.Equal(t, 2, len(result.Unmatched), "number of unmatched entries")
+	if len(result.Unmatched) == 2 {
+		assert.Equal(t, 8, result.Unmatched[0].Line, "first unmatched line")
+		assert.Equal(t, 11, result.Unmatched[1].Line, "second unmatched line")
+	}
+
+	playlist, err := manager.Get(ctx, result.PlaylistID, "")
+	assert.NilErr(t, err, "getting imported playlist")
+	assert.Equal(t, "Imported", playlist.Name, "playlist name")
+	assertTracks(t, []int64{
+		trackIDs["Payback"],
+		trackIDs["Tittled Track"],
+		trackIDs["Another One"],
+		2,
+	}, playlist)
+
+	_, err = manager.Import(ctx, playlists.ImportArgs{Entries: entries})
+	assert.NotNilErr(t, err, "expected an error for import without a name")
+}
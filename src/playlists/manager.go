@@ -26,21 +26,40 @@ func NewManager(sendDBWork func(library.DatabaseExecutable) error) Playlister {
 }
 
 // Get implements Playlister.
-func (m *manager) Get(ctx context.Context, id int64) (Playlist, error) {
+func (m *manager) Get(ctx context.Context, id int64, user string) (Playlist, error) {
 	const getPlaylistQuery = selectPlaylistQuery + `
-		WHERE pl.id = @playlist_id
+		WHERE pl.id = @playlist_id AND ` + visibleCondition + `
 		GROUP BY pl.id
 	`
 
-	const getTrackIDsQuery = `
-		SELECT track_id, "index" FROM playlists_tracks
-		WHERE playlist_id = @playlist_id
+	const getEntriesQuery = `
+		SELECT
+			track_id,
+			fs_path,
+			artist,
+			album,
+			title,
+			duration
+		FROM
+			playlists_tracks
+		WHERE
+			playlist_id = @playlist_id
+		ORDER BY
+			"index"
 	`
 
+	type playlistEntry struct {
+		trackID   sql.NullInt64
+		reference FileEntry
+	}
+
 	var playlist Playlist
 
 	work := func(db *sql.DB) error {
-		row := db.QueryRowContext(ctx, getPlaylistQuery, sql.Named("playlist_id", id))
+		row := db.QueryRowContext(ctx, getPlaylistQuery,
+			sql.Named("playlist_id", id),
+			sql.Named("user", user),
+		)
 		scanned, err := scanPlaylist(row)
 		if err != nil && errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
@@ -50,37 +69,64 @@ func (m *manager) Get(ctx context.Context, id int64) (Playlist, error) {
 
 		playlist = scanned
 
-		var playlistTracks []int64
-		res, err := db.QueryContext(ctx, getTrackIDsQuery, sql.Named("playlist_id", id))
+		collaborators, err := queryCollaborators(ctx, db, []int64{id})
+		if err != nil {
+			return err
+		}
+		playlist.Collaborators = collaborators[id]
+
+		if playlist.Rules != nil {
+			tracks, err := querySmartTracks(ctx, db, *playlist.Rules)
+			if err != nil {
+				return err
+			}
+
+			playlist.Tracks = tracks
+			playlist.TracksCount = int64(len(tracks))
+			playlist.Duration = 0
+			for _, track := range tracks {
+				playlist.Duration += time.Duration(track.Duration) * time.Millisecond
+			}
+
+			return nil
+		}
+
+		var entries []playlistEntry
+		res, err := db.QueryContext(ctx, getEntriesQuery, sql.Named("playlist_id", id))
 		if err != nil {
 			return fmt.Errorf("failed to get track IDs: %w", err)
 		}
 		for res.Next() {
-			var (
-				trackID int64
-				index   int64
-			)
+			var entry playlistEntry
 
-			if err := res.Scan(&trackID, &index); err != nil {
+			entry.reference, err = scanEntryReference(res, &entry.trackID)
+			if err != nil {
 				return fmt.Errorf("failed to scan track: %w", err)
 			}
 
-			playlistTracks = append(playlistTracks, trackID)
+			entries = append(entries, entry)
 		}
 
-		if len(playlistTracks) == 0 {
-			return nil
-		}
-
-		tracksQueryArg := make([]any, 0, len(playlistTracks))
+		tracksQueryArg := make([]any, 0, len(entries))
 		tracksSet := make(map[int64]struct{})
-		for _, trackID := range playlistTracks {
-			if _, found := tracksSet[trackID]; found {
+		for _, entry := range entries {
+			if !entry.trackID.Valid {
+				continue
+			}
+
+			if _, found := tracksSet[entry.trackID.Int64]; found {
 				continue
 			}
 
-			tracksSet[trackID] = struct{}{}
-			tracksQueryArg = append(tracksQueryArg, trackID)
+			tracksSet[entry.trackID.Int64] = struct{}{}
+			tracksQueryArg = append(tracksQueryArg, entry.trackID.Int64)
+		}
+
+		if len(tracksQueryArg) == 0 {
+			for _, entry := range entries {
+				playlist.Tracks = append(playlist.Tracks, missingTrack(entry.reference))
+			}
+			return nil
 		}
 
 		queryTracksWhere := []string{
@@ -96,7 +142,7 @@ func (m *manager) Get(ctx context.Context, id int64) (Playlist, error) {
 		}
 
 		// tracks is a map from track ID => track info.
-		tracks := make(map[int64]library.TrackInfo, len(playlistTracks))
+		tracks := make(map[int64]library.TrackInfo, len(tracksQueryArg))
 		for rows.Next() {
 			track, err := library.ScanTrack(rows)
 			if err != nil {
@@ -106,11 +152,12 @@ func (m *manager) Get(ctx context.Context, id int64) (Playlist, error) {
 			tracks[track.ID] = track
 		}
 
-		for _, trackID := range playlistTracks {
-			trackInfo, found := tracks[trackID]
-			if !found {
-				// Something fishy is going on!
-				continue
+		for _, entry := range entries {
+			trackInfo, found := tracks[entry.trackID.Int64]
+			if !entry.trackID.Valid || !found {
+				// Entries which tracks are not in the library are shown as
+				// missing so that they keep their places in the playlist.
+				trackInfo = missingTrack(entry.reference)
 			}
 
 			playlist.Tracks = append(playlist.Tracks, trackInfo)
@@ -126,13 +173,13 @@ func (m *manager) Get(ctx context.Context, id int64) (Playlist, error) {
 }
 
 // Count implements Playlister.
-func (m *manager) Count(ctx context.Context) (int64, error) {
+func (m *manager) Count(ctx context.Context, user string) (int64, error) {
 	var playlistsCount int64
 
 	work := func(db *sql.DB) error {
 		var count sql.NullInt64
 
-		row := db.QueryRowContext(ctx, countPlaylistsQuery)
+		row := db.QueryRowContext(ctx, countPlaylistsQuery, sql.Named("user", user))
 		if err := row.Scan(&count); err != nil {
 			return fmt.Errorf("error in SQL query for getting playlists count: %w", err)
 		}
@@ -160,16 +207,22 @@ func (m *manager) List(ctx context.Context, args ListArgs) ([]Playlist, error) {
 		queryArgs []any
 
 		querySuffix = `
+		WHERE
+			` + visibleCondition + `
 		GROUP BY
 			pl.id
 		`
 	)
 
+	queryArgs = append(queryArgs, sql.Named("user", args.User))
 	if args.Count > 0 || args.Offset > 0 {
 		querySuffix += `
-		LIMIT ?, ?
+		LIMIT @offset, @count
 		`
-		queryArgs = append(queryArgs, args.Offset, args.Count)
+		queryArgs = append(queryArgs,
+			sql.Named("offset", args.Offset),
+			sql.Named("count", args.Count),
+		)
 	}
 
 	getPlaylistsQuery := selectPlaylistQuery + querySuffix
@@ -189,6 +242,31 @@ func (m *manager) List(ctx context.Context, args ListArgs) ([]Playlist, error) {
 			playlists = append(playlists, playlist)
 		}
 
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over playlists: %w", err)
+		}
+
+		playlistIDs := make([]int64, 0, len(playlists))
+		for _, playlist := range playlists {
+			playlistIDs = append(playlistIDs, playlist.ID)
+		}
+
+		collaborators, err := queryCollaborators(ctx, db, playlistIDs)
+		if err != nil {
+			return err
+		}
+
+		for ind := range playlists {
+			playlists[ind].Collaborators = collaborators[playlists[ind].ID]
+			if playlists[ind].Rules == nil {
+				continue
+			}
+
+			if err := setSmartStats(ctx, db, &playlists[ind]); err != nil {
+				return fmt.Errorf("playlist %d: %w", playlists[ind].ID, err)
+			}
+		}
+
 		return nil
 	}
 	if err := m.executeDBJobAndWait(work); err != nil {
@@ -207,13 +285,36 @@ func (m *manager) Create(
 		return 0, fmt.Errorf("name cannot be empty")
 	}
 
+	rulesVal := sql.Named("rules", nil)
+	if args.Rules != nil {
+		if len(args.Tracks) > 0 {
+			return 0, fmt.Errorf("%w: tracks cannot be added", ErrSmartPlaylist)
+		}
+
+		encoded, err := encodeRules(*args.Rules)
+		if err != nil {
+			return 0, err
+		}
+		rulesVal = sql.Named("rules", encoded)
+	}
+
+	ownerVal := sql.Named("owner", nil)
+	if args.Owner != "" {
+		ownerVal = sql.Named("owner", args.Owner)
+	}
+
+	publicInt := 1
+	if args.Public != nil && !*args.Public {
+		publicInt = 0
+	}
+
 	var lastInsertID int64
 
 	insertPlaylistQuery := `
 		INSERT INTO
-			playlists (name, description, public, created_at, updated_at)
+			playlists (name, description, public, owner, rules, created_at, updated_at)
 		VALUES
-			(@name, @description, 1, @current_time, @current_time)
+			(@name, @description, @public, @owner, @rules, @current_time, @current_time)
 	`
 
 	insertSongsQuery := `
@@ -245,7 +346,10 @@ func (m *manager) Create(
 		res, err := tx.ExecContext(ctx, insertPlaylistQuery,
 			sql.Named("name", args.Name),
 			sql.Named("current_time", time.Now().Unix()),
+			sql.Named("public", publicInt),
 			descVal,
+			ownerVal,
+			rulesVal,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert playlist: %w", err)
@@ -257,6 +361,12 @@ func (m *manager) Create(
 		}
 
 		lastInsertID = id
+
+		err = insertCollaborators(ctx, tx, lastInsertID, args.Owner, args.Collaborators)
+		if err != nil {
+			return err
+		}
+
 		if len(args.Tracks) == 0 {
 			return nil
 		}
@@ -277,7 +387,7 @@ func (m *manager) Create(
 			return fmt.Errorf("failed to insert playlist: %w", err)
 		}
 
-		return nil
+		return refreshEntries(ctx, tx, lastInsertID)
 	}
 
 	if err := m.executeDBJobAndWait(work); err != nil {
@@ -313,9 +423,29 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 		updateValues = append(updateValues, sql.Named("public", publicInt))
 	}
 
-	if len(updateFields) == 0 && !args.RemoveAllTracks &&
-		len(args.AddTracks) == 0 && len(args.RemoveTracks) == 0 &&
-		len(args.MoveTracks) == 0 {
+	changesTracks := len(args.AddTracks) > 0 || len(args.RemoveTracks) > 0 ||
+		len(args.MoveTracks) > 0
+
+	if args.Rules != nil {
+		if changesTracks {
+			return fmt.Errorf("%w: tracks cannot be changed along with rules",
+				ErrSmartPlaylist)
+		}
+
+		encoded, err := encodeRules(*args.Rules)
+		if err != nil {
+			return err
+		}
+
+		updateFields = append(updateFields, "rules = @rules")
+		updateValues = append(updateValues, sql.Named("rules", encoded))
+
+		// Smart playlists do not have tracks of their own.
+		args.RemoveAllTracks = true
+	}
+
+	if len(updateFields) == 0 && !args.RemoveAllTracks && !changesTracks &&
+		args.Collaborators == nil {
 		// nothing to do here!
 		return nil
 	}
@@ -334,6 +464,12 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 			id = @playlist_id
 	`
 
+	const removeCollaboratorsQuery = `
+		DELETE FROM playlists_collaborators
+		WHERE
+			playlist_id = @playlist_id
+	`
+
 	const removeAllQuery = `
 		DELETE FROM playlists_tracks
 		WHERE
@@ -362,9 +498,14 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 			"index" > @track_index
 	`
 
-	const getTrackByIndexQuery = `
+	const getEntryByIndexQuery = `
 		SELECT
-			track_id
+			track_id,
+			fs_path,
+			artist,
+			album,
+			title,
+			duration
 		FROM
 			playlists_tracks
 		WHERE
@@ -405,20 +546,49 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 			}
 		}()
 
-		res, err := tx.ExecContext(ctx, updatePlaylistQuery, updateValues...)
-		if err != nil && errors.Is(err, sql.ErrNoRows) {
+		current, err := queryAccess(ctx, tx, id)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !current.VisibleFor(args.User) {
 			return fmt.Errorf("playlist for updating not found: %w", ErrNotFound)
 		} else if err != nil {
-			return fmt.Errorf("update playlist error: %w", err)
+			return fmt.Errorf("failed to check playlist access: %w", err)
 		}
 
-		affected, err := res.RowsAffected()
+		if !current.EditableBy(args.User) {
+			return ErrNotAllowed
+		}
+
+		changesAccess := args.Public != nil || args.Collaborators != nil
+		if changesAccess && current.Owner != "" && current.Owner != args.User {
+			return fmt.Errorf("%w: only the owner may change who has access to it",
+				ErrNotAllowed)
+		}
+
+		if current.FilePath != "" {
+			return fmt.Errorf("%w: playlist follows its file", ErrReadOnly)
+		}
+
+		if current.Rules != nil && args.Rules == nil &&
+			(args.RemoveAllTracks || changesTracks) {
+			return ErrSmartPlaylist
+		}
+
+		_, err = tx.ExecContext(ctx, updatePlaylistQuery, updateValues...)
 		if err != nil {
-			return fmt.Errorf("could not get the number of affected rows: %w", err)
+			return fmt.Errorf("update playlist error: %w", err)
 		}
 
-		if affected < 1 {
-			return fmt.Errorf("playlist for updating not found: %w", ErrNotFound)
+		if args.Collaborators != nil {
+			_, err := tx.ExecContext(ctx, removeCollaboratorsQuery,
+				sql.Named("playlist_id", id),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to remove playlist collaborators: %w", err)
+			}
+
+			err = insertCollaborators(ctx, tx, id, current.Owner, *args.Collaborators)
+			if err != nil {
+				return err
+			}
 		}
 
 		if args.RemoveAllTracks {
@@ -478,6 +648,10 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 			if err != nil {
 				return fmt.Errorf("failed to insert songs to playlist: %w", err)
 			}
+
+			if err := refreshEntries(ctx, tx, id); err != nil {
+				return err
+			}
 		}
 
 		for ind, move := range args.MoveTracks {
@@ -485,12 +659,15 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 				continue
 			}
 
-			var trackID int64
-			row := tx.QueryRowContext(ctx, getTrackByIndexQuery,
+			// Missing entries are moved too so the whole entry is moved
+			// instead of only its track.
+			var trackID sql.NullInt64
+			row := tx.QueryRowContext(ctx, getEntryByIndexQuery,
 				sql.Named("playlist_id", id),
 				sql.Named("track_index", move.FromIndex),
 			)
-			if err := row.Scan(&trackID); err != nil {
+			reference, err := scanEntryReference(row, &trackID)
+			if err != nil {
 				return fmt.Errorf("failed to scan for track for move %d (%d->%d): %w",
 					ind, move.FromIndex, move.ToIndex, err)
 			}
@@ -524,15 +701,26 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 
 			insertMovedQuery := `
 				INSERT INTO
-					playlists_tracks (playlist_id, track_id, "index")
+					playlists_tracks (
+						playlist_id, track_id, "index",
+						fs_path, artist, album, title, duration
+					)
 				VALUES
-					(@playlist_id, @track_id, @track_index)
+					(
+						@playlist_id, @track_id, @track_index,
+						@fs_path, @artist, @album, @title, @duration
+					)
 			`
 
 			var insertArgs = []any{
 				sql.Named("playlist_id", id),
 				sql.Named("track_id", trackID),
 				sql.Named("track_index", move.ToIndex),
+				sql.Named("fs_path", reference.Location),
+				sql.Named("artist", reference.Artist),
+				sql.Named("album", reference.Album),
+				sql.Named("title", reference.Title),
+				sql.Named("duration", reference.Duration.Milliseconds()),
 			}
 
 			_, err = tx.ExecContext(ctx, insertMovedQuery, insertArgs...)
@@ -553,13 +741,28 @@ func (m *manager) Update(ctx context.Context, id int64, args UpdateArgs) error {
 }
 
 // Delete implements Playlister.
-func (m *manager) Delete(ctx context.Context, id int64) error {
+func (m *manager) Delete(ctx context.Context, id int64, user string) error {
 	const deletePlaylistQuery = `
 		DELETE FROM playlists
-		WHERE id = @playlist_id
+		WHERE id = @playlist_id AND fs_path IS NULL
 	`
 
 	work := func(db *sql.DB) (retErr error) {
+		current, err := queryAccess(ctx, db, id)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !current.VisibleFor(user) {
+			return ErrNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to check playlist access: %w", err)
+		}
+
+		if current.Owner != "" && current.Owner != user {
+			return fmt.Errorf("%w: only the owner may delete it", ErrNotAllowed)
+		}
+
+		if current.FilePath != "" {
+			return fmt.Errorf("%w: playlist follows its file", ErrReadOnly)
+		}
+
 		res, err := db.ExecContext(ctx, deletePlaylistQuery, sql.Named("playlist_id", id))
 		if err != nil {
 			return fmt.Errorf("sql query error: %w", err)
@@ -592,7 +795,10 @@ const selectPlaylistQuery = `
 		pl.public,
 		pl.created_at,
 		pl.updated_at,
-		COUNT(pt.track_id) as track_count,
+		pl.rules,
+		pl.fs_path,
+		pl.owner,
+		COUNT(pt.rowid) as track_count,
 		SUM(t.duration) as duration
 	FROM
 		playlists pl
@@ -605,8 +811,23 @@ const countPlaylistsQuery = `
 		COUNT(*) as cnt
 	FROM
 		playlists pl
+	WHERE
+		` + visibleCondition + `
 `
 
+// visibleCondition is an SQL condition for the playlists which are visible for
+// the user in the `@user` named argument. It must be kept in sync with
+// [Playlist.VisibleFor] and the shared playlists query of the shares package.
+const visibleCondition = `(
+	pl.public != 0 OR
+	pl.owner IS NULL OR
+	pl.owner = @user OR
+	EXISTS (
+		SELECT 1 FROM playlists_collaborators pc
+		WHERE pc.playlist_id = pl.id AND pc.username = @user
+	)
+)`
+
 func scanPlaylist(row rowScanner) (Playlist, error) {
 	var (
 		playlist    Playlist
@@ -614,13 +835,17 @@ func scanPlaylist(row rowScanner) (Playlist, error) {
 		public      int64
 		created     int64
 		updated     int64
+		rules       sql.NullString
+		filePath    sql.NullString
+		owner       sql.NullString
 		trackCount  sql.NullInt64
 		duration    sql.NullInt64
 	)
 
 	err := row.Scan(
 		&playlist.ID, &playlist.Name, &description,
-		&public, &created, &updated, &trackCount, &duration,
+		&public, &created, &updated, &rules, &filePath,
+		&owner, &trackCount, &duration,
 	)
 	if err != nil {
 		return Playlist{}, fmt.Errorf("error scanning playlist: %w", err)
@@ -634,6 +859,21 @@ func scanPlaylist(row rowScanner) (Playlist, error) {
 		playlist.Public = true
 	}
 
+	if rules.Valid {
+		playlist.Rules, err = decodeRules(rules.String)
+		if err != nil {
+			return Playlist{}, err
+		}
+	}
+
+	if filePath.Valid {
+		playlist.FilePath = filePath.String
+	}
+
+	if owner.Valid {
+		playlist.Owner = owner.String
+	}
+
 	if duration.Valid {
 		playlist.Duration = time.Duration(duration.Int64) * time.Millisecond
 	}
@@ -651,3 +891,143 @@ func scanPlaylist(row rowScanner) (Playlist, error) {
 type rowScanner interface {
 	Scan(dest ...any) error
 }
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// queryAccess returns the playlist with ID `id` with only the fields needed
+// for checking who may see and change it populated. Returns sql.ErrNoRows
+// when there is no such playlist.
+func queryAccess(ctx context.Context, db queryer, id int64) (Playlist, error) {
+	const accessQuery = `
+		SELECT
+			public,
+			owner,
+			rules,
+			fs_path
+		FROM
+			playlists
+		WHERE
+			id = @playlist_id
+	`
+
+	var (
+		playlist = Playlist{ID: id}
+		owner    sql.NullString
+		rules    sql.NullString
+		filePath sql.NullString
+	)
+
+	row := db.QueryRowContext(ctx, accessQuery, sql.Named("playlist_id", id))
+	if err := row.Scan(&playlist.Public, &owner, &rules, &filePath); err != nil {
+		return Playlist{}, err
+	}
+
+	playlist.Owner = owner.String
+	playlist.FilePath = filePath.String
+	if rules.Valid {
+		// Only whether the playlist is smart matters here so the rules are not
+		// decoded.
+		playlist.Rules = &SmartRules{}
+	}
+
+	collaborators, err := queryCollaborators(ctx, db, []int64{id})
+	if err != nil {
+		return Playlist{}, err
+	}
+	playlist.Collaborators = collaborators[id]
+
+	return playlist, nil
+}
+
+// queryCollaborators returns the collaborators for each of the playlists in
+// `playlistIDs`, sorted by their names.
+func queryCollaborators(
+	ctx context.Context,
+	db queryer,
+	playlistIDs []int64,
+) (map[int64][]string, error) {
+	collaborators := make(map[int64][]string)
+	if len(playlistIDs) == 0 {
+		return collaborators, nil
+	}
+
+	queryArgs := make([]any, 0, len(playlistIDs))
+	for _, id := range playlistIDs {
+		queryArgs = append(queryArgs, id)
+	}
+
+	query := `
+		SELECT
+			playlist_id,
+			username
+		FROM
+			playlists_collaborators
+		WHERE
+			playlist_id IN (` + strings.TrimSuffix(
+		strings.Repeat("?,", len(playlistIDs)), ",",
+	) + `)
+		ORDER BY
+			username
+	`
+
+	rows, err := db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query playlist collaborators: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			playlistID int64
+			username   string
+		)
+		if err := rows.Scan(&playlistID, &username); err != nil {
+			return nil, fmt.Errorf("failed to scan playlist collaborator: %w", err)
+		}
+
+		collaborators[playlistID] = append(collaborators[playlistID], username)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over playlist collaborators: %w", err)
+	}
+
+	return collaborators, nil
+}
+
+// insertCollaborators adds `users` as collaborators to a playlist. The owner
+// of the playlist and repeated users are skipped.
+func insertCollaborators(
+	ctx context.Context,
+	tx *sql.Tx,
+	playlistID int64,
+	owner string,
+	users []string,
+) error {
+	const insertQuery = `
+		INSERT INTO
+			playlists_collaborators (playlist_id, username)
+		VALUES
+			(@playlist_id, @username)
+		ON CONFLICT DO NOTHING
+	`
+
+	for _, user := range users {
+		if user == "" || user == owner {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, insertQuery,
+			sql.Named("playlist_id", playlistID),
+			sql.Named("username", user),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add playlist collaborator %s: %w", user, err)
+		}
+	}
+
+	return nil
+}
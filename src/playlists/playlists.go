@@ -3,6 +3,7 @@ package playlists
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/ironsmile/euterpe/src/library"
@@ -12,16 +13,17 @@ import (
 
 // Playlister is the interface for handling playlists in Euterpe.
 type Playlister interface {
-	// Get returns a single playlist by its ID.
-	Get(ctx context.Context, id int64) (Playlist, error)
+	// Get returns a single playlist by its ID. Playlists which are not visible
+	// for `user` are not found. See [Playlist.VisibleFor].
+	Get(ctx context.Context, id int64, user string) (Playlist, error)
 
-	// List returns a list playlists. Does not return the tracks associated with each
-	// playlist. Set both [args.Count] and [args.Offset] to zero in order to
-	// list all playlists at once.
+	// List returns a list playlists visible for [args.User]. Does not return the
+	// tracks associated with each playlist. Set both [args.Count] and
+	// [args.Offset] to zero in order to list all playlists at once.
 	List(ctx context.Context, args ListArgs) ([]Playlist, error)
 
-	// Count returns the count of all playlists available.
-	Count(ctx context.Context) (int64, error)
+	// Count returns the count of all playlists visible for `user`.
+	Count(ctx context.Context, user string) (int64, error)
 
 	// Create creates a new playlist with the given create arguments.
 	//
@@ -31,11 +33,19 @@ type Playlister interface {
 	// Update updates the playlist with ID `id` with the values
 	// given in `args`. Note that everything in args is optional
 	// and will not change the playlist if the zero value of the
-	// property is left.
+	// property is left. Only the owner and the collaborators of
+	// the playlist may update it.
 	Update(ctx context.Context, id int64, args UpdateArgs) error
 
-	// Delete removes a playlist by its `id`.
-	Delete(ctx context.Context, id int64) error
+	// Delete removes a playlist by its `id`. Only the owner of the
+	// playlist may delete it.
+	Delete(ctx context.Context, id int64, user string) error
+
+	// Import creates a new playlist out of the entries of a playlist file.
+	// Entries are matched to library tracks first by their file path and then
+	// by their artist, album and title. Entries which could not be matched are
+	// listed in the result.
+	Import(ctx context.Context, args ImportArgs) (ImportResult, error)
 }
 
 // Playlist represents a single playlist.
@@ -45,34 +55,115 @@ type Playlist struct {
 	Desc   string // Desc is a text which describes the playlist.
 	Public bool   // Public is true if the playlist will be visible for all users.
 
+	// Owner is the name of the user who created the playlist. It is empty for
+	// playlists without an owner which are visible and editable for everyone.
+	Owner string
+
+	// Collaborators are the users other than the owner who may edit the playlist.
+	Collaborators []string
+
 	Duration  time.Duration // Duration is the overall duration of the playlist.
 	CreatedAt time.Time     // CreatedAt is the time when this playlist was created.
 	UpdatedAt time.Time     // UpdatedAt is the time of the last update of the playlist.
 
 	// TracksCount is the number of tracks in this playlist. Relevant for when
-	// the playlist is returned without populated `Tracks`.
+	// the playlist is returned without populated `Tracks`. Entries for tracks
+	// which are missing from the library are counted too.
 	TracksCount int64
 
 	// Tracks is the which are added to this playlist. The slice is ordered by
 	// the tracks' explicit order in the playlist.
 	Tracks []library.TrackInfo
+
+	// Rules is set only for smart playlists. Their tracks are the ones which
+	// match the rules at the time of reading and could not be changed directly.
+	Rules *SmartRules
+
+	// FilePath is set only for playlists which are backed by a playlist file
+	// in one of the library directories. Such playlists follow the file and
+	// could not be changed or deleted directly.
+	FilePath string
+}
+
+// ReadOnly returns true when the tracks of the playlist could not be changed
+// directly. Such are the smart and file-backed playlists.
+func (p Playlist) ReadOnly() bool {
+	return p.Rules != nil || p.FilePath != ""
+}
+
+// VisibleFor returns true when `user` is allowed to see the playlist.
+func (p Playlist) VisibleFor(user string) bool {
+	return p.Public || p.EditableBy(user)
+}
+
+// EditableBy returns true when `user` is allowed to change the playlist.
+func (p Playlist) EditableBy(user string) bool {
+	return p.Owner == "" || p.Owner == user || slices.Contains(p.Collaborators, user)
 }
 
 // CreateArgs are the arguments needed for creating a playlist.
 type CreateArgs struct {
 	Name string // Name is the short name of the playlist. Required.
 
+	// Owner is the user who creates the playlist. When empty the playlist will
+	// be visible and editable for everyone.
+	Owner string
+
+	// Public makes the playlist visible for all users. Playlists are public
+	// when it is not set.
+	Public *bool
+
+	// Collaborators are users other than the owner who may edit the playlist.
+	Collaborators []string
+
 	// Description is an optional short text which explains more about the playlist.
 	Description string
 
 	// Tracks is an list of track IDs to be added in the playlist. May be left
 	// empty.
 	Tracks []int64
+
+	// Rules makes the new playlist a smart one. Tracks must be empty when it
+	// is set.
+	Rules *SmartRules
+}
+
+// ImportArgs are the arguments needed for creating a playlist out of a
+// playlist file.
+type ImportArgs struct {
+	Name string // Name is the short name of the playlist. Required.
+
+	// Description is an optional short text which explains more about the playlist.
+	Description string
+
+	// Owner is the user who imports the playlist. See [CreateArgs.Owner].
+	Owner string
+
+	// Entries are the tracks found in the playlist file.
+	Entries []FileEntry
+}
+
+// ImportResult describes the outcome of importing a playlist file.
+type ImportResult struct {
+	// PlaylistID is the unique ID of the newly created playlist.
+	PlaylistID int64
+
+	// Matched is the number of entries which were found in the library and
+	// added to the playlist.
+	Matched int
+
+	// Unmatched are the entries which were not found in the library. They are
+	// not part of the created playlist.
+	Unmatched []FileEntry
 }
 
 // UpdateArgs is all the possible arguments which could be updated
 // for a given playlist.
 type UpdateArgs struct {
+	// User is the one who updates the playlist. Only the owner of a playlist
+	// may change its Public and Collaborators fields.
+	User string
+
 	Name   string // Name is the new name of the playlist.
 	Desc   string // Desc sets the playlist description.
 	Public *bool  // Public sets the public field of the playlist.
@@ -91,6 +182,15 @@ type UpdateArgs struct {
 
 	// RemoveAllTracks causes all tracks of the playlist to be removed.
 	RemoveAllTracks bool
+
+	// Rules replaces the rules of a smart playlist. Setting it for a regular
+	// playlist turns it into a smart one and removes all of its tracks. It
+	// could not be combined with adding, removing or moving tracks.
+	Rules *SmartRules
+
+	// Collaborators replaces the list of users who may edit the playlist when
+	// it is not nil.
+	Collaborators *[]string
 }
 
 // MoveArgs defines a single move of a track from one position in the playlist
@@ -102,6 +202,10 @@ type MoveArgs struct {
 
 // ListArgs defines what portion of the playlists list will be returned.
 type ListArgs struct {
+	// User is the one for whom the list is for. Only playlists visible for
+	// them will be listed.
+	User string
+
 	// Offset is an index in the list of playlist from which to start the list.
 	Offset int64
 
@@ -112,3 +216,11 @@ type ListArgs struct {
 
 // ErrNotFound is returned when a playlist was not found for a given operation.
 var ErrNotFound = errors.New("playlist not found")
+
+// ErrReadOnly is returned when trying to change a playlist which could not be
+// changed directly. See [Playlist.ReadOnly].
+var ErrReadOnly = errors.New("playlist is read-only")
+
+// ErrNotAllowed is returned when a user tries to change a playlist which they
+// are not allowed to. See [Playlist.EditableBy].
+var ErrNotAllowed = errors.New("not allowed to change the playlist")
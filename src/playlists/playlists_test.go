@@ -26,7 +26,7 @@ func TestPlaylistsManagerCRUD(t *testing.T) {
 	}()
 	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
 
-	count, err := manager.Count(ctx)
+	count, err := manager.Count(ctx, "")
 	assert.NilErr(t, err, "getting playlists count")
 	assert.Equal(t, 0, count, "unexpected number of playlists")
 
@@ -51,7 +51,7 @@ func TestPlaylistsManagerCRUD(t *testing.T) {
 		UpdatedAt: time.Unix(now.Unix(), 0), // seconds precision in the db
 	}
 
-	playlist, err := manager.Get(ctx, id)
+	playlist, err := manager.Get(ctx, id, "")
 	assert.NilErr(t, err, "getting a single playlist")
 	assertPlaylist(t, expected, playlist)
 
@@ -72,11 +72,11 @@ func TestPlaylistsManagerCRUD(t *testing.T) {
 	expected.UpdatedAt = time.Unix(now.Unix(), 0)
 
 	// Get it again from the database and assert it has the new values.
-	playlist, err = manager.Get(ctx, playlist.ID)
+	playlist, err = manager.Get(ctx, playlist.ID, "")
 	assert.NilErr(t, err, "getting a single playlist")
 	assertPlaylist(t, expected, playlist)
 
-	count, err = manager.Count(ctx)
+	count, err = manager.Count(ctx, "")
 	assert.NilErr(t, err, "getting playlists count")
 	assert.Equal(t, 1, count, "number of playlists in the database")
 
@@ -93,10 +93,10 @@ func TestPlaylistsManagerCRUD(t *testing.T) {
 		)
 	}
 
-	err = manager.Delete(ctx, playlist.ID)
+	err = manager.Delete(ctx, playlist.ID, "")
 	assert.NilErr(t, err, "while deleting a playlist")
 
-	_, err = manager.Get(ctx, playlist.ID)
+	_, err = manager.Get(ctx, playlist.ID, "")
 	assert.NotNilErr(t, err, "expected 'not found' error for deleted playlist")
 
 	const listDescription = "some playlist description"
@@ -116,7 +116,7 @@ func TestPlaylistsManagerCRUD(t *testing.T) {
 		UpdatedAt: time.Unix(now.Unix(), 0), // seconds precision in the db
 	}
 
-	playlist, err = manager.Get(ctx, id)
+	playlist, err = manager.Get(ctx, id, "")
 	assert.NilErr(t, err, "getting a single playlist with description")
 	assertPlaylist(t, expected, playlist)
 }
@@ -132,7 +132,7 @@ func TestPlaylistsManagerNotFoundErrors(t *testing.T) {
 	}()
 	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
 
-	_, err := manager.Get(ctx, 123123)
+	_, err := manager.Get(ctx, 123123, "")
 	if !errors.Is(err, playlists.ErrNotFound) {
 		t.Fatalf("get: expected 'not found' error but got: %s", err)
 	}
@@ -142,12 +142,130 @@ func TestPlaylistsManagerNotFoundErrors(t *testing.T) {
 		t.Fatalf("update: expected 'not found' error but got: %s", err)
 	}
 
-	err = manager.Delete(ctx, 123123123)
+	err = manager.Delete(ctx, 123123123, "")
 	if !errors.Is(err, playlists.ErrNotFound) {
 		t.Fatalf("delete: expected 'not found' error but got: %s", err)
 	}
 }
 
+// TestPlaylistsManagerOwnership checks that playlists are visible only for the
+// users who are allowed to see them and that only their owners and collaborators
+// may change them.
+func TestPlaylistsManagerOwnership(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+
+	private := false
+	privateID, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:          "private",
+		Owner:         "alice",
+		Public:        &private,
+		Collaborators: []string{"bob", "alice", "bob"},
+	})
+	assert.NilErr(t, err, "creating private playlist")
+
+	publicID, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:  "public",
+		Owner: "alice",
+	})
+	assert.NilErr(t, err, "creating public playlist")
+
+	sharedID, err := manager.Create(ctx, playlists.CreateArgs{Name: "shared"})
+	assert.NilErr(t, err, "creating playlist without an owner")
+
+	for user, expected := range map[string]int64{"alice": 3, "bob": 3, "carol": 2} {
+		count, err := manager.Count(ctx, user)
+		assert.NilErr(t, err, "getting playlists count for %s", user)
+		assert.Equal(t, expected, count, "wrong playlists count for %s", user)
+
+		listed, err := manager.List(ctx, playlists.ListArgs{User: user})
+		assert.NilErr(t, err, "listing playlists for %s", user)
+		assert.Equal(t, expected, int64(len(listed)), "wrong listed playlists for %s", user)
+	}
+
+	playlist, err := manager.Get(ctx, privateID, "bob")
+	assert.NilErr(t, err, "getting private playlist as a collaborator")
+	assert.Equal(t, "alice", playlist.Owner, "wrong playlist owner")
+	assert.Equal(t, false, playlist.Public, "wrong public flag")
+	assert.Equal(t, 1, len(playlist.Collaborators), "wrong number of collaborators")
+	assert.Equal(t, "bob", playlist.Collaborators[0], "wrong collaborator")
+
+	_, err = manager.Get(ctx, privateID, "carol")
+	if !errors.Is(err, playlists.ErrNotFound) {
+		t.Errorf("expected private playlist not to be found but got: %v", err)
+	}
+
+	err = manager.Update(ctx, privateID, playlists.UpdateArgs{
+		User: "carol",
+		Name: "stolen",
+	})
+	if !errors.Is(err, playlists.ErrNotFound) {
+		t.Errorf("expected not found error for invisible playlist but got: %v", err)
+	}
+
+	err = manager.Update(ctx, publicID, playlists.UpdateArgs{
+		User: "carol",
+		Name: "stolen",
+	})
+	if !errors.Is(err, playlists.ErrNotAllowed) {
+		t.Errorf("expected not allowed error for updating but got: %v", err)
+	}
+
+	err = manager.Delete(ctx, publicID, "carol")
+	if !errors.Is(err, playlists.ErrNotAllowed) {
+		t.Errorf("expected not allowed error for deleting but got: %v", err)
+	}
+
+	err = manager.Update(ctx, sharedID, playlists.UpdateArgs{
+		User: "carol",
+		Name: "shared by carol",
+	})
+	assert.NilErr(t, err, "updating playlist without an owner")
+
+	err = manager.Update(ctx, privateID, playlists.UpdateArgs{
+		User: "bob",
+		Name: "edited by bob",
+	})
+	assert.NilErr(t, err, "updating playlist as a collaborator")
+
+	public := true
+	err = manager.Update(ctx, privateID, playlists.UpdateArgs{
+		User:   "bob",
+		Public: &public,
+	})
+	if !errors.Is(err, playlists.ErrNotAllowed) {
+		t.Errorf("expected collaborator not to be allowed to change access: %v", err)
+	}
+
+	err = manager.Delete(ctx, privateID, "bob")
+	if !errors.Is(err, playlists.ErrNotAllowed) {
+		t.Errorf("expected collaborator not to be allowed to delete: %v", err)
+	}
+
+	err = manager.Update(ctx, privateID, playlists.UpdateArgs{
+		User:          "alice",
+		Collaborators: &[]string{"carol"},
+	})
+	assert.NilErr(t, err, "changing collaborators")
+
+	playlist, err = manager.Get(ctx, privateID, "carol")
+	assert.NilErr(t, err, "getting private playlist as a new collaborator")
+	assert.Equal(t, "edited by bob", playlist.Name, "wrong playlist name")
+
+	_, err = manager.Get(ctx, privateID, "bob")
+	if !errors.Is(err, playlists.ErrNotFound) {
+		t.Errorf("expected removed collaborator not to find the playlist: %v", err)
+	}
+
+	err = manager.Delete(ctx, privateID, "alice")
+	assert.NilErr(t, err, "deleting playlist as its owner")
+}
+
 // TestPlaylistsManagerSongOperations checks that adding, moving and removing
 // songs from a playlist work.
 func TestPlaylistsManagerSongOperations(t *testing.T) {
@@ -190,7 +308,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 	playlistID, err := manager.Create(ctx, createArgs)
 	assert.NilErr(t, err, "failed while creating a playlist with all tracks")
 
-	playlist, err := manager.Get(ctx, playlistID)
+	playlist, err := manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting newly inserted playlist")
 	assertTracks(t, trackIDs, playlist)
 
@@ -200,7 +318,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 	})
 	assert.NilErr(t, err, "removing all tracks from a playlist")
 
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "getting playlist after tracks removal")
 	assert.Equal(t, 0, len(playlist.Tracks), "wrong number of tracks after removal")
 	assert.Equal(t, 0, playlist.TracksCount, "inconsistent .TracksCount")
@@ -211,7 +329,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 	})
 	assert.NilErr(t, err, "adding tracks with .Update()")
 
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting newly updated playlist")
 	assertTracks(t, trackIDs, playlist)
 
@@ -226,7 +344,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 		RemoveTracks: []int64{1},
 	})
 	assert.NilErr(t, err, "removing a single track from the library")
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting playlist after removing tracks")
 	assertTracks(t, currentTracks, playlist)
 
@@ -241,14 +359,14 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 		},
 	})
 	assert.NilErr(t, err, "while moving tracks around")
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting playlist after moving tracks")
 	assertTracks(t, currentTracks, playlist)
 
 	// Make sure empty update operation is a no-opt.
 	err = manager.Update(ctx, playlistID, playlists.UpdateArgs{})
 	assert.NilErr(t, err, "while doing a no-opt")
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting playlist after no-opt update")
 	assertTracks(t, currentTracks, playlist)
 
@@ -262,7 +380,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 		},
 	})
 	assert.NilErr(t, err, "while doing moving from index to the same index")
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting playlist after moving tracks")
 	assertTracks(t, currentTracks, playlist)
 
@@ -274,7 +392,7 @@ func TestPlaylistsManagerSongOperations(t *testing.T) {
 	})
 	assert.NilErr(t, err, "while appending a track to the end of the list")
 
-	playlist, err = manager.Get(ctx, playlistID)
+	playlist, err = manager.Get(ctx, playlistID, "")
 	assert.NilErr(t, err, "failed while getting playlist after moving tracks")
 	assertTracks(t, currentTracks, playlist)
 }
@@ -9,10 +9,11 @@ import (
 )
 
 type FakePlaylister struct {
-	CountStub        func(context.Context) (int64, error)
+	CountStub        func(context.Context, string) (int64, error)
 	countMutex       sync.RWMutex
 	countArgsForCall []struct {
 		arg1 context.Context
+		arg2 string
 	}
 	countReturns struct {
 		result1 int64
@@ -36,11 +37,12 @@ type FakePlaylister struct {
 		result1 int64
 		result2 error
 	}
-	DeleteStub        func(context.Context, int64) error
+	DeleteStub        func(context.Context, int64, string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
 		arg1 context.Context
 		arg2 int64
+		arg3 string
 	}
 	deleteReturns struct {
 		result1 error
@@ -48,11 +50,12 @@ type FakePlaylister struct {
 	deleteReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetStub        func(context.Context, int64) (playlists.Playlist, error)
+	GetStub        func(context.Context, int64, string) (playlists.Playlist, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
 		arg1 context.Context
 		arg2 int64
+		arg3 string
 	}
 	getReturns struct {
 		result1 playlists.Playlist
@@ -62,6 +65,20 @@ type FakePlaylister struct {
 		result1 playlists.Playlist
 		result2 error
 	}
+	ImportStub        func(context.Context, playlists.ImportArgs) (playlists.ImportResult, error)
+	importMutex       sync.RWMutex
+	importArgsForCall []struct {
+		arg1 context.Context
+		arg2 playlists.ImportArgs
+	}
+	importReturns struct {
+		result1 playlists.ImportResult
+		result2 error
+	}
+	importReturnsOnCall map[int]struct {
+		result1 playlists.ImportResult
+		result2 error
+	}
 	ListStub        func(context.Context, playlists.ListArgs) ([]playlists.Playlist, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
@@ -93,18 +110,19 @@ type FakePlaylister struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakePlaylister) Count(arg1 context.Context) (int64, error) {
+func (fake *FakePlaylister) Count(arg1 context.Context, arg2 string) (int64, error) {
 	fake.countMutex.Lock()
 	ret, specificReturn := fake.countReturnsOnCall[len(fake.countArgsForCall)]
 	fake.countArgsForCall = append(fake.countArgsForCall, struct {
 		arg1 context.Context
-	}{arg1})
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.CountStub
 	fakeReturns := fake.countReturns
-	fake.recordInvocation("Count", []interface{}{arg1})
+	fake.recordInvocation("Count", []interface{}{arg1, arg2})
 	fake.countMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -118,17 +136,17 @@ func (fake *FakePlaylister) CountCallCount() int {
 	return len(fake.countArgsForCall)
 }
 
-func (fake *FakePlaylister) CountCalls(stub func(context.Context) (int64, error)) {
+func (fake *FakePlaylister) CountCalls(stub func(context.Context, string) (int64, error)) {
 	fake.countMutex.Lock()
 	defer fake.countMutex.Unlock()
 	fake.CountStub = stub
 }
 
-func (fake *FakePlaylister) CountArgsForCall(i int) context.Context {
+func (fake *FakePlaylister) CountArgsForCall(i int) (context.Context, string) {
 	fake.countMutex.RLock()
 	defer fake.countMutex.RUnlock()
 	argsForCall := fake.countArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakePlaylister) CountReturns(result1 int64, result2 error) {
@@ -222,19 +240,20 @@ func (fake *FakePlaylister) CreateReturnsOnCall(i int, result1 int64, result2 er
 	}{result1, result2}
 }
 
-func (fake *FakePlaylister) Delete(arg1 context.Context, arg2 int64) error {
+func (fake *FakePlaylister) Delete(arg1 context.Context, arg2 int64, arg3 string) error {
 	fake.deleteMutex.Lock()
 	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
 		arg1 context.Context
 		arg2 int64
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.DeleteStub
 	fakeReturns := fake.deleteReturns
-	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2, arg3})
 	fake.deleteMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -248,17 +267,17 @@ func (fake *FakePlaylister) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
-func (fake *FakePlaylister) DeleteCalls(stub func(context.Context, int64) error) {
+func (fake *FakePlaylister) DeleteCalls(stub func(context.Context, int64, string) error) {
 	fake.deleteMutex.Lock()
 	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = stub
 }
 
-func (fake *FakePlaylister) DeleteArgsForCall(i int) (context.Context, int64) {
+func (fake *FakePlaylister) DeleteArgsForCall(i int) (context.Context, int64, string) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	argsForCall := fake.deleteArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakePlaylister) DeleteReturns(result1 error) {
@@ -284,19 +303,20 @@ func (fake *FakePlaylister) DeleteReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakePlaylister) Get(arg1 context.Context, arg2 int64) (playlists.Playlist, error) {
+func (fake *FakePlaylister) Get(arg1 context.Context, arg2 int64, arg3 string) (playlists.Playlist, error) {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
 		arg1 context.Context
 		arg2 int64
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.GetStub
 	fakeReturns := fake.getReturns
-	fake.recordInvocation("Get", []interface{}{arg1, arg2})
+	fake.recordInvocation("Get", []interface{}{arg1, arg2, arg3})
 	fake.getMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -310,17 +330,17 @@ func (fake *FakePlaylister) GetCallCount() int {
 	return len(fake.getArgsForCall)
 }
 
-func (fake *FakePlaylister) GetCalls(stub func(context.Context, int64) (playlists.Playlist, error)) {
+func (fake *FakePlaylister) GetCalls(stub func(context.Context, int64, string) (playlists.Playlist, error)) {
 	fake.getMutex.Lock()
 	defer fake.getMutex.Unlock()
 	fake.GetStub = stub
 }
 
-func (fake *FakePlaylister) GetArgsForCall(i int) (context.Context, int64) {
+func (fake *FakePlaylister) GetArgsForCall(i int) (context.Context, int64, string) {
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
 	argsForCall := fake.getArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakePlaylister) GetReturns(result1 playlists.Playlist, result2 error) {
@@ -349,6 +369,71 @@ func (fake *FakePlaylister) GetReturnsOnCall(i int, result1 playlists.Playlist,
 	}{result1, result2}
 }
 
+func (fake *FakePlaylister) Import(arg1 context.Context, arg2 playlists.ImportArgs) (playlists.ImportResult, error) {
+	fake.importMutex.Lock()
+	ret, specificReturn := fake.importReturnsOnCall[len(fake.importArgsForCall)]
+	fake.importArgsForCall = append(fake.importArgsForCall, struct {
+		arg1 context.Context
+		arg2 playlists.ImportArgs
+	}{arg1, arg2})
+	stub := fake.ImportStub
+	fakeReturns := fake.importReturns
+	fake.recordInvocation("Import", []interface{}{arg1, arg2})
+	fake.importMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePlaylister) ImportCallCount() int {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	return len(fake.importArgsForCall)
+}
+
+func (fake *FakePlaylister) ImportCalls(stub func(context.Context, playlists.ImportArgs) (playlists.ImportResult, error)) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = stub
+}
+
+func (fake *FakePlaylister) ImportArgsForCall(i int) (context.Context, playlists.ImportArgs) {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	argsForCall := fake.importArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakePlaylister) ImportReturns(result1 playlists.ImportResult, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	fake.importReturns = struct {
+		result1 playlists.ImportResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePlaylister) ImportReturnsOnCall(i int, result1 playlists.ImportResult, result2 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	if fake.importReturnsOnCall == nil {
+		fake.importReturnsOnCall = make(map[int]struct {
+			result1 playlists.ImportResult
+			result2 error
+		})
+	}
+	fake.importReturnsOnCall[i] = struct {
+		result1 playlists.ImportResult
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakePlaylister) List(arg1 context.Context, arg2 playlists.ListArgs) ([]playlists.Playlist, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
@@ -488,6 +573,8 @@ func (fake *FakePlaylister) Invocations() map[string][][]interface{} {
 	defer fake.deleteMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
 	fake.updateMutex.RLock()
@@ -0,0 +1,485 @@
+package playlists
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// SmartRules define which tracks are part of a smart playlist. They are stored
+// with the playlist and evaluated every time its tracks are read. So smart
+// playlists are always up to date with the library and the listening history.
+type SmartRules struct {
+	// Match is the root of the rules tree. Tracks which satisfy it are part of
+	// the playlist. A rule without conditions matches all tracks.
+	Match Rule `json:"match"`
+
+	// Sort is the field by which tracks are ordered. Besides the fields which
+	// could be used in rules it could be "random". When empty tracks are
+	// ordered by artist, album and track number.
+	Sort string `json:"sort,omitempty"`
+
+	// Descending reverses the sort order.
+	Descending bool `json:"descending,omitempty"`
+
+	// Limit is the maximum number of tracks in the playlist. Zero means there
+	// is no limit.
+	Limit int64 `json:"limit,omitempty"`
+}
+
+// Rule is a node in the smart playlist rules tree. It is either a group of
+// rules or a single condition for a track field. In groups the rules in All
+// must all match while from the rules in Any at least one must match.
+type Rule struct {
+	All []Rule `json:"all,omitempty"`
+	Any []Rule `json:"any,omitempty"`
+
+	// Field is the track property which is checked by this condition. Text
+	// fields are "title", "artist", "album" and "path". Numeric fields are
+	// "year", "track", "duration" (in seconds), "bitrate", "rating" and
+	// "play_count". Time fields are "added" and "last_played". The only
+	// boolean field is "favourite".
+	Field string `json:"field,omitempty"`
+
+	// Operator is the comparison between the track field and Value.
+	Operator Operator `json:"operator,omitempty"`
+
+	// Value is what the field is compared against. It is a string for text
+	// fields, a number for numeric fields and a boolean for "favourite". For
+	// times it is a number of days or a period name for [OpInCurrent].
+	Value any `json:"value,omitempty"`
+}
+
+// Operator is a comparison used in smart playlist rules.
+type Operator string
+
+// All the operators supported in smart playlist rules.
+const (
+	OpIs          Operator = "is"
+	OpIsNot       Operator = "is_not"
+	OpContains    Operator = "contains"
+	OpNotContains Operator = "not_contains"
+	OpStartsWith  Operator = "starts_with"
+	OpEndsWith    Operator = "ends_with"
+	OpGreater     Operator = "gt"
+	OpLess        Operator = "lt"
+
+	// OpInLast matches times within the last Value days.
+	OpInLast Operator = "in_last"
+
+	// OpNotInLast matches times before the last Value days and also missing
+	// times. E.g. tracks which were never played.
+	OpNotInLast Operator = "not_in_last"
+
+	// OpInCurrent matches times in the current "day", "week", "month" or
+	// "year" according to the local time.
+	OpInCurrent Operator = "in_current"
+)
+
+// ErrInvalidRules is returned when smart playlist rules could not be evaluated.
+var ErrInvalidRules = errors.New("invalid smart playlist rules")
+
+// ErrSmartPlaylist is returned when trying to change the tracks of a smart
+// playlist. Its tracks are always determined by its rules. It wraps ErrReadOnly.
+var ErrSmartPlaylist = fmt.Errorf("%w: smart playlist tracks cannot be changed",
+	ErrReadOnly)
+
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindNumber
+	kindTime
+	kindBool
+)
+
+// smartField is a track property which could be used in smart playlist rules.
+type smartField struct {
+	expr string // expr is the SQL expression for this field.
+	kind fieldKind
+}
+
+// smartFields maps the field names used in rules to their SQL expressions. The
+// table aliases are the ones used by library.QueryTracks.
+var smartFields = map[string]smartField{
+	"title":       {expr: "COALESCE(t.name, '')", kind: kindText},
+	"artist":      {expr: "COALESCE(at.name, '')", kind: kindText},
+	"album":       {expr: "COALESCE(al.name, '')", kind: kindText},
+	"path":        {expr: "COALESCE(t.fs_path, '')", kind: kindText},
+	"year":        {expr: "COALESCE(t.year, 0)", kind: kindNumber},
+	"track":       {expr: "COALESCE(t.number, 0)", kind: kindNumber},
+	"duration":    {expr: "COALESCE(t.duration, 0) / 1000", kind: kindNumber},
+	"bitrate":     {expr: "COALESCE(t.bitrate, 0)", kind: kindNumber},
+	"rating":      {expr: "COALESCE(us.user_rating, 0)", kind: kindNumber},
+	"play_count":  {expr: "COALESCE(us.play_count, 0)", kind: kindNumber},
+	"favourite":   {expr: "(us.favourite IS NOT NULL)", kind: kindBool},
+	"last_played": {expr: "us.last_played", kind: kindTime},
+	"added":       {expr: "t.created_at", kind: kindTime},
+}
+
+const defaultSmartOrder = "at.name, al.name, t.number, t.id"
+
+// smartQuery is the compiled SQL form of smart playlist rules.
+type smartQuery struct {
+	where   string
+	orderBy string
+	args    []any
+}
+
+// compileRules converts the rules to an SQL query condition. Times in the rules
+// are relative to `now`.
+func compileRules(rules SmartRules, now time.Time) (smartQuery, error) {
+	c := &rulesCompiler{now: now}
+
+	where, err := c.compile(rules.Match, "match")
+	if err != nil {
+		return smartQuery{}, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+
+	if rules.Limit < 0 {
+		return smartQuery{}, fmt.Errorf("%w: negative limit", ErrInvalidRules)
+	}
+
+	orderBy := defaultSmartOrder
+	if rules.Sort == "random" {
+		orderBy = "RANDOM()"
+	} else if rules.Sort != "" {
+		field, ok := smartFields[rules.Sort]
+		if !ok {
+			return smartQuery{}, fmt.Errorf("%w: unknown sort field %q",
+				ErrInvalidRules, rules.Sort)
+		}
+		orderBy = field.expr
+		if rules.Descending {
+			orderBy += " DESC"
+		}
+		orderBy += ", t.id"
+	} else if rules.Descending {
+		orderBy = "at.name DESC, al.name DESC, t.number DESC, t.id DESC"
+	}
+
+	args := c.args
+	if rules.Limit > 0 {
+		args = append(args,
+			sql.Named("offset", 0),
+			sql.Named("count", rules.Limit),
+		)
+	}
+
+	return smartQuery{
+		where:   where,
+		orderBy: orderBy,
+		args:    args,
+	}, nil
+}
+
+type rulesCompiler struct {
+	now  time.Time
+	args []any
+}
+
+// arg adds a query argument and returns its placeholder.
+func (c *rulesCompiler) arg(val any) string {
+	name := fmt.Sprintf("smart_arg_%d", len(c.args))
+	c.args = append(c.args, sql.Named(name, val))
+	return "@" + name
+}
+
+func (c *rulesCompiler) compile(rule Rule, path string) (string, error) {
+	isGroup := len(rule.All) > 0 || len(rule.Any) > 0
+	if isGroup && rule.Field != "" {
+		return "", fmt.Errorf("%s: rule is both a group and a condition", path)
+	}
+
+	if rule.Field != "" {
+		cond, err := c.compileCondition(rule)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return cond, nil
+	}
+
+	if rule.Operator != "" || rule.Value != nil {
+		return "", fmt.Errorf("%s: field is required", path)
+	}
+
+	var conditions []string
+	for ind, sub := range rule.All {
+		cond, err := c.compile(sub, fmt.Sprintf("%s.all[%d]", path, ind))
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(rule.Any) > 0 {
+		var anyConds []string
+		for ind, sub := range rule.Any {
+			cond, err := c.compile(sub, fmt.Sprintf("%s.any[%d]", path, ind))
+			if err != nil {
+				return "", err
+			}
+			anyConds = append(anyConds, cond)
+		}
+		conditions = append(conditions, "("+strings.Join(anyConds, " OR ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "1", nil
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", nil
+}
+
+func (c *rulesCompiler) compileCondition(rule Rule) (string, error) {
+	field, ok := smartFields[rule.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown field %q", rule.Field)
+	}
+
+	unsupported := fmt.Errorf("operator %q is not supported for %q",
+		rule.Operator, rule.Field)
+
+	switch field.kind {
+	case kindText:
+		val, ok := rule.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("value for %q must be a string", rule.Field)
+		}
+
+		switch rule.Operator {
+		case OpIs:
+			return field.expr + " = " + c.arg(val) + " COLLATE NOCASE", nil
+		case OpIsNot:
+			return field.expr + " != " + c.arg(val) + " COLLATE NOCASE", nil
+		case OpContains:
+			return field.expr + " LIKE " + c.arg("%"+escapeLike(val)+"%") +
+				` ESCAPE '\'`, nil
+		case OpNotContains:
+			return field.expr + " NOT LIKE " + c.arg("%"+escapeLike(val)+"%") +
+				` ESCAPE '\'`, nil
+		case OpStartsWith:
+			return field.expr + " LIKE " + c.arg(escapeLike(val)+"%") +
+				` ESCAPE '\'`, nil
+		case OpEndsWith:
+			return field.expr + " LIKE " + c.arg("%"+escapeLike(val)) +
+				` ESCAPE '\'`, nil
+		}
+	case kindNumber:
+		val, err := ruleNumber(rule.Value)
+		if err != nil {
+			return "", fmt.Errorf("value for %q: %w", rule.Field, err)
+		}
+
+		switch rule.Operator {
+		case OpIs:
+			return field.expr + " = " + c.arg(val), nil
+		case OpIsNot:
+			return field.expr + " != " + c.arg(val), nil
+		case OpGreater:
+			return field.expr + " > " + c.arg(val), nil
+		case OpLess:
+			return field.expr + " < " + c.arg(val), nil
+		}
+	case kindBool:
+		val, ok := rule.Value.(bool)
+		if !ok {
+			return "", fmt.Errorf("value for %q must be a boolean", rule.Field)
+		}
+
+		switch rule.Operator {
+		case OpIs:
+			return field.expr + " = " + c.arg(val), nil
+		case OpIsNot:
+			return field.expr + " != " + c.arg(val), nil
+		}
+	case kindTime:
+		if rule.Operator == OpInCurrent {
+			period, _ := rule.Value.(string)
+			start, err := periodStart(c.now, period)
+			if err != nil {
+				return "", fmt.Errorf("value for %q: %w", rule.Field, err)
+			}
+			return field.expr + " >= " + c.arg(start.Unix()), nil
+		}
+
+		days, err := ruleNumber(rule.Value)
+		if err != nil {
+			return "", fmt.Errorf("value for %q: %w", rule.Field, err)
+		}
+		cutoff := c.now.Add(-time.Duration(days*24) * time.Hour).Unix()
+
+		switch rule.Operator {
+		case OpInLast:
+			return field.expr + " >= " + c.arg(cutoff), nil
+		case OpNotInLast:
+			return fmt.Sprintf("(%s IS NULL OR %s < %s)",
+				field.expr, field.expr, c.arg(cutoff)), nil
+		}
+	}
+
+	return "", unsupported
+}
+
+// ruleNumber converts a rule value to an integer. JSON numbers are decoded as
+// float64 so both are accepted.
+func ruleNumber(val any) (int64, error) {
+	switch num := val.(type) {
+	case int:
+		return int64(num), nil
+	case int64:
+		return num, nil
+	case float64:
+		if num != math.Trunc(num) {
+			return 0, fmt.Errorf("%v is not an integer", num)
+		}
+		return int64(num), nil
+	case json.Number:
+		return num.Int64()
+	}
+
+	return 0, fmt.Errorf("must be a number")
+}
+
+// periodStart returns the start of the current `period` for the time `now`.
+func periodStart(now time.Time, period string) (time.Time, error) {
+	year, month, day := now.Date()
+	switch period {
+	case "day":
+		return time.Date(year, month, day, 0, 0, 0, 0, now.Location()), nil
+	case "week":
+		// Weeks start on Monday.
+		offset := (int(now.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, now.Location()), nil
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, now.Location()), nil
+	case "year":
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	return time.Time{}, fmt.Errorf(
+		`period must be one of "day", "week", "month" or "year"`,
+	)
+}
+
+// encodeRules validates the rules and returns them in the form in which they
+// are stored in the database.
+func encodeRules(rules SmartRules) (string, error) {
+	if _, err := compileRules(rules, time.Now()); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return "", fmt.Errorf("encoding rules: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+// decodeRules parses rules stored in the database.
+func decodeRules(stored string) (*SmartRules, error) {
+	var rules SmartRules
+	if err := json.Unmarshal([]byte(stored), &rules); err != nil {
+		return nil, fmt.Errorf("decoding smart playlist rules: %w", err)
+	}
+
+	return &rules, nil
+}
+
+// querySmartTracks returns the tracks which currently match the rules.
+func querySmartTracks(
+	ctx context.Context,
+	db *sql.DB,
+	rules SmartRules,
+) ([]library.TrackInfo, error) {
+	query, err := compileRules(rules, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := library.QueryTracks(
+		ctx,
+		db,
+		[]string{query.where},
+		query.orderBy,
+		query.args,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting tracks for smart playlist: %w", err)
+	}
+	defer rows.Close()
+
+	var tracks []library.TrackInfo
+	for rows.Next() {
+		track, err := library.ScanTrack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning a track: %w", err)
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	return tracks, rows.Err()
+}
+
+// smartStatsQuery returns the number of tracks and their duration for a smart
+// playlist query.
+const smartStatsQuery = `
+	SELECT
+		COUNT(*),
+		SUM(duration)
+	FROM (
+		SELECT
+			t.duration as duration
+		FROM
+			tracks as t
+				LEFT JOIN albums as al ON al.id = t.album_id
+				LEFT JOIN artists as at ON at.id = t.artist_id
+				LEFT JOIN user_stats as us ON us.track_id = t.id
+		WHERE
+			%s
+		ORDER BY
+			%s
+		%s
+	)
+`
+
+// setSmartStats sets the tracks count and duration of a smart playlist without
+// reading all of its tracks.
+func setSmartStats(ctx context.Context, db *sql.DB, playlist *Playlist) error {
+	query, err := compileRules(*playlist.Rules, time.Now())
+	if err != nil {
+		return err
+	}
+
+	limit := ""
+	if playlist.Rules.Limit > 0 {
+		limit = "LIMIT @offset, @count"
+	}
+
+	var (
+		count    sql.NullInt64
+		duration sql.NullInt64
+	)
+	row := db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(smartStatsQuery, query.where, query.orderBy, limit),
+		query.args...,
+	)
+	if err := row.Scan(&count, &duration); err != nil {
+		return fmt.Errorf("error counting smart playlist tracks: %w", err)
+	}
+
+	playlist.TracksCount = count.Int64
+	playlist.Duration = time.Duration(duration.Int64) * time.Millisecond
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package playlists_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// TestSmartPlaylists checks that the tracks of smart playlists are evaluated
+// from their rules over the library and the user stats every time they are read.
+func TestSmartPlaylists(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	trackIDs := make(map[string]int64)
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: "", Count: 100}) {
+		trackIDs[track.Title] = track.ID
+	}
+	if len(trackIDs) < 3 {
+		t.Fatalf("not enough tracks found in the library for smart playlists")
+	}
+
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+
+	// "4+ stars not played in 90 days"
+	rated := playlists.SmartRules{
+		Match: playlists.Rule{
+			All: []playlists.Rule{
+				{Field: "rating", Operator: playlists.OpGreater, Value: 3},
+				{Field: "last_played", Operator: playlists.OpNotInLast, Value: 90},
+			},
+		},
+	}
+	ratedID, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:  "Forgotten Favourites",
+		Rules: &rated,
+	})
+	assert.NilErr(t, err, "creating smart playlist")
+
+	ratedPlaylist, err := manager.Get(ctx, ratedID, "")
+	assert.NilErr(t, err, "getting smart playlist")
+	assertTracks(t, nil, ratedPlaylist)
+	if ratedPlaylist.Rules == nil {
+		t.Fatalf("smart playlist was returned without its rules")
+	}
+	assert.Equal(t, 2, len(ratedPlaylist.Rules.Match.All), "stored rules")
+
+	assert.NilErr(t, lib.SetTrackRating(ctx, trackIDs["Payback"], 5), "rating")
+	assert.NilErr(t, lib.SetTrackRating(ctx, trackIDs["Another One"], 4), "rating")
+	assert.NilErr(t, lib.SetTrackRating(ctx, trackIDs["Tittled Track"], 2), "rating")
+
+	ratedPlaylist, err = manager.Get(ctx, ratedID, "")
+	assert.NilErr(t, err, "getting smart playlist after rating")
+	assertTracks(t, []int64{trackIDs["Another One"], trackIDs["Payback"]}, ratedPlaylist)
+
+	err = lib.RecordTrackPlay(ctx, trackIDs["Payback"], time.Now())
+	assert.NilErr(t, err, "recording track play")
+
+	ratedPlaylist, err = manager.Get(ctx, ratedID, "")
+	assert.NilErr(t, err, "getting smart playlist after playing")
+	assertTracks(t, []int64{trackIDs["Another One"]}, ratedPlaylist)
+	assert.Equal(t, 1, ratedPlaylist.TracksCount, "tracks count")
+
+	// Groups with OR, sorting and limits.
+	anyRules := playlists.SmartRules{
+		Match: playlists.Rule{
+			Any: []playlists.Rule{
+				{Field: "artist", Operator: playlists.OpIs, Value: "buggy bugoff"},
+				{Field: "title", Operator: playlists.OpStartsWith, Value: "Tittled"},
+			},
+		},
+		Sort:       "title",
+		Descending: true,
+	}
+	anyID, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:  "Any Of",
+		Rules: &anyRules,
+	})
+	assert.NilErr(t, err, "creating smart playlist with OR")
+
+	anyPlaylist, err := manager.Get(ctx, anyID, "")
+	assert.NilErr(t, err, "getting smart playlist with OR")
+	assertTracks(t, []int64{trackIDs["Tittled Track"], trackIDs["Payback"]}, anyPlaylist)
+
+	anyRules.Limit = 1
+	err = manager.Update(ctx, anyID, playlists.UpdateArgs{Rules: &anyRules})
+	assert.NilErr(t, err, "updating smart playlist rules")
+
+	anyPlaylist, err = manager.Get(ctx, anyID, "")
+	assert.NilErr(t, err, "getting smart playlist with limit")
+	assertTracks(t, []int64{trackIDs["Tittled Track"]}, anyPlaylist)
+
+	// Smart playlists in lists have their tracks count and duration.
+	list, err := manager.List(ctx, playlists.ListArgs{})
+	assert.NilErr(t, err, "listing playlists")
+	assert.Equal(t, 2, len(list), "number of playlists")
+	for _, pl := range list {
+		if pl.Rules == nil {
+			t.Errorf("playlist %d listed without its rules", pl.ID)
+		}
+		assert.Equal(t, 1, pl.TracksCount, "tracks count for playlist %d", pl.ID)
+	}
+
+	// Tracks of smart playlists cannot be changed directly.
+	err = manager.Update(ctx, anyID, playlists.UpdateArgs{
+		AddTracks: []int64{trackIDs["Payback"]},
+	})
+	if !errors.Is(err, playlists.ErrSmartPlaylist) {
+		t.Errorf("expected smart playlist error for adding tracks but got: %v", err)
+	}
+
+	err = manager.Update(ctx, anyID, playlists.UpdateArgs{Name: "Renamed"})
+	assert.NilErr(t, err, "renaming smart playlist")
+
+	// Regular playlists become smart when rules are set.
+	regularID, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:   "Regular",
+		Tracks: []int64{trackIDs["Payback"], trackIDs["Another One"]},
+	})
+	assert.NilErr(t, err, "creating regular playlist")
+
+	allRules := playlists.SmartRules{Sort: "title"}
+	err = manager.Update(ctx, regularID, playlists.UpdateArgs{Rules: &allRules})
+	assert.NilErr(t, err, "turning regular playlist into a smart one")
+
+	converted, err := manager.Get(ctx, regularID, "")
+	assert.NilErr(t, err, "getting converted playlist")
+	assert.Equal(t, len(trackIDs), len(converted.Tracks), "all tracks match")
+}
+
+// TestSmartPlaylistsInvalidRules checks that rules which could not be evaluated
+// are rejected.
+func TestSmartPlaylistsInvalidRules(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+	manager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+
+	tests := []struct {
+		desc  string
+		rules playlists.SmartRules
+	}{
+		{
+			desc: "unknown field",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Field: "mood", Operator: playlists.OpIs, Value: "happy",
+			}},
+		},
+		{
+			desc: "unsupported operator",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Field: "title", Operator: playlists.OpGreater, Value: "a",
+			}},
+		},
+		{
+			desc: "wrong value type",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Field: "rating", Operator: playlists.OpIs, Value: "five",
+			}},
+		},
+		{
+			desc: "unknown period",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Field: "added", Operator: playlists.OpInCurrent, Value: "decade",
+			}},
+		},
+		{
+			desc: "group and condition",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Field:    "year",
+				Operator: playlists.OpIs,
+				Value:    2000,
+				All: []playlists.Rule{
+					{Field: "year", Operator: playlists.OpIs, Value: 2001},
+				},
+			}},
+		},
+		{
+			desc: "nested error",
+			rules: playlists.SmartRules{Match: playlists.Rule{
+				Any: []playlists.Rule{
+					{Field: "favourite", Operator: playlists.OpIs, Value: true},
+					{All: []playlists.Rule{{Field: "year"}}},
+				},
+			}},
+		},
+		{
+			desc:  "unknown sort",
+			rules: playlists.SmartRules{Sort: "mood"},
+		},
+		{
+			desc:  "negative limit",
+			rules: playlists.SmartRules{Limit: -5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := manager.Create(ctx, playlists.CreateArgs{
+				Name:  "Smart",
+				Rules: &test.rules,
+			})
+			if !errors.Is(err, playlists.ErrInvalidRules) {
+				t.Errorf("expected invalid rules error but got: %v", err)
+			}
+		})
+	}
+
+	_, err := manager.Create(ctx, playlists.CreateArgs{
+		Name:   "Smart",
+		Rules:  &playlists.SmartRules{},
+		Tracks: []int64{1},
+	})
+	if !errors.Is(err, playlists.ErrSmartPlaylist) {
+		t.Errorf("expected smart playlist error for tracks but got: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package playqueue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// queueID is the ID of the only play queue. Once there are many users every
+// one of them will have its own queue.
+const queueID = 1
+
+// manager implements the Queuer interface by just requiring a function for
+// sending database work.
+type manager struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewManager returns a Queuer which will send SQL queries to `sendDBWork`.
+func NewManager(sendDBWork func(library.DatabaseExecutable) error) Queuer {
+	return &manager{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// Get implements Queuer.
+func (m *manager) Get(ctx context.Context) (Queue, error) {
+	const getQueueQuery = `
+		SELECT
+			current_index,
+			position,
+			changed_by,
+			updated_at,
+			version
+		FROM
+			play_queue
+		WHERE
+			id = @queue_id
+	`
+
+	queue := Queue{
+		CurrentIndex: -1,
+	}
+
+	work := func(db *sql.DB) error {
+		var (
+			currentIndex sql.NullInt64
+			updatedAt    int64
+		)
+
+		row := db.QueryRowContext(ctx, getQueueQuery, sql.Named("queue_id", queueID))
+		err := row.Scan(
+			&currentIndex,
+			&queue.Position,
+			&queue.ChangedBy,
+			&updatedAt,
+			&queue.Version,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error getting play queue: %w", err)
+		}
+		queue.UpdatedAt = time.Unix(updatedAt, 0)
+
+		storedIndex := int64(-1)
+		if currentIndex.Valid {
+			storedIndex = currentIndex.Int64
+		}
+
+		return populateTracks(ctx, db, &queue, storedIndex)
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return Queue{}, err
+	}
+
+	return queue, nil
+}
+
+// Save implements Queuer.
+func (m *manager) Save(ctx context.Context, args SaveArgs) (int64, error) {
+	if args.CurrentIndex < -1 || args.CurrentIndex >= len(args.TrackIDs) {
+		return 0, fmt.Errorf("current index %d is out of range", args.CurrentIndex)
+	}
+	if args.Position < 0 {
+		return 0, fmt.Errorf("position cannot be negative")
+	}
+
+	const (
+		getVersionQuery = `
+			SELECT version FROM play_queue
+			WHERE id = @queue_id
+		`
+
+		saveQueueQuery = `
+			INSERT INTO
+				play_queue (id, current_index, position, changed_by, updated_at, version)
+			VALUES
+				(@queue_id, @current_index, @position, @changed_by, @current_time, @version)
+			ON CONFLICT (id) DO
+			UPDATE SET
+				current_index = excluded.current_index,
+				position = excluded.position,
+				changed_by = excluded.changed_by,
+				updated_at = excluded.updated_at,
+				version = excluded.version
+		`
+
+		removeTracksQuery = `
+			DELETE FROM play_queue_tracks
+			WHERE queue_id = @queue_id
+		`
+
+		insertTrackQuery = `
+			INSERT INTO
+				play_queue_tracks (queue_id, track_id, "index")
+			SELECT
+				@queue_id, id, @index
+			FROM
+				tracks
+			WHERE
+				id = @track_id
+		`
+	)
+
+	var newVersion int64
+
+	work := func(db *sql.DB) (retErr error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin DB transaction: %w", err)
+		}
+		defer func() {
+			if retErr == nil {
+				retErr = tx.Commit()
+			} else {
+				_ = tx.Rollback()
+			}
+		}()
+
+		var version int64
+		row := tx.QueryRowContext(ctx, getVersionQuery, sql.Named("queue_id", queueID))
+		if err := row.Scan(&version); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error getting play queue version: %w", err)
+		}
+
+		if args.IfVersion != nil && *args.IfVersion != version {
+			return ErrVersionMismatch
+		}
+		newVersion = version + 1
+
+		currentIndex := sql.NullInt64{
+			Int64: int64(args.CurrentIndex),
+			Valid: args.CurrentIndex >= 0,
+		}
+		_, err = tx.ExecContext(ctx, saveQueueQuery,
+			sql.Named("queue_id", queueID),
+			sql.Named("current_index", currentIndex),
+			sql.Named("position", args.Position),
+			sql.Named("changed_by", args.ChangedBy),
+			sql.Named("current_time", time.Now().Unix()),
+			sql.Named("version", newVersion),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save play queue: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, removeTracksQuery, sql.Named("queue_id", queueID))
+		if err != nil {
+			return fmt.Errorf("failed to remove play queue tracks: %w", err)
+		}
+
+		for index, trackID := range args.TrackIDs {
+			res, err := tx.ExecContext(ctx, insertTrackQuery,
+				sql.Named("queue_id", queueID),
+				sql.Named("track_id", trackID),
+				sql.Named("index", index),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert play queue track: %w", err)
+			}
+
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("cannot get number of affected rows: %w", err)
+			}
+			if affected < 1 {
+				return fmt.Errorf("%w: %d", ErrTrackNotFound, trackID)
+			}
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return 0, err
+	}
+
+	return newVersion, nil
+}
+
+// populateTracks sets the tracks of the queue. Its current index is set to the
+// position of the track which was stored with index `storedIndex`.
+func populateTracks(
+	ctx context.Context,
+	db *sql.DB,
+	queue *Queue,
+	storedIndex int64,
+) error {
+	const getTracksQuery = `
+		SELECT track_id, "index" FROM play_queue_tracks
+		WHERE queue_id = @queue_id
+		ORDER BY "index"
+	`
+
+	rows, err := db.QueryContext(ctx, getTracksQuery, sql.Named("queue_id", queueID))
+	if err != nil {
+		return fmt.Errorf("failed to get play queue tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		trackIDs []int64
+		indexes  []int64
+	)
+	for rows.Next() {
+		var trackID, index int64
+		if err := rows.Scan(&trackID, &index); err != nil {
+			return fmt.Errorf("failed to scan play queue track: %w", err)
+		}
+
+		trackIDs = append(trackIDs, trackID)
+		indexes = append(indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over play queue tracks: %w", err)
+	}
+
+	if len(trackIDs) == 0 {
+		return nil
+	}
+
+	tracksQueryArg := make([]any, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		tracksQueryArg = append(tracksQueryArg, trackID)
+	}
+
+	queryTracksWhere := []string{
+		"t.id IN (" + strings.TrimSuffix(
+			strings.Repeat("?,", len(tracksQueryArg)),
+			",",
+		) + ")",
+	}
+
+	trackRows, err := library.QueryTracks(ctx, db, queryTracksWhere, "", tracksQueryArg)
+	if err != nil {
+		return fmt.Errorf("error selecting tracks for play queue: %w", err)
+	}
+	defer trackRows.Close()
+
+	// tracks is a map from track ID => track info.
+	tracks := make(map[int64]library.TrackInfo, len(trackIDs))
+	for trackRows.Next() {
+		track, err := library.ScanTrack(trackRows)
+		if err != nil {
+			return fmt.Errorf("error while scanning a track: %w", err)
+		}
+
+		tracks[track.ID] = track
+	}
+	if err := trackRows.Err(); err != nil {
+		return fmt.Errorf("error iterating over tracks: %w", err)
+	}
+
+	for ind, trackID := range trackIDs {
+		track, found := tracks[trackID]
+		if !found {
+			continue
+		}
+
+		if indexes[ind] == storedIndex {
+			queue.CurrentIndex = len(queue.Tracks)
+		}
+		queue.Tracks = append(queue.Tracks, track)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package playqueue stores the play queue of the user so that listening could be
+// continued on another device from the same track and position.
+package playqueue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+//counterfeiter:generate . Queuer
+
+// Queuer is the interface for saving and restoring the play queue.
+type Queuer interface {
+	// Get returns the saved play queue. When no queue has been saved yet an
+	// empty queue with version 0 is returned.
+	Get(ctx context.Context) (Queue, error)
+
+	// Save replaces the saved play queue. It returns the version of the newly
+	// saved queue.
+	//
+	// When args.IfVersion is set and it is not the version of the currently
+	// saved queue then ErrVersionMismatch is returned and nothing is saved.
+	Save(ctx context.Context, args SaveArgs) (int64, error)
+}
+
+// Queue is a saved play queue.
+type Queue struct {
+	// Tracks are all tracks in the queue in order.
+	Tracks []library.TrackInfo
+
+	// CurrentIndex is the index in Tracks of the currently playing track. It is
+	// -1 when there is no current track.
+	CurrentIndex int
+
+	// Position is the position in the current track in milliseconds.
+	Position int64
+
+	// ChangedBy is the name of the client which saved the queue.
+	ChangedBy string
+
+	// UpdatedAt is the time when the queue was saved.
+	UpdatedAt time.Time
+
+	// Version is increased every time the queue is saved. It is 0 when no queue
+	// has been saved yet.
+	Version int64
+}
+
+// Current returns the currently playing track. The returned boolean is false when
+// there is no current track.
+func (q Queue) Current() (library.TrackInfo, bool) {
+	if q.CurrentIndex < 0 || q.CurrentIndex >= len(q.Tracks) {
+		return library.TrackInfo{}, false
+	}
+
+	return q.Tracks[q.CurrentIndex], true
+}
+
+// SaveArgs are the arguments for saving the play queue.
+type SaveArgs struct {
+	// TrackIDs are the IDs of all tracks in the queue in order. The same track
+	// may be in the queue more than once.
+	TrackIDs []int64
+
+	// CurrentIndex is the index in TrackIDs of the currently playing track. Use
+	// -1 when there is no current track.
+	CurrentIndex int
+
+	// Position is the position in the current track in milliseconds.
+	Position int64
+
+	// ChangedBy is the name of the client which saves the queue.
+	ChangedBy string
+
+	// IfVersion is the version of the queue which the client has last seen. When
+	// set the queue is saved only if it has not been changed since.
+	IfVersion *int64
+}
+
+var (
+	// ErrVersionMismatch is returned when saving a queue which has been changed
+	// by someone else in the meantime.
+	ErrVersionMismatch = errors.New("play queue has been changed in the meantime")
+
+	// ErrTrackNotFound is returned when saving a queue with a track which does
+	// not exist.
+	ErrTrackNotFound = errors.New("track not found")
+)
@@ -0,0 +1,158 @@
+package playqueue_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playqueue"
+)
+
+// TestPlayQueueManager checks that the play queue manager saves and restores the
+// play queue and that it refuses to overwrite a queue which has been changed
+// in the meantime.
+func TestPlayQueueManager(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	allTracks := lib.Search(ctx, library.SearchArgs{Query: "", Count: 100})
+	if len(allTracks) < 2 {
+		t.Fatalf("not enough tracks found in the library for working with the queue")
+	}
+
+	manager := playqueue.NewManager(lib.ExecuteDBJobAndWait)
+
+	queue, err := manager.Get(ctx)
+	assert.NilErr(t, err, "getting empty queue")
+	assert.Equal(t, 0, queue.Version, "empty queue version")
+	assert.Equal(t, 0, len(queue.Tracks), "empty queue tracks")
+	if _, ok := queue.Current(); ok {
+		t.Errorf("did not expect a current track in an empty queue")
+	}
+
+	_, err = manager.Save(ctx, playqueue.SaveArgs{
+		TrackIDs:     []int64{allTracks[0].ID, 987654},
+		CurrentIndex: 0,
+	})
+	if !errors.Is(err, playqueue.ErrTrackNotFound) {
+		t.Errorf("expected ErrTrackNotFound for missing track but got %v", err)
+	}
+
+	_, err = manager.Save(ctx, playqueue.SaveArgs{
+		TrackIDs:     []int64{allTracks[0].ID},
+		CurrentIndex: 1,
+	})
+	if err == nil {
+		t.Errorf("expected an error for current index out of range")
+	}
+
+	now := time.Now()
+	version, err := manager.Save(ctx, playqueue.SaveArgs{
+		TrackIDs: []int64{
+			allTracks[1].ID,
+			allTracks[0].ID,
+			allTracks[1].ID,
+		},
+		CurrentIndex: 2,
+		Position:     12500,
+		ChangedBy:    "phone",
+	})
+	assert.NilErr(t, err, "saving queue")
+	assert.Equal(t, 1, version, "first saved version")
+
+	queue, err = manager.Get(ctx)
+	assert.NilErr(t, err, "getting saved queue")
+	assert.Equal(t, 1, queue.Version, "saved queue version")
+	assert.Equal(t, 3, len(queue.Tracks), "saved queue tracks")
+	assert.Equal(t, allTracks[1].ID, queue.Tracks[0].ID, "first queue track")
+	assert.Equal(t, allTracks[0].ID, queue.Tracks[1].ID, "second queue track")
+	assert.Equal(t, allTracks[1].Title, queue.Tracks[2].Title, "third queue track")
+	assert.Equal(t, 2, queue.CurrentIndex, "current index")
+	assert.Equal(t, 12500, queue.Position, "position")
+	assert.Equal(t, "phone", queue.ChangedBy, "changed by")
+	if queue.UpdatedAt.Before(now.Add(-time.Second)) {
+		t.Errorf("queue update time %s is too far in the past", queue.UpdatedAt)
+	}
+
+	staleVersion := int64(0)
+	_, err = manager.Save(ctx, playqueue.SaveArgs{
+		TrackIDs:     []int64{allTracks[0].ID},
+		CurrentIndex: 0,
+		ChangedBy:    "desktop",
+		IfVersion:    &staleVersion,
+	})
+	if !errors.Is(err, playqueue.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch for stale version but got %v", err)
+	}
+
+	queue, err = manager.Get(ctx)
+	assert.NilErr(t, err, "getting queue after stale save")
+	assert.Equal(t, 1, queue.Version, "queue version after stale save")
+	assert.Equal(t, "phone", queue.ChangedBy, "changed by after stale save")
+
+	version, err = manager.Save(ctx, playqueue.SaveArgs{
+		CurrentIndex: -1,
+		ChangedBy:    "desktop",
+		IfVersion:    &queue.Version,
+	})
+	assert.NilErr(t, err, "clearing queue")
+	assert.Equal(t, 2, version, "version after clearing")
+
+	queue, err = manager.Get(ctx)
+	assert.NilErr(t, err, "getting cleared queue")
+	assert.Equal(t, 2, queue.Version, "cleared queue version")
+	assert.Equal(t, 0, len(queue.Tracks), "cleared queue tracks")
+	assert.Equal(t, -1, queue.CurrentIndex, "cleared queue current index")
+	assert.Equal(t, "desktop", queue.ChangedBy, "cleared queue changed by")
+}
+
+// getTestMigrationFiles returns the SQLs directory used by the application itself
+// normally. This way tests will be done with the exact same files which will be
+// bundled into the binary on build.
+func getTestMigrationFiles() fs.FS {
+	return os.DirFS("../../sqls")
+}
+
+// getLibrary returns a library with all test files scanned into it.
+func getLibrary(ctx context.Context, t *testing.T) *library.LocalLibrary {
+	migrationsFS := getTestMigrationFiles()
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, migrationsFS)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	return lib
+}
@@ -0,0 +1,249 @@
+package podcasts
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feed is a parsed podcast feed regardless of its format.
+type feed struct {
+	Title       string
+	Description string
+	ImageURL    string
+	Items       []feedItem
+}
+
+// feedItem is a single episode in a podcast feed.
+type feedItem struct {
+	GUID        string
+	Title       string
+	Description string
+	URL         string
+	ContentType string
+	Size        int64
+	Duration    time.Duration
+	PublishDate time.Time
+}
+
+// parseFeed reads an RSS 2.0 or Atom feed from r. Feed items without a media
+// file are skipped since they are not podcast episodes.
+func parseFeed(r io.Reader) (feed, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return feed{}, fmt.Errorf("no feed found in the document")
+		} else if err != nil {
+			return feed{}, fmt.Errorf("reading XML: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "rss":
+			var doc rssDocument
+			if err := dec.DecodeElement(&doc, &start); err != nil {
+				return feed{}, fmt.Errorf("decoding RSS feed: %w", err)
+			}
+			return doc.toFeed(), nil
+		case "feed":
+			var doc atomFeed
+			if err := dec.DecodeElement(&doc, &start); err != nil {
+				return feed{}, fmt.Errorf("decoding Atom feed: %w", err)
+			}
+			return doc.toFeed(), nil
+		default:
+			return feed{}, fmt.Errorf("unsupported feed format <%s>", start.Name.Local)
+		}
+	}
+}
+
+// rssDocument is an RSS 2.0 document with the iTunes podcast extensions. The
+// fields in the iTunes name space come first since fields without a name space
+// match elements in any name space.
+type rssDocument struct {
+	Channel struct {
+		ITunesImage struct {
+			Href string `xml:"href,attr"`
+		} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		ITunesSummary string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Image       struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	ITunesDuration string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesSummary  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	Enclosure   struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+func (doc rssDocument) toFeed() feed {
+	ch := doc.Channel
+	parsed := feed{
+		Title:       strings.TrimSpace(ch.Title),
+		Description: firstNonEmpty(ch.Description, ch.ITunesSummary),
+		ImageURL:    firstNonEmpty(ch.ITunesImage.Href, ch.Image.URL),
+	}
+
+	for _, item := range ch.Items {
+		mediaURL := strings.TrimSpace(item.Enclosure.URL)
+		if mediaURL == "" {
+			continue
+		}
+
+		size, _ := strconv.ParseInt(strings.TrimSpace(item.Enclosure.Length), 10, 64)
+		parsed.Items = append(parsed.Items, feedItem{
+			GUID:        firstNonEmpty(item.GUID, mediaURL),
+			Title:       strings.TrimSpace(item.Title),
+			Description: firstNonEmpty(item.Description, item.ITunesSummary),
+			URL:         mediaURL,
+			ContentType: strings.TrimSpace(item.Enclosure.Type),
+			Size:        size,
+			Duration:    parseDuration(item.ITunesDuration),
+			PublishDate: parseDate(item.PubDate),
+		})
+	}
+
+	return parsed
+}
+
+// atomFeed is an Atom feed document. Episodes are the entries with an
+// "enclosure" link.
+type atomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Logo     string      `xml:"logo"`
+	Icon     string      `xml:"icon"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Links     []struct {
+		Rel    string `xml:"rel,attr"`
+		Href   string `xml:"href,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"link"`
+}
+
+func (doc atomFeed) toFeed() feed {
+	parsed := feed{
+		Title:       strings.TrimSpace(doc.Title),
+		Description: strings.TrimSpace(doc.Subtitle),
+		ImageURL:    firstNonEmpty(doc.Logo, doc.Icon),
+	}
+
+	for _, entry := range doc.Entries {
+		for _, link := range entry.Links {
+			mediaURL := strings.TrimSpace(link.Href)
+			if link.Rel != "enclosure" || mediaURL == "" {
+				continue
+			}
+
+			size, _ := strconv.ParseInt(strings.TrimSpace(link.Length), 10, 64)
+			parsed.Items = append(parsed.Items, feedItem{
+				GUID:        firstNonEmpty(entry.ID, mediaURL),
+				Title:       strings.TrimSpace(entry.Title),
+				Description: firstNonEmpty(entry.Summary, entry.Content),
+				URL:         mediaURL,
+				ContentType: strings.TrimSpace(link.Type),
+				Size:        size,
+				PublishDate: parseDate(firstNonEmpty(entry.Published, entry.Updated)),
+			})
+			break
+		}
+	}
+
+	return parsed
+}
+
+// dateLayouts are the date formats found in podcast feeds. RSS uses RFC 822 dates
+// but many feeds do not follow it exactly.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+// parseDate parses a feed date. It returns the zero time when the date is
+// not recognized.
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+
+	return time.Time{}
+}
+
+// parseDuration parses an itunes:duration value. It could be a number of
+// seconds, "MM:SS" or "HH:MM:SS". Zero is returned for unrecognized values.
+func parseDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	var seconds float64
+	for _, part := range strings.Split(value, ":") {
+		parsed, err := strconv.ParseFloat(part, 64)
+		if err != nil || parsed < 0 {
+			return 0
+		}
+		seconds = seconds*60 + parsed
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// firstNonEmpty returns the first of values which is not empty after trimming
+// the white space around it.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+
+	return ""
+}
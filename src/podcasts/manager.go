@@ -0,0 +1,1204 @@
+package podcasts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/version"
+)
+
+// userAgentFormat is the format of the User-Agent header for all requests for
+// feeds and media files. It is the same as the one used for downloading artwork.
+const userAgentFormat = "Euterpe Media Server/%s (github.com/ironsmile/euterpe)"
+
+// maxFeedSize is the maximum size of a feed document in bytes.
+const maxFeedSize = 20 * 1024 * 1024
+
+// allowedSchemes are the URL schemes of feeds and media files which could
+// be downloaded.
+var allowedSchemes = []string{"http", "https"}
+
+// manager implements the Podcaster interface by just requiring a function for
+// sending database work. Downloaded files are stored in the directory from the
+// configuration.
+type manager struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+
+	cfg       config.PodcastsSection
+	client    *http.Client
+	useragent string
+
+	// downloading holds the IDs of the episodes which are being downloaded at
+	// the moment.
+	downloading     map[int64]struct{}
+	downloadingLock sync.Mutex
+}
+
+// NewManager returns a Podcaster which will use the `sendDBWork` to execute its
+// database queries. Episodes and channel artwork are downloaded in cfg.Directory.
+//
+// Episodes left in StatusDownloading by a previous run of the server are marked
+// as failed before the first query of the manager since nothing is downloading
+// them any more.
+func NewManager(
+	sendDBWork func(library.DatabaseExecutable) error,
+	cfg config.PodcastsSection,
+) Podcaster {
+	m := &manager{
+		cfg:         cfg,
+		client:      &http.Client{Timeout: cfg.DownloadTimeout},
+		useragent:   fmt.Sprintf(userAgentFormat, version.Version),
+		downloading: make(map[int64]struct{}),
+	}
+
+	var resetOnce sync.Once
+	m.executeDBJobAndWait = func(work library.DatabaseExecutable) error {
+		resetOnce.Do(func() {
+			if err := sendDBWork(resetInterruptedDownloads); err != nil {
+				log.Printf("Resetting interrupted podcast downloads: %s\n", err)
+			}
+		})
+		return sendDBWork(work)
+	}
+
+	return m
+}
+
+const selectChannelsQuery = `
+	SELECT
+		id,
+		url,
+		title,
+		description,
+		image_url,
+		image_path,
+		status,
+		error_message,
+		created_at,
+		refreshed_at,
+		keep_episodes
+	FROM
+		podcast_channels
+`
+
+const selectEpisodesQuery = `
+	SELECT
+		e.id,
+		e.channel_id,
+		c.title,
+		e.guid,
+		e.title,
+		e.description,
+		e.url,
+		e.content_type,
+		e.size,
+		e.duration,
+		e.publish_date,
+		e.status,
+		e.error_message,
+		e.file_path,
+		e.downloaded_at
+	FROM
+		podcast_episodes as e
+		JOIN podcast_channels as c ON c.id = e.channel_id
+`
+
+// Channels implements the Podcaster interface.
+func (m *manager) Channels(ctx context.Context, includeEpisodes bool) ([]Channel, error) {
+	var channels []Channel
+
+	work := func(db *sql.DB) error {
+		var err error
+		channels, err = queryChannels(ctx, db, "ORDER BY title COLLATE NOCASE")
+		if err != nil {
+			return err
+		}
+
+		if !includeEpisodes || len(channels) == 0 {
+			return nil
+		}
+
+		episodes, err := queryEpisodes(ctx, db, "ORDER BY e.publish_date DESC, e.id DESC")
+		if err != nil {
+			return err
+		}
+
+		// channelIndexes is a map from channel ID => index in channels.
+		channelIndexes := make(map[int64]int, len(channels))
+		for ind, channel := range channels {
+			channelIndexes[channel.ID] = ind
+		}
+
+		for _, episode := range episodes {
+			ind, ok := channelIndexes[episode.ChannelID]
+			if !ok {
+				continue
+			}
+			channels[ind].Episodes = append(channels[ind].Episodes, episode)
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// Channel implements the Podcaster interface.
+func (m *manager) Channel(ctx context.Context, id int64) (Channel, error) {
+	var channel Channel
+
+	work := func(db *sql.DB) error {
+		var err error
+		channel, err = getChannel(ctx, db, id)
+		if err != nil {
+			return err
+		}
+
+		channel.Episodes, err = queryEpisodes(ctx, db, `
+			WHERE e.channel_id = @channel_id
+			ORDER BY e.publish_date DESC, e.id DESC
+		`, sql.Named("channel_id", id))
+		return err
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return Channel{}, err
+	}
+
+	return channel, nil
+}
+
+// Episode implements the Podcaster interface.
+func (m *manager) Episode(ctx context.Context, id int64) (Episode, error) {
+	var episodes []Episode
+
+	work := func(db *sql.DB) error {
+		var err error
+		episodes, err = queryEpisodes(ctx, db,
+			"WHERE e.id = @episode_id",
+			sql.Named("episode_id", id),
+		)
+		return err
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return Episode{}, err
+	}
+
+	if len(episodes) == 0 {
+		return Episode{}, ErrNotFound
+	}
+
+	return episodes[0], nil
+}
+
+// NewestEpisodes implements the Podcaster interface.
+func (m *manager) NewestEpisodes(ctx context.Context, count int) ([]Episode, error) {
+	var episodes []Episode
+
+	work := func(db *sql.DB) error {
+		var err error
+		episodes, err = queryEpisodes(ctx, db, `
+			WHERE e.status != @deleted
+			ORDER BY e.publish_date DESC, e.id DESC
+			LIMIT @count
+		`,
+			sql.Named("deleted", StatusDeleted),
+			sql.Named("count", count),
+		)
+		return err
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return episodes, nil
+}
+
+// Subscribe implements the Podcaster interface.
+func (m *manager) Subscribe(ctx context.Context, feedURL string) (int64, error) {
+	parsedURL, err := url.Parse(feedURL)
+	if err != nil {
+		return 0, fmt.Errorf("malformed feed URL: %w", err)
+	}
+	if !slices.Contains(allowedSchemes, parsedURL.Scheme) || parsedURL.Host == "" {
+		return 0, fmt.Errorf(
+			"feed URL scheme can only be one of %s",
+			strings.Join(allowedSchemes, ", "),
+		)
+	}
+
+	const (
+		findQuery = `
+			SELECT id FROM podcast_channels
+			WHERE url = @url
+		`
+
+		insertQuery = `
+			INSERT INTO
+				podcast_channels (url, title, status, created_at)
+			VALUES
+				(@url, @url, @status, @created_at)
+		`
+	)
+
+	var channelID int64
+	work := func(db *sql.DB) error {
+		row := db.QueryRowContext(ctx, findQuery, sql.Named("url", parsedURL.String()))
+		err := row.Scan(&channelID)
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error finding channel: %w", err)
+		}
+
+		res, err := db.ExecContext(ctx, insertQuery,
+			sql.Named("url", parsedURL.String()),
+			sql.Named("status", StatusNew),
+			sql.Named("created_at", time.Now().Unix()),
+		)
+		if err != nil {
+			return fmt.Errorf("inserting channel: %w", err)
+		}
+
+		channelID, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("getting new channel ID: %w", err)
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return 0, err
+	}
+
+	return channelID, nil
+}
+
+// SetKeepEpisodes implements the Podcaster interface.
+func (m *manager) SetKeepEpisodes(ctx context.Context, channelID int64, keep int) error {
+	if keep < 0 {
+		return errors.New("the number of kept episodes cannot be negative")
+	}
+
+	const query = `
+		UPDATE podcast_channels
+		SET keep_episodes = NULLIF(@keep, 0)
+		WHERE id = @channel_id
+	`
+
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx, query,
+			sql.Named("keep", keep),
+			sql.Named("channel_id", channelID),
+		)
+		if err != nil {
+			return fmt.Errorf("updating channel: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+		if affected < 1 {
+			return ErrNotFound
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return err
+	}
+
+	return m.applyRetention(ctx, channelID)
+}
+
+// Unsubscribe implements the Podcaster interface.
+func (m *manager) Unsubscribe(ctx context.Context, id int64) error {
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx,
+			"DELETE FROM podcast_channels WHERE id = @channel_id",
+			sql.Named("channel_id", id),
+		)
+		if err != nil {
+			return fmt.Errorf("deleting channel: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+		if affected < 1 {
+			return ErrNotFound
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(m.channelDir(id)); err != nil {
+		return fmt.Errorf("removing channel files: %w", err)
+	}
+
+	return nil
+}
+
+// Refresh implements the Podcaster interface.
+func (m *manager) Refresh(ctx context.Context) error {
+	channels, err := m.Channels(ctx, false)
+	if err != nil {
+		return fmt.Errorf("getting channels: %w", err)
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := m.RefreshChannel(ctx, channel.ID); err != nil {
+			errs = append(errs, fmt.Errorf("channel %d: %w", channel.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RefreshChannel implements the Podcaster interface.
+func (m *manager) RefreshChannel(ctx context.Context, id int64) error {
+	var channel Channel
+	err := m.executeDBJobAndWait(func(db *sql.DB) error {
+		var err error
+		channel, err = getChannel(ctx, db, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	parsedFeed, err := m.fetchFeed(ctx, channel.URL)
+	if err != nil {
+		if statusErr := m.setChannelError(ctx, id, err); statusErr != nil {
+			log.Printf("Storing error for podcast channel %d: %s\n", id, statusErr)
+		}
+		return fmt.Errorf("reading feed: %w", err)
+	}
+
+	if err := m.storeFeed(ctx, channel, parsedFeed); err != nil {
+		return fmt.Errorf("storing feed: %w", err)
+	}
+
+	var errs []error
+	imageURL := resolveURL(channel.URL, parsedFeed.ImageURL)
+	if imageURL != "" && (imageURL != channel.ImageURL || channel.ImagePath == "") {
+		if err := m.downloadImage(ctx, channel, imageURL); err != nil {
+			errs = append(errs, fmt.Errorf("downloading artwork: %w", err))
+		}
+	}
+
+	toDownload, err := m.newEpisodesForDownload(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding episodes for download: %w", err)
+	}
+	for _, episodeID := range toDownload {
+		if err := m.Download(ctx, episodeID); err != nil {
+			errs = append(errs, fmt.Errorf("episode %d: %w", episodeID, err))
+		}
+	}
+
+	if err := m.applyRetention(ctx, id); err != nil {
+		errs = append(errs, fmt.Errorf("removing old episodes: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Download implements the Podcaster interface.
+func (m *manager) Download(ctx context.Context, episodeID int64) error {
+	episode, err := m.Episode(ctx, episodeID)
+	if err != nil {
+		return err
+	}
+
+	m.downloadingLock.Lock()
+	if _, ok := m.downloading[episodeID]; ok {
+		m.downloadingLock.Unlock()
+		return fmt.Errorf("episode %d is already being downloaded", episodeID)
+	}
+	m.downloading[episodeID] = struct{}{}
+	m.downloadingLock.Unlock()
+
+	defer func() {
+		m.downloadingLock.Lock()
+		delete(m.downloading, episodeID)
+		m.downloadingLock.Unlock()
+	}()
+
+	// The status of the episode must be stored even when the download has been
+	// cancelled.
+	statusCtx := context.WithoutCancel(ctx)
+
+	err = m.setEpisodeStatus(statusCtx, episodeID, StatusDownloading, nil)
+	if err != nil {
+		return fmt.Errorf("setting episode status: %w", err)
+	}
+
+	filePath, size, err := m.downloadEpisodeFile(ctx, episode)
+	if err != nil {
+		if statusErr := m.setEpisodeStatus(statusCtx, episodeID, StatusError, err); statusErr != nil {
+			log.Printf("Storing error for podcast episode %d: %s\n", episodeID, statusErr)
+		}
+		return err
+	}
+
+	const completeQuery = `
+		UPDATE podcast_episodes
+		SET
+			status = @status,
+			error_message = NULL,
+			file_path = @file_path,
+			size = @size,
+			downloaded_at = @downloaded_at
+		WHERE
+			id = @episode_id
+	`
+	err = m.executeDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(statusCtx, completeQuery,
+			sql.Named("status", StatusCompleted),
+			sql.Named("file_path", filePath),
+			sql.Named("size", size),
+			sql.Named("downloaded_at", time.Now().Unix()),
+			sql.Named("episode_id", episodeID),
+		)
+		return err
+	})
+	if err != nil {
+		_ = os.Remove(filePath)
+		return fmt.Errorf("storing downloaded episode: %w", err)
+	}
+
+	return m.applyRetention(statusCtx, episode.ChannelID)
+}
+
+// DeleteEpisode implements the Podcaster interface.
+func (m *manager) DeleteEpisode(ctx context.Context, episodeID int64) error {
+	episode, err := m.Episode(ctx, episodeID)
+	if err != nil {
+		return err
+	}
+
+	return m.deleteEpisodeFile(ctx, episode.ID, episode.FilePath)
+}
+
+// deleteEpisodeFile removes the file of an episode and marks it as deleted.
+func (m *manager) deleteEpisodeFile(ctx context.Context, episodeID int64, filePath string) error {
+	if filePath != "" {
+		err := os.Remove(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing episode file: %w", err)
+		}
+	}
+
+	const deleteQuery = `
+		UPDATE podcast_episodes
+		SET
+			status = @status,
+			error_message = NULL,
+			file_path = NULL,
+			downloaded_at = NULL
+		WHERE
+			id = @episode_id
+	`
+	return m.executeDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, deleteQuery,
+			sql.Named("status", StatusDeleted),
+			sql.Named("episode_id", episodeID),
+		)
+		if err != nil {
+			return fmt.Errorf("marking episode as deleted: %w", err)
+		}
+		return nil
+	})
+}
+
+// applyRetention deletes the downloaded episodes of a channel above its limit or
+// the configured one when the channel has none. The ones which were downloaded
+// last are kept.
+func (m *manager) applyRetention(ctx context.Context, channelID int64) error {
+	const query = `
+		SELECT id, file_path FROM podcast_episodes
+		WHERE channel_id = @channel_id AND status = @status
+		ORDER BY downloaded_at DESC, id DESC
+		LIMIT -1 OFFSET @keep
+	`
+
+	var (
+		episodeIDs []int64
+		filePaths  []string
+	)
+	work := func(db *sql.DB) error {
+		channel, err := getChannel(ctx, db, channelID)
+		if err != nil {
+			return err
+		}
+
+		keep := m.cfg.KeepEpisodes
+		if channel.KeepEpisodes > 0 {
+			keep = channel.KeepEpisodes
+		}
+		if keep <= 0 {
+			return nil
+		}
+
+		rows, err := db.QueryContext(ctx, query,
+			sql.Named("channel_id", channelID),
+			sql.Named("status", StatusCompleted),
+			sql.Named("keep", keep),
+		)
+		if err != nil {
+			return fmt.Errorf("querying downloaded episodes: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				episodeID int64
+				filePath  sql.NullString
+			)
+			if err := rows.Scan(&episodeID, &filePath); err != nil {
+				return fmt.Errorf("scanning episode: %w", err)
+			}
+
+			episodeIDs = append(episodeIDs, episodeID)
+			filePaths = append(filePaths, filePath.String)
+		}
+
+		return rows.Err()
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return err
+	}
+
+	for ind, episodeID := range episodeIDs {
+		if err := m.deleteEpisodeFile(ctx, episodeID, filePaths[ind]); err != nil {
+			return fmt.Errorf("deleting episode %d: %w", episodeID, err)
+		}
+	}
+
+	return nil
+}
+
+// newEpisodesForDownload returns the IDs of the configured number of newest
+// episodes of a channel which have never been downloaded.
+func (m *manager) newEpisodesForDownload(ctx context.Context, channelID int64) ([]int64, error) {
+	if m.cfg.DownloadNewest <= 0 {
+		return nil, nil
+	}
+
+	const query = `
+		SELECT id, status FROM podcast_episodes
+		WHERE channel_id = @channel_id
+		ORDER BY publish_date DESC, id DESC
+		LIMIT @count
+	`
+
+	var episodeIDs []int64
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query,
+			sql.Named("channel_id", channelID),
+			sql.Named("count", m.cfg.DownloadNewest),
+		)
+		if err != nil {
+			return fmt.Errorf("querying newest episodes: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				episodeID int64
+				status    Status
+			)
+			if err := rows.Scan(&episodeID, &status); err != nil {
+				return fmt.Errorf("scanning episode: %w", err)
+			}
+
+			if status == StatusNew {
+				episodeIDs = append(episodeIDs, episodeID)
+			}
+		}
+
+		return rows.Err()
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return episodeIDs, nil
+}
+
+// storeFeed updates the channel with the data from its feed and stores all of its
+// episodes. Episodes which are already stored have their meta data updated.
+func (m *manager) storeFeed(ctx context.Context, channel Channel, parsedFeed feed) error {
+	const (
+		updateChannelQuery = `
+			UPDATE podcast_channels
+			SET
+				title = @title,
+				description = @description,
+				status = @status,
+				error_message = NULL,
+				refreshed_at = @refreshed_at
+			WHERE
+				id = @channel_id
+		`
+
+		storeEpisodeQuery = `
+			INSERT INTO
+				podcast_episodes (channel_id, guid, title, description, url,
+					content_type, size, duration, publish_date, status)
+			VALUES
+				(@channel_id, @guid, @title, @description, @url,
+					@content_type, @size, @duration, @publish_date, @status)
+			ON CONFLICT (channel_id, guid) DO
+			UPDATE SET
+				title = excluded.title,
+				description = excluded.description,
+				url = excluded.url,
+				content_type = excluded.content_type,
+				duration = excluded.duration,
+				publish_date = excluded.publish_date
+		`
+	)
+
+	title := parsedFeed.Title
+	if title == "" {
+		title = channel.Title
+	}
+
+	work := func(db *sql.DB) (retErr error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin DB transaction: %w", err)
+		}
+		defer func() {
+			if retErr == nil {
+				retErr = tx.Commit()
+			} else {
+				_ = tx.Rollback()
+			}
+		}()
+
+		_, err = tx.ExecContext(ctx, updateChannelQuery,
+			sql.Named("title", title),
+			sql.Named("description", parsedFeed.Description),
+			sql.Named("status", StatusCompleted),
+			sql.Named("refreshed_at", time.Now().Unix()),
+			sql.Named("channel_id", channel.ID),
+		)
+		if err != nil {
+			return fmt.Errorf("updating channel: %w", err)
+		}
+
+		// Feeds list their newest items first. They are inserted in reverse so
+		// that newer episodes have greater IDs.
+		for _, item := range slices.Backward(parsedFeed.Items) {
+			mediaURL := resolveURL(channel.URL, item.URL)
+			if mediaURL == "" {
+				continue
+			}
+
+			publishDate := sql.NullInt64{
+				Int64: item.PublishDate.Unix(),
+				Valid: !item.PublishDate.IsZero(),
+			}
+			_, err := tx.ExecContext(ctx, storeEpisodeQuery,
+				sql.Named("channel_id", channel.ID),
+				sql.Named("guid", item.GUID),
+				sql.Named("title", item.Title),
+				sql.Named("description", item.Description),
+				sql.Named("url", mediaURL),
+				sql.Named("content_type", item.ContentType),
+				sql.Named("size", item.Size),
+				sql.Named("duration", item.Duration.Milliseconds()),
+				sql.Named("publish_date", publishDate),
+				sql.Named("status", StatusNew),
+			)
+			if err != nil {
+				return fmt.Errorf("storing episode %s: %w", item.GUID, err)
+			}
+		}
+
+		return nil
+	}
+
+	return m.executeDBJobAndWait(work)
+}
+
+// downloadImage downloads the artwork of a channel into its directory.
+func (m *manager) downloadImage(ctx context.Context, channel Channel, imageURL string) error {
+	resp, err := m.get(ctx, imageURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	ext := fileExtension(imageURL, resp.Header.Get("Content-Type"))
+	imagePath := filepath.Join(m.channelDir(channel.ID), "cover"+ext)
+	if _, err := writeFile(imagePath, resp, m.cfg.MaxImageSize); err != nil {
+		return err
+	}
+
+	if channel.ImagePath != "" && channel.ImagePath != imagePath {
+		_ = os.Remove(channel.ImagePath)
+	}
+
+	const query = `
+		UPDATE podcast_channels
+		SET
+			image_url = @image_url,
+			image_path = @image_path
+		WHERE
+			id = @channel_id
+	`
+	return m.executeDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, query,
+			sql.Named("image_url", imageURL),
+			sql.Named("image_path", imagePath),
+			sql.Named("channel_id", channel.ID),
+		)
+		if err != nil {
+			return fmt.Errorf("storing channel artwork: %w", err)
+		}
+		return nil
+	})
+}
+
+// downloadEpisodeFile downloads the media file of an episode into its channel's
+// directory. It returns the path to the file and its size.
+func (m *manager) downloadEpisodeFile(ctx context.Context, episode Episode) (string, int64, error) {
+	resp, err := m.get(ctx, episode.URL)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if episode.ContentType != "" {
+		contentType = episode.ContentType
+	}
+
+	fileName := strconv.FormatInt(episode.ID, 10) + fileExtension(episode.URL, contentType)
+	filePath := filepath.Join(m.channelDir(episode.ChannelID), fileName)
+
+	size, err := writeFile(filePath, resp, m.cfg.MaxEpisodeSize)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return filePath, size, nil
+}
+
+// fetchFeed downloads and parses the feed at feedURL.
+func (m *manager) fetchFeed(ctx context.Context, feedURL string) (feed, error) {
+	resp, err := m.get(ctx, feedURL)
+	if err != nil {
+		return feed{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseFeed(io.LimitReader(resp.Body, maxFeedSize))
+}
+
+// get makes a GET HTTP request for rawURL. An error is returned for all responses
+// which are not successful.
+func (m *manager) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("malformed URL: %w", err)
+	}
+	if !slices.Contains(allowedSchemes, parsedURL.Scheme) {
+		return nil, fmt.Errorf("URL scheme %q is not supported", parsedURL.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", m.useragent)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP response: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// resetInterruptedDownloads marks all episodes which are still downloading as
+// failed. It must be executed before any download has been started.
+func resetInterruptedDownloads(db *sql.DB) error {
+	const query = `
+		UPDATE podcast_episodes
+		SET
+			status = @error_status,
+			error_message = @error_message
+		WHERE
+			status = @downloading_status
+	`
+
+	_, err := db.Exec(query,
+		sql.Named("error_status", StatusError),
+		sql.Named("error_message", "the download was interrupted"),
+		sql.Named("downloading_status", StatusDownloading),
+	)
+	return err
+}
+
+// setChannelError marks a channel as failed with the error message of err.
+func (m *manager) setChannelError(ctx context.Context, id int64, err error) error {
+	const query = `
+		UPDATE podcast_channels
+		SET
+			status = @status,
+			error_message = @error_message,
+			refreshed_at = @refreshed_at
+		WHERE
+			id = @channel_id
+	`
+
+	return m.executeDBJobAndWait(func(db *sql.DB) error {
+		_, execErr := db.ExecContext(context.WithoutCancel(ctx), query,
+			sql.Named("status", StatusError),
+			sql.Named("error_message", err.Error()),
+			sql.Named("refreshed_at", time.Now().Unix()),
+			sql.Named("channel_id", id),
+		)
+		return execErr
+	})
+}
+
+// setEpisodeStatus sets the status of an episode. The error message of the episode
+// is set to the message of statusErr when it is not nil.
+func (m *manager) setEpisodeStatus(
+	ctx context.Context,
+	id int64,
+	status Status,
+	statusErr error,
+) error {
+	const query = `
+		UPDATE podcast_episodes
+		SET
+			status = @status,
+			error_message = @error_message
+		WHERE
+			id = @episode_id
+	`
+
+	var errorMessage sql.NullString
+	if statusErr != nil {
+		errorMessage = sql.NullString{String: statusErr.Error(), Valid: true}
+	}
+
+	return m.executeDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx, query,
+			sql.Named("status", status),
+			sql.Named("error_message", errorMessage),
+			sql.Named("episode_id", id),
+		)
+		return err
+	})
+}
+
+// channelDir returns the directory in which the files of a channel are stored.
+// Paths are made only from IDs so that feeds cannot choose where files go.
+func (m *manager) channelDir(channelID int64) string {
+	return filepath.Join(m.cfg.Directory, strconv.FormatInt(channelID, 10))
+}
+
+// getChannel returns the channel with ID `id` without its episodes.
+func getChannel(ctx context.Context, db *sql.DB, id int64) (Channel, error) {
+	channels, err := queryChannels(ctx, db,
+		"WHERE id = @channel_id",
+		sql.Named("channel_id", id),
+	)
+	if err != nil {
+		return Channel{}, err
+	}
+	if len(channels) == 0 {
+		return Channel{}, ErrNotFound
+	}
+
+	return channels[0], nil
+}
+
+// queryChannels returns the channels selected with the `rest` of the query.
+func queryChannels(
+	ctx context.Context,
+	db *sql.DB,
+	rest string,
+	args ...any,
+) ([]Channel, error) {
+	rows, err := db.QueryContext(ctx, selectChannelsQuery+rest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying channels: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []Channel
+	for rows.Next() {
+		var (
+			channel      Channel
+			description  sql.NullString
+			imageURL     sql.NullString
+			imagePath    sql.NullString
+			errorMessage sql.NullString
+			createdAt    int64
+			refreshedAt  sql.NullInt64
+			keepEpisodes sql.NullInt64
+		)
+
+		err := rows.Scan(
+			&channel.ID,
+			&channel.URL,
+			&channel.Title,
+			&description,
+			&imageURL,
+			&imagePath,
+			&channel.Status,
+			&errorMessage,
+			&createdAt,
+			&refreshedAt,
+			&keepEpisodes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning channel: %w", err)
+		}
+
+		channel.Description = description.String
+		channel.ImageURL = imageURL.String
+		channel.ImagePath = imagePath.String
+		channel.ErrorMessage = errorMessage.String
+		channel.KeepEpisodes = int(keepEpisodes.Int64)
+		channel.CreatedAt = time.Unix(createdAt, 0)
+		if refreshedAt.Valid {
+			channel.RefreshedAt = time.Unix(refreshedAt.Int64, 0)
+		}
+
+		channels = append(channels, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+// queryEpisodes returns the episodes selected with the `rest` of the query.
+func queryEpisodes(
+	ctx context.Context,
+	db *sql.DB,
+	rest string,
+	args ...any,
+) ([]Episode, error) {
+	rows, err := db.QueryContext(ctx, selectEpisodesQuery+rest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var episodes []Episode
+	for rows.Next() {
+		var (
+			episode      Episode
+			description  sql.NullString
+			contentType  sql.NullString
+			duration     int64
+			publishDate  sql.NullInt64
+			errorMessage sql.NullString
+			filePath     sql.NullString
+			downloadedAt sql.NullInt64
+		)
+
+		err := rows.Scan(
+			&episode.ID,
+			&episode.ChannelID,
+			&episode.ChannelTitle,
+			&episode.GUID,
+			&episode.Title,
+			&description,
+			&episode.URL,
+			&contentType,
+			&episode.Size,
+			&duration,
+			&publishDate,
+			&episode.Status,
+			&errorMessage,
+			&filePath,
+			&downloadedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning episode: %w", err)
+		}
+
+		episode.Description = description.String
+		episode.ContentType = contentType.String
+		episode.Duration = time.Duration(duration) * time.Millisecond
+		if publishDate.Valid {
+			episode.PublishDate = time.Unix(publishDate.Int64, 0)
+		}
+		episode.ErrorMessage = errorMessage.String
+		episode.FilePath = filePath.String
+		if downloadedAt.Valid {
+			episode.DownloadedAt = time.Unix(downloadedAt.Int64, 0)
+		}
+
+		episodes = append(episodes, episode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over episodes: %w", err)
+	}
+
+	return episodes, nil
+}
+
+// writeFile writes the body of resp into a file at filePath. The file is first
+// written under a temporary name so that a partially downloaded file is never
+// found at filePath. Returns the number of written bytes. When maxSize is
+// positive, bodies larger than it are not written at all.
+func writeFile(filePath string, resp *http.Response, maxSize int64) (int64, error) {
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return 0, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+
+	var r io.Reader = resp.Body
+	if maxSize > 0 {
+		// Reading one byte more than allowed tells apart the files which are
+		// exactly maxSize from the ones which are larger.
+		r = io.LimitReader(resp.Body, maxSize+1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return 0, fmt.Errorf("creating directory: %w", err)
+	}
+
+	tmpPath := filePath + ".tmp"
+	fh, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("creating file: %w", err)
+	}
+
+	size, err := io.Copy(fh, r)
+	if closeErr := fh.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("writing file: %w", err)
+	}
+	if maxSize > 0 && size > maxSize {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("renaming file: %w", err)
+	}
+
+	return size, nil
+}
+
+// resolveURL resolves ref relative to the URL of the feed. It returns an empty
+// string when ref is empty or it is not a valid URL.
+func resolveURL(feedURL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	return base.ResolveReference(refURL).String()
+}
+
+// extensionRegexp matches the file extensions which are used as they are found
+// in media URLs.
+var extensionRegexp = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+
+// contentTypeExtensions are the file extensions of the most common media types
+// in podcast feeds.
+var contentTypeExtensions = map[string]string{
+	"audio/mpeg":  ".mp3",
+	"audio/mp3":   ".mp3",
+	"audio/mp4":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/aac":   ".aac",
+	"audio/ogg":   ".ogg",
+	"audio/opus":  ".opus",
+	"audio/flac":  ".flac",
+	"video/mp4":   ".mp4",
+	"image/jpeg":  ".jpg",
+	"image/png":   ".png",
+	"image/webp":  ".webp",
+}
+
+// fileExtension returns the extension for a file downloaded from rawURL. The
+// extension from the URL is preferred and the one for contentType is used when
+// the URL has none.
+func fileExtension(rawURL, contentType string) string {
+	if parsedURL, err := url.Parse(rawURL); err == nil {
+		ext := strings.ToLower(path.Ext(parsedURL.Path))
+		if extensionRegexp.MatchString(ext) {
+			return ext
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	if ext, ok := contentTypeExtensions[mediaType]; ok {
+		return ext
+	}
+
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+
+	return exts[0]
+}
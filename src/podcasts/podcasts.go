@@ -0,0 +1,163 @@
+// Package podcasts manages podcast subscriptions. It reads the feeds of the
+// subscribed channels, downloads their episodes and keeps only the newest of them
+// on disk.
+package podcasts
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+//counterfeiter:generate . Podcaster
+
+// Podcaster is the interface for managing podcast channels and their episodes.
+type Podcaster interface {
+	// Channels returns all subscribed channels. Their episodes are included only
+	// when includeEpisodes is true.
+	Channels(ctx context.Context, includeEpisodes bool) ([]Channel, error)
+
+	// Channel returns the channel with the given ID together with its episodes.
+	// ErrNotFound is returned when there is no such channel.
+	Channel(ctx context.Context, id int64) (Channel, error)
+
+	// Episode returns the episode with the given ID. ErrNotFound is returned
+	// when there is no such episode.
+	Episode(ctx context.Context, id int64) (Episode, error)
+
+	// NewestEpisodes returns up to `count` episodes from all channels, newest
+	// first. Deleted episodes are not included.
+	NewestEpisodes(ctx context.Context, count int) ([]Episode, error)
+
+	// Subscribe adds a channel for the feed at feedURL and returns its ID. The
+	// feed itself is not read until the channel is refreshed. Subscribing for
+	// a feed twice returns the ID of the existing channel.
+	Subscribe(ctx context.Context, feedURL string) (int64, error)
+
+	// SetKeepEpisodes sets the number of downloaded episodes kept for a channel.
+	// Zero means that the configured default is used for it. ErrNotFound is
+	// returned when there is no such channel.
+	SetKeepEpisodes(ctx context.Context, channelID int64, keep int) error
+
+	// Unsubscribe removes a channel together with all its episodes and their
+	// downloaded files.
+	Unsubscribe(ctx context.Context, id int64) error
+
+	// Refresh refreshes all channels. See RefreshChannel.
+	Refresh(ctx context.Context) error
+
+	// RefreshChannel reads the feed of a channel, stores its new episodes and
+	// downloads its artwork and newest episodes. Errors while reading the feed
+	// are also stored in the channel's status.
+	RefreshChannel(ctx context.Context, id int64) error
+
+	// Download downloads the file of an episode. Once downloaded, the episodes of
+	// the channel above the retention limit which were downloaded first
+	// are deleted.
+	Download(ctx context.Context, episodeID int64) error
+
+	// DeleteEpisode removes the downloaded file of an episode and marks it
+	// as deleted.
+	DeleteEpisode(ctx context.Context, episodeID int64) error
+}
+
+// Status is the status of a channel or an episode.
+type Status string
+
+// All the possible values for Status.
+const (
+	StatusNew         Status = "new"
+	StatusDownloading Status = "downloading"
+	StatusCompleted   Status = "completed"
+	StatusError       Status = "error"
+	StatusDeleted     Status = "deleted"
+	StatusSkipped     Status = "skipped"
+)
+
+// Channel is a subscribed podcast.
+type Channel struct {
+	ID          int64
+	URL         string
+	Title       string
+	Description string
+
+	// ImageURL is the URL of the channel artwork as found in the feed.
+	ImageURL string
+
+	// ImagePath is the file system path of the downloaded channel artwork. It is
+	// empty when the artwork has not been downloaded.
+	ImagePath string
+
+	// Status is StatusNew until the feed is read for the first time. After that
+	// it is either StatusCompleted or StatusError.
+	Status       Status
+	ErrorMessage string
+
+	// KeepEpisodes is the number of downloaded episodes kept for this channel.
+	// Zero means that the configured default is used.
+	KeepEpisodes int
+
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+
+	Episodes []Episode
+}
+
+// Episode is a single episode of a podcast channel.
+type Episode struct {
+	ID        int64
+	ChannelID int64
+
+	// ChannelTitle is the title of the channel to which this episode belongs.
+	ChannelTitle string
+
+	// GUID uniquely identifies the episode in its channel's feed.
+	GUID        string
+	Title       string
+	Description string
+
+	// URL is the address of the episode's media file.
+	URL         string
+	ContentType string
+
+	// Size is the size of the media file in bytes.
+	Size        int64
+	Duration    time.Duration
+	PublishDate time.Time
+
+	Status       Status
+	ErrorMessage string
+
+	// FilePath is the file system path of the downloaded episode. It is empty
+	// unless the episode's status is StatusCompleted.
+	FilePath     string
+	DownloadedAt time.Time
+}
+
+// ErrNotFound is returned when a channel or an episode does not exist.
+var ErrNotFound = errors.New("not found")
+
+// RefreshPeriodically refreshes all channels of podcaster every `interval` until
+// ctx is cancelled. The first refresh is done right away. Nothing is done when
+// the interval is not positive.
+func RefreshPeriodically(ctx context.Context, podcaster Podcaster, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := podcaster.Refresh(ctx); err != nil {
+			log.Printf("Refreshing podcasts: %s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
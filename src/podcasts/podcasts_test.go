@@ -0,0 +1,479 @@
+package podcasts_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/podcasts"
+	"github.com/ironsmile/euterpe/src/podcasts/podcastsfakes"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+	<channel>
+		<title>Test Podcast</title>
+		<description>A podcast for testing.</description>
+		<itunes:image href="/cover.png"/>
+		<item>
+			<guid>episode-3</guid>
+			<title>Third Episode</title>
+			<pubDate>Wed, 03 Jan 2024 10:00:00 +0000</pubDate>
+			<itunes:duration>01:02:03</itunes:duration>
+			<enclosure url="/episodes/3.mp3" type="audio/mpeg" length="12"/>
+		</item>
+		<item>
+			<guid>no-media</guid>
+			<title>Announcement</title>
+			<pubDate>Tue, 02 Jan 2024 12:00:00 +0000</pubDate>
+		</item>
+		<item>
+			<guid>episode-2</guid>
+			<title>Second Episode</title>
+			<description>The second one.</description>
+			<pubDate>Tue, 2 Jan 2024 10:00:00 +0000</pubDate>
+			<itunes:duration>05:30</itunes:duration>
+			<enclosure url="%s/episodes/2.mp3" type="audio/mpeg" length="12"/>
+		</item>
+		<item>
+			<guid>episode-1</guid>
+			<title>First Episode</title>
+			<pubDate>Mon, 01 Jan 2024 10:00:00 GMT</pubDate>
+			<itunes:duration>90</itunes:duration>
+			<enclosure url="/episodes/1.mp3" type="audio/mpeg" length="12"/>
+		</item>
+	</channel>
+</rss>
+`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Atom Podcast</title>
+	<subtitle>Atom feeds are podcasts too.</subtitle>
+	<entry>
+		<id>urn:atom:2</id>
+		<title>Atom Two</title>
+		<published>2024-02-02T10:00:00Z</published>
+		<link rel="alternate" href="/atom/2.html"/>
+		<link rel="enclosure" href="/episodes/a2.mp3" type="audio/mpeg" length="12"/>
+	</entry>
+	<entry>
+		<id>urn:atom:1</id>
+		<title>Atom One</title>
+		<updated>2024-02-01T10:00:00Z</updated>
+		<link rel="enclosure" href="/episodes/a1.mp3" type="audio/mpeg" length="12"/>
+	</entry>
+</feed>
+`
+
+// TestPodcastsManager checks subscribing for podcast feeds, refreshing them,
+// downloading their episodes and keeping only the configured number of them.
+func TestPodcastsManager(t *testing.T) {
+	ctx := t.Context()
+
+	var feedServer *httptest.Server
+	feedServer = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			if !strings.HasPrefix(req.Header.Get("User-Agent"), "Euterpe Media Server/") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			switch {
+			case req.URL.Path == "/feed.xml":
+				fmt.Fprintf(w, rssFeed, feedServer.URL)
+			case req.URL.Path == "/atom.xml":
+				fmt.Fprint(w, atomFeed)
+			case req.URL.Path == "/cover.png":
+				w.Header().Set("Content-Type", "image/png")
+				fmt.Fprint(w, "cover")
+			case strings.HasPrefix(req.URL.Path, "/episodes/"):
+				w.Header().Set("Content-Type", "audio/mpeg")
+				fmt.Fprint(w, "episode file")
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		},
+	))
+	defer feedServer.Close()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	podcastsDir := t.TempDir()
+	manager := podcasts.NewManager(lib.ExecuteDBJobAndWait, config.PodcastsSection{
+		Directory:      podcastsDir,
+		KeepEpisodes:   2,
+		DownloadNewest: 1,
+	})
+
+	_, err := manager.Subscribe(ctx, "ftp://example.com/feed.xml")
+	if err == nil {
+		t.Errorf("expected an error for subscribing to a FTP feed")
+	}
+
+	channelID, err := manager.Subscribe(ctx, feedServer.URL+"/feed.xml")
+	assert.NilErr(t, err, "subscribing for RSS feed")
+
+	sameID, err := manager.Subscribe(ctx, feedServer.URL+"/feed.xml")
+	assert.NilErr(t, err, "subscribing for RSS feed again")
+	assert.Equal(t, channelID, sameID, "channel ID on second subscription")
+
+	channel, err := manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting new channel")
+	assert.Equal(t, podcasts.StatusNew, channel.Status, "new channel status")
+	assert.Equal(t, 0, len(channel.Episodes), "episodes before refresh")
+
+	err = manager.RefreshChannel(ctx, channelID)
+	assert.NilErr(t, err, "refreshing RSS channel")
+
+	channel, err = manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting refreshed channel")
+	assert.Equal(t, podcasts.StatusCompleted, channel.Status, "refreshed channel status")
+	assert.Equal(t, "Test Podcast", channel.Title, "channel title")
+	assert.Equal(t, "A podcast for testing.", channel.Description, "channel description")
+	assert.Equal(t, feedServer.URL+"/cover.png", channel.ImageURL, "channel image URL")
+	assertFileContents(t, channel.ImagePath, "cover")
+
+	if len(channel.Episodes) != 3 {
+		t.Fatalf("expected 3 episodes but got %d", len(channel.Episodes))
+	}
+
+	third, second, first := channel.Episodes[0], channel.Episodes[1], channel.Episodes[2]
+	assert.Equal(t, "Third Episode", third.Title, "newest episode title")
+	assert.Equal(t, "Test Podcast", third.ChannelTitle, "episode channel title")
+	assert.Equal(t, feedServer.URL+"/episodes/3.mp3", third.URL, "episode URL")
+	assert.Equal(t, time.Hour+2*time.Minute+3*time.Second, third.Duration, "duration")
+	assert.Equal(t, podcasts.StatusCompleted, third.Status, "newest episode status")
+	assertFileContents(t, third.FilePath, "episode file")
+
+	assert.Equal(t, "Second Episode", second.Title, "second episode title")
+	assert.Equal(t, "The second one.", second.Description, "second episode description")
+	assert.Equal(t, 5*time.Minute+30*time.Second, second.Duration, "second duration")
+	assert.Equal(t, podcasts.StatusNew, second.Status, "second episode status")
+	assert.Equal(t, "", second.FilePath, "second episode file")
+
+	assert.Equal(t, "First Episode", first.Title, "first episode title")
+	assert.Equal(t, 90*time.Second, first.Duration, "first episode duration")
+	assert.Equal(t,
+		time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).Unix(),
+		first.PublishDate.Unix(),
+		"first episode publish date",
+	)
+
+	// Refreshing again must not duplicate episodes or download anything new.
+	err = manager.RefreshChannel(ctx, channelID)
+	assert.NilErr(t, err, "refreshing RSS channel again")
+	channel, err = manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting channel after second refresh")
+	assert.Equal(t, 3, len(channel.Episodes), "episodes after second refresh")
+
+	assert.NilErr(t, manager.Download(ctx, first.ID), "downloading first episode")
+	assert.NilErr(t, manager.Download(ctx, second.ID), "downloading second episode")
+
+	channel, err = manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting channel after downloads")
+
+	var completed, deleted int
+	for _, episode := range channel.Episodes {
+		switch episode.Status {
+		case podcasts.StatusCompleted:
+			completed++
+			assertFileContents(t, episode.FilePath, "episode file")
+		case podcasts.StatusDeleted:
+			deleted++
+			assert.Equal(t, "", episode.FilePath, "deleted episode file path")
+		}
+	}
+	assert.Equal(t, 2, completed, "completed episodes after retention")
+	assert.Equal(t, 1, deleted, "deleted episodes after retention")
+
+	newest, err := manager.NewestEpisodes(ctx, 10)
+	assert.NilErr(t, err, "getting newest episodes")
+	assert.Equal(t, 2, len(newest), "newest episodes without deleted")
+
+	err = manager.DeleteEpisode(ctx, newest[0].ID)
+	assert.NilErr(t, err, "deleting episode")
+	episode, err := manager.Episode(ctx, newest[0].ID)
+	assert.NilErr(t, err, "getting deleted episode")
+	assert.Equal(t, podcasts.StatusDeleted, episode.Status, "deleted episode status")
+	if _, err := os.Stat(newest[0].FilePath); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected deleted episode file to be removed but got %v", err)
+	}
+
+	_, err = manager.Episode(ctx, 98765)
+	if !errors.Is(err, podcasts.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing episode but got %v", err)
+	}
+	err = manager.Download(ctx, 98765)
+	if !errors.Is(err, podcasts.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for downloading missing episode but got %v", err)
+	}
+
+	atomID, err := manager.Subscribe(ctx, feedServer.URL+"/atom.xml")
+	assert.NilErr(t, err, "subscribing for Atom feed")
+	brokenID, err := manager.Subscribe(ctx, feedServer.URL+"/broken.xml")
+	assert.NilErr(t, err, "subscribing for broken feed")
+
+	if err := manager.Refresh(ctx); err == nil {
+		t.Errorf("expected an error for refreshing a broken feed")
+	}
+
+	broken, err := manager.Channel(ctx, brokenID)
+	assert.NilErr(t, err, "getting broken channel")
+	assert.Equal(t, podcasts.StatusError, broken.Status, "broken channel status")
+	if broken.ErrorMessage == "" {
+		t.Errorf("expected an error message for the broken channel")
+	}
+
+	channels, err := manager.Channels(ctx, true)
+	assert.NilErr(t, err, "getting all channels")
+	assert.Equal(t, 3, len(channels), "number of channels")
+
+	atom := channels[0]
+	assert.Equal(t, atomID, atom.ID, "channels are sorted by title")
+	assert.Equal(t, "Atom Podcast", atom.Title, "Atom channel title")
+	assert.Equal(t, "Atom feeds are podcasts too.", atom.Description, "Atom description")
+	if len(atom.Episodes) != 2 {
+		t.Fatalf("expected 2 Atom episodes but got %d", len(atom.Episodes))
+	}
+	assert.Equal(t, "Atom Two", atom.Episodes[0].Title, "newest Atom episode")
+	assert.Equal(t, "urn:atom:2", atom.Episodes[0].GUID, "Atom episode GUID")
+	assert.Equal(t, podcasts.StatusCompleted, atom.Episodes[0].Status, "Atom episode status")
+	assert.Equal(t, podcasts.StatusNew, atom.Episodes[1].Status, "old Atom episode status")
+
+	channels, err = manager.Channels(ctx, false)
+	assert.NilErr(t, err, "getting all channels without episodes")
+	for _, channel := range channels {
+		assert.Equal(t, 0, len(channel.Episodes), "channel episodes when not included")
+	}
+
+	err = manager.Unsubscribe(ctx, channelID)
+	assert.NilErr(t, err, "unsubscribing")
+	_, err = manager.Channel(ctx, channelID)
+	if !errors.Is(err, podcasts.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after unsubscribing but got %v", err)
+	}
+	_, err = os.Stat(filepath.Join(podcastsDir, fmt.Sprint(channelID)))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected channel directory to be removed but got %v", err)
+	}
+
+	err = manager.Unsubscribe(ctx, channelID)
+	if !errors.Is(err, podcasts.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unsubscribing twice but got %v", err)
+	}
+}
+
+// TestPodcastsManagerLimits checks the download size limits, the download timeout,
+// the retention limit of single channels and that interrupted downloads are not
+// left as downloading.
+func TestPodcastsManagerLimits(t *testing.T) {
+	ctx := t.Context()
+
+	var feedServer *httptest.Server
+	feedServer = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.URL.Path == "/feed.xml":
+				fmt.Fprintf(w, rssFeed, feedServer.URL)
+			case req.URL.Path == "/slow.xml":
+				select {
+				case <-req.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			case req.URL.Path == "/cover.png":
+				// Flushing makes the response chunked so that its size is not
+				// known before reading it.
+				fmt.Fprint(w, "co")
+				w.(http.Flusher).Flush()
+				fmt.Fprint(w, "ver")
+			case strings.HasPrefix(req.URL.Path, "/episodes/"):
+				w.Header().Set("Content-Type", "audio/mpeg")
+				fmt.Fprint(w, "episode file")
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		},
+	))
+	defer feedServer.Close()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	podcastsDir := t.TempDir()
+	limited := podcasts.NewManager(lib.ExecuteDBJobAndWait, config.PodcastsSection{
+		Directory:       podcastsDir,
+		DownloadNewest:  1,
+		DownloadTimeout: 100 * time.Millisecond,
+		MaxEpisodeSize:  5,
+		MaxImageSize:    3,
+	})
+
+	slowID, err := limited.Subscribe(ctx, feedServer.URL+"/slow.xml")
+	assert.NilErr(t, err, "subscribing for slow feed")
+	started := time.Now()
+	if err := limited.RefreshChannel(ctx, slowID); err == nil {
+		t.Errorf("expected an error for refreshing a feed slower than the timeout")
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("expected the refresh to time out but it took %s", elapsed)
+	}
+
+	channelID, err := limited.Subscribe(ctx, feedServer.URL+"/feed.xml")
+	assert.NilErr(t, err, "subscribing for RSS feed")
+	if err := limited.RefreshChannel(ctx, channelID); err == nil {
+		t.Errorf("expected an error for downloading too large files")
+	}
+
+	channel, err := limited.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting channel")
+	assert.Equal(t, "", channel.ImagePath, "too large image path")
+	if len(channel.Episodes) != 3 {
+		t.Fatalf("expected 3 episodes but got %d", len(channel.Episodes))
+	}
+	newest := channel.Episodes[0]
+	assert.Equal(t, podcasts.StatusError, newest.Status, "too large episode status")
+	if !strings.Contains(newest.ErrorMessage, "larger than 5 bytes") {
+		t.Errorf("unexpected error for too large episode: %s", newest.ErrorMessage)
+	}
+
+	files, err := os.ReadDir(filepath.Join(podcastsDir, fmt.Sprint(channelID)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("reading channel directory: %s", err)
+	}
+	assert.Equal(t, 0, len(files), "files left from too large downloads")
+
+	manager := podcasts.NewManager(lib.ExecuteDBJobAndWait, config.PodcastsSection{
+		Directory:    podcastsDir,
+		KeepEpisodes: 10,
+	})
+
+	assert.NilErr(t, manager.SetKeepEpisodes(ctx, channelID, 1), "setting kept episodes")
+	if err := manager.SetKeepEpisodes(ctx, channelID, -1); err == nil {
+		t.Errorf("expected an error for negative number of kept episodes")
+	}
+	err = manager.SetKeepEpisodes(ctx, 98765, 1)
+	if !errors.Is(err, podcasts.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing channel but got %v", err)
+	}
+
+	for _, episode := range channel.Episodes {
+		assert.NilErr(t, manager.Download(ctx, episode.ID), "downloading episode")
+	}
+
+	channel, err = manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting channel after downloads")
+	assert.Equal(t, 1, channel.KeepEpisodes, "channel kept episodes")
+
+	var completed int
+	for _, episode := range channel.Episodes {
+		if episode.Status == podcasts.StatusCompleted {
+			completed++
+		}
+	}
+	assert.Equal(t, 1, completed, "completed episodes with channel limit")
+
+	// Zero brings back the configured limit.
+	assert.NilErr(t, manager.SetKeepEpisodes(ctx, channelID, 0), "resetting kept episodes")
+	channel, err = manager.Channel(ctx, channelID)
+	assert.NilErr(t, err, "getting channel after reset")
+	assert.Equal(t, 0, channel.KeepEpisodes, "channel kept episodes after reset")
+
+	err = lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+		_, err := db.ExecContext(ctx,
+			"UPDATE podcast_episodes SET status = @status WHERE id = @id",
+			sql.Named("status", podcasts.StatusDownloading),
+			sql.Named("id", newest.ID),
+		)
+		return err
+	})
+	assert.NilErr(t, err, "marking episode as downloading")
+
+	manager = podcasts.NewManager(lib.ExecuteDBJobAndWait, config.PodcastsSection{
+		Directory: podcastsDir,
+	})
+	episode, err := manager.Episode(ctx, newest.ID)
+	assert.NilErr(t, err, "getting interrupted episode")
+	assert.Equal(t, podcasts.StatusError, episode.Status, "interrupted episode status")
+	if episode.ErrorMessage == "" {
+		t.Errorf("expected an error message for the interrupted episode")
+	}
+}
+
+// TestRefreshPeriodically checks that channels are refreshed until the context
+// is cancelled.
+func TestRefreshPeriodically(t *testing.T) {
+	fakePodcaster := &podcastsfakes.FakePodcaster{}
+	podcasts.RefreshPeriodically(t.Context(), fakePodcaster, 0)
+	assert.Equal(t, 0, fakePodcaster.RefreshCallCount(), "refreshes when disabled")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	fakePodcaster.RefreshCalls(func(context.Context) error {
+		if fakePodcaster.RefreshCallCount() >= 2 {
+			cancel()
+		}
+		return fmt.Errorf("refresh error")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		podcasts.RefreshPeriodically(ctx, fakePodcaster, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("periodic refresh did not stop after cancelling its context")
+	}
+
+	if fakePodcaster.RefreshCallCount() < 2 {
+		t.Errorf("expected at least two refreshes but got %d",
+			fakePodcaster.RefreshCallCount())
+	}
+}
+
+func assertFileContents(t *testing.T, filePath, expected string) {
+	t.Helper()
+
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Errorf("reading file `%s`: %s", filePath, err)
+		return
+	}
+
+	assert.Equal(t, expected, string(contents), "file contents")
+}
+
+// getLibrary returns an empty library which is used only for its database.
+func getLibrary(ctx context.Context, t *testing.T) *library.LocalLibrary {
+	migrationsFS := os.DirFS("../../sqls")
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, migrationsFS)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	return lib
+}
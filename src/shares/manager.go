@@ -0,0 +1,491 @@
+package shares
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// idBytes is the number of random bytes in a share ID.
+const idBytes = 16
+
+// manager implements the Sharer interface by just requiring a function for
+// sending database work.
+type manager struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewManager returns a Sharer which will send SQL queries to `sendDBWork`.
+func NewManager(sendDBWork func(library.DatabaseExecutable) error) Sharer {
+	return &manager{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// Get implements Sharer.
+func (m *manager) Get(ctx context.Context, id string) (Share, error) {
+	const getShareQuery = selectShareQuery + `
+		WHERE id = @share_id
+	`
+
+	var share Share
+
+	work := func(db *sql.DB) error {
+		row := db.QueryRowContext(ctx, getShareQuery, sql.Named("share_id", id))
+		scanned, err := scanShare(row)
+		if err != nil && errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+
+		if err := populateShare(ctx, db, &scanned); err != nil {
+			return err
+		}
+
+		share = scanned
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return Share{}, err
+	}
+
+	return share, nil
+}
+
+// List implements Sharer.
+func (m *manager) List(ctx context.Context) ([]Share, error) {
+	const listSharesQuery = selectShareQuery + `
+		ORDER BY created_at, id
+	`
+
+	var shares []Share
+
+	work := func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, listSharesQuery)
+		if err != nil {
+			return fmt.Errorf("could not query the database: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			share, err := scanShare(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning shares: %w", err)
+			}
+
+			shares = append(shares, share)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over shares: %w", err)
+		}
+
+		for ind := range shares {
+			if err := populateShare(ctx, db, &shares[ind]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// Create implements Sharer.
+func (m *manager) Create(ctx context.Context, args CreateArgs) (string, error) {
+	if len(args.Items) == 0 {
+		return "", fmt.Errorf("at least one item must be shared")
+	}
+
+	id, err := newShareID()
+	if err != nil {
+		return "", fmt.Errorf("generating share ID: %w", err)
+	}
+
+	const insertShareQuery = `
+		INSERT INTO
+			shares (id, description, created_at, expires_at, owner)
+		VALUES
+			(@share_id, @description, @current_time, @expires_at, @owner)
+	`
+
+	insertItemsQuery := `
+		INSERT INTO
+			shares_items (share_id, item_type, item_id, "index")
+		VALUES
+	` + strings.TrimSuffix(strings.Repeat(
+		"(@share_id, ?, ?, ?),", len(args.Items),
+	), ",")
+
+	work := func(db *sql.DB) (retErr error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin DB transaction: %w", err)
+		}
+		defer func() {
+			if retErr == nil {
+				retErr = tx.Commit()
+			} else {
+				_ = tx.Rollback()
+			}
+		}()
+
+		_, err = tx.ExecContext(ctx, insertShareQuery,
+			sql.Named("share_id", id),
+			sql.Named("description", nullString(args.Description)),
+			sql.Named("current_time", time.Now().Unix()),
+			sql.Named("expires_at", nullTime(args.ExpiresAt)),
+			sql.Named("owner", nullString(args.Owner)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert share: %w", err)
+		}
+
+		queryVals := []any{
+			sql.Named("share_id", id),
+		}
+		for index, item := range args.Items {
+			queryVals = append(queryVals, item.Type, item.ID, index)
+		}
+
+		_, err = tx.ExecContext(ctx, insertItemsQuery, queryVals...)
+		if err != nil {
+			return fmt.Errorf("failed to insert shared items: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := m.executeDBJobAndWait(work); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Update implements Sharer.
+func (m *manager) Update(ctx context.Context, id string, args UpdateArgs) error {
+	var (
+		updateFields []string
+		updateValues []any
+	)
+
+	if args.Description != nil {
+		updateFields = append(updateFields, "description = @description")
+		updateValues = append(updateValues,
+			sql.Named("description", nullString(*args.Description)),
+		)
+	}
+
+	if args.ExpiresAt != nil {
+		updateFields = append(updateFields, "expires_at = @expires_at")
+		updateValues = append(updateValues,
+			sql.Named("expires_at", nullTime(*args.ExpiresAt)),
+		)
+	}
+
+	if len(updateFields) == 0 {
+		// nothing to do here!
+		return nil
+	}
+
+	updateValues = append(updateValues, sql.Named("share_id", id))
+	updateShareQuery := `
+		UPDATE shares
+		SET
+			` + strings.Join(updateFields, ",") + `
+		WHERE
+			id = @share_id
+	`
+
+	return m.execAffectingShare(ctx, updateShareQuery, updateValues...)
+}
+
+// Delete implements Sharer.
+func (m *manager) Delete(ctx context.Context, id string) error {
+	const deleteShareQuery = `
+		DELETE FROM shares
+		WHERE id = @share_id
+	`
+
+	return m.execAffectingShare(ctx, deleteShareQuery, sql.Named("share_id", id))
+}
+
+// RecordVisit implements Sharer.
+func (m *manager) RecordVisit(ctx context.Context, id string, at time.Time) error {
+	const recordVisitQuery = `
+		UPDATE shares
+		SET
+			visit_count = visit_count + 1,
+			last_visited = @visited_at
+		WHERE
+			id = @share_id
+	`
+
+	return m.execAffectingShare(ctx, recordVisitQuery,
+		sql.Named("share_id", id),
+		sql.Named("visited_at", at.Unix()),
+	)
+}
+
+// execAffectingShare executes query and returns ErrNotFound if no share was
+// affected by it.
+func (m *manager) execAffectingShare(
+	ctx context.Context,
+	query string,
+	args ...any,
+) error {
+	work := func(db *sql.DB) error {
+		res, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get number of affected rows: %w", err)
+		}
+
+		if affected < 1 {
+			return ErrNotFound
+		}
+
+		return nil
+	}
+
+	return m.executeDBJobAndWait(work)
+}
+
+// populateShare sets the items of share and all of the tracks for them.
+func populateShare(ctx context.Context, db *sql.DB, share *Share) error {
+	const getItemsQuery = `
+		SELECT item_type, item_id FROM shares_items
+		WHERE share_id = @share_id
+		ORDER BY "index"
+	`
+
+	rows, err := db.QueryContext(ctx, getItemsQuery, sql.Named("share_id", share.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get shared items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(&item.Type, &item.ID); err != nil {
+			return fmt.Errorf("failed to scan shared item: %w", err)
+		}
+
+		share.Items = append(share.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over shared items: %w", err)
+	}
+
+	var trackIDs []int64
+	seen := make(map[int64]struct{})
+	for _, item := range share.Items {
+		itemTracks, err := itemTrackIDs(ctx, db, item, share.Owner)
+		if err != nil {
+			return err
+		}
+
+		for _, trackID := range itemTracks {
+			if _, found := seen[trackID]; found {
+				continue
+			}
+			seen[trackID] = struct{}{}
+			trackIDs = append(trackIDs, trackID)
+		}
+	}
+
+	if len(trackIDs) == 0 {
+		return nil
+	}
+
+	tracksQueryArg := make([]any, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		tracksQueryArg = append(tracksQueryArg, trackID)
+	}
+
+	queryTracksWhere := []string{
+		"t.id IN (" + strings.TrimSuffix(
+			strings.Repeat("?,", len(tracksQueryArg)),
+			",",
+		) + ")",
+	}
+
+	trackRows, err := library.QueryTracks(ctx, db, queryTracksWhere, "", tracksQueryArg)
+	if err != nil {
+		return fmt.Errorf("error selecting tracks for share: %w", err)
+	}
+	defer trackRows.Close()
+
+	// tracks is a map from track ID => track info.
+	tracks := make(map[int64]library.TrackInfo, len(trackIDs))
+	for trackRows.Next() {
+		track, err := library.ScanTrack(trackRows)
+		if err != nil {
+			return fmt.Errorf("error while scanning a track: %w", err)
+		}
+
+		tracks[track.ID] = track
+	}
+
+	for _, trackID := range trackIDs {
+		trackInfo, found := tracks[trackID]
+		if !found {
+			// The track has been removed from the library since sharing.
+			continue
+		}
+
+		share.Tracks = append(share.Tracks, trackInfo)
+	}
+
+	return nil
+}
+
+// itemTrackIDs returns the IDs of all tracks for a shared item in order. Playlists
+// which are not visible for `owner` have no tracks.
+func itemTrackIDs(
+	ctx context.Context,
+	db *sql.DB,
+	item Item,
+	owner string,
+) ([]int64, error) {
+	var query string
+	switch item.Type {
+	case ItemTrack:
+		return []int64{item.ID}, nil
+	case ItemAlbum:
+		query = `
+			SELECT id FROM tracks
+			WHERE album_id = @item_id
+			ORDER BY number, id
+		`
+	case ItemPlaylist:
+		// The visibility condition must be kept in sync with
+		// [playlists.Playlist.VisibleFor].
+		query = `
+			SELECT pt.track_id FROM playlists_tracks pt
+			JOIN playlists pl ON pl.id = pt.playlist_id
+			WHERE
+				pt.playlist_id = @item_id AND
+				pt.track_id IS NOT NULL AND
+				(
+					pl.public != 0 OR
+					pl.owner IS NULL OR
+					pl.owner = @owner OR
+					EXISTS (
+						SELECT 1 FROM playlists_collaborators pc
+						WHERE pc.playlist_id = pl.id AND pc.username = @owner
+					)
+				)
+			ORDER BY pt."index"
+		`
+	default:
+		return nil, fmt.Errorf("unknown shared item type %d", item.Type)
+	}
+
+	rows, err := db.QueryContext(ctx, query,
+		sql.Named("item_id", item.ID),
+		sql.Named("owner", nullString(owner)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks for shared item: %w", err)
+	}
+	defer rows.Close()
+
+	var trackIDs []int64
+	for rows.Next() {
+		var trackID int64
+		if err := rows.Scan(&trackID); err != nil {
+			return nil, fmt.Errorf("failed to scan track ID: %w", err)
+		}
+		trackIDs = append(trackIDs, trackID)
+	}
+
+	return trackIDs, rows.Err()
+}
+
+// newShareID returns a random string which is suitable for use in URLs.
+func newShareID() (string, error) {
+	buf := make([]byte, idBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func nullString(val string) sql.NullString {
+	return sql.NullString{String: val, Valid: val != ""}
+}
+
+func nullTime(val time.Time) sql.NullInt64 {
+	return sql.NullInt64{Int64: val.Unix(), Valid: !val.IsZero()}
+}
+
+const selectShareQuery = `
+	SELECT
+		id,
+		description,
+		created_at,
+		expires_at,
+		last_visited,
+		visit_count,
+		owner
+	FROM
+		shares
+`
+
+func scanShare(row rowScanner) (Share, error) {
+	var (
+		share       Share
+		description sql.NullString
+		created     int64
+		expires     sql.NullInt64
+		lastVisited sql.NullInt64
+		owner       sql.NullString
+	)
+
+	err := row.Scan(
+		&share.ID, &description, &created,
+		&expires, &lastVisited, &share.VisitCount, &owner,
+	)
+	if err != nil {
+		return Share{}, fmt.Errorf("error scanning share: %w", err)
+	}
+
+	if description.Valid {
+		share.Description = description.String
+	}
+
+	if expires.Valid {
+		share.ExpiresAt = time.Unix(expires.Int64, 0)
+	}
+
+	if lastVisited.Valid {
+		share.LastVisited = time.Unix(lastVisited.Int64, 0)
+	}
+
+	share.Owner = owner.String
+	share.CreatedAt = time.Unix(created, 0)
+
+	return share, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
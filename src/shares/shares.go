@@ -0,0 +1,136 @@
+// Package shares deals with public links which give access to a set of tracks,
+// albums or playlists without authentication.
+package shares
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+//counterfeiter:generate . Sharer
+
+// Sharer is the interface for handling shares in Euterpe.
+type Sharer interface {
+	// Get returns a single share by its ID. The tracks for all of its items
+	// are populated. Expired shares are returned too. Playlists which are not
+	// visible for the owner of the share are left without tracks.
+	Get(ctx context.Context, id string) (Share, error)
+
+	// List returns all shares. The tracks for all of their items are populated.
+	List(ctx context.Context) ([]Share, error)
+
+	// Create creates a new share with the given create arguments.
+	//
+	// Returns the unique and randomly generated ID of the new share.
+	Create(ctx context.Context, args CreateArgs) (string, error)
+
+	// Update changes the share with ID `id`. Only the non-nil values in args
+	// are changed.
+	Update(ctx context.Context, id string, args UpdateArgs) error
+
+	// Delete removes a share by its `id`.
+	Delete(ctx context.Context, id string) error
+
+	// RecordVisit increases the visits count for the share with ID `id` and sets
+	// its last visited time to `at`.
+	RecordVisit(ctx context.Context, id string, at time.Time) error
+}
+
+// ItemType is the type of a shared item.
+type ItemType int
+
+const (
+	// ItemTrack is a single track identified by its ID.
+	ItemTrack ItemType = iota + 1
+
+	// ItemAlbum is a whole album identified by its ID.
+	ItemAlbum
+
+	// ItemPlaylist is a whole playlist identified by its ID.
+	ItemPlaylist
+)
+
+// Item is a single shared thing such as a track or album.
+type Item struct {
+	Type ItemType // Type shows what kind of thing is shared.
+	ID   int64    // ID is the ID of the track, album or playlist in the database.
+}
+
+// Share represents a single share.
+type Share struct {
+	// ID is the unique random string which identifies this share. It is used
+	// in its public URL.
+	ID string
+
+	Description string    // Description is an optional text for the share.
+	CreatedAt   time.Time // CreatedAt is the time when this share was created.
+
+	// Owner is the name of the user who created the share. It is empty for
+	// shares created before shares had owners.
+	Owner string
+
+	// ExpiresAt is the time after which the share is no longer accessible. The
+	// zero value means that the share never expires.
+	ExpiresAt time.Time
+
+	// LastVisited is the time of the last visit of the share page. It is the zero
+	// value when the share has never been visited.
+	LastVisited time.Time
+
+	// VisitCount is how many times the share page has been visited.
+	VisitCount int64
+
+	// Items is all the shared things in the order in which they were shared.
+	Items []Item
+
+	// Tracks are all the tracks for all items in order.
+	Tracks []library.TrackInfo
+}
+
+// Expired returns true when the share is no longer accessible at time `now`.
+func (s Share) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// HasTrack returns true if the track with ID `trackID` is part of the share.
+func (s Share) HasTrack(trackID int64) bool {
+	for _, track := range s.Tracks {
+		if track.ID == trackID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateArgs are the arguments needed for creating a share.
+type CreateArgs struct {
+	// Items is the list of things to share. At least one is required.
+	Items []Item
+
+	// Description is an optional short text which explains more about the share.
+	Description string
+
+	// Owner is the user who creates the share. Only playlists visible for
+	// them are shared.
+	Owner string
+
+	// ExpiresAt is an optional time after which the share will not be accessible.
+	ExpiresAt time.Time
+}
+
+// UpdateArgs is all the possible arguments which could be updated for a given
+// share.
+type UpdateArgs struct {
+	// Description sets the description of the share.
+	Description *string
+
+	// ExpiresAt sets the expiry time of the share. Setting it to the zero value
+	// makes the share never expire.
+	ExpiresAt *time.Time
+}
+
+// ErrNotFound is returned when a share was not found for a given operation.
+var ErrNotFound = errors.New("share not found")
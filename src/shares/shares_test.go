@@ -0,0 +1,255 @@
+package shares_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/shares"
+)
+
+// TestSharesManager checks that the shares manager creates, updates and removes
+// shares and resolves the tracks for all shared items.
+func TestSharesManager(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	allTracks := lib.Search(ctx, library.SearchArgs{Query: "", Count: 100})
+	if len(allTracks) < 3 {
+		t.Fatalf("not enough tracks found in the library for working with shares")
+	}
+
+	playlistsManager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	playlistID, err := playlistsManager.Create(ctx, playlists.CreateArgs{
+		Name:   "Shared Playlist",
+		Tracks: []int64{allTracks[2].ID, allTracks[1].ID},
+	})
+	assert.NilErr(t, err, "creating playlist")
+
+	albumTracks := lib.GetAlbumFiles(ctx, allTracks[0].AlbumID)
+
+	manager := shares.NewManager(lib.ExecuteDBJobAndWait)
+
+	list, err := manager.List(ctx)
+	assert.NilErr(t, err, "listing shares")
+	assert.Equal(t, 0, len(list), "did not expect any shares")
+
+	_, err = manager.Create(ctx, shares.CreateArgs{Description: "nothing"})
+	if err == nil {
+		t.Errorf("expected an error when creating a share without items")
+	}
+
+	now := time.Now()
+	id, err := manager.Create(ctx, shares.CreateArgs{
+		Description: "some description",
+		Items: []shares.Item{
+			{Type: shares.ItemPlaylist, ID: playlistID},
+			{Type: shares.ItemTrack, ID: allTracks[2].ID},
+			{Type: shares.ItemAlbum, ID: allTracks[0].AlbumID},
+		},
+	})
+	assert.NilErr(t, err, "creating share")
+	if len(id) < 20 {
+		t.Errorf("share ID `%s` is too short to be unguessable", id)
+	}
+
+	otherID, err := manager.Create(ctx, shares.CreateArgs{
+		Items:     []shares.Item{{Type: shares.ItemTrack, ID: allTracks[0].ID}},
+		ExpiresAt: now.Add(-time.Minute),
+	})
+	assert.NilErr(t, err, "creating second share")
+	if otherID == id {
+		t.Fatalf("two shares have the same ID `%s`", id)
+	}
+
+	share, err := manager.Get(ctx, id)
+	assert.NilErr(t, err, "getting share")
+	assert.Equal(t, id, share.ID, "share ID")
+	assert.Equal(t, "some description", share.Description, "share description")
+	if share.CreatedAt.Before(now.Add(-time.Second)) || share.CreatedAt.After(time.Now()) {
+		t.Errorf("share creation time %s is not around %s", share.CreatedAt, now)
+	}
+	assert.Equal(t, true, share.ExpiresAt.IsZero(), "share expiry")
+	assert.Equal(t, false, share.Expired(now), "share expired")
+	assert.Equal(t, 3, len(share.Items), "number of shared items")
+
+	expectedTracks := []int64{allTracks[2].ID, allTracks[1].ID}
+	for _, track := range albumTracks {
+		if track.ID != allTracks[1].ID && track.ID != allTracks[2].ID {
+			expectedTracks = append(expectedTracks, track.ID)
+		}
+	}
+	assert.Equal(t, len(expectedTracks), len(share.Tracks), "number of shared tracks")
+	for ind, trackID := range expectedTracks {
+		assert.Equal(t, trackID, share.Tracks[ind].ID, "track at index %d", ind)
+		assert.Equal(t, true, share.HasTrack(trackID), "has track %d", trackID)
+	}
+
+	other, err := manager.Get(ctx, otherID)
+	assert.NilErr(t, err, "getting expired share")
+	assert.Equal(t, true, other.Expired(now), "share expired")
+	assert.Equal(t, false, other.HasTrack(allTracks[2].ID), "has not shared track")
+
+	visitedAt := now.Add(time.Hour)
+	assert.NilErr(t, manager.RecordVisit(ctx, id, now), "recording visit")
+	assert.NilErr(t, manager.RecordVisit(ctx, id, visitedAt), "recording visit")
+
+	newDesc := "new description"
+	expiresAt := now.Add(24 * time.Hour)
+	err = manager.Update(ctx, id, shares.UpdateArgs{
+		Description: &newDesc,
+		ExpiresAt:   &expiresAt,
+	})
+	assert.NilErr(t, err, "updating share")
+
+	share, err = manager.Get(ctx, id)
+	assert.NilErr(t, err, "getting updated share")
+	assert.Equal(t, newDesc, share.Description, "updated description")
+	assert.Equal(t, expiresAt.Unix(), share.ExpiresAt.Unix(), "updated expiry")
+	assert.Equal(t, 2, share.VisitCount, "visit count")
+	assert.Equal(t, visitedAt.Unix(), share.LastVisited.Unix(), "last visited")
+
+	list, err = manager.List(ctx)
+	assert.NilErr(t, err, "listing shares")
+	assert.Equal(t, 2, len(list), "number of shares")
+
+	assert.NilErr(t, manager.Delete(ctx, id), "deleting share")
+
+	_, err = manager.Get(ctx, id)
+	if !errors.Is(err, shares.ErrNotFound) {
+		t.Errorf("get: expected 'not found' error but got: %v", err)
+	}
+
+	err = manager.Update(ctx, id, shares.UpdateArgs{Description: &newDesc})
+	if !errors.Is(err, shares.ErrNotFound) {
+		t.Errorf("update: expected 'not found' error but got: %v", err)
+	}
+
+	err = manager.RecordVisit(ctx, id, now)
+	if !errors.Is(err, shares.ErrNotFound) {
+		t.Errorf("visit: expected 'not found' error but got: %v", err)
+	}
+
+	err = manager.Delete(ctx, id)
+	if !errors.Is(err, shares.ErrNotFound) {
+		t.Errorf("delete: expected 'not found' error but got: %v", err)
+	}
+}
+
+// TestSharesPrivatePlaylists checks that shared playlists have tracks only while
+// they are visible for the owner of the share.
+func TestSharesPrivatePlaylists(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	allTracks := lib.Search(ctx, library.SearchArgs{Query: "", Count: 100})
+	if len(allTracks) < 2 {
+		t.Fatalf("not enough tracks found in the library for working with shares")
+	}
+
+	private := false
+	playlistsManager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	playlistID, err := playlistsManager.Create(ctx, playlists.CreateArgs{
+		Name:          "Private Playlist",
+		Owner:         "playlist-owner",
+		Public:        &private,
+		Collaborators: []string{"collaborator"},
+		Tracks:        []int64{allTracks[0].ID, allTracks[1].ID},
+	})
+	assert.NilErr(t, err, "creating playlist")
+
+	manager := shares.NewManager(lib.ExecuteDBJobAndWait)
+
+	tests := []struct {
+		owner          string
+		expectedTracks int
+	}{
+		{owner: "playlist-owner", expectedTracks: 2},
+		{owner: "collaborator", expectedTracks: 2},
+		{owner: "someone-else", expectedTracks: 0},
+		{owner: "", expectedTracks: 0},
+	}
+	for _, test := range tests {
+		id, err := manager.Create(ctx, shares.CreateArgs{
+			Items: []shares.Item{{Type: shares.ItemPlaylist, ID: playlistID}},
+			Owner: test.owner,
+		})
+		assert.NilErr(t, err, "creating share for %q", test.owner)
+
+		share, err := manager.Get(ctx, id)
+		assert.NilErr(t, err, "getting share for %q", test.owner)
+		assert.Equal(t, test.owner, share.Owner, "share owner")
+		assert.Equal(t, test.expectedTracks, len(share.Tracks),
+			"shared tracks for %q", test.owner)
+	}
+
+	// Making the playlist public shares its tracks with everyone.
+	public := true
+	err = playlistsManager.Update(ctx, playlistID, playlists.UpdateArgs{
+		User:   "playlist-owner",
+		Public: &public,
+	})
+	assert.NilErr(t, err, "making playlist public")
+
+	list, err := manager.List(ctx)
+	assert.NilErr(t, err, "listing shares")
+	for _, share := range list {
+		assert.Equal(t, 2, len(share.Tracks), "tracks of public playlist share")
+	}
+}
+
+// getTestMigrationFiles returns the SQLs directory used by the application itself
+// normally. This way tests will be done with the exact same files which will be
+// bundled into the binary on build.
+func getTestMigrationFiles() fs.FS {
+	return os.DirFS("../../sqls")
+}
+
+// getLibrary returns a library with all test files scanned into it.
+func getLibrary(ctx context.Context, t *testing.T) *library.LocalLibrary {
+	migrationsFS := getTestMigrationFiles()
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, migrationsFS)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	return lib
+}
@@ -0,0 +1,440 @@
+package similar
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+const (
+	// sessionLength is the time in seconds before and after a play of a seed
+	// track in which other plays are considered to be from the same listening
+	// session.
+	sessionLength = 30 * 60
+
+	// yearDistance is the maximum number of years between tracks which are
+	// considered to be from the same era.
+	yearDistance = 2
+
+	// maxHits caps the hits of a single signal for a candidate so that no signal
+	// could dominate all others.
+	maxHits = 5
+)
+
+// signal is a single source of similarity between the seeds and a candidate
+// track.
+type signal struct {
+	// weight is how much a single hit of this signal adds to the score of a
+	// candidate.
+	weight int
+
+	// query selects the `track_id` and `hits` columns for candidate tracks. It
+	// could use the `seeds`, `seed_years` and `seed_genres` common table
+	// expressions.
+	query string
+}
+
+// isFavourite is an SQL expression which is true when the track `t`, its album
+// or its artist has been added to the favourites.
+const isFavourite = `(
+	EXISTS (
+		SELECT 1 FROM user_stats
+		WHERE track_id = t.id AND favourite > 0
+	) OR EXISTS (
+		SELECT 1 FROM albums_stats
+		WHERE album_id = t.album_id AND favourite > 0
+	) OR EXISTS (
+		SELECT 1 FROM artists_stats
+		WHERE artist_id = t.artist_id AND favourite > 0
+	)
+)`
+
+// signals are all sources of similarity.
+var signals = []signal{
+	{
+		// Tracks which are in the same playlists as the seeds.
+		weight: 3,
+		query: `
+			SELECT other.track_id AS track_id, COUNT(*) AS hits
+			FROM playlists_tracks AS seed_pt
+				JOIN playlists_tracks AS other
+					ON other.playlist_id = seed_pt.playlist_id
+					AND other.track_id != seed_pt.track_id
+			WHERE seed_pt.track_id IN (SELECT id FROM seeds)
+			GROUP BY other.track_id
+		`,
+	},
+	{
+		// Tracks which were played in the same listening sessions as the seeds.
+		weight: 2,
+		query: `
+			SELECT other.track_id AS track_id, COUNT(*) AS hits
+			FROM track_plays AS seed_tp
+				JOIN track_plays AS other
+					ON other.played_at BETWEEN
+						seed_tp.played_at - @session_length AND
+						seed_tp.played_at + @session_length
+					AND other.track_id != seed_tp.track_id
+			WHERE seed_tp.track_id IN (SELECT id FROM seeds)
+			GROUP BY other.track_id
+		`,
+	},
+	{
+		// Favourite tracks are similar to each other.
+		weight: 1,
+		query: `
+			SELECT t.id AS track_id, 1 AS hits
+			FROM tracks AS t
+			WHERE ` + isFavourite + ` AND EXISTS (
+				SELECT 1 FROM tracks AS t
+				WHERE t.id IN (SELECT id FROM seeds) AND ` + isFavourite + `
+			)
+		`,
+	},
+	{
+		// Tracks by the same artists as the seeds.
+		weight: 2,
+		query: `
+			SELECT t.id AS track_id, 1 AS hits
+			FROM tracks AS t
+			WHERE t.artist_id IN (SELECT artist_id FROM seeds)
+		`,
+	},
+	{
+		// Tracks of the same genres. Genres are compared case insensitively
+		// since tags are not consistent about it.
+		weight: 2,
+		query: `
+			SELECT t.id AS track_id, 1 AS hits
+			FROM tracks AS t
+			WHERE t.genre IS NOT NULL
+				AND lower(t.genre) IN (SELECT genre FROM seed_genres)
+		`,
+	},
+	{
+		// Tracks from neighbouring years.
+		weight: 1,
+		query: `
+			SELECT t.id AS track_id, 1 AS hits
+			FROM tracks AS t
+			WHERE t.year > 0 AND EXISTS (
+				SELECT 1 FROM seed_years AS sy
+				WHERE abs(t.year - sy.year) <= @year_distance
+			)
+		`,
+	},
+}
+
+// finder implements the Finder interface by just requiring a function for
+// sending database work.
+type finder struct {
+	executeDBJobAndWait func(library.DatabaseExecutable) error
+}
+
+// NewFinder returns a Finder which will send SQL queries to `sendDBWork`.
+func NewFinder(sendDBWork func(library.DatabaseExecutable) error) Finder {
+	return &finder{
+		executeDBJobAndWait: sendDBWork,
+	}
+}
+
+// SimilarTracks implements Finder.
+func (f *finder) SimilarTracks(
+	ctx context.Context,
+	args Args,
+) ([]library.TrackInfo, error) {
+	if !args.hasSeeds() {
+		return nil, fmt.Errorf("at least one seed is required")
+	}
+	if args.Count <= 0 {
+		return nil, nil
+	}
+
+	var (
+		where     []string
+		whereArgs []any
+	)
+
+	excluded := append(append([]int64{}, args.TrackIDs...), args.ExcludeTrackIDs...)
+	if len(excluded) > 0 {
+		list, listArgs := namedList("exclude", excluded)
+		where = append(where, "t.id NOT IN ("+list+")")
+		whereArgs = append(whereArgs, listArgs...)
+	}
+
+	var tracks []library.TrackInfo
+	work := func(db *sql.DB) error {
+		ids, err := scoreCandidates(ctx, db, "t.id", args, where, whereArgs)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		list, listArgs := namedList("track", ids)
+		rows, err := library.QueryTracks(
+			ctx, db, []string{"t.id IN (" + list + ")"}, "", listArgs,
+		)
+		if err != nil {
+			return fmt.Errorf("error selecting similar tracks: %w", err)
+		}
+		defer rows.Close()
+
+		found := make(map[int64]library.TrackInfo, len(ids))
+		for rows.Next() {
+			track, err := library.ScanTrack(rows)
+			if err != nil {
+				return fmt.Errorf("error while scanning a track: %w", err)
+			}
+			found[track.ID] = track
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over tracks: %w", err)
+		}
+
+		for _, id := range ids {
+			if track, ok := found[id]; ok {
+				tracks = append(tracks, track)
+			}
+		}
+
+		return nil
+	}
+	if err := f.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// SimilarArtists implements Finder.
+func (f *finder) SimilarArtists(
+	ctx context.Context,
+	args Args,
+) ([]library.Artist, error) {
+	if !args.hasSeeds() {
+		return nil, fmt.Errorf("at least one seed is required")
+	}
+	if args.Count <= 0 {
+		return nil, nil
+	}
+
+	where := []string{"t.artist_id NOT IN (SELECT artist_id FROM seeds)"}
+	var whereArgs []any
+	if len(args.ExcludeTrackIDs) > 0 {
+		list, listArgs := namedList("exclude", args.ExcludeTrackIDs)
+		where = append(where, "t.id NOT IN ("+list+")")
+		whereArgs = append(whereArgs, listArgs...)
+	}
+
+	var artists []library.Artist
+	work := func(db *sql.DB) error {
+		ids, err := scoreCandidates(ctx, db, "t.artist_id", args, where, whereArgs)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		list, listArgs := namedList("artist", ids)
+		query := `
+			SELECT
+				ar.id,
+				ar.name,
+				COUNT(DISTINCT(tr.album_id)) as album_count,
+				ars.favourite,
+				ars.user_rating
+			FROM artists AS ar
+				JOIN tracks AS tr ON tr.artist_id = ar.id
+				LEFT JOIN artists_stats AS ars ON ars.artist_id = ar.id
+			WHERE
+				ar.id IN (` + list + `)
+			GROUP BY
+				ar.id
+		`
+
+		rows, err := db.QueryContext(ctx, query, listArgs...)
+		if err != nil {
+			return fmt.Errorf("error selecting similar artists: %w", err)
+		}
+		defer rows.Close()
+
+		found := make(map[int64]library.Artist, len(ids))
+		for rows.Next() {
+			var (
+				artist library.Artist
+				fav    sql.NullInt64
+				rating sql.NullInt16
+			)
+			err := rows.Scan(
+				&artist.ID, &artist.Name, &artist.AlbumCount, &fav, &rating,
+			)
+			if err != nil {
+				return fmt.Errorf("error while scanning an artist: %w", err)
+			}
+			if fav.Valid {
+				artist.Favourite = fav.Int64
+			}
+			if rating.Valid {
+				artist.Rating = uint8(rating.Int16)
+			}
+			found[artist.ID] = artist
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over artists: %w", err)
+		}
+
+		for _, id := range ids {
+			if artist, ok := found[id]; ok {
+				artists = append(artists, artist)
+			}
+		}
+
+		return nil
+	}
+	if err := f.executeDBJobAndWait(work); err != nil {
+		return nil, err
+	}
+
+	return artists, nil
+}
+
+// scoreCandidates returns the IDs of the most similar candidates to the seeds
+// in args. Candidates are grouped by the `groupBy` column of the `t` tracks
+// table. `where` is a list of additional conditions for the candidate tracks.
+// The result is ordered from the most to the least similar candidate.
+func scoreCandidates(
+	ctx context.Context,
+	db *sql.DB,
+	groupBy string,
+	args Args,
+	where []string,
+	whereArgs []any,
+) ([]int64, error) {
+	var (
+		seedConds []string
+		queryArgs = []any{
+			sql.Named("session_length", sessionLength),
+			sql.Named("year_distance", yearDistance),
+			sql.Named("max_hits", maxHits),
+			sql.Named("count", args.Count),
+		}
+	)
+
+	for _, seed := range []struct {
+		column string
+		ids    []int64
+	}{
+		{"id", args.TrackIDs},
+		{"album_id", args.AlbumIDs},
+		{"artist_id", args.ArtistIDs},
+	} {
+		if len(seed.ids) == 0 {
+			continue
+		}
+
+		list, listArgs := namedList("seed_"+seed.column, seed.ids)
+		seedConds = append(seedConds, seed.column+" IN ("+list+")")
+		queryArgs = append(queryArgs, listArgs...)
+	}
+
+	if len(args.MusicFolderIDs) > 0 {
+		list, listArgs := namedList("music_folder", args.MusicFolderIDs)
+		where = append(where, "t.music_folder_id IN ("+list+")")
+		queryArgs = append(queryArgs, listArgs...)
+	}
+	queryArgs = append(queryArgs, whereArgs...)
+
+	var (
+		signalQueries []string
+		weights       []string
+	)
+	for ind, sig := range signals {
+		signalQueries = append(signalQueries, fmt.Sprintf(
+			"SELECT track_id, %d AS signal, hits FROM (%s)", ind, sig.query,
+		))
+		weights = append(weights, fmt.Sprintf("WHEN %d THEN %d", ind, sig.weight))
+	}
+
+	whereStr := ""
+	if len(where) > 0 {
+		whereStr = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := `
+		WITH
+			seeds AS (
+				SELECT id, artist_id, album_id, year, genre
+				FROM tracks
+				WHERE ` + strings.Join(seedConds, " OR ") + `
+			),
+			seed_years AS (
+				SELECT DISTINCT year FROM seeds WHERE year > 0
+			),
+			seed_genres AS (
+				SELECT DISTINCT lower(genre) AS genre
+				FROM seeds
+				WHERE genre IS NOT NULL AND genre != ''
+			),
+			signals AS (
+				` + strings.Join(signalQueries, "\nUNION ALL\n") + `
+			),
+			candidates AS (
+				SELECT ` + groupBy + ` AS id, signal, SUM(hits) AS hits
+				FROM signals
+					JOIN tracks AS t ON t.id = signals.track_id
+				` + whereStr + `
+				GROUP BY ` + groupBy + `, signal
+			)
+		SELECT
+			id,
+			SUM(
+				MIN(hits, @max_hits) * CASE signal ` + strings.Join(weights, " ") + ` END
+			) AS score
+		FROM candidates
+		GROUP BY id
+		ORDER BY score DESC, RANDOM()
+		LIMIT @count
+	`
+
+	rows, err := db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("error scoring similar candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var (
+			id    int64
+			score int64
+		)
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, fmt.Errorf("error scanning similar candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// namedList returns a comma separated list with named SQL parameters for all
+// values and the arguments for them. The parameters are named after `prefix`.
+func namedList(prefix string, values []int64) (string, []any) {
+	names := make([]string, 0, len(values))
+	args := make([]any, 0, len(values))
+	for ind, val := range values {
+		name := fmt.Sprintf("%s_%d", prefix, ind)
+		names = append(names, "@"+name)
+		args = append(args, sql.Named(name, val))
+	}
+
+	return strings.Join(names, ", "), args
+}
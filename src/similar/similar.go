@@ -0,0 +1,53 @@
+// Package similar finds tracks and artists which are similar to other tracks,
+// albums or artists. It does not use any external services. Instead the
+// similarity is calculated only from data already stored in the library such as
+// playlists, the play history, favourites and genres.
+package similar
+
+import (
+	"context"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+//counterfeiter:generate . Finder
+
+// Finder is the interface for finding similar things in the library.
+type Finder interface {
+	// SimilarTracks returns up to args.Count tracks which are similar to the
+	// seeds in args. The most similar tracks are first. Tracks from args.TrackIDs
+	// are never returned.
+	SimilarTracks(ctx context.Context, args Args) ([]library.TrackInfo, error)
+
+	// SimilarArtists returns up to args.Count artists which are similar to the
+	// seeds in args. The most similar artists are first. Artists of the seeds
+	// are never returned.
+	SimilarArtists(ctx context.Context, args Args) ([]library.Artist, error)
+}
+
+// Args are the arguments for finding similar things. At least one seed is
+// required.
+type Args struct {
+	// TrackIDs are tracks used as seeds.
+	TrackIDs []int64
+
+	// AlbumIDs are albums used as seeds. All of their tracks are seeds.
+	AlbumIDs []int64
+
+	// ArtistIDs are artists used as seeds. All of their tracks are seeds.
+	ArtistIDs []int64
+
+	// ExcludeTrackIDs is a list of tracks which must not be returned.
+	ExcludeTrackIDs []int64
+
+	// MusicFolderIDs limits the results to the music folders with these IDs.
+	// When empty results from all music folders are returned.
+	MusicFolderIDs []int64
+
+	// Count is the maximum number of results.
+	Count int
+}
+
+func (a Args) hasSeeds() bool {
+	return len(a.TrackIDs) > 0 || len(a.AlbumIDs) > 0 || len(a.ArtistIDs) > 0
+}
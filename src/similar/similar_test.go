@@ -0,0 +1,262 @@
+package similar_test
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/helpers"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/similar"
+)
+
+// TestSimilarTracksAndArtists checks that similarity is found from playlists,
+// the play history, the artists, the genres and the years of the tracks.
+// "Payback" is a "Horror" track from 2013 while the other two tracks are "Tester"
+// tracks by another artist from 2014.
+func TestSimilarTracksAndArtists(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	var (
+		payback    = findTrack(ctx, t, lib, "Payback")
+		tittled    = findTrack(ctx, t, lib, "Tittled Track")
+		anotherOne = findTrack(ctx, t, lib, "Another One")
+	)
+
+	finder := similar.NewFinder(lib.ExecuteDBJobAndWait)
+
+	_, err := finder.SimilarTracks(ctx, similar.Args{Count: 10})
+	if err == nil {
+		t.Errorf("expected an error when there are no seeds")
+	}
+
+	assertTracks(ctx, t, finder, "same artist and neighbouring year", similar.Args{
+		TrackIDs: []int64{tittled.ID},
+		Count:    10,
+	}, anotherOne.ID, payback.ID)
+
+	assertArtists(ctx, t, finder, "neighbouring years", similar.Args{
+		ArtistIDs: []int64{payback.ArtistID},
+		Count:     10,
+	}, tittled.ArtistID)
+
+	playedAt := time.Now()
+	err = lib.RecordTrackPlay(ctx, payback.ID, playedAt)
+	assert.NilErr(t, err, "recording play")
+	err = lib.RecordTrackPlay(ctx, anotherOne.ID, playedAt.Add(5*time.Minute))
+	assert.NilErr(t, err, "recording play")
+
+	assertTracks(ctx, t, finder, "same listening session", similar.Args{
+		TrackIDs: []int64{payback.ID},
+		Count:    10,
+	}, anotherOne.ID, tittled.ID)
+
+	playlistsManager := playlists.NewManager(lib.ExecuteDBJobAndWait)
+	_, err = playlistsManager.Create(ctx, playlists.CreateArgs{
+		Name:   "Mixed",
+		Tracks: []int64{tittled.ID, payback.ID},
+	})
+	assert.NilErr(t, err, "creating playlist")
+
+	assertTracks(ctx, t, finder, "shared playlist", similar.Args{
+		TrackIDs: []int64{payback.ID},
+		Count:    10,
+	}, tittled.ID, anotherOne.ID)
+
+	assertTracks(ctx, t, finder, "limited count", similar.Args{
+		TrackIDs: []int64{payback.ID},
+		Count:    1,
+	}, tittled.ID)
+
+	assertTracks(ctx, t, finder, "excluded tracks", similar.Args{
+		TrackIDs:        []int64{payback.ID},
+		ExcludeTrackIDs: []int64{tittled.ID},
+		Count:           10,
+	}, anotherOne.ID)
+
+	assertTracks(ctx, t, finder, "missing music folder", similar.Args{
+		TrackIDs:       []int64{tittled.ID},
+		MusicFolderIDs: []int64{-1},
+		Count:          10,
+	})
+
+	// The tracks of the seed artist share its genre so they come first.
+	assertTracks(ctx, t, finder, "artist seed", similar.Args{
+		ArtistIDs: []int64{payback.ArtistID},
+		Count:     2,
+	}, payback.ID, tittled.ID)
+
+	assertArtists(ctx, t, finder, "similar artist", similar.Args{
+		ArtistIDs: []int64{payback.ArtistID},
+		Count:     10,
+	}, tittled.ArtistID)
+
+	assertArtists(ctx, t, finder, "similar artist for album", similar.Args{
+		AlbumIDs: []int64{tittled.AlbumID},
+		Count:    10,
+	}, payback.ArtistID)
+}
+
+// TestSimilarTracksGenre checks that tracks of the same genre as the seeds are
+// more similar than the rest.
+func TestSimilarTracksGenre(t *testing.T) {
+	ctx := t.Context()
+
+	lib := getLibrary(ctx, t)
+	defer func() {
+		_ = lib.Truncate()
+	}()
+
+	var (
+		payback    = findTrack(ctx, t, lib, "Payback")
+		tittled    = findTrack(ctx, t, lib, "Tittled Track")
+		anotherOne = findTrack(ctx, t, lib, "Another One")
+	)
+
+	for trackID, genre := range map[int64]string{
+		payback.ID:    "Trip Hop",
+		tittled.ID:    "Jazz",
+		anotherOne.ID: "trip hop",
+	} {
+		err := lib.ExecuteDBJobAndWait(func(db *sql.DB) error {
+			_, err := db.ExecContext(ctx,
+				`UPDATE tracks SET genre = @genre WHERE id = @id`,
+				sql.Named("genre", genre),
+				sql.Named("id", trackID),
+			)
+			return err
+		})
+		assert.NilErr(t, err, "setting genre of track %d", trackID)
+	}
+
+	finder := similar.NewFinder(lib.ExecuteDBJobAndWait)
+
+	assertTracks(ctx, t, finder, "same genre", similar.Args{
+		TrackIDs: []int64{payback.ID},
+		Count:    1,
+	}, anotherOne.ID)
+
+	assertArtists(ctx, t, finder, "artist of the same genre", similar.Args{
+		ArtistIDs: []int64{payback.ArtistID},
+		Count:     10,
+	}, anotherOne.ArtistID)
+}
+
+func assertTracks(
+	ctx context.Context,
+	t *testing.T,
+	finder similar.Finder,
+	desc string,
+	args similar.Args,
+	expected ...int64,
+) {
+	t.Helper()
+
+	tracks, err := finder.SimilarTracks(ctx, args)
+	assert.NilErr(t, err, "%s: finding similar tracks", desc)
+
+	var found []int64
+	for _, track := range tracks {
+		found = append(found, track.ID)
+	}
+
+	if !slices.Equal(expected, found) {
+		t.Errorf("%s: expected similar tracks %v but got %v", desc, expected, found)
+	}
+}
+
+func assertArtists(
+	ctx context.Context,
+	t *testing.T,
+	finder similar.Finder,
+	desc string,
+	args similar.Args,
+	expected ...int64,
+) {
+	t.Helper()
+
+	artists, err := finder.SimilarArtists(ctx, args)
+	assert.NilErr(t, err, "%s: finding similar artists", desc)
+
+	var found []int64
+	for _, artist := range artists {
+		if artist.Name == "" {
+			t.Errorf("%s: artist %d has no name", desc, artist.ID)
+		}
+		found = append(found, artist.ID)
+	}
+
+	if !slices.Equal(expected, found) {
+		t.Errorf("%s: expected similar artists %v but got %v", desc, expected, found)
+	}
+}
+
+func findTrack(
+	ctx context.Context,
+	t *testing.T,
+	lib *library.LocalLibrary,
+	title string,
+) library.TrackInfo {
+	t.Helper()
+
+	for _, track := range lib.Search(ctx, library.SearchArgs{Query: title}) {
+		if track.Title == title {
+			return track
+		}
+	}
+
+	t.Fatalf("track `%s` not found in the test library", title)
+	return library.TrackInfo{}
+}
+
+// getTestMigrationFiles returns the SQLs directory used by the application itself
+// normally. This way tests will be done with the exact same files which will be
+// bundled into the binary on build.
+func getTestMigrationFiles() fs.FS {
+	return os.DirFS("../../sqls")
+}
+
+// getLibrary returns a library with all test files scanned into it.
+func getLibrary(ctx context.Context, t *testing.T) *library.LocalLibrary {
+	migrationsFS := getTestMigrationFiles()
+	lib, err := library.NewLocalLibrary(ctx, library.SQLiteMemoryFile, migrationsFS)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = lib.Initialize()
+	if err != nil {
+		t.Fatalf("Initializing library: %s", err)
+	}
+
+	projRoot, err := helpers.ProjectRoot()
+	assert.NilErr(t, err, "getting the repository root directory")
+
+	lib.AddLibraryPath(filepath.Join(projRoot, "test_files", "library"))
+
+	ch := make(chan struct{})
+	go func() {
+		lib.Scan()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out after waiting for library scan to complete")
+	}
+
+	return lib
+}
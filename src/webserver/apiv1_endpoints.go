@@ -14,9 +14,27 @@ const (
 	APIv1EndpointSearch         = "/v1/search/"
 	APIv1EndpointLoginToken     = "/v1/login/token/"
 	APIv1EndpointRegisterToken  = "/v1/register/token/"
+	APIv1EndpointArtistRadio    = "/v1/artist-radio"
+	APIv1EndpointNowPlaying     = "/v1/now-playing"
+	APIv1EndpointTrackTags      = "/v1/track/{trackID}/tags"
+	APIv1EndpointAlbumTags      = "/v1/album/{albumID}/tags"
 
-	APIv1EndpointPlaylists = "/v1/playlists"
-	APIv1EndpointPlaylist  = "/v1/playlist/{playlistID}"
+	APIv1EndpointPlaylists       = "/v1/playlists"
+	APIv1EndpointPlaylist        = "/v1/playlist/{playlistID}"
+	APIv1EndpointPlaylistsImport = "/v1/playlists/import"
+	APIv1EndpointPlaylistExport  = "/v1/playlist/{playlistID:[0-9]+}.{format:m3u8|pls|xspf}"
+	APIv1EndpointPlaylistImage   = "/v1/playlist/{playlistID:[0-9]+}/image"
+
+	APIv1EndpointBookmarks = "/v1/bookmarks"
+	APIv1EndpointBookmark  = "/v1/bookmark/{trackID}"
+
+	APIv1EndpointPlayQueue = "/v1/play-queue"
+
+	APIv1EndpointLibraryScan       = "/v1/library/scan"
+	APIv1EndpointLibraryDuplicates = "/v1/library/duplicates"
+	APIv1EndpointLibraryHealth     = "/v1/library/health"
+
+	APIv1EndpointUserAvatar = "/v1/user/{username}/avatar"
 )
 
 // APIv1Methods defines on which HTTP methods APIv1 endpoints will respond to.
@@ -30,6 +48,10 @@ var APIv1Methods map[string][]string = map[string][]string{
 	APIv1EndpointSearch:         {http.MethodGet},
 	APIv1EndpointLoginToken:     {http.MethodPost},
 	APIv1EndpointRegisterToken:  {http.MethodPost},
+	APIv1EndpointArtistRadio:    {http.MethodGet},
+	APIv1EndpointNowPlaying:     {http.MethodGet},
+	APIv1EndpointTrackTags:      {http.MethodPatch},
+	APIv1EndpointAlbumTags:      {http.MethodPatch},
 	APIv1EndpointArtistImage: {
 		http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
 	},
@@ -41,4 +63,24 @@ var APIv1Methods map[string][]string = map[string][]string{
 	APIv1EndpointPlaylist: {
 		http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete,
 	},
+	APIv1EndpointPlaylistsImport: {http.MethodPost},
+	APIv1EndpointPlaylistExport:  {http.MethodGet},
+	APIv1EndpointPlaylistImage: {
+		http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	},
+
+	APIv1EndpointBookmarks: {http.MethodGet},
+	APIv1EndpointBookmark: {
+		http.MethodGet, http.MethodPut, http.MethodDelete,
+	},
+
+	APIv1EndpointPlayQueue: {http.MethodGet, http.MethodPut},
+
+	APIv1EndpointLibraryScan:       {http.MethodGet, http.MethodPost},
+	APIv1EndpointLibraryDuplicates: {http.MethodGet, http.MethodPost},
+	APIv1EndpointLibraryHealth:     {http.MethodGet},
+
+	APIv1EndpointUserAvatar: {
+		http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	},
 }
@@ -1,7 +1,6 @@
 package webserver
 
 import (
-	"crypto/subtle"
 	"log"
 	"net/http"
 
@@ -35,12 +34,8 @@ func WithInternalError(fnc HandlerFuncWithError) http.HandlerFunc {
 	}
 }
 
-// The following check is carefully orchestrated so that it will take constant
-// time for wrong and correct pairs of username and password. This mitigates
-// simple timing attacks.
+// checkLoginCreds returns true when user and pass are the configured credentials.
+// The password hash is used when one is configured.
 func checkLoginCreds(user, pass string, auth config.Auth) bool {
-	userCheck := subtle.ConstantTimeCompare([]byte(user), []byte(auth.User))
-	passCheck := subtle.ConstantTimeCompare([]byte(pass), []byte(auth.Password))
-
-	return userCheck&passCheck == 1
+	return auth.CheckCredentials(user, pass)
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 )
 
@@ -18,6 +19,7 @@ import (
 // album by the album ID.
 type AlbumHandler struct {
 	library library.Library
+	auth    config.Auth
 }
 
 // ServeHTTP is required by the http.Handler's interface
@@ -52,6 +54,13 @@ func (fh AlbumHandler) find(writer http.ResponseWriter, req *http.Request) error
 		return nil
 	}
 
+	if !canAccessMusicFolders(
+		req.Context(), fh.library, fh.auth, int64(id), fh.library.AlbumInMusicFolders,
+	) {
+		http.NotFoundHandler().ServeHTTP(writer, req)
+		return nil
+	}
+
 	albumFiles := fh.library.GetAlbumFiles(req.Context(), int64(id))
 
 	if len(albumFiles) < 1 {
@@ -120,9 +129,12 @@ func (fh AlbumHandler) writeZipContents(writer io.Writer, files []string) (int64
 	return written, zipWriter.Close()
 }
 
-// NewAlbumHandler returns a new Album handler. It needs a library to search in
-func NewAlbumHandler(lib library.Library) *AlbumHandler {
+// NewAlbumHandler returns a new Album handler. It needs a library to search in.
+// Albums outside of the music folders which the `auth` user is allowed to access
+// are not found.
+func NewAlbumHandler(lib library.Library, auth config.Auth) *AlbumHandler {
 	fh := new(AlbumHandler)
 	fh.library = lib
+	fh.auth = auth
 	return fh
 }
@@ -0,0 +1,198 @@
+package webserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+const (
+	// artistRadioDefaultCount is the number of tracks returned by the artist
+	// radio when the client has not asked for a particular number.
+	artistRadioDefaultCount = 20
+
+	// artistRadioMaxCount is the maximum number of tracks returned by a single
+	// artist radio request.
+	artistRadioMaxCount = 100
+
+	// artistRadioPoolFactor controls how many more similar tracks than requested
+	// are selected. The returned tracks are picked at random from this pool so
+	// that consecutive requests do not return the same tracks over and over.
+	artistRadioPoolFactor = 3
+)
+
+// artistRadioHandler returns a shuffled list of tracks similar to a track or an
+// artist. Clients are expected to call it again with the already queued tracks
+// excluded whenever they run low on tracks. This way the radio never ends.
+type artistRadioHandler struct {
+	finder  similar.Finder
+	browser library.Browser
+	library library.Library
+	auth    config.Auth
+}
+
+// NewArtistRadioHandler returns an HTTP handler which uses `finder` for selecting
+// the tracks for the radio. When there are not enough similar tracks the radio is
+// filled with random tracks from `browser`. Only tracks from the music folders of
+// `lib` which the user from `auth` could access are selected.
+func NewArtistRadioHandler(
+	finder similar.Finder,
+	browser library.Browser,
+	lib library.Library,
+	auth config.Auth,
+) http.Handler {
+	return &artistRadioHandler{
+		finder:  finder,
+		browser: browser,
+		library: lib,
+		auth:    auth,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *artistRadioHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	InternalErrorOnErrorHandler(w, req, h.radio)
+}
+
+func (h *artistRadioHandler) radio(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if err := req.ParseForm(); err != nil {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	args, err := getArtistRadioArgs(req)
+	if err != nil {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	args.MusicFolderIDs, err = allowedMusicFolderIDs(req.Context(), h.library, h.auth)
+	if err != nil {
+		return err
+	}
+
+	count := args.Count
+	args.Count = count * artistRadioPoolFactor
+
+	tracks, err := h.finder.SimilarTracks(req.Context(), args)
+	if err != nil {
+		return fmt.Errorf("finding similar tracks: %w", err)
+	}
+
+	rand.Shuffle(len(tracks), func(i, j int) {
+		tracks[i], tracks[j] = tracks[j], tracks[i]
+	})
+	if len(tracks) > count {
+		tracks = tracks[:count]
+	}
+
+	if len(tracks) < count {
+		tracks = h.fillWithRandom(tracks, count, args)
+	}
+
+	resp := struct {
+		Tracks []library.TrackInfo `json:"tracks"`
+	}{
+		Tracks: tracks,
+	}
+	if resp.Tracks == nil {
+		resp.Tracks = []library.TrackInfo{}
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(resp)
+}
+
+// fillWithRandom adds random tracks to `tracks` until there are `count` of them or
+// there are no more tracks in the library. Seeds, excluded tracks and tracks which
+// are already in the list are not added again.
+func (h *artistRadioHandler) fillWithRandom(
+	tracks []library.TrackInfo,
+	count int,
+	args similar.Args,
+) []library.TrackInfo {
+	skip := make(map[int64]struct{})
+	for _, id := range slices.Concat(args.TrackIDs, args.ExcludeTrackIDs) {
+		skip[id] = struct{}{}
+	}
+	for _, track := range tracks {
+		skip[track.ID] = struct{}{}
+	}
+
+	random, _ := h.browser.BrowseTracks(library.BrowseArgs{
+		PerPage:        uint(count + len(skip)),
+		OrderBy:        library.OrderByRandom,
+		MusicFolderIDs: args.MusicFolderIDs,
+	})
+	for _, track := range random {
+		if len(tracks) >= count {
+			break
+		}
+		if _, ok := skip[track.ID]; ok {
+			continue
+		}
+		skip[track.ID] = struct{}{}
+		tracks = append(tracks, track)
+	}
+
+	return tracks
+}
+
+// getArtistRadioArgs parses the query of the artist radio request. Exactly one of
+// `track_id` or `artist_id` must be set.
+func getArtistRadioArgs(req *http.Request) (similar.Args, error) {
+	args := similar.Args{
+		Count: artistRadioDefaultCount,
+	}
+
+	trackID := req.Form.Get("track_id")
+	artistID := req.Form.Get("artist_id")
+	if (trackID == "") == (artistID == "") {
+		return args, fmt.Errorf("exactly one of track_id or artist_id is required")
+	}
+
+	if trackID != "" {
+		id, err := strconv.ParseInt(trackID, 10, 64)
+		if err != nil {
+			return args, fmt.Errorf("malformed track_id: %w", err)
+		}
+		args.TrackIDs = []int64{id}
+	} else {
+		id, err := strconv.ParseInt(artistID, 10, 64)
+		if err != nil {
+			return args, fmt.Errorf("malformed artist_id: %w", err)
+		}
+		args.ArtistIDs = []int64{id}
+	}
+
+	if countStr := req.Form.Get("count"); countStr != "" {
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			return args, fmt.Errorf("count must be a positive integer")
+		}
+		args.Count = min(count, artistRadioMaxCount)
+	}
+
+	if exclude := req.Form.Get("exclude"); exclude != "" {
+		for _, idStr := range strings.Split(exclude, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+			if err != nil {
+				return args, fmt.Errorf("malformed track ID in exclude: %w", err)
+			}
+			args.ExcludeTrackIDs = append(args.ExcludeTrackIDs, id)
+		}
+	}
+
+	return args, nil
+}
@@ -0,0 +1,171 @@
+package webserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/similar/similarfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestArtistRadioHandler checks that the artist radio parses its arguments, returns
+// only similar tracks when there are enough of them and fills the rest with random
+// tracks otherwise.
+func TestArtistRadioHandler(t *testing.T) {
+	similarTracks := []library.TrackInfo{
+		{ID: 1, Title: "First"},
+		{ID: 2, Title: "Second"},
+		{ID: 3, Title: "Third"},
+	}
+	randomTracks := []library.TrackInfo{
+		{ID: 2, Title: "Second"},
+		{ID: 7, Title: "Excluded"},
+		{ID: 8, Title: "Random"},
+		{ID: 9, Title: "Seed"},
+	}
+
+	tests := []struct {
+		desc         string
+		url          string
+		expectedCode int
+		expectedArgs *similar.Args
+		expectedIDs  []int64
+	}{
+		{
+			desc:         "seeded by track",
+			url:          "/v1/artist-radio?track_id=9&count=3",
+			expectedCode: http.StatusOK,
+			expectedArgs: &similar.Args{
+				TrackIDs: []int64{9},
+				Count:    9,
+			},
+			expectedIDs: []int64{1, 2, 3},
+		},
+		{
+			desc:         "seeded by artist",
+			url:          "/v1/artist-radio?artist_id=4&count=3",
+			expectedCode: http.StatusOK,
+			expectedArgs: &similar.Args{
+				ArtistIDs: []int64{4},
+				Count:     9,
+			},
+			expectedIDs: []int64{1, 2, 3},
+		},
+		{
+			desc:         "filled with random tracks",
+			url:          "/v1/artist-radio?track_id=9&count=5&exclude=7,10",
+			expectedCode: http.StatusOK,
+			expectedArgs: &similar.Args{
+				TrackIDs:        []int64{9},
+				ExcludeTrackIDs: []int64{7, 10},
+				Count:           15,
+			},
+			expectedIDs: []int64{1, 2, 3, 8},
+		},
+		{
+			desc:         "limited count",
+			url:          "/v1/artist-radio?artist_id=4&count=1000",
+			expectedCode: http.StatusOK,
+			expectedArgs: &similar.Args{
+				ArtistIDs: []int64{4},
+				Count:     300,
+			},
+			expectedIDs: []int64{1, 2, 3, 7, 8, 9},
+		},
+		{
+			desc:         "without seed",
+			url:          "/v1/artist-radio",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "with two seeds",
+			url:          "/v1/artist-radio?track_id=1&artist_id=2",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed track ID",
+			url:          "/v1/artist-radio?track_id=baba",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed count",
+			url:          "/v1/artist-radio?track_id=1&count=-3",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed exclude",
+			url:          "/v1/artist-radio?track_id=1&exclude=1,baba",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			finder := &similarfakes.FakeFinder{
+				SimilarTracksStub: func(
+					_ context.Context,
+					_ similar.Args,
+				) ([]library.TrackInfo, error) {
+					return slices.Clone(similarTracks), nil
+				},
+			}
+			browser := &libraryfakes.FakeBrowser{
+				BrowseTracksStub: func(
+					_ library.BrowseArgs,
+				) ([]library.TrackInfo, int) {
+					return slices.Clone(randomTracks), len(randomTracks)
+				},
+			}
+
+			handler := webserver.NewArtistRadioHandler(
+				finder,
+				browser,
+				&libraryfakes.FakeLibrary{},
+				config.Auth{},
+			)
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			assert.Equal(t, test.expectedCode, resp.StatusCode, "HTTP status code")
+			if test.expectedArgs == nil {
+				assert.Equal(t, 0, finder.SimilarTracksCallCount(), "finder calls")
+				return
+			}
+
+			assert.Equal(t, 1, finder.SimilarTracksCallCount(), "finder calls")
+			_, args := finder.SimilarTracksArgsForCall(0)
+			if !reflect.DeepEqual(*test.expectedArgs, args) {
+				t.Errorf("expected similar args %+v but got %+v", *test.expectedArgs, args)
+			}
+
+			var radio struct {
+				Tracks []library.TrackInfo `json:"tracks"`
+			}
+			err := json.NewDecoder(resp.Body).Decode(&radio)
+			assert.NilErr(t, err, "decoding response")
+
+			var found []int64
+			for _, track := range radio.Tracks {
+				found = append(found, track.ID)
+			}
+			slices.Sort(found)
+			slices.Sort(test.expectedIDs)
+
+			if !slices.Equal(test.expectedIDs, found) {
+				t.Errorf("expected tracks %v but got %v", test.expectedIDs, found)
+			}
+		})
+	}
+}
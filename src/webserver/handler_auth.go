@@ -10,6 +10,8 @@ import (
 
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 const (
@@ -20,6 +22,7 @@ const (
 // to do the authentication and then pass the work to the Handler it wraps around.
 // Possible methods for authentication:
 //
+//  * User set by an authenticating reverse proxy in a trusted header
 //  * Basic Auth with the username and password
 //  * Authorization Bearer JWT token
 //  * JWT token in a session cookie
@@ -28,37 +31,44 @@ const (
 // Basic auth is preserved for backward compatibility. Needless to say, it so not
 // a preferred method for authentication.
 type AuthHandler struct {
-	wrapped    http.Handler // The actual handler that does the APP Logic job
-	username   string       // Username to be used for basic authenticate
-	password   string       // Password to be used for basic authenticate
-	templates  Templates    // Template finder
-	secret     string       // Secret used to craft and decode tokens
-	exceptions []string     // Paths which will be exempt from authentication
+	wrapped        http.Handler           // The actual handler that does the APP Logic job
+	auth           config.Auth            // Credentials and secret used for authentication
+	templates      Templates              // Template finder
+	exceptions     []string               // Paths which will be exempt from authentication
+	attempts       *loginattempts.Tracker // Failed basic authentication attempts
+	trustedProxies config.CIDRList        // Proxies trusted for the client IP address
 }
 
 // NewAuthHandler returns a new AuthHandler.
 func NewAuthHandler(
 	wrapped http.Handler,
-	username string,
-	password string,
+	auth config.Auth,
 	templatesResolver Templates,
-	secret string,
 	exceptions []string,
+	attempts *loginattempts.Tracker,
+	trustedProxies config.CIDRList,
 ) *AuthHandler {
 	return &AuthHandler{
-		wrapped:    wrapped,
-		username:   username,
-		password:   password,
-		templates:  templatesResolver,
-		secret:     secret,
-		exceptions: exceptions,
+		wrapped:        wrapped,
+		auth:           auth,
+		templates:      templatesResolver,
+		exceptions:     exceptions,
+		attempts:       attempts,
+		trustedProxies: trustedProxies,
 	}
 }
 
 // ServeHTTP implements the http.Handler interface and does the actual basic authenticate
 // check for every request
 func (hl *AuthHandler) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
-	if !hl.authenticated(req) {
+	authenticated, wait := hl.authenticated(req)
+	if wait > 0 {
+		webutils.SetRetryAfter(writer, wait)
+		http.Error(writer, tooManyAttemptsText, http.StatusTooManyRequests)
+		return
+	}
+
+	if !authenticated {
 		InternalErrorOnErrorHandler(writer, req, hl.challengeAuthentication)
 		return
 	}
@@ -115,59 +125,70 @@ func (hl *AuthHandler) redirectToLogin(w http.ResponseWriter, r *http.Request) e
 }
 
 // Compares the authentication header with the stored user and passwords
-// and returns true if they pass.
-func (hl *AuthHandler) authenticated(r *http.Request) bool {
+// and returns true if they pass. When the client is not allowed to try basic
+// authentication at the moment the returned duration is how long it has to wait.
+func (hl *AuthHandler) authenticated(r *http.Request) (bool, time.Duration) {
 	for _, path := range hl.exceptions {
 		if strings.HasPrefix(r.URL.Path, path) {
-			return true
+			return true, 0
 		}
 	}
 
+	if proxyUser, ok := webutils.ProxyAuthUser(r, hl.auth); ok {
+		return proxyUser == hl.auth.User, 0
+	}
+
 	authHeader := r.Header.Get("Authorization")
 
 	if strings.HasPrefix(authHeader, "Bearer ") {
-		return hl.withJWT(strings.TrimPrefix(authHeader, "Bearer "))
+		return hl.withJWT(strings.TrimPrefix(authHeader, "Bearer ")), 0
 	}
 
 	if strings.HasPrefix(authHeader, "Basic ") {
-		return hl.withBasicAuth(strings.TrimPrefix(authHeader, "Basic "))
+		return hl.withBasicAuth(r, strings.TrimPrefix(authHeader, "Basic "))
 	}
 
 	if cookie, err := r.Cookie(sessionCookieName); err == nil {
-		return hl.withJWT(cookie.Value)
+		return hl.withJWT(cookie.Value), 0
 	}
 
 	if queryToken := r.URL.Query().Get("token"); queryToken != "" {
-		return hl.withJWT(queryToken)
+		return hl.withJWT(queryToken), 0
 	}
 
-	return false
+	return false, 0
 }
 
-func (hl *AuthHandler) withBasicAuth(encoded string) bool {
+func (hl *AuthHandler) withBasicAuth(r *http.Request, encoded string) (bool, time.Duration) {
 	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
 	if err != nil {
-		return false
+		return false, 0
 	}
 
 	pair := strings.SplitN(string(b), ":", 2)
 
 	if len(pair) != 2 {
-		return false
+		return false, 0
+	}
+
+	clientIP := webutils.ClientIP(r, hl.trustedProxies)
+	if wait, ok := hl.attempts.Allowed(clientIP, pair[0]); !ok {
+		return false, wait
 	}
 
-	cfg := config.Auth{
-		User:     hl.username,
-		Password: hl.password,
+	if !checkLoginCreds(pair[0], pair[1], hl.auth) {
+		hl.attempts.Failed(clientIP, pair[0], "basic authentication")
+		return false, 0
 	}
 
-	return checkLoginCreds(pair[0], pair[1], cfg)
+	hl.attempts.Succeeded(clientIP, pair[0])
+	return true, 0
 }
 
 func (hl *AuthHandler) withJWT(token string) bool {
 	var jot jwt.Payload
 
-	alg := jwt.NewHS256([]byte(hl.secret))
+	alg := jwt.NewHS256([]byte(hl.auth.Secret))
 	exp := jwt.ExpirationTimeValidator(time.Now())
 	validatePayload := jwt.ValidatePayload(&jot, exp)
 
@@ -3,12 +3,14 @@ package webserver_test
 import (
 	"encoding/base64"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/webserver"
 )
 
@@ -214,6 +216,43 @@ func TestAuthHandlerDifferentAuthMethods(t *testing.T) {
 			},
 			expectedCode: http.StatusUnauthorized,
 		},
+		{
+			desc: "proxy header from trusted network",
+			newRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = "10.1.2.3:5678"
+				req.Header.Set("Remote-User", username)
+				return req
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc: "proxy header with wrong user",
+			newRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Accept", "application/json")
+				req.RemoteAddr = "10.1.2.3:5678"
+				req.Header.Set("Remote-User", "someone-else")
+				return req
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			desc: "proxy header from untrusted network",
+			newRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Accept", "application/json")
+				req.RemoteAddr = "198.51.100.1:5678"
+				req.Header.Set("Remote-User", username)
+				return req
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	_, proxyNetwork, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("parsing proxy network: %s", err)
 	}
 
 	for _, test := range tests {
@@ -225,11 +264,17 @@ func TestAuthHandlerDifferentAuthMethods(t *testing.T) {
 
 			auh := webserver.NewAuthHandler(
 				wrapped,
-				username,
-				password,
+				config.Auth{
+					User:          username,
+					Password:      password,
+					Secret:        secret,
+					ProxyHeader:   "Remote-User",
+					ProxyNetworks: config.CIDRList{proxyNetwork},
+				},
 				nil,
-				secret,
 				test.exceptions,
+				nil,
+				nil,
 			)
 
 			req := test.newRequest()
@@ -0,0 +1,147 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// bookmarkHandler will handle the REST methods for the bookmark of a single track.
+//
+// The bookmark operations are as follows:
+//
+// * Getting the bookmark (GET)
+// * Creating or replacing the bookmark (PUT)
+// * Removing the bookmark (DELETE)
+type bookmarkHandler struct {
+	bookmarks bookmarks.Bookmarker
+}
+
+// NewBookmarkHandler returns an HTTP handler for interacting with the bookmark
+// of a single track identified by its ID.
+func NewBookmarkHandler(bookmarker bookmarks.Bookmarker) http.Handler {
+	return &bookmarkHandler{
+		bookmarks: bookmarker,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *bookmarkHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	vars := mux.Vars(req)
+	trackID, err := strconv.ParseInt(vars["trackID"], 10, 64)
+	if err != nil {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Method == http.MethodPut {
+		h.setBookmark(w, req, trackID)
+		return
+	} else if req.Method == http.MethodDelete {
+		h.deleteBookmark(w, req, trackID)
+		return
+	}
+
+	h.getBookmark(w, req, trackID)
+}
+
+func (h *bookmarkHandler) setBookmark(
+	w http.ResponseWriter,
+	req *http.Request,
+	trackID int64,
+) {
+	var params bookmarkRequest
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&params); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("cannot parse request body: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	if params.Position == nil || *params.Position < 0 {
+		webutils.JSONError(
+			w,
+			"position must be a non-negative number of milliseconds",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	err := h.bookmarks.Set(req.Context(), trackID, bookmarks.SetArgs{
+		Position: *params.Position,
+		Comment:  params.Comment,
+	})
+	if errors.Is(err, bookmarks.ErrTrackNotFound) {
+		webutils.JSONError(w, "track not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error setting the bookmark: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *bookmarkHandler) deleteBookmark(
+	w http.ResponseWriter,
+	req *http.Request,
+	trackID int64,
+) {
+	err := h.bookmarks.Delete(req.Context(), trackID)
+	if errors.Is(err, bookmarks.ErrNotFound) {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error deleting a bookmark: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *bookmarkHandler) getBookmark(
+	w http.ResponseWriter,
+	req *http.Request,
+	trackID int64,
+) {
+	bm, err := h.bookmarks.Get(req.Context(), trackID)
+	if errors.Is(err, bookmarks.ErrNotFound) {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error getting a bookmark: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(toAPIbookmark(bm)); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding bookmark response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
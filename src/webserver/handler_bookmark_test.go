@@ -0,0 +1,178 @@
+package webserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/bookmarks/bookmarksfakes"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestBookmarkHandlers checks that the bookmark handlers pass their arguments to
+// the bookmarks manager and convert its errors to the correct HTTP status codes.
+func TestBookmarkHandlers(t *testing.T) {
+	now := time.Now()
+	stored := bookmarks.Bookmark{
+		Track:     library.TrackInfo{ID: 42, Title: "Long Mix"},
+		Position:  360000,
+		Comment:   "the good part",
+		CreatedAt: now.Add(-time.Hour),
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		desc         string
+		method       string
+		url          string
+		body         string
+		bookmarksErr error
+
+		expectedCode int
+		expectedBody string
+		expectedSet  *bookmarks.SetArgs
+	}{
+		{
+			desc:         "list bookmarks",
+			method:       http.MethodGet,
+			url:          "/v1/bookmarks",
+			expectedCode: http.StatusOK,
+			expectedBody: `"position":360000`,
+		},
+		{
+			desc:         "get bookmark",
+			method:       http.MethodGet,
+			url:          "/v1/bookmark/42",
+			expectedCode: http.StatusOK,
+			expectedBody: `"comment":"the good part"`,
+		},
+		{
+			desc:         "get missing bookmark",
+			method:       http.MethodGet,
+			url:          "/v1/bookmark/43",
+			bookmarksErr: bookmarks.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "set bookmark",
+			method:       http.MethodPut,
+			url:          "/v1/bookmark/42",
+			body:         `{"position": 5000, "comment": "chapter 3"}`,
+			expectedCode: http.StatusNoContent,
+			expectedSet: &bookmarks.SetArgs{
+				Position: 5000,
+				Comment:  "chapter 3",
+			},
+		},
+		{
+			desc:         "set bookmark for missing track",
+			method:       http.MethodPut,
+			url:          "/v1/bookmark/43",
+			body:         `{"position": 5000}`,
+			bookmarksErr: bookmarks.ErrTrackNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "set bookmark without position",
+			method:       http.MethodPut,
+			url:          "/v1/bookmark/42",
+			body:         `{"comment": "chapter 3"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "set bookmark with malformed body",
+			method:       http.MethodPut,
+			url:          "/v1/bookmark/42",
+			body:         `{"position"`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "delete bookmark",
+			method:       http.MethodDelete,
+			url:          "/v1/bookmark/42",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			desc:         "delete missing bookmark",
+			method:       http.MethodDelete,
+			url:          "/v1/bookmark/43",
+			bookmarksErr: bookmarks.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "malformed track ID",
+			method:       http.MethodGet,
+			url:          "/v1/bookmark/baba",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "list error",
+			method:       http.MethodGet,
+			url:          "/v1/bookmarks",
+			bookmarksErr: fmt.Errorf("some error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			bookmarker := &bookmarksfakes.FakeBookmarker{
+				GetStub: func(_ context.Context, _ int64) (bookmarks.Bookmark, error) {
+					return stored, test.bookmarksErr
+				},
+				ListStub: func(_ context.Context) ([]bookmarks.Bookmark, error) {
+					return []bookmarks.Bookmark{stored}, test.bookmarksErr
+				},
+				SetStub: func(_ context.Context, _ int64, _ bookmarks.SetArgs) error {
+					return test.bookmarksErr
+				},
+				DeleteStub: func(_ context.Context, _ int64) error {
+					return test.bookmarksErr
+				},
+			}
+
+			router := mux.NewRouter()
+			router.Handle(
+				webserver.APIv1EndpointBookmarks,
+				webserver.NewBookmarksHandler(bookmarker),
+			).Methods(webserver.APIv1Methods[webserver.APIv1EndpointBookmarks]...)
+			router.Handle(
+				webserver.APIv1EndpointBookmark,
+				webserver.NewBookmarkHandler(bookmarker),
+			).Methods(webserver.APIv1Methods[webserver.APIv1EndpointBookmark]...)
+
+			req := httptest.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+			if test.expectedBody != "" && !strings.Contains(rec.Body.String(), test.expectedBody) {
+				t.Errorf("expected `%s` in body `%s`", test.expectedBody, rec.Body.String())
+			}
+
+			if rec.Code == http.StatusOK {
+				var decoded any
+				err := json.Unmarshal(rec.Body.Bytes(), &decoded)
+				assert.NilErr(t, err, "decoding response JSON")
+			}
+
+			if test.expectedSet == nil {
+				return
+			}
+
+			assert.Equal(t, 1, bookmarker.SetCallCount(), "set calls")
+			_, trackID, args := bookmarker.SetArgsForCall(0)
+			assert.Equal(t, 42, trackID, "bookmarked track ID")
+			assert.Equal(t, *test.expectedSet, args, "set arguments")
+		})
+	}
+}
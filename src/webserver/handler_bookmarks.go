@@ -0,0 +1,85 @@
+package webserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// bookmarksHandler will list all bookmarks (GET).
+type bookmarksHandler struct {
+	bookmarks bookmarks.Bookmarker
+}
+
+// NewBookmarksHandler returns an http.Handler which lists all bookmarks.
+func NewBookmarksHandler(bookmarker bookmarks.Bookmarker) http.Handler {
+	return &bookmarksHandler{
+		bookmarks: bookmarker,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *bookmarksHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	list, err := h.bookmarks.List(req.Context())
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error listing bookmarks: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	resp := bookmarksResponse{
+		Bookmarks: make([]bookmark, 0, len(list)),
+	}
+	for _, bm := range list {
+		resp.Bookmarks = append(resp.Bookmarks, toAPIbookmark(bm))
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding bookmarks response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+type bookmarksResponse struct {
+	Bookmarks []bookmark `json:"bookmarks"`
+}
+
+type bookmark struct {
+	TrackID   int64             `json:"track_id"`
+	Position  int64             `json:"position"` // Position in millisecs.
+	Comment   string            `json:"comment,omitempty"`
+	CreatedAt int64             `json:"created_at"` // Unix timestamp in seconds.
+	UpdatedAt int64             `json:"updated_at"` // Unix timestamp in seconds.
+	Track     library.TrackInfo `json:"track"`
+}
+
+// toAPIbookmark converts a bookmarks.Bookmark to a bookmark object suitable for
+// JSON encoding as an API response from the Euterpe APIs.
+func toAPIbookmark(bm bookmarks.Bookmark) bookmark {
+	return bookmark{
+		TrackID:   bm.Track.ID,
+		Position:  bm.Position,
+		Comment:   bm.Comment,
+		CreatedAt: bm.CreatedAt.Unix(),
+		UpdatedAt: bm.UpdatedAt.Unix(),
+		Track:     bm.Track,
+	}
+}
+
+type bookmarkRequest struct {
+	Position *int64 `json:"position"`
+	Comment  string `json:"comment"`
+}
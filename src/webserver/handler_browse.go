@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
@@ -17,6 +18,8 @@ import (
 // albums with the help of pagination.
 type BrowseHandler struct {
 	browser library.Browser
+	library library.Library
+	auth    config.Auth
 }
 
 // ServeHTTP is required by the http.Handler's interface
@@ -28,6 +31,14 @@ func (bh BrowseHandler) ServeHTTP(writer http.ResponseWriter, req *http.Request)
 func (bh BrowseHandler) browse(writer http.ResponseWriter, req *http.Request) error {
 	writer.Header().Add("Content-Type", "application/json; charset=utf-8")
 
+	// Browse results include play counts and random orders which change without
+	// the library being modified. So clients must not cache them heuristically
+	// based on the Last-Modified header.
+	if lastModified := bh.browser.LastModified(); !lastModified.IsZero() {
+		writer.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		writer.Header().Set("Cache-Control", "no-cache")
+	}
+
 	if err := req.ParseForm(); err != nil {
 		bh.badRequest(writer, err.Error())
 		return nil
@@ -89,21 +100,32 @@ func (bh BrowseHandler) browse(writer http.ResponseWriter, req *http.Request) er
 		return nil
 	}
 
+	if browseBy == "song" && orderBy == "" {
+		orderBy = "id"
+	}
+
+	browseArgs := getBrowseArgs(page, perPage, orderBy, order)
+	musicFolderIDs, err := allowedMusicFolderIDs(req.Context(), bh.library, bh.auth)
+	if err != nil {
+		return err
+	}
+	browseArgs.MusicFolderIDs = musicFolderIDs
+
 	if browseBy == "artist" {
-		return bh.browseArtists(writer, page, perPage, orderBy, order)
+		return bh.browseArtists(writer, browseArgs, page, perPage, orderBy, order)
 	} else if browseBy == "song" {
-		return bh.browseSongs(writer, page, perPage, orderBy, order)
+		return bh.browseSongs(writer, browseArgs, page, perPage, orderBy, order)
 	}
 
-	return bh.browseAlbums(writer, page, perPage, orderBy, order)
+	return bh.browseAlbums(writer, browseArgs, page, perPage, orderBy, order)
 }
 
 func (bh BrowseHandler) browseAlbums(
 	writer http.ResponseWriter,
+	browseArgs library.BrowseArgs,
 	page, perPage int,
 	orderBy, order string,
 ) error {
-	browseArgs := getBrowseArgs(page, perPage, orderBy, order)
 	albums, count := bh.browser.BrowseAlbums(browseArgs)
 	prevPage, nextPage := getBrowsePrevNextPageURI(
 		"album",
@@ -132,10 +154,10 @@ func (bh BrowseHandler) browseAlbums(
 
 func (bh BrowseHandler) browseArtists(
 	writer http.ResponseWriter,
+	browseArgs library.BrowseArgs,
 	page, perPage int,
 	orderBy, order string,
 ) error {
-	browseArgs := getBrowseArgs(page, perPage, orderBy, order)
 	unsupportedBrowseBy := []library.BrowseOrderBy{
 		library.OrderByRecentlyPlayed,
 		library.OrderByFrequentlyPlayed,
@@ -176,14 +198,10 @@ func (bh BrowseHandler) browseArtists(
 
 func (bh BrowseHandler) browseSongs(
 	writer http.ResponseWriter,
+	browseArgs library.BrowseArgs,
 	page, perPage int,
 	orderBy, order string,
 ) error {
-	if orderBy == "" {
-		orderBy = "id"
-	}
-
-	browseArgs := getBrowseArgs(page, perPage, orderBy, order)
 	tracks, count := bh.browser.BrowseTracks(browseArgs)
 	prevPage, nextPage := getBrowsePrevNextPageURI(
 		"song",
@@ -296,9 +314,16 @@ func getBrowsePrevNextPageURI(
 }
 
 // NewBrowseHandler returns a new Browse handler. It needs a library.Browser to browse
-// through.
-func NewBrowseHandler(browser library.Browser) *BrowseHandler {
+// through. Only items from the music folders of `lib` which the user from `auth`
+// could access are browsed.
+func NewBrowseHandler(
+	browser library.Browser,
+	lib library.Library,
+	auth config.Auth,
+) *BrowseHandler {
 	return &BrowseHandler{
 		browser: browser,
+		library: lib,
+		auth:    auth,
 	}
 }
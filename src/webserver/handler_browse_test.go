@@ -6,9 +6,14 @@ import (
 	"mime"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/webserver"
@@ -199,7 +204,7 @@ func TestBrowseHandlerArgumentParsing(t *testing.T) {
 				},
 			}
 
-			handler := routeBrowseHandler(webserver.NewBrowseHandler(&fakeBrowser))
+			handler := routeBrowseHandler(webserver.NewBrowseHandler(&fakeBrowser, &libraryfakes.FakeLibrary{}, config.Auth{}))
 			req := httptest.NewRequest(http.MethodGet, test.url, nil)
 			resp := httptest.NewRecorder()
 			handler.ServeHTTP(resp, req)
@@ -219,7 +224,7 @@ func TestBrowseHandlerArgumentParsing(t *testing.T) {
 
 				expected := *test.expectedAlbumArgs
 				foundArgs := fakeBrowser.BrowseAlbumsArgsForCall(0)
-				if foundArgs != expected {
+				if !reflect.DeepEqual(foundArgs, expected) {
 					t.Errorf("expected album args %+v but got %+v", expected, foundArgs)
 				}
 			}
@@ -234,7 +239,7 @@ func TestBrowseHandlerArgumentParsing(t *testing.T) {
 
 				expected := *test.expectedArtistArgs
 				foundArgs := fakeBrowser.BrowseArtistsArgsForCall(0)
-				if foundArgs != expected {
+				if !reflect.DeepEqual(foundArgs, expected) {
 					t.Errorf("expected artist args %+v but got %+v", expected, foundArgs)
 				}
 			}
@@ -249,7 +254,7 @@ func TestBrowseHandlerArgumentParsing(t *testing.T) {
 
 				expected := *test.expectedSongsArgs
 				foundArgs := fakeBrowser.BrowseTracksArgsForCall(0)
-				if foundArgs != expected {
+				if !reflect.DeepEqual(foundArgs, expected) {
 					t.Errorf("expected track args %+v but got %+v", expected, foundArgs)
 				}
 			}
@@ -340,9 +345,13 @@ func TestBrowseHandlerResponseEncoding(t *testing.T) {
 		) ([]library.TrackInfo, int) {
 			return songsResponse, 4
 		},
+
+		LastModifiedStub: func() time.Time {
+			return time.Date(2024, 5, 13, 18, 32, 22, 0, time.UTC)
+		},
 	}
 
-	handler := webserver.NewBrowseHandler(&fakeBrowser)
+	handler := webserver.NewBrowseHandler(&fakeBrowser, &libraryfakes.FakeLibrary{}, config.Auth{})
 
 	// Try album response.
 	req := httptest.NewRequest(
@@ -354,6 +363,11 @@ func TestBrowseHandlerResponseEncoding(t *testing.T) {
 	handler.ServeHTTP(resp, req)
 
 	assertContentTypeJSON(t, resp.Header().Get("Content-Type"))
+	const expectedLastModified = "Mon, 13 May 2024 18:32:22 GMT"
+	if lm := resp.Header().Get("Last-Modified"); lm != expectedLastModified {
+		t.Errorf("expected Last-Modified `%s` but got `%s`", expectedLastModified, lm)
+	}
+
 	var decAlbums struct {
 		PageCount uint32               `json:"pages_count"`
 		Next      string               `json:"next"`
@@ -538,7 +552,7 @@ func TestBrowseHandlerResponseEncoding(t *testing.T) {
 
 	for i, song := range expectedSongs {
 		respSong := decSongs.Songs[i]
-		if respSong != song {
+		if !reflect.DeepEqual(respSong, song) {
 			t.Errorf(
 				"expected song %d to be `%+v` but it was `%+v`",
 				i, song, respSong,
@@ -597,6 +611,48 @@ func assertContentTypeJSON(t *testing.T, contentType string) {
 	}
 }
 
+// TestBrowseHandlerMusicFolders makes sure that browsing is limited to the music
+// folders which the user is allowed to access.
+func TestBrowseHandlerMusicFolders(t *testing.T) {
+	fakeLib := &libraryfakes.FakeLibrary{}
+	fakeLib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "allowed", Path: "/music/allowed"},
+		{ID: 2, Name: "private", Path: "/music/private"},
+	}, nil)
+	auth := config.Auth{User: "user", MusicFolders: []string{"allowed"}}
+
+	fakeBrowser := libraryfakes.FakeBrowser{}
+	handler := routeBrowseHandler(
+		webserver.NewBrowseHandler(&fakeBrowser, fakeLib, auth),
+	)
+
+	for _, by := range []string{"album", "artist", "song"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/browse?by="+by, nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "HTTP status code for %s", by)
+	}
+
+	assert.Equal(t, 1, fakeBrowser.BrowseAlbumsCallCount(), "browse albums calls")
+	assert.Equal(t, 1, fakeBrowser.BrowseArtistsCallCount(), "browse artists calls")
+	assert.Equal(t, 1, fakeBrowser.BrowseTracksCallCount(), "browse tracks calls")
+
+	for kind, args := range map[string]library.BrowseArgs{
+		"albums":  fakeBrowser.BrowseAlbumsArgsForCall(0),
+		"artists": fakeBrowser.BrowseArtistsArgsForCall(0),
+		"tracks":  fakeBrowser.BrowseTracksArgsForCall(0),
+	} {
+		if !slices.Contains(args.MusicFolderIDs, 1) ||
+			slices.Contains(args.MusicFolderIDs, 2) {
+			t.Errorf(
+				"expected only the allowed music folder when browsing %s but got %v",
+				kind,
+				args.MusicFolderIDs,
+			)
+		}
+	}
+}
+
 func assertResponseIsValidJSON(t *testing.T, r io.Reader) {
 	var decoded map[string]interface{}
 	dec := json.NewDecoder(r)
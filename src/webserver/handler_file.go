@@ -10,12 +10,18 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 // FileHandler will find and serve a media file by its ID
 type FileHandler struct {
-	library library.Library
+	library        library.Library
+	nowPlaying     *nowplaying.Registry
+	auth           config.Auth
+	trustedProxies config.CIDRList
 }
 
 // ServeHTTP is required by the http.Handler's interface
@@ -39,6 +45,13 @@ func (fh FileHandler) find(writer http.ResponseWriter, req *http.Request) error
 		return fmt.Errorf("Library for FileHandler is nil")
 	}
 
+	if !canAccessMusicFolders(
+		req.Context(), fh.library, fh.auth, int64(id), fh.library.TrackInMusicFolders,
+	) {
+		http.NotFoundHandler().ServeHTTP(writer, req)
+		return nil
+	}
+
 	filePath := fh.library.GetFilePath(req.Context(), int64(id))
 	fileReader, err := os.Open(filePath)
 	if err != nil {
@@ -57,6 +70,7 @@ func (fh FileHandler) find(writer http.ResponseWriter, req *http.Request) error
 	if err != nil {
 		log.Printf("failed to update track %d stats: %s", id, err)
 	}
+	fh.recordNowPlaying(req, int64(id))
 
 	baseName := filepath.Base(filePath)
 	writer.Header().Add("Content-Disposition",
@@ -65,10 +79,41 @@ func (fh FileHandler) find(writer http.ResponseWriter, req *http.Request) error
 	return nil
 }
 
+// recordNowPlaying marks the track as playing on the client which requested it.
+// Clients are identified by their user agent and IP address.
+func (fh FileHandler) recordNowPlaying(req *http.Request, trackID int64) {
+	if fh.nowPlaying == nil {
+		return
+	}
+
+	track, err := fh.library.GetTrack(req.Context(), trackID)
+	if err != nil {
+		log.Printf("failed to get track %d for now playing: %s", trackID, err)
+		return
+	}
+
+	fh.nowPlaying.Playing(
+		fh.auth.User,
+		req.UserAgent(),
+		webutils.ClientIP(req, fh.trustedProxies),
+		track,
+	)
+}
+
 // NewFileHandler returns a new File handler will will be resposible for serving a file
-// from the library identified from its ID.
-func NewFileHandler(lib library.Library) *FileHandler {
+// from the library identified from its ID. Served tracks are recorded as playing
+// by the `auth` user in the nowPlaying registry. It could be nil. Tracks outside
+// of the music folders which the user is allowed to access are not found.
+func NewFileHandler(
+	lib library.Library,
+	nowPlaying *nowplaying.Registry,
+	auth config.Auth,
+	trustedProxies config.CIDRList,
+) *FileHandler {
 	fh := new(FileHandler)
 	fh.library = lib
+	fh.nowPlaying = nowPlaying
+	fh.auth = auth
+	fh.trustedProxies = trustedProxies
 	return fh
 }
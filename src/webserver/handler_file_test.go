@@ -1,18 +1,26 @@
 package webserver_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/webserver"
 )
 
 // TestFileHandlerWithNoLibrary makes sure that the handler works even without a
 // library and that it returns "internal server error" in this case.
 func TestFileHandlerWithNoLibrary(t *testing.T) {
-	h := routeFileHandler(webserver.NewFileHandler(nil))
+	h := routeFileHandler(webserver.NewFileHandler(nil, nil, config.Auth{}, nil))
 
 	req := httptest.NewRequest(http.MethodGet, "/v1/file/23", nil)
 	resp := httptest.NewRecorder()
@@ -33,7 +41,7 @@ func TestFileHandlerWithNoLibrary(t *testing.T) {
 // when there is no ID in its gorilla mux.
 func TestFileHandlerWithWrongPathVars(t *testing.T) {
 	// Simulate no gorilla mux by not having one! :D
-	h := webserver.NewFileHandler(nil)
+	h := webserver.NewFileHandler(nil, nil, config.Auth{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	resp := httptest.NewRecorder()
@@ -50,6 +58,70 @@ func TestFileHandlerWithWrongPathVars(t *testing.T) {
 	}
 }
 
+// TestFileAndAlbumHandlersMusicFolders makes sure that tracks and albums outside
+// of the music folders which the user is allowed to access are not found.
+func TestFileAndAlbumHandlersMusicFolders(t *testing.T) {
+	trackFile := filepath.Join(t.TempDir(), "track.mp3")
+	err := os.WriteFile(trackFile, []byte("some track contents"), 0600)
+	assert.NilErr(t, err, "writing track file")
+
+	lib := &libraryfakes.FakeLibrary{}
+	lib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "allowed", Path: "/music/allowed"},
+		{ID: 2, Name: "private", Path: "/music/private"},
+	}, nil)
+	lib.GetFilePathReturns(trackFile)
+	lib.GetAlbumFilesReturns([]library.TrackInfo{{ID: 5, Album: "Album"}})
+	lib.TrackInMusicFoldersStub = func(
+		_ context.Context,
+		trackID int64,
+		folderIDs []int64,
+	) (bool, error) {
+		if !slices.Contains(folderIDs, 1) || slices.Contains(folderIDs, 2) {
+			t.Errorf("expected only the allowed music folder but got %v", folderIDs)
+		}
+		return trackID == 5, nil
+	}
+	lib.AlbumInMusicFoldersStub = func(
+		_ context.Context,
+		albumID int64,
+		_ []int64,
+	) (bool, error) {
+		return albumID == 7, nil
+	}
+
+	auth := config.Auth{User: "user", MusicFolders: []string{"allowed"}}
+	router := mux.NewRouter()
+	router.Handle(
+		webserver.APIv1EndpointFile,
+		webserver.NewFileHandler(lib, nil, auth, nil),
+	)
+	router.Handle(
+		webserver.APIv1EndpointDownloadAlbum,
+		webserver.NewAlbumHandler(lib, auth),
+	)
+
+	tests := []struct {
+		url          string
+		expectedCode int
+	}{
+		{url: "/v1/file/5", expectedCode: http.StatusOK},
+		{url: "/v1/file/6", expectedCode: http.StatusNotFound},
+		{url: "/v1/album/7", expectedCode: http.StatusOK},
+		{url: "/v1/album/8", expectedCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedCode, resp.Code, "HTTP status code")
+		})
+	}
+}
+
 // routeFileHandler wraps a handler the same way the web server will do when
 // constructing the main application router. This is needed for tests so that the
 // Gorilla mux variables will be parsed.
@@ -0,0 +1,146 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/duplicates"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// libraryDuplicatesHandler lists the groups of duplicate tracks in the library
+// (GET) and merges duplicates into a single track which is to be kept (POST).
+type libraryDuplicatesHandler struct {
+	finder duplicates.Finder
+}
+
+// NewLibraryDuplicatesHandler returns an HTTP handler for finding and merging
+// duplicate tracks in the library.
+func NewLibraryDuplicatesHandler(finder duplicates.Finder) http.Handler {
+	return &libraryDuplicatesHandler{
+		finder: finder,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *libraryDuplicatesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	if req.Method == http.MethodPost {
+		h.merge(w, req)
+		return
+	}
+
+	h.listDuplicates(w, req)
+}
+
+func (h *libraryDuplicatesHandler) listDuplicates(
+	w http.ResponseWriter,
+	req *http.Request,
+) {
+	var args duplicates.FindArgs
+	if tolerance := req.URL.Query().Get("tolerance"); tolerance != "" {
+		ms, err := strconv.ParseInt(tolerance, 10, 64)
+		if err != nil || ms < 0 {
+			webutils.JSONError(
+				w,
+				"tolerance must be a non-negative number of milliseconds",
+				http.StatusBadRequest,
+			)
+			return
+		}
+		args.Tolerance = time.Duration(ms) * time.Millisecond
+	}
+
+	groups, err := h.finder.Find(req.Context(), args)
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error finding duplicates: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	resp := libraryDuplicatesResponse{
+		Groups: groups,
+	}
+	if resp.Groups == nil {
+		resp.Groups = []duplicates.Group{}
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding duplicates response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+func (h *libraryDuplicatesHandler) merge(w http.ResponseWriter, req *http.Request) {
+	var params libraryDuplicatesMergeRequest
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&params); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("cannot parse request body: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	if params.KeeperID <= 0 {
+		webutils.JSONError(w, "keeper_id is required", http.StatusBadRequest)
+		return
+	}
+
+	duplicateIDs := params.DuplicateIDs
+	if duplicateIDs == nil {
+		groups, err := h.finder.Find(req.Context(), duplicates.FindArgs{})
+		if err != nil {
+			webutils.JSONError(
+				w,
+				fmt.Sprintf("error finding duplicates: %s", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+		duplicateIDs = duplicates.GroupedWith(groups, params.KeeperID)
+	}
+
+	err := h.finder.Merge(req.Context(), params.KeeperID, duplicateIDs)
+	if errors.Is(err, duplicates.ErrTrackNotFound) {
+		webutils.JSONError(w, "track not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, duplicates.ErrNoDuplicates) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error merging duplicates: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type libraryDuplicatesResponse struct {
+	Groups []duplicates.Group `json:"groups"`
+}
+
+type libraryDuplicatesMergeRequest struct {
+	KeeperID int64 `json:"keeper_id"`
+
+	// DuplicateIDs are the tracks which will be merged into the keeper. When
+	// missing all tracks grouped together with the keeper are merged.
+	DuplicateIDs []int64 `json:"duplicate_ids"`
+}
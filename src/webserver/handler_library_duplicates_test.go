@@ -0,0 +1,172 @@
+package webserver_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/duplicates"
+	"github.com/ironsmile/euterpe/src/duplicates/duplicatesfakes"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestLibraryDuplicatesHandler checks that the duplicates handler lists the
+// groups of duplicate tracks and merges them into the requested track.
+func TestLibraryDuplicatesHandler(t *testing.T) {
+	groups := []duplicates.Group{
+		{
+			Match: duplicates.MatchMetadata,
+			Tracks: []duplicates.Track{
+				{
+					TrackInfo: library.TrackInfo{ID: 3, Title: "Song", Format: "mp3"},
+					Path:      "/music/song.mp3",
+				},
+				{
+					TrackInfo: library.TrackInfo{ID: 7, Title: "Song", Format: "flac"},
+					Path:      "/music/flac/song.flac",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		method   string
+		query    string
+		body     string
+		findErr  error
+		mergeErr error
+
+		expectedCode       int
+		expectedBody       string
+		expectedTolerance  time.Duration
+		expectedKeeper     int64
+		expectedDuplicates []int64
+	}{
+		{
+			desc:         "list duplicates",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expectedBody: `"path":"/music/flac/song.flac"`,
+		},
+		{
+			desc:              "list duplicates with tolerance",
+			method:            http.MethodGet,
+			query:             "?tolerance=500",
+			expectedCode:      http.StatusOK,
+			expectedBody:      `"match":"metadata"`,
+			expectedTolerance: 500 * time.Millisecond,
+		},
+		{
+			desc:         "malformed tolerance",
+			method:       http.MethodGet,
+			query:        "?tolerance=long",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "finding error",
+			method:       http.MethodGet,
+			findErr:      fmt.Errorf("some error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			desc:               "merge listed duplicates",
+			method:             http.MethodPost,
+			body:               `{"keeper_id": 7, "duplicate_ids": [3, 9]}`,
+			expectedCode:       http.StatusNoContent,
+			expectedKeeper:     7,
+			expectedDuplicates: []int64{3, 9},
+		},
+		{
+			desc:               "merge the whole group",
+			method:             http.MethodPost,
+			body:               `{"keeper_id": 7}`,
+			expectedCode:       http.StatusNoContent,
+			expectedKeeper:     7,
+			expectedDuplicates: []int64{3},
+		},
+		{
+			desc:               "merge missing track",
+			method:             http.MethodPost,
+			body:               `{"keeper_id": 7, "duplicate_ids": [3]}`,
+			mergeErr:           duplicates.ErrTrackNotFound,
+			expectedCode:       http.StatusNotFound,
+			expectedKeeper:     7,
+			expectedDuplicates: []int64{3},
+		},
+		{
+			desc:               "merge without duplicates",
+			method:             http.MethodPost,
+			body:               `{"keeper_id": 5}`,
+			mergeErr:           duplicates.ErrNoDuplicates,
+			expectedCode:       http.StatusBadRequest,
+			expectedKeeper:     5,
+			expectedDuplicates: nil,
+		},
+		{
+			desc:         "merge without keeper",
+			method:       http.MethodPost,
+			body:         `{"duplicate_ids": [3]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed body",
+			method:       http.MethodPost,
+			body:         `{"keeper_id"`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			finder := &duplicatesfakes.FakeFinder{}
+			finder.FindReturns(groups, test.findErr)
+			finder.MergeReturns(test.mergeErr)
+
+			handler := webserver.NewLibraryDuplicatesHandler(finder)
+
+			req := httptest.NewRequest(
+				test.method,
+				webserver.APIv1EndpointLibraryDuplicates+test.query,
+				strings.NewReader(test.body),
+			)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+			if test.expectedBody != "" && !strings.Contains(rec.Body.String(), test.expectedBody) {
+				t.Errorf("expected `%s` in body `%s`", test.expectedBody, rec.Body.String())
+			}
+
+			if test.method == http.MethodGet && rec.Code == http.StatusOK {
+				var decoded any
+				err := json.Unmarshal(rec.Body.Bytes(), &decoded)
+				assert.NilErr(t, err, "decoding response JSON")
+
+				_, args := finder.FindArgsForCall(0)
+				assert.Equal(t, test.expectedTolerance, args.Tolerance, "tolerance")
+			}
+
+			if test.expectedKeeper == 0 {
+				assert.Equal(t, 0, finder.MergeCallCount(), "merge calls")
+				return
+			}
+
+			assert.Equal(t, 1, finder.MergeCallCount(), "merge calls")
+			_, keeperID, duplicateIDs := finder.MergeArgsForCall(0)
+			assert.Equal(t, test.expectedKeeper, keeperID, "kept track")
+			assert.Equal(
+				t,
+				fmt.Sprint(test.expectedDuplicates),
+				fmt.Sprint(duplicateIDs),
+				"merged duplicates",
+			)
+		})
+	}
+}
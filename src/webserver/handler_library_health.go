@@ -0,0 +1,98 @@
+package webserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// libraryHealthHandler returns the library totals and the problems found in it
+// while scanning and resolving album artwork.
+type libraryHealthHandler struct {
+	reporter library.HealthReporter
+}
+
+// NewLibraryHealthHandler returns an HTTP handler for the library health report.
+func NewLibraryHealthHandler(reporter library.HealthReporter) http.Handler {
+	return &libraryHealthHandler{
+		reporter: reporter,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *libraryHealthHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	report, err := h.reporter.LibraryHealth(req.Context())
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error getting library health: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	resp := libraryHealth{
+		Tracks:   report.Tracks,
+		Size:     report.Size,
+		Duration: report.Duration.Milliseconds(),
+		Formats:  make([]libraryFormatStats, 0, len(report.Formats)),
+		Issues:   make(map[library.HealthIssueKind][]libraryIssue),
+	}
+	for _, format := range report.Formats {
+		resp.Formats = append(resp.Formats, libraryFormatStats{
+			Format:   format.Format,
+			Tracks:   format.Tracks,
+			Size:     format.Size,
+			Duration: format.Duration.Milliseconds(),
+		})
+	}
+	for _, kind := range library.HealthIssueKinds {
+		resp.Issues[kind] = []libraryIssue{}
+	}
+	for _, issue := range report.Issues {
+		resp.Issues[issue.Kind] = append(resp.Issues[issue.Kind], libraryIssue{
+			Path:    issue.Path,
+			TrackID: issue.TrackID,
+			AlbumID: issue.AlbumID,
+			Message: issue.Message,
+			FoundAt: issue.FoundAt.Unix(),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding library health response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+type libraryHealth struct {
+	Tracks   int64                                      `json:"tracks"`
+	Size     int64                                      `json:"size"`     // In bytes.
+	Duration int64                                      `json:"duration"` // In milliseconds.
+	Formats  []libraryFormatStats                       `json:"formats"`
+	Issues   map[library.HealthIssueKind][]libraryIssue `json:"issues"`
+}
+
+type libraryFormatStats struct {
+	Format   string `json:"format"`
+	Tracks   int64  `json:"tracks"`
+	Size     int64  `json:"size"`     // In bytes.
+	Duration int64  `json:"duration"` // In milliseconds.
+}
+
+type libraryIssue struct {
+	Path    string `json:"path"`
+	TrackID int64  `json:"track_id,omitempty"`
+	AlbumID int64  `json:"album_id,omitempty"`
+	Message string `json:"message,omitempty"`
+	FoundAt int64  `json:"found_at"` // Unix timestamp in seconds.
+}
@@ -0,0 +1,105 @@
+package webserver_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestLibraryHealthHandler checks that the library health handler returns the
+// library totals and its issues grouped by kind.
+func TestLibraryHealthHandler(t *testing.T) {
+	foundAt := time.Unix(1728838923, 0)
+
+	reporter := &libraryfakes.FakeHealthReporter{}
+	reporter.LibraryHealthReturns(library.HealthReport{
+		Tracks:   3,
+		Size:     6000,
+		Duration: 5 * time.Minute,
+		Formats: []library.FormatStats{
+			{Format: "mp3", Tracks: 2, Size: 3000, Duration: 2 * time.Minute},
+			{Format: "flac", Tracks: 1, Size: 3000, Duration: 3 * time.Minute},
+		},
+		Issues: []library.HealthIssue{
+			{
+				Kind:    library.IssueBadTags,
+				Path:    "/music/broken.mp3",
+				Message: "failed to parse file",
+				FoundAt: foundAt,
+			},
+			{
+				Kind:    library.IssueUnknownArtist,
+				Path:    "/music/no-artist.mp3",
+				TrackID: 42,
+				FoundAt: foundAt,
+			},
+			{
+				Kind:    library.IssueUnknownArtist,
+				Path:    "/music/no-artist-either.mp3",
+				TrackID: 43,
+				FoundAt: foundAt,
+			},
+		},
+	}, nil)
+
+	handler := webserver.NewLibraryHealthHandler(reporter)
+
+	req := httptest.NewRequest(http.MethodGet, webserver.APIv1EndpointLibraryHealth, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "HTTP status code")
+
+	var resp struct {
+		Tracks   int64 `json:"tracks"`
+		Size     int64 `json:"size"`
+		Duration int64 `json:"duration"`
+		Formats  []struct {
+			Format   string `json:"format"`
+			Tracks   int64  `json:"tracks"`
+			Duration int64  `json:"duration"`
+		} `json:"formats"`
+		Issues map[string][]struct {
+			Path    string `json:"path"`
+			TrackID int64  `json:"track_id"`
+			Message string `json:"message"`
+			FoundAt int64  `json:"found_at"`
+		} `json:"issues"`
+	}
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NilErr(t, err, "decoding response JSON")
+
+	assert.Equal(t, int64(3), resp.Tracks, "tracks")
+	assert.Equal(t, int64(6000), resp.Size, "size")
+	assert.Equal(t, int64(300000), resp.Duration, "duration")
+	assert.Equal(t, 2, len(resp.Formats), "formats")
+	if len(resp.Formats) == 2 {
+		assert.Equal(t, "flac", resp.Formats[1].Format, "second format")
+		assert.Equal(t, int64(180000), resp.Formats[1].Duration, "flac duration")
+	}
+
+	assert.Equal(t, len(library.HealthIssueKinds), len(resp.Issues), "issue kinds")
+	assert.Equal(t, 0, len(resp.Issues[string(library.IssueMissingArtwork)]), "artwork")
+	assert.Equal(t, 2, len(resp.Issues[string(library.IssueUnknownArtist)]), "no artist")
+
+	badTags := resp.Issues[string(library.IssueBadTags)]
+	assert.Equal(t, 1, len(badTags), "bad tags")
+	if len(badTags) == 1 {
+		assert.Equal(t, "/music/broken.mp3", badTags[0].Path, "bad tags path")
+		assert.Equal(t, "failed to parse file", badTags[0].Message, "bad tags message")
+		assert.Equal(t, foundAt.Unix(), badTags[0].FoundAt, "bad tags found at")
+	}
+
+	reporter.LibraryHealthReturns(library.HealthReport{}, fmt.Errorf("some error"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "HTTP status on error")
+}
@@ -0,0 +1,111 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// libraryScanHandler shows the status of the library scan (GET) and starts new
+// scans on demand (POST).
+type libraryScanHandler struct {
+	scanner library.Scanner
+}
+
+// NewLibraryScanHandler returns an HTTP handler for following and starting
+// library scans while the server is running.
+func NewLibraryScanHandler(scanner library.Scanner) http.Handler {
+	return &libraryScanHandler{
+		scanner: scanner,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *libraryScanHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	if req.Method == http.MethodPost {
+		h.startScan(w, req)
+		return
+	}
+
+	h.writeStatus(w, http.StatusOK)
+}
+
+func (h *libraryScanHandler) startScan(w http.ResponseWriter, req *http.Request) {
+	var params libraryScanRequest
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("cannot parse request body: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	err := h.scanner.StartScan(params.FullRescan)
+	if errors.Is(err, library.ErrScanInProgress) {
+		webutils.JSONError(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error starting library scan: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	h.writeStatus(w, http.StatusAccepted)
+}
+
+func (h *libraryScanHandler) writeStatus(w http.ResponseWriter, code int) {
+	status := h.scanner.ScanStatus()
+	resp := libraryScanStatus{
+		Running:      status.Running,
+		FullRescan:   status.FullRescan,
+		FilesWalked:  status.FilesWalked,
+		FilesAdded:   status.FilesAdded,
+		FilesUpdated: status.FilesUpdated,
+		Errors:       status.Errors,
+		CurrentPath:  status.CurrentPath,
+	}
+	if !status.StartedAt.IsZero() {
+		resp.StartedAt = status.StartedAt.Unix()
+	}
+	if !status.FinishedAt.IsZero() {
+		resp.FinishedAt = status.FinishedAt.Unix()
+	}
+
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding scan status response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+type libraryScanStatus struct {
+	Running      bool   `json:"running"`
+	FullRescan   bool   `json:"full_rescan"`
+	StartedAt    int64  `json:"started_at,omitempty"`  // Unix timestamp in seconds.
+	FinishedAt   int64  `json:"finished_at,omitempty"` // Unix timestamp in seconds.
+	FilesWalked  int64  `json:"files_walked"`
+	FilesAdded   int64  `json:"files_added"`
+	FilesUpdated int64  `json:"files_updated"`
+	Errors       int64  `json:"errors"`
+	CurrentPath  string `json:"current_path,omitempty"`
+}
+
+type libraryScanRequest struct {
+	FullRescan bool `json:"full_rescan"`
+}
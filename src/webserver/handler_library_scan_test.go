@@ -0,0 +1,122 @@
+package webserver_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestLibraryScanHandler checks that the library scan handler returns the scan
+// status and starts scans with the requested mode.
+func TestLibraryScanHandler(t *testing.T) {
+	tests := []struct {
+		desc    string
+		method  string
+		body    string
+		scanErr error
+
+		expectedCode     int
+		expectedBody     string
+		expectedStart    bool
+		expectedFullScan bool
+	}{
+		{
+			desc:         "get status",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expectedBody: `"files_added":3`,
+		},
+		{
+			desc:          "start scan without body",
+			method:        http.MethodPost,
+			expectedCode:  http.StatusAccepted,
+			expectedBody:  `"running":true`,
+			expectedStart: true,
+		},
+		{
+			desc:             "start full rescan",
+			method:           http.MethodPost,
+			body:             `{"full_rescan": true}`,
+			expectedCode:     http.StatusAccepted,
+			expectedStart:    true,
+			expectedFullScan: true,
+		},
+		{
+			desc:          "start scan while another is running",
+			method:        http.MethodPost,
+			scanErr:       library.ErrScanInProgress,
+			expectedCode:  http.StatusConflict,
+			expectedStart: true,
+		},
+		{
+			desc:          "start scan error",
+			method:        http.MethodPost,
+			scanErr:       fmt.Errorf("some error"),
+			expectedCode:  http.StatusInternalServerError,
+			expectedStart: true,
+		},
+		{
+			desc:         "malformed body",
+			method:       http.MethodPost,
+			body:         `{"full_rescan"`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			scanner := &libraryfakes.FakeScanner{}
+			scanner.ScanStatusReturns(library.ScanStatus{
+				Running:     true,
+				StartedAt:   time.Now(),
+				FilesWalked: 4,
+				FilesAdded:  3,
+				CurrentPath: "/music/song.mp3",
+			})
+			scanner.StartScanReturns(test.scanErr)
+
+			handler := webserver.NewLibraryScanHandler(scanner)
+
+			req := httptest.NewRequest(
+				test.method,
+				webserver.APIv1EndpointLibraryScan,
+				strings.NewReader(test.body),
+			)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+			if test.expectedBody != "" && !strings.Contains(rec.Body.String(), test.expectedBody) {
+				t.Errorf("expected `%s` in body `%s`", test.expectedBody, rec.Body.String())
+			}
+
+			if rec.Code == http.StatusOK || rec.Code == http.StatusAccepted {
+				var decoded any
+				err := json.Unmarshal(rec.Body.Bytes(), &decoded)
+				assert.NilErr(t, err, "decoding response JSON")
+			}
+
+			if !test.expectedStart {
+				assert.Equal(t, 0, scanner.StartScanCallCount(), "start scan calls")
+				return
+			}
+
+			assert.Equal(t, 1, scanner.StartScanCallCount(), "start scan calls")
+			assert.Equal(
+				t,
+				test.expectedFullScan,
+				scanner.StartScanArgsForCall(0),
+				"full rescan",
+			)
+		})
+	}
+}
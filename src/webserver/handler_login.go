@@ -10,6 +10,8 @@ import (
 	"github.com/gbrlsnchs/jwt/v3"
 
 	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 var (
@@ -23,15 +25,23 @@ var (
 )
 
 type loginHandler struct {
-	auth config.Auth
+	auth           config.Auth
+	attempts       *loginattempts.Tracker
+	trustedProxies config.CIDRList
 }
 
 // NewLoginHandler returns a new login handler which will use the information in
 // auth for deciding when user has logged in correctly and also for generating
-// tokens.
-func NewLoginHandler(auth config.Auth) http.Handler {
+// tokens. Failed logins are recorded in attempts which may be nil.
+func NewLoginHandler(
+	auth config.Auth,
+	attempts *loginattempts.Tracker,
+	trustedProxies config.CIDRList,
+) http.Handler {
 	return &loginHandler{
-		auth: auth,
+		auth:           auth,
+		attempts:       attempts,
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -43,12 +53,21 @@ func (h *loginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	user := r.PostFormValue("username")
 	pass := r.PostFormValue("password")
+	clientIP := webutils.ClientIP(r, h.trustedProxies)
+
+	if wait, ok := h.attempts.Allowed(clientIP, user); !ok {
+		webutils.SetRetryAfter(w, wait)
+		http.Error(w, tooManyAttemptsText, http.StatusTooManyRequests)
+		return
+	}
 
 	if !checkLoginCreds(user, pass, h.auth) {
+		h.attempts.Failed(clientIP, user, "login form")
 		h.respondWrong(w, r, returnTo)
 		return
 	}
 
+	h.attempts.Succeeded(clientIP, user)
 	h.respondCorrect(w, r, returnTo)
 }
 
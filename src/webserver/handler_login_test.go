@@ -60,7 +60,7 @@ func TestLoginHandlerSuccessful(t *testing.T) {
 	for _, test := range tests {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
-			h := webserver.NewLoginHandler(cfg)
+			h := webserver.NewLoginHandler(cfg, nil, nil)
 
 			formSting := fmt.Sprintf(
 				"username=%s&password=%s", cfg.User, cfg.Password,
@@ -145,7 +145,7 @@ func TestLoginHandlerWrong(t *testing.T) {
 
 	const returnTo = "/a/test/place?with=query"
 
-	h := webserver.NewLoginHandler(cfg)
+	h := webserver.NewLoginHandler(cfg, nil, nil)
 	req := httptest.NewRequest(
 		http.MethodPost,
 		"/?return_to="+returnTo,
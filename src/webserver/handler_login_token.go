@@ -9,22 +9,33 @@ import (
 	"github.com/gbrlsnchs/jwt/v3"
 
 	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 const (
-	wrongLoginText = "wrong username or password"
+	wrongLoginText      = "wrong username or password"
+	tooManyAttemptsText = "too many failed login attempts, try again later"
 )
 
 type loginTokenHandler struct {
-	auth config.Auth
+	auth           config.Auth
+	attempts       *loginattempts.Tracker
+	trustedProxies config.CIDRList
 }
 
 // NewLoginTokenHandler returns a new login handler which will use the information in
 // auth for deciding when device or program was logged in correctly by entering
-// username and password.
-func NewLoginTokenHandler(auth config.Auth) http.Handler {
+// username and password. Failed logins are recorded in attempts which may be nil.
+func NewLoginTokenHandler(
+	auth config.Auth,
+	attempts *loginattempts.Tracker,
+	trustedProxies config.CIDRList,
+) http.Handler {
 	return &loginTokenHandler{
-		auth: auth,
+		auth:           auth,
+		attempts:       attempts,
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -47,10 +58,19 @@ func (h *loginTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	clientIP := webutils.ClientIP(r, h.trustedProxies)
+	if wait, ok := h.attempts.Allowed(clientIP, reqBody.User); !ok {
+		webutils.SetRetryAfter(w, wait)
+		respondWithJSONError(w, http.StatusTooManyRequests, tooManyAttemptsText)
+		return
+	}
+
 	if !checkLoginCreds(reqBody.User, reqBody.Pass, h.auth) {
+		h.attempts.Failed(clientIP, reqBody.User, "login token")
 		respondWithJSONError(w, http.StatusUnauthorized, wrongLoginText)
 		return
 	}
+	h.attempts.Succeeded(clientIP, reqBody.User)
 
 	now := time.Now()
 	pl := jwt.Payload{
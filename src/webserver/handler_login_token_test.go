@@ -3,14 +3,17 @@ package webserver_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/webserver"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
 )
 
 // TestLoginTokenHandler uses the login-with-token HTTP handler and makes sure the
@@ -79,7 +82,7 @@ func TestLoginTokenHandler(t *testing.T) {
 	for _, test := range tests {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
-			h := routeLoginTokenHandler(webserver.NewLoginTokenHandler(cfg))
+			h := routeLoginTokenHandler(webserver.NewLoginTokenHandler(cfg, nil, nil))
 			req := httptest.NewRequest(
 				http.MethodPost,
 				"/v1/login/token/",
@@ -117,6 +120,52 @@ func TestLoginTokenHandler(t *testing.T) {
 	}
 }
 
+// TestLoginTokenHandlerTooManyAttempts makes sure that clients which failed to log in
+// have to wait before trying again.
+func TestLoginTokenHandlerTooManyAttempts(t *testing.T) {
+	cfg := config.Auth{
+		User:     "test-user",
+		Password: "test-pass",
+		Secret:   "test-secret",
+	}
+
+	attempts := loginattempts.NewTracker(config.LoginAttempts{
+		MaxFailures:     3,
+		InitialBackoff:  time.Minute,
+		LockoutDuration: time.Hour,
+		ForgetAfter:     time.Hour,
+	})
+	h := routeLoginTokenHandler(webserver.NewLoginTokenHandler(cfg, attempts, nil))
+
+	login := func(pass string) *http.Response {
+		reqBody := fmt.Sprintf(`{"username": %q, "password": %q}`, cfg.User, pass)
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/v1/login/token/",
+			bytes.NewBufferString(reqBody),
+		)
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp.Result()
+	}
+
+	if resp := login("wrong-pass"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected HTTP status %d but got %d",
+			http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	resp := login(cfg.Password)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected HTTP status %d but got %d",
+			http.StatusTooManyRequests, resp.StatusCode)
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "60" {
+		t.Errorf("expected Retry-After header `60` but got `%s`", retryAfter)
+	}
+	assertContentTypeJSON(t, resp.Header.Get("Content-Type"))
+}
+
 // routeLoginTokenHandler wraps a handler the same way the web server will do when
 // constructing the main application router. This is needed for tests so that the
 // Gorilla mux variables will be parsed.
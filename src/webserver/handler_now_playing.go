@@ -0,0 +1,61 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
+)
+
+// nowPlayingHandler lists all tracks which are being played at the moment.
+type nowPlayingHandler struct {
+	nowPlaying *nowplaying.Registry
+}
+
+// NewNowPlayingHandler returns an HTTP handler which lists the entries in the
+// nowPlaying registry.
+func NewNowPlayingHandler(nowPlaying *nowplaying.Registry) http.Handler {
+	return &nowPlayingHandler{
+		nowPlaying: nowPlaying,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *nowPlayingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	InternalErrorOnErrorHandler(w, req, h.list)
+}
+
+func (h *nowPlayingHandler) list(w http.ResponseWriter, req *http.Request) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	resp := nowPlayingResponse{
+		Entries: []nowPlayingEntry{},
+	}
+	for _, entry := range h.nowPlaying.Entries() {
+		resp.Entries = append(resp.Entries, nowPlayingEntry{
+			User:      entry.User,
+			Client:    entry.Client,
+			Device:    entry.Device,
+			PlayerID:  entry.PlayerID,
+			StartedAt: entry.StartedAt.Unix(),
+			Track:     entry.Track,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(resp)
+}
+
+type nowPlayingResponse struct {
+	Entries []nowPlayingEntry `json:"now_playing"`
+}
+
+type nowPlayingEntry struct {
+	User      string            `json:"user"`
+	Client    string            `json:"client"`
+	Device    string            `json:"device"`
+	PlayerID  int64             `json:"player_id"`
+	StartedAt int64             `json:"started_at"`
+	Track     library.TrackInfo `json:"track"`
+}
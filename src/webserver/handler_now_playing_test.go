@@ -0,0 +1,77 @@
+package webserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
+)
+
+// TestNowPlayingHandler checks that tracks streamed with the file handler are
+// listed by the now playing handler.
+func TestNowPlayingHandler(t *testing.T) {
+	trackFile := filepath.Join(t.TempDir(), "track.mp3")
+	err := os.WriteFile(trackFile, []byte("some track contents"), 0600)
+	assert.NilErr(t, err, "writing track file")
+
+	lib := &libraryfakes.FakeLibrary{
+		GetFilePathStub: func(_ context.Context, _ int64) string {
+			return trackFile
+		},
+		GetTrackStub: func(_ context.Context, id int64) (library.TrackInfo, error) {
+			return library.TrackInfo{
+				ID:       id,
+				Title:    "Some Song",
+				Duration: 180000,
+			}, nil
+		},
+	}
+
+	registry := nowplaying.NewRegistry()
+	fileHandler := routeFileHandler(
+		webserver.NewFileHandler(lib, registry, config.Auth{User: "test-user"}, nil),
+	)
+	nowPlayingHandler := webserver.NewNowPlayingHandler(registry)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/file/42", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.RemoteAddr = "192.0.2.1:4242"
+	rec := httptest.NewRecorder()
+	fileHandler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "file HTTP status code")
+
+	req = httptest.NewRequest(http.MethodGet, webserver.APIv1EndpointNowPlaying, nil)
+	rec = httptest.NewRecorder()
+	nowPlayingHandler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+
+	var resp struct {
+		NowPlaying []struct {
+			User     string            `json:"user"`
+			Client   string            `json:"client"`
+			Device   string            `json:"device"`
+			PlayerID int64             `json:"player_id"`
+			Track    library.TrackInfo `json:"track"`
+		} `json:"now_playing"`
+	}
+	err = json.NewDecoder(rec.Result().Body).Decode(&resp)
+	assert.NilErr(t, err, "decoding response")
+
+	assert.Equal(t, 1, len(resp.NowPlaying), "number of entries")
+	entry := resp.NowPlaying[0]
+	assert.Equal(t, "test-user", entry.User, "user")
+	assert.Equal(t, "test-agent", entry.Client, "client")
+	assert.Equal(t, "192.0.2.1", entry.Device, "device")
+	assert.Equal(t, 1, entry.PlayerID, "player ID")
+	assert.Equal(t, 42, entry.Track.ID, "track ID")
+}
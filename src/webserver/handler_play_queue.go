@@ -0,0 +1,206 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// playQueueHandler will handle the REST methods for the saved play queue.
+//
+// The play queue operations are as follows:
+//
+// * Getting the queue (GET)
+// * Replacing the queue (PUT)
+//
+// Every saved queue has a version which is sent as an ETag. Clients which send it
+// back in the If-Match header will not overwrite a queue which was saved by
+// another device in the meantime.
+type playQueueHandler struct {
+	queue playqueue.Queuer
+}
+
+// NewPlayQueueHandler returns an HTTP handler for saving and restoring the
+// play queue.
+func NewPlayQueueHandler(queuer playqueue.Queuer) http.Handler {
+	return &playQueueHandler{
+		queue: queuer,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *playQueueHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	if req.Method == http.MethodPut {
+		h.savePlayQueue(w, req)
+		return
+	}
+
+	h.getPlayQueue(w, req)
+}
+
+func (h *playQueueHandler) getPlayQueue(w http.ResponseWriter, req *http.Request) {
+	queue, err := h.queue.Get(req.Context())
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error getting the play queue: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	resp := playQueue{
+		Tracks:       queue.Tracks,
+		CurrentIndex: queue.CurrentIndex,
+		Position:     queue.Position,
+		ChangedBy:    queue.ChangedBy,
+		Version:      queue.Version,
+	}
+	if resp.Tracks == nil {
+		resp.Tracks = []library.TrackInfo{}
+	}
+	if queue.Version > 0 {
+		resp.UpdatedAt = queue.UpdatedAt.Unix()
+	}
+
+	w.Header().Set("ETag", playQueueETag(queue.Version))
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding play queue response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+func (h *playQueueHandler) savePlayQueue(w http.ResponseWriter, req *http.Request) {
+	var params playQueueRequest
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&params); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("cannot parse request body: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	args := playqueue.SaveArgs{
+		TrackIDs:     params.TrackIDs,
+		CurrentIndex: -1,
+		Position:     params.Position,
+		ChangedBy:    params.ChangedBy,
+		IfVersion:    params.Version,
+	}
+	if params.CurrentIndex != nil {
+		args.CurrentIndex = *params.CurrentIndex
+	}
+
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parsePlayQueueETag(ifMatch)
+		if err != nil {
+			webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		args.IfVersion = &version
+	}
+
+	if args.CurrentIndex < -1 || args.CurrentIndex >= len(args.TrackIDs) {
+		webutils.JSONError(
+			w,
+			"current_index must be an index in track_ids",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	if args.Position < 0 {
+		webutils.JSONError(
+			w,
+			"position must be a non-negative number of milliseconds",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	version, err := h.queue.Save(req.Context(), args)
+	if errors.Is(err, playqueue.ErrVersionMismatch) {
+		webutils.JSONError(
+			w,
+			"the play queue has been changed in the meantime",
+			http.StatusPreconditionFailed,
+		)
+		return
+	} else if errors.Is(err, playqueue.ErrTrackNotFound) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error saving the play queue: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.Header().Set("ETag", playQueueETag(version))
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(playQueueSaved{Version: version}); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Encoding play queue response failed: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// playQueueETag returns the value of the ETag header for a play queue with
+// a certain version.
+func playQueueETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parsePlayQueueETag returns the play queue version from the value of an
+// If-Match header.
+func parsePlayQueueETag(etag string) (int64, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	version, err := strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed If-Match header: %s", etag)
+	}
+
+	return version, nil
+}
+
+type playQueue struct {
+	Tracks       []library.TrackInfo `json:"tracks"`
+	CurrentIndex int                 `json:"current_index"` // -1 for no current track.
+	Position     int64               `json:"position"`      // Position in millisecs.
+	ChangedBy    string              `json:"changed_by"`
+	UpdatedAt    int64               `json:"updated_at"` // Unix timestamp in seconds.
+	Version      int64               `json:"version"`
+}
+
+type playQueueRequest struct {
+	TrackIDs     []int64 `json:"track_ids"`
+	CurrentIndex *int    `json:"current_index"`
+	Position     int64   `json:"position"`
+	ChangedBy    string  `json:"changed_by"`
+	Version      *int64  `json:"version"`
+}
+
+type playQueueSaved struct {
+	Version int64 `json:"version"`
+}
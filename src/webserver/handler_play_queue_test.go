@@ -0,0 +1,176 @@
+package webserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/playqueue/playqueuefakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestPlayQueueHandler checks that the play queue handler sends the queue version
+// as an ETag and that it uses the If-Match header for refusing to overwrite
+// queues which have been changed in the meantime.
+func TestPlayQueueHandler(t *testing.T) {
+	stored := playqueue.Queue{
+		Tracks: []library.TrackInfo{
+			{ID: 42, Title: "Long Mix"},
+			{ID: 43, Title: "Short Mix"},
+		},
+		CurrentIndex: 1,
+		Position:     4000,
+		ChangedBy:    "phone",
+		UpdatedAt:    time.Now(),
+		Version:      7,
+	}
+
+	five := int64(5)
+
+	tests := []struct {
+		desc     string
+		method   string
+		body     string
+		ifMatch  string
+		queueErr error
+
+		expectedCode int
+		expectedETag string
+		expectedBody string
+		expectedSave *playqueue.SaveArgs
+	}{
+		{
+			desc:         "get queue",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expectedETag: `"7"`,
+			expectedBody: `"current_index":1`,
+		},
+		{
+			desc:         "get error",
+			method:       http.MethodGet,
+			queueErr:     fmt.Errorf("some error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			desc:         "save queue",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [42, 43, 42], "current_index": 2, "position": 100, "changed_by": "desktop"}`,
+			ifMatch:      `"7"`,
+			expectedCode: http.StatusOK,
+			expectedETag: `"8"`,
+			expectedBody: `"version":8`,
+			expectedSave: &playqueue.SaveArgs{
+				TrackIDs:     []int64{42, 43, 42},
+				CurrentIndex: 2,
+				Position:     100,
+				ChangedBy:    "desktop",
+				IfVersion:    &stored.Version,
+			},
+		},
+		{
+			desc:         "save queue with version in the body",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [42], "version": 5}`,
+			expectedCode: http.StatusOK,
+			expectedSave: &playqueue.SaveArgs{
+				TrackIDs:     []int64{42},
+				CurrentIndex: -1,
+				IfVersion:    &five,
+			},
+		},
+		{
+			desc:         "save stale queue",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [42], "current_index": 0}`,
+			ifMatch:      `"6"`,
+			queueErr:     playqueue.ErrVersionMismatch,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			desc:         "save queue with missing track",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [987654]}`,
+			queueErr:     playqueue.ErrTrackNotFound,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "save queue with current index out of range",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [42], "current_index": 1}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "save queue with malformed If-Match",
+			method:       http.MethodPut,
+			body:         `{"track_ids": [42]}`,
+			ifMatch:      `"baba"`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "save queue with malformed body",
+			method:       http.MethodPut,
+			body:         `{"track_ids"`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			queuer := &playqueuefakes.FakeQueuer{
+				GetStub: func(_ context.Context) (playqueue.Queue, error) {
+					return stored, test.queueErr
+				},
+				SaveStub: func(_ context.Context, _ playqueue.SaveArgs) (int64, error) {
+					return stored.Version + 1, test.queueErr
+				},
+			}
+
+			handler := webserver.NewPlayQueueHandler(queuer)
+
+			req := httptest.NewRequest(
+				test.method,
+				webserver.APIv1EndpointPlayQueue,
+				strings.NewReader(test.body),
+			)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+			if test.expectedETag != "" {
+				assert.Equal(t, test.expectedETag, rec.Header().Get("ETag"), "ETag")
+			}
+			if test.expectedBody != "" && !strings.Contains(rec.Body.String(), test.expectedBody) {
+				t.Errorf("expected `%s` in body `%s`", test.expectedBody, rec.Body.String())
+			}
+
+			if rec.Code == http.StatusOK {
+				var decoded any
+				err := json.Unmarshal(rec.Body.Bytes(), &decoded)
+				assert.NilErr(t, err, "decoding response JSON")
+			}
+
+			if test.expectedSave == nil {
+				return
+			}
+
+			assert.Equal(t, 1, queuer.SaveCallCount(), "save calls")
+			_, args := queuer.SaveArgsForCall(0)
+			if !reflect.DeepEqual(*test.expectedSave, args) {
+				t.Errorf("expected save arguments %+v but got %+v", *test.expectedSave, args)
+			}
+		})
+	}
+}
@@ -22,13 +22,19 @@ import (
 // * Change playlist information and/or reordering tracks (PATCH)
 type playlistHandler struct {
 	playlists playlists.Playlister
+	username  string
 }
 
 // NewSinglePlaylistHandler returns an HTTP handler for interacting with a single
-// playlist identified by its ID.
-func NewSinglePlaylistHandler(playlister playlists.Playlister) http.Handler {
+// playlist identified by its ID. All operations are done on behalf of the user
+// with name `username`.
+func NewSinglePlaylistHandler(
+	playlister playlists.Playlister,
+	username string,
+) http.Handler {
 	return &playlistHandler{
 		playlists: playlister,
+		username:  username,
 	}
 }
 
@@ -73,17 +79,30 @@ func (h *playlistHandler) replacePlaylist(
 		return
 	}
 
+	// Smart playlists are replaced by their rules since they have no tracks
+	// of their own.
 	updateReq := playlists.UpdateArgs{
+		User:            h.username,
 		Name:            params.Name,
 		Desc:            params.Desc,
 		AddTracks:       params.AddTracksByID,
-		RemoveAllTracks: true,
+		RemoveAllTracks: params.Rules == nil,
+		Rules:           params.Rules,
+		Public:          params.Public,
+		Collaborators:   params.Collaborators,
 	}
 
 	err := h.playlists.Update(req.Context(), playlistID, updateReq)
 	if errors.Is(err, playlists.ErrNotFound) {
 		webutils.JSONError(w, "not found", http.StatusNotFound)
 		return
+	} else if errors.Is(err, playlists.ErrInvalidRules) ||
+		errors.Is(err, playlists.ErrReadOnly) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, playlists.ErrNotAllowed) {
+		webutils.JSONError(w, err.Error(), http.StatusForbidden)
+		return
 	} else if err != nil {
 		webutils.JSONError(
 			w,
@@ -113,10 +132,14 @@ func (h *playlistHandler) changePlaylist(
 	}
 
 	updateReq := playlists.UpdateArgs{
-		Name:         params.Name,
-		Desc:         params.Desc,
-		AddTracks:    params.AddTracksByID,
-		RemoveTracks: params.RemoveIndeces,
+		User:          h.username,
+		Name:          params.Name,
+		Desc:          params.Desc,
+		AddTracks:     params.AddTracksByID,
+		RemoveTracks:  params.RemoveIndeces,
+		Rules:         params.Rules,
+		Public:        params.Public,
+		Collaborators: params.Collaborators,
 	}
 
 	for _, moveReq := range params.MoveTracks {
@@ -130,6 +153,13 @@ func (h *playlistHandler) changePlaylist(
 	if errors.Is(err, playlists.ErrNotFound) {
 		webutils.JSONError(w, "not found", http.StatusNotFound)
 		return
+	} else if errors.Is(err, playlists.ErrInvalidRules) ||
+		errors.Is(err, playlists.ErrReadOnly) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, playlists.ErrNotAllowed) {
+		webutils.JSONError(w, err.Error(), http.StatusForbidden)
+		return
 	} else if err != nil {
 		webutils.JSONError(
 			w,
@@ -147,10 +177,16 @@ func (h *playlistHandler) deletePlaylist(
 	req *http.Request,
 	playlistID int64,
 ) {
-	err := h.playlists.Delete(req.Context(), playlistID)
+	err := h.playlists.Delete(req.Context(), playlistID, h.username)
 	if errors.Is(err, playlists.ErrNotFound) {
 		webutils.JSONError(w, "not found", http.StatusNotFound)
 		return
+	} else if errors.Is(err, playlists.ErrReadOnly) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, playlists.ErrNotAllowed) {
+		webutils.JSONError(w, err.Error(), http.StatusForbidden)
+		return
 	} else if err != nil {
 		webutils.JSONError(
 			w,
@@ -168,7 +204,7 @@ func (h *playlistHandler) getPlaylist(
 	req *http.Request,
 	playlistID int64,
 ) {
-	pl, err := h.playlists.Get(req.Context(), playlistID)
+	pl, err := h.playlists.Get(req.Context(), playlistID, h.username)
 	if errors.Is(err, playlists.ErrNotFound) {
 		webutils.JSONError(w, "not found", http.StatusNotFound)
 		return
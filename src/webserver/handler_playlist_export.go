@@ -0,0 +1,186 @@
+package webserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// playlistExportHandler writes playlists as M3U8, PLS or XSPF files.
+type playlistExportHandler struct {
+	playlists playlists.Playlister
+	library   library.Library
+	auth      config.Auth
+}
+
+// NewPlaylistExportHandler returns an HTTP handler which exports a playlist as
+// a playlist file. The format of the file is determined by the extension in the
+// URL. By default the file entries are absolute URLs for streaming the tracks.
+// With the "paths=relative" query parameter they are file paths relative to the
+// library directories instead. Only playlists visible for the user from `auth`
+// could be exported and only their tracks from music folders which this user
+// could access are included.
+func NewPlaylistExportHandler(
+	playlister playlists.Playlister,
+	lib library.Library,
+	auth config.Auth,
+) http.Handler {
+	return &playlistExportHandler{
+		playlists: playlister,
+		library:   lib,
+		auth:      auth,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *playlistExportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	playlistID, err := strconv.ParseInt(vars["playlistID"], 10, 64)
+	if err != nil {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	format, err := playlists.ParseFormat(vars["format"])
+	if err != nil {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	pathsMode := req.URL.Query().Get("paths")
+	if pathsMode != "" && pathsMode != "relative" && pathsMode != "urls" {
+		webutils.JSONError(
+			w,
+			`"paths" must be one of "urls" or "relative"`,
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	ctx := req.Context()
+	pl, err := h.playlists.Get(ctx, playlistID, h.auth.User)
+	if errors.Is(err, playlists.ErrNotFound) {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error getting a playlist: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	allowedFolderIDs, err := allowedMusicFolderIDs(ctx, h.library, h.auth)
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error getting music folders: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+	tracks := accessibleTracks(pl.Tracks, allowedFolderIDs)
+
+	var folders []library.MusicFolder
+	if pathsMode == "relative" {
+		folders, err = h.library.MusicFolders(ctx)
+		if err != nil {
+			webutils.JSONError(
+				w,
+				fmt.Sprintf("error getting library directories: %s", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+	}
+
+	baseURL := fmt.Sprintf(
+		"%s://%s",
+		webutils.RequestScheme(req),
+		webutils.RequestHost(req),
+	)
+
+	entries := make([]playlists.FileEntry, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Missing {
+			// There is no file to point to for tracks which are not in
+			// the library.
+			continue
+		}
+
+		entry := playlists.FileEntry{
+			Artist:   track.Artist,
+			Album:    track.Album,
+			Title:    track.Title,
+			Duration: time.Duration(track.Duration) * time.Millisecond,
+		}
+
+		if pathsMode == "relative" {
+			entry.Location = relativeToFolders(
+				h.library.GetFilePath(ctx, track.ID),
+				folders,
+			)
+		} else {
+			entry.Location = baseURL + strings.Replace(
+				APIv1EndpointFile,
+				"{fileID}",
+				strconv.FormatInt(track.ID, 10),
+				1,
+			)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	var buf bytes.Buffer
+	if err := playlists.Encode(&buf, format, pl.Name, entries); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error encoding the playlist file: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s.%s", pl.Name, format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(
+		"attachment",
+		map[string]string{"filename": fileName},
+	))
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("error writing playlist %d file: %s", playlistID, err)
+	}
+}
+
+// relativeToFolders returns filePath relative to the library directory which
+// contains it. When no such directory is found then filePath is returned as is.
+func relativeToFolders(filePath string, folders []library.MusicFolder) string {
+	for _, folder := range folders {
+		rel, err := filepath.Rel(folder.Path, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(
+			rel,
+			".."+string(filepath.Separator),
+		) {
+			continue
+		}
+
+		return filepath.ToSlash(rel)
+	}
+
+	return filePath
+}
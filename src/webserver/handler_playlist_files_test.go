@@ -0,0 +1,365 @@
+package webserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestPlaylistImportHandler checks that uploaded playlist files are decoded and
+// given to the playlists manager and that the import report is returned.
+func TestPlaylistImportHandler(t *testing.T) {
+	const m3uFile = "#EXTM3U\n" +
+		"#EXTINF:183,Artist Testoff - Tittled Track\n" +
+		"/music/test_file_one.mp3\n" +
+		"#EXTINF:-1,Unknown - Song\n" +
+		"/music/unknown.mp3\n"
+
+	fakeplay := &playlistsfakes.FakePlaylister{}
+	fakeplay.ImportStub = func(
+		_ context.Context,
+		args playlists.ImportArgs,
+	) (playlists.ImportResult, error) {
+		if len(args.Entries) != 2 {
+			return playlists.ImportResult{}, fmt.Errorf("wrong entries count")
+		}
+		return playlists.ImportResult{
+			PlaylistID: 42,
+			Matched:    1,
+			Unmatched:  args.Entries[1:],
+		}, nil
+	}
+
+	handler := routePlaylistFilesHandler(
+		webserver.NewPlaylistImportHandler(fakeplay, "test-user"),
+		webserver.APIv1EndpointPlaylistsImport,
+	)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	formFile, err := form.CreateFormFile("playlist", "Old Favourites.m3u8")
+	assert.NilErr(t, err, "creating form file")
+	_, err = formFile.Write([]byte(m3uFile))
+	assert.NilErr(t, err, "writing form file")
+	assert.NilErr(t, form.Close(), "closing multipart writer")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/playlists/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code, "HTTP status code")
+
+	var importResp struct {
+		ID        int64 `json:"created_playlist_id"`
+		Matched   int   `json:"matched"`
+		Unmatched []struct {
+			Line     int    `json:"line"`
+			Location string `json:"location"`
+			Artist   string `json:"artist"`
+			Title    string `json:"title"`
+		} `json:"unmatched"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	assert.NilErr(t, dec.Decode(&importResp), "decoding import response")
+	assert.Equal(t, 42, importResp.ID, "created playlist ID")
+	assert.Equal(t, 1, importResp.Matched, "matched entries")
+	assert.Equal(t, 1, len(importResp.Unmatched), "unmatched entries")
+	assert.Equal(t, 5, importResp.Unmatched[0].Line, "unmatched line")
+	assert.Equal(t, "/music/unknown.mp3", importResp.Unmatched[0].Location,
+		"unmatched location")
+	assert.Equal(t, "Unknown", importResp.Unmatched[0].Artist, "unmatched artist")
+	assert.Equal(t, "Song", importResp.Unmatched[0].Title, "unmatched title")
+
+	assert.Equal(t, 1, fakeplay.ImportCallCount(), "Import calls")
+	_, importArgs := fakeplay.ImportArgsForCall(0)
+	assert.Equal(t, "Old Favourites", importArgs.Name, "playlist name")
+
+	// Raw request body with explicit format and name.
+	req = httptest.NewRequest(
+		http.MethodPost,
+		"/v1/playlists/import?format=m3u&name=Raw+Upload",
+		strings.NewReader(m3uFile),
+	)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code, "raw body HTTP status code")
+	assert.Equal(t, 2, fakeplay.ImportCallCount(), "Import calls")
+	_, importArgs = fakeplay.ImportArgsForCall(1)
+	assert.Equal(t, "Raw Upload", importArgs.Name, "raw body playlist name")
+}
+
+// TestPlaylistImportHandlerErrors checks how the import handler reacts to
+// malformed requests and errors from the playlists manager.
+func TestPlaylistImportHandlerErrors(t *testing.T) {
+	tests := []struct {
+		desc      string
+		url       string
+		body      string
+		importErr error
+
+		expectedCode int
+	}{
+		{
+			desc:         "missing format",
+			url:          "/v1/playlists/import",
+			body:         "/music/file.mp3\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "unsupported format",
+			url:          "/v1/playlists/import?format=wpl",
+			body:         "/music/file.mp3\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "no entries",
+			url:          "/v1/playlists/import?format=m3u8",
+			body:         "#EXTM3U\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed XSPF",
+			url:          "/v1/playlists/import?format=xspf",
+			body:         "<playlist><trackList>",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "import error",
+			url:          "/v1/playlists/import?format=m3u8",
+			body:         "/music/file.mp3\n",
+			importErr:    fmt.Errorf("some error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			fakeplay := &playlistsfakes.FakePlaylister{}
+			fakeplay.ImportReturns(playlists.ImportResult{}, test.importErr)
+
+			handler := routePlaylistFilesHandler(
+				webserver.NewPlaylistImportHandler(fakeplay, "test-user"),
+				webserver.APIv1EndpointPlaylistsImport,
+			)
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				test.url,
+				strings.NewReader(test.body),
+			)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedCode, resp.Code, "HTTP status code")
+		})
+	}
+}
+
+// TestPlaylistExportHandler checks that playlists are exported in the format
+// from the URL with either stream URLs or relative file paths.
+func TestPlaylistExportHandler(t *testing.T) {
+	fakeplay := &playlistsfakes.FakePlaylister{}
+	fakeplay.GetReturns(playlists.Playlist{
+		ID:   5,
+		Name: "Old Favourites",
+		Tracks: []library.TrackInfo{
+			{
+				ID:       11,
+				Artist:   "Artist Testoff",
+				Album:    "Album Of Tests",
+				Title:    "Tittled Track",
+				Duration: 183000,
+			},
+			{
+				ID:     12,
+				Artist: "Buggy Bugoff",
+				Title:  "Payback",
+			},
+		},
+	}, nil)
+
+	fakelib := &libraryfakes.FakeLibrary{}
+	fakelib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "music", Path: "/music"},
+	}, nil)
+	fakelib.GetFilePathStub = func(_ context.Context, trackID int64) string {
+		if trackID == 11 {
+			return "/music/Artist Testoff/test_file_one.mp3"
+		}
+		return "/elsewhere/third_file.mp3"
+	}
+
+	handler := routePlaylistFilesHandler(
+		webserver.NewPlaylistExportHandler(
+			fakeplay,
+			fakelib,
+			config.Auth{User: "test-user"},
+		),
+		webserver.APIv1EndpointPlaylistExport,
+	)
+
+	tests := []struct {
+		url         string
+		format      playlists.Format
+		contentType string
+		locations   []string
+	}{
+		{
+			url:         "/v1/playlist/5.m3u8",
+			format:      playlists.FormatM3U8,
+			contentType: "audio/x-mpegurl",
+			locations: []string{
+				"http://example.com/v1/file/11",
+				"http://example.com/v1/file/12",
+			},
+		},
+		{
+			url:         "/v1/playlist/5.pls?paths=relative",
+			format:      playlists.FormatPLS,
+			contentType: "audio/x-scpls",
+			locations: []string{
+				"Artist Testoff/test_file_one.mp3",
+				"/elsewhere/third_file.mp3",
+			},
+		},
+		{
+			url:         "/v1/playlist/5.xspf",
+			format:      playlists.FormatXSPF,
+			contentType: "application/xspf+xml",
+			locations: []string{
+				"http://example.com/v1/file/11",
+				"http://example.com/v1/file/12",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code, "HTTP status code")
+
+			contentType := resp.Header().Get("Content-Type")
+			if !strings.HasPrefix(contentType, test.contentType) {
+				t.Errorf("expected content type %s but got %s",
+					test.contentType, contentType)
+			}
+
+			expectedDisposition := fmt.Sprintf(
+				`attachment; filename="Old Favourites.%s"`,
+				test.format,
+			)
+			assert.Equal(
+				t,
+				expectedDisposition,
+				resp.Header().Get("Content-Disposition"),
+				"content disposition",
+			)
+
+			entries, err := playlists.Decode(resp.Body, test.format)
+			assert.NilErr(t, err, "decoding exported playlist")
+			assert.Equal(t, len(test.locations), len(entries), "entries count")
+			for ind, location := range test.locations {
+				if ind >= len(entries) {
+					break
+				}
+				assert.Equal(t, location, entries[ind].Location, "location %d", ind)
+			}
+
+			if len(entries) > 0 {
+				assert.Equal(t, "Tittled Track", entries[0].Title, "first title")
+			}
+		})
+	}
+
+	fakeplay.GetReturns(playlists.Playlist{}, playlists.ErrNotFound)
+	req := httptest.NewRequest(http.MethodGet, "/v1/playlist/6.m3u8", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code, "missing playlist status code")
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/playlist/5.m3u8?paths=foo", nil)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "wrong paths status code")
+}
+
+// TestPlaylistExportHandlerMusicFolders makes sure that exported playlists do
+// not include tracks from music folders which the user could not access.
+func TestPlaylistExportHandlerMusicFolders(t *testing.T) {
+	fakeplay := &playlistsfakes.FakePlaylister{}
+	fakeplay.GetReturns(playlists.Playlist{
+		ID:   5,
+		Name: "Mixed",
+		Tracks: []library.TrackInfo{
+			{ID: 11, Title: "Allowed", MusicFolderID: 1},
+			{ID: 12, Title: "Private", MusicFolderID: 2},
+			{ID: 13, Title: "Also Allowed", MusicFolderID: 1},
+		},
+	}, nil)
+
+	fakelib := &libraryfakes.FakeLibrary{}
+	fakelib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "allowed", Path: "/music/allowed"},
+		{ID: 2, Name: "private", Path: "/music/private"},
+	}, nil)
+
+	handler := routePlaylistFilesHandler(
+		webserver.NewPlaylistExportHandler(
+			fakeplay,
+			fakelib,
+			config.Auth{User: "test-user", MusicFolders: []string{"allowed"}},
+		),
+		webserver.APIv1EndpointPlaylistExport,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/playlist/5.m3u8", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code, "HTTP status code")
+
+	entries, err := playlists.Decode(resp.Body, playlists.FormatM3U8)
+	assert.NilErr(t, err, "decoding exported playlist")
+
+	expected := []string{
+		"http://example.com/v1/file/11",
+		"http://example.com/v1/file/13",
+	}
+	assert.Equal(t, len(expected), len(entries), "entries count")
+	for ind, location := range expected {
+		if ind >= len(entries) {
+			break
+		}
+		assert.Equal(t, location, entries[ind].Location, "location %d", ind)
+	}
+}
+
+// routePlaylistFilesHandler wraps a playlist import or export handler the same
+// way the web server does so that the Gorilla mux variables are parsed.
+func routePlaylistFilesHandler(h http.Handler, endpoint string) http.Handler {
+	router := mux.NewRouter()
+	router.StrictSlash(true)
+	router.UseEncodedPath()
+	router.Handle(endpoint, h).Methods(webserver.APIv1Methods[endpoint]...)
+
+	return router
+}
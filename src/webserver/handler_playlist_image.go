@@ -0,0 +1,190 @@
+package webserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/playlists"
+)
+
+// PlaylistImageHandler is a http.Handler which provides CRUD operations for
+// playlist images. Playlists without uploaded images are served a mosaic of the
+// artwork of their albums.
+type PlaylistImageHandler struct {
+	playlists    playlists.Playlister
+	imageManager library.PlaylistImageManager
+	username     string
+}
+
+// NewPlaylistImageHandler returns a new playlist image handler. Mosaics are
+// created only for playlists visible for the user with name `username`.
+func NewPlaylistImageHandler(
+	playlister playlists.Playlister,
+	im library.PlaylistImageManager,
+	username string,
+) *PlaylistImageHandler {
+	return &PlaylistImageHandler{
+		playlists:    playlister,
+		imageManager: im,
+		username:     username,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *PlaylistImageHandler) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	idString, ok := vars["playlistID"]
+	if !ok {
+		http.NotFoundHandler().ServeHTTP(writer, req)
+		return
+	}
+
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "Bad request. Parsing playlistID: %s\n", err)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		err = h.remove(writer, req, id)
+	} else if req.Method == http.MethodPut {
+		err = h.upload(writer, req, id)
+	} else {
+		err = h.Find(writer, req, id)
+	}
+
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		if _, err := writer.Write([]byte(err.Error())); err != nil {
+			log.Printf("error writing body in PlaylistImageHandler: %s", err)
+		}
+	}
+}
+
+// Find serves the image of a playlist. When the playlist has no uploaded image
+// a mosaic of its albums' artwork is served instead.
+func (h *PlaylistImageHandler) Find(
+	writer http.ResponseWriter,
+	req *http.Request,
+	id int64,
+) error {
+	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Minute)
+	defer cancel()
+
+	imgSize := library.OriginalImage
+	if req.URL.Query().Get("size") == "small" {
+		imgSize = library.SmallImage
+	}
+
+	imgReader, err := h.imageManager.FindPlaylistImage(ctx, id, imgSize)
+	if errors.Is(err, library.ErrArtworkNotFound) {
+		imgReader, err = h.mosaic(ctx, id, imgSize)
+	}
+
+	if errors.Is(err, library.ErrArtworkNotFound) ||
+		errors.Is(err, playlists.ErrNotFound) {
+		writer.WriteHeader(http.StatusNotFound)
+		if req.Method == http.MethodHead {
+			return nil
+		}
+
+		fmt.Fprintln(writer, "404 image not found")
+		return nil
+	}
+
+	if err != nil {
+		log.Printf("Error finding playlist %d image: %s\n", id, err)
+		return err
+	}
+
+	defer imgReader.Close()
+
+	// Playlist images change along with the playlists so they are not cached
+	// for long.
+	writer.Header().Set("Cache-Control", "no-cache")
+	if req.Method == http.MethodHead {
+		n, _ := io.Copy(io.Discard, imgReader)
+		writer.Header().Set("Content-Length", strconv.FormatInt(n, 10))
+		return nil
+	}
+
+	_, err = io.Copy(writer, imgReader)
+	if err != nil {
+		log.Printf("error sending HTTP data for playlist image %d: %s", id, err)
+	}
+
+	return nil
+}
+
+// mosaic returns a mosaic of the artwork of the albums in the playlist, in
+// the order in which they first appear in it.
+func (h *PlaylistImageHandler) mosaic(
+	ctx context.Context,
+	id int64,
+	size library.ImageSize,
+) (io.ReadCloser, error) {
+	playlist, err := h.playlists.Get(ctx, id, h.username)
+	if err != nil {
+		return nil, err
+	}
+
+	albumIDs := make([]int64, 0, len(playlist.Tracks))
+	for _, track := range playlist.Tracks {
+		if track.Missing {
+			continue
+		}
+		albumIDs = append(albumIDs, track.AlbumID)
+	}
+
+	return h.imageManager.AlbumsMosaic(ctx, albumIDs, size)
+}
+
+func (h *PlaylistImageHandler) remove(
+	writer http.ResponseWriter,
+	req *http.Request,
+	id int64,
+) error {
+	if err := h.imageManager.RemovePlaylistImage(req.Context(), id); err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *PlaylistImageHandler) upload(
+	writer http.ResponseWriter,
+	req *http.Request,
+	id int64,
+) error {
+	err := h.imageManager.SavePlaylistImage(req.Context(), id, req.Body)
+	if errors.Is(err, library.ErrPlaylistNotFound) {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte("Playlist not found."))
+		return nil
+	} else if errors.Is(err, library.ErrArtworkTooBig) {
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = writer.Write([]byte("Uploaded image is too large."))
+		return nil
+	} else if _, ok := err.(*library.ArtworkError); ok {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+	return nil
+}
@@ -0,0 +1,214 @@
+package webserver_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestPlaylistImageHandler checks that playlist images could be uploaded, found
+// and removed and that mosaics are served for playlists without images.
+func TestPlaylistImageHandler(t *testing.T) {
+	imageOriginal := []byte("playlist image original")
+	imageSmall := []byte("playlist image small")
+	mosaic := []byte("playlist mosaic")
+
+	fakeIM := &libraryfakes.FakePlaylistImageManager{
+		FindPlaylistImageStub: func(
+			_ context.Context,
+			playlistID int64,
+			size library.ImageSize,
+		) (io.ReadCloser, error) {
+			if playlistID != 1 {
+				return nil, library.ErrArtworkNotFound
+			}
+
+			if size == library.SmallImage {
+				return io.NopCloser(bytes.NewReader(imageSmall)), nil
+			}
+
+			return io.NopCloser(bytes.NewReader(imageOriginal)), nil
+		},
+		SavePlaylistImageStub: func(
+			_ context.Context,
+			playlistID int64,
+			r io.Reader,
+		) error {
+			body, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			if playlistID != 1 {
+				return library.ErrPlaylistNotFound
+			}
+
+			switch string(body) {
+			case "too big":
+				return library.ErrArtworkTooBig
+			case "":
+				return library.NewArtworkError(io.ErrUnexpectedEOF)
+			}
+
+			return nil
+		},
+		AlbumsMosaicStub: func(
+			_ context.Context,
+			albumIDs []int64,
+			_ library.ImageSize,
+		) (io.ReadCloser, error) {
+			if len(albumIDs) == 0 {
+				return nil, library.ErrArtworkNotFound
+			}
+
+			return io.NopCloser(bytes.NewReader(mosaic)), nil
+		},
+	}
+
+	fakeplay := &playlistsfakes.FakePlaylister{
+		GetStub: func(_ context.Context, id int64, _ string) (playlists.Playlist, error) {
+			switch id {
+			case 2:
+				return playlists.Playlist{
+					ID: 2,
+					Tracks: []library.TrackInfo{
+						{ID: 1, AlbumID: 5},
+						{ID: 2, AlbumID: 7},
+						{ID: 3, AlbumID: 5},
+					},
+				}, nil
+			case 3:
+				return playlists.Playlist{ID: 3}, nil
+			default:
+				return playlists.Playlist{}, playlists.ErrNotFound
+			}
+		},
+	}
+
+	router := mux.NewRouter()
+	router.UseEncodedPath()
+	router.Handle(
+		webserver.APIv1EndpointPlaylistImage,
+		webserver.NewPlaylistImageHandler(fakeplay, fakeIM, "test-user"),
+	).Methods(webserver.APIv1Methods[webserver.APIv1EndpointPlaylistImage]...)
+
+	tests := []struct {
+		desc         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		expectedBody []byte
+	}{
+		{
+			desc:         "original image",
+			method:       http.MethodGet,
+			url:          "/v1/playlist/1/image",
+			expectedCode: http.StatusOK,
+			expectedBody: imageOriginal,
+		},
+		{
+			desc:         "small image",
+			method:       http.MethodGet,
+			url:          "/v1/playlist/1/image?size=small",
+			expectedCode: http.StatusOK,
+			expectedBody: imageSmall,
+		},
+		{
+			desc:         "mosaic",
+			method:       http.MethodGet,
+			url:          "/v1/playlist/2/image",
+			expectedCode: http.StatusOK,
+			expectedBody: mosaic,
+		},
+		{
+			desc:         "empty playlist",
+			method:       http.MethodGet,
+			url:          "/v1/playlist/3/image",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "missing playlist",
+			method:       http.MethodGet,
+			url:          "/v1/playlist/4/image",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "upload",
+			method:       http.MethodPut,
+			url:          "/v1/playlist/1/image",
+			body:         "image",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			desc:         "upload to missing playlist",
+			method:       http.MethodPut,
+			url:          "/v1/playlist/4/image",
+			body:         "image",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "upload too big",
+			method:       http.MethodPut,
+			url:          "/v1/playlist/1/image",
+			body:         "too big",
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:         "upload empty",
+			method:       http.MethodPut,
+			url:          "/v1/playlist/1/image",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "delete",
+			method:       http.MethodDelete,
+			url:          "/v1/playlist/1/image",
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				test.method,
+				test.url,
+				bytes.NewBufferString(test.body),
+			)
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != test.expectedCode {
+				t.Errorf("expected code %d but got %d", test.expectedCode, resp.Code)
+			}
+
+			if test.expectedBody != nil && !bytes.Equal(test.expectedBody, resp.Body.Bytes()) {
+				t.Errorf("expected body `%s` but got `%s`",
+					test.expectedBody, resp.Body.Bytes())
+			}
+		})
+	}
+
+	if calls := fakeIM.AlbumsMosaicCallCount(); calls != 2 {
+		t.Fatalf("expected mosaic to be requested twice but it was %d times", calls)
+	}
+	_, albumIDs, _ := fakeIM.AlbumsMosaicArgsForCall(0)
+	if !slices.Equal([]int64{5, 7, 5}, albumIDs) {
+		t.Errorf("wrong album IDs for mosaic: %v", albumIDs)
+	}
+
+	if calls := fakeIM.RemovePlaylistImageCallCount(); calls != 1 {
+		t.Errorf("expected image to be removed once but it was %d times", calls)
+	}
+}
@@ -0,0 +1,178 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// maxPlaylistFileSize is the maximum size of playlist files which could be
+// imported. Even playlists with tens of thousands of entries are well below it.
+const maxPlaylistFileSize = 10 << 20
+
+// defaultImportedPlaylistName is used for imported playlists when neither a name
+// nor a file name is available.
+const defaultImportedPlaylistName = "Imported Playlist"
+
+// playlistImportHandler creates playlists out of uploaded playlist files.
+type playlistImportHandler struct {
+	playlists playlists.Playlister
+	username  string
+}
+
+// NewPlaylistImportHandler returns an HTTP handler which creates a new playlist
+// out of an M3U, M3U8, PLS or XSPF file. The file could be uploaded as the
+// "playlist" field of a multipart form or as the raw request body. In the latter
+// case its format must be set with the "format" query parameter.
+//
+// The imported playlists are owned by the user with name `username`.
+func NewPlaylistImportHandler(
+	playlister playlists.Playlister,
+	username string,
+) http.Handler {
+	return &playlistImportHandler{
+		playlists: playlister,
+		username:  username,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *playlistImportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	req.Body = http.MaxBytesReader(w, req.Body, maxPlaylistFileSize)
+
+	var (
+		file     io.Reader = req.Body
+		fileName string
+	)
+
+	mediaType := req.Header.Get("Content-Type")
+	if strings.HasPrefix(mediaType, "multipart/form-data") {
+		formFile, header, err := req.FormFile("playlist")
+		if err != nil {
+			webutils.JSONError(
+				w,
+				fmt.Sprintf(`Cannot read the "playlist" form file: %s`, err),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		defer formFile.Close()
+
+		file = formFile
+		fileName = header.Filename
+	}
+
+	formatName := req.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = fileName
+	}
+
+	format, err := playlists.ParseFormat(formatName)
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Unsupported playlist format %q", formatName),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	entries, err := playlists.Decode(file, format)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		webutils.JSONError(
+			w,
+			"Playlist file is too large",
+			http.StatusRequestEntityTooLarge,
+		)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Cannot read the playlist file: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	if len(entries) == 0 {
+		webutils.JSONError(
+			w,
+			"The playlist file does not have any entries",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	name := req.URL.Query().Get("name")
+	if name == "" && fileName != "" {
+		name = strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	}
+	if name == "" {
+		name = defaultImportedPlaylistName
+	}
+
+	result, err := h.playlists.Import(req.Context(), playlists.ImportArgs{
+		Name:        name,
+		Description: req.URL.Query().Get("description"),
+		Owner:       h.username,
+		Entries:     entries,
+	})
+	if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Failed to import playlist: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	resp := importPlaylistResponse{
+		CreatedPlaylistID: result.PlaylistID,
+		Matched:           result.Matched,
+		Unmatched:         []unmatchedPlaylistEntry{},
+	}
+	for _, entry := range result.Unmatched {
+		resp.Unmatched = append(resp.Unmatched, unmatchedPlaylistEntry{
+			Line:     entry.Line,
+			Location: entry.Location,
+			Artist:   entry.Artist,
+			Album:    entry.Album,
+			Title:    entry.Title,
+		})
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("Playlist imported but cannot write response JSON: %s", err),
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+type importPlaylistResponse struct {
+	CreatedPlaylistID int64                    `json:"created_playlist_id"`
+	Matched           int                      `json:"matched"`
+	Unmatched         []unmatchedPlaylistEntry `json:"unmatched"`
+}
+
+// unmatchedPlaylistEntry is an entry from an imported playlist file for which
+// no track was found in the library.
+type unmatchedPlaylistEntry struct {
+	Line     int    `json:"line"`
+	Location string `json:"location"`
+	Artist   string `json:"artist,omitempty"`
+	Album    string `json:"album,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
@@ -91,6 +91,22 @@ func TestPlaylistHandlerErrors(t *testing.T) {
 			playlistsErr: fmt.Errorf("some error"),
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			desc:         "replace smart playlist tracks",
+			method:       http.MethodPut,
+			url:          "/v1/playlist/5",
+			body:         `{"name": "does not matter", "add_tracks_by_id": [1]}`,
+			playlistsErr: playlists.ErrSmartPlaylist,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "change playlist invalid rules",
+			method:       http.MethodPatch,
+			url:          "/v1/playlist/5",
+			body:         `{"rules": {"limit": -1}}`,
+			playlistsErr: fmt.Errorf("wrapped: %w", playlists.ErrInvalidRules),
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			desc:         "replace playlist wrong request JSON",
 			method:       http.MethodPut,
@@ -143,6 +159,21 @@ func TestPlaylistHandlerErrors(t *testing.T) {
 			playlistsErr: fmt.Errorf("wrapped: %w", playlists.ErrNotFound),
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			desc:         "delete playlist of another user",
+			method:       http.MethodDelete,
+			url:          "/v1/playlist/5",
+			playlistsErr: playlists.ErrNotAllowed,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "update playlist of another user",
+			method:       http.MethodPatch,
+			url:          "/v1/playlist/5",
+			body:         `{"public": false}`,
+			playlistsErr: fmt.Errorf("wrapped: %w", playlists.ErrNotAllowed),
+			expectedCode: http.StatusForbidden,
+		},
 		{
 			desc:         "delete playlist general error",
 			method:       http.MethodDelete,
@@ -182,7 +213,7 @@ func TestPlaylistHandlerErrors(t *testing.T) {
 			fakeplay.UpdateReturns(test.playlistsErr)
 
 			handler := routePlaylistHandler(
-				webserver.NewSinglePlaylistHandler(fakeplay),
+				webserver.NewSinglePlaylistHandler(fakeplay, "test-user"),
 			)
 
 			var body io.Reader
@@ -273,7 +304,7 @@ func TestPlaylistGettingSingle(t *testing.T) {
 	fakeplay.GetReturns(expected, nil)
 
 	handler := routePlaylistHandler(
-		webserver.NewSinglePlaylistHandler(fakeplay),
+		webserver.NewSinglePlaylistHandler(fakeplay, "test-user"),
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/v1/playlist/5", nil)
@@ -285,8 +316,9 @@ func TestPlaylistGettingSingle(t *testing.T) {
 	assertJSONContentType(t, result)
 
 	assert.Equal(t, 1, fakeplay.GetCallCount(), "handler did not request the playlist")
-	_, callID := fakeplay.GetArgsForCall(0)
+	_, callID, user := fakeplay.GetArgsForCall(0)
 	assert.Equal(t, 5, callID, "getting playlist called with wrong ID")
+	assert.Equal(t, "test-user", user, "getting playlist for wrong user")
 
 	var actual apiPlaylist
 	dec := json.NewDecoder(result.Body)
@@ -356,7 +388,7 @@ func TestPlaylistChange(t *testing.T) {
 			fakeplay := &playlistsfakes.FakePlaylister{}
 
 			handler := routePlaylistHandler(
-				webserver.NewSinglePlaylistHandler(fakeplay),
+				webserver.NewSinglePlaylistHandler(fakeplay, "test-user"),
 			)
 
 			expected := test.expected
@@ -400,7 +432,7 @@ func TestPlaylistRemoving(t *testing.T) {
 	fakeplay := &playlistsfakes.FakePlaylister{}
 
 	handler := routePlaylistHandler(
-		webserver.NewSinglePlaylistHandler(fakeplay),
+		webserver.NewSinglePlaylistHandler(fakeplay, "test-user"),
 	)
 
 	req := httptest.NewRequest(http.MethodDelete, "/v1/playlist/5521", nil)
@@ -411,8 +443,9 @@ func TestPlaylistRemoving(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, result.StatusCode, "unexpected HTTP response")
 
 	assert.Equal(t, 1, fakeplay.DeleteCallCount(), "unexpected number of Delete calls")
-	_, actualID := fakeplay.DeleteArgsForCall(0)
+	_, actualID, user := fakeplay.DeleteArgsForCall(0)
 	assert.Equal(t, 5521, actualID, "wrong playlist deleted wow!")
+	assert.Equal(t, "test-user", user, "playlist deleted by wrong user")
 }
 
 func assertJSONContentType(t *testing.T, result *http.Response) {
@@ -2,6 +2,7 @@ package webserver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -15,13 +16,19 @@ import (
 // playlistsHandler will list playlists (GET) and create a new one (POST).
 type playlistsHandler struct {
 	playlists playlists.Playlister
+	username  string
 }
 
 // NewPlaylistsHandler returns an http.Handler which supports listing all playlists
-// with a GET request and creating a new playlist with a POST request.
-func NewPlaylistsHandler(playlister playlists.Playlister) http.Handler {
+// with a GET request and creating a new playlist with a POST request. Playlists
+// are listed and created on behalf of the user with name `username`.
+func NewPlaylistsHandler(
+	playlister playlists.Playlister,
+	username string,
+) http.Handler {
 	return &playlistsHandler{
 		playlists: playlister,
+		username:  username,
 	}
 }
 
@@ -50,11 +57,19 @@ func (plh playlistsHandler) create(w http.ResponseWriter, req *http.Request) {
 	}
 
 	newID, err := plh.playlists.Create(req.Context(), playlists.CreateArgs{
-		Name:        createReq.Name,
-		Description: createReq.Desc,
-		Tracks:      createReq.AddTracksByID,
+		Name:          createReq.Name,
+		Description:   createReq.Desc,
+		Owner:         plh.username,
+		Public:        createReq.Public,
+		Collaborators: derefStrings(createReq.Collaborators),
+		Tracks:        createReq.AddTracksByID,
+		Rules:         createReq.Rules,
 	})
-	if err != nil {
+	if errors.Is(err, playlists.ErrInvalidRules) ||
+		errors.Is(err, playlists.ErrReadOnly) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
 		webutils.JSONError(
 			w,
 			fmt.Sprintf("Failed to create playlist: %s", err),
@@ -125,7 +140,7 @@ func (plh playlistsHandler) list(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	playlistsCount, err := plh.playlists.Count(req.Context())
+	playlistsCount, err := plh.playlists.Count(req.Context(), plh.username)
 	if err != nil {
 		webutils.JSONError(
 			w,
@@ -148,6 +163,7 @@ func (plh playlistsHandler) list(w http.ResponseWriter, req *http.Request) {
 		Playlists:  []playlist{},
 	}
 	playlists, err := plh.playlists.List(req.Context(), playlists.ListArgs{
+		User:   plh.username,
 		Offset: (page - 1) * perPage,
 		Count:  perPage,
 	})
@@ -211,11 +227,24 @@ type playlist struct {
 	ID          int64               `json:"id"`
 	Name        string              `json:"name"`
 	Desc        string              `json:"description,omitempty"`
+	Owner       string              `json:"owner,omitempty"`
+	Public      bool                `json:"public"`
 	TracksCount int64               `json:"tracks_count"`
 	Duration    int64               `json:"duration"`   // Playlist duration in millisecs.
 	CreatedAt   int64               `json:"created_at"` // Unix timestamp in seconds.
 	UpdatedAt   int64               `json:"updated_at"` // Unix timestamp in seconds.
 	Tracks      []library.TrackInfo `json:"tracks,omitempty"`
+
+	// Rules are set only for smart playlists. Their tracks are read-only.
+	Rules *playlists.SmartRules `json:"rules,omitempty"`
+
+	// ReadOnly is true for smart playlists and for playlists which follow
+	// playlist files in the library directories.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// Collaborators are the users other than the owner who may edit the
+	// playlist.
+	Collaborators []string `json:"collaborators,omitempty"`
 }
 
 // toAPIplaylist converts a playlists.Playlist to a playlist object suitable for
@@ -225,11 +254,17 @@ func toAPIplaylist(pl playlists.Playlist) playlist {
 		ID:          pl.ID,
 		Name:        pl.Name,
 		Desc:        pl.Desc,
+		Owner:       pl.Owner,
+		Public:      pl.Public,
 		TracksCount: pl.TracksCount,
 		Duration:    pl.Duration.Milliseconds(),
 		CreatedAt:   pl.CreatedAt.Unix(),
 		UpdatedAt:   pl.UpdatedAt.Unix(),
 		Tracks:      pl.Tracks,
+		Rules:       pl.Rules,
+		ReadOnly:    pl.ReadOnly(),
+
+		Collaborators: pl.Collaborators,
 	}
 }
 
@@ -239,6 +274,27 @@ type playlistRequest struct {
 	AddTracksByID []int64             `json:"add_tracks_by_id"`
 	RemoveIndeces []int64             `json:"remove_indeces"`
 	MoveTracks    []playlistTrackMove `json:"move_indeces"`
+
+	// Rules makes the playlist a smart one or changes the rules of a smart
+	// playlist.
+	Rules *playlists.SmartRules `json:"rules"`
+
+	// Public sets whether the playlist is visible for all users. Only the
+	// owner of the playlist may change it.
+	Public *bool `json:"public"`
+
+	// Collaborators replaces the users who may edit the playlist. Only the
+	// owner of the playlist may change it.
+	Collaborators *[]string `json:"collaborators"`
+}
+
+// derefStrings returns the slice pointed by `s` or nil when `s` is nil.
+func derefStrings(s *[]string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return *s
 }
 
 // playlistTrackMove encodes a request to move a track from a particular index to
@@ -32,7 +32,7 @@ func TestPlaylistsCreation(t *testing.T) {
 	fakeplay.CreateReturns(expectedID, nil)
 
 	handler := routePlaylistsHandler(
-		webserver.NewPlaylistsHandler(fakeplay),
+		webserver.NewPlaylistsHandler(fakeplay, "test-user"),
 	)
 
 	body := bytes.NewBufferString(`{
@@ -69,6 +69,53 @@ func TestPlaylistsCreation(t *testing.T) {
 	assert.Equal(t, expectedID, respJSON.ID, "wrong ID returned by the HTTP response")
 }
 
+// TestSmartPlaylistsCreation checks that rules for smart playlists are parsed
+// from the create request.
+func TestSmartPlaylistsCreation(t *testing.T) {
+	fakeplay := &playlistsfakes.FakePlaylister{}
+	fakeplay.CreateReturns(11, nil)
+
+	handler := routePlaylistsHandler(
+		webserver.NewPlaylistsHandler(fakeplay, "test-user"),
+	)
+
+	body := bytes.NewBufferString(`{
+		"name": "Forgotten Favourites",
+		"rules": {
+			"match": {
+				"all": [
+					{"field": "rating", "operator": "gt", "value": 3},
+					{"field": "last_played", "operator": "not_in_last", "value": 90}
+				]
+			},
+			"sort": "random",
+			"limit": 50
+		}
+	}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/playlists", body)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code, "unexpected HTTP response")
+	assert.Equal(t, 1, fakeplay.CreateCallCount(), "unexpected number of Create calls")
+
+	_, actualArgs := fakeplay.CreateArgsForCall(0)
+	if actualArgs.Rules == nil {
+		t.Fatalf("smart playlist rules were not given to the playlists manager")
+	}
+
+	rules := actualArgs.Rules
+	assert.Equal(t, "random", rules.Sort, "sort field")
+	assert.Equal(t, 50, rules.Limit, "limit")
+	assert.Equal(t, 2, len(rules.Match.All), "number of rules")
+	if len(rules.Match.All) == 2 {
+		rule := rules.Match.All[1]
+		assert.Equal(t, "last_played", rule.Field, "rule field")
+		assert.Equal(t, playlists.OpNotInLast, rule.Operator, "rule operator")
+		assert.Equal(t, any(float64(90)), rule.Value, "rule value")
+	}
+}
+
 // TestPlaylistsHandlerErrors checks how the playlists handler reacts to errors
 // returned by the playlist manager.
 func TestPlaylistsHandlerErrors(t *testing.T) {
@@ -102,6 +149,22 @@ func TestPlaylistsHandlerErrors(t *testing.T) {
 			playlistsErr: fmt.Errorf("some error"),
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			desc:         "create playlist invalid rules",
+			method:       http.MethodPost,
+			url:          "/v1/playlists",
+			body:         `{"name": "smart", "rules": {"sort": "mood"}}`,
+			playlistsErr: fmt.Errorf("wrapped: %w", playlists.ErrInvalidRules),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "create smart playlist with tracks",
+			method:       http.MethodPost,
+			url:          "/v1/playlists",
+			body:         `{"name": "smart", "rules": {}, "add_tracks_by_id": [1]}`,
+			playlistsErr: playlists.ErrSmartPlaylist,
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			desc:         "create wrong playlist JSON",
 			method:       http.MethodPost,
@@ -168,7 +231,7 @@ func TestPlaylistsHandlerErrors(t *testing.T) {
 			}
 
 			handler := routePlaylistsHandler(
-				webserver.NewPlaylistsHandler(fakeplay),
+				webserver.NewPlaylistsHandler(fakeplay, "test-user"),
 			)
 
 			var body io.Reader
@@ -225,6 +288,7 @@ func TestPlaylistsListing(t *testing.T) {
 			desc: "middle page",
 			url:  "/v1/playlists?page=2&per-page=3",
 			expectedListArgs: playlists.ListArgs{
+				User:   "test-user",
 				Offset: 3,
 				Count:  3,
 			},
@@ -252,6 +316,7 @@ func TestPlaylistsListing(t *testing.T) {
 			desc: "first page",
 			url:  "/v1/playlists?per-page=2",
 			expectedListArgs: playlists.ListArgs{
+				User:   "test-user",
 				Offset: 0,
 				Count:  2,
 			},
@@ -280,6 +345,7 @@ func TestPlaylistsListing(t *testing.T) {
 			desc: "last page",
 			url:  "/v1/playlists?per-page=2&page=3",
 			expectedListArgs: playlists.ListArgs{
+				User:   "test-user",
 				Offset: 4,
 				Count:  2,
 			},
@@ -318,7 +384,7 @@ func TestPlaylistsListing(t *testing.T) {
 			fakeplay.CountReturns(test.totalInDB, nil)
 
 			handler := routePlaylistsHandler(
-				webserver.NewPlaylistsHandler(fakeplay),
+				webserver.NewPlaylistsHandler(fakeplay, "test-user"),
 			)
 
 			req := httptest.NewRequest(http.MethodGet, test.url, nil)
@@ -7,6 +7,7 @@ import (
 	"net/url"
 
 	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 )
 
@@ -14,6 +15,7 @@ import (
 // the Library to return a list of matched files to the interface.
 type SearchHandler struct {
 	library library.Library
+	auth    config.Auth
 }
 
 // ServeHTTP is required by the http.Handler's interface
@@ -46,9 +48,17 @@ func (sh SearchHandler) search(writer http.ResponseWriter, req *http.Request) er
 		}
 	}
 
+	musicFolderIDs, err := allowedMusicFolderIDs(req.Context(), sh.library, sh.auth)
+	if err != nil {
+		return err
+	}
+
 	results := sh.library.Search(
 		req.Context(),
-		library.SearchArgs{Query: query},
+		library.SearchArgs{
+			Query:          query,
+			MusicFolderIDs: musicFolderIDs,
+		},
 	)
 
 	if len(results) == 0 {
@@ -61,9 +71,11 @@ func (sh SearchHandler) search(writer http.ResponseWriter, req *http.Request) er
 }
 
 // NewSearchHandler returns a new SearchHandler for processing search queries. They
-// will be run against the supplied library
-func NewSearchHandler(lib library.Library) *SearchHandler {
+// will be run against the supplied library. Only tracks from the music folders
+// which the user from auth could access are found.
+func NewSearchHandler(lib library.Library, auth config.Auth) *SearchHandler {
 	sh := new(SearchHandler)
 	sh.library = lib
+	sh.auth = auth
 	return sh
 }
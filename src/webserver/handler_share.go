@@ -0,0 +1,149 @@
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/version"
+)
+
+// shareHandler shows the public page of a share. It lists all shared tracks
+// and a player for each of them. No authentication is required for it.
+type shareHandler struct {
+	shares shares.Sharer
+	tpl    *template.Template
+}
+
+// NewShareHandler returns an HTTP handler which renders the page of the share
+// identified by its ID in the URL. Every visit of the page is recorded.
+func NewShareHandler(sharer shares.Sharer, tpl *template.Template) http.Handler {
+	return &shareHandler{
+		shares: sharer,
+		tpl:    tpl,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *shareHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	InternalErrorOnErrorHandler(w, req, h.servePage)
+}
+
+func (h *shareHandler) servePage(w http.ResponseWriter, req *http.Request) error {
+	now := time.Now()
+	share, found, err := getActiveShare(req, h.shares, now)
+	if err != nil {
+		return err
+	}
+	if !found {
+		http.NotFoundHandler().ServeHTTP(w, req)
+		return nil
+	}
+
+	if err := h.shares.RecordVisit(req.Context(), share.ID, now); err != nil {
+		log.Printf("failed to record visit for share %s: %s", share.ID, err)
+	}
+
+	data := struct {
+		Share   shares.Share
+		Version string
+	}{
+		Share:   share,
+		Version: version.Version,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := h.tpl.Execute(w, data); err != nil {
+		return fmt.Errorf("executing share template: %w", err)
+	}
+
+	return nil
+}
+
+// shareFileHandler serves media files for shares. Only files which are part of
+// a share which has not expired are served.
+type shareFileHandler struct {
+	shares  shares.Sharer
+	library library.Library
+}
+
+// NewShareFileHandler returns an HTTP handler which serves a track from a share.
+// Both the share and the track are identified by their IDs in the URL.
+func NewShareFileHandler(sharer shares.Sharer, lib library.Library) http.Handler {
+	return &shareFileHandler{
+		shares:  sharer,
+		library: lib,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *shareFileHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	InternalErrorOnErrorHandler(w, req, h.serveFile)
+}
+
+func (h *shareFileHandler) serveFile(w http.ResponseWriter, req *http.Request) error {
+	trackID, err := strconv.ParseInt(mux.Vars(req)["fileID"], 10, 64)
+	if err != nil {
+		http.NotFoundHandler().ServeHTTP(w, req)
+		return nil
+	}
+
+	share, found, err := getActiveShare(req, h.shares, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found || !share.HasTrack(trackID) {
+		http.NotFoundHandler().ServeHTTP(w, req)
+		return nil
+	}
+
+	filePath := h.library.GetFilePath(req.Context(), trackID)
+	fileReader, err := os.Open(filePath)
+	if err != nil {
+		http.NotFoundHandler().ServeHTTP(w, req)
+		return nil
+	}
+	defer fileReader.Close()
+
+	modTime := time.Time{}
+	st, err := fileReader.Stat()
+	if err == nil {
+		modTime = st.ModTime()
+	}
+
+	baseName := filepath.Base(filePath)
+	w.Header().Add("Content-Disposition",
+		fmt.Sprintf("filename=\"%s\"", baseName))
+	http.ServeContent(w, req, baseName, modTime, fileReader)
+	return nil
+}
+
+// getActiveShare returns the share for the shareID URL variable. The returned
+// boolean is false when there is no such share or when it has expired.
+func getActiveShare(
+	req *http.Request,
+	sharer shares.Sharer,
+	now time.Time,
+) (shares.Share, bool, error) {
+	share, err := sharer.Get(req.Context(), mux.Vars(req)["shareID"])
+	if errors.Is(err, shares.ErrNotFound) {
+		return shares.Share{}, false, nil
+	} else if err != nil {
+		return shares.Share{}, false, fmt.Errorf("getting share: %w", err)
+	}
+
+	if share.Expired(now) {
+		return shares.Share{}, false, nil
+	}
+
+	return share, true, nil
+}
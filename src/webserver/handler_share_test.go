@@ -0,0 +1,143 @@
+package webserver_test
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/shares/sharesfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestShareHandlers checks that the share page lists the shared tracks, that only
+// shared tracks could be played and that expired shares are not accessible.
+func TestShareHandlers(t *testing.T) {
+	trackFile := filepath.Join(t.TempDir(), "track.mp3")
+	const trackContents = "some track contents"
+	err := os.WriteFile(trackFile, []byte(trackContents), 0600)
+	assert.NilErr(t, err, "writing track file")
+
+	sharesByID := map[string]shares.Share{
+		"active": {
+			ID:          "active",
+			Description: "Summer Songs",
+			Tracks: []library.TrackInfo{
+				{ID: 42, Title: "First Song", Artist: "Some Artist"},
+			},
+		},
+		"expired": {
+			ID:        "expired",
+			ExpiresAt: time.Now().Add(-time.Hour),
+			Tracks: []library.TrackInfo{
+				{ID: 42, Title: "First Song", Artist: "Some Artist"},
+			},
+		},
+	}
+
+	sharer := &sharesfakes.FakeSharer{
+		GetStub: func(_ context.Context, id string) (shares.Share, error) {
+			share, ok := sharesByID[id]
+			if !ok {
+				return shares.Share{}, shares.ErrNotFound
+			}
+			return share, nil
+		},
+	}
+	lib := &libraryfakes.FakeLibrary{
+		GetFilePathStub: func(_ context.Context, _ int64) string {
+			return trackFile
+		},
+	}
+
+	tpl := template.Must(template.New("share").Parse(
+		`{{.Share.Description}}{{range .Share.Tracks}}|{{.Title}}{{end}}`,
+	))
+
+	router := mux.NewRouter()
+	router.Handle("/share/{shareID}", webserver.NewShareHandler(sharer, tpl))
+	router.Handle(
+		"/share/{shareID}/file/{fileID}",
+		webserver.NewShareFileHandler(sharer, lib),
+	)
+
+	tests := []struct {
+		desc   string
+		url    string
+		status int
+		body   string
+	}{
+		{
+			desc:   "share page",
+			url:    "/share/active",
+			status: http.StatusOK,
+			body:   "Summer Songs|First Song",
+		},
+		{
+			desc:   "shared file",
+			url:    "/share/active/file/42",
+			status: http.StatusOK,
+			body:   trackContents,
+		},
+		{
+			desc:   "file which is not shared",
+			url:    "/share/active/file/43",
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "missing share",
+			url:    "/share/missing",
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "expired share",
+			url:    "/share/expired",
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "file from expired share",
+			url:    "/share/expired/file/42",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			assert.Equal(t, test.status, resp.StatusCode, "HTTP status code")
+			if test.body == "" {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NilErr(t, err, "reading response body")
+			if !strings.Contains(string(body), test.body) {
+				t.Errorf("expected body `%s` but got `%s`", test.body, body)
+			}
+		})
+	}
+
+	assert.Equal(t, 1, sharer.RecordVisitCallCount(), "recorded visits")
+	_, visitedID, _ := sharer.RecordVisitArgsForCall(0)
+	assert.Equal(t, "active", visitedID, "visited share")
+
+	assert.Equal(t, 1, lib.GetFilePathCallCount(), "served files")
+	_, servedID := lib.GetFilePathArgsForCall(0)
+	assert.Equal(t, int64(42), servedID, "served track")
+}
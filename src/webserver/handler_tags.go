@@ -0,0 +1,131 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// tagsHandler edits the meta data tags of a single track or of all tracks in an
+// album. It is used for both endpoints and tells them apart by their URL
+// variables.
+type tagsHandler struct {
+	editor  library.TagEditor
+	library library.Library
+	auth    config.Auth
+}
+
+// NewTagsHandler returns an HTTP handler for editing the tags of a track
+// identified by the "trackID" URL variable or of an album identified by the
+// "albumID" URL variable. The user from `auth` could edit only tracks from
+// music folders which they could access and only albums which have no tracks
+// in other music folders.
+func NewTagsHandler(
+	editor library.TagEditor,
+	lib library.Library,
+	auth config.Auth,
+) http.Handler {
+	return &tagsHandler{
+		editor:  editor,
+		library: lib,
+		auth:    auth,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *tagsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	vars := mux.Vars(req)
+	idVar, isAlbum := vars["albumID"]
+	if !isAlbum {
+		idVar = vars["trackID"]
+	}
+
+	id, err := strconv.ParseInt(idVar, 10, 64)
+	if err != nil {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := req.Context()
+	var accessible bool
+	if isAlbum {
+		accessible = onlyInAllowedMusicFolders(
+			ctx, h.library, h.auth, id, h.library.AlbumInMusicFolders,
+		)
+	} else {
+		accessible = canAccessMusicFolders(
+			ctx, h.library, h.auth, id, h.library.TrackInMusicFolders,
+		)
+	}
+	if !accessible {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var params tagsRequest
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&params); err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("cannot parse request body: %s", err),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	changes := library.TagChanges{
+		Title:       params.Title,
+		Artist:      params.Artist,
+		Album:       params.Album,
+		AlbumArtist: params.AlbumArtist,
+		Genre:       params.Genre,
+		Track:       params.Track,
+		Disc:        params.Disc,
+		Year:        params.Year,
+	}
+
+	if isAlbum {
+		err = h.editor.EditAlbumTags(ctx, id, changes)
+	} else {
+		err = h.editor.EditTrackTags(ctx, id, changes)
+	}
+
+	if errors.Is(err, library.ErrTrackNotFound) ||
+		errors.Is(err, library.ErrAlbumNotFound) {
+		webutils.JSONError(w, "not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, library.ErrInvalidTagChanges) ||
+		errors.Is(err, library.ErrTagsNotWritable) {
+		webutils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		webutils.JSONError(
+			w,
+			fmt.Sprintf("error editing tags: %s", err),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type tagsRequest struct {
+	Title       *string `json:"title"`
+	Artist      *string `json:"artist"`
+	Album       *string `json:"album"`
+	AlbumArtist *string `json:"album_artist"`
+	Genre       *string `json:"genre"`
+	Track       *int    `json:"track"`
+	Disc        *int    `json:"disc"`
+	Year        *int    `json:"year"`
+}
@@ -0,0 +1,234 @@
+package webserver_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestTagsHandler checks that the tags handler passes the changes to the tag
+// editor and converts its errors to the correct HTTP status codes.
+func TestTagsHandler(t *testing.T) {
+	var (
+		title = "New Title"
+		album = "New Album"
+		year  = 1999
+	)
+
+	tests := []struct {
+		desc      string
+		url       string
+		body      string
+		editorErr error
+
+		expectedCode    int
+		expectedTrack   bool
+		expectedAlbum   bool
+		expectedChanges library.TagChanges
+	}{
+		{
+			desc:            "edit track",
+			url:             "/v1/track/42/tags",
+			body:            `{"title": "New Title", "year": 1999}`,
+			expectedCode:    http.StatusNoContent,
+			expectedTrack:   true,
+			expectedChanges: library.TagChanges{Title: &title, Year: &year},
+		},
+		{
+			desc:            "edit album",
+			url:             "/v1/album/42/tags",
+			body:            `{"album": "New Album"}`,
+			expectedCode:    http.StatusNoContent,
+			expectedAlbum:   true,
+			expectedChanges: library.TagChanges{Album: &album},
+		},
+		{
+			desc:         "missing track",
+			url:          "/v1/track/43/tags",
+			body:         `{"title": "New Title"}`,
+			editorErr:    library.ErrTrackNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "missing album",
+			url:          "/v1/album/43/tags",
+			body:         `{"album": "New Album"}`,
+			editorErr:    library.ErrAlbumNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "invalid changes",
+			url:          "/v1/track/42/tags",
+			body:         `{"disc": -1}`,
+			editorErr:    fmt.Errorf("disc: %w", library.ErrInvalidTagChanges),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "not writable format",
+			url:          "/v1/track/42/tags",
+			body:         `{"title": "New Title"}`,
+			editorErr:    library.ErrTagsNotWritable,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed body",
+			url:          "/v1/track/42/tags",
+			body:         `{"title"`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed track ID",
+			url:          "/v1/track/baba/tags",
+			body:         `{"title": "New Title"}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "writing error",
+			url:          "/v1/album/42/tags",
+			body:         `{"album": "New Album"}`,
+			editorErr:    fmt.Errorf("disk full"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			editor := &libraryfakes.FakeTagEditor{
+				EditTrackTagsStub: func(context.Context, int64, library.TagChanges) error {
+					return test.editorErr
+				},
+				EditAlbumTagsStub: func(context.Context, int64, library.TagChanges) error {
+					return test.editorErr
+				},
+			}
+
+			handler := webserver.NewTagsHandler(
+				editor,
+				&libraryfakes.FakeLibrary{},
+				config.Auth{},
+			)
+			router := mux.NewRouter()
+			router.Handle(webserver.APIv1EndpointTrackTags, handler).Methods(
+				webserver.APIv1Methods[webserver.APIv1EndpointTrackTags]...,
+			)
+			router.Handle(webserver.APIv1EndpointAlbumTags, handler).Methods(
+				webserver.APIv1Methods[webserver.APIv1EndpointAlbumTags]...,
+			)
+
+			req := httptest.NewRequest(
+				http.MethodPatch,
+				test.url,
+				strings.NewReader(test.body),
+			)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+
+			if test.expectedTrack {
+				assert.Equal(t, 1, editor.EditTrackTagsCallCount(), "track edits")
+				_, trackID, changes := editor.EditTrackTagsArgsForCall(0)
+				assert.Equal(t, int64(42), trackID, "edited track ID")
+				if !reflect.DeepEqual(test.expectedChanges, changes) {
+					t.Errorf("track changes differ from the request body")
+				}
+			}
+			if test.expectedAlbum {
+				assert.Equal(t, 1, editor.EditAlbumTagsCallCount(), "album edits")
+				_, albumID, changes := editor.EditAlbumTagsArgsForCall(0)
+				assert.Equal(t, int64(42), albumID, "edited album ID")
+				if !reflect.DeepEqual(test.expectedChanges, changes) {
+					t.Errorf("album changes differ from the request body")
+				}
+			}
+		})
+	}
+}
+
+// TestTagsHandlerMusicFolders makes sure that users could not edit the tags of
+// tracks and albums outside of the music folders which they could access.
+func TestTagsHandlerMusicFolders(t *testing.T) {
+	lib := &libraryfakes.FakeLibrary{}
+	lib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "allowed", Path: "/music/allowed"},
+		{ID: 2, Name: "private", Path: "/music/private"},
+	}, nil)
+	lib.TrackInMusicFoldersStub = func(
+		_ context.Context,
+		trackID int64,
+		folderIDs []int64,
+	) (bool, error) {
+		return trackID == 5 && slices.Contains(folderIDs, 1), nil
+	}
+	lib.AlbumInMusicFoldersStub = func(
+		_ context.Context,
+		albumID int64,
+		folderIDs []int64,
+	) (bool, error) {
+		switch albumID {
+		case 7:
+			return slices.Contains(folderIDs, 1), nil
+		case 8:
+			// Album with tracks in both music folders.
+			return true, nil
+		default:
+			return slices.Contains(folderIDs, 2), nil
+		}
+	}
+
+	auth := config.Auth{User: "user", MusicFolders: []string{"allowed"}}
+
+	tests := []struct {
+		url          string
+		expectedCode int
+	}{
+		{url: "/v1/track/5/tags", expectedCode: http.StatusNoContent},
+		{url: "/v1/track/6/tags", expectedCode: http.StatusNotFound},
+		{url: "/v1/album/7/tags", expectedCode: http.StatusNoContent},
+		{url: "/v1/album/8/tags", expectedCode: http.StatusNotFound},
+		{url: "/v1/album/9/tags", expectedCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			editor := &libraryfakes.FakeTagEditor{}
+			handler := webserver.NewTagsHandler(editor, lib, auth)
+			router := mux.NewRouter()
+			router.Handle(webserver.APIv1EndpointTrackTags, handler).Methods(
+				webserver.APIv1Methods[webserver.APIv1EndpointTrackTags]...,
+			)
+			router.Handle(webserver.APIv1EndpointAlbumTags, handler).Methods(
+				webserver.APIv1Methods[webserver.APIv1EndpointAlbumTags]...,
+			)
+
+			req := httptest.NewRequest(
+				http.MethodPatch,
+				test.url,
+				strings.NewReader(`{"title": "New Title"}`),
+			)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+
+			edits := editor.EditTrackTagsCallCount() + editor.EditAlbumTagsCallCount()
+			if test.expectedCode == http.StatusNotFound {
+				assert.Equal(t, 0, edits, "tag edits")
+			} else {
+				assert.Equal(t, 1, edits, "tag edits")
+			}
+		})
+	}
+}
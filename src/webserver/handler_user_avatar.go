@@ -0,0 +1,129 @@
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// userAvatarHandler is a http.Handler which provides CRUD operations for the
+// avatars of users.
+//
+// Euterpe has a single user, the one from the authentication configuration. Requests
+// for any other username are answered with "not found".
+type userAvatarHandler struct {
+	avatars  library.AvatarManager
+	username string
+}
+
+// NewUserAvatarHandler returns a new handler for uploading, getting and removing
+// the avatar of the user with name `username`.
+func NewUserAvatarHandler(
+	avatars library.AvatarManager,
+	username string,
+) http.Handler {
+	return &userAvatarHandler{
+		avatars:  avatars,
+		username: username,
+	}
+}
+
+// ServeHTTP is required by the http.Handler's interface
+func (h *userAvatarHandler) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	username, err := url.PathUnescape(vars["username"])
+	if err != nil || username == "" || username != h.username {
+		http.NotFoundHandler().ServeHTTP(writer, req)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		err = h.remove(writer, req)
+	} else if req.Method == http.MethodPut {
+		err = h.upload(writer, req)
+	} else {
+		err = h.find(writer, req)
+	}
+
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		if _, err := writer.Write([]byte(err.Error())); err != nil {
+			log.Printf("error writing body in userAvatarHandler: %s", err)
+		}
+	}
+}
+
+func (h *userAvatarHandler) find(writer http.ResponseWriter, req *http.Request) error {
+	imgSize := library.OriginalImage
+	if req.URL.Query().Get("size") == "small" {
+		imgSize = library.SmallImage
+	}
+
+	imgReader, err := h.avatars.FindAvatar(req.Context(), h.username, imgSize)
+	if errors.Is(err, library.ErrArtworkNotFound) {
+		writer.WriteHeader(http.StatusNotFound)
+		if req.Method == http.MethodHead {
+			return nil
+		}
+
+		fmt.Fprintln(writer, "404 image not found")
+		return nil
+	}
+
+	if err != nil {
+		log.Printf("Error finding avatar for %s: %s\n", h.username, err)
+		return err
+	}
+
+	defer imgReader.Close()
+
+	writer.Header().Set("Cache-Control", "no-cache")
+	if req.Method == http.MethodHead {
+		n, _ := io.Copy(io.Discard, imgReader)
+		writer.Header().Set("Content-Length", strconv.FormatInt(n, 10))
+		return nil
+	}
+
+	_, err = io.Copy(writer, imgReader)
+	if err != nil {
+		log.Printf("error sending HTTP data for avatar of %s: %s", h.username, err)
+	}
+
+	return nil
+}
+
+func (h *userAvatarHandler) remove(writer http.ResponseWriter, req *http.Request) error {
+	if err := h.avatars.RemoveAvatar(req.Context(), h.username); err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *userAvatarHandler) upload(writer http.ResponseWriter, req *http.Request) error {
+	err := h.avatars.SaveAvatar(req.Context(), h.username, req.Body)
+	if err == library.ErrArtworkTooBig {
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = writer.Write([]byte("Uploaded avatar is too large."))
+		return nil
+	} else if _, ok := err.(*library.ArtworkError); ok {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+	return nil
+}
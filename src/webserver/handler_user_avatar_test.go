@@ -0,0 +1,157 @@
+package webserver_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver"
+)
+
+// TestUserAvatarHandler checks that the avatar of the configured user could be
+// uploaded, found and removed and that other users are not found.
+func TestUserAvatarHandler(t *testing.T) {
+	avatarOriginal := []byte("avatar original")
+	avatarSmall := []byte("avatar small")
+
+	fakeAM := &libraryfakes.FakeAvatarManager{
+		FindAvatarStub: func(
+			_ context.Context,
+			username string,
+			size library.ImageSize,
+		) (io.ReadCloser, error) {
+			if username != "test-user" {
+				return nil, library.ErrArtworkNotFound
+			}
+
+			if size == library.SmallImage {
+				return io.NopCloser(bytes.NewReader(avatarSmall)), nil
+			}
+
+			return io.NopCloser(bytes.NewReader(avatarOriginal)), nil
+		},
+		SaveAvatarStub: func(_ context.Context, _ string, r io.Reader) error {
+			body, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			switch string(body) {
+			case "too big":
+				return library.ErrArtworkTooBig
+			case "":
+				return library.NewArtworkError(io.ErrUnexpectedEOF)
+			}
+
+			return nil
+		},
+	}
+
+	router := mux.NewRouter()
+	router.UseEncodedPath()
+	router.Handle(
+		webserver.APIv1EndpointUserAvatar,
+		webserver.NewUserAvatarHandler(fakeAM, "test-user"),
+	).Methods(webserver.APIv1Methods[webserver.APIv1EndpointUserAvatar]...)
+
+	tests := []struct {
+		desc         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		expectedBody []byte
+	}{
+		{
+			desc:         "original avatar",
+			method:       http.MethodGet,
+			url:          "/v1/user/test-user/avatar",
+			expectedCode: http.StatusOK,
+			expectedBody: avatarOriginal,
+		},
+		{
+			desc:         "small avatar",
+			method:       http.MethodGet,
+			url:          "/v1/user/test-user/avatar?size=small",
+			expectedCode: http.StatusOK,
+			expectedBody: avatarSmall,
+		},
+		{
+			desc:         "other user",
+			method:       http.MethodGet,
+			url:          "/v1/user/other-user/avatar",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "upload to other user",
+			method:       http.MethodPut,
+			url:          "/v1/user/other-user/avatar",
+			body:         "avatar",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "upload",
+			method:       http.MethodPut,
+			url:          "/v1/user/test-user/avatar",
+			body:         "avatar",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			desc:         "upload too big",
+			method:       http.MethodPut,
+			url:          "/v1/user/test-user/avatar",
+			body:         "too big",
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:         "upload empty",
+			method:       http.MethodPut,
+			url:          "/v1/user/test-user/avatar",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "delete",
+			method:       http.MethodDelete,
+			url:          "/v1/user/test-user/avatar",
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				test.method,
+				test.url,
+				bytes.NewBufferString(test.body),
+			)
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != test.expectedCode {
+				t.Errorf("expected code %d but got %d", test.expectedCode, resp.Code)
+			}
+
+			if test.expectedBody != nil && !bytes.Equal(test.expectedBody, resp.Body.Bytes()) {
+				t.Errorf("expected body `%s` but got `%s`",
+					test.expectedBody, resp.Body.Bytes())
+			}
+		})
+	}
+
+	if calls := fakeAM.SaveAvatarCallCount(); calls != 3 {
+		t.Errorf("expected avatar to be saved 3 times but it was %d", calls)
+	}
+
+	if calls := fakeAM.RemoveAvatarCallCount(); calls != 1 {
+		t.Errorf("expected avatar to be removed once but it was %d times", calls)
+	}
+	if _, username := fakeAM.RemoveAvatarArgsForCall(0); username != "test-user" {
+		t.Errorf("expected avatar of `test-user` to be removed but was `%s`", username)
+	}
+}
@@ -0,0 +1,229 @@
+// Package loginattempts keeps track of failed login attempts and decides when
+// a client should not be allowed to try logging in again for a while. Attempts
+// are tracked for every client IP and username pair. Every client IP is tracked
+// on its own as well so that trying many different usernames gets locked out too.
+package loginattempts
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/config"
+)
+
+const (
+	// ipFailuresMultiplier is how many times more failures are allowed for a
+	// client IP regardless of the username than for an IP and username pair.
+	ipFailuresMultiplier = 3
+
+	// cleanUpInterval is how often entries which are forgotten are removed.
+	cleanUpInterval = time.Minute
+
+	// defaultMaxEntries is the maximum number of tracked entries. Usernames come
+	// from the clients so without a limit anyone could grow the tracker without
+	// bound by trying different names from different addresses.
+	defaultMaxEntries = 10000
+)
+
+// Tracker records failed login attempts and tells whether a new attempt is allowed.
+// It is safe for concurrent use. A nil *Tracker allows everything.
+type Tracker struct {
+	cfg config.LoginAttempts
+
+	mu          sync.Mutex
+	entries     map[string]*entry
+	maxEntries  int
+	lastCleanUp time.Time
+
+	// now returns the current time. Replaceable in tests.
+	now func() time.Time
+}
+
+type entry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// NewTracker returns a new Tracker which uses the limits in cfg.
+func NewTracker(cfg config.LoginAttempts) *Tracker {
+	return &Tracker{
+		cfg:        cfg,
+		entries:    make(map[string]*entry),
+		maxEntries: defaultMaxEntries,
+		now:        time.Now,
+	}
+}
+
+// Allowed checks whether a login attempt from the client IP for user is allowed
+// at the moment. When it is not the returned duration is how long the client has
+// to wait before trying again.
+func (t *Tracker) Allowed(ip, user string) (time.Duration, bool) {
+	if t == nil || t.cfg.Disable {
+		return 0, true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var wait time.Duration
+	for _, key := range []string{pairKey(ip, user), ipKey(ip)} {
+		e, ok := t.entries[key]
+		if !ok || !now.Before(e.blockedUntil) {
+			continue
+		}
+
+		if left := e.blockedUntil.Sub(now); left > wait {
+			wait = left
+		}
+	}
+
+	return wait, wait == 0
+}
+
+// Failed records a failed login attempt from the client IP for user. The failure
+// is logged in a format which is suitable for tools such as fail2ban:
+//
+//	Authentication failure for user "name" from 192.0.2.1 via method
+func (t *Tracker) Failed(ip, user, method string) {
+	log.Printf("Authentication failure for user %q from %s via %s", user, ip, method)
+
+	if t == nil || t.cfg.Disable {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.cleanUp(now)
+
+	t.fail(pairKey(ip, user), t.cfg.MaxFailures, true, now)
+	t.fail(ipKey(ip), t.cfg.MaxFailures*ipFailuresMultiplier, false, now)
+
+	if e := t.entries[pairKey(ip, user)]; e.failures == t.cfg.MaxFailures {
+		log.Printf(
+			"Authentication locked out for user %q from %s for %s",
+			user, ip, t.cfg.LockoutDuration,
+		)
+	}
+	if e := t.entries[ipKey(ip)]; e.failures == t.cfg.MaxFailures*ipFailuresMultiplier {
+		log.Printf(
+			"Authentication locked out for all users from %s for %s",
+			ip, t.cfg.LockoutDuration,
+		)
+	}
+}
+
+// Succeeded records a successful login from the client IP for user. All failures
+// recorded for them are forgotten.
+func (t *Tracker) Succeeded(ip, user string) {
+	if t == nil || t.cfg.Disable {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, pairKey(ip, user))
+	delete(t.entries, ipKey(ip))
+}
+
+// fail records a single failure for key. When withBackoff is false the key is
+// blocked only after reaching maxFailures. Must be called with t.mu held.
+func (t *Tracker) fail(key string, maxFailures int, withBackoff bool, now time.Time) {
+	e, ok := t.entries[key]
+	if !ok && len(t.entries) >= t.maxEntries {
+		t.evict(now)
+	}
+	if !ok || (!now.Before(e.blockedUntil) && now.Sub(e.lastFailure) >= t.cfg.ForgetAfter) {
+		e = &entry{}
+		t.entries[key] = e
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	if maxFailures > 0 && e.failures >= maxFailures {
+		e.blockedUntil = now.Add(t.cfg.LockoutDuration)
+		return
+	}
+
+	if withBackoff {
+		e.blockedUntil = now.Add(t.backoff(e.failures))
+	}
+}
+
+// backoff returns the time a client has to wait after its n-th failure.
+func (t *Tracker) backoff(n int) time.Duration {
+	backoff := t.cfg.InitialBackoff
+	for i := 1; i < n; i++ {
+		backoff *= 2
+		if t.cfg.MaxBackoff > 0 && backoff >= t.cfg.MaxBackoff {
+			return t.cfg.MaxBackoff
+		}
+	}
+
+	if t.cfg.MaxBackoff > 0 && backoff > t.cfg.MaxBackoff {
+		return t.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+// cleanUp removes entries which are no longer blocked and had no failures
+// recently. Must be called with t.mu held.
+func (t *Tracker) cleanUp(now time.Time) {
+	if now.Sub(t.lastCleanUp) < cleanUpInterval {
+		return
+	}
+	t.lastCleanUp = now
+
+	for key, e := range t.entries {
+		if now.Before(e.blockedUntil) {
+			continue
+		}
+
+		if now.Sub(e.lastFailure) >= t.cfg.ForgetAfter {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// evict makes room for a new entry. All forgotten entries are removed. When there
+// are none, the one with the oldest failure is removed. Entries which are blocked
+// at the moment are removed only when all of them are blocked. Must be called
+// with t.mu held.
+func (t *Tracker) evict(now time.Time) {
+	t.lastCleanUp = time.Time{}
+	t.cleanUp(now)
+	if len(t.entries) < t.maxEntries {
+		return
+	}
+
+	var (
+		oldestKey     string
+		oldest        *entry
+		oldestBlocked bool
+	)
+	for key, e := range t.entries {
+		blocked := now.Before(e.blockedUntil)
+		switch {
+		case oldest == nil,
+			oldestBlocked && !blocked,
+			oldestBlocked == blocked && e.lastFailure.Before(oldest.lastFailure):
+			oldestKey, oldest, oldestBlocked = key, e, blocked
+		}
+	}
+
+	delete(t.entries, oldestKey)
+}
+
+func pairKey(ip, user string) string {
+	return "pair\x00" + ip + "\x00" + user
+}
+
+func ipKey(ip string) string {
+	return "ip\x00" + ip
+}
@@ -0,0 +1,182 @@
+package loginattempts
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/config"
+)
+
+// TestTrackerBackoffAndLockout checks that failed attempts cause exponentially
+// growing waiting times and eventually a lockout.
+func TestTrackerBackoffAndLockout(t *testing.T) {
+	const (
+		ip   = "192.0.2.1"
+		user = "user"
+	)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTracker(config.LoginAttempts{
+		MaxFailures:     4,
+		InitialBackoff:  time.Second,
+		MaxBackoff:      3 * time.Second,
+		LockoutDuration: time.Hour,
+		ForgetAfter:     24 * time.Hour,
+	})
+	tracker.now = func() time.Time { return now }
+
+	if _, ok := tracker.Allowed(ip, user); !ok {
+		t.Fatalf("expected attempt to be allowed before any failures")
+	}
+
+	expectedWaits := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		3 * time.Second,
+		time.Hour,
+	}
+
+	for i, expected := range expectedWaits {
+		tracker.Failed(ip, user, "test")
+
+		wait, ok := tracker.Allowed(ip, user)
+		if ok {
+			t.Fatalf("failure %d: expected attempt not to be allowed", i+1)
+		}
+		if wait != expected {
+			t.Errorf("failure %d: expected wait %s but got %s", i+1, expected, wait)
+		}
+
+		if _, ok := tracker.Allowed(ip, "other-user"); !ok {
+			t.Errorf("failure %d: expected other users to be allowed", i+1)
+		}
+
+		if _, ok := tracker.Allowed("192.0.2.2", user); !ok {
+			t.Errorf("failure %d: expected other IPs to be allowed", i+1)
+		}
+
+		now = now.Add(wait)
+	}
+
+	if _, ok := tracker.Allowed(ip, user); !ok {
+		t.Errorf("expected attempt to be allowed after the lockout")
+	}
+}
+
+// TestTrackerIPLockout makes sure that trying many different usernames from
+// the same IP is limited as well.
+func TestTrackerIPLockout(t *testing.T) {
+	const ip = "192.0.2.1"
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTracker(config.LoginAttempts{
+		MaxFailures:     2,
+		InitialBackoff:  time.Millisecond,
+		LockoutDuration: time.Hour,
+		ForgetAfter:     24 * time.Hour,
+	})
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 2*ipFailuresMultiplier; i++ {
+		tracker.Failed(ip, string(rune('a'+i)), "test")
+		now = now.Add(time.Second)
+	}
+
+	if _, ok := tracker.Allowed(ip, "brand-new-user"); ok {
+		t.Errorf("expected IP to be locked out for all users")
+	}
+}
+
+// TestTrackerSucceededAndForget checks that failures are forgotten after a
+// successful login or after enough time has passed.
+func TestTrackerSucceededAndForget(t *testing.T) {
+	const (
+		ip   = "192.0.2.1"
+		user = "user"
+	)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTracker(config.LoginAttempts{
+		MaxFailures:     2,
+		InitialBackoff:  time.Second,
+		LockoutDuration: time.Hour,
+		ForgetAfter:     time.Minute,
+	})
+	tracker.now = func() time.Time { return now }
+
+	tracker.Failed(ip, user, "test")
+	now = now.Add(time.Second)
+	tracker.Succeeded(ip, user)
+	tracker.Failed(ip, user, "test")
+
+	if wait, _ := tracker.Allowed(ip, user); wait != time.Second {
+		t.Errorf("expected failures to be reset on success, got wait %s", wait)
+	}
+
+	now = now.Add(2 * time.Minute)
+	tracker.Failed(ip, user, "test")
+
+	if wait, _ := tracker.Allowed(ip, user); wait != time.Second {
+		t.Errorf("expected old failures to be forgotten, got wait %s", wait)
+	}
+}
+
+// TestTrackerMaxEntries makes sure the number of tracked entries is limited and
+// that entries which are blocked are kept in favour of the rest.
+func TestTrackerMaxEntries(t *testing.T) {
+	const (
+		blockedIP   = "192.0.2.1"
+		blockedUser = "blocked"
+	)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTracker(config.LoginAttempts{
+		MaxFailures:     2,
+		InitialBackoff:  time.Millisecond,
+		LockoutDuration: time.Hour,
+		ForgetAfter:     24 * time.Hour,
+	})
+	tracker.maxEntries = 4
+	tracker.now = func() time.Time { return now }
+
+	tracker.Failed(blockedIP, blockedUser, "test")
+	tracker.Failed(blockedIP, blockedUser, "test")
+
+	for i := 0; i < 50; i++ {
+		now = now.Add(time.Second)
+		tracker.Failed(
+			fmt.Sprintf("198.51.100.%d", i),
+			fmt.Sprintf("user-%d", i),
+			"test",
+		)
+
+		if len(tracker.entries) > tracker.maxEntries {
+			t.Fatalf("expected at most %d entries but got %d",
+				tracker.maxEntries, len(tracker.entries))
+		}
+	}
+
+	if _, ok := tracker.Allowed(blockedIP, blockedUser); ok {
+		t.Errorf("expected the blocked user to stay blocked")
+	}
+}
+
+// TestTrackerDisabled makes sure disabled and nil trackers allow everything.
+func TestTrackerDisabled(t *testing.T) {
+	var nilTracker *Tracker
+	nilTracker.Failed("192.0.2.1", "user", "test")
+	if _, ok := nilTracker.Allowed("192.0.2.1", "user"); !ok {
+		t.Errorf("expected nil tracker to allow attempts")
+	}
+
+	tracker := NewTracker(config.LoginAttempts{
+		Disable:         true,
+		MaxFailures:     1,
+		LockoutDuration: time.Hour,
+	})
+	tracker.Failed("192.0.2.1", "user", "test")
+	if _, ok := tracker.Allowed("192.0.2.1", "user"); !ok {
+		t.Errorf("expected disabled tracker to allow attempts")
+	}
+}
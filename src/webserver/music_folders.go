@@ -0,0 +1,147 @@
+package webserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// noMusicFolderID is used for filtering when the user is not allowed to access
+// any of the existing music folders. No music folder has this ID.
+const noMusicFolderID int64 = -1
+
+// inMusicFoldersFunc is one of the library methods which check whether an item
+// is in any of the music folders with IDs `folderIDs`.
+type inMusicFoldersFunc func(ctx context.Context, id int64, folderIDs []int64) (bool, error)
+
+// allowedMusicFolderIDs returns the IDs of the music folders which the user from
+// `auth` is allowed to access. They are meant for the MusicFolderIDs filters of
+// the library. An empty list is returned when the user is not limited to any
+// music folders and no filtering is necessary.
+func allowedMusicFolderIDs(
+	ctx context.Context,
+	lib library.Library,
+	auth config.Auth,
+) ([]int64, error) {
+	if len(auth.MusicFolders) == 0 {
+		return nil, nil
+	}
+
+	folders, err := lib.MusicFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting music folders: %w", err)
+	}
+
+	allowed := []int64{noMusicFolderID}
+	for _, folder := range folders {
+		if auth.CanAccessMusicFolder(folder.Name, folder.Path) {
+			allowed = append(allowed, folder.ID)
+		}
+	}
+
+	return allowed, nil
+}
+
+// canAccessMusicFolders returns true when the library item with `id` is in a
+// music folder which the user from `auth` is allowed to access. Whether the
+// item is in particular music folders is checked with `inMusicFolders`.
+func canAccessMusicFolders(
+	ctx context.Context,
+	lib library.Library,
+	auth config.Auth,
+	id int64,
+	inMusicFolders inMusicFoldersFunc,
+) bool {
+	if len(auth.MusicFolders) == 0 {
+		return true
+	}
+
+	allowed, err := allowedMusicFolderIDs(ctx, lib, auth)
+	if err != nil {
+		log.Printf("error checking music folders of %d: %s", id, err)
+		return false
+	}
+
+	found, err := inMusicFolders(ctx, id, allowed)
+	if err != nil {
+		log.Printf("error checking music folders of %d: %s", id, err)
+		return false
+	}
+
+	return found
+}
+
+// onlyInAllowedMusicFolders returns true when the library item with `id` is in
+// music folders which the user from `auth` is allowed to access and in no other
+// music folders. It is stricter than canAccessMusicFolders and is meant for
+// changing items such as albums which could have tracks in many music folders.
+func onlyInAllowedMusicFolders(
+	ctx context.Context,
+	lib library.Library,
+	auth config.Auth,
+	id int64,
+	inMusicFolders inMusicFoldersFunc,
+) bool {
+	if len(auth.MusicFolders) == 0 {
+		return true
+	}
+
+	folders, err := lib.MusicFolders(ctx)
+	if err != nil {
+		log.Printf("error getting music folders: %s", err)
+		return false
+	}
+
+	allowed := []int64{noMusicFolderID}
+	var forbidden []int64
+	for _, folder := range folders {
+		if auth.CanAccessMusicFolder(folder.Name, folder.Path) {
+			allowed = append(allowed, folder.ID)
+		} else {
+			forbidden = append(forbidden, folder.ID)
+		}
+	}
+
+	found, err := inMusicFolders(ctx, id, allowed)
+	if err != nil {
+		log.Printf("error checking music folders of %d: %s", id, err)
+		return false
+	}
+	if !found {
+		return false
+	}
+
+	if len(forbidden) == 0 {
+		return true
+	}
+
+	found, err = inMusicFolders(ctx, id, forbidden)
+	if err != nil {
+		log.Printf("error checking music folders of %d: %s", id, err)
+		return false
+	}
+
+	return !found
+}
+
+// accessibleTracks returns the tracks from `tracks` which are in the music
+// folders with `allowedIDs`. All tracks are accessible when `allowedIDs` is
+// empty. Entries for tracks which are missing from the library are kept.
+func accessibleTracks(tracks []library.TrackInfo, allowedIDs []int64) []library.TrackInfo {
+	if len(allowedIDs) == 0 {
+		return tracks
+	}
+
+	accessible := make([]library.TrackInfo, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Missing || slices.Contains(allowedIDs, track.MusicFolderID) {
+			accessible = append(accessible, track)
+		}
+	}
+
+	return accessible
+}
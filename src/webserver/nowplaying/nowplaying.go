@@ -0,0 +1,153 @@
+// Package nowplaying keeps track of what is being listened to at the moment. Every
+// player is identified by the user, the client application and the device which
+// it runs on. Entries are forgotten once the track they are for should have ended.
+package nowplaying
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+const (
+	// expiryGrace is added to the duration of a track when calculating when
+	// an entry expires. It accounts for pauses and for buffering.
+	expiryGrace = time.Minute
+
+	// unknownDuration is used in place of the duration of tracks for which it
+	// is not known.
+	unknownDuration = 5 * time.Minute
+)
+
+// Entry is a single track which is being played at the moment.
+type Entry struct {
+	// User is the name of the user who is listening.
+	User string
+
+	// Client is the name of the application used for playing the track.
+	Client string
+
+	// Device identifies the device on which the client runs. This is its
+	// IP address.
+	Device string
+
+	// PlayerID is a number which is unique for every user, client and device.
+	// It does not change for the life time of the Registry.
+	PlayerID int64
+
+	// Track is the track being played.
+	Track library.TrackInfo
+
+	// StartedAt is the time at which the track has started playing.
+	StartedAt time.Time
+
+	// ExpiresAt is the time after which the entry is forgotten.
+	ExpiresAt time.Time
+}
+
+// Registry stores the now playing entries. It is safe for concurrent use. A nil
+// *Registry ignores all tracks and has no entries.
+type Registry struct {
+	mu        sync.Mutex
+	entries   map[playerKey]Entry
+	playerIDs map[playerKey]int64
+
+	// now returns the current time. Replaceable in tests.
+	now func() time.Time
+}
+
+type playerKey struct {
+	user   string
+	client string
+	device string
+}
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:   make(map[playerKey]Entry),
+		playerIDs: make(map[playerKey]int64),
+		now:       time.Now,
+	}
+}
+
+// Playing records that user is listening to track with the client application on
+// device. It replaces whatever was playing on this player before. Recording the
+// same track again while it is still playing does not restart it. This way many
+// requests for the same track, such as HTTP range requests, do not change
+// its start time.
+func (r *Registry) Playing(user, client, device string, track library.TrackInfo) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.cleanUp(now)
+
+	key := playerKey{user: user, client: client, device: device}
+	if e, ok := r.entries[key]; ok && e.Track.ID == track.ID {
+		return
+	}
+
+	playerID, ok := r.playerIDs[key]
+	if !ok {
+		playerID = int64(len(r.playerIDs) + 1)
+		r.playerIDs[key] = playerID
+	}
+
+	duration := time.Duration(track.Duration) * time.Millisecond
+	if duration <= 0 {
+		duration = unknownDuration
+	}
+
+	r.entries[key] = Entry{
+		User:      user,
+		Client:    client,
+		Device:    device,
+		PlayerID:  playerID,
+		Track:     track,
+		StartedAt: now,
+		ExpiresAt: now.Add(duration + expiryGrace),
+	}
+}
+
+// Entries returns all tracks which are being played at the moment. The most
+// recently started tracks are first.
+func (r *Registry) Entries() []Entry {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cleanUp(r.now())
+
+	entries := make([]Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return int(a.PlayerID - b.PlayerID)
+	})
+
+	return entries
+}
+
+// cleanUp removes all entries which have expired. Must be called with r.mu held.
+func (r *Registry) cleanUp(now time.Time) {
+	for key, e := range r.entries {
+		if !now.Before(e.ExpiresAt) {
+			delete(r.entries, key)
+		}
+	}
+}
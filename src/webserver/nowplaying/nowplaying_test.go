@@ -0,0 +1,72 @@
+package nowplaying
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// TestRegistryEntries checks that entries are kept per player, that they expire
+// after their track should have ended and that players keep their IDs.
+func TestRegistryEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	registry := NewRegistry()
+	registry.now = func() time.Time { return now }
+
+	short := library.TrackInfo{ID: 1, Duration: 2 * 60 * 1000}
+	long := library.TrackInfo{ID: 2, Duration: 10 * 60 * 1000}
+	unknown := library.TrackInfo{ID: 3}
+
+	registry.Playing("user", "phone-app", "192.0.2.1", short)
+	now = now.Add(time.Minute)
+	registry.Playing("user", "web", "192.0.2.1", long)
+
+	entries := registry.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries but got %d", len(entries))
+	}
+	if entries[0].Track.ID != long.ID || entries[1].Track.ID != short.ID {
+		t.Errorf("expected the most recently started track to be first")
+	}
+	if entries[0].PlayerID == entries[1].PlayerID {
+		t.Errorf("expected different players to have different IDs")
+	}
+	phoneID := entries[1].PlayerID
+
+	now = now.Add(30 * time.Second)
+	registry.Playing("user", "phone-app", "192.0.2.1", short)
+	entries = registry.Entries()
+	if started := entries[1].StartedAt; !started.Equal(now.Add(-90 * time.Second)) {
+		t.Errorf("expected playing the same track not to restart it, started %s", started)
+	}
+
+	now = now.Add(2 * time.Minute)
+	entries = registry.Entries()
+	if len(entries) != 1 || entries[0].Track.ID != long.ID {
+		t.Fatalf("expected only the long track to be playing but got %+v", entries)
+	}
+
+	registry.Playing("user", "phone-app", "192.0.2.1", unknown)
+	entries = registry.Entries()
+	if entries[0].PlayerID != phoneID {
+		t.Errorf("expected player ID %d but got %d", phoneID, entries[0].PlayerID)
+	}
+	if expected := now.Add(unknownDuration + expiryGrace); !entries[0].ExpiresAt.Equal(expected) {
+		t.Errorf("expected expiry at %s but got %s", expected, entries[0].ExpiresAt)
+	}
+
+	now = now.Add(time.Hour)
+	if entries := registry.Entries(); len(entries) != 0 {
+		t.Errorf("expected all entries to have expired but got %+v", entries)
+	}
+}
+
+// TestNilRegistry makes sure that a nil *Registry could be used.
+func TestNilRegistry(t *testing.T) {
+	var registry *Registry
+	registry.Playing("user", "client", "device", library.TrackInfo{ID: 1})
+	if entries := registry.Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries but got %+v", entries)
+	}
+}
@@ -1,12 +1,16 @@
 package subsonic
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 func (s *subsonic) authHandler(handler http.Handler) http.Handler {
@@ -15,11 +19,11 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 		r *http.Request,
 	) {
 		if err := r.ParseForm(); err != nil {
-			http.Error(
-				w,
+			resp := responseError(
+				errCodeGeneric,
 				fmt.Sprintf("cannot parse request: %s", err),
-				http.StatusBadRequest,
 			)
+			encodeResponse(w, r, resp)
 			return
 		}
 
@@ -28,6 +32,26 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 			return
 		}
 
+		// The proxy authenticates browsers with their cookies or similar. So any
+		// page could include a JSONP response as a script and read it in the name
+		// of the logged in user. That is why JSONP is served only for requests
+		// with credentials of their own.
+		proxyUser, ok := webutils.ProxyAuthUser(r, s.auth)
+		if ok && r.Form.Get("f") != formatJSONP {
+			if proxyUser != s.auth.User {
+				resp := responseError(
+					errCodeWrongUserOrPass,
+					"Wrong username or password",
+				)
+
+				encodeResponse(w, r, resp)
+				return
+			}
+
+			handler.ServeHTTP(w, withRequestUser(r, proxyUser))
+			return
+		}
+
 		user := r.Form.Get("u")
 		pass := r.Form.Get("p")
 		token := r.Form.Get("t")
@@ -39,7 +63,18 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 				"Required parameter is missing",
 			)
 
-			w.WriteHeader(http.StatusUnauthorized)
+			encodeResponse(w, r, resp)
+			return
+		}
+
+		clientIP := webutils.ClientIP(r, s.trustedProxies)
+		if wait, ok := s.loginAttempts.Allowed(clientIP, user); !ok {
+			resp := responseError(
+				errCodeGeneric,
+				"Too many failed login attempts, try again later",
+			)
+
+			webutils.SetRetryAfter(w, wait)
 			encodeResponse(w, r, resp)
 			return
 		}
@@ -51,6 +86,7 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 				pass = strings.TrimPrefix(pass, "enc:")
 				decPass, err := hex.DecodeString(pass)
 				if err != nil {
+					s.loginAttempts.Failed(clientIP, user, "subsonic")
 					resp := responseError(
 						errCodeWrongUserOrPass,
 						fmt.Sprintf(
@@ -59,7 +95,6 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 						),
 					)
 
-					w.WriteHeader(http.StatusUnauthorized)
 					encodeResponse(w, r, resp)
 					return
 				}
@@ -67,15 +102,29 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 				pass = string(decPass)
 			}
 
-			userCheck := subtle.ConstantTimeCompare([]byte(user), []byte(s.auth.User))
-			passCheck := subtle.ConstantTimeCompare([]byte(pass), []byte(s.auth.Password))
+			authSuccess = s.auth.CheckCredentials(user, pass)
+		} else {
+			if !s.auth.SubsonicTokenAuth {
+				resp := responseError(
+					errCodeTokenAuthLDAP,
+					"Token authentication not supported",
+				)
+
+				encodeResponse(w, r, resp)
+				return
+			}
+
+			password, err := s.auth.SubsonicPassword()
+			if err != nil {
+				log.Printf("Cannot get the Subsonic token password: %s", err)
+				resp := responseError(errCodeGeneric, "Token authentication failed")
 
-			if userCheck&passCheck == 1 {
-				authSuccess = true
+				encodeResponse(w, r, resp)
+				return
 			}
-		} else {
+
 			correctToken := md5.New()
-			_, _ = fmt.Fprintf(correctToken, "%s%s", s.auth.Password, salt)
+			_, _ = fmt.Fprintf(correctToken, "%s%s", password, salt)
 			correctTokenHex := hex.EncodeToString(correctToken.Sum(nil))
 
 			userCheck := subtle.ConstantTimeCompare([]byte(user), []byte(s.auth.User))
@@ -88,16 +137,35 @@ func (s *subsonic) authHandler(handler http.Handler) http.Handler {
 		}
 
 		if !authSuccess {
+			s.loginAttempts.Failed(clientIP, user, "subsonic")
 			resp := responseError(
 				errCodeWrongUserOrPass,
 				"Wrong username or password",
 			)
 
-			w.WriteHeader(http.StatusUnauthorized)
 			encodeResponse(w, r, resp)
 			return
 		}
 
-		handler.ServeHTTP(w, r)
+		s.loginAttempts.Succeeded(clientIP, user)
+		handler.ServeHTTP(w, withRequestUser(r, user))
 	})
 }
+
+// requestUserKey is the context key under which the authenticated user of
+// a request is stored.
+type requestUserKey struct{}
+
+// withRequestUser returns a copy of r with `user` stored as its authenticated user.
+func withRequestUser(r *http.Request, user string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user))
+}
+
+// requestUser returns the user who made the request. When authentication is
+// disabled it is the configured user.
+func (s *subsonic) requestUser(req *http.Request) string {
+	if user, ok := req.Context().Value(requestUserKey{}).(string); ok {
+		return user
+	}
+	return s.auth.User
+}
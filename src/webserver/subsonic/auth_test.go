@@ -5,15 +5,19 @@ import (
 	"encoding/hex"
 	"encoding/xml"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
 	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
 )
@@ -31,9 +35,30 @@ func TestAuthHandler(t *testing.T) {
 	fmt.Fprintf(tokenMD5, "%s%s", password, salt)
 	token := hex.EncodeToString(tokenMD5.Sum(nil))
 
+	emptyMD5 := md5.New()
+	fmt.Fprintf(emptyMD5, "%s", salt)
+	emptyPasswordToken := hex.EncodeToString(emptyMD5.Sum(nil))
+
+	const secret = "some-secret"
+
+	passwordHash, err := config.HashPassword(password)
+	if err != nil {
+		t.Fatalf("cannot hash password: %s", err)
+	}
+
+	subsonicSecret, err := config.EncryptSubsonicSecret(secret, password)
+	if err != nil {
+		t.Fatalf("cannot encrypt subsonic secret: %s", err)
+	}
+
 	tests := []struct {
 		Desc         string
 		SkipAuth     bool
+		Hashed       bool
+		NoSecret     bool
+		TokenAuth    bool
+		ProxyAuth    bool
+		Headers      map[string]string
 		Query        map[string]string
 		Success      bool
 		ExpectedCode int
@@ -60,14 +85,83 @@ func TestAuthHandler(t *testing.T) {
 			Success: true,
 		},
 		{
-			Desc: "with salt and token",
+			Desc:   "with hashed password",
+			Hashed: true,
+			Query: map[string]string{
+				"u": username,
+				"p": password,
+			},
+			Success: true,
+		},
+		{
+			Desc:      "with salt and token",
+			TokenAuth: true,
+			Query: map[string]string{
+				"u": username,
+				"s": salt,
+				"t": token,
+			},
+			Success: true,
+		},
+		{
+			Desc:      "with salt and token and encrypted secret",
+			Hashed:    true,
+			TokenAuth: true,
+			Query: map[string]string{
+				"u": username,
+				"s": salt,
+				"t": token,
+			},
+			Success: true,
+		},
+		{
+			Desc:      "token for empty password without encrypted secret",
+			Hashed:    true,
+			NoSecret:  true,
+			TokenAuth: true,
+			Query: map[string]string{
+				"u": username,
+				"s": salt,
+				"t": emptyPasswordToken,
+			},
+			Success:      false,
+			ExpectedCode: 0,
+		},
+		{
+			Desc: "salt and token when token auth is disabled",
 			Query: map[string]string{
 				"u": username,
 				"s": salt,
 				"t": token,
 			},
+			Success:      false,
+			ExpectedCode: 41,
+		},
+		{
+			Desc:      "with header from trusted proxy",
+			ProxyAuth: true,
+			Headers: map[string]string{
+				"Remote-User": username,
+			},
 			Success: true,
 		},
+		{
+			Desc:      "with header from trusted proxy for wrong user",
+			ProxyAuth: true,
+			Headers: map[string]string{
+				"Remote-User": "wrong-username",
+			},
+			Success:      false,
+			ExpectedCode: 40,
+		},
+		{
+			Desc: "with header from untrusted source",
+			Headers: map[string]string{
+				"Remote-User": username,
+			},
+			Success:      false,
+			ExpectedCode: 10,
+		},
 		{
 			Desc: "missing username",
 			Query: map[string]string{
@@ -126,7 +220,18 @@ func TestAuthHandler(t *testing.T) {
 			ExpectedCode: 40,
 		},
 		{
-			Desc: "wrong token for salt",
+			Desc:   "wrong hashed password",
+			Hashed: true,
+			Query: map[string]string{
+				"u": username,
+				"p": "wrong-password",
+			},
+			Success:      false,
+			ExpectedCode: 40,
+		},
+		{
+			Desc:      "wrong token for salt",
+			TokenAuth: true,
 			Query: map[string]string{
 				"u": username,
 				"s": salt,
@@ -136,7 +241,8 @@ func TestAuthHandler(t *testing.T) {
 			ExpectedCode: 40,
 		},
 		{
-			Desc: "wrong username with token and salt",
+			Desc:      "wrong username with token and salt",
+			TokenAuth: true,
 			Query: map[string]string{
 				"u": "wrong-username",
 				"s": salt,
@@ -174,9 +280,11 @@ func TestAuthHandler(t *testing.T) {
 				t.Fatalf("failed to decode XML response: %s", err)
 			}
 
-			if resp.StatusCode != http.StatusUnauthorized {
+			// Subsonic clients expect API errors with HTTP status 200 just
+			// like successful responses.
+			if resp.StatusCode != http.StatusOK {
 				t.Errorf(
-					"expected HTTP status for unauthorized but got %d",
+					"expected HTTP status OK for API error but got %d",
 					resp.StatusCode,
 				)
 			}
@@ -198,20 +306,41 @@ func TestAuthHandler(t *testing.T) {
 			cfg := config.Config{
 				Auth: !test.SkipAuth,
 				Authenticate: config.Auth{
-					User:     username,
-					Password: password,
+					User:              username,
+					Password:          password,
+					Secret:            secret,
+					SubsonicTokenAuth: test.TokenAuth,
+					ProxyHeader:       "Remote-User",
 				},
 			}
 
+			if test.ProxyAuth {
+				_, localhost, err := net.ParseCIDR("127.0.0.0/8")
+				if err != nil {
+					t.Fatalf("cannot parse network: %s", err)
+				}
+				cfg.Authenticate.ProxyNetworks = config.CIDRList{localhost}
+			}
+
+			if test.Hashed {
+				cfg.Authenticate.Password = ""
+				cfg.Authenticate.PasswordHash = passwordHash
+				if !test.NoSecret {
+					cfg.Authenticate.SubsonicSecret = subsonicSecret
+				}
+			}
+
 			sh := subsonic.NewHandler(
 				subsonic.Prefix,
-				&libraryfakes.FakeLibrary{},
-				&libraryfakes.FakeBrowser{},
-				&radiofakes.FakeStations{},
-				&playlistsfakes.FakePlaylister{},
 				cfg,
-				&subsonicfakes.FakeCoverArtHandler{},
-				&subsonicfakes.FakeCoverArtHandler{},
+				subsonic.Deps{
+					Library:    &libraryfakes.FakeLibrary{},
+					Browser:    &libraryfakes.FakeBrowser{},
+					Stations:   &radiofakes.FakeStations{},
+					Playlister: &playlistsfakes.FakePlaylister{},
+					AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+				},
 			)
 
 			srv := httptest.NewServer(sh)
@@ -239,6 +368,9 @@ func TestAuthHandler(t *testing.T) {
 			if err != nil {
 				t.Fatalf("cannot create request: %s", err)
 			}
+			for hk, hv := range test.Headers {
+				req.Header.Set(hk, hv)
+			}
 
 			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
@@ -255,6 +387,147 @@ func TestAuthHandler(t *testing.T) {
 	}
 }
 
+// TestProxyAuthJSONP checks that JSONP responses are not served for requests
+// authenticated only with the reverse proxy header.
+func TestProxyAuthJSONP(t *testing.T) {
+	_, proxyNet, err := net.ParseCIDR("192.0.2.0/24")
+	if err != nil {
+		t.Fatalf("cannot parse network: %s", err)
+	}
+
+	sh := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Auth: true,
+			Authenticate: config.Auth{
+				User:          "the-real-user",
+				Password:      "the-real-password",
+				ProxyHeader:   "Remote-User",
+				ProxyNetworks: config.CIDRList{proxyNet},
+			},
+		},
+		subsonic.Deps{
+			Library:   &libraryfakes.FakeLibrary{},
+			Browser:   &libraryfakes.FakeBrowser{},
+			AlbumArt:  &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt: &subsonicfakes.FakeCoverArtHandler{},
+		},
+	)
+
+	tests := []struct {
+		desc     string
+		query    string
+		expected string
+	}{
+		{
+			desc:     "JSON with proxy header",
+			query:    "f=json",
+			expected: `"status": "ok"`,
+		},
+		{
+			desc:     "JSONP with proxy header",
+			query:    "f=jsonp&callback=cb",
+			expected: `"code": 10`,
+		},
+		{
+			desc:     "JSONP with proxy header and credentials",
+			query:    "f=jsonp&callback=cb&u=the-real-user&p=the-real-password",
+			expected: `"status": "ok"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodGet,
+				subsonic.Prefix+"/getLicense?"+test.query,
+				nil,
+			)
+			req.RemoteAddr = "192.0.2.10:34567"
+			req.Header.Set("Remote-User", "the-real-user")
+
+			rec := httptest.NewRecorder()
+			sh.ServeHTTP(rec, req)
+
+			body := rec.Body.String()
+			if !strings.Contains(body, test.expected) {
+				t.Errorf("expected `%s` in the response but got:\n%s", test.expected, body)
+			}
+		})
+	}
+}
+
+// TestAuthLockoutBeforePasswordCheck makes sure that clients which are locked out
+// are refused before their password is checked. Even the correct password is not
+// accepted while locked out.
+func TestAuthLockoutBeforePasswordCheck(t *testing.T) {
+	const (
+		username = "the-real-user"
+		password = "the-real-password"
+	)
+
+	passwordHash, err := config.HashPassword(password)
+	if err != nil {
+		t.Fatalf("cannot hash password: %s", err)
+	}
+
+	sh := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Auth: true,
+			Authenticate: config.Auth{
+				User:         username,
+				PasswordHash: passwordHash,
+			},
+		},
+		subsonic.Deps{
+			Library:   &libraryfakes.FakeLibrary{},
+			Browser:   &libraryfakes.FakeBrowser{},
+			AlbumArt:  &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt: &subsonicfakes.FakeCoverArtHandler{},
+			LoginAttempts: loginattempts.NewTracker(config.LoginAttempts{
+				MaxFailures:     1,
+				LockoutDuration: time.Hour,
+				ForgetAfter:     time.Hour,
+			}),
+		},
+	)
+
+	ping := func(pass string) errorResponse {
+		query := url.Values{
+			"u": {username},
+			"p": {pass},
+			"v": {"1.16.1"},
+			"c": {"test"},
+		}
+		req := httptest.NewRequest(
+			http.MethodGet,
+			subsonic.Prefix+"/ping?"+query.Encode(),
+			nil,
+		)
+		rec := httptest.NewRecorder()
+		sh.ServeHTTP(rec, req)
+
+		var resp errorResponse
+		if err := xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("cannot decode response: %s", err)
+		}
+		return resp
+	}
+
+	if resp := ping("wrong-password"); resp.Error.Code != 40 {
+		t.Fatalf("expected wrong password error code 40 but got %d", resp.Error.Code)
+	}
+
+	resp := ping(password)
+	if resp.Status != "failed" {
+		t.Fatalf("expected the locked out client to be refused")
+	}
+	if !strings.Contains(resp.Error.Message, "Too many failed login attempts") {
+		t.Errorf("expected a lockout error but got `%s`", resp.Error.Message)
+	}
+}
+
 type baseResponse struct {
 	XMLName xml.Name `xml:"subsonic-response"`
 	Status  string   `xml:"status,attr"`
@@ -4,22 +4,47 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"log"
 	"net/http"
+	"regexp"
 
 	"github.com/ironsmile/euterpe/src/version"
 )
 
+// Response formats which clients may request with the `f` query parameter.
+const (
+	formatXML   = "xml"
+	formatJSON  = "json"
+	formatJSONP = "jsonp"
+)
+
+// maxJSONPCallbackLen is the maximum length of a JSONP callback name.
+const maxJSONPCallbackLen = 128
+
+// jsonpCallbackRegexp matches the callback names which are safe to use in JSONP
+// responses. These are JavaScript identifiers, possibly joined with dots.
+var jsonpCallbackRegexp = regexp.MustCompile(
+	`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`,
+)
+
+// encodeResponse writes `resp` in the format requested with the `f` parameter.
+// Unknown formats fall back to XML. Just like the reference Subsonic server
+// API errors are returned with HTTP status 200 and only the response body
+// signals them. So handlers must not change the status code before calling
+// this function.
 func encodeResponse(w http.ResponseWriter, req *http.Request, resp any) {
-	if req.Form.Get("f") == "json" {
+	switch req.Form.Get("f") {
+	case formatJSON:
 		encodeResponseJSON(w, req, resp)
-		return
+	case formatJSONP:
+		encodeResponseJSONP(w, req, resp)
+	default:
+		encodeResponseXML(w, req, resp)
 	}
-
-	encodeResponseXML(w, req, resp)
 }
 
 func encodeResponseJSON(w http.ResponseWriter, _ *http.Request, resp any) {
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
@@ -31,17 +56,58 @@ func encodeResponseJSON(w http.ResponseWriter, _ *http.Request, resp any) {
 	}
 }
 
-func encodeResponseXML(w http.ResponseWriter, _ *http.Request, resp any) {
-	w.Header().Set("Content-Type", "application/xml")
+// encodeResponseJSONP writes `resp` as JSON wrapped in a call to the function
+// from the `callback` parameter. Callback names which are missing or are not
+// valid JavaScript identifiers result in a plain JSON error. This way nothing
+// from the request is ever written in a JavaScript response unchecked.
+func encodeResponseJSONP(w http.ResponseWriter, req *http.Request, resp any) {
+	callback := req.Form.Get("callback")
+	if callback == "" {
+		encodeResponseJSON(w, req, responseError(
+			errCodeMissingParameter,
+			"Required parameter is missing: callback",
+		))
+		return
+	}
 
-	enc := xml.NewEncoder(w)
-	enc.Indent("", "  ")
+	if len(callback) > maxJSONPCallbackLen || !jsonpCallbackRegexp.MatchString(callback) {
+		encodeResponseJSON(w, req, responseError(
+			errCodeGeneric,
+			"Invalid JSONP callback name",
+		))
+		return
+	}
 
-	if err := enc.Encode(resp); err != nil {
+	body, err := json.MarshalIndent(jsonResponse{Response: resp}, "", "  ")
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to encode JSON: %s", err)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// The leading empty comment guards against the response being interpreted
+	// as something other than JavaScript, e.g. a Flash file.
+	if _, err := fmt.Fprintf(w, "/**/%s(%s);\n", callback, body); err != nil {
+		log.Printf("error writing JSONP response: %s", err)
+	}
+}
+
+func encodeResponseXML(w http.ResponseWriter, _ *http.Request, resp any) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+	body, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
 		errMsg := fmt.Sprintf("failed to encode XML: %s", err)
 		http.Error(w, errMsg, http.StatusInternalServerError)
 		return
 	}
+
+	if _, err := fmt.Fprintf(w, "%s%s\n", xml.Header, body); err != nil {
+		log.Printf("error writing XML response: %s", err)
+	}
 }
 
 type jsonResponse struct {
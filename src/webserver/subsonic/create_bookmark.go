@@ -0,0 +1,55 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/bookmarks"
+)
+
+func (s *subsonic) createBookmark(w http.ResponseWriter, req *http.Request) {
+	idString := req.Form.Get("id")
+	positionString := req.Form.Get("position")
+	if idString == "" || positionString == "" {
+		resp := responseError(
+			errCodeMissingParameter,
+			"both id and position are required",
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	subsonicID, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil || !isTrackID(subsonicID) {
+		resp := responseError(errCodeNotFound, "song not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	position, err := strconv.ParseInt(positionString, 10, 64)
+	if err != nil || position < 0 {
+		resp := responseError(
+			errCodeGeneric,
+			"position must be a non-negative integer",
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	err = s.bookmarks.Set(req.Context(), toTrackDBID(subsonicID), bookmarks.SetArgs{
+		Position: position,
+		Comment:  req.Form.Get("comment"),
+	})
+	if errors.Is(err, bookmarks.ErrTrackNotFound) {
+		resp := responseError(errCodeNotFound, "song not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
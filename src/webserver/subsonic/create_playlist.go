@@ -1,6 +1,7 @@
 package subsonic
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -33,6 +34,7 @@ func (s *subsonic) createNewPlaylist(w http.ResponseWriter, req *http.Request) {
 
 	createArgs := playlists.CreateArgs{
 		Name:   name,
+		Owner:  s.auth.User,
 		Tracks: trackIDs,
 	}
 	id, err := s.playlists.Create(req.Context(), createArgs)
@@ -64,12 +66,19 @@ func (s *subsonic) updatePlaylistFromCreate(w http.ResponseWriter, req *http.Req
 	}
 
 	playlistUpdate := playlists.UpdateArgs{
+		User:            s.auth.User,
 		Name:            req.Form.Get("name"),
 		RemoveAllTracks: true,
 		AddTracks:       trackIDs,
 	}
 
-	if err := s.playlists.Update(req.Context(), playlistID, playlistUpdate); err != nil {
+	err = s.playlists.Update(req.Context(), playlistID, playlistUpdate)
+	if errors.Is(err, playlists.ErrReadOnly) ||
+		errors.Is(err, playlists.ErrNotAllowed) {
+		resp := responseError(errCodeNotAuthorized, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
 		resp := responseError(
 			errCodeGeneric,
 			fmt.Sprintf("failed to update playlist: %s", err),
@@ -106,7 +115,7 @@ func (s *subsonic) respondCreatedPlaylist(
 	req *http.Request,
 	id int64,
 ) {
-	playlist, err := s.playlists.Get(req.Context(), id)
+	playlist, err := s.playlists.Get(req.Context(), id, s.auth.User)
 	if err != nil {
 		resp := responseError(
 			errCodeGeneric,
@@ -118,7 +127,7 @@ func (s *subsonic) respondCreatedPlaylist(
 
 	resp := playlistWithSongsResponse{
 		baseResponse: responseOk(),
-		Playlist:     toXsdPlaylistWithSongs(playlist, s.auth.User, s.lastModified),
+		Playlist:     toXsdPlaylistWithSongs(playlist, s.auth.User, s.getLastModified()),
 	}
 
 	encodeResponse(w, req, resp)
@@ -0,0 +1,73 @@
+package subsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// createPodcastChannel subscribes for the podcast feed at the `url` parameter. The
+// feed is read in the background. Errors while reading it are reported in the
+// status of the channel.
+//
+// The optional `keepEpisodes` parameter is an Euterpe extension. It sets how many
+// downloaded episodes are kept for the channel, zero meaning the configured
+// default. Calling it again for the same `url` changes this number.
+func (s *subsonic) createPodcastChannel(w http.ResponseWriter, req *http.Request) {
+	feedURL := req.Form.Get("url")
+	if feedURL == "" {
+		resp := responseError(
+			errCodeMissingParameter,
+			"the parameter `url` is required",
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	keepEpisodes := -1
+	if keepString := req.Form.Get("keepEpisodes"); keepString != "" {
+		keep, err := strconv.Atoi(keepString)
+		if err != nil || keep < 0 {
+			resp := responseError(
+				errCodeMissingParameter,
+				"Bad parameter `keepEpisodes`. It must be a non-negative integer.",
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+		keepEpisodes = keep
+	}
+
+	channelID, err := s.podcasts.Subscribe(req.Context(), feedURL)
+	if err != nil {
+		resp := responseError(
+			errCodeGeneric,
+			fmt.Sprintf("could not create podcast channel: %s", err),
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	if keepEpisodes >= 0 {
+		err := s.podcasts.SetKeepEpisodes(req.Context(), channelID, keepEpisodes)
+		if err != nil {
+			resp := responseError(
+				errCodeGeneric,
+				fmt.Sprintf("could not set kept episodes: %s", err),
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+	}
+
+	ctx := context.WithoutCancel(req.Context())
+	go func() {
+		if err := s.podcasts.RefreshChannel(ctx, channelID); err != nil {
+			log.Printf("Refreshing podcast channel %d: %s\n", channelID, err)
+		}
+	}()
+
+	encodeResponse(w, req, responseOk())
+}
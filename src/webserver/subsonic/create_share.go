@@ -0,0 +1,191 @@
+package subsonic
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/shares"
+)
+
+func (s *subsonic) createShare(w http.ResponseWriter, req *http.Request) {
+	if len(req.Form["id"]) == 0 {
+		resp := responseError(errCodeMissingParameter, "at least one ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	items, err := queryToShareItems(req)
+	if err != nil {
+		resp := responseError(errCodeNotFound, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	owner := s.requestUser(req)
+	for _, item := range items {
+		if notFound := s.shareItemNotFound(req, item, owner); notFound != "" {
+			resp := responseError(errCodeNotFound, notFound)
+			encodeResponse(w, req, resp)
+			return
+		}
+	}
+
+	createArgs := shares.CreateArgs{
+		Items:       items,
+		Description: req.Form.Get("description"),
+		Owner:       owner,
+	}
+
+	if expiresStr := req.Form.Get("expires"); expiresStr != "" {
+		createArgs.ExpiresAt, err = parseShareExpires(expiresStr)
+		if err != nil {
+			resp := responseError(errCodeGeneric, err.Error())
+			encodeResponse(w, req, resp)
+			return
+		}
+	}
+
+	id, err := s.shares.Create(req.Context(), createArgs)
+	if err != nil {
+		resp := responseError(
+			errCodeGeneric,
+			fmt.Sprintf("failed to create share: %s", err),
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	share, err := s.shares.Get(req.Context(), id)
+	if err != nil {
+		resp := responseError(
+			errCodeGeneric,
+			fmt.Sprintf("failed to get created share: %s", err),
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	if len(share.Tracks) == 0 {
+		// None of the shared items were found. There is no point in keeping
+		// an empty share around.
+		if err := s.shares.Delete(req.Context(), id); err != nil {
+			resp := responseError(errCodeGeneric, err.Error())
+			encodeResponse(w, req, resp)
+			return
+		}
+
+		resp := responseError(errCodeNotFound, "nothing to share was found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := sharesResponse{
+		baseResponse: responseOk(),
+	}
+	resp.Shares.Children = append(resp.Shares.Children, s.toXsdShare(req, share))
+
+	encodeResponse(w, req, resp)
+}
+
+// shareItemNotFound returns an error message when `user` is not allowed to share
+// `item`. Shares are served without authentication so only items which the user
+// could access themselves are allowed. An empty string is returned for items which
+// could be shared.
+func (s *subsonic) shareItemNotFound(req *http.Request, item shares.Item, user string) string {
+	switch item.Type {
+	case shares.ItemTrack:
+		if !s.canAccessTrack(req.Context(), item.ID) {
+			return fmt.Sprintf("song %d not found", trackFSID(item.ID))
+		}
+	case shares.ItemAlbum:
+		if !s.canAccessAlbum(req.Context(), item.ID) {
+			return fmt.Sprintf("album %d not found", albumFSID(item.ID))
+		}
+	case shares.ItemPlaylist:
+		if !s.canSharePlaylist(req, item.ID, user) {
+			return fmt.Sprintf("playlist %s%d not found", coverPlaylistPrefix, item.ID)
+		}
+	}
+
+	return ""
+}
+
+// canSharePlaylist returns true when the playlist with ID `playlistID` is visible
+// for `user`.
+func (s *subsonic) canSharePlaylist(req *http.Request, playlistID int64, user string) bool {
+	if s.playlists == nil {
+		return false
+	}
+
+	_, err := s.playlists.Get(req.Context(), playlistID, user)
+	if err != nil && !errors.Is(err, playlists.ErrNotFound) {
+		log.Printf("error getting playlist %d for sharing: %s", playlistID, err)
+	}
+
+	return err == nil
+}
+
+// queryToShareItems converts the "id" input query array into items for sharing.
+// Songs and albums are identified by their subsonic IDs. Playlists are shared
+// using their cover art IDs, e.g. "pl-5".
+func queryToShareItems(req *http.Request) ([]shares.Item, error) {
+	var items []shares.Item
+	for _, idStr := range req.Form["id"] {
+		if playlistIDStr, ok := strings.CutPrefix(idStr, coverPlaylistPrefix); ok {
+			playlistID, err := strconv.ParseInt(playlistIDStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("playlist %s not found", idStr)
+			}
+
+			items = append(items, shares.Item{
+				Type: shares.ItemPlaylist,
+				ID:   playlistID,
+			})
+			continue
+		}
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ID: %w", err)
+		}
+
+		switch {
+		case isTrackID(id):
+			items = append(items, shares.Item{
+				Type: shares.ItemTrack,
+				ID:   toTrackDBID(id),
+			})
+		case isAlbumID(id):
+			items = append(items, shares.Item{
+				Type: shares.ItemAlbum,
+				ID:   toAlbumDBID(id),
+			})
+		default:
+			return nil, fmt.Errorf("only songs, albums and playlists could be shared")
+		}
+	}
+
+	return items, nil
+}
+
+// parseShareExpires parses the "expires" query value for shares. It is in
+// milliseconds since the Unix epoch. Zero means that the share never expires
+// and it is represented by the zero time.
+func parseShareExpires(expiresStr string) (time.Time, error) {
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed expires: %w", err)
+	}
+
+	if expires <= 0 {
+		return time.Time{}, nil
+	}
+
+	return time.UnixMilli(expires), nil
+}
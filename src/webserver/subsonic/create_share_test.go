@@ -0,0 +1,178 @@
+package subsonic_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/shares/sharesfakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestCreateShareOwner checks that shares are owned by the user who made the
+// request and that only playlists visible for them could be shared.
+func TestCreateShareOwner(t *testing.T) {
+	const (
+		username = "the-real-user"
+		password = "the-real-password"
+	)
+
+	playlister := &playlistsfakes.FakePlaylister{
+		GetStub: func(
+			_ context.Context,
+			id int64,
+			user string,
+		) (playlists.Playlist, error) {
+			if id != 5 || user != username {
+				return playlists.Playlist{}, playlists.ErrNotFound
+			}
+			return playlists.Playlist{ID: 5, Owner: username}, nil
+		},
+	}
+
+	sharer := &sharesfakes.FakeSharer{}
+	sharer.CreateReturns("share-id", nil)
+	sharer.GetStub = func(_ context.Context, id string) (shares.Share, error) {
+		_, args := sharer.CreateArgsForCall(sharer.CreateCallCount() - 1)
+		return shares.Share{
+			ID:     id,
+			Owner:  args.Owner,
+			Tracks: []library.TrackInfo{{ID: 10}},
+		}, nil
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Auth: true,
+			Authenticate: config.Auth{
+				User:     username,
+				Password: password,
+			},
+		},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+			Playlister: playlister,
+			Sharer:     sharer,
+		},
+	)
+
+	createShare := func(ids string) string {
+		req := httptest.NewRequest(
+			http.MethodGet,
+			subsonic.Prefix+"/createShare?f=json&u="+username+"&p="+password+"&"+ids,
+			nil,
+		)
+		rec := httptest.NewRecorder()
+		ssHandler.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	body := createShare("id=pl-5")
+	if !strings.Contains(body, `"username": "the-real-user"`) {
+		t.Errorf("expected the share to be owned by the request user but got:\n%s", body)
+	}
+	assert.Equal(t, 1, sharer.CreateCallCount(), "shares created")
+	_, args := sharer.CreateArgsForCall(0)
+	assert.Equal(t, username, args.Owner, "share owner")
+
+	body = createShare("id=pl-6")
+	if !strings.Contains(body, `"code": 70`) {
+		t.Errorf("expected not found error for a private playlist but got:\n%s", body)
+	}
+	assert.Equal(t, 1, sharer.CreateCallCount(), "shares created for private playlist")
+}
+
+// TestCreateShareMusicFolders makes sure that users who are limited to some music
+// folders could not share songs and albums from other music folders. Shares are
+// served without authentication.
+func TestCreateShareMusicFolders(t *testing.T) {
+	lib := &libraryfakes.FakeLibrary{}
+	lib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "Main", Path: "/media/Main"},
+		{ID: 2, Name: "Kids", Path: "/media/Kids"},
+	}, nil)
+	lib.TrackInMusicFoldersStub = func(_ context.Context, id int64, _ []int64) (bool, error) {
+		return id == 5, nil
+	}
+	lib.AlbumInMusicFoldersStub = func(_ context.Context, id int64, _ []int64) (bool, error) {
+		return id == 7, nil
+	}
+
+	sharer := &sharesfakes.FakeSharer{}
+	sharer.CreateReturns("share-id", nil)
+	sharer.GetReturns(shares.Share{
+		ID:     "share-id",
+		Tracks: []library.TrackInfo{{ID: 5}},
+	}, nil)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User:         "test-user",
+				MusicFolders: []string{"Kids"},
+			},
+		},
+		subsonic.Deps{
+			Library:   lib,
+			Browser:   &libraryfakes.FakeBrowser{},
+			AlbumArt:  &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt: &subsonicfakes.FakeCoverArtHandler{},
+			Sharer:    sharer,
+		},
+	)
+
+	tests := []struct {
+		desc    string
+		ids     string
+		created bool
+	}{
+		{desc: "allowed song", ids: "id=2000000005", created: true},
+		{desc: "allowed album", ids: "id=7", created: true},
+		{desc: "song from another music folder", ids: "id=2000000006"},
+		{desc: "album from another music folder", ids: "id=8"},
+		{desc: "mixed songs", ids: "id=2000000005&id=2000000006"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			createdBefore := sharer.CreateCallCount()
+
+			req := httptest.NewRequest(
+				http.MethodGet,
+				subsonic.Prefix+"/createShare?f=json&"+test.ids,
+				nil,
+			)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+			body := rec.Body.String()
+
+			if !test.created {
+				if !strings.Contains(body, `"code": 70`) {
+					t.Errorf("expected not found error but got:\n%s", body)
+				}
+				assert.Equal(t, createdBefore, sharer.CreateCallCount(), "shares created")
+				return
+			}
+
+			if !strings.Contains(body, `"status": "ok"`) {
+				t.Errorf("expected the share to be created but got:\n%s", body)
+			}
+			assert.Equal(t, createdBefore+1, sharer.CreateCallCount(), "shares created")
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/bookmarks"
+)
+
+func (s *subsonic) deleteBookmark(w http.ResponseWriter, req *http.Request) {
+	idString := req.Form.Get("id")
+	if idString == "" {
+		resp := responseError(errCodeMissingParameter, "song ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	subsonicID, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil || !isTrackID(subsonicID) {
+		resp := responseError(errCodeNotFound, "bookmark not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	err = s.bookmarks.Delete(req.Context(), toTrackDBID(subsonicID))
+	if errors.Is(err, bookmarks.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "bookmark not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
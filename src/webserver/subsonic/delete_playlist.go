@@ -20,11 +20,16 @@ func (s *subsonic) deletePlaylist(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err = s.playlists.Delete(req.Context(), id)
+	err = s.playlists.Delete(req.Context(), id, s.auth.User)
 	if err != nil && errors.Is(err, playlists.ErrNotFound) {
 		resp := responseError(errCodeNotFound, "playlist not found")
 		encodeResponse(w, req, resp)
 		return
+	} else if errors.Is(err, playlists.ErrReadOnly) ||
+		errors.Is(err, playlists.ErrNotAllowed) {
+		resp := responseError(errCodeNotAuthorized, err.Error())
+		encodeResponse(w, req, resp)
+		return
 	} else if err != nil {
 		resp := responseError(errCodeGeneric, err.Error())
 		encodeResponse(w, req, resp)
@@ -0,0 +1,46 @@
+package subsonic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/podcasts"
+)
+
+// deletePodcastChannel unsubscribes from a podcast channel and removes all of its
+// downloaded episodes.
+func (s *subsonic) deletePodcastChannel(w http.ResponseWriter, req *http.Request) {
+	idString := req.Form.Get("id")
+	if idString == "" {
+		resp := responseError(
+			errCodeMissingParameter,
+			"the parameter `id` is required",
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	channelID, err := toPodcastChannelDBID(idString)
+	if err != nil {
+		resp := responseError(errCodeNotFound, "podcast channel not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	err = s.podcasts.Unsubscribe(req.Context(), channelID)
+	if errors.Is(err, podcasts.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "podcast channel not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(
+			errCodeGeneric,
+			fmt.Sprintf("could not delete podcast channel: %s", err),
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
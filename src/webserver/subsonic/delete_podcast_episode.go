@@ -0,0 +1,60 @@
+package subsonic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/podcasts"
+)
+
+// deletePodcastEpisode removes the downloaded file of a podcast episode.
+func (s *subsonic) deletePodcastEpisode(w http.ResponseWriter, req *http.Request) {
+	episodeID, ok := s.podcastEpisodeParam(w, req)
+	if !ok {
+		return
+	}
+
+	err := s.podcasts.DeleteEpisode(req.Context(), episodeID)
+	if errors.Is(err, podcasts.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "podcast episode not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(
+			errCodeGeneric,
+			fmt.Sprintf("could not delete podcast episode: %s", err),
+		)
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
+
+// podcastEpisodeParam returns the database ID of the podcast episode from the `id`
+// parameter. When it is missing or malformed an error response is written and
+// the returned boolean is false.
+func (s *subsonic) podcastEpisodeParam(
+	w http.ResponseWriter,
+	req *http.Request,
+) (int64, bool) {
+	idString := req.Form.Get("id")
+	if idString == "" {
+		resp := responseError(
+			errCodeMissingParameter,
+			"the parameter `id` is required",
+		)
+		encodeResponse(w, req, resp)
+		return 0, false
+	}
+
+	episodeID, err := toPodcastEpisodeDBID(idString)
+	if err != nil {
+		resp := responseError(errCodeNotFound, "podcast episode not found")
+		encodeResponse(w, req, resp)
+		return 0, false
+	}
+
+	return episodeID, true
+}
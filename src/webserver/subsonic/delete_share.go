@@ -0,0 +1,30 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/shares"
+)
+
+func (s *subsonic) deleteShare(w http.ResponseWriter, req *http.Request) {
+	id := req.Form.Get("id")
+	if id == "" {
+		resp := responseError(errCodeMissingParameter, "share ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	err := s.shares.Delete(req.Context(), id)
+	if err != nil && errors.Is(err, shares.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "share not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
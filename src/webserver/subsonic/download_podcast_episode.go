@@ -0,0 +1,38 @@
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/podcasts"
+)
+
+// downloadPodcastEpisode starts downloading a podcast episode in the background.
+func (s *subsonic) downloadPodcastEpisode(w http.ResponseWriter, req *http.Request) {
+	episodeID, ok := s.podcastEpisodeParam(w, req)
+	if !ok {
+		return
+	}
+
+	_, err := s.podcasts.Episode(req.Context(), episodeID)
+	if errors.Is(err, podcasts.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "podcast episode not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	ctx := context.WithoutCancel(req.Context())
+	go func() {
+		if err := s.podcasts.Download(ctx, episodeID); err != nil {
+			log.Printf("Downloading podcast episode %d: %s\n", episodeID, err)
+		}
+	}()
+
+	encodeResponse(w, req, responseOk())
+}
@@ -15,6 +15,11 @@ func (s *subsonic) getAlbum(w http.ResponseWriter, req *http.Request) {
 	}
 
 	albumID := toAlbumDBID(subsonicID)
+	if !s.canAccessAlbum(req.Context(), albumID) {
+		resp := responseError(errCodeNotFound, "album not found")
+		encodeResponse(w, req, resp)
+		return
+	}
 
 	album, err := s.lib.GetAlbum(req.Context(), albumID)
 	if err != nil {
@@ -28,12 +33,13 @@ func (s *subsonic) getAlbum(w http.ResponseWriter, req *http.Request) {
 	}
 
 	tracks := s.lib.GetAlbumFiles(req.Context(), albumID)
+	positions := s.bookmarkPositions(req.Context())
 	for _, track := range tracks {
-		alEntry.Children = append(alEntry.Children, trackToChild(
-			track,
-			s.getLastModified(),
-		))
+		child := trackToChild(track, s.getLastModified())
+		child.BookmarkPosition = positions[track.ID]
+		alEntry.Children = append(alEntry.Children, child)
 	}
+	alEntry.DiscTitles = toDiscTitles(tracks)
 
 	resp := albumResponse{
 		baseResponse: responseOk(),
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 func (s *subsonic) getAlbumInfo2(w http.ResponseWriter, req *http.Request) {
@@ -19,6 +20,11 @@ func (s *subsonic) getAlbumInfo2(w http.ResponseWriter, req *http.Request) {
 	}
 
 	albumID := toAlbumDBID(subsonicID)
+	if !s.canAccessAlbum(req.Context(), albumID) {
+		resp := responseError(errCodeNotFound, "album not found")
+		encodeResponse(w, req, resp)
+		return
+	}
 
 	album, err := s.lib.GetAlbum(req.Context(), albumID)
 	if err != nil && errors.Is(err, library.ErrAlbumNotFound) {
@@ -67,8 +73,8 @@ func (s *subsonic) getAlbumImageURL(
 	query.Set("id", albumConverArtID(albumID))
 	setQueryFromReq(query, req)
 	artURL := url.URL{
-		Scheme:   getProtoFromRequest(req),
-		Host:     getHostFromRequest(req),
+		Scheme:   webutils.RequestScheme(req),
+		Host:     webutils.RequestHost(req),
 		Path:     s.prefix + "/getCoverArt",
 		RawQuery: query.Encode(),
 	}
@@ -84,6 +84,12 @@ func (s *subsonic) getAlbumList2(w http.ResponseWriter, req *http.Request) {
 		browseArgs.Offset = offset
 	}
 
+	browseArgs.MusicFolderIDs, err = s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
 	albums, _ := s.libBrowser.BrowseAlbums(browseArgs)
 
 	var albumList []xsdAlbumID3
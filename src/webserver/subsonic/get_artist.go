@@ -1,8 +1,11 @@
 package subsonic
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+
+	"github.com/ironsmile/euterpe/src/library"
 )
 
 func (s *subsonic) getArtist(w http.ResponseWriter, req *http.Request) {
@@ -17,7 +20,11 @@ func (s *subsonic) getArtist(w http.ResponseWriter, req *http.Request) {
 	artistID := toArtistDBID(subsonicID)
 
 	entry, err := s.getArtistDirectory(req, artistID)
-	if err != nil {
+	if errors.Is(err, library.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "artist not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
 		resp := responseError(errCodeGeneric, err.Error())
 		encodeResponse(w, req, resp)
 		return
@@ -30,10 +30,25 @@ func (s *subsonic) getArtistInfo(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	baseArtistInfo := s.getArtistInfoBase(req, artist)
+	similarArtists, err := s.similarArtists(req, artistID)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
 	resp := artistInfoResponse{
-		baseResponse: baseArtistInfo.baseResponse,
-		ArtistInfo:   baseArtistInfo.ArtistInfo2,
+		baseResponse: responseOk(),
+		ArtistInfo: xsdArtistInfo{
+			xsdArtistInfoBase: s.getArtistInfoBase(req, artist),
+		},
+	}
+
+	for _, similarArtist := range similarArtists {
+		artURL, _ := s.getAristImageURL(req, similarArtist.ID)
+		resp.ArtistInfo.SimilarArtists = append(
+			resp.ArtistInfo.SimilarArtists,
+			toXSDArtist(similarArtist, artURL),
+		)
 	}
 
 	encodeResponse(w, req, resp)
@@ -42,7 +57,5 @@ func (s *subsonic) getArtistInfo(w http.ResponseWriter, req *http.Request) {
 type artistInfoResponse struct {
 	baseResponse
 
-	// ArtistInfo reuses the aristInfo2Element element since for the moment
-	// it is exactly the same as what would've been the artistInfoElement.
-	ArtistInfo xsdArtistInfoBase `xml:"artistInfo"`
+	ArtistInfo xsdArtistInfo `xml:"artistInfo"`
 }
@@ -7,6 +7,8 @@ import (
 	"strconv"
 
 	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 func (s *subsonic) getArtistInfo2(w http.ResponseWriter, req *http.Request) {
@@ -31,36 +33,82 @@ func (s *subsonic) getArtistInfo2(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	resp := s.getArtistInfoBase(req, artist)
+	similarArtists, err := s.similarArtists(req, artistID)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
+	resp := artistInfo2Response{
+		baseResponse: responseOk(),
+		ArtistInfo2: xsdArtistInfo2{
+			xsdArtistInfoBase: s.getArtistInfoBase(req, artist),
+		},
+	}
+
+	for _, similarArtist := range similarArtists {
+		artURL, _ := s.getAristImageURL(req, similarArtist.ID)
+		resp.ArtistInfo2.SimilarArtists = append(
+			resp.ArtistInfo2.SimilarArtists,
+			dbArtistToArtistID3(similarArtist, artURL),
+		)
+	}
+
 	encodeResponse(w, req, resp)
 }
 
 func (s *subsonic) getArtistInfoBase(
 	req *http.Request,
 	artist library.Artist,
-) artistInfo2Response {
+) xsdArtistInfoBase {
 	artURL, query := s.getAristImageURL(req, artist.ID)
 
-	resp := artistInfo2Response{
-		baseResponse: responseOk(),
-		ArtistInfo2: xsdArtistInfoBase{
-			LastfmURL: "https://last.fm/music/" + url.PathEscape(artist.Name),
-		},
+	info := xsdArtistInfoBase{
+		LastfmURL: "https://last.fm/music/" + url.PathEscape(artist.Name),
 	}
 
 	query.Set("size", "150")
 	artURL.RawQuery = query.Encode()
-	resp.ArtistInfo2.SmallImageURL = artURL.String()
+	info.SmallImageURL = artURL.String()
 
 	query.Set("size", "300")
 	artURL.RawQuery = query.Encode()
-	resp.ArtistInfo2.MediumImageURL = artURL.String()
+	info.MediumImageURL = artURL.String()
 
 	query.Set("size", "600")
 	artURL.RawQuery = query.Encode()
-	resp.ArtistInfo2.LargeImageURL = artURL.String()
+	info.LargeImageURL = artURL.String()
+
+	return info
+}
+
+// similarArtists returns the artists which are similar to the one with ID
+// `artistID`. The "count" request parameter controls how many are returned.
+// Only artists from music folders which the user could access are included.
+// There are none when the handler has no similar.Finder.
+func (s *subsonic) similarArtists(
+	req *http.Request,
+	artistID int64,
+) ([]library.Artist, error) {
+	if s.similar == nil {
+		return nil, nil
+	}
+
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		return nil, err
+	}
+
+	count := parseIntOrDefault(req.Form.Get("count"), 20)
+	if count > 100 {
+		count = 100
+	}
 
-	return resp
+	return s.similar.SimilarArtists(req.Context(), similar.Args{
+		ArtistIDs:      []int64{artistID},
+		MusicFolderIDs: musicFolderIDs,
+		Count:          int(count),
+	})
 }
 
 // getAristImageURL returns a URL for artist image with query parameters
@@ -74,8 +122,8 @@ func (s *subsonic) getAristImageURL(
 	query.Set("id", artistCoverArtID(artistID))
 	setQueryFromReq(query, req)
 	artURL := url.URL{
-		Scheme:   getProtoFromRequest(req),
-		Host:     getHostFromRequest(req),
+		Scheme:   webutils.RequestScheme(req),
+		Host:     webutils.RequestHost(req),
 		Path:     s.prefix + "/getCoverArt",
 		RawQuery: query.Encode(),
 	}
@@ -109,5 +157,5 @@ func setQueryFromReq(query url.Values, req *http.Request) {
 type artistInfo2Response struct {
 	baseResponse
 
-	ArtistInfo2 xsdArtistInfoBase `xml:"artistInfo2"`
+	ArtistInfo2 xsdArtistInfo2 `xml:"artistInfo2"`
 }
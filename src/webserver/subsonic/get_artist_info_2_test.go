@@ -15,6 +15,7 @@ import (
 	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
 	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/similar/similarfakes"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
 )
@@ -28,28 +29,31 @@ func TestGetArtistInfo2(t *testing.T) {
 
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		&libraryfakes.FakeLibrary{
-			GetArtistStub: func(_ context.Context, id int64) (library.Artist, error) {
-				if id != artistID {
-					return library.Artist{}, library.ErrArtistNotFound
-				}
-
-				return library.Artist{
-					ID:   artistID,
-					Name: artistName,
-				}, nil
-			},
-		},
-		&libraryfakes.FakeBrowser{},
-		&radiofakes.FakeStations{},
-		&playlistsfakes.FakePlaylister{},
 		config.Config{
 			Authenticate: config.Auth{
 				User: "test-user",
 			},
 		},
-		&subsonicfakes.FakeCoverArtHandler{},
-		&subsonicfakes.FakeCoverArtHandler{},
+		subsonic.Deps{
+			Library: &libraryfakes.FakeLibrary{
+				GetArtistStub: func(_ context.Context, id int64) (library.Artist, error) {
+					if id != artistID {
+						return library.Artist{}, library.ErrArtistNotFound
+					}
+
+					return library.Artist{
+						ID:   artistID,
+						Name: artistName,
+					}, nil
+				},
+			},
+			Browser:       &libraryfakes.FakeBrowser{},
+			Stations:      &radiofakes.FakeStations{},
+			Playlister:    &playlistsfakes.FakePlaylister{},
+			AlbumArt:      &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:     &subsonicfakes.FakeCoverArtHandler{},
+			SimilarFinder: &similarfakes.FakeFinder{},
+		},
 	)
 
 	tests := []struct {
@@ -299,17 +303,20 @@ func TestBothGetArtistInfosErrors(t *testing.T) {
 
 			ssHandler := subsonic.NewHandler(
 				subsonic.Prefix,
-				lib,
-				&libraryfakes.FakeBrowser{},
-				&radiofakes.FakeStations{},
-				&playlistsfakes.FakePlaylister{},
 				config.Config{
 					Authenticate: config.Auth{
 						User: "test-user",
 					},
 				},
-				&subsonicfakes.FakeCoverArtHandler{},
-				&subsonicfakes.FakeCoverArtHandler{},
+				subsonic.Deps{
+					Library:       lib,
+					Browser:       &libraryfakes.FakeBrowser{},
+					Stations:      &radiofakes.FakeStations{},
+					Playlister:    &playlistsfakes.FakePlaylister{},
+					AlbumArt:      &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:     &subsonicfakes.FakeCoverArtHandler{},
+					SimilarFinder: &similarfakes.FakeFinder{},
+				},
 			)
 
 			req := httptest.NewRequest(http.MethodGet, test.url, nil)
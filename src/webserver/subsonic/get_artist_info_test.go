@@ -14,6 +14,7 @@ import (
 	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
 	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/similar/similarfakes"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
 )
@@ -27,28 +28,31 @@ func TestGetArtistInfo(t *testing.T) {
 
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		&libraryfakes.FakeLibrary{
-			GetArtistStub: func(_ context.Context, id int64) (library.Artist, error) {
-				if id != artistID {
-					return library.Artist{}, library.ErrArtistNotFound
-				}
-
-				return library.Artist{
-					ID:   artistID,
-					Name: artistName,
-				}, nil
-			},
-		},
-		&libraryfakes.FakeBrowser{},
-		&radiofakes.FakeStations{},
-		&playlistsfakes.FakePlaylister{},
 		config.Config{
 			Authenticate: config.Auth{
 				User: "test-user",
 			},
 		},
-		&subsonicfakes.FakeCoverArtHandler{},
-		&subsonicfakes.FakeCoverArtHandler{},
+		subsonic.Deps{
+			Library: &libraryfakes.FakeLibrary{
+				GetArtistStub: func(_ context.Context, id int64) (library.Artist, error) {
+					if id != artistID {
+						return library.Artist{}, library.ErrArtistNotFound
+					}
+
+					return library.Artist{
+						ID:   artistID,
+						Name: artistName,
+					}, nil
+				},
+			},
+			Browser:       &libraryfakes.FakeBrowser{},
+			Stations:      &radiofakes.FakeStations{},
+			Playlister:    &playlistsfakes.FakePlaylister{},
+			AlbumArt:      &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:     &subsonicfakes.FakeCoverArtHandler{},
+			SimilarFinder: &similarfakes.FakeFinder{},
+		},
 	)
 
 	tests := []struct {
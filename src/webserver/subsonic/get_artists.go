@@ -7,7 +7,30 @@ import (
 )
 
 func (s *subsonic) getArtists(w http.ResponseWriter, req *http.Request) {
-	indexes := xsdArtistsID3{}
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
+	modified, err := s.modifiedSince(req)
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	indexes := xsdArtistsID3{
+		LastModified: s.getLastModified().UnixMilli(),
+	}
+
+	if !modified {
+		encodeResponse(w, req, artistsResponse{
+			baseResponse: responseOk(),
+			AristsList:   indexes,
+		})
+		return
+	}
 
 	artURL, artQuery := s.getAristImageURL(req, 0)
 	var (
@@ -17,10 +40,11 @@ func (s *subsonic) getArtists(w http.ResponseWriter, req *http.Request) {
 	)
 	for {
 		artists, totalCount := s.libBrowser.BrowseArtists(library.BrowseArgs{
-			Page:    page,
-			PerPage: 500,
-			Order:   library.OrderAsc,
-			OrderBy: library.OrderByName,
+			Page:           page,
+			PerPage:        500,
+			Order:          library.OrderAsc,
+			OrderBy:        library.OrderByName,
+			MusicFolderIDs: musicFolderIDs,
 		})
 
 		if len(artists) == 0 {
@@ -0,0 +1,56 @@
+package subsonic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+func (s *subsonic) getAvatar(w http.ResponseWriter, req *http.Request) {
+	username := req.Form.Get("username")
+	if username == "" {
+		resp := responseError(errCodeMissingParameter, "missing username parameter")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	if username != s.auth.User {
+		resp := responseError(errCodeNotFound, "user not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	avatar, err := s.userAvatar(req, username)
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(avatar))
+}
+
+// userAvatar returns the uploaded avatar of a user. When there is none then an
+// image with the user's initials is generated.
+func (s *subsonic) userAvatar(req *http.Request, username string) ([]byte, error) {
+	imgReader, err := s.avatars.FindAvatar(req.Context(), username, library.OriginalImage)
+	if errors.Is(err, library.ErrArtworkNotFound) {
+		return initialsImage(username)
+	} else if err != nil {
+		return nil, fmt.Errorf("finding avatar: %w", err)
+	}
+	defer imgReader.Close()
+
+	avatar, err := io.ReadAll(imgReader)
+	if err != nil {
+		return nil, fmt.Errorf("reading avatar: %w", err)
+	}
+
+	return avatar, nil
+}
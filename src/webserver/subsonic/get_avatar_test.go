@@ -0,0 +1,88 @@
+package subsonic_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestGetAvatar checks that the uploaded avatar of the user is returned by
+// getAvatar and that an image is generated when there is no such avatar.
+func TestGetAvatar(t *testing.T) {
+	const uploadedAvatar = "uploaded avatar"
+	var hasAvatar bool
+
+	avatars := &libraryfakes.FakeAvatarManager{
+		FindAvatarStub: func(
+			_ context.Context,
+			_ string,
+			_ library.ImageSize,
+		) (io.ReadCloser, error) {
+			if !hasAvatar {
+				return nil, library.ErrArtworkNotFound
+			}
+
+			return io.NopCloser(bytes.NewBufferString(uploadedAvatar)), nil
+		},
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User: "test-user",
+			},
+		},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			Avatars:    avatars,
+		},
+	)
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+"/getAvatar?username=test-user",
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "HTTP status code for generated avatar")
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"), "content type")
+
+	img, err := png.Decode(rec.Body)
+	assert.NilErr(t, err, "decoding generated avatar")
+	assert.Equal(t, 256, img.Bounds().Dx(), "generated avatar width")
+	assert.Equal(t, 256, img.Bounds().Dy(), "generated avatar height")
+
+	hasAvatar = true
+	req = httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+"/getAvatar?username=test-user",
+		nil,
+	)
+	rec = httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "HTTP status code for uploaded avatar")
+	assert.Equal(t, uploadedAvatar, rec.Body.String(), "uploaded avatar body")
+
+	_, username, size := avatars.FindAvatarArgsForCall(1)
+	assert.Equal(t, "test-user", username, "avatar username")
+	assert.Equal(t, library.OriginalImage, size, "avatar size")
+}
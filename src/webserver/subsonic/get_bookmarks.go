@@ -0,0 +1,56 @@
+package subsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+func (s *subsonic) getBookmarks(w http.ResponseWriter, req *http.Request) {
+	list, err := s.bookmarks.List(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := bookmarksResponse{
+		baseResponse: responseOk(),
+		Bookmarks: xsdBookmarks{
+			Children: []xsdBookmark{},
+		},
+	}
+	for _, bookmark := range list {
+		resp.Bookmarks.Children = append(
+			resp.Bookmarks.Children,
+			toXsdBookmark(bookmark, s.auth.User, s.getLastModified()),
+		)
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+// bookmarkPositions returns a map from track ID to the position of its bookmark
+// in milliseconds. Errors are only logged since bookmark positions are
+// not essential.
+func (s *subsonic) bookmarkPositions(ctx context.Context) map[int64]int64 {
+	positions := make(map[int64]int64)
+
+	list, err := s.bookmarks.List(ctx)
+	if err != nil {
+		log.Printf("error getting bookmarks: %s", err)
+		return positions
+	}
+
+	for _, bookmark := range list {
+		positions[bookmark.Track.ID] = bookmark.Position
+	}
+
+	return positions
+}
+
+type bookmarksResponse struct {
+	baseResponse
+
+	Bookmarks xsdBookmarks `xml:"bookmarks" json:"bookmarks"`
+}
@@ -1,6 +1,7 @@
 package subsonic
 
 import (
+    "context"
     "log"
     "net/http"
     "strconv"
@@ -11,21 +12,35 @@ func (s *subsonic) getCoverArt(w http.ResponseWriter, req *http.Request) {
     id := req.Form.Get("id")
     size := req.Form.Get("size")
 
-    var artworkHandler CoverArtHandler
+    var (
+        artworkHandler CoverArtHandler
+        canAccess      func(context.Context, int64) bool
+    )
     if strings.HasPrefix(id, coverPlaylistPrefix) {
-        w.WriteHeader(http.StatusNotFound)
+        if s.playlistArtHandler == nil {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        artworkHandler = s.playlistArtHandler
+        id = strings.TrimPrefix(id, coverPlaylistPrefix)
+    } else if strings.HasPrefix(id, podcastChannelPrefix) {
+        s.getPodcastCoverArt(w, req, id)
         return
     } else if strings.HasPrefix(id, coverAlbumPrefix) {
         artworkHandler = s.albumArtHandler
+        canAccess = s.canAccessAlbum
         id = strings.TrimPrefix(id, coverAlbumPrefix)
     } else if strings.HasPrefix(id, coverArtistPrefix) {
         artworkHandler = s.artistArtHandler
+        canAccess = s.canAccessArtist
         id = strings.TrimPrefix(id, coverArtistPrefix)
     } else if albumID := isAlbumIDString(id); albumID != "" {
         artworkHandler = s.albumArtHandler
+        canAccess = s.canAccessAlbum
         id = albumID
     } else if artistID := isArtistIDString(id); artistID != "" {
         artworkHandler = s.artistArtHandler
+        canAccess = s.canAccessArtist
         id = artistID
     } else {
         w.WriteHeader(http.StatusNotFound)
@@ -41,6 +56,11 @@ func (s *subsonic) getCoverArt(w http.ResponseWriter, req *http.Request) {
         return
     }
 
+    if canAccess != nil && !canAccess(req.Context(), dbArtID) {
+        w.WriteHeader(http.StatusNotFound)
+        return
+    }
+
     if sizePx, err := strconv.ParseInt(size, 10, 64); err == nil && sizePx < 200 {
         query := req.Form
         query.Set("size", "small")
@@ -57,6 +77,24 @@ func (s *subsonic) getCoverArt(w http.ResponseWriter, req *http.Request) {
     }
 }
 
+// getPodcastCoverArt serves the downloaded artwork of the podcast channel with
+// subsonic ID `id`.
+func (s *subsonic) getPodcastCoverArt(w http.ResponseWriter, req *http.Request, id string) {
+    channelID, err := toPodcastChannelDBID(id)
+    if err != nil || s.podcasts == nil {
+        w.WriteHeader(http.StatusNotFound)
+        return
+    }
+
+    channel, err := s.podcasts.Channel(req.Context(), channelID)
+    if err != nil || channel.ImagePath == "" {
+        w.WriteHeader(http.StatusNotFound)
+        return
+    }
+
+    http.ServeFile(w, req, channel.ImagePath)
+}
+
 func isAlbumIDString(subsonicID string) string {
     id, err := strconv.ParseInt(subsonicID, 10, 64)
     if err != nil {
@@ -20,8 +20,9 @@ import (
 // format.
 func TestGetCoverArt(t *testing.T) {
 	const (
-		albumArtwork  = `album artwork body`
-		artistArtwork = `artist artwork body`
+		albumArtwork    = `album artwork body`
+		artistArtwork   = `artist artwork body`
+		playlistArtwork = `playlist artwork body`
 	)
 
 	albumArtFinder := &subsonicfakes.FakeCoverArtHandler{
@@ -46,20 +47,34 @@ func TestGetCoverArt(t *testing.T) {
 			return nil
 		},
 	}
+	playlistArtFinder := &subsonicfakes.FakeCoverArtHandler{
+		FindStub: func(w http.ResponseWriter, _ *http.Request, id int64) error {
+			if id != 42 {
+				w.WriteHeader(http.StatusNotFound)
+				return nil
+			}
+
+			fmt.Fprint(w, playlistArtwork)
+			return nil
+		},
+	}
 
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		&libraryfakes.FakeLibrary{},
-		&libraryfakes.FakeBrowser{},
-		&radiofakes.FakeStations{},
-		&playlistsfakes.FakePlaylister{},
 		config.Config{
 			Authenticate: config.Auth{
 				User: "test-user",
 			},
 		},
-		albumArtFinder,
-		artistArtFinder,
+		subsonic.Deps{
+			Library:     &libraryfakes.FakeLibrary{},
+			Browser:     &libraryfakes.FakeBrowser{},
+			Stations:    &radiofakes.FakeStations{},
+			Playlister:  &playlistsfakes.FakePlaylister{},
+			AlbumArt:    albumArtFinder,
+			ArtistArt:   artistArtFinder,
+			PlaylistArt: playlistArtFinder,
+		},
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/rest/getCoverArt?id=al-42", nil)
@@ -113,14 +128,22 @@ func TestGetCoverArt(t *testing.T) {
 		"wrong size requested from the art finder",
 	)
 
+	req = httptest.NewRequest(http.MethodGet, "/rest/getCoverArt?id=pl-42&size=64", nil)
+	rec = httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+	assert.Equal(t, playlistArtwork, rec.Body.String(), "playlist response body")
+	assert.Equal(t, 1, playlistArtFinder.FindCallCount(), "wrong number of Find calls")
+	_, findReq, findID = playlistArtFinder.FindArgsForCall(0)
+	assert.Equal(t, 42, findID, "wrong playlist ID send to the art finder")
+	assert.Equal(t, "small", findReq.URL.Query().Get("size"),
+		"wrong size requested from the playlist art finder",
+	)
+
 	notFoundTests := []struct {
 		desc string
 		url  string
 	}{
-		{
-			desc: "playlists have no artwork",
-			url:  "/rest/getCoverArt?id=pl-42",
-		},
 		{
 			desc: "tracks have no artwork",
 			url:  fmt.Sprintf("/rest/getCoverArt?id=%d", int64(2e9+42)),
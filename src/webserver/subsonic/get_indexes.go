@@ -4,43 +4,38 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/ironsmile/euterpe/src/library"
 )
 
 func (s *subsonic) getIndexes(w http.ResponseWriter, req *http.Request) {
-	musicFolderID := req.Form.Get("musicFolderId")
-	ifModifiedSince := req.Form.Get("ifModifiedSince")
-
-	if musicFolderID != "" && !musicFolderExists(musicFolderID) {
-		resp := responseError(errCodeNotFound, "Unknown music folder ID")
-		encodeResponse(w, req, resp)
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
 		return
 	}
 
-	if ifModifiedSince != "" {
-		t, err := strconv.ParseInt(ifModifiedSince, 10, 64)
-		if err != nil {
-			resp := responseError(
-				0,
-				fmt.Sprintf("ifModifiedSince must be an int: %s", err),
-			)
-			encodeResponse(w, req, resp)
-			return
-		}
-		ifModfiedSinceTime := time.Unix(t/1000, (t%1000)*1e6)
-
-		if ifModfiedSinceTime.After(s.getLastModified()) {
-			w.WriteHeader(http.StatusNotModified)
-			return
-		}
+	modified, err := s.modifiedSince(req)
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
 	}
 
 	indexes := xsdIndexes{
 		LastModified: s.getLastModified().UnixMilli(),
 	}
 
+	// Clients which already have the latest indexes receive an empty list. This
+	// way they know they could keep using their cached copy.
+	if !modified {
+		encodeResponse(w, req, indexesResponse{
+			baseResponse: responseOk(),
+			IndexesList:  indexes,
+		})
+		return
+	}
+
 	artURL, artURLQuery := s.getAristImageURL(req, 0)
 
 	var (
@@ -50,10 +45,11 @@ func (s *subsonic) getIndexes(w http.ResponseWriter, req *http.Request) {
 	)
 	for {
 		artists, totalCount := s.libBrowser.BrowseArtists(library.BrowseArgs{
-			Page:    page,
-			PerPage: 500,
-			Order:   library.OrderAsc,
-			OrderBy: library.OrderByName,
+			Page:           page,
+			PerPage:        500,
+			Order:          library.OrderAsc,
+			OrderBy:        library.OrderByName,
+			MusicFolderIDs: musicFolderIDs,
 		})
 
 		if len(artists) == 0 {
@@ -109,3 +105,20 @@ type indexesResponse struct {
 
 	IndexesList xsdIndexes `xml:"indexes" json:"indexes"`
 }
+
+// modifiedSince returns whether the library has been changed after the time in the
+// "ifModifiedSince" request parameter. It is in milliseconds since the Unix epoch.
+// Requests without this parameter are always considered modified.
+func (s *subsonic) modifiedSince(req *http.Request) (bool, error) {
+	ifModifiedSince := req.Form.Get("ifModifiedSince")
+	if ifModifiedSince == "" {
+		return true, nil
+	}
+
+	since, err := strconv.ParseInt(ifModifiedSince, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("ifModifiedSince must be an int: %w", err)
+	}
+
+	return s.getLastModified().UnixMilli() > since, nil
+}
@@ -1,6 +1,7 @@
 package subsonic
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -38,7 +39,11 @@ func (s *subsonic) getMusicDirectory(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err != nil {
+	if errors.Is(err, library.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "directory not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
 		resp := responseError(errCodeGeneric, err.Error())
 		encodeResponse(w, req, resp)
 		return
@@ -58,12 +63,22 @@ func (s *subsonic) getArtistDirectory(
 ) (xsdDirectory, error) {
 	ctx := req.Context()
 
+	if !s.canAccessArtist(ctx, artistID) {
+		return xsdDirectory{}, library.ErrArtistNotFound
+	}
+
 	artist, err := s.lib.GetArtist(ctx, artistID)
 	if err != nil {
 		return xsdDirectory{}, fmt.Errorf("getting artist: %w", err)
 	}
 	artistSubsonicID := artistFSID(artistID)
-	albums := s.lib.GetArtistAlbums(req.Context(), artistID)
+
+	var albums []library.Album
+	for _, album := range s.lib.GetArtistAlbums(ctx, artistID) {
+		if s.canAccessAlbum(ctx, album.ID) {
+			albums = append(albums, album)
+		}
+	}
 
 	artURL, _ := s.getAristImageURL(req, artistID)
 
@@ -84,7 +99,7 @@ func (s *subsonic) getArtistDirectory(
 		resp.Children = append(resp.Children, albumToChild(
 			album,
 			artistID,
-			s.lastModified,
+			s.getLastModified(),
 		))
 	}
 
@@ -95,6 +110,10 @@ func (s *subsonic) getAlbumDirectory(
 	req *http.Request,
 	albumID int64,
 ) (xsdDirectory, error) {
+	if !s.canAccessAlbum(req.Context(), albumID) {
+		return xsdDirectory{}, library.ErrAlbumNotFound
+	}
+
 	album, err := s.lib.GetAlbum(req.Context(), albumID)
 	if err != nil {
 		return xsdDirectory{}, fmt.Errorf("getting album failed: %w", err)
@@ -120,15 +139,20 @@ func (s *subsonic) getAlbumDirectory(
 			resp.ParentID = artistFSID(track.ArtistID)
 		}
 
-		resp.Children = append(resp.Children, trackToChild(track, s.lastModified))
+		resp.Children = append(resp.Children, trackToChild(track, s.getLastModified()))
 	}
 
 	return resp, nil
 }
 
 func (s *subsonic) getRootDirectory(
-	_ *http.Request,
+	req *http.Request,
 ) (xsdDirectory, error) {
+	allowed, err := s.allowedMusicFolders(req.Context())
+	if err != nil {
+		return xsdDirectory{}, err
+	}
+
 	var (
 		page uint = 0
 		resp      = xsdDirectory{
@@ -139,10 +163,11 @@ func (s *subsonic) getRootDirectory(
 	)
 	for {
 		artists, _ := s.libBrowser.BrowseArtists(library.BrowseArgs{
-			Page:    page,
-			PerPage: 500,
-			Order:   library.OrderAsc,
-			OrderBy: library.OrderByName,
+			Page:           page,
+			PerPage:        500,
+			Order:          library.OrderAsc,
+			OrderBy:        library.OrderByName,
+			MusicFolderIDs: allowedMusicFolderIDs(s.auth, allowed),
 		})
 
 		if len(artists) == 0 {
@@ -166,7 +191,7 @@ func (s *subsonic) getRootDirectory(
 					MediaType:     "artist",
 					DirectoryType: "music",
 					IsDir:         true,
-					Created:       s.lastModified,
+					Created:       s.getLastModified(),
 				},
 			)
 		}
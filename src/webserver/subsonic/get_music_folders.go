@@ -5,16 +5,33 @@ import (
 )
 
 func (s *subsonic) getMusicFolders(w http.ResponseWriter, req *http.Request) {
+	folders, err := s.allowedMusicFolders(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
 	resp := musicFoldersResponse{
 		baseResponse: responseOk(),
-		MusicFolders: musicFolders{
-			Children: []musicFolder{
-				{
-					ID:   combinedMusicFolderID,
-					Name: "Combined Music Library",
-				},
+	}
+
+	for _, folder := range folders {
+		resp.MusicFolders.Children = append(resp.MusicFolders.Children, musicFolder{
+			ID:   folder.ID,
+			Name: folder.Name,
+		})
+	}
+
+	// Without any configured libraries the whole library is still shown as
+	// a single music folder.
+	if len(resp.MusicFolders.Children) == 0 && len(s.auth.MusicFolders) == 0 {
+		resp.MusicFolders.Children = []musicFolder{
+			{
+				ID:   combinedMusicFolderID,
+				Name: "Combined Music Library",
 			},
-		},
+		}
 	}
 
 	encodeResponse(w, req, resp)
@@ -0,0 +1,42 @@
+package subsonic
+
+import (
+	"net/http"
+)
+
+// getNewestPodcasts returns the most recently published podcast episodes. Their
+// number is controlled with the `count` parameter.
+func (s *subsonic) getNewestPodcasts(w http.ResponseWriter, req *http.Request) {
+	count := parseIntOrDefault(req.Form.Get("count"), 20)
+	if count > 500 {
+		count = 500
+	}
+
+	episodes, err := s.podcasts.NewestEpisodes(req.Context(), int(count))
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := newestPodcastsResponse{
+		baseResponse: responseOk(),
+		NewestPodcasts: xsdNewestPodcasts{
+			Episodes: []xsdPodcastEpisode{},
+		},
+	}
+	for _, episode := range episodes {
+		resp.NewestPodcasts.Episodes = append(
+			resp.NewestPodcasts.Episodes,
+			toXsdPodcastEpisode(episode),
+		)
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type newestPodcastsResponse struct {
+	baseResponse
+
+	NewestPodcasts xsdNewestPodcasts `xml:"newestPodcasts" json:"newestPodcasts"`
+}
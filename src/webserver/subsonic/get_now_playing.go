@@ -0,0 +1,36 @@
+package subsonic
+
+import (
+	"net/http"
+	"time"
+)
+
+func (s *subsonic) getNowPlaying(w http.ResponseWriter, req *http.Request) {
+	now := time.Now()
+
+	nowPlaying := xsdNowPlaying{
+		Entries: []xsdNowPlayingEntry{},
+	}
+	for _, entry := range s.nowPlaying.Entries() {
+		nowPlaying.Entries = append(nowPlaying.Entries, xsdNowPlayingEntry{
+			xsdChild:   trackToChild(entry.Track, s.getLastModified()),
+			Username:   entry.User,
+			MinutesAgo: int64(now.Sub(entry.StartedAt).Minutes()),
+			PlayerID:   entry.PlayerID,
+			PlayerName: entry.Client,
+		})
+	}
+
+	resp := nowPlayingResponse{
+		baseResponse: responseOk(),
+		NowPlaying:   nowPlaying,
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type nowPlayingResponse struct {
+	baseResponse
+
+	NowPlaying xsdNowPlaying `xml:"nowPlaying" json:"nowPlaying"`
+}
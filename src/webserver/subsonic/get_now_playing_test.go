@@ -0,0 +1,128 @@
+package subsonic_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestGetNowPlaying checks that scrobbles with submission=false are shown by the
+// /getNowPlaying endpoint and that they do not increase the play count.
+func TestGetNowPlaying(t *testing.T) {
+	lib := &libraryfakes.FakeLibrary{
+		GetTrackStub: func(_ context.Context, id int64) (library.TrackInfo, error) {
+			if id != 10 {
+				return library.TrackInfo{}, library.ErrNotFound
+			}
+			return library.TrackInfo{
+				ID:       10,
+				Title:    "Some Song",
+				Duration: 180000,
+			}, nil
+		},
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User: "test-user",
+			},
+		},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+			NowPlaying: nowplaying.NewRegistry(),
+		},
+	)
+
+	scrobbleURL := "/rest/scrobble?f=json&submission=false&c=%s&id=%d"
+	for _, client := range []string{"first-client", "second-client"} {
+		req := httptest.NewRequest(
+			http.MethodGet,
+			fmt.Sprintf(scrobbleURL, client, int64(2e9+10)),
+			nil,
+		)
+		rec := httptest.NewRecorder()
+		ssHandler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+	}
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf(scrobbleURL, "first-client", int64(2e9+11)),
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	var errResp struct {
+		Subsonic struct {
+			Status string `json:"status"`
+			Error  struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		} `json:"subsonic-response"`
+	}
+	err := json.NewDecoder(rec.Result().Body).Decode(&errResp)
+	assert.NilErr(t, err, "decoding scrobble response")
+	assert.Equal(t, "failed", errResp.Subsonic.Status, "missing track status")
+	assert.Equal(t, 70, errResp.Subsonic.Error.Code, "missing track error code")
+
+	assert.Equal(t, 0, lib.RecordTrackPlayCallCount(), "recorded plays")
+
+	req = httptest.NewRequest(http.MethodGet, "/rest/getNowPlaying?f=json", nil)
+	rec = httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+
+	var resp struct {
+		Subsonic struct {
+			Status     string `json:"status"`
+			NowPlaying struct {
+				Entries []struct {
+					ID         string `json:"id"`
+					Username   string `json:"username"`
+					MinutesAgo int64  `json:"minutesAgo"`
+					PlayerID   int64  `json:"playerId"`
+					PlayerName string `json:"playerName"`
+				} `json:"entry"`
+			} `json:"nowPlaying"`
+		} `json:"subsonic-response"`
+	}
+	err = json.NewDecoder(rec.Result().Body).Decode(&resp)
+	assert.NilErr(t, err, "decoding now playing response")
+	assert.Equal(t, "ok", resp.Subsonic.Status, "response status")
+
+	entries := resp.Subsonic.NowPlaying.Entries
+	assert.Equal(t, 2, len(entries), "number of entries")
+
+	players := make(map[string]int64)
+	for _, entry := range entries {
+		assert.Equal(t, fmt.Sprint(int64(2e9+10)), entry.ID, "track ID")
+		assert.Equal(t, "test-user", entry.Username, "username")
+		assert.Equal(t, 0, entry.MinutesAgo, "minutes ago")
+		players[entry.PlayerName] = entry.PlayerID
+	}
+
+	if len(players) != 2 || players["first-client"] == players["second-client"] {
+		t.Errorf("expected two different players but got %v", players)
+	}
+}
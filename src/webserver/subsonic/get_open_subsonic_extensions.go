@@ -10,6 +10,10 @@ func (s *subsonic) getOpenSubsonicExtensions(w http.ResponseWriter, req *http.Re
 				Name:     "formPost",
 				Versions: []int{1},
 			},
+			{
+				Name:     "indexBasedQueue",
+				Versions: []int{1},
+			},
 		},
 	}
 
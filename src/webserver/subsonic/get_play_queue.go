@@ -0,0 +1,34 @@
+package subsonic
+
+import (
+	"net/http"
+)
+
+func (s *subsonic) getPlayQueue(w http.ResponseWriter, req *http.Request) {
+	queue, err := s.playQueue.Get(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	// No queue has ever been saved. Respond with an empty response as the
+	// reference Subsonic implementation does.
+	if queue.Version == 0 {
+		encodeResponse(w, req, responseOk())
+		return
+	}
+
+	resp := playQueueResponse{
+		baseResponse: responseOk(),
+		PlayQueue:    toXsdPlayQueue(queue, s.auth.User, s.getLastModified()),
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type playQueueResponse struct {
+	baseResponse
+
+	PlayQueue xsdPlayQueue `xml:"playQueue" json:"playQueue"`
+}
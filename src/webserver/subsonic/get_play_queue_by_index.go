@@ -0,0 +1,46 @@
+package subsonic
+
+import (
+	"net/http"
+)
+
+// getPlayQueueByIndex is the OpenSubsonic variant of getPlayQueue which points
+// to the current track with its index in the queue. This way it is not ambiguous
+// when the same track is in the queue more than once.
+func (s *subsonic) getPlayQueueByIndex(w http.ResponseWriter, req *http.Request) {
+	queue, err := s.playQueue.Get(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	if queue.Version == 0 {
+		encodeResponse(w, req, responseOk())
+		return
+	}
+
+	playQueue := toXsdPlayQueue(queue, s.auth.User, s.getLastModified())
+	resp := playQueueByIndexResponse{
+		baseResponse: responseOk(),
+		PlayQueue: xsdPlayQueueByIndex{
+			Position:  playQueue.Position,
+			Username:  playQueue.Username,
+			Changed:   playQueue.Changed,
+			ChangedBy: playQueue.ChangedBy,
+			Entries:   playQueue.Entries,
+		},
+	}
+	if _, ok := queue.Current(); ok {
+		currentIndex := queue.CurrentIndex
+		resp.PlayQueue.CurrentIndex = &currentIndex
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type playQueueByIndexResponse struct {
+	baseResponse
+
+	PlayQueue xsdPlayQueueByIndex `xml:"playQueueByIndex" json:"playQueueByIndex"`
+}
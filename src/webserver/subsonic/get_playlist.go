@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ironsmile/euterpe/src/playlists"
 )
@@ -24,7 +25,7 @@ func (s *subsonic) getPlaylist(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	playlist, err := s.playlists.Get(req.Context(), playlistID)
+	playlist, err := s.playlists.Get(req.Context(), playlistID, s.auth.User)
 	if err != nil && errors.Is(err, playlists.ErrNotFound) {
 		resp := responseError(errCodeNotFound, "playlist not found")
 		encodeResponse(w, req, resp)
@@ -38,9 +39,19 @@ func (s *subsonic) getPlaylist(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	accessible := s.accessibleTracks(req.Context(), playlist.Tracks)
+	if len(accessible) != len(playlist.Tracks) {
+		playlist.Tracks = accessible
+		playlist.TracksCount = int64(len(accessible))
+		playlist.Duration = 0
+		for _, track := range accessible {
+			playlist.Duration += time.Duration(track.Duration) * time.Millisecond
+		}
+	}
+
 	resp := playlistWithSongsResponse{
 		baseResponse: responseOk(),
-		Playlist:     toXsdPlaylistWithSongs(playlist, s.auth.User, s.lastModified),
+		Playlist:     toXsdPlaylistWithSongs(playlist, s.auth.User, s.getLastModified()),
 	}
 
 	encodeResponse(w, req, resp)
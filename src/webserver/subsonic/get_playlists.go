@@ -15,6 +15,7 @@ func (s *subsonic) getPlaylists(w http.ResponseWriter, req *http.Request) {
 	}
 
 	playlists, err := s.playlists.List(req.Context(), playlists.ListArgs{
+		User:   s.auth.User,
 		Offset: 0,
 		Count:  0, // 0 means "all"
 	})
@@ -0,0 +1,67 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/podcasts"
+)
+
+// getPodcasts returns all podcast channels or only the one with the `id`
+// parameter. Episodes are included unless `includeEpisodes` is false.
+func (s *subsonic) getPodcasts(w http.ResponseWriter, req *http.Request) {
+	includeEpisodes := req.Form.Get("includeEpisodes") != "false"
+
+	var (
+		channels []podcasts.Channel
+		err      error
+	)
+	if idString := req.Form.Get("id"); idString != "" {
+		channelID, parseErr := toPodcastChannelDBID(idString)
+		if parseErr != nil {
+			resp := responseError(errCodeNotFound, "podcast channel not found")
+			encodeResponse(w, req, resp)
+			return
+		}
+
+		var channel podcasts.Channel
+		channel, err = s.podcasts.Channel(req.Context(), channelID)
+		if !includeEpisodes {
+			channel.Episodes = nil
+		}
+		channels = []podcasts.Channel{channel}
+	} else {
+		channels, err = s.podcasts.Channels(req.Context(), includeEpisodes)
+	}
+
+	if errors.Is(err, podcasts.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "podcast channel not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := podcastsResponse{
+		baseResponse: responseOk(),
+		Podcasts: xsdPodcasts{
+			Channels: []xsdPodcastChannel{},
+		},
+	}
+	for _, channel := range channels {
+		resp.Podcasts.Channels = append(
+			resp.Podcasts.Channels,
+			toXsdPodcastChannel(channel),
+		)
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type podcastsResponse struct {
+	baseResponse
+
+	Podcasts xsdPodcasts `xml:"podcasts" json:"podcasts"`
+}
@@ -13,19 +13,24 @@ func (s *subsonic) getRandomSongs(w http.ResponseWriter, req *http.Request) {
 	genre := req.URL.Query().Get("genre")
 	fromYear := req.URL.Query().Get("fromYear")
 	toYear := req.URL.Query().Get("toYear")
-	musicFolderID := req.URL.Query().Get("musicFolderID")
 
 	// Ignored search filters:
-	_ = musicFolderID
 	_ = genre
 
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
 	if size > 500 {
 		size = 500
 	}
 
 	browseArgs := library.BrowseArgs{
-		OrderBy: library.OrderByRandom,
-		PerPage: uint(size),
+		OrderBy:        library.OrderByRandom,
+		PerPage:        uint(size),
+		MusicFolderIDs: musicFolderIDs,
 	}
 
 	if fromYear != "" {
@@ -0,0 +1,55 @@
+package subsonic
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+func (s *subsonic) getShares(w http.ResponseWriter, req *http.Request) {
+	sharesList, err := s.shares.List(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := sharesResponse{
+		baseResponse: responseOk(),
+	}
+
+	for _, share := range sharesList {
+		resp.Shares.Children = append(
+			resp.Shares.Children,
+			s.toXsdShare(req, share),
+		)
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+// toXsdShare converts share to its subsonic representation. The public URL of the
+// share is built using the scheme and host from the request. Shares created before
+// shares had owners are shown as owned by the configured user.
+func (s *subsonic) toXsdShare(req *http.Request, share shares.Share) xsdShare {
+	shareURL := url.URL{
+		Scheme: webutils.RequestScheme(req),
+		Host:   webutils.RequestHost(req),
+		Path:   "/share/" + share.ID,
+	}
+
+	owner := share.Owner
+	if owner == "" {
+		owner = s.auth.User
+	}
+
+	return toXsdShare(share, shareURL, owner, s.getLastModified())
+}
+
+type sharesResponse struct {
+	baseResponse
+
+	Shares xsdShares `xml:"shares" json:"shares"`
+}
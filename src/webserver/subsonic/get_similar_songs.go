@@ -0,0 +1,109 @@
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/similar"
+)
+
+func (s *subsonic) getSimilarSongs(w http.ResponseWriter, req *http.Request) {
+	idString := req.Form.Get("id")
+	if idString == "" {
+		resp := responseError(errCodeMissingParameter, "ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		resp := responseError(errCodeNotFound, "not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	args := similar.Args{
+		Count: int(parseIntOrDefault(req.Form.Get("count"), 50)),
+	}
+
+	ctx := req.Context()
+	switch {
+	case isTrackID(id):
+		_, err = s.lib.GetTrack(ctx, toTrackDBID(id))
+		args.TrackIDs = []int64{toTrackDBID(id)}
+	case isArtistID(id):
+		_, err = s.lib.GetArtist(ctx, toArtistDBID(id))
+		args.ArtistIDs = []int64{toArtistDBID(id)}
+	case isAlbumID(id):
+		_, err = s.lib.GetAlbum(ctx, toAlbumDBID(id))
+		args.AlbumIDs = []int64{toAlbumDBID(id)}
+	default:
+		err = library.ErrNotFound
+	}
+	if errors.Is(err, library.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	songs, err := s.similarSongs(ctx, req, args)
+	if errors.Is(err, errMusicFolderNotFound) {
+		musicFoldersError(w, req, err)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := similarSongsResponse{
+		baseResponse: responseOk(),
+		SimilarSongs: songs,
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+// similarSongs returns the songs which are similar to the seeds in args. Only
+// songs from the music folders which the user could access are returned.
+func (s *subsonic) similarSongs(
+	ctx context.Context,
+	req *http.Request,
+	args similar.Args,
+) (xsdSongs, error) {
+	var songs xsdSongs
+
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		return songs, err
+	}
+	args.MusicFolderIDs = musicFolderIDs
+
+	if args.Count > 500 {
+		args.Count = 500
+	}
+
+	tracks, err := s.similar.SimilarTracks(ctx, args)
+	if err != nil {
+		return songs, err
+	}
+
+	for _, track := range tracks {
+		songs.Songs = append(songs.Songs, trackToChild(track, s.getLastModified()))
+	}
+
+	return songs, nil
+}
+
+type similarSongsResponse struct {
+	baseResponse
+
+	SimilarSongs xsdSongs `xml:"similarSongs" json:"similarSongs"`
+}
@@ -0,0 +1,64 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/similar"
+)
+
+func (s *subsonic) getSimilarSongs2(w http.ResponseWriter, req *http.Request) {
+	idString := req.Form.Get("id")
+	if idString == "" {
+		resp := responseError(errCodeMissingParameter, "artist ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	subsonicID, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil || !isArtistID(subsonicID) {
+		resp := responseError(errCodeNotFound, "artist not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	artistID := toArtistDBID(subsonicID)
+	_, err = s.lib.GetArtist(req.Context(), artistID)
+	if errors.Is(err, library.ErrArtistNotFound) {
+		resp := responseError(errCodeNotFound, "artist not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	songs, err := s.similarSongs(req.Context(), req, similar.Args{
+		ArtistIDs: []int64{artistID},
+		Count:     int(parseIntOrDefault(req.Form.Get("count"), 50)),
+	})
+	if errors.Is(err, errMusicFolderNotFound) {
+		musicFoldersError(w, req, err)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := similarSongs2Response{
+		baseResponse:  responseOk(),
+		SimilarSongs2: songs,
+	}
+
+	encodeResponse(w, req, resp)
+}
+
+type similarSongs2Response struct {
+	baseResponse
+
+	SimilarSongs2 xsdSongs `xml:"similarSongs2" json:"similarSongs2"`
+}
@@ -2,9 +2,11 @@ package subsonic
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/ironsmile/euterpe/src/bookmarks"
 	"github.com/ironsmile/euterpe/src/library"
 )
 
@@ -20,15 +22,25 @@ func (s *subsonic) getSong(w http.ResponseWriter, req *http.Request) {
 	trackID := toTrackDBID(subsonicID)
 
 	track, err := s.lib.GetTrack(req.Context(), trackID)
-	if errors.Is(err, library.ErrNotFound) {
+	if errors.Is(err, library.ErrNotFound) || !s.canAccessTrack(req.Context(), trackID) {
 		resp := responseError(errCodeNotFound, "song not found")
 		encodeResponse(w, req, resp)
 		return
 	}
 
+	song := trackToChild(track, s.getLastModified())
+	if s.bookmarks != nil {
+		bookmark, err := s.bookmarks.Get(req.Context(), trackID)
+		if err == nil {
+			song.BookmarkPosition = bookmark.Position
+		} else if !errors.Is(err, bookmarks.ErrNotFound) {
+			log.Printf("error getting bookmark for track %d: %s", trackID, err)
+		}
+	}
+
 	resp := songResponse{
 		baseResponse: responseOk(),
-		Song:         trackToChild(track, s.lastModified),
+		Song:         song,
 	}
 
 	encodeResponse(w, req, resp)
@@ -7,14 +7,21 @@ import (
 )
 
 func (s *subsonic) getStarred2(w http.ResponseWriter, req *http.Request) {
+    musicFolderIDs, err := s.requestMusicFolders(req)
+    if err != nil {
+        musicFoldersError(w, req, err)
+        return
+    }
+
     resp := starred2Response{
         baseResponse: responseOk(),
     }
 
     browseArgs := library.BrowseArgs{
-        PerPage: 500,
-        Order:   library.OrderDesc,
-        OrderBy: library.OrderByFavourites,
+        PerPage:        500,
+        Order:          library.OrderDesc,
+        OrderBy:        library.OrderByFavourites,
+        MusicFolderIDs: musicFolderIDs,
     }
 
     artURL, query := s.getAristImageURL(req, 0)
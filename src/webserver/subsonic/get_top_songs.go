@@ -19,11 +19,18 @@ func (s *subsonic) getTopSongs(w http.ResponseWriter, req *http.Request) {
 		count = 500
 	}
 
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
 	artists := s.lib.SearchArtists(
 		req.Context(),
 		library.SearchArgs{
-			Query: artistName,
-			Count: 10,
+			Query:          artistName,
+			Count:          10,
+			MusicFolderIDs: musicFolderIDs,
 		},
 	)
 
@@ -46,10 +53,11 @@ func (s *subsonic) getTopSongs(w http.ResponseWriter, req *http.Request) {
 	}
 
 	topSongs, _ := s.libBrowser.BrowseTracks(library.BrowseArgs{
-		OrderBy:  library.OrderByFrequentlyPlayed,
-		Order:    library.OrderDesc,
-		PerPage:  uint(count),
-		ArtistID: artist.ID,
+		OrderBy:        library.OrderByFrequentlyPlayed,
+		Order:          library.OrderDesc,
+		PerPage:        uint(count),
+		ArtistID:       artist.ID,
+		MusicFolderIDs: musicFolderIDs,
 	})
 
 	resp := topSonxResponse{
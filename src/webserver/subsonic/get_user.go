@@ -16,6 +16,21 @@ func (s *subsonic) getUser(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	folders, err := s.allowedMusicFolders(req.Context())
+	if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	var folderIDs []int64
+	for _, folder := range folders {
+		folderIDs = append(folderIDs, folder.ID)
+	}
+	if len(folderIDs) == 0 && len(s.auth.MusicFolders) == 0 {
+		folderIDs = []int64{combinedMusicFolderID}
+	}
+
 	resp := getUserResponse{
 		baseResponse: responseOk(),
 
@@ -33,9 +48,7 @@ func (s *subsonic) getUser(w http.ResponseWriter, req *http.Request) {
 			StreamRole:   true,
 			JukeboxRole:  true,
 			ShareRole:    true,
-			Folders: []int64{
-				combinedMusicFolderID,
-			},
+			Folders:      folderIDs,
 		},
 	}
 
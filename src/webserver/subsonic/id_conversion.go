@@ -3,6 +3,7 @@ package subsonic
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -76,18 +77,37 @@ func albumConverArtID(albumID int64) string {
 	return fmt.Sprintf("%s%d", coverAlbumPrefix, albumID)
 }
 
-// musicFolderExists returns true if musicFolderID is a real music folder
-// which could be found on the server.
-//
-// For the moment the only possible value is the string representation
-// of the combaindMusicFolderID.
-func musicFolderExists(musicFolderID string) bool {
-	combindIDstr := strconv.FormatInt(combinedMusicFolderID, 10)
-	return combindIDstr == musicFolderID
-}
-
 const (
 	coverAlbumPrefix    = "al-"
 	coverArtistPrefix   = "ar-"
 	coverPlaylistPrefix = "pl-"
 )
+
+// podcastChannelID converts the ID of a podcast channel in the database to its ID
+// in the exposed subsonic API. It is also the ID of the channel's cover image.
+func podcastChannelID(channelID int64) string {
+	return fmt.Sprintf("%s%d", podcastChannelPrefix, channelID)
+}
+
+// toPodcastChannelDBID converts a subsonic podcast channel ID back to the ID
+// of the channel in the database.
+func toPodcastChannelDBID(id string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(id, podcastChannelPrefix), 10, 64)
+}
+
+// podcastEpisodeID converts the ID of a podcast episode in the database to its ID
+// in the exposed subsonic API. The same ID is used for streaming the episode.
+func podcastEpisodeID(episodeID int64) string {
+	return fmt.Sprintf("%s%d", podcastEpisodePrefix, episodeID)
+}
+
+// toPodcastEpisodeDBID converts a subsonic podcast episode ID back to the ID
+// of the episode in the database.
+func toPodcastEpisodeDBID(id string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(id, podcastEpisodePrefix), 10, 64)
+}
+
+const (
+	podcastChannelPrefix = "pc-"
+	podcastEpisodePrefix = "pe-"
+)
@@ -5,10 +5,17 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/ironsmile/euterpe/src/bookmarks"
 	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/podcasts"
 	"github.com/ironsmile/euterpe/src/radio"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
 )
 
 type subsonic struct {
@@ -17,15 +24,23 @@ type subsonic struct {
 	lib        library.Library
 	radio      radio.Stations
 	playlists  playlists.Playlister
+	shares     shares.Sharer
+	similar    similar.Finder
+	bookmarks  bookmarks.Bookmarker
+	playQueue  playqueue.Queuer
+	scanner    library.Scanner
+	podcasts   podcasts.Podcaster
+	avatars    library.AvatarManager
 	needsAuth  bool
 	auth       config.Auth
 
-	albumArtHandler  CoverArtHandler
-	artistArtHandler CoverArtHandler
+	loginAttempts  *loginattempts.Tracker
+	trustedProxies config.CIDRList
+	nowPlaying     *nowplaying.Registry
 
-	//!TODO: track real lastModified centrally. On every insert or
-	// delete in the database.
-	lastModified time.Time
+	albumArtHandler    CoverArtHandler
+	artistArtHandler   CoverArtHandler
+	playlistArtHandler CoverArtHandler
 
 	mux http.Handler
 }
@@ -33,30 +48,55 @@ type subsonic struct {
 // Prefix is the URL path prefix for all subsonic API endpoints.
 const Prefix = "/rest"
 
+// Deps are the dependencies of the subsonic API handler. Library, Browser,
+// AlbumArt and ArtistArt are required. Everything else is optional. The endpoints
+// which need a missing dependency respond with an error and the IDs which only it
+// could resolve are not found.
+type Deps struct {
+	Library   library.Library
+	Browser   library.Browser
+	AlbumArt  CoverArtHandler
+	ArtistArt CoverArtHandler
+
+	PlaylistArt   CoverArtHandler
+	Stations      radio.Stations
+	Playlister    playlists.Playlister
+	LoginAttempts *loginattempts.Tracker
+	Sharer        shares.Sharer
+	SimilarFinder similar.Finder
+	NowPlaying    *nowplaying.Registry
+	Bookmarker    bookmarks.Bookmarker
+	Queuer        playqueue.Queuer
+	Scanner       library.Scanner
+	Podcaster     podcasts.Podcaster
+	Avatars       library.AvatarManager
+}
+
 // NewHandler returns a HTTP handler which would serve the subsonic API
 // (https://www.subsonic.org/pages/api.jsp). Endpoints are served after
 // the `prefix` URL path.
-func NewHandler(
-	prefix string,
-	lib library.Library,
-	libBrowser library.Browser,
-	stations radio.Stations,
-	playlister playlists.Playlister,
-	cfg config.Config,
-	albumArt CoverArtHandler,
-	artistArt CoverArtHandler,
-) http.Handler {
+func NewHandler(prefix string, cfg config.Config, deps Deps) http.Handler {
 	handler := &subsonic{
-		prefix:           prefix,
-		lib:              lib,
-		libBrowser:       libBrowser,
-		radio:            stations,
-		playlists:        playlister,
-		needsAuth:        cfg.Auth,
-		auth:             cfg.Authenticate,
-		albumArtHandler:  albumArt,
-		artistArtHandler: artistArt,
-		lastModified:     time.Now(),
+		prefix:             prefix,
+		lib:                deps.Library,
+		libBrowser:         deps.Browser,
+		radio:              deps.Stations,
+		playlists:          deps.Playlister,
+		shares:             deps.Sharer,
+		similar:            deps.SimilarFinder,
+		bookmarks:          deps.Bookmarker,
+		playQueue:          deps.Queuer,
+		scanner:            deps.Scanner,
+		podcasts:           deps.Podcaster,
+		avatars:            deps.Avatars,
+		needsAuth:          cfg.Auth,
+		auth:               cfg.Authenticate,
+		loginAttempts:      deps.LoginAttempts,
+		trustedProxies:     cfg.TrustedProxies,
+		nowPlaying:         deps.NowPlaying,
+		albumArtHandler:    deps.AlbumArt,
+		artistArtHandler:   deps.ArtistArt,
+		playlistArtHandler: deps.PlaylistArt,
 	}
 
 	handler.initRouter()
@@ -84,6 +124,29 @@ func (s *subsonic) initRouter() {
 		).Methods(methods...)
 	}
 
+	// requires returns the handler only when its feature is available. Otherwise
+	// it returns one which responds with an error.
+	requires := func(available bool, feature string, handler http.HandlerFunc) http.HandlerFunc {
+		if available {
+			return handler
+		}
+		return func(w http.ResponseWriter, req *http.Request) {
+			resp := responseError(errCodeGeneric, feature+" are not available")
+			encodeResponse(w, req, resp)
+		}
+	}
+	var (
+		hasAvatars   = s.avatars != nil
+		hasSimilar   = s.similar != nil
+		hasBookmarks = s.bookmarks != nil
+		hasPlayQueue = s.playQueue != nil
+		hasScanner   = s.scanner != nil
+		hasPodcasts  = s.podcasts != nil
+		hasRadio     = s.radio != nil
+		hasPlaylists = s.playlists != nil
+		hasShares    = s.shares != nil
+	)
+
 	setUpHandler("/ping", s.apiPing)
 	setUpHandler("/getLicense", s.getLicense)
 	setUpHandler("/getOpenSubsonicExtensions", s.getOpenSubsonicExtensions)
@@ -97,7 +160,10 @@ func (s *subsonic) initRouter() {
 	setUpHandler("/getArtist", s.getArtist)
 	setUpHandler("/getArtistInfo", s.getArtistInfo)
 	setUpHandler("/getArtistInfo2", s.getArtistInfo2)
+	setUpHandler("/getSimilarSongs", requires(hasSimilar, "similar songs", s.getSimilarSongs))
+	setUpHandler("/getSimilarSongs2", requires(hasSimilar, "similar songs", s.getSimilarSongs2))
 	setUpHandler("/getCoverArt", s.getCoverArt, "GET", "HEAD")
+	setUpHandler("/getAvatar", requires(hasAvatars, "avatars", s.getAvatar), "GET", "HEAD")
 	setUpHandler("/stream", s.stream, "GET", "HEAD")
 	setUpHandler("/download", s.stream, "GET", "HEAD")
 	setUpHandler("/getSong", s.getSong)
@@ -108,6 +174,23 @@ func (s *subsonic) initRouter() {
 	setUpHandler("/search2", s.search2)
 	setUpHandler("/search", s.search)
 	setUpHandler("/scrobble", s.scrobble)
+	setUpHandler("/getNowPlaying", s.getNowPlaying)
+	setUpHandler("/getBookmarks", requires(hasBookmarks, "bookmarks", s.getBookmarks))
+	setUpHandler("/createBookmark", requires(hasBookmarks, "bookmarks", s.createBookmark))
+	setUpHandler("/deleteBookmark", requires(hasBookmarks, "bookmarks", s.deleteBookmark))
+	setUpHandler("/getPlayQueue", requires(hasPlayQueue, "play queues", s.getPlayQueue))
+	setUpHandler("/savePlayQueue", requires(hasPlayQueue, "play queues", s.savePlayQueue))
+	setUpHandler("/getPlayQueueByIndex", requires(hasPlayQueue, "play queues", s.getPlayQueueByIndex))
+	setUpHandler("/savePlayQueueByIndex", requires(hasPlayQueue, "play queues", s.savePlayQueueByIndex))
+	setUpHandler("/getScanStatus", requires(hasScanner, "library scans", s.getScanStatus))
+	setUpHandler("/startScan", requires(hasScanner, "library scans", s.startScan))
+	setUpHandler("/getPodcasts", requires(hasPodcasts, "podcasts", s.getPodcasts))
+	setUpHandler("/getNewestPodcasts", requires(hasPodcasts, "podcasts", s.getNewestPodcasts))
+	setUpHandler("/refreshPodcasts", requires(hasPodcasts, "podcasts", s.refreshPodcasts))
+	setUpHandler("/createPodcastChannel", requires(hasPodcasts, "podcasts", s.createPodcastChannel))
+	setUpHandler("/deletePodcastChannel", requires(hasPodcasts, "podcasts", s.deletePodcastChannel))
+	setUpHandler("/deletePodcastEpisode", requires(hasPodcasts, "podcasts", s.deletePodcastEpisode))
+	setUpHandler("/downloadPodcastEpisode", requires(hasPodcasts, "podcasts", s.downloadPodcastEpisode))
 	setUpHandler("/setRating", s.setRating)
 	setUpHandler("/star", s.star)
 	setUpHandler("/unstar", s.unstar)
@@ -116,17 +199,21 @@ func (s *subsonic) initRouter() {
 	setUpHandler("/getTopSongs", s.getTopSongs)
 	setUpHandler("/getAlbumInfo", s.getAlbumInfo)
 	setUpHandler("/getAlbumInfo2", s.getAlbumInfo2)
-	setUpHandler("/getInternetRadioStations", s.getInternetRadionStations)
-	setUpHandler("/createInternetRadioStation", s.createInternetRadioStation)
-	setUpHandler("/updateInternetRadioStation", s.updateInternetRadioStation)
-	setUpHandler("/deleteInternetRadioStation", s.deleteInternetRadioStation)
+	setUpHandler("/getInternetRadioStations", requires(hasRadio, "internet radio stations", s.getInternetRadionStations))
+	setUpHandler("/createInternetRadioStation", requires(hasRadio, "internet radio stations", s.createInternetRadioStation))
+	setUpHandler("/updateInternetRadioStation", requires(hasRadio, "internet radio stations", s.updateInternetRadioStation))
+	setUpHandler("/deleteInternetRadioStation", requires(hasRadio, "internet radio stations", s.deleteInternetRadioStation))
 	setUpHandler("/getUser", s.getUser)
 	setUpHandler("/getRandomSongs", s.getRandomSongs)
-	setUpHandler("/createPlaylist", s.createPlaylist)
-	setUpHandler("/getPlaylist", s.getPlaylist)
-	setUpHandler("/getPlaylists", s.getPlaylists)
-	setUpHandler("/deletePlaylist", s.deletePlaylist)
-	setUpHandler("/updatePlaylist", s.updatePlaylist)
+	setUpHandler("/createPlaylist", requires(hasPlaylists, "playlists", s.createPlaylist))
+	setUpHandler("/getPlaylist", requires(hasPlaylists, "playlists", s.getPlaylist))
+	setUpHandler("/getPlaylists", requires(hasPlaylists, "playlists", s.getPlaylists))
+	setUpHandler("/deletePlaylist", requires(hasPlaylists, "playlists", s.deletePlaylist))
+	setUpHandler("/updatePlaylist", requires(hasPlaylists, "playlists", s.updatePlaylist))
+	setUpHandler("/getShares", requires(hasShares, "shares", s.getShares))
+	setUpHandler("/createShare", requires(hasShares, "shares", s.createShare))
+	setUpHandler("/updateShare", requires(hasShares, "shares", s.updateShare))
+	setUpHandler("/deleteShare", requires(hasShares, "shares", s.deleteShare))
 
 	s.mux = s.authHandler(router)
 }
@@ -135,6 +222,7 @@ func (s *subsonic) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	s.mux.ServeHTTP(w, req)
 }
 
+// getLastModified returns the last time the content of the library was changed.
 func (s *subsonic) getLastModified() time.Time {
-	return s.lastModified
+	return s.libBrowser.LastModified()
 }
@@ -0,0 +1,107 @@
+package subsonic_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestNewHandlerOptionalDeps checks that the handler works with only its required
+// dependencies. Endpoints which need a missing one respond with an error instead
+// of panicking.
+func TestNewHandlerOptionalDeps(t *testing.T) {
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:   &libraryfakes.FakeLibrary{},
+			Browser:   &libraryfakes.FakeBrowser{},
+			AlbumArt:  &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt: &subsonicfakes.FakeCoverArtHandler{},
+		},
+	)
+
+	unavailable := []string{
+		"/getAvatar?username=test-user",
+		"/getSimilarSongs?id=1",
+		"/getBookmarks",
+		"/getPlayQueue",
+		"/getScanStatus",
+		"/getPodcasts",
+		"/getInternetRadioStations",
+		"/getPlaylists",
+		"/getShares",
+	}
+	for _, url := range unavailable {
+		t.Run(url, func(t *testing.T) {
+			resp := getSubsonicJSON(t, ssHandler, url)
+			assert.Equal(t, "failed", resp.Response.Status, "response status")
+			assert.Equal(t, 0, resp.Response.Error.Code, "error code")
+		})
+	}
+
+	available := []string{
+		fmt.Sprintf("/getSong?id=%d", int64(2e9+10)),
+		fmt.Sprintf("/getArtistInfo?id=%d", int64(1e9+5)),
+		"/ping",
+	}
+	for _, url := range available {
+		t.Run(url, func(t *testing.T) {
+			resp := getSubsonicJSON(t, ssHandler, url)
+			assert.Equal(t, "ok", resp.Response.Status, "response status")
+		})
+	}
+
+	resp := getSubsonicJSON(t, ssHandler, "/stream?id=pe-3")
+	assert.Equal(t, 70, resp.Response.Error.Code, "podcast episode stream error code")
+
+	for _, id := range []string{"pl-2", "pc-3"} {
+		req := httptest.NewRequest(
+			http.MethodGet,
+			subsonic.Prefix+"/getCoverArt?id="+id,
+			nil,
+		)
+		rec := httptest.NewRecorder()
+		ssHandler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "cover art status for "+id)
+	}
+}
+
+type subsonicJSONResponse struct {
+	Response struct {
+		Status string `json:"status"`
+		Error  struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	} `json:"subsonic-response"`
+}
+
+// getSubsonicJSON makes a request for `url` with the JSON format and returns the
+// decoded response.
+func getSubsonicJSON(t *testing.T, handler http.Handler, url string) subsonicJSONResponse {
+	t.Helper()
+
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+
+	req := httptest.NewRequest(http.MethodGet, subsonic.Prefix+url+sep+"f=json", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp subsonicJSONResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NilErr(t, err, "decoding response for "+url)
+
+	return resp
+}
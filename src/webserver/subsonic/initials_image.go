@@ -0,0 +1,112 @@
+package subsonic
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// initialsImageSize is the width and height in pixels of the generated avatars.
+const initialsImageSize = 256
+
+// initialsBackgrounds is the palette from which the background colour of the
+// generated avatars is chosen. All colours are dark enough for white text.
+var initialsBackgrounds = []color.RGBA{
+	{R: 0xc6, G: 0x28, B: 0x28, A: 0xff},
+	{R: 0xad, G: 0x14, B: 0x57, A: 0xff},
+	{R: 0x6a, G: 0x1b, B: 0x9a, A: 0xff},
+	{R: 0x45, G: 0x27, B: 0xa0, A: 0xff},
+	{R: 0x28, G: 0x35, B: 0x93, A: 0xff},
+	{R: 0x15, G: 0x65, B: 0xc0, A: 0xff},
+	{R: 0x00, G: 0x83, B: 0x8f, A: 0xff},
+	{R: 0x00, G: 0x69, B: 0x5c, A: 0xff},
+	{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff},
+	{R: 0xe6, G: 0x51, B: 0x00, A: 0xff},
+	{R: 0x4e, G: 0x34, B: 0x2e, A: 0xff},
+	{R: 0x37, G: 0x47, B: 0x4f, A: 0xff},
+}
+
+// initialsImage returns a PNG image with the initials of `name` drawn over a
+// background. The background colour depends only on the name so that the same
+// user always gets the same image.
+func initialsImage(name string) ([]byte, error) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	bg := initialsBackgrounds[hash.Sum32()%uint32(len(initialsBackgrounds))]
+
+	img := image.NewRGBA(image.Rect(0, 0, initialsImageSize, initialsImageSize))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: bg}, image.Point{}, draw.Src)
+
+	fnt, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font: %w", err)
+	}
+
+	face, err := opentype.NewFace(fnt, &opentype.FaceOptions{
+		Size:    initialsImageSize * 0.4,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating font face: %w", err)
+	}
+	defer face.Close()
+
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.White,
+		Face: face,
+	}
+
+	text := nameInitials(name)
+	bounds, _ := drawer.BoundString(text)
+	textWidth := bounds.Max.X - bounds.Min.X
+	textHeight := bounds.Max.Y - bounds.Min.Y
+	center := fixed.I(initialsImageSize / 2)
+	drawer.Dot = fixed.Point26_6{
+		X: center - textWidth/2 - bounds.Min.X,
+		Y: center + textHeight/2 - bounds.Max.Y,
+	}
+	drawer.DrawString(text)
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		return nil, fmt.Errorf("encoding PNG: %w", err)
+	}
+
+	return buff.Bytes(), nil
+}
+
+// nameInitials returns up to two upper case letters for `name`. These are the
+// first letters of its first two words. Names such as "john.smith" or
+// "john_smith" are split into words too.
+func nameInitials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var initials []rune
+	for _, word := range words {
+		initials = append(initials, unicode.ToUpper([]rune(word)[0]))
+		if len(initials) == 2 {
+			break
+		}
+	}
+
+	if len(initials) == 0 {
+		return "?"
+	}
+
+	return string(initials)
+}
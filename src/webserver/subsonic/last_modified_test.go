@@ -0,0 +1,156 @@
+package subsonic_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestLibraryLastModified checks that getIndexes and getArtists use the library
+// modification time for the ifModifiedSince parameter and that search passes
+// newerThan to the library.
+func TestLibraryLastModified(t *testing.T) {
+	lastModified := time.UnixMilli(1714856348123)
+
+	lib := &libraryfakes.FakeLibrary{}
+	browser := &libraryfakes.FakeBrowser{
+		BrowseArtistsStub: func(_ library.BrowseArgs) ([]library.Artist, int) {
+			return []library.Artist{
+				{ID: 1, Name: "First Artist"},
+			}, 1
+		},
+		LastModifiedStub: func() time.Time {
+			return lastModified
+		},
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    browser,
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+		},
+	)
+
+	tests := []struct {
+		desc            string
+		url             string
+		expectedArtists int
+	}{
+		{
+			desc:            "indexes without ifModifiedSince",
+			url:             "/getIndexes?f=json",
+			expectedArtists: 1,
+		},
+		{
+			desc: "indexes modified since",
+			url: fmt.Sprintf(
+				"/getIndexes?f=json&ifModifiedSince=%d",
+				lastModified.UnixMilli()-1,
+			),
+			expectedArtists: 1,
+		},
+		{
+			desc: "indexes not modified since",
+			url: fmt.Sprintf(
+				"/getIndexes?f=json&ifModifiedSince=%d",
+				lastModified.UnixMilli(),
+			),
+			expectedArtists: 0,
+		},
+		{
+			desc: "artists modified since",
+			url: fmt.Sprintf(
+				"/getArtists?f=json&ifModifiedSince=%d",
+				lastModified.UnixMilli()-1,
+			),
+			expectedArtists: 1,
+		},
+		{
+			desc: "artists not modified since",
+			url: fmt.Sprintf(
+				"/getArtists?f=json&ifModifiedSince=%d",
+				lastModified.UnixMilli(),
+			),
+			expectedArtists: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, subsonic.Prefix+test.url, nil)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, "HTTP status code")
+
+			type indexes struct {
+				LastModified int64 `json:"lastModified"`
+				Index        []struct {
+					Artist []json.RawMessage `json:"artist"`
+				} `json:"index"`
+			}
+			var resp struct {
+				Response struct {
+					Status  string  `json:"status"`
+					Indexes indexes `json:"indexes"`
+					Artists indexes `json:"artists"`
+				} `json:"subsonic-response"`
+			}
+			assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &resp), "decoding JSON")
+			assert.Equal(t, "ok", resp.Response.Status, "response status")
+
+			found := resp.Response.Indexes
+			if strings.HasPrefix(test.url, "/getArtists") {
+				found = resp.Response.Artists
+			}
+
+			assert.Equal(t, lastModified.UnixMilli(), found.LastModified, "lastModified")
+
+			var artists int
+			for _, index := range found.Index {
+				artists += len(index.Artist)
+			}
+			assert.Equal(t, test.expectedArtists, artists, "number of artists")
+		})
+	}
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+"/search?f=json&any=baba&newerThan=1714856348000",
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "search HTTP status code")
+	assert.Equal(t, 1, lib.SearchCallCount(), "number of Search calls")
+
+	_, args := lib.SearchArgsForCall(0)
+	expectedNewerThan := time.UnixMilli(1714856348000)
+	if !args.NewerThan.Equal(expectedNewerThan) {
+		t.Errorf("expected search newerThan `%s` but got `%s`",
+			expectedNewerThan, args.NewerThan)
+	}
+
+	_, albumArgs := lib.SearchAlbumsArgsForCall(0)
+	if !albumArgs.NewerThan.Equal(expectedNewerThan) {
+		t.Errorf("expected album search newerThan `%s` but got `%s`",
+			expectedNewerThan, albumArgs.NewerThan)
+	}
+}
@@ -0,0 +1,172 @@
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// noMusicFolderID is used for filtering when the user is not allowed to access
+// any of the existing music folders. No music folder has this ID.
+const noMusicFolderID int64 = -1
+
+// errMusicFolderNotFound is returned when a request uses a music folder which
+// does not exist or the user is not allowed to access.
+var errMusicFolderNotFound = errors.New("music folder not found")
+
+// allowedMusicFolders returns the library music folders which the user is
+// allowed to access.
+func (s *subsonic) allowedMusicFolders(ctx context.Context) ([]library.MusicFolder, error) {
+	folders, err := s.lib.MusicFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting music folders: %w", err)
+	}
+
+	var allowed []library.MusicFolder
+	for _, folder := range folders {
+		if s.auth.CanAccessMusicFolder(folder.Name, folder.Path) {
+			allowed = append(allowed, folder)
+		}
+	}
+
+	return allowed, nil
+}
+
+// requestMusicFolders returns the IDs of the music folders which results for req
+// have to be limited to. They are selected by the "musicFolderId" parameter and
+// the music folders the user is allowed to access. An empty list means that no
+// limiting is necessary.
+//
+// errMusicFolderNotFound is returned when "musicFolderId" is not a music folder
+// which the user could access.
+func (s *subsonic) requestMusicFolders(req *http.Request) ([]int64, error) {
+	allowed, err := s.allowedMusicFolders(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	musicFolderID := req.Form.Get("musicFolderId")
+	if musicFolderID == "" ||
+		musicFolderID == strconv.FormatInt(combinedMusicFolderID, 10) {
+		return allowedMusicFolderIDs(s.auth, allowed), nil
+	}
+
+	id, err := strconv.ParseInt(musicFolderID, 10, 64)
+	if err != nil {
+		return nil, errMusicFolderNotFound
+	}
+
+	for _, folder := range allowed {
+		if folder.ID == id {
+			return []int64{id}, nil
+		}
+	}
+
+	return nil, errMusicFolderNotFound
+}
+
+// allowedMusicFolderIDs returns the IDs of the `allowed` music folders for
+// filtering. An empty list is returned when the user is not limited to any
+// music folders and no filtering is necessary.
+func allowedMusicFolderIDs(auth config.Auth, allowed []library.MusicFolder) []int64 {
+	if len(auth.MusicFolders) == 0 {
+		return nil
+	}
+
+	ids := []int64{noMusicFolderID}
+	for _, folder := range allowed {
+		ids = append(ids, folder.ID)
+	}
+	return ids
+}
+
+// canAccessTrack returns true when the track with library ID `trackID` is in
+// a music folder which the user is allowed to access.
+func (s *subsonic) canAccessTrack(ctx context.Context, trackID int64) bool {
+	return s.canAccess(ctx, "track", trackID, s.lib.TrackInMusicFolders)
+}
+
+// canAccessAlbum returns true when the album with library ID `albumID` has
+// tracks in a music folder which the user is allowed to access.
+func (s *subsonic) canAccessAlbum(ctx context.Context, albumID int64) bool {
+	return s.canAccess(ctx, "album", albumID, s.lib.AlbumInMusicFolders)
+}
+
+// canAccessArtist returns true when the artist with library ID `artistID` has
+// tracks in a music folder which the user is allowed to access.
+func (s *subsonic) canAccessArtist(ctx context.Context, artistID int64) bool {
+	return s.canAccess(ctx, "artist", artistID, s.lib.ArtistInMusicFolders)
+}
+
+func (s *subsonic) canAccess(
+	ctx context.Context,
+	kind string,
+	id int64,
+	inMusicFolders func(context.Context, int64, []int64) (bool, error),
+) bool {
+	if len(s.auth.MusicFolders) == 0 {
+		return true
+	}
+
+	allowed, err := s.allowedMusicFolders(ctx)
+	if err != nil {
+		log.Printf("error checking music folders of %s %d: %s", kind, id, err)
+		return false
+	}
+
+	found, err := inMusicFolders(ctx, id, allowedMusicFolderIDs(s.auth, allowed))
+	if err != nil {
+		log.Printf("error checking music folders of %s %d: %s", kind, id, err)
+		return false
+	}
+
+	return found
+}
+
+// accessibleTracks returns the tracks which are in music folders the user is
+// allowed to access. Playlist entries for tracks which are missing from the
+// library are kept since they have no files.
+func (s *subsonic) accessibleTracks(
+	ctx context.Context,
+	tracks []library.TrackInfo,
+) []library.TrackInfo {
+	if len(s.auth.MusicFolders) == 0 {
+		return tracks
+	}
+
+	allowed, err := s.allowedMusicFolders(ctx)
+	if err != nil {
+		log.Printf("error checking music folders of tracks: %s", err)
+		return nil
+	}
+	allowedIDs := allowedMusicFolderIDs(s.auth, allowed)
+
+	accessible := make([]library.TrackInfo, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Missing || slices.Contains(allowedIDs, track.MusicFolderID) {
+			accessible = append(accessible, track)
+		}
+	}
+
+	return accessible
+}
+
+// musicFoldersError writes the appropriate response for an error returned by
+// requestMusicFolders.
+func musicFoldersError(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, errMusicFolderNotFound) {
+		resp := responseError(errCodeNotFound, "music folder not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := responseError(errCodeGeneric, err.Error())
+	encodeResponse(w, req, resp)
+}
@@ -0,0 +1,415 @@
+package subsonic_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/bookmarks/bookmarksfakes"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestMusicFolders checks that the library music folders are listed and that
+// the musicFolderId parameter and the folders the user is limited to are used
+// for filtering.
+func TestMusicFolders(t *testing.T) {
+	folders := []library.MusicFolder{
+		{ID: 1, Name: "Main", Path: "/media/Main"},
+		{ID: 2, Name: "Kids", Path: "/media/Kids"},
+		{ID: 3, Name: "Audiobooks", Path: "/media/Audiobooks"},
+	}
+
+	tests := []struct {
+		desc        string
+		allowed     []string
+		url         string
+		errCode     int
+		listed      []int64
+		filteredBy  []int64
+		notFiltered bool
+	}{
+		{
+			desc:   "all music folders are listed",
+			url:    "/rest/getMusicFolders?f=json",
+			listed: []int64{1, 2, 3},
+		},
+		{
+			desc:    "only allowed music folders are listed",
+			allowed: []string{"Kids", "/media/Audiobooks"},
+			url:     "/rest/getMusicFolders?f=json",
+			listed:  []int64{2, 3},
+		},
+		{
+			desc:        "no filtering without musicFolderId",
+			url:         "/rest/getArtists?f=json",
+			notFiltered: true,
+		},
+		{
+			desc:        "no filtering for the combined music folder",
+			url:         "/rest/getArtists?f=json&musicFolderId=1000000000",
+			notFiltered: true,
+		},
+		{
+			desc:       "filtering by musicFolderId",
+			url:        "/rest/getArtists?f=json&musicFolderId=2",
+			filteredBy: []int64{2},
+		},
+		{
+			desc:       "filtering by allowed music folders",
+			allowed:    []string{"Kids"},
+			url:        "/rest/getArtists?f=json",
+			filteredBy: []int64{2},
+		},
+		{
+			desc:    "music folder which is not allowed",
+			allowed: []string{"Kids"},
+			url:     "/rest/getArtists?f=json&musicFolderId=1",
+			errCode: 70,
+		},
+		{
+			desc:    "music folder which does not exist",
+			url:     "/rest/getArtists?f=json&musicFolderId=42",
+			errCode: 70,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			lib := &libraryfakes.FakeLibrary{
+				MusicFoldersStub: func(_ context.Context) ([]library.MusicFolder, error) {
+					return folders, nil
+				},
+			}
+			browser := &libraryfakes.FakeBrowser{}
+
+			ssHandler := subsonic.NewHandler(
+				subsonic.Prefix,
+				config.Config{
+					Authenticate: config.Auth{
+						User:         "test-user",
+						MusicFolders: test.allowed,
+					},
+				},
+				subsonic.Deps{
+					Library:    lib,
+					Browser:    browser,
+					Stations:   &radiofakes.FakeStations{},
+					Playlister: &playlistsfakes.FakePlaylister{},
+					AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+				},
+			)
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			rec := httptest.NewRecorder()
+
+			ssHandler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+
+			var jsonResp musicFoldersRespJSON
+			dec := json.NewDecoder(rec.Result().Body)
+			assert.NilErr(t, dec.Decode(&jsonResp), "error decoding response")
+
+			if test.errCode != 0 {
+				assert.Equal(t, "failed", jsonResp.Subsonic.Status)
+				assert.Equal(t, test.errCode, jsonResp.Subsonic.Error.Code, "error code")
+				return
+			}
+			assert.Equal(t, "ok", jsonResp.Subsonic.Status)
+
+			if test.listed != nil {
+				var listed []int64
+				for _, folder := range jsonResp.Subsonic.MusicFolders.Folders {
+					listed = append(listed, folder.ID)
+				}
+				if !slices.Equal(test.listed, listed) {
+					t.Errorf("expected music folders %v but got %v", test.listed, listed)
+				}
+				return
+			}
+
+			assert.Equal(t, 1, browser.BrowseArtistsCallCount(), "browse artists calls")
+			args := browser.BrowseArtistsArgsForCall(0)
+			if test.notFiltered && len(args.MusicFolderIDs) != 0 {
+				t.Errorf("expected no filtering but got %v", args.MusicFolderIDs)
+			}
+			for _, id := range test.filteredBy {
+				if !slices.Contains(args.MusicFolderIDs, id) {
+					t.Errorf("expected filtering by %d but got %v", id, args.MusicFolderIDs)
+				}
+			}
+			for _, folder := range folders {
+				if slices.Contains(test.filteredBy, folder.ID) {
+					continue
+				}
+				if slices.Contains(args.MusicFolderIDs, folder.ID) {
+					t.Errorf("unexpected filtering by %d", folder.ID)
+				}
+			}
+		})
+	}
+}
+
+// TestMusicFoldersItemAccess checks that tracks, albums and artists outside of
+// the music folders which the user is allowed to access are not found.
+func TestMusicFoldersItemAccess(t *testing.T) {
+	trackFile := filepath.Join(t.TempDir(), "track.mp3")
+	err := os.WriteFile(trackFile, []byte("some track contents"), 0600)
+	assert.NilErr(t, err, "writing track file")
+
+	lib := &libraryfakes.FakeLibrary{}
+	lib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "Main", Path: "/media/Main"},
+		{ID: 2, Name: "Kids", Path: "/media/Kids"},
+	}, nil)
+	lib.GetFilePathReturns(trackFile)
+	lib.GetTrackReturns(library.TrackInfo{ID: 5, Title: "Song"}, nil)
+	lib.GetAlbumReturns(library.Album{ID: 7, Name: "Album"}, nil)
+	lib.GetArtistReturns(library.Artist{ID: 9, Name: "Artist"}, nil)
+	lib.GetAlbumFilesReturns([]library.TrackInfo{{ID: 5, Title: "Song"}})
+	lib.GetArtistAlbumsReturns([]library.Album{{ID: 7}, {ID: 8}})
+
+	allowedIn := func(allowedID int64) func(context.Context, int64, []int64) (bool, error) {
+		return func(_ context.Context, id int64, folderIDs []int64) (bool, error) {
+			if slices.Contains(folderIDs, 1) || !slices.Contains(folderIDs, 2) {
+				t.Errorf("expected filtering by the Kids music folder but got %v", folderIDs)
+			}
+			return id == allowedID, nil
+		}
+	}
+	lib.TrackInMusicFoldersStub = allowedIn(5)
+	lib.AlbumInMusicFoldersStub = allowedIn(7)
+	lib.ArtistInMusicFoldersStub = allowedIn(9)
+
+	albumArt := &subsonicfakes.FakeCoverArtHandler{}
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User:         "test-user",
+				MusicFolders: []string{"Kids"},
+			},
+		},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			AlbumArt:   albumArt,
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+			Bookmarker: &bookmarksfakes.FakeBookmarker{},
+		},
+	)
+
+	tests := []struct {
+		desc     string
+		url      string
+		found    bool
+		jsonResp bool
+	}{
+		{desc: "allowed song", url: "/rest/getSong?id=2000000005", found: true, jsonResp: true},
+		{desc: "song", url: "/rest/getSong?id=2000000006", jsonResp: true},
+		{desc: "allowed album", url: "/rest/getAlbum?id=7", found: true, jsonResp: true},
+		{desc: "album", url: "/rest/getAlbum?id=8", jsonResp: true},
+		{desc: "allowed artist", url: "/rest/getArtist?id=1000000009", found: true, jsonResp: true},
+		{desc: "artist", url: "/rest/getArtist?id=1000000010", jsonResp: true},
+		{
+			desc:     "allowed album directory",
+			url:      "/rest/getMusicDirectory?id=7",
+			found:    true,
+			jsonResp: true,
+		},
+		{desc: "album directory", url: "/rest/getMusicDirectory?id=8", jsonResp: true},
+		{desc: "artist directory", url: "/rest/getMusicDirectory?id=1000000010", jsonResp: true},
+		{desc: "allowed stream", url: "/rest/stream?id=2000000005", found: true},
+		{desc: "stream", url: "/rest/stream?id=2000000006", jsonResp: true},
+		{desc: "download", url: "/rest/download?id=2000000006", jsonResp: true},
+		{
+			desc:     "allowed album info",
+			url:      "/rest/getAlbumInfo2?id=7",
+			found:    true,
+			jsonResp: true,
+		},
+		{desc: "album info", url: "/rest/getAlbumInfo2?id=8", jsonResp: true},
+		{desc: "legacy album info", url: "/rest/getAlbumInfo?id=8", jsonResp: true},
+		{desc: "allowed cover art", url: "/rest/getCoverArt?id=al-7", found: true},
+		{desc: "cover art", url: "/rest/getCoverArt?id=al-8"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url+"&f=json", nil)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			if !test.jsonResp {
+				if test.found && rec.Code != http.StatusOK {
+					t.Errorf("expected HTTP status OK but got %d", rec.Code)
+				} else if !test.found && rec.Code != http.StatusNotFound {
+					t.Errorf("expected HTTP status Not Found but got %d", rec.Code)
+				}
+				return
+			}
+
+			var jsonResp musicFoldersRespJSON
+			dec := json.NewDecoder(rec.Result().Body)
+			assert.NilErr(t, dec.Decode(&jsonResp), "error decoding response")
+
+			if test.found {
+				assert.Equal(t, "ok", jsonResp.Subsonic.Status, "response status")
+				return
+			}
+			assert.Equal(t, "failed", jsonResp.Subsonic.Status, "response status")
+			assert.Equal(t, 70, jsonResp.Subsonic.Error.Code, "error code")
+		})
+	}
+
+	assert.Equal(t, 1, albumArt.FindCallCount(), "served album art")
+
+	// Albums which are not allowed are not listed for allowed artists.
+	req := httptest.NewRequest(http.MethodGet, "/rest/getArtist?f=json&id=1000000009", nil)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	var artistResp struct {
+		Subsonic struct {
+			Artist struct {
+				Albums []struct {
+					ID string `json:"id"`
+				} `json:"album"`
+			} `json:"artist"`
+		} `json:"subsonic-response"`
+	}
+	assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &artistResp), "decoding artist")
+	albums := artistResp.Subsonic.Artist.Albums
+	if len(albums) != 1 || albums[0].ID != "7" {
+		t.Errorf("expected only the allowed album but got %+v", albums)
+	}
+}
+
+// TestMusicFoldersListings checks that endpoints which list songs, albums and
+// artists return only items from the music folders the user could access.
+func TestMusicFoldersListings(t *testing.T) {
+	lib := &libraryfakes.FakeLibrary{}
+	lib.MusicFoldersReturns([]library.MusicFolder{
+		{ID: 1, Name: "Main", Path: "/media/Main"},
+		{ID: 2, Name: "Kids", Path: "/media/Kids"},
+	}, nil)
+	lib.SearchArtistsReturns([]library.Artist{{ID: 9, Name: "Artist"}})
+
+	browser := &libraryfakes.FakeBrowser{}
+	playlister := &playlistsfakes.FakePlaylister{}
+	playlister.GetReturns(playlists.Playlist{
+		ID:          3,
+		Name:        "Mixed",
+		TracksCount: 3,
+		Tracks: []library.TrackInfo{
+			{ID: 5, Title: "Kids Song", MusicFolderID: 2},
+			{ID: 6, Title: "Main Song", MusicFolderID: 1},
+			{ID: 7, Title: "Elsewhere", MusicFolderID: 0},
+		},
+	}, nil)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User:         "test-user",
+				MusicFolders: []string{"Kids"},
+			},
+		},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    browser,
+			Playlister: playlister,
+			AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+		},
+	)
+
+	serve := func(url string) string {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ssHandler.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	assertFiltered := func(what string, folderIDs []int64) {
+		t.Helper()
+		if !slices.Contains(folderIDs, 2) || slices.Contains(folderIDs, 1) {
+			t.Errorf("%s: expected filtering by the Kids music folder but got %v",
+				what, folderIDs)
+		}
+	}
+
+	serve("/rest/search?f=json&any=song")
+	assert.Equal(t, 1, lib.SearchCallCount(), "search calls")
+	_, searchArgs := lib.SearchArgsForCall(0)
+	assertFiltered("search songs", searchArgs.MusicFolderIDs)
+	_, searchArgs = lib.SearchAlbumsArgsForCall(0)
+	assertFiltered("search albums", searchArgs.MusicFolderIDs)
+	_, searchArgs = lib.SearchArtistsArgsForCall(0)
+	assertFiltered("search artists", searchArgs.MusicFolderIDs)
+
+	for _, endpoint := range []string{"getStarred", "getStarred2"} {
+		browsedBefore := browser.BrowseTracksCallCount()
+		serve("/rest/" + endpoint + "?f=json")
+		assert.Equal(t, browsedBefore+1, browser.BrowseTracksCallCount(), "browse calls")
+
+		args := browser.BrowseArtistsArgsForCall(browser.BrowseArtistsCallCount() - 1)
+		assertFiltered(endpoint+" artists", args.MusicFolderIDs)
+		args = browser.BrowseAlbumsArgsForCall(browser.BrowseAlbumsCallCount() - 1)
+		assertFiltered(endpoint+" albums", args.MusicFolderIDs)
+		args = browser.BrowseTracksArgsForCall(browser.BrowseTracksCallCount() - 1)
+		assertFiltered(endpoint+" songs", args.MusicFolderIDs)
+	}
+
+	serve("/rest/getTopSongs?f=json&artist=Artist")
+	_, searchArgs = lib.SearchArtistsArgsForCall(lib.SearchArtistsCallCount() - 1)
+	assertFiltered("top songs artist", searchArgs.MusicFolderIDs)
+	args := browser.BrowseTracksArgsForCall(browser.BrowseTracksCallCount() - 1)
+	assertFiltered("top songs", args.MusicFolderIDs)
+
+	body := serve("/rest/getPlaylist?f=json&id=3")
+	if !strings.Contains(body, "Kids Song") {
+		t.Errorf("expected the allowed song in the playlist but got:\n%s", body)
+	}
+	for _, title := range []string{"Main Song", "Elsewhere"} {
+		if strings.Contains(body, title) {
+			t.Errorf("unexpected song `%s` in the playlist:\n%s", title, body)
+		}
+	}
+	if !strings.Contains(body, `"songCount": 1`) {
+		t.Errorf("expected only the allowed song to be counted but got:\n%s", body)
+	}
+}
+
+type musicFoldersRespJSON struct {
+	Subsonic struct {
+		Status string `json:"status"`
+		Error  struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		MusicFolders struct {
+			Folders []struct {
+				ID   int64  `json:"id,string"`
+				Name string `json:"name"`
+			} `json:"musicFolder"`
+		} `json:"musicFolders"`
+	} `json:"subsonic-response"`
+}
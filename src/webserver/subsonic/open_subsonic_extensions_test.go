@@ -31,10 +31,6 @@ func TestOpenSubsonicFromPostExtension(t *testing.T) {
 
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		lib,
-		browser,
-		stations,
-		playlister,
 		config.Config{
 			Auth: true,
 			Authenticate: config.Auth{
@@ -42,7 +38,12 @@ func TestOpenSubsonicFromPostExtension(t *testing.T) {
 				Password: authPassword,
 			},
 		},
-		nil, nil,
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    browser,
+			Stations:   stations,
+			Playlister: playlister,
+		},
 	)
 
 	body := url.Values{}
@@ -0,0 +1,545 @@
+package subsonic_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/bookmarks/bookmarksfakes"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// openSubsonicSchemaFile is a JSON schema file with the definitions of the
+// OpenSubsonic response types as published in the OpenSubsonic API documentation.
+const openSubsonicSchemaFile = "opensubsonic-responses.schema.json"
+
+// jsonSchema is the subset of JSON Schema used in openSubsonicSchemaFile.
+type jsonSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       string                 `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+}
+
+// loadOpenSubsonicSchemas returns the OpenSubsonic type definitions from
+// openSubsonicSchemaFile by type name.
+func loadOpenSubsonicSchemas(t *testing.T) map[string]*jsonSchema {
+	t.Helper()
+
+	content, err := os.ReadFile(openSubsonicSchemaFile)
+	if err != nil {
+		t.Fatalf("reading OpenSubsonic schema: %s", err)
+	}
+
+	var schemaFile struct {
+		Defs map[string]*jsonSchema `json:"$defs"`
+	}
+	if err := json.Unmarshal(content, &schemaFile); err != nil {
+		t.Fatalf("decoding OpenSubsonic schema: %s", err)
+	}
+
+	return schemaFile.Defs
+}
+
+// TestOpenSubsonicJSONResponses checks that the JSON responses for songs, albums
+// and artists conform to the OpenSubsonic schemas. This is the OpenSubsonic
+// counterpart of TestSubsonicXMLResponses.
+func TestOpenSubsonicJSONResponses(t *testing.T) {
+	song := library.TrackInfo{
+		ID:          11,
+		ArtistID:    10,
+		Artist:      "First Artist",
+		AlbumID:     10,
+		Album:       "First Album",
+		Title:       "First Song",
+		TrackNumber: 1,
+		Format:      "mp3",
+		Duration:    162000,
+		Plays:       12,
+		LastPlayed:  1714856348,
+		Favourite:   1714856348,
+		Rating:      3,
+		Year:        2004,
+		Genre:       "Trip Hop",
+		Comment:     "Recorded live",
+
+		SortName:      "First Song, The",
+		MusicBrainzID: "e5a3f8a0-0b8d-4d46-9a27-6b2a4bb1f0c3",
+		ISRCs:         []string{"USRC17607839"},
+		BPM:           120,
+		Disc:          1,
+		DiscSubtitle:  "Studio",
+	}
+	album := library.Album{
+		ID:         10,
+		Name:       "First Album",
+		Artist:     "Various Artists",
+		SongCount:  5,
+		Duration:   42318473,
+		Plays:      932,
+		LastPlayed: 1714856348,
+		Rating:     3,
+		Year:       2004,
+
+		SortName:            "First Album, The",
+		MusicBrainzID:       "0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1",
+		ReleaseDate:         "2004-05-01",
+		OriginalReleaseDate: "2001",
+		Compilation:         true,
+	}
+	artist := library.Artist{
+		ID:         10,
+		Name:       "First Artist",
+		AlbumCount: 3,
+		Favourite:  1714856348,
+		Rating:     4,
+	}
+
+	lib := &libraryfakes.FakeLibrary{
+		GetTrackStub: func(_ context.Context, _ int64) (library.TrackInfo, error) {
+			return song, nil
+		},
+		GetAlbumStub: func(_ context.Context, _ int64) (library.Album, error) {
+			return album, nil
+		},
+		GetArtistStub: func(_ context.Context, _ int64) (library.Artist, error) {
+			return artist, nil
+		},
+	}
+	lib.GetAlbumFilesReturns([]library.TrackInfo{song})
+	lib.GetArtistAlbumsReturns([]library.Album{album})
+	lib.SearchReturns([]library.SearchResult{song})
+	lib.SearchAlbumsReturns([]library.Album{album})
+	lib.SearchArtistsReturns([]library.Artist{artist})
+
+	browser := &libraryfakes.FakeBrowser{
+		BrowseAlbumsStub: func(ba library.BrowseArgs) ([]library.Album, int) {
+			if ba.Offset > 0 {
+				return nil, 1
+			}
+			return []library.Album{album}, 1
+		},
+	}
+
+	bookmarker := &bookmarksfakes.FakeBookmarker{}
+	bookmarker.GetReturns(bookmarks.Bookmark{}, bookmarks.ErrNotFound)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    browser,
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			Bookmarker: bookmarker,
+		},
+	)
+
+	schemas := loadOpenSubsonicSchemas(t)
+
+	type checkedPath struct {
+		path   []string
+		schema string
+	}
+
+	tests := []struct {
+		desc   string
+		url    string
+		checks []checkedPath
+	}{
+		{
+			desc: "getSong",
+			url:  fmt.Sprintf("/getSong?id=%d", int64(2e9+11)),
+			checks: []checkedPath{
+				{path: []string{"song"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "getAlbum",
+			url:  "/getAlbum?id=10",
+			checks: []checkedPath{
+				{path: []string{"album"}, schema: "AlbumID3"},
+				{path: []string{"album", "song"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "getArtist",
+			url:  fmt.Sprintf("/getArtist?id=%d", int64(1e9+10)),
+			checks: []checkedPath{
+				{path: []string{"artist"}, schema: "ArtistID3"},
+				{path: []string{"artist", "album"}, schema: "AlbumID3"},
+			},
+		},
+		{
+			desc: "getMusicDirectory artist",
+			url:  fmt.Sprintf("/getMusicDirectory?id=%d", int64(1e9+10)),
+			checks: []checkedPath{
+				{path: []string{"directory", "child"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "getMusicDirectory album",
+			url:  "/getMusicDirectory?id=10",
+			checks: []checkedPath{
+				{path: []string{"directory", "child"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "getAlbumList2",
+			url:  "/getAlbumList2?type=random",
+			checks: []checkedPath{
+				{path: []string{"albumList2", "album"}, schema: "AlbumID3"},
+			},
+		},
+		{
+			desc: "getAlbumList",
+			url:  "/getAlbumList?type=random",
+			checks: []checkedPath{
+				{path: []string{"albumList", "album"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "search2",
+			url:  "/search2?query=first",
+			checks: []checkedPath{
+				{path: []string{"searchResult2", "album"}, schema: "Child"},
+				{path: []string{"searchResult2", "song"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "search3",
+			url:  "/search3?query=first",
+			checks: []checkedPath{
+				{path: []string{"searchResult3", "artist"}, schema: "ArtistID3"},
+				{path: []string{"searchResult3", "album"}, schema: "AlbumID3"},
+				{path: []string{"searchResult3", "song"}, schema: "Child"},
+			},
+		},
+		{
+			desc: "search",
+			url:  "/search?any=first",
+			checks: []checkedPath{
+				{path: []string{"searchResult", "match"}, schema: "Child"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodGet,
+				subsonic.Prefix+test.url+"&f=json",
+				nil,
+			)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected HTTP status OK but got %d", rec.Code)
+			}
+
+			var resp struct {
+				Response map[string]any `json:"subsonic-response"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding JSON response: %s", err)
+			}
+
+			if status := resp.Response["status"]; status != "ok" {
+				t.Fatalf("expected status `ok` but got `%v`: %s", status, rec.Body)
+			}
+
+			for _, check := range test.checks {
+				objects := findJSONObjects(resp.Response, check.path)
+				if len(objects) == 0 {
+					t.Errorf("no objects found at %v", check.path)
+				}
+
+				schema, ok := schemas[check.schema]
+				if !ok {
+					t.Fatalf("no OpenSubsonic schema for %s", check.schema)
+				}
+				for _, obj := range objects {
+					checkOpenSubsonicSchema(
+						t, strings.Join(check.path, "."), schemas, schema, obj,
+					)
+				}
+			}
+		})
+	}
+}
+
+// findJSONObjects returns all JSON objects found under `path` in `root`. Arrays
+// along the way are expanded.
+func findJSONObjects(root map[string]any, path []string) []map[string]any {
+	found := []any{root}
+	for _, key := range path {
+		var next []any
+		for _, val := range found {
+			obj, ok := val.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch child := obj[key].(type) {
+			case []any:
+				next = append(next, child...)
+			case nil:
+			default:
+				next = append(next, child)
+			}
+		}
+		found = next
+	}
+
+	var objects []map[string]any
+	for _, val := range found {
+		if obj, ok := val.(map[string]any); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// checkOpenSubsonicSchema validates the JSON value `val` found at `path` against
+// `schema`. References in schemas are resolved from `defs`.
+func checkOpenSubsonicSchema(
+	t *testing.T,
+	path string,
+	defs map[string]*jsonSchema,
+	schema *jsonSchema,
+	val any,
+) {
+	t.Helper()
+
+	if schema.Ref != "" {
+		ref, ok := defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+		if !ok {
+			t.Fatalf("%s: unknown schema reference %s", path, schema.Ref)
+		}
+		checkOpenSubsonicSchema(t, path, defs, ref, val)
+		return
+	}
+
+	if kind := jsonTypeOf(val); kind != schema.Type &&
+		(schema.Type != "number" || kind != "integer") {
+		t.Errorf("%s: expected %s but it was %s", path, schema.Type, kind)
+		return
+	}
+
+	switch obj := val.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				t.Errorf("%s: required property `%s` is missing", path, name)
+			}
+		}
+		for name, propSchema := range schema.Properties {
+			if propVal, ok := obj[name]; ok {
+				checkOpenSubsonicSchema(t, path+"."+name, defs, propSchema, propVal)
+			}
+		}
+	case []any:
+		for ind, item := range obj {
+			itemPath := fmt.Sprintf("%s[%d]", path, ind)
+			checkOpenSubsonicSchema(t, itemPath, defs, schema.Items, item)
+		}
+	}
+}
+
+// jsonTypeOf returns the JSON Schema type of a decoded JSON value.
+func jsonTypeOf(val any) string {
+	switch val := val.(type) {
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "null"
+	}
+}
+
+// TestOpenSubsonicFields checks the values of the OpenSubsonic song and album
+// properties which are derived from the library data.
+func TestOpenSubsonicFields(t *testing.T) {
+	lib := &libraryfakes.FakeLibrary{}
+	lib.GetAlbumReturns(library.Album{
+		ID:         10,
+		Name:       "Compilation",
+		Artist:     "Various Artists",
+		SongCount:  2,
+		LastPlayed: 1714856348,
+		Rating:     4,
+		Year:       2004,
+
+		SortName:            "Compilation, The",
+		MusicBrainzID:       "0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1",
+		ReleaseDate:         "2004-05",
+		OriginalReleaseDate: "1999-12-31",
+		Compilation:         true,
+	}, nil)
+	track := library.TrackInfo{
+		ID:       11,
+		AlbumID:  10,
+		Artist:   "First Artist",
+		Title:    "First Song",
+		Format:   "mp3",
+		Genre:    "Trip Hop",
+		Comment:  "Recorded live",
+		Duration: 162000,
+
+		SortName:      "First Song, The",
+		MusicBrainzID: "e5a3f8a0-0b8d-4d46-9a27-6b2a4bb1f0c3",
+		ISRCs:         []string{"USRC17607839", "GBAYE0601498"},
+		BPM:           120,
+		Disc:          2,
+		DiscSubtitle:  "Live",
+	}
+	lib.GetTrackReturns(track, nil)
+	lib.GetAlbumFilesReturns([]library.TrackInfo{
+		{ID: 12, AlbumID: 10, Disc: 1, DiscSubtitle: "Studio"},
+		track,
+		{ID: 13, AlbumID: 10, Disc: 2, DiscSubtitle: "Live"},
+		{ID: 14, AlbumID: 10, Disc: 3},
+	})
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    lib,
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			Bookmarker: &bookmarksfakes.FakeBookmarker{},
+		},
+	)
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+"/getAlbum?id=10&f=json",
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	type itemDate struct {
+		Year  int `json:"year"`
+		Month int `json:"month"`
+		Day   int `json:"day"`
+	}
+	type discTitle struct {
+		Disc  int    `json:"disc"`
+		Title string `json:"title"`
+	}
+
+	var albumResp struct {
+		Response struct {
+			Album map[string]any `json:"album"`
+		} `json:"subsonic-response"`
+	}
+	assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &albumResp), "decoding album JSON")
+
+	album := albumResp.Response.Album
+	played, _ := album["played"].(string)
+	if playedAt, err := time.Parse(time.RFC3339, played); err != nil ||
+		!playedAt.Equal(time.Unix(1714856348, 0)) {
+		t.Errorf("wrong played time: %s", played)
+	}
+	assert.Equal[any](t, float64(4), album["userRating"], "user rating")
+	assert.Equal[any](t, "Various Artists", album["displayArtist"], "display artist")
+
+	assert.Equal[any](t, "Compilation, The", album["sortName"], "album sort name")
+	assert.Equal[any](
+		t, "0b4c9ff3-1bbd-4a4f-b1c6-d8f8b0a6e2c1", album["musicBrainzId"],
+		"album MusicBrainz ID",
+	)
+	assert.Equal[any](t, true, album["isCompilation"], "album is compilation")
+
+	var albumDates struct {
+		Response struct {
+			Album struct {
+				ReleaseDate         itemDate    `json:"releaseDate"`
+				OriginalReleaseDate itemDate    `json:"originalReleaseDate"`
+				DiscTitles          []discTitle `json:"discTitles"`
+			} `json:"album"`
+		} `json:"subsonic-response"`
+	}
+	assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &albumDates), "decoding album dates")
+
+	dates := albumDates.Response.Album
+	assert.Equal(t, itemDate{Year: 2004, Month: 5}, dates.ReleaseDate, "release date")
+	assert.Equal(
+		t, itemDate{Year: 1999, Month: 12, Day: 31}, dates.OriginalReleaseDate,
+		"original release date",
+	)
+	expectedDiscs := []discTitle{{Disc: 1, Title: "Studio"}, {Disc: 2, Title: "Live"}}
+	if !slices.Equal(expectedDiscs, dates.DiscTitles) {
+		t.Errorf("expected disc titles %+v but got %+v", expectedDiscs, dates.DiscTitles)
+	}
+
+	req = httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+fmt.Sprintf("/getSong?id=%d&f=json", int64(2e9+11)),
+		nil,
+	)
+	rec = httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	var songResp struct {
+		Response struct {
+			Song struct {
+				Genre   string `json:"genre"`
+				Comment string `json:"comment"`
+				Genres  []struct {
+					Name string `json:"name"`
+				} `json:"genres"`
+				SortName      string   `json:"sortName"`
+				MusicBrainzID string   `json:"musicBrainzId"`
+				ISRC          []string `json:"isrc"`
+				BPM           int      `json:"bpm"`
+				DiscNumber    int      `json:"discNumber"`
+			} `json:"song"`
+		} `json:"subsonic-response"`
+	}
+	assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &songResp), "decoding song JSON")
+
+	song := songResp.Response.Song
+	assert.Equal(t, "Trip Hop", song.Genre, "song genre")
+	assert.Equal(t, "Recorded live", song.Comment, "song comment")
+	if len(song.Genres) != 1 || song.Genres[0].Name != "Trip Hop" {
+		t.Errorf("expected genres with `Trip Hop` but got %+v", song.Genres)
+	}
+	assert.Equal(t, track.SortName, song.SortName, "song sort name")
+	assert.Equal(t, track.MusicBrainzID, song.MusicBrainzID, "song MusicBrainz ID")
+	assert.Equal(t, track.BPM, song.BPM, "song BPM")
+	assert.Equal(t, track.Disc, song.DiscNumber, "song disc number")
+	if !slices.Equal(track.ISRCs, song.ISRC) {
+		t.Errorf("expected ISRCs %v but got %v", track.ISRCs, song.ISRC)
+	}
+}
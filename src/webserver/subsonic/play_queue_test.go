@@ -0,0 +1,166 @@
+package subsonic_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/playqueue/playqueuefakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestPlayQueueByIndex checks the OpenSubsonic index based play queue endpoints.
+// The same track is in the queue twice so that the current index is not
+// ambiguous.
+func TestPlayQueueByIndex(t *testing.T) {
+	var saved *playqueue.Queue
+
+	queuer := &playqueuefakes.FakeQueuer{
+		GetStub: func(_ context.Context) (playqueue.Queue, error) {
+			if saved == nil {
+				return playqueue.Queue{CurrentIndex: -1}, nil
+			}
+			return *saved, nil
+		},
+		SaveStub: func(_ context.Context, args playqueue.SaveArgs) (int64, error) {
+			queue := playqueue.Queue{
+				CurrentIndex: args.CurrentIndex,
+				Position:     args.Position,
+				ChangedBy:    args.ChangedBy,
+				UpdatedAt:    time.Now(),
+				Version:      1,
+			}
+			for _, trackID := range args.TrackIDs {
+				queue.Tracks = append(queue.Tracks, library.TrackInfo{
+					ID:    trackID,
+					Title: fmt.Sprintf("Track %d", trackID),
+				})
+			}
+			saved = &queue
+			return queue.Version, nil
+		},
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{
+				User: "test-user",
+			},
+		},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+			Queuer:     queuer,
+		},
+	)
+
+	type playQueue struct {
+		Current      string `json:"current"`
+		CurrentIndex *int   `json:"currentIndex"`
+		Position     int64  `json:"position"`
+		Username     string `json:"username"`
+		ChangedBy    string `json:"changedBy"`
+		Entries      []struct {
+			ID string `json:"id"`
+		} `json:"entry"`
+	}
+
+	type response struct {
+		Subsonic struct {
+			Status string `json:"status"`
+			Error  struct {
+				Code int `json:"code"`
+			} `json:"error"`
+			PlayQueue        *playQueue `json:"playQueue"`
+			PlayQueueByIndex *playQueue `json:"playQueueByIndex"`
+		} `json:"subsonic-response"`
+	}
+
+	request := func(url string, args ...any) response {
+		req := httptest.NewRequest(
+			http.MethodGet,
+			subsonic.Prefix+fmt.Sprintf(url, args...),
+			nil,
+		)
+		rec := httptest.NewRecorder()
+		ssHandler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode, "HTTP status code")
+
+		var resp response
+		err := json.NewDecoder(rec.Result().Body).Decode(&resp)
+		assert.NilErr(t, err, "decoding response")
+
+		return resp
+	}
+
+	resp := request("/getPlayQueueByIndex?f=json")
+	assert.Equal(t, "ok", resp.Subsonic.Status, "empty queue status")
+	if resp.Subsonic.PlayQueueByIndex != nil {
+		t.Errorf("did not expect a play queue before one is saved")
+	}
+
+	resp = request(
+		"/savePlayQueueByIndex?f=json&c=phone&id=%d&id=%d&id=%d&currentIndex=3",
+		int64(2e9+10), int64(2e9+11), int64(2e9+10),
+	)
+	assert.Equal(t, "failed", resp.Subsonic.Status, "out of range index status")
+	assert.Equal(t, 0, queuer.SaveCallCount(), "saves with index out of range")
+
+	resp = request(
+		"/savePlayQueueByIndex?f=json&c=phone&id=%d&id=%d&id=%d&currentIndex=2&position=500",
+		int64(2e9+10), int64(2e9+11), int64(2e9+10),
+	)
+	assert.Equal(t, "ok", resp.Subsonic.Status, "save status")
+	assert.Equal(t, 1, queuer.SaveCallCount(), "saves")
+
+	_, args := queuer.SaveArgsForCall(0)
+	expectedArgs := playqueue.SaveArgs{
+		TrackIDs:     []int64{10, 11, 10},
+		CurrentIndex: 2,
+		Position:     500,
+		ChangedBy:    "phone",
+	}
+	if !reflect.DeepEqual(expectedArgs, args) {
+		t.Errorf("expected save arguments %+v but got %+v", expectedArgs, args)
+	}
+
+	resp = request("/getPlayQueueByIndex?f=json")
+	assert.Equal(t, "ok", resp.Subsonic.Status, "get by index status")
+	queue := resp.Subsonic.PlayQueueByIndex
+	if queue == nil {
+		t.Fatalf("expected a play queue by index in the response")
+	}
+	if queue.CurrentIndex == nil {
+		t.Fatalf("expected current index in the response")
+	}
+	assert.Equal(t, 2, *queue.CurrentIndex, "current index")
+	assert.Equal(t, 500, queue.Position, "position")
+	assert.Equal(t, "test-user", queue.Username, "username")
+	assert.Equal(t, "phone", queue.ChangedBy, "changed by")
+	assert.Equal(t, 3, len(queue.Entries), "queue entries")
+
+	resp = request("/getPlayQueue?f=json")
+	assert.Equal(t, "ok", resp.Subsonic.Status, "get status")
+	if resp.Subsonic.PlayQueue == nil {
+		t.Fatalf("expected a play queue in the response")
+	}
+	assert.Equal(t, fmt.Sprint(int64(2e9+10)), resp.Subsonic.PlayQueue.Current, "current")
+}
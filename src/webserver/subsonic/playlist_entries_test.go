@@ -0,0 +1,88 @@
+package subsonic_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestPlaylistMissingEntries checks that playlist entries which tracks are
+// missing from the library are not shown and that song indexes are translated
+// to the indexes of the playlist entries.
+func TestPlaylistMissingEntries(t *testing.T) {
+	withMissing := playlists.Playlist{
+		ID:        3,
+		Name:      "With Missing",
+		CreatedAt: time.Unix(1728838802, 0),
+		UpdatedAt: time.Unix(1728838802, 0),
+		Tracks: []library.TrackInfo{
+			{ID: 11, Title: "First"},
+			{Title: "Gone", Missing: true},
+			{ID: 13, Title: "Third"},
+		},
+		TracksCount: 2,
+	}
+
+	playlister := &playlistsfakes.FakePlaylister{}
+	playlister.GetReturns(withMissing, nil)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: playlister,
+		},
+	)
+
+	var playlistResp struct {
+		Response struct {
+			Playlist struct {
+				Entries []struct {
+					Title string `json:"title"`
+				} `json:"entry"`
+			} `json:"playlist"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler, "/getPlaylist?f=json&id=3", &playlistResp)
+	entries := playlistResp.Response.Playlist.Entries
+	assert.Equal(t, 2, len(entries), "number of shown entries")
+	if len(entries) == 2 {
+		assert.Equal(t, "First", entries[0].Title, "first entry")
+		assert.Equal(t, "Third", entries[1].Title, "second entry")
+	}
+
+	var statusResp struct {
+		Response struct {
+			Status string `json:"status"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler,
+		"/updatePlaylist?f=json&playlistId=3&songIndexToRemove=1", &statusResp,
+	)
+	assert.Equal(t, "ok", statusResp.Response.Status, "removing song")
+	assert.Equal(t, 1, playlister.UpdateCallCount(), "update calls")
+	if playlister.UpdateCallCount() == 1 {
+		_, _, args := playlister.UpdateArgsForCall(0)
+		if !slices.Equal([]int64{2}, args.RemoveTracks) {
+			t.Errorf("expected removal of entry 2 but got %v", args.RemoveTracks)
+		}
+	}
+
+	decodeResponse(t, ssHandler,
+		"/updatePlaylist?f=json&playlistId=3&songIndexToRemove=2", &statusResp,
+	)
+	assert.Equal(t, "failed", statusResp.Response.Status, "out of range song index")
+	assert.Equal(t, 1, playlister.UpdateCallCount(), "update calls after failure")
+}
@@ -0,0 +1,115 @@
+package subsonic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestPlaylistOwners checks that the owner, public flag and collaborators of
+// playlists are reported and that playlists are requested for the configured
+// user.
+func TestPlaylistOwners(t *testing.T) {
+	owned := playlists.Playlist{
+		ID:            3,
+		Name:          "Owned",
+		Owner:         "alice",
+		Collaborators: []string{"bob", "carol"},
+		CreatedAt:     time.Unix(1728838802, 0),
+		UpdatedAt:     time.Unix(1728838802, 0),
+	}
+	ownerless := playlists.Playlist{
+		ID:        4,
+		Name:      "Ownerless",
+		Public:    true,
+		CreatedAt: time.Unix(1728838802, 0),
+		UpdatedAt: time.Unix(1728838802, 0),
+	}
+
+	playlister := &playlistsfakes.FakePlaylister{}
+	playlister.GetReturns(owned, nil)
+	playlister.ListReturns([]playlists.Playlist{owned, ownerless}, nil)
+	playlister.UpdateReturns(playlists.ErrNotAllowed)
+	playlister.DeleteReturns(playlists.ErrNotAllowed)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{
+			Authenticate: config.Auth{User: "alice"},
+		},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: playlister,
+		},
+	)
+
+	type xsdPlaylist struct {
+		ID           string   `json:"id"`
+		Owner        string   `json:"owner"`
+		Public       bool     `json:"public"`
+		AllowedUsers []string `json:"allowedUser"`
+	}
+
+	var playlistResp struct {
+		Response struct {
+			Playlist xsdPlaylist `json:"playlist"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler, "/getPlaylist?f=json&id=3", &playlistResp)
+	pl := playlistResp.Response.Playlist
+	assert.Equal(t, "alice", pl.Owner, "playlist owner")
+	assert.Equal(t, false, pl.Public, "public flag")
+	assert.Equal(t, 2, len(pl.AllowedUsers), "allowed users count")
+	if len(pl.AllowedUsers) == 2 {
+		assert.Equal(t, "bob", pl.AllowedUsers[0], "first allowed user")
+		assert.Equal(t, "carol", pl.AllowedUsers[1], "second allowed user")
+	}
+
+	_, _, user := playlister.GetArgsForCall(0)
+	assert.Equal(t, "alice", user, "playlist requested for user")
+
+	var listResp struct {
+		Response struct {
+			Playlists struct {
+				Playlist []xsdPlaylist `json:"playlist"`
+			} `json:"playlists"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler, "/getPlaylists?f=json", &listResp)
+	list := listResp.Response.Playlists.Playlist
+	assert.Equal(t, 2, len(list), "playlists count")
+	if len(list) == 2 {
+		assert.Equal(t, "alice", list[1].Owner, "owner of playlist without one")
+		assert.Equal(t, true, list[1].Public, "public flag of playlist without owner")
+		assert.Equal(t, 0, len(list[1].AllowedUsers), "allowed users without owner")
+	}
+
+	_, listArgs := playlister.ListArgsForCall(0)
+	assert.Equal(t, "alice", listArgs.User, "playlists listed for user")
+
+	for _, url := range []string{
+		"/updatePlaylist?f=json&playlistId=3&name=other",
+		"/deletePlaylist?f=json&id=3",
+	} {
+		var errResp struct {
+			Response struct {
+				Status string `json:"status"`
+				Error  struct {
+					Code int `json:"code"`
+				} `json:"error"`
+			} `json:"subsonic-response"`
+		}
+		decodeResponse(t, ssHandler, url, &errResp)
+		assert.Equal(t, "failed", errResp.Response.Status, "status for %s", url)
+		assert.Equal(t, 50, errResp.Response.Error.Code, "error code for %s", url)
+	}
+}
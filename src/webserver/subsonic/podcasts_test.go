@@ -0,0 +1,240 @@
+package subsonic_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/podcasts"
+	"github.com/ironsmile/euterpe/src/podcasts/podcastsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic/subsonicfakes"
+)
+
+// TestPodcastFiles checks that downloaded podcast episodes are served by the
+// stream endpoint and channel images by the cover art endpoint.
+func TestPodcastFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	episodePath := filepath.Join(tmpDir, "5.mp3")
+	imagePath := filepath.Join(tmpDir, "cover.png")
+	assert.NilErr(t, os.WriteFile(episodePath, []byte("episode file"), 0644))
+	assert.NilErr(t, os.WriteFile(imagePath, []byte("cover"), 0644))
+
+	podcaster := &podcastsfakes.FakePodcaster{
+		EpisodeStub: func(_ context.Context, id int64) (podcasts.Episode, error) {
+			switch id {
+			case 5:
+				return podcasts.Episode{
+					ID:        5,
+					ChannelID: 3,
+					Title:     "Downloaded Episode",
+					Status:    podcasts.StatusCompleted,
+					FilePath:  episodePath,
+				}, nil
+			case 4:
+				return podcasts.Episode{
+					ID:        4,
+					ChannelID: 3,
+					Title:     "New Episode",
+					Status:    podcasts.StatusNew,
+				}, nil
+			default:
+				return podcasts.Episode{}, podcasts.ErrNotFound
+			}
+		},
+		ChannelStub: func(_ context.Context, id int64) (podcasts.Channel, error) {
+			if id != 3 {
+				return podcasts.Channel{}, podcasts.ErrNotFound
+			}
+			return podcasts.Channel{
+				ID:        3,
+				Title:     "Test Podcast",
+				ImagePath: imagePath,
+				Status:    podcasts.StatusCompleted,
+			}, nil
+		},
+	}
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+			AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+			ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+			Podcaster:  podcaster,
+		},
+	)
+
+	tests := []struct {
+		desc         string
+		url          string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			desc:         "stream downloaded episode",
+			url:          "/stream?id=pe-5",
+			expectedCode: http.StatusOK,
+			expectedBody: "episode file",
+		},
+		{
+			desc:         "download episode file",
+			url:          "/download?id=pe-5",
+			expectedCode: http.StatusOK,
+			expectedBody: "episode file",
+		},
+		{
+			desc:         "channel cover art",
+			url:          "/getCoverArt?id=pc-3",
+			expectedCode: http.StatusOK,
+			expectedBody: "cover",
+		},
+		{
+			desc:         "missing channel cover art",
+			url:          "/getCoverArt?id=pc-42",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, subsonic.Prefix+test.url, nil)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedCode, rec.Code, "HTTP status code")
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, rec.Body.String(), "response body")
+			}
+		})
+	}
+
+	// Episodes which are not downloaded cannot be streamed.
+	req := httptest.NewRequest(
+		http.MethodGet,
+		subsonic.Prefix+"/stream?id=pe-4&f=json",
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	ssHandler.ServeHTTP(rec, req)
+
+	var resp struct {
+		Response struct {
+			Status string `json:"status"`
+			Error  struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		} `json:"subsonic-response"`
+	}
+	assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &resp), "decoding stream error")
+	assert.Equal(t, "failed", resp.Response.Status, "stream response status")
+	assert.Equal(t, 70, resp.Response.Error.Code, "stream error code")
+}
+
+// TestCreatePodcastChannelKeepEpisodes checks that the optional `keepEpisodes`
+// parameter of createPodcastChannel sets the retention of the channel.
+func TestCreatePodcastChannelKeepEpisodes(t *testing.T) {
+	tests := []struct {
+		desc         string
+		query        string
+		expectedCode int
+		subscribed   bool
+		expectedKeep int
+	}{
+		{
+			desc:       "without keepEpisodes",
+			query:      "url=http://example.com/feed.xml",
+			subscribed: true,
+		},
+		{
+			desc:         "with keepEpisodes",
+			query:        "url=http://example.com/feed.xml&keepEpisodes=3",
+			subscribed:   true,
+			expectedKeep: 3,
+		},
+		{
+			desc:         "resetting keepEpisodes",
+			query:        "url=http://example.com/feed.xml&keepEpisodes=0",
+			subscribed:   true,
+			expectedKeep: 0,
+		},
+		{
+			desc:         "negative keepEpisodes",
+			query:        "url=http://example.com/feed.xml&keepEpisodes=-1",
+			expectedCode: 10,
+		},
+		{
+			desc:         "malformed keepEpisodes",
+			query:        "url=http://example.com/feed.xml&keepEpisodes=many",
+			expectedCode: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			podcaster := &podcastsfakes.FakePodcaster{}
+			podcaster.SubscribeReturns(7, nil)
+
+			ssHandler := subsonic.NewHandler(
+				subsonic.Prefix,
+				config.Config{},
+				subsonic.Deps{
+					Library:    &libraryfakes.FakeLibrary{},
+					Browser:    &libraryfakes.FakeBrowser{},
+					Stations:   &radiofakes.FakeStations{},
+					Playlister: &playlistsfakes.FakePlaylister{},
+					AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+					Podcaster:  podcaster,
+				},
+			)
+
+			req := httptest.NewRequest(
+				http.MethodGet,
+				subsonic.Prefix+"/createPodcastChannel?f=json&"+test.query,
+				nil,
+			)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			var resp struct {
+				Response struct {
+					Error struct {
+						Code int `json:"code"`
+					} `json:"error"`
+				} `json:"subsonic-response"`
+			}
+			assert.NilErr(t, json.Unmarshal(rec.Body.Bytes(), &resp), "decoding response")
+			assert.Equal(t, test.expectedCode, resp.Response.Error.Code, "error code")
+
+			if !test.subscribed {
+				assert.Equal(t, 0, podcaster.SubscribeCallCount(), "subscribe calls")
+				return
+			}
+			assert.Equal(t, 1, podcaster.SubscribeCallCount(), "subscribe calls")
+
+			if !strings.Contains(test.query, "keepEpisodes") {
+				assert.Equal(t, 0, podcaster.SetKeepEpisodesCallCount(), "set keep calls")
+				return
+			}
+			assert.Equal(t, 1, podcaster.SetKeepEpisodesCallCount(), "set keep calls")
+			_, channelID, keep := podcaster.SetKeepEpisodesArgsForCall(0)
+			assert.Equal(t, int64(7), channelID, "channel ID")
+			assert.Equal(t, test.expectedKeep, keep, "kept episodes")
+		})
+	}
+}
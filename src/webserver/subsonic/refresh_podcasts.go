@@ -0,0 +1,19 @@
+package subsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// refreshPodcasts starts refreshing all podcast channels in the background.
+func (s *subsonic) refreshPodcasts(w http.ResponseWriter, req *http.Request) {
+	ctx := context.WithoutCancel(req.Context())
+	go func() {
+		if err := s.podcasts.Refresh(ctx); err != nil {
+			log.Printf("Refreshing podcasts: %s\n", err)
+		}
+	}()
+
+	encodeResponse(w, req, responseOk())
+}
@@ -0,0 +1,207 @@
+package subsonic_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestResponseFormats checks that responses are encoded in the format requested
+// with the `f` parameter and that both successful and error responses use the
+// HTTP status codes and content types of the reference Subsonic server.
+func TestResponseFormats(t *testing.T) {
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: &playlistsfakes.FakePlaylister{},
+		},
+	)
+
+	tests := []struct {
+		desc        string
+		url         string
+		contentType string
+		format      string
+
+		// callback is the expected JSONP callback function name.
+		callback string
+
+		expectedStatus string
+		expectedCode   int
+	}{
+		{
+			desc:           "default format",
+			url:            "/ping",
+			contentType:    "text/xml; charset=utf-8",
+			format:         "xml",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "explicit XML",
+			url:            "/ping?f=xml",
+			contentType:    "text/xml; charset=utf-8",
+			format:         "xml",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "unknown format",
+			url:            "/ping?f=yaml",
+			contentType:    "text/xml; charset=utf-8",
+			format:         "xml",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "XML error",
+			url:            "/getSong",
+			contentType:    "text/xml; charset=utf-8",
+			format:         "xml",
+			expectedStatus: "failed",
+			expectedCode:   70,
+		},
+		{
+			desc:           "JSON",
+			url:            "/ping?f=json",
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "JSON error",
+			url:            "/getSong?f=json",
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "failed",
+			expectedCode:   70,
+		},
+		{
+			desc:           "JSONP",
+			url:            "/ping?f=jsonp&callback=handleResponse",
+			contentType:    "text/javascript; charset=utf-8",
+			format:         "jsonp",
+			callback:       "handleResponse",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "JSONP with dotted callback",
+			url:            "/ping?f=jsonp&callback=jQuery.callbacks._123",
+			contentType:    "text/javascript; charset=utf-8",
+			format:         "jsonp",
+			callback:       "jQuery.callbacks._123",
+			expectedStatus: "ok",
+		},
+		{
+			desc:           "JSONP error",
+			url:            "/getSong?f=jsonp&callback=cb",
+			contentType:    "text/javascript; charset=utf-8",
+			format:         "jsonp",
+			callback:       "cb",
+			expectedStatus: "failed",
+			expectedCode:   70,
+		},
+		{
+			desc:           "JSONP without callback",
+			url:            "/ping?f=jsonp",
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "failed",
+			expectedCode:   10,
+		},
+		{
+			desc:           "JSONP with script in the callback",
+			url:            "/ping?f=jsonp&callback=alert(1)%3Bcb",
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "failed",
+			expectedCode:   0,
+		},
+		{
+			desc:           "JSONP with callback starting with a digit",
+			url:            "/ping?f=jsonp&callback=1cb",
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "failed",
+			expectedCode:   0,
+		},
+		{
+			desc:           "JSONP with too long callback",
+			url:            "/ping?f=jsonp&callback=" + strings.Repeat("a", 129),
+			contentType:    "application/json; charset=utf-8",
+			format:         "json",
+			expectedStatus: "failed",
+			expectedCode:   0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, subsonic.Prefix+test.url, nil)
+			rec := httptest.NewRecorder()
+			ssHandler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, "HTTP status code")
+			assert.Equal(
+				t,
+				test.contentType,
+				rec.Header().Get("Content-Type"),
+				"content type",
+			)
+
+			var (
+				status string
+				code   int
+				body   = rec.Body.String()
+			)
+			switch test.format {
+			case "xml":
+				var resp struct {
+					Status string `xml:"status,attr"`
+					Error  struct {
+						Code int `xml:"code,attr"`
+					} `xml:"error"`
+				}
+				if !strings.HasPrefix(body, xml.Header) {
+					t.Errorf("XML response does not start with the XML header")
+				}
+				assert.NilErr(t, xml.Unmarshal([]byte(body), &resp), "decoding XML")
+				status, code = resp.Status, resp.Error.Code
+			case "jsonp":
+				prefix := "/**/" + test.callback + "("
+				if !strings.HasPrefix(body, prefix) {
+					t.Fatalf("expected JSONP body to start with `%s` but it is: %s",
+						prefix, body)
+				}
+				body = strings.TrimPrefix(body, prefix)
+				body = strings.TrimSuffix(strings.TrimSpace(body), ");")
+				fallthrough
+			case "json":
+				var resp struct {
+					Response struct {
+						Status string `json:"status"`
+						Error  struct {
+							Code int `json:"code"`
+						} `json:"error"`
+					} `json:"subsonic-response"`
+				}
+				assert.NilErr(t, json.Unmarshal([]byte(body), &resp), "decoding JSON")
+				status, code = resp.Response.Status, resp.Response.Error.Code
+			}
+
+			assert.Equal(t, test.expectedStatus, status, "response status")
+			assert.Equal(t, test.expectedCode, code, "error code")
+		})
+	}
+}
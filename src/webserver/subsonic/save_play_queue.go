@@ -0,0 +1,104 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ironsmile/euterpe/src/playqueue"
+)
+
+func (s *subsonic) savePlayQueue(w http.ResponseWriter, req *http.Request) {
+	trackIDs, ok := parsePlayQueueTracks(w, req)
+	if !ok {
+		return
+	}
+
+	currentIndex := -1
+	if currentString := req.Form.Get("current"); currentString != "" {
+		current, err := strconv.ParseInt(currentString, 10, 64)
+		if err != nil || !isTrackID(current) {
+			resp := responseError(errCodeNotFound, "current song not found")
+			encodeResponse(w, req, resp)
+			return
+		}
+
+		for ind, trackID := range trackIDs {
+			if trackID == toTrackDBID(current) {
+				currentIndex = ind
+				break
+			}
+		}
+
+		if currentIndex < 0 {
+			resp := responseError(
+				errCodeGeneric,
+				"current song is not in the play queue",
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+	}
+
+	s.savePlayQueueWithIndex(w, req, trackIDs, currentIndex)
+}
+
+// savePlayQueueWithIndex saves the play queue with `trackIDs` and the current
+// track at `currentIndex`. The position and the client are read from the request.
+func (s *subsonic) savePlayQueueWithIndex(
+	w http.ResponseWriter,
+	req *http.Request,
+	trackIDs []int64,
+	currentIndex int,
+) {
+	var position int64
+	if positionString := req.Form.Get("position"); positionString != "" {
+		var err error
+		position, err = strconv.ParseInt(positionString, 10, 64)
+		if err != nil || position < 0 {
+			resp := responseError(
+				errCodeGeneric,
+				"position must be a non-negative integer",
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+	}
+
+	_, err := s.playQueue.Save(req.Context(), playqueue.SaveArgs{
+		TrackIDs:     trackIDs,
+		CurrentIndex: currentIndex,
+		Position:     position,
+		ChangedBy:    req.Form.Get("c"),
+	})
+	if errors.Is(err, playqueue.ErrTrackNotFound) {
+		resp := responseError(errCodeNotFound, "song not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
+
+// parsePlayQueueTracks returns the database IDs of all tracks in the `id`
+// parameters of the request. When some of them is not a track ID then an
+// error response is written and the returned boolean is false.
+func parsePlayQueueTracks(w http.ResponseWriter, req *http.Request) ([]int64, bool) {
+	var trackIDs []int64
+	for _, idString := range req.Form["id"] {
+		subsonicID, err := strconv.ParseInt(idString, 10, 64)
+		if err != nil || !isTrackID(subsonicID) {
+			resp := responseError(errCodeNotFound, "song not found")
+			encodeResponse(w, req, resp)
+			return nil, false
+		}
+
+		trackIDs = append(trackIDs, toTrackDBID(subsonicID))
+	}
+
+	return trackIDs, true
+}
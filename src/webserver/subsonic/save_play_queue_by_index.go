@@ -0,0 +1,32 @@
+package subsonic
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// savePlayQueueByIndex is the OpenSubsonic variant of savePlayQueue which
+// receives the index of the current track in the queue instead of its ID.
+func (s *subsonic) savePlayQueueByIndex(w http.ResponseWriter, req *http.Request) {
+	trackIDs, ok := parsePlayQueueTracks(w, req)
+	if !ok {
+		return
+	}
+
+	currentIndex := -1
+	if indexString := req.Form.Get("currentIndex"); indexString != "" {
+		index, err := strconv.Atoi(indexString)
+		if err != nil || index < 0 || index >= len(trackIDs) {
+			resp := responseError(
+				errCodeGeneric,
+				"currentIndex is out of the play queue range",
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+
+		currentIndex = index
+	}
+
+	s.savePlayQueueWithIndex(w, req, trackIDs, currentIndex)
+}
@@ -1,22 +1,18 @@
 package subsonic
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/ironsmile/euterpe/src/library"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
 )
 
 func (s *subsonic) scrobble(w http.ResponseWriter, req *http.Request) {
-	if submission := req.Form.Get("submission"); submission == "false" {
-		// This is for setting "now playing" and should not increase the
-		// play count and other track stats. I haven't decided what to do
-		// with this so at the moment it is a no-op.
-		encodeResponse(w, req, responseOk())
-		return
-	}
-
 	ids := req.Form["id"]
 	if len(ids) == 0 {
 		resp := responseError(errCodeMissingParameter, "no track ID set")
@@ -49,6 +45,13 @@ func (s *subsonic) scrobble(w http.ResponseWriter, req *http.Request) {
 	}
 
 	ctx := req.Context()
+	if submission := req.Form.Get("submission"); submission == "false" {
+		// This is for setting "now playing" and should not increase the
+		// play count and other track stats.
+		s.scrobbleNowPlaying(w, req, idInts)
+		return
+	}
+
 	scrobbleTime := time.Now()
 	if timeArg := req.Form.Get("time"); timeArg != "" {
 		unixTimeMs, err := strconv.ParseInt(timeArg, 10, 64)
@@ -76,3 +79,33 @@ func (s *subsonic) scrobble(w http.ResponseWriter, req *http.Request) {
 
 	encodeResponse(w, req, responseOk())
 }
+
+// scrobbleNowPlaying records the tracks as playing on the client which made
+// the request.
+func (s *subsonic) scrobbleNowPlaying(
+	w http.ResponseWriter,
+	req *http.Request,
+	trackIDs []int64,
+) {
+	device := webutils.ClientIP(req, s.trustedProxies)
+
+	for _, trackID := range trackIDs {
+		track, err := s.lib.GetTrack(req.Context(), trackID)
+		if errors.Is(err, library.ErrNotFound) {
+			resp := responseError(
+				errCodeNotFound,
+				fmt.Sprintf("track ID '%d' not found", trackFSID(trackID)),
+			)
+			encodeResponse(w, req, resp)
+			return
+		} else if err != nil {
+			resp := responseError(errCodeGeneric, err.Error())
+			encodeResponse(w, req, resp)
+			return
+		}
+
+		s.nowPlaying.Playing(s.auth.User, req.Form.Get("c"), device, track)
+	}
+
+	encodeResponse(w, req, responseOk())
+}
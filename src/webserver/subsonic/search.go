@@ -1,7 +1,10 @@
 package subsonic
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ironsmile/euterpe/src/library"
 )
@@ -22,6 +25,26 @@ func (s *subsonic) search(w http.ResponseWriter, req *http.Request) {
 		artistQuery = anyQuery
 	}
 
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
+		return
+	}
+
+	var newerThan time.Time
+	if newerThanStr := reqValues.Get("newerThan"); newerThanStr != "" {
+		newerThanMs, err := strconv.ParseInt(newerThanStr, 10, 64)
+		if err != nil {
+			resp := responseError(
+				errCodeGeneric,
+				fmt.Sprintf("newerThan must be an int: %s", err),
+			)
+			encodeResponse(w, req, resp)
+			return
+		}
+		newerThan = time.UnixMilli(newerThanMs)
+	}
+
 	resp := searchResponse{
 		baseResponse: responseOk(),
 		Result: xsdSearchResult{
@@ -33,15 +56,17 @@ func (s *subsonic) search(w http.ResponseWriter, req *http.Request) {
 		results := s.lib.Search(
 			req.Context(),
 			library.SearchArgs{
-				Query:  trackQuery,
-				Offset: count,
-				Count:  offset,
+				Query:          trackQuery,
+				Offset:         offset,
+				Count:          count,
+				NewerThan:      newerThan,
+				MusicFolderIDs: musicFolderIDs,
 			},
 		)
 		for _, track := range results {
 			resp.Result.Matches = append(
 				resp.Result.Matches,
-				trackToChild(track, s.lastModified),
+				trackToChild(track, s.getLastModified()),
 			)
 		}
 	}
@@ -50,9 +75,11 @@ func (s *subsonic) search(w http.ResponseWriter, req *http.Request) {
 		albums := s.lib.SearchAlbums(
 			req.Context(),
 			library.SearchArgs{
-				Query:  albumQuery,
-				Offset: count,
-				Count:  offset,
+				Query:          albumQuery,
+				Offset:         offset,
+				Count:          count,
+				NewerThan:      newerThan,
+				MusicFolderIDs: musicFolderIDs,
 			},
 		)
 		for _, album := range albums {
@@ -61,7 +88,7 @@ func (s *subsonic) search(w http.ResponseWriter, req *http.Request) {
 				albumToChild(
 					album,
 					0,
-					s.lastModified,
+					s.getLastModified(),
 				),
 			)
 		}
@@ -71,13 +98,15 @@ func (s *subsonic) search(w http.ResponseWriter, req *http.Request) {
 		artists := s.lib.SearchArtists(
 			req.Context(),
 			library.SearchArgs{
-				Query:  artistQuery,
-				Offset: count,
-				Count:  offset,
+				Query:          artistQuery,
+				Offset:         offset,
+				Count:          count,
+				NewerThan:      newerThan,
+				MusicFolderIDs: musicFolderIDs,
 			},
 		)
 		for _, artist := range artists {
-			artistChild := artistToChild(artist, s.lastModified)
+			artistChild := artistToChild(artist, s.getLastModified())
 			resp.Result.Matches = append(resp.Result.Matches, artistChild)
 		}
 	}
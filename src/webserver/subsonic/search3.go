@@ -9,10 +9,9 @@ import (
 func (s *subsonic) search3(w http.ResponseWriter, req *http.Request) {
 	reqValues := req.Form
 	searchQuery := reqValues.Get("query")
-	musicFolderID := reqValues.Get("musicFolderId")
-	if musicFolderID != "" && musicFolderExists(musicFolderID) {
-		resp := responseError(errCodeNotFound, "music folder not found")
-		encodeResponse(w, req, resp)
+	musicFolderIDs, err := s.requestMusicFolders(req)
+	if err != nil {
+		musicFoldersError(w, req, err)
 		return
 	}
 	songCount := parseIntOrDefault(reqValues.Get("songCount"), 20)
@@ -25,15 +24,16 @@ func (s *subsonic) search3(w http.ResponseWriter, req *http.Request) {
 	results := s.lib.Search(
 		req.Context(),
 		library.SearchArgs{
-			Query:  searchQuery,
-			Offset: songOffset,
-			Count:  songCount,
+			Query:          searchQuery,
+			Offset:         songOffset,
+			Count:          songCount,
+			MusicFolderIDs: musicFolderIDs,
 		},
 	)
 	for _, track := range results {
 		resp.Result.Songs = append(
 			resp.Result.Songs,
-			trackToChild(track, s.lastModified),
+			trackToChild(track, s.getLastModified()),
 		)
 	}
 
@@ -43,9 +43,10 @@ func (s *subsonic) search3(w http.ResponseWriter, req *http.Request) {
 	albums := s.lib.SearchAlbums(
 		req.Context(),
 		library.SearchArgs{
-			Query:  searchQuery,
-			Offset: albumOffset,
-			Count:  albumCount,
+			Query:          searchQuery,
+			Offset:         albumOffset,
+			Count:          albumCount,
+			MusicFolderIDs: musicFolderIDs,
 		},
 	)
 	for _, album := range albums {
@@ -59,9 +60,10 @@ func (s *subsonic) search3(w http.ResponseWriter, req *http.Request) {
 	artists := s.lib.SearchArtists(
 		req.Context(),
 		library.SearchArgs{
-			Query:  searchQuery,
-			Offset: artistOffset,
-			Count:  artistCount,
+			Query:          searchQuery,
+			Offset:         artistOffset,
+			Count:          artistCount,
+			MusicFolderIDs: musicFolderIDs,
 		},
 	)
 	for _, artist := range artists {
@@ -68,17 +68,19 @@ func TestSetRating(t *testing.T) {
 			lib := &libraryfakes.FakeLibrary{}
 			ssHandler := subsonic.NewHandler(
 				subsonic.Prefix,
-				lib,
-				&libraryfakes.FakeBrowser{},
-				&radiofakes.FakeStations{},
-				&playlistsfakes.FakePlaylister{},
 				config.Config{
 					Authenticate: config.Auth{
 						User: "test-user",
 					},
 				},
-				&subsonicfakes.FakeCoverArtHandler{},
-				&subsonicfakes.FakeCoverArtHandler{},
+				subsonic.Deps{
+					Library:    lib,
+					Browser:    &libraryfakes.FakeBrowser{},
+					Stations:   &radiofakes.FakeStations{},
+					Playlister: &playlistsfakes.FakePlaylister{},
+					AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+				},
 			)
 
 			req := httptest.NewRequest(http.MethodGet, test.url, nil)
@@ -185,17 +187,19 @@ func TestSetRatingErrors(t *testing.T) {
 
 			ssHandler := subsonic.NewHandler(
 				subsonic.Prefix,
-				lib,
-				&libraryfakes.FakeBrowser{},
-				&radiofakes.FakeStations{},
-				&playlistsfakes.FakePlaylister{},
 				config.Config{
 					Authenticate: config.Auth{
 						User: "test-user",
 					},
 				},
-				&subsonicfakes.FakeCoverArtHandler{},
-				&subsonicfakes.FakeCoverArtHandler{},
+				subsonic.Deps{
+					Library:    lib,
+					Browser:    &libraryfakes.FakeBrowser{},
+					Stations:   &radiofakes.FakeStations{},
+					Playlister: &playlistsfakes.FakePlaylister{},
+					AlbumArt:   &subsonicfakes.FakeCoverArtHandler{},
+					ArtistArt:  &subsonicfakes.FakeCoverArtHandler{},
+				},
 			)
 
 			req := httptest.NewRequest(http.MethodGet, test.url, nil)
@@ -0,0 +1,113 @@
+package subsonic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ironsmile/euterpe/src/assert"
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/library/libraryfakes"
+	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/webserver/subsonic"
+)
+
+// TestSmartPlaylistsReadOnly checks that smart playlists are marked as read-only
+// and that changing their tracks is not authorized.
+func TestSmartPlaylistsReadOnly(t *testing.T) {
+	smart := playlists.Playlist{
+		ID:        3,
+		Name:      "Forgotten Favourites",
+		CreatedAt: time.Unix(1728838802, 0),
+		UpdatedAt: time.Unix(1728838802, 0),
+		Rules:     &playlists.SmartRules{Limit: 10},
+	}
+	regular := playlists.Playlist{
+		ID:        4,
+		Name:      "Regular",
+		CreatedAt: time.Unix(1728838802, 0),
+		UpdatedAt: time.Unix(1728838802, 0),
+	}
+
+	playlister := &playlistsfakes.FakePlaylister{}
+	playlister.GetReturns(smart, nil)
+	playlister.ListReturns([]playlists.Playlist{smart, regular}, nil)
+	playlister.UpdateReturns(playlists.ErrSmartPlaylist)
+	playlister.DeleteReturns(playlists.ErrReadOnly)
+
+	ssHandler := subsonic.NewHandler(
+		subsonic.Prefix,
+		config.Config{},
+		subsonic.Deps{
+			Library:    &libraryfakes.FakeLibrary{},
+			Browser:    &libraryfakes.FakeBrowser{},
+			Stations:   &radiofakes.FakeStations{},
+			Playlister: playlister,
+		},
+	)
+
+	var playlistResp struct {
+		Response struct {
+			Playlist struct {
+				ID       string `json:"id"`
+				Readonly bool   `json:"readonly"`
+			} `json:"playlist"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler, "/getPlaylist?f=json&id=3", &playlistResp)
+	assert.Equal(t, "3", playlistResp.Response.Playlist.ID, "playlist ID")
+	assert.Equal(t, true, playlistResp.Response.Playlist.Readonly, "read-only")
+
+	var listResp struct {
+		Response struct {
+			Playlists struct {
+				Playlist []struct {
+					ID       string `json:"id"`
+					Readonly bool   `json:"readonly"`
+				} `json:"playlist"`
+			} `json:"playlists"`
+		} `json:"subsonic-response"`
+	}
+	decodeResponse(t, ssHandler, "/getPlaylists?f=json", &listResp)
+	list := listResp.Response.Playlists.Playlist
+	assert.Equal(t, 2, len(list), "playlists count")
+	if len(list) == 2 {
+		assert.Equal(t, true, list[0].Readonly, "smart playlist read-only")
+		assert.Equal(t, false, list[1].Readonly, "regular playlist read-only")
+	}
+
+	for _, url := range []string{
+		"/updatePlaylist?f=json&playlistId=3&songIdToAdd=2000000010",
+		"/createPlaylist?f=json&playlistId=3&songId=2000000010",
+		"/deletePlaylist?f=json&id=3",
+	} {
+		var errResp struct {
+			Response struct {
+				Status string `json:"status"`
+				Error  struct {
+					Code int `json:"code"`
+				} `json:"error"`
+			} `json:"subsonic-response"`
+		}
+		decodeResponse(t, ssHandler, url, &errResp)
+		assert.Equal(t, "failed", errResp.Response.Status, "status for %s", url)
+		assert.Equal(t, 50, errResp.Response.Error.Code, "error code for %s", url)
+	}
+}
+
+// decodeResponse makes a request for `url` and decodes the JSON response in `resp`.
+func decodeResponse(t *testing.T, h http.Handler, url string, resp any) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, subsonic.Prefix+url, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "HTTP status for %s", url)
+	err := json.Unmarshal(rec.Body.Bytes(), resp)
+	assert.NilErr(t, err, "decoding response for %s", url)
+}
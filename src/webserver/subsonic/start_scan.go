@@ -0,0 +1,30 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/library"
+)
+
+// startScan starts a library scan. When the `fullScan` parameter is true then
+// the meta data of all files already in the library is read again. Starting
+// a scan while another one is running is not an error. The status of the
+// running scan is returned instead.
+func (s *subsonic) startScan(w http.ResponseWriter, req *http.Request) {
+	fullScan := req.Form.Get("fullScan") == "true"
+
+	err := s.scanner.StartScan(fullScan)
+	if err != nil && !errors.Is(err, library.ErrScanInProgress) {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	resp := scanStatusResponse{
+		baseResponse: responseOk(),
+		ScanStatus:   toXsdScanStatus(s.scanner.ScanStatus()),
+	}
+
+	encodeResponse(w, req, resp)
+}
@@ -6,13 +6,20 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
 func (s *subsonic) stream(w http.ResponseWriter, req *http.Request) {
 	idString := req.Form.Get("id")
+	if strings.HasPrefix(idString, podcastEpisodePrefix) {
+		s.streamPodcastEpisode(w, req, idString)
+		return
+	}
+
 	trackID, err := strconv.ParseInt(idString, 10, 64)
-	if idString == "" || err != nil || !isTrackID(trackID) {
+	if idString == "" || err != nil || !isTrackID(trackID) ||
+		!s.canAccessTrack(req.Context(), toTrackDBID(trackID)) {
 		resp := responseError(errCodeNotFound, "track not found")
 		encodeResponse(w, req, resp)
 		return
@@ -22,7 +29,36 @@ func (s *subsonic) stream(w http.ResponseWriter, req *http.Request) {
 	// endpoint must be created for the "/download" endpoint.
 
 	filePath := s.lib.GetFilePath(req.Context(), toTrackDBID(trackID))
+	serveFile(w, req, filePath)
+}
+
+// streamPodcastEpisode serves the downloaded file of the podcast episode with
+// subsonic ID `idString`.
+func (s *subsonic) streamPodcastEpisode(
+	w http.ResponseWriter,
+	req *http.Request,
+	idString string,
+) {
+	episodeID, err := toPodcastEpisodeDBID(idString)
+	if err != nil || s.podcasts == nil {
+		resp := responseError(errCodeNotFound, "podcast episode not found")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	episode, err := s.podcasts.Episode(req.Context(), episodeID)
+	if err != nil || episode.FilePath == "" {
+		resp := responseError(errCodeNotFound, "podcast episode not downloaded")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	serveFile(w, req, episode.FilePath)
+}
 
+// serveFile writes the file at filePath as a response. Range requests are
+// supported.
+func serveFile(w http.ResponseWriter, req *http.Request, filePath string) {
 	fh, err := os.Open(filePath)
 	if err != nil {
 		http.NotFoundHandler().ServeHTTP(w, req)
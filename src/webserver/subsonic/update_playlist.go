@@ -25,6 +25,7 @@ func (s *subsonic) updatePlaylist(w http.ResponseWriter, req *http.Request) {
 	}
 
 	updateArgs := playlists.UpdateArgs{
+		User: s.auth.User,
 		Name: req.Form.Get("name"),
 		Desc: req.Form.Get("comment"),
 	}
@@ -71,11 +72,23 @@ func (s *subsonic) updatePlaylist(w http.ResponseWriter, req *http.Request) {
 		updateArgs.AddTracks = append(updateArgs.AddTracks, toTrackDBID(songID))
 	}
 
-	err = s.playlists.Update(req.Context(), id, updateArgs)
+	if len(updateArgs.RemoveTracks) > 0 {
+		updateArgs.RemoveTracks, err = s.toPlaylistIndexes(
+			req, id, updateArgs.RemoveTracks,
+		)
+	}
+	if err == nil {
+		err = s.playlists.Update(req.Context(), id, updateArgs)
+	}
 	if err != nil && errors.Is(err, playlists.ErrNotFound) {
 		resp := responseError(errCodeNotFound, "playlist not found")
 		encodeResponse(w, req, resp)
 		return
+	} else if errors.Is(err, playlists.ErrReadOnly) ||
+		errors.Is(err, playlists.ErrNotAllowed) {
+		resp := responseError(errCodeNotAuthorized, err.Error())
+		encodeResponse(w, req, resp)
+		return
 	} else if err != nil {
 		resp := responseError(errCodeGeneric, err.Error())
 		encodeResponse(w, req, resp)
@@ -84,3 +97,36 @@ func (s *subsonic) updatePlaylist(w http.ResponseWriter, req *http.Request) {
 
 	encodeResponse(w, req, responseOk())
 }
+
+// toPlaylistIndexes converts indexes of songs as seen by Subsonic clients to
+// indexes in the playlist. They differ when the playlist has entries which
+// tracks are missing from the library since such entries are not shown to the
+// clients.
+func (s *subsonic) toPlaylistIndexes(
+	req *http.Request,
+	id int64,
+	songIndexes []int64,
+) ([]int64, error) {
+	playlist, err := s.playlists.Get(req.Context(), id, s.auth.User)
+	if err != nil {
+		return nil, err
+	}
+
+	var shown []int64
+	for index, track := range playlist.Tracks {
+		if !track.Missing {
+			shown = append(shown, int64(index))
+		}
+	}
+
+	indexes := make([]int64, 0, len(songIndexes))
+	for _, songIndex := range songIndexes {
+		if songIndex < 0 || songIndex >= int64(len(shown)) {
+			return nil, fmt.Errorf("there is no song with index %d", songIndex)
+		}
+
+		indexes = append(indexes, shown[songIndex])
+	}
+
+	return indexes, nil
+}
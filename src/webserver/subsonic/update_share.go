@@ -0,0 +1,52 @@
+package subsonic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ironsmile/euterpe/src/shares"
+)
+
+func (s *subsonic) updateShare(w http.ResponseWriter, req *http.Request) {
+	id := req.Form.Get("id")
+	if id == "" {
+		resp := responseError(errCodeMissingParameter, "share ID is required")
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	var updateArgs shares.UpdateArgs
+
+	if _, ok := req.Form["description"]; ok {
+		desc := req.Form.Get("description")
+		updateArgs.Description = &desc
+	}
+
+	if expiresStr := req.Form.Get("expires"); expiresStr != "" {
+		expires, err := parseShareExpires(expiresStr)
+		if err != nil {
+			resp := responseError(errCodeGeneric, err.Error())
+			encodeResponse(w, req, resp)
+			return
+		}
+		updateArgs.ExpiresAt = &expires
+	}
+
+	err := s.shares.Update(req.Context(), id, updateArgs)
+	if err == nil && updateArgs.Description == nil && updateArgs.ExpiresAt == nil {
+		// Nothing was updated so make sure the share actually exists.
+		_, err = s.shares.Get(req.Context(), id)
+	}
+
+	if err != nil && errors.Is(err, shares.ErrNotFound) {
+		resp := responseError(errCodeNotFound, "share not found")
+		encodeResponse(w, req, resp)
+		return
+	} else if err != nil {
+		resp := responseError(errCodeGeneric, err.Error())
+		encodeResponse(w, req, resp)
+		return
+	}
+
+	encodeResponse(w, req, responseOk())
+}
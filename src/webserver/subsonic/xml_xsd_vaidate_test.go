@@ -11,13 +11,24 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ironsmile/euterpe/src/bookmarks"
+	"github.com/ironsmile/euterpe/src/bookmarks/bookmarksfakes"
 	"github.com/ironsmile/euterpe/src/config"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/library/libraryfakes"
 	"github.com/ironsmile/euterpe/src/playlists"
 	"github.com/ironsmile/euterpe/src/playlists/playlistsfakes"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/playqueue/playqueuefakes"
+	"github.com/ironsmile/euterpe/src/podcasts"
+	"github.com/ironsmile/euterpe/src/podcasts/podcastsfakes"
 	"github.com/ironsmile/euterpe/src/radio"
 	"github.com/ironsmile/euterpe/src/radio/radiofakes"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/shares/sharesfakes"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/similar/similarfakes"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic"
 	xsdvalidate "github.com/terminalstatic/go-xsd-validate"
 )
@@ -294,7 +305,7 @@ func TestSubsonicXMLResponses(t *testing.T) {
 	}
 
 	playlister := &playlistsfakes.FakePlaylister{
-		GetStub: func(ctx context.Context, i int64) (playlists.Playlist, error) {
+		GetStub: func(ctx context.Context, i int64, _ string) (playlists.Playlist, error) {
 			return playlists.Playlist{
 				ID:        5,
 				Name:      "new playlist",
@@ -324,6 +335,72 @@ func TestSubsonicXMLResponses(t *testing.T) {
 		},
 	}
 
+	share := shares.Share{
+		ID:          "wa2Hbny1uNfrHXkbYfaPzA",
+		Description: "some description",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		LastVisited: time.Now(),
+		VisitCount:  3,
+		Items:       []shares.Item{{Type: shares.ItemPlaylist, ID: 5}},
+		Tracks:      libSongs,
+	}
+	sharer := &sharesfakes.FakeSharer{
+		GetStub: func(_ context.Context, _ string) (shares.Share, error) {
+			return share, nil
+		},
+		ListStub: func(_ context.Context) ([]shares.Share, error) {
+			return []shares.Share{share, {
+				ID:        "pR8Fq4kZ2Hc0wQ1yXo7LbA",
+				CreatedAt: time.Now(),
+				Items:     []shares.Item{{Type: shares.ItemTrack, ID: 11}},
+			}}, nil
+		},
+		CreateStub: func(_ context.Context, _ shares.CreateArgs) (string, error) {
+			return share.ID, nil
+		},
+	}
+
+	bookmarker := &bookmarksfakes.FakeBookmarker{
+		GetStub: func(_ context.Context, _ int64) (bookmarks.Bookmark, error) {
+			return bookmarks.Bookmark{Track: libSongs[0], Position: 42000}, nil
+		},
+		ListStub: func(_ context.Context) ([]bookmarks.Bookmark, error) {
+			return []bookmarks.Bookmark{
+				{
+					Track:     libSongs[0],
+					Position:  42000,
+					Comment:   "where I stopped",
+					CreatedAt: time.Now().Add(-time.Hour),
+					UpdatedAt: time.Now(),
+				},
+				{
+					Track:     libSongs[1],
+					Position:  1000,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+			}, nil
+		},
+	}
+
+	finder := &similarfakes.FakeFinder{
+		SimilarTracksStub: func(
+			_ context.Context,
+			_ similar.Args,
+		) ([]library.TrackInfo, error) {
+			return libSongs, nil
+		},
+		SimilarArtistsStub: func(
+			_ context.Context,
+			_ similar.Args,
+		) ([]library.Artist, error) {
+			return []library.Artist{
+				{ID: 12, Name: "Similar Artist", AlbumCount: 2},
+			}, nil
+		},
+	}
+
 	err := xsdvalidate.Init()
 	if err != nil {
 		t.Fatalf("failed to initialize xsdvalidate: %s", err)
@@ -339,18 +416,101 @@ func TestSubsonicXMLResponses(t *testing.T) {
 	}
 	defer xsdhandler.Free()
 
+	scanner := &libraryfakes.FakeScanner{
+		ScanStatusStub: func() library.ScanStatus {
+			return library.ScanStatus{
+				Running:     true,
+				FilesWalked: 42,
+			}
+		},
+	}
+
+	queuer := &playqueuefakes.FakeQueuer{
+		GetStub: func(_ context.Context) (playqueue.Queue, error) {
+			return playqueue.Queue{
+				Tracks:       libSongs[:2],
+				CurrentIndex: 1,
+				Position:     31000,
+				ChangedBy:    "test-client",
+				UpdatedAt:    time.Now(),
+				Version:      3,
+			}, nil
+		},
+	}
+
+	episodes := []podcasts.Episode{
+		{
+			ID:           5,
+			ChannelID:    3,
+			ChannelTitle: "Test Podcast",
+			GUID:         "episode-5",
+			Title:        "Downloaded Episode",
+			Description:  "An episode which is on the disk.",
+			URL:          "https://example.com/episodes/5.mp3",
+			ContentType:  "audio/mpeg",
+			Size:         1024,
+			Duration:     31 * time.Minute,
+			PublishDate:  time.Now(),
+			Status:       podcasts.StatusCompleted,
+			FilePath:     "/podcasts/3/5.mp3",
+			DownloadedAt: time.Now(),
+		},
+		{
+			ID:           4,
+			ChannelID:    3,
+			ChannelTitle: "Test Podcast",
+			GUID:         "episode-4",
+			Title:        "New Episode",
+			URL:          "https://example.com/episodes/4.mp3",
+			Status:       podcasts.StatusNew,
+		},
+	}
+	channel := podcasts.Channel{
+		ID:          3,
+		URL:         "https://example.com/feed.xml",
+		Title:       "Test Podcast",
+		Description: "A podcast for testing.",
+		ImageURL:    "https://example.com/cover.png",
+		ImagePath:   "/podcasts/3/cover.png",
+		Status:      podcasts.StatusCompleted,
+		Episodes:    episodes,
+	}
+	podcaster := &podcastsfakes.FakePodcaster{}
+	podcaster.ChannelsReturns([]podcasts.Channel{
+		channel,
+		{
+			ID:           6,
+			URL:          "https://example.com/broken.xml",
+			Title:        "https://example.com/broken.xml",
+			Status:       podcasts.StatusError,
+			ErrorMessage: "HTTP response: 500 Internal Server Error",
+		},
+	}, nil)
+	podcaster.ChannelReturns(channel, nil)
+	podcaster.EpisodeReturns(episodes[0], nil)
+	podcaster.NewestEpisodesReturns(episodes, nil)
+	podcaster.SubscribeReturns(7, nil)
+
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		lib,
-		browser,
-		stations,
-		playlister,
 		config.Config{
 			Authenticate: config.Auth{
 				User: "test-user",
 			},
 		},
-		nil, nil,
+		subsonic.Deps{
+			Library:       lib,
+			Browser:       browser,
+			Stations:      stations,
+			Playlister:    playlister,
+			Sharer:        sharer,
+			SimilarFinder: finder,
+			NowPlaying:    nowplaying.NewRegistry(),
+			Bookmarker:    bookmarker,
+			Queuer:        queuer,
+			Scanner:       scanner,
+			Podcaster:     podcaster,
+		},
 	)
 
 	testURL := func(format string, args ...any) string {
@@ -377,6 +537,10 @@ func TestSubsonicXMLResponses(t *testing.T) {
 			desc: "getIndexes",
 			url:  testURL("/getIndexes"),
 		},
+		{
+			desc: "getIndexes not modified",
+			url:  testURL("/getIndexes?ifModifiedSince=%d", time.Now().Add(time.Hour).UnixMilli()),
+		},
 		{
 			desc: "getMusicDirectory artist",
 			url:  testURL("/getMusicDirectory?id=%d", int64(1e9+10)),
@@ -449,10 +613,100 @@ func TestSubsonicXMLResponses(t *testing.T) {
 			desc: "search tracks",
 			url:  testURL("/search?title=baba"),
 		},
+		{
+			desc: "search newer than",
+			url:  testURL("/search?title=baba&newerThan=1714834066000"),
+		},
 		{
 			desc: "scrobble",
 			url:  testURL("/scrobble?id=%d&time=1714834066", int64(2e9+33)),
 		},
+		{
+			desc: "scrobble now playing",
+			url:  testURL("/scrobble?id=%d&submission=false&c=test", int64(2e9+33)),
+		},
+		{
+			desc: "getNowPlaying",
+			url:  testURL("/getNowPlaying"),
+		},
+		{
+			desc: "getBookmarks",
+			url:  testURL("/getBookmarks"),
+		},
+		{
+			desc: "createBookmark",
+			url:  testURL("/createBookmark?id=%d&position=1000&comment=hi", int64(2e9+11)),
+		},
+		{
+			desc: "deleteBookmark",
+			url:  testURL("/deleteBookmark?id=%d", int64(2e9+11)),
+		},
+		{
+			desc: "getPlayQueue",
+			url:  testURL("/getPlayQueue"),
+		},
+		{
+			desc: "savePlayQueue",
+			url: testURL(
+				"/savePlayQueue?id=%d&id=%d&current=%d&position=1000",
+				int64(2e9+11), int64(2e9+12), int64(2e9+12),
+			),
+		},
+		{
+			desc: "savePlayQueue clears the queue",
+			url:  testURL("/savePlayQueue"),
+		},
+		{
+			desc: "getScanStatus",
+			url:  testURL("/getScanStatus"),
+		},
+		{
+			desc: "startScan",
+			url:  testURL("/startScan"),
+		},
+		{
+			desc: "startScan full",
+			url:  testURL("/startScan?fullScan=true"),
+		},
+		{
+			desc: "getPodcasts",
+			url:  testURL("/getPodcasts"),
+		},
+		{
+			desc: "getPodcasts without episodes",
+			url:  testURL("/getPodcasts?includeEpisodes=false"),
+		},
+		{
+			desc: "getPodcasts for a channel",
+			url:  testURL("/getPodcasts?id=pc-3"),
+		},
+		{
+			desc: "getNewestPodcasts",
+			url:  testURL("/getNewestPodcasts?count=5"),
+		},
+		{
+			desc: "refreshPodcasts",
+			url:  testURL("/refreshPodcasts"),
+		},
+		{
+			desc: "createPodcastChannel",
+			url: testURL(
+				"/createPodcastChannel?url=%s",
+				url.QueryEscape("https://example.com/feed.xml"),
+			),
+		},
+		{
+			desc: "deletePodcastChannel",
+			url:  testURL("/deletePodcastChannel?id=pc-3"),
+		},
+		{
+			desc: "deletePodcastEpisode",
+			url:  testURL("/deletePodcastEpisode?id=pe-5"),
+		},
+		{
+			desc: "downloadPodcastEpisode",
+			url:  testURL("/downloadPodcastEpisode?id=pe-4"),
+		},
 		{
 			desc: "star track",
 			url:  testURL("/star?id=%d", int64(2e9+33)),
@@ -515,6 +769,22 @@ func TestSubsonicXMLResponses(t *testing.T) {
 			desc: "getStarred2",
 			url:  testURL("/getStarred2"),
 		},
+		{
+			desc: "getSimilarSongs for song",
+			url:  testURL("/getSimilarSongs?id=%d&count=5", int64(2e9+11)),
+		},
+		{
+			desc: "getSimilarSongs for album",
+			url:  testURL("/getSimilarSongs?id=%d", 10),
+		},
+		{
+			desc: "getSimilarSongs for artist",
+			url:  testURL("/getSimilarSongs?id=%d", int64(1e9+10)),
+		},
+		{
+			desc: "getSimilarSongs2",
+			url:  testURL("/getSimilarSongs2?id=%d", int64(1e9+10)),
+		},
 		{
 			desc: "getTopSongs",
 			url:  testURL("/getTopSongs?artist=First+Artist&count=3"),
@@ -587,6 +857,24 @@ func TestSubsonicXMLResponses(t *testing.T) {
 			desc: "updatePlaylist",
 			url:  testURL("/getPlaylists?playlistId=5&name=baba&songIndexToRemove=2"),
 		},
+		{
+			desc: "getShares",
+			url:  testURL("/getShares"),
+		},
+		{
+			desc: "createShare",
+			url: testURL("/createShare?id=%d&id=%d&id=pl-5&description=baba&expires=%d",
+				int64(2e9+11), int64(10), time.Now().Add(time.Hour).UnixMilli(),
+			),
+		},
+		{
+			desc: "updateShare",
+			url:  testURL("/updateShare?id=%s&description=baba&expires=0", share.ID),
+		},
+		{
+			desc: "deleteShare",
+			url:  testURL("/deleteShare?id=%s", share.ID),
+		},
 	}
 
 	for _, test := range tests {
@@ -681,10 +969,10 @@ func TestSubsonicXMLErrors(t *testing.T) {
 	}
 
 	playlister := &playlistsfakes.FakePlaylister{
-		GetStub: func(ctx context.Context, i int64) (playlists.Playlist, error) {
+		GetStub: func(ctx context.Context, i int64, _ string) (playlists.Playlist, error) {
 			return playlists.Playlist{}, fmt.Errorf("not found: %w", playlists.ErrNotFound)
 		},
-		DeleteStub: func(ctx context.Context, i int64) error {
+		DeleteStub: func(ctx context.Context, i int64, _ string) error {
 			return fmt.Errorf("not found: %w", playlists.ErrNotFound)
 		},
 		UpdateStub: func(ctx context.Context, i int64, ua playlists.UpdateArgs) error {
@@ -707,14 +995,47 @@ func TestSubsonicXMLErrors(t *testing.T) {
 	}
 	defer xsdhandler.Free()
 
+	bookmarker := &bookmarksfakes.FakeBookmarker{
+		SetStub: func(_ context.Context, _ int64, _ bookmarks.SetArgs) error {
+			return bookmarks.ErrTrackNotFound
+		},
+		DeleteStub: func(_ context.Context, _ int64) error {
+			return bookmarks.ErrNotFound
+		},
+	}
+
+	scanner := &libraryfakes.FakeScanner{}
+	scanner.StartScanReturns(fmt.Errorf("cannot scan"))
+
+	podcaster := &podcastsfakes.FakePodcaster{}
+	podcaster.ChannelReturns(podcasts.Channel{}, podcasts.ErrNotFound)
+	podcaster.EpisodeReturns(podcasts.Episode{}, podcasts.ErrNotFound)
+	podcaster.SubscribeReturns(0, fmt.Errorf("feed URL scheme is not supported"))
+	podcaster.UnsubscribeReturns(podcasts.ErrNotFound)
+	podcaster.DeleteEpisodeReturns(podcasts.ErrNotFound)
+
+	queuer := &playqueuefakes.FakeQueuer{
+		SaveStub: func(_ context.Context, _ playqueue.SaveArgs) (int64, error) {
+			return 0, playqueue.ErrTrackNotFound
+		},
+	}
+
 	ssHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		lib,
-		browser,
-		stations,
-		playlister,
 		config.Config{},
-		nil, nil,
+		subsonic.Deps{
+			Library:       lib,
+			Browser:       browser,
+			Stations:      stations,
+			Playlister:    playlister,
+			Sharer:        &sharesfakes.FakeSharer{},
+			SimilarFinder: &similarfakes.FakeFinder{},
+			Bookmarker:    bookmarker,
+			Queuer:        queuer,
+			Scanner:       scanner,
+			Podcaster:     podcaster,
+			Avatars:       &libraryfakes.FakeAvatarManager{},
+		},
 	)
 
 	testURL := func(format string, args ...any) string {
@@ -776,6 +1097,144 @@ func TestSubsonicXMLErrors(t *testing.T) {
 			url:       testURL("/getArtistInfo2"),
 			errorCode: 70,
 		},
+		{
+			desc:      "getSimilarSongs without ID",
+			url:       testURL("/getSimilarSongs"),
+			errorCode: 10,
+		},
+		{
+			desc:      "getSimilarSongs artist not found",
+			url:       testURL("/getSimilarSongs?id=%d", int64(1e9+10)),
+			errorCode: 70,
+		},
+		{
+			desc:      "getSimilarSongs2 without ID",
+			url:       testURL("/getSimilarSongs2"),
+			errorCode: 10,
+		},
+		{
+			desc:      "getSimilarSongs2 ID for something which is not artist",
+			url:       testURL("/getSimilarSongs2?id=2"),
+			errorCode: 70,
+		},
+		{
+			desc:      "createBookmark without position",
+			url:       testURL("/createBookmark?id=%d", int64(2e9+11)),
+			errorCode: 10,
+		},
+		{
+			desc:      "createBookmark for something which is not a song",
+			url:       testURL("/createBookmark?id=11&position=10"),
+			errorCode: 70,
+		},
+		{
+			desc:      "createBookmark with negative position",
+			url:       testURL("/createBookmark?id=%d&position=-10", int64(2e9+11)),
+			errorCode: 0,
+		},
+		{
+			desc:      "deleteBookmark without ID",
+			url:       testURL("/deleteBookmark"),
+			errorCode: 10,
+		},
+		{
+			desc:      "deleteBookmark which does not exist",
+			url:       testURL("/deleteBookmark?id=%d", int64(2e9+11)),
+			errorCode: 70,
+		},
+		{
+			desc:      "savePlayQueue with something which is not a song",
+			url:       testURL("/savePlayQueue?id=11"),
+			errorCode: 70,
+		},
+		{
+			desc: "savePlayQueue with current song not in the queue",
+			url: testURL(
+				"/savePlayQueue?id=%d&current=%d",
+				int64(2e9+11), int64(2e9+12),
+			),
+			errorCode: 0,
+		},
+		{
+			desc:      "savePlayQueue with missing song",
+			url:       testURL("/savePlayQueue?id=%d", int64(2e9+11)),
+			errorCode: 70,
+		},
+		{
+			desc:      "startScan failure",
+			url:       testURL("/startScan"),
+			errorCode: 0,
+		},
+		{
+			desc:      "getPodcasts for missing channel",
+			url:       testURL("/getPodcasts?id=pc-42"),
+			errorCode: 70,
+		},
+		{
+			desc:      "getPodcasts with malformed ID",
+			url:       testURL("/getPodcasts?id=baba"),
+			errorCode: 70,
+		},
+		{
+			desc:      "createPodcastChannel without URL",
+			url:       testURL("/createPodcastChannel"),
+			errorCode: 10,
+		},
+		{
+			desc:      "createPodcastChannel with bad URL",
+			url:       testURL("/createPodcastChannel?url=ftp://example.com"),
+			errorCode: 0,
+		},
+		{
+			desc:      "deletePodcastChannel without ID",
+			url:       testURL("/deletePodcastChannel"),
+			errorCode: 10,
+		},
+		{
+			desc:      "deletePodcastChannel for missing channel",
+			url:       testURL("/deletePodcastChannel?id=pc-42"),
+			errorCode: 70,
+		},
+		{
+			desc:      "deletePodcastEpisode without ID",
+			url:       testURL("/deletePodcastEpisode"),
+			errorCode: 10,
+		},
+		{
+			desc:      "deletePodcastEpisode for missing episode",
+			url:       testURL("/deletePodcastEpisode?id=pe-42"),
+			errorCode: 70,
+		},
+		{
+			desc:      "downloadPodcastEpisode for missing episode",
+			url:       testURL("/downloadPodcastEpisode?id=pe-42"),
+			errorCode: 70,
+		},
+		{
+			desc:      "stream podcast episode which is missing",
+			url:       testURL("/stream?id=pe-42"),
+			errorCode: 70,
+		},
+		{
+			desc:      "getIndexes with wrong ifModifiedSince",
+			url:       testURL("/getIndexes?ifModifiedSince=baba"),
+			errorCode: 0,
+		},
+		{
+			desc:      "search with wrong newerThan",
+			url:       testURL("/search?any=baba&newerThan=baba"),
+			errorCode: 0,
+		},
+		{
+			desc:      "getAvatar without username",
+			url:       testURL("/getAvatar"),
+			errorCode: 10,
+		},
+		{
+			desc:      "getAvatar for missing user",
+			url:       testURL("/getAvatar?username=other-user"),
+			errorCode: 70,
+		},
 		{
 			desc:      "scrobble with no track",
 			url:       testURL("/scrobble"),
@@ -1060,6 +1519,31 @@ func TestSubsonicXMLErrors(t *testing.T) {
 			url:       testURL("/updatePlaylist?playlistId=6"),
 			errorCode: 70,
 		},
+		{
+			desc:      "create share without ID",
+			url:       testURL("/createShare?description=baba"),
+			errorCode: 10,
+		},
+		{
+			desc:      "create share for an artist",
+			url:       testURL("/createShare?id=%d", int64(1e9+10)),
+			errorCode: 70,
+		},
+		{
+			desc:      "create share with malformed playlist ID",
+			url:       testURL("/createShare?id=pl-baba"),
+			errorCode: 70,
+		},
+		{
+			desc:      "update share without ID",
+			url:       testURL("/updateShare?description=baba"),
+			errorCode: 10,
+		},
+		{
+			desc:      "delete share without ID",
+			url:       testURL("/deleteShare"),
+			errorCode: 10,
+		},
 	}
 
 	for _, test := range tests {
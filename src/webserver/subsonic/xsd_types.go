@@ -5,11 +5,18 @@ import (
 	"mime"
 	"net/url"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ironsmile/euterpe/src/bookmarks"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/podcasts"
 	"github.com/ironsmile/euterpe/src/radio"
+	"github.com/ironsmile/euterpe/src/shares"
 )
 
 type xsdIndexes struct {
@@ -62,7 +69,8 @@ type xsdChild struct {
 	Track         int64      `xml:"track,attr,omitempty" json:"track,omitempty"`       // position in album, I suppose
 	Duration      int64      `xml:"duration,attr,omitempty" json:"duration,omitempty"` // in seconds
 	Year          int16      `xml:"year,attr" json:"year"`
-	Genre         string     `xml:"genre,attr,omitempty" json:"gener,omitempty"`
+	DiscNumber    int        `xml:"discNumber,attr,omitempty" json:"discNumber,omitempty"`
+	Genre         string     `xml:"genre,attr,omitempty" json:"genre,omitempty"`
 	Size          int64      `xml:"size,attr,omitempty" json:"size,omitempty"` // in bytes
 	ContentType   string     `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
 	PlayCount     int64      `xml:"playCount,attr,omitempty" json:"playCount,omitempty"`
@@ -73,10 +81,112 @@ type xsdChild struct {
 	Created       time.Time  `xml:"created,attr,omitempty" json:"created,omitempty"`
 	Starred       *time.Time `xml:"starred,attr,omitempty" json:"starred,omitempty"`
 
+	// BookmarkPosition is the position of the bookmark for this track in
+	// milliseconds. Zero when there is no bookmark.
+	BookmarkPosition int64 `xml:"bookmarkPosition,attr,omitempty" json:"bookmarkPosition,omitempty"`
+
 	// Open Subsonic additions
-	Name      string `xml:"-" json:"-"`
-	SongCount int64  `xml:"-" json:"songCount,omitempty"`
-	MediaType string `xml:"-" json:"mediaType"`
+	Name          string         `xml:"-" json:"-"`
+	SongCount     int64          `xml:"-" json:"songCount,omitempty"`
+	MediaType     string         `xml:"-" json:"mediaType"`
+	Played        *time.Time     `xml:"-" json:"played,omitempty"`
+	Comment       string         `xml:"-" json:"comment,omitempty"`
+	Genres        []xsdItemGenre `xml:"-" json:"genres,omitempty"`
+	DisplayArtist string         `xml:"-" json:"displayArtist,omitempty"`
+	SortName      string         `xml:"-" json:"sortName,omitempty"`
+	MusicBrainzID string         `xml:"-" json:"musicBrainzId,omitempty"`
+	ISRC          []string       `xml:"-" json:"isrc,omitempty"`
+	BPM           int            `xml:"-" json:"bpm,omitempty"`
+
+	// Album children carry these for their AlbumID3 entries. Child does not
+	// have them.
+	releaseDate         *xsdItemDate
+	originalReleaseDate *xsdItemDate
+	isCompilation       bool
+}
+
+// xsdItemGenre is the OpenSubsonic ItemGenre type. Songs and albums list all of
+// their genres with it.
+type xsdItemGenre struct {
+	Name string `json:"name"`
+}
+
+// toItemGenres returns the OpenSubsonic list of genres for a media with
+// `genre`. Only a single genre is stored for tracks in the library.
+func toItemGenres(genre string) []xsdItemGenre {
+	if genre == "" {
+		return nil
+	}
+	return []xsdItemGenre{{Name: genre}}
+}
+
+// xsdItemDate is the OpenSubsonic ItemDate type. Parts of the date which are
+// not known are left out.
+type xsdItemDate struct {
+	Year  int `json:"year,omitempty"`
+	Month int `json:"month,omitempty"`
+	Day   int `json:"day,omitempty"`
+}
+
+// toItemDate parses a tag date in the "YYYY", "YYYY-MM" or "YYYY-MM-DD" form.
+// It returns nil when there is no year in `date`.
+func toItemDate(date string) *xsdItemDate {
+	date, _, _ = strings.Cut(date, "T")
+	parts := strings.SplitN(date, "-", 3)
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || year <= 0 {
+		return nil
+	}
+
+	itemDate := &xsdItemDate{Year: year}
+	if len(parts) < 2 {
+		return itemDate
+	}
+	if month, err := strconv.Atoi(parts[1]); err == nil && month >= 1 && month <= 12 {
+		itemDate.Month = month
+	} else {
+		return itemDate
+	}
+	if len(parts) < 3 {
+		return itemDate
+	}
+	if day, err := strconv.Atoi(parts[2]); err == nil && day >= 1 && day <= 31 {
+		itemDate.Day = day
+	}
+
+	return itemDate
+}
+
+// xsdDiscTitle is the OpenSubsonic DiscTitle type.
+type xsdDiscTitle struct {
+	Disc  int    `json:"disc"`
+	Title string `json:"title"`
+}
+
+// toDiscTitles returns the titles of the discs of an album with `tracks`, ordered
+// by disc number.
+func toDiscTitles(tracks []library.TrackInfo) []xsdDiscTitle {
+	var titles []xsdDiscTitle
+	for _, track := range tracks {
+		if track.Disc <= 0 || track.DiscSubtitle == "" {
+			continue
+		}
+		if slices.ContainsFunc(titles, func(title xsdDiscTitle) bool {
+			return title.Disc == track.Disc
+		}) {
+			continue
+		}
+		titles = append(titles, xsdDiscTitle{
+			Disc:  track.Disc,
+			Title: track.DiscSubtitle,
+		})
+	}
+
+	slices.SortFunc(titles, func(a, b xsdDiscTitle) int {
+		return a.Disc - b.Disc
+	})
+	return titles
 }
 
 func trackToChild(track library.TrackInfo, defaultCreated time.Time) xsdChild {
@@ -99,6 +209,7 @@ func trackToChild(track library.TrackInfo, defaultCreated time.Time) xsdChild {
 		IsDir:         false,
 		CoverArtID:    albumConverArtID(track.AlbumID),
 		Track:         track.TrackNumber,
+		DiscNumber:    track.Disc,
 		Duration:      track.Duration / 1000,
 		Suffix:        track.Format,
 		Path: filepath.Join(
@@ -110,9 +221,19 @@ func trackToChild(track library.TrackInfo, defaultCreated time.Time) xsdChild {
 		PlayCount:  track.Plays,
 		UserRating: track.Rating,
 		Starred:    toUnixTimeWithNull(track.Favourite),
+		Played:     toUnixTimeWithNull(track.LastPlayed),
 		Year:       int16(track.Year),
 		Size:       track.Size,
 		BitRate:    int(track.Bitrate),
+		Genre:      track.Genre,
+		Comment:    track.Comment,
+
+		Genres:        toItemGenres(track.Genre),
+		DisplayArtist: track.Artist,
+		SortName:      track.SortName,
+		MusicBrainzID: track.MusicBrainzID,
+		ISRC:          track.ISRCs,
+		BPM:           track.BPM,
 
 		// Here we take advantage of the knowledge that the track.Format is just
 		// the file name extension.
@@ -145,9 +266,17 @@ func albumToChild(
 		Created:       created,
 		Duration:      album.Duration / 1000,
 		Starred:       toUnixTimeWithNull(album.Favourite),
+		Played:        toUnixTimeWithNull(album.LastPlayed),
 		UserRating:    album.Rating,
 		PlayCount:     album.Plays,
 		Year:          int16(album.Year),
+		DisplayArtist: album.Artist,
+		SortName:      album.SortName,
+		MusicBrainzID: album.MusicBrainzID,
+
+		releaseDate:         toItemDate(album.ReleaseDate),
+		originalReleaseDate: toItemDate(album.OriginalReleaseDate),
+		isCompilation:       album.Compilation,
 	}
 
 	if artistID != 0 {
@@ -190,7 +319,19 @@ type xsdAlbumID3 struct {
 	Created    time.Time  `xml:"created,attr" json:"created"`
 	Starred    *time.Time `xml:"starred,attr,omitempty" json:"starred,omitempty"`
 	Year       int16      `xml:"year,attr" json:"year"`
-	Genre      string     `xml:"genre,attr,omitempty" json:"gener,omitempty"`
+	Genre      string     `xml:"genre,attr,omitempty" json:"genre,omitempty"`
+
+	// Open Subsonic additions
+	Played        *time.Time `xml:"-" json:"played,omitempty"`
+	UserRating    uint8      `xml:"-" json:"userRating,omitempty"`
+	DisplayArtist string     `xml:"-" json:"displayArtist,omitempty"`
+	SortName      string     `xml:"-" json:"sortName,omitempty"`
+	MusicBrainzID string     `xml:"-" json:"musicBrainzId,omitempty"`
+
+	ReleaseDate         *xsdItemDate   `xml:"-" json:"releaseDate,omitempty"`
+	OriginalReleaseDate *xsdItemDate   `xml:"-" json:"originalReleaseDate,omitempty"`
+	IsCompilation       bool           `xml:"-" json:"isCompilation,omitempty"`
+	DiscTitles          []xsdDiscTitle `xml:"-" json:"discTitles,omitempty"`
 }
 
 func toAlbumID3Entry(child xsdChild) xsdAlbumID3 {
@@ -207,6 +348,16 @@ func toAlbumID3Entry(child xsdChild) xsdAlbumID3 {
 		Created:    child.Created,
 		Starred:    child.Starred,
 		PlayCount:  child.PlayCount,
+
+		Played:        child.Played,
+		UserRating:    child.UserRating,
+		DisplayArtist: child.DisplayArtist,
+		SortName:      child.SortName,
+		MusicBrainzID: child.MusicBrainzID,
+
+		ReleaseDate:         child.releaseDate,
+		OriginalReleaseDate: child.originalReleaseDate,
+		IsCompilation:       child.isCompilation,
 	}
 }
 
@@ -221,6 +372,16 @@ func dbAlbumToAlbumID3Entry(album library.Album) xsdAlbumID3 {
 		Starred:    toUnixTimeWithNull(album.Favourite),
 		PlayCount:  album.Plays,
 		Year:       int16(album.Year),
+
+		Played:        toUnixTimeWithNull(album.LastPlayed),
+		UserRating:    album.Rating,
+		DisplayArtist: album.Artist,
+		SortName:      album.SortName,
+		MusicBrainzID: album.MusicBrainzID,
+
+		ReleaseDate:         toItemDate(album.ReleaseDate),
+		OriginalReleaseDate: toItemDate(album.OriginalReleaseDate),
+		IsCompilation:       album.Compilation,
 	}
 }
 
@@ -276,6 +437,9 @@ func dbArtistToArtistID3(artist library.Artist, artURL url.URL) xsdArtistID3 {
 }
 
 type xsdArtistsID3 struct {
+	// LastModified is not part of the Subsonic XSD for this type. So it is
+	// returned only for JSON responses.
+	LastModified    int64         `xml:"-" json:"lastModified"`
 	IgnoredArticles string        `xml:"ignoredArticles,attr" json:"ignoredArticles"`
 	Children        []xsdIndexID3 `xml:"index" json:"index"`
 }
@@ -319,6 +483,18 @@ type xsdArtistInfoBase struct {
 	LargeImageURL  string `xml:"largeImageUrl" json:"largeImageUrl"`
 }
 
+type xsdArtistInfo struct {
+	xsdArtistInfoBase
+
+	SimilarArtists []xsdArtist `xml:"similarArtist" json:"similarArtist,omitempty"`
+}
+
+type xsdArtistInfo2 struct {
+	xsdArtistInfoBase
+
+	SimilarArtists []xsdArtistID3 `xml:"similarArtist" json:"similarArtist,omitempty"`
+}
+
 type xsdAlbumInfo struct {
 	Notes          string `xml:"notes,omitempty" json:"notes,omitempty"`
 	LastfmURL      string `xml:"lastFmUrl,omitempty" json:"lastFmUrl,omitempty"`
@@ -422,10 +598,21 @@ type xsdPlaylist struct {
 	Changed    time.Time `xml:"changed,attr" json:"changed"`
 	CoverArt   string    `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
 
+	// Readonly is an OpenSubsonic extension. Tracks of read-only playlists
+	// such as smart playlists could not be changed.
+	Readonly bool `xml:"readonly,attr,omitempty" json:"readonly,omitempty"`
+
 	AllowedUsers []string `xml:"allowedUser" json:"allowedUser"`
 }
 
-func toXsdPlaylist(playlist playlists.Playlist, owner string) xsdPlaylist {
+// toXsdPlaylist converts a playlist to its Subsonic representation. The
+// `defaultOwner` is reported as the owner of playlists which do not have one.
+func toXsdPlaylist(playlist playlists.Playlist, defaultOwner string) xsdPlaylist {
+	owner := playlist.Owner
+	if owner == "" {
+		owner = defaultOwner
+	}
+
 	return xsdPlaylist{
 		ID:           playlist.ID,
 		Name:         playlist.Name,
@@ -436,8 +623,9 @@ func toXsdPlaylist(playlist playlists.Playlist, owner string) xsdPlaylist {
 		Created:      playlist.CreatedAt,
 		Changed:      playlist.UpdatedAt,
 		Duration:     int64(playlist.Duration.Seconds()),
-		AllowedUsers: []string{owner},
+		AllowedUsers: append([]string{}, playlist.Collaborators...),
 		CoverArt:     fmt.Sprintf("pl-%d", playlist.ID),
+		Readonly:     playlist.ReadOnly(),
 	}
 }
 
@@ -449,14 +637,20 @@ type xsdPlaylistWithSongs struct {
 
 func toXsdPlaylistWithSongs(
 	playlist playlists.Playlist,
-	owner string,
+	defaultOwner string,
 	defaultLastModified time.Time,
 ) xsdPlaylistWithSongs {
 	xsdPlst := xsdPlaylistWithSongs{
-		xsdPlaylist: toXsdPlaylist(playlist, owner),
+		xsdPlaylist: toXsdPlaylist(playlist, defaultOwner),
 	}
 
 	for _, track := range playlist.Tracks {
+		if track.Missing {
+			// Subsonic clients have no way to show entries which could
+			// not be played.
+			continue
+		}
+
 		xsdPlst.Entries = append(
 			xsdPlst.Entries,
 			trackToChild(track, defaultLastModified),
@@ -473,3 +667,256 @@ type xsdPlaylists struct {
 type xsdSongs struct {
 	Songs []xsdChild `xml:"song" json:"song"`
 }
+
+type xsdNowPlaying struct {
+	Entries []xsdNowPlayingEntry `xml:"entry" json:"entry"`
+}
+
+type xsdNowPlayingEntry struct {
+	xsdChild
+
+	Username   string `xml:"username,attr" json:"username"`
+	MinutesAgo int64  `xml:"minutesAgo,attr" json:"minutesAgo"`
+	PlayerID   int64  `xml:"playerId,attr" json:"playerId"`
+	PlayerName string `xml:"playerName,attr,omitempty" json:"playerName,omitempty"`
+}
+
+type xsdShare struct {
+	ID          string     `xml:"id,attr" json:"id"`
+	URL         string     `xml:"url,attr" json:"url"`
+	Description string     `xml:"description,attr,omitempty" json:"description,omitempty"`
+	Username    string     `xml:"username,attr" json:"username"`
+	Created     time.Time  `xml:"created,attr" json:"created"`
+	Expires     *time.Time `xml:"expires,attr,omitempty" json:"expires,omitempty"`
+	LastVisited *time.Time `xml:"lastVisited,attr,omitempty" json:"lastVisited,omitempty"`
+	VisitCount  int64      `xml:"visitCount,attr" json:"visitCount"`
+
+	Entries []xsdChild `xml:"entry" json:"entry,omitempty"`
+}
+
+func toXsdShare(
+	share shares.Share,
+	shareURL url.URL,
+	username string,
+	defaultLastModified time.Time,
+) xsdShare {
+	xsdSh := xsdShare{
+		ID:          share.ID,
+		URL:         shareURL.String(),
+		Description: share.Description,
+		Username:    username,
+		Created:     share.CreatedAt,
+		VisitCount:  share.VisitCount,
+	}
+
+	if !share.ExpiresAt.IsZero() {
+		expires := share.ExpiresAt
+		xsdSh.Expires = &expires
+	}
+
+	if !share.LastVisited.IsZero() {
+		lastVisited := share.LastVisited
+		xsdSh.LastVisited = &lastVisited
+	}
+
+	for _, track := range share.Tracks {
+		xsdSh.Entries = append(
+			xsdSh.Entries,
+			trackToChild(track, defaultLastModified),
+		)
+	}
+
+	return xsdSh
+}
+
+type xsdShares struct {
+	Children []xsdShare `xml:"share" json:"share"`
+}
+
+type xsdBookmark struct {
+	Position int64     `xml:"position,attr" json:"position"`
+	Username string    `xml:"username,attr" json:"username"`
+	Comment  string    `xml:"comment,attr,omitempty" json:"comment,omitempty"`
+	Created  time.Time `xml:"created,attr" json:"created"`
+	Changed  time.Time `xml:"changed,attr" json:"changed"`
+
+	Entry xsdChild `xml:"entry" json:"entry"`
+}
+
+func toXsdBookmark(
+	bookmark bookmarks.Bookmark,
+	username string,
+	defaultLastModified time.Time,
+) xsdBookmark {
+	entry := trackToChild(bookmark.Track, defaultLastModified)
+	entry.BookmarkPosition = bookmark.Position
+
+	return xsdBookmark{
+		Position: bookmark.Position,
+		Username: username,
+		Comment:  bookmark.Comment,
+		Created:  bookmark.CreatedAt,
+		Changed:  bookmark.UpdatedAt,
+		Entry:    entry,
+	}
+}
+
+type xsdBookmarks struct {
+	Children []xsdBookmark `xml:"bookmark" json:"bookmark"`
+}
+
+type xsdPlayQueue struct {
+	Current   int64     `xml:"current,attr,omitempty" json:"current,omitempty,string"`
+	Position  int64     `xml:"position,attr" json:"position"`
+	Username  string    `xml:"username,attr" json:"username"`
+	Changed   time.Time `xml:"changed,attr" json:"changed"`
+	ChangedBy string    `xml:"changedBy,attr" json:"changedBy"`
+
+	Entries []xsdChild `xml:"entry" json:"entry"`
+}
+
+// xsdPlayQueueByIndex is the OpenSubsonic play queue which points to the current
+// track by its index in the queue instead of its ID.
+type xsdPlayQueueByIndex struct {
+	CurrentIndex *int      `xml:"currentIndex,attr,omitempty" json:"currentIndex,omitempty"`
+	Position     int64     `xml:"position,attr" json:"position"`
+	Username     string    `xml:"username,attr" json:"username"`
+	Changed      time.Time `xml:"changed,attr" json:"changed"`
+	ChangedBy    string    `xml:"changedBy,attr" json:"changedBy"`
+
+	Entries []xsdChild `xml:"entry" json:"entry"`
+}
+
+func toXsdPlayQueue(
+	queue playqueue.Queue,
+	username string,
+	defaultLastModified time.Time,
+) xsdPlayQueue {
+	xsdQueue := xsdPlayQueue{
+		Position:  queue.Position,
+		Username:  username,
+		Changed:   queue.UpdatedAt,
+		ChangedBy: queue.ChangedBy,
+		Entries:   make([]xsdChild, 0, len(queue.Tracks)),
+	}
+
+	if current, ok := queue.Current(); ok {
+		xsdQueue.Current = trackFSID(current.ID)
+	}
+
+	for _, track := range queue.Tracks {
+		xsdQueue.Entries = append(
+			xsdQueue.Entries,
+			trackToChild(track, defaultLastModified),
+		)
+	}
+
+	return xsdQueue
+}
+
+type xsdScanStatus struct {
+	Scanning bool  `xml:"scanning,attr" json:"scanning"`
+	Count    int64 `xml:"count,attr" json:"count"`
+}
+
+func toXsdScanStatus(status library.ScanStatus) xsdScanStatus {
+	return xsdScanStatus{
+		Scanning: status.Running,
+		Count:    status.FilesWalked,
+	}
+}
+
+type xsdPodcasts struct {
+	Channels []xsdPodcastChannel `xml:"channel" json:"channel"`
+}
+
+type xsdPodcastChannel struct {
+	ID               string `xml:"id,attr" json:"id"`
+	URL              string `xml:"url,attr" json:"url"`
+	Title            string `xml:"title,attr,omitempty" json:"title,omitempty"`
+	Description      string `xml:"description,attr,omitempty" json:"description,omitempty"`
+	CoverArtID       string `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	OriginalImageURL string `xml:"originalImageUrl,attr,omitempty" json:"originalImageUrl,omitempty"`
+	Status           string `xml:"status,attr" json:"status"`
+	ErrorMessage     string `xml:"errorMessage,attr,omitempty" json:"errorMessage,omitempty"`
+
+	Episodes []xsdPodcastEpisode `xml:"episode" json:"episode,omitempty"`
+}
+
+func toXsdPodcastChannel(channel podcasts.Channel) xsdPodcastChannel {
+	xsdChannel := xsdPodcastChannel{
+		ID:               podcastChannelID(channel.ID),
+		URL:              channel.URL,
+		Title:            channel.Title,
+		Description:      channel.Description,
+		OriginalImageURL: channel.ImageURL,
+		Status:           string(channel.Status),
+		ErrorMessage:     channel.ErrorMessage,
+	}
+
+	if channel.ImagePath != "" {
+		xsdChannel.CoverArtID = podcastChannelID(channel.ID)
+	}
+
+	for _, episode := range channel.Episodes {
+		xsdChannel.Episodes = append(xsdChannel.Episodes, toXsdPodcastEpisode(episode))
+	}
+
+	return xsdChannel
+}
+
+type xsdNewestPodcasts struct {
+	Episodes []xsdPodcastEpisode `xml:"episode" json:"episode"`
+}
+
+// xsdPodcastEpisode is a podcast episode. In the XSD it is an extension of the
+// Child type but unlike tracks its IDs are strings.
+type xsdPodcastEpisode struct {
+	ID          string     `xml:"id,attr" json:"id"`
+	StreamID    string     `xml:"streamId,attr,omitempty" json:"streamId,omitempty"`
+	ChannelID   string     `xml:"channelId,attr" json:"channelId"`
+	ParentID    string     `xml:"parent,attr" json:"parent"`
+	MediaType   string     `xml:"type,attr" json:"type"`
+	IsDir       bool       `xml:"isDir,attr" json:"isDir"`
+	Title       string     `xml:"title,attr" json:"title"`
+	Album       string     `xml:"album,attr,omitempty" json:"album,omitempty"`
+	CoverArtID  string     `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	Size        int64      `xml:"size,attr,omitempty" json:"size,omitempty"` // in bytes
+	ContentType string     `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	Suffix      string     `xml:"suffix,attr,omitempty" json:"suffix,omitempty"`
+	Duration    int64      `xml:"duration,attr,omitempty" json:"duration,omitempty"` // in seconds
+	Description string     `xml:"description,attr,omitempty" json:"description,omitempty"`
+	Status      string     `xml:"status,attr" json:"status"`
+	PublishDate *time.Time `xml:"publishDate,attr,omitempty" json:"publishDate,omitempty"`
+}
+
+func toXsdPodcastEpisode(episode podcasts.Episode) xsdPodcastEpisode {
+	xsdEpisode := xsdPodcastEpisode{
+		ID:          podcastEpisodeID(episode.ID),
+		ChannelID:   podcastChannelID(episode.ChannelID),
+		ParentID:    podcastChannelID(episode.ChannelID),
+		MediaType:   "podcast",
+		Title:       episode.Title,
+		Album:       episode.ChannelTitle,
+		CoverArtID:  podcastChannelID(episode.ChannelID),
+		Size:        episode.Size,
+		ContentType: episode.ContentType,
+		Duration:    int64(episode.Duration.Seconds()),
+		Description: episode.Description,
+		Status:      string(episode.Status),
+	}
+
+	if !episode.PublishDate.IsZero() {
+		xsdEpisode.PublishDate = &episode.PublishDate
+	}
+
+	if episode.FilePath != "" {
+		xsdEpisode.StreamID = xsdEpisode.ID
+		xsdEpisode.Suffix = strings.TrimPrefix(filepath.Ext(episode.FilePath), ".")
+		if xsdEpisode.ContentType == "" {
+			xsdEpisode.ContentType = mime.TypeByExtension(filepath.Ext(episode.FilePath))
+		}
+	}
+
+	return xsdEpisode
+}
@@ -74,9 +74,15 @@ func (t *FSTemplates) All() (*AllTemplates, error) {
 		return nil, fmt.Errorf("finding add_device template: %s", err)
 	}
 
+	share, err := t.Get("share.html")
+	if err != nil {
+		return nil, fmt.Errorf("parsing share template: %s", err)
+	}
+
 	return &AllTemplates{
 		index:     index,
 		addDevice: addDevice,
+		share:     share,
 	}, nil
 }
 
@@ -93,4 +99,5 @@ func NewFSTemplates(fs fs.FS) *FSTemplates {
 type AllTemplates struct {
 	index     *template.Template
 	addDevice *template.Template
+	share     *template.Template
 }
@@ -15,10 +15,18 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/ironsmile/euterpe/src/bookmarks"
 	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/duplicates"
 	"github.com/ironsmile/euterpe/src/library"
 	"github.com/ironsmile/euterpe/src/playlists"
+	"github.com/ironsmile/euterpe/src/playqueue"
+	"github.com/ironsmile/euterpe/src/podcasts"
 	"github.com/ironsmile/euterpe/src/radio"
+	"github.com/ironsmile/euterpe/src/shares"
+	"github.com/ironsmile/euterpe/src/similar"
+	"github.com/ironsmile/euterpe/src/webserver/loginattempts"
+	"github.com/ironsmile/euterpe/src/webserver/nowplaying"
 	"github.com/ironsmile/euterpe/src/webserver/subsonic"
 	"github.com/ironsmile/wrapfs"
 )
@@ -88,40 +96,116 @@ func (srv *Server) serveGoroutine() {
 		panic(err)
 	}
 	playlistsManager := playlists.NewManager(srv.library.ExecuteDBJobAndWait)
+	sharesManager := shares.NewManager(srv.library.ExecuteDBJobAndWait)
+	similarFinder := similar.NewFinder(srv.library.ExecuteDBJobAndWait)
+	nowPlaying := nowplaying.NewRegistry()
+	bookmarksManager := bookmarks.NewManager(srv.library.ExecuteDBJobAndWait)
+	playQueueManager := playqueue.NewManager(srv.library.ExecuteDBJobAndWait)
+	podcastsManager := podcasts.NewManager(
+		srv.library.ExecuteDBJobAndWait,
+		srv.cfg.Podcasts,
+	)
+	go podcasts.RefreshPeriodically(
+		srv.ctx,
+		podcastsManager,
+		srv.cfg.Podcasts.RefreshInterval,
+	)
 
 	staticFilesHandler := http.FileServer(http.FS(
 		wrapfs.WithModTime(srv.httpRootFS, time.Now()),
 	))
-	searchHandler := NewSearchHandler(srv.library)
-	albumHandler := NewAlbumHandler(srv.library)
+	searchHandler := NewSearchHandler(srv.library, srv.cfg.Authenticate)
+	albumHandler := NewAlbumHandler(srv.library, srv.cfg.Authenticate)
 	artoworkHandler := NewAlbumArtworkHandler(
 		srv.library,
 		srv.httpRootFS,
 		notFoundAlbumImage,
 	)
 	artistImageHandler := NewArtistImagesHandler(srv.library)
-	browseHandler := NewBrowseHandler(srv.library)
-	mediaFileHandler := NewFileHandler(srv.library)
+	browseHandler := NewBrowseHandler(srv.library, srv.library, srv.cfg.Authenticate)
+	artistRadioHandler := NewArtistRadioHandler(
+		similarFinder,
+		srv.library,
+		srv.library,
+		srv.cfg.Authenticate,
+	)
+	mediaFileHandler := NewFileHandler(
+		srv.library,
+		nowPlaying,
+		srv.cfg.Authenticate,
+		srv.cfg.TrustedProxies,
+	)
+	nowPlayingHandler := NewNowPlayingHandler(nowPlaying)
 	aboutHandler := NewAboutHandler()
-	loginHandler := NewLoginHandler(srv.cfg.Authenticate)
-	loginTokenHandler := NewLoginTokenHandler(srv.cfg.Authenticate)
+	loginAttempts := loginattempts.NewTracker(srv.cfg.LoginAttempts)
+	loginHandler := NewLoginHandler(
+		srv.cfg.Authenticate,
+		loginAttempts,
+		srv.cfg.TrustedProxies,
+	)
+	loginTokenHandler := NewLoginTokenHandler(
+		srv.cfg.Authenticate,
+		loginAttempts,
+		srv.cfg.TrustedProxies,
+	)
 	logoutHandler := NewLogoutHandler()
 	createQRTokenHandler := NewCreateQRTokenHandler(srv.cfg.Auth, srv.cfg.Authenticate)
 	indexHandler := NewTemplateHandler(allTpls.index, "")
 	addDeviceHandler := NewTemplateHandler(allTpls.addDevice, "Add Device")
 	registerTokenHandler := NewRigisterTokenHandler()
-	playlistsHandler := NewPlaylistsHandler(playlistsManager)
-	singlePlaylistHandler := NewSinglePlaylistHandler(playlistsManager)
+	playlistsHandler := NewPlaylistsHandler(playlistsManager, srv.cfg.Authenticate.User)
+	singlePlaylistHandler := NewSinglePlaylistHandler(
+		playlistsManager,
+		srv.cfg.Authenticate.User,
+	)
+	playlistImportHandler := NewPlaylistImportHandler(
+		playlistsManager,
+		srv.cfg.Authenticate.User,
+	)
+	playlistExportHandler := NewPlaylistExportHandler(
+		playlistsManager,
+		srv.library,
+		srv.cfg.Authenticate,
+	)
+	playlistImageHandler := NewPlaylistImageHandler(
+		playlistsManager,
+		srv.library,
+		srv.cfg.Authenticate.User,
+	)
+	bookmarksHandler := NewBookmarksHandler(bookmarksManager)
+	bookmarkHandler := NewBookmarkHandler(bookmarksManager)
+	playQueueHandler := NewPlayQueueHandler(playQueueManager)
+	libraryScanHandler := NewLibraryScanHandler(srv.library)
+	libraryDuplicatesHandler := NewLibraryDuplicatesHandler(
+		duplicates.NewFinder(srv.library.ExecuteDBJobAndWait),
+	)
+	libraryHealthHandler := NewLibraryHealthHandler(srv.library)
+	tagsHandler := NewTagsHandler(srv.library, srv.library, srv.cfg.Authenticate)
+	userAvatarHandler := NewUserAvatarHandler(srv.library, srv.cfg.Authenticate.User)
+	shareHandler := NewShareHandler(sharesManager, allTpls.share)
+	shareFileHandler := NewShareFileHandler(sharesManager, srv.library)
 
 	subsonicHandler := subsonic.NewHandler(
 		subsonic.Prefix,
-		srv.library,
-		srv.library,
-		radio.NewManager(srv.library.ExecuteDBJobAndWait),
-		playlistsManager,
 		srv.cfg,
-		artoworkHandler,
-		artistImageHandler,
+		subsonic.Deps{
+			Library:       srv.library,
+			Browser:       srv.library,
+			Stations:      radio.NewManager(srv.library.ExecuteDBJobAndWait),
+			Playlister:    playlistsManager,
+			AlbumArt:      artoworkHandler,
+			ArtistArt:     artistImageHandler,
+			LoginAttempts: loginAttempts,
+			Sharer:        sharesManager,
+			SimilarFinder: similarFinder,
+			NowPlaying:    nowPlaying,
+			Bookmarker:    bookmarksManager,
+			Queuer:        playQueueManager,
+			Scanner:       srv.library,
+			Podcaster:     podcastsManager,
+			Avatars:       srv.library,
+			PlaylistArt:   playlistImageHandler,
+		},
 	)
 
 	router := mux.NewRouter()
@@ -153,6 +237,18 @@ func (srv *Server) serveGoroutine() {
 	router.Handle(APIv1EndpointSearch, searchHandler).Methods(
 		APIv1Methods[APIv1EndpointSearch]...,
 	)
+	router.Handle(APIv1EndpointArtistRadio, artistRadioHandler).Methods(
+		APIv1Methods[APIv1EndpointArtistRadio]...,
+	)
+	router.Handle(APIv1EndpointNowPlaying, nowPlayingHandler).Methods(
+		APIv1Methods[APIv1EndpointNowPlaying]...,
+	)
+	router.Handle(APIv1EndpointTrackTags, tagsHandler).Methods(
+		APIv1Methods[APIv1EndpointTrackTags]...,
+	)
+	router.Handle(APIv1EndpointAlbumTags, tagsHandler).Methods(
+		APIv1Methods[APIv1EndpointAlbumTags]...,
+	)
 	router.Handle(APIv1EndpointLoginToken, loginTokenHandler).Methods(
 		APIv1Methods[APIv1EndpointLoginToken]...,
 	)
@@ -162,9 +258,41 @@ func (srv *Server) serveGoroutine() {
 	router.Handle(APIv1EndpointPlaylists, playlistsHandler).Methods(
 		APIv1Methods[APIv1EndpointPlaylists]...,
 	)
+	router.Handle(APIv1EndpointPlaylistsImport, playlistImportHandler).Methods(
+		APIv1Methods[APIv1EndpointPlaylistsImport]...,
+	)
+	// The export endpoint must be before the single playlist one since the
+	// latter matches any playlist ID.
+	router.Handle(APIv1EndpointPlaylistExport, playlistExportHandler).Methods(
+		APIv1Methods[APIv1EndpointPlaylistExport]...,
+	)
 	router.Handle(APIv1EndpointPlaylist, singlePlaylistHandler).Methods(
 		APIv1Methods[APIv1EndpointPlaylist]...,
 	)
+	router.Handle(APIv1EndpointPlaylistImage, playlistImageHandler).Methods(
+		APIv1Methods[APIv1EndpointPlaylistImage]...,
+	)
+	router.Handle(APIv1EndpointBookmarks, bookmarksHandler).Methods(
+		APIv1Methods[APIv1EndpointBookmarks]...,
+	)
+	router.Handle(APIv1EndpointBookmark, bookmarkHandler).Methods(
+		APIv1Methods[APIv1EndpointBookmark]...,
+	)
+	router.Handle(APIv1EndpointPlayQueue, playQueueHandler).Methods(
+		APIv1Methods[APIv1EndpointPlayQueue]...,
+	)
+	router.Handle(APIv1EndpointLibraryScan, libraryScanHandler).Methods(
+		APIv1Methods[APIv1EndpointLibraryScan]...,
+	)
+	router.Handle(APIv1EndpointLibraryDuplicates, libraryDuplicatesHandler).Methods(
+		APIv1Methods[APIv1EndpointLibraryDuplicates]...,
+	)
+	router.Handle(APIv1EndpointLibraryHealth, libraryHealthHandler).Methods(
+		APIv1Methods[APIv1EndpointLibraryHealth]...,
+	)
+	router.Handle(APIv1EndpointUserAvatar, userAvatarHandler).Methods(
+		APIv1Methods[APIv1EndpointUserAvatar]...,
+	)
 
 	// Kept for backward compatibility with older clients created before the
 	// API v1 compatibility promise. Although no promise has been made for
@@ -189,6 +317,8 @@ func (srv *Server) serveGoroutine() {
 	router.Handle("/", indexHandler).Methods("GET")
 	router.Handle("/add_device/", addDeviceHandler).Methods("GET")
 	router.Handle("/new_qr_token/", createQRTokenHandler).Methods("GET")
+	router.Handle("/share/{shareID}", shareHandler).Methods("GET")
+	router.Handle("/share/{shareID}/file/{fileID}", shareFileHandler).Methods("GET")
 	router.PathPrefix(subsonic.Prefix).Handler(subsonicHandler).Methods("GET", "POST", "HEAD")
 	router.PathPrefix("/").Handler(staticFilesHandler).Methods("GET")
 
@@ -202,6 +332,7 @@ func (srv *Server) serveGoroutine() {
 				"/album/",
 				"/v1/file/",
 				"/v1/album/",
+				"/share/",
 			},
 		)
 	}
@@ -209,10 +340,8 @@ func (srv *Server) serveGoroutine() {
 	if srv.cfg.Auth {
 		handler = NewAuthHandler(
 			handler,
-			srv.cfg.Authenticate.User,
-			srv.cfg.Authenticate.Password,
+			srv.cfg.Authenticate,
 			templatesResolver,
-			srv.cfg.Authenticate.Secret,
 			[]string{
 				"/v1/login/token/",
 				"/login/",
@@ -220,8 +349,11 @@ func (srv *Server) serveGoroutine() {
 				"/js/",
 				"/favicon/",
 				"/fonts/",
+				"/share/",
 				strings.TrimSuffix(subsonic.Prefix, "/") + "/",
 			},
+			loginAttempts,
+			srv.cfg.TrustedProxies,
 		)
 	}
 
@@ -0,0 +1,54 @@
+package webutils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/config"
+)
+
+// ClientIP returns the IP address of the client which made the request. When the
+// request comes from one of the trusted proxies then the X-Forwarded-For and
+// X-Real-IP headers are consulted. X-Forwarded-For is read from right to left and
+// the first address which is not a trusted proxy is returned.
+func ClientIP(r *http.Request, trustedProxies config.CIDRList) string {
+	remoteIP := remoteAddrIP(r.RemoteAddr)
+
+	ip := net.ParseIP(remoteIP)
+	if ip == nil || !trustedProxies.Contains(ip) {
+		return remoteIP
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	var hops []string
+	for _, header := range forwarded {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+
+		if !trustedProxies.Contains(hop) {
+			return hop.String()
+		}
+	}
+
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP.String()
+	}
+
+	return remoteIP
+}
+
+// remoteAddrIP strips the port from a http.Request.RemoteAddr.
+func remoteAddrIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,82 @@
+package webutils_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ironsmile/euterpe/src/config"
+	"github.com/ironsmile/euterpe/src/webserver/webutils"
+)
+
+// TestClientIP checks that proxy headers are used only when the request comes
+// from a trusted proxy.
+func TestClientIP(t *testing.T) {
+	var trusted config.CIDRList
+	err := json.Unmarshal([]byte(`["10.0.0.0/8", "192.0.2.1"]`), &trusted)
+	if err != nil {
+		t.Fatalf("parsing trusted proxies: %s", err)
+	}
+
+	tests := []struct {
+		desc       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{
+			desc:       "no proxy",
+			remoteAddr: "198.51.100.5:4455",
+			expected:   "198.51.100.5",
+		},
+		{
+			desc:       "headers from untrusted source are ignored",
+			remoteAddr: "198.51.100.5:4455",
+			forwarded:  "203.0.113.7",
+			realIP:     "203.0.113.8",
+			expected:   "198.51.100.5",
+		},
+		{
+			desc:       "trusted proxy with X-Forwarded-For",
+			remoteAddr: "10.1.2.3:4455",
+			forwarded:  "203.0.113.7",
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "chain of trusted proxies",
+			remoteAddr: "10.1.2.3:4455",
+			forwarded:  "198.51.100.9, 203.0.113.7, 192.0.2.1, 10.0.0.2",
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "trusted proxy with X-Real-IP",
+			remoteAddr: "192.0.2.1:4455",
+			realIP:     "203.0.113.8",
+			expected:   "203.0.113.8",
+		},
+		{
+			desc:       "trusted proxy without headers",
+			remoteAddr: "10.1.2.3:4455",
+			expected:   "10.1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", test.forwarded)
+			}
+			if test.realIP != "" {
+				req.Header.Set("X-Real-IP", test.realIP)
+			}
+
+			actual := webutils.ClientIP(req, trusted)
+			if actual != test.expected {
+				t.Errorf("expected client IP `%s` but got `%s`", test.expected, actual)
+			}
+		})
+	}
+}
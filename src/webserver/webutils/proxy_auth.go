@@ -0,0 +1,31 @@
+package webutils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ironsmile/euterpe/src/config"
+)
+
+// ProxyAuthUser returns the name of the user as set by an authenticating reverse
+// proxy in the auth.ProxyHeader header. The header is used only when the request
+// comes directly from one of the auth.ProxyNetworks. Otherwise it is ignored and
+// the returned boolean is false.
+func ProxyAuthUser(r *http.Request, auth config.Auth) (string, bool) {
+	if auth.ProxyHeader == "" || len(auth.ProxyNetworks) == 0 {
+		return "", false
+	}
+
+	ip := net.ParseIP(remoteAddrIP(r.RemoteAddr))
+	if ip == nil || !auth.ProxyNetworks.Contains(ip) {
+		return "", false
+	}
+
+	user := strings.TrimSpace(r.Header.Get(auth.ProxyHeader))
+	if user == "" {
+		return "", false
+	}
+
+	return user, true
+}
@@ -0,0 +1,63 @@
+package webutils
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequestScheme returns the original request scheme used for accessing
+// the server. It takes into account the X-Forwarded-Proto and the Forwarded
+// HTTP headers.
+func RequestScheme(req *http.Request) string {
+	proto := "http"
+	if forwadedProto := req.Header.Get("X-Forwarded-Proto"); forwadedProto == "https" {
+		proto = "https"
+	}
+
+	if forwarded := req.Header.Get("Forwarded"); forwarded != "" {
+		vals := splitForwarded(forwarded)
+		if forwardedProto, ok := vals["proto"]; ok && forwardedProto == "https" {
+			proto = "https"
+		}
+	}
+
+	return proto
+}
+
+// RequestHost returns the original request Host used for accessing the
+// server. It takes into account the X-Forwarded-Host and Forwarded HTTP headers.
+func RequestHost(req *http.Request) string {
+	host := req.Host
+	if forwadedHost := req.Header.Get("X-Forwarded-Host"); forwadedHost != "" {
+		host = forwadedHost
+	}
+
+	if forwarded := req.Header.Get("Forwarded"); forwarded != "" {
+		vals := splitForwarded(forwarded)
+		if forwardedHost, ok := vals["host"]; ok {
+			host = forwardedHost
+		}
+	}
+
+	return host
+}
+
+// splitForwarded splits the value of the HTTP header Forwarded into a map of
+// keys and values.
+func splitForwarded(val string) map[string]string {
+	vals := make(map[string]string)
+
+	// Example:
+	// Forwarded: by=<identifier>;for=<identifier>;host=<host>;proto=<http|https>
+	pairs := strings.Split(val, ";")
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || v == "" {
+			continue
+		}
+
+		vals[k] = strings.Trim(v, `"`)
+	}
+
+	return vals
+}
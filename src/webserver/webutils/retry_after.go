@@ -0,0 +1,18 @@
+package webutils
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetRetryAfter sets the Retry-After header to the number of seconds in wait,
+// rounded up.
+func SetRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}